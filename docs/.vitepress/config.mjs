@@ -38,6 +38,7 @@ export default defineConfig({
               items: [
                 { text: 'Overview', link: '/en/api/overview' },
                 { text: 'Client Interface', link: '/en/api/client' },
+                { text: 'Interface Package', link: '/en/api/npmiface' },
                 { text: 'NPM Package', link: '/en/api/npm' },
                 { text: 'Platform Package', link: '/en/api/platform' },
                 { text: 'Utils Package', link: '/en/api/utils' },
@@ -90,6 +91,7 @@ export default defineConfig({
               items: [
                 { text: '概览', link: '/zh/api/overview' },
                 { text: '客户端接口', link: '/zh/api/client' },
+                { text: '接口包', link: '/zh/api/npmiface' },
                 { text: 'NPM 包', link: '/zh/api/npm' },
                 { text: '平台包', link: '/zh/api/platform' },
                 { text: '工具包', link: '/zh/api/utils' },
@@ -0,0 +1,86 @@
+package fixtures
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+func TestSamples(t *testing.T) {
+	samples, err := Samples()
+	if err != nil {
+		t.Fatalf("Samples() failed: %v", err)
+	}
+
+	kinds := make(map[Kind]int)
+	ids := make(map[string]bool)
+	for _, sample := range samples {
+		kinds[sample.Kind]++
+		if ids[sample.ID()] {
+			t.Errorf("Duplicate sample %s", sample.ID())
+		}
+		ids[sample.ID()] = true
+		if sample.Command != "npm" {
+			t.Errorf("Sample %s: expected npm command, got %q", sample.ID(), sample.Command)
+		}
+	}
+	for _, kind := range []Kind{KindList, KindAudit, KindOutdated, KindSearch, KindError} {
+		if kinds[kind] == 0 {
+			t.Errorf("Expected samples of kind %s", kind)
+		}
+	}
+	if !ids["npm-6.14.18/error/e404"] {
+		t.Error("Expected npm 6 sample")
+	}
+
+	errorSamples, err := Samples(KindError)
+	if err != nil {
+		t.Fatalf("Samples(KindError) failed: %v", err)
+	}
+	if len(errorSamples) != kinds[KindError] {
+		t.Errorf("Expected %d error samples, got %d", kinds[KindError], len(errorSamples))
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"npm-11.0.0/list/empty.json": {Data: []byte(`{"command": "npm", "args": ["list", "--json"], "stdout": "{}\n", "stderr": "", "exit_code": 0}`)},
+		"npm-11.0.0/README.md":       {Data: []byte("ignored")},
+	}
+
+	samples, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(samples) != 1 {
+		t.Fatalf("Expected 1 sample, got %d", len(samples))
+	}
+	sample := samples[0]
+	if sample.ID() != "npm-11.0.0/list/empty" || sample.NpmVersion != "11.0.0" || sample.Stdout != "{}\n" {
+		t.Errorf("Unexpected sample: %+v", sample)
+	}
+
+	// 回放样本
+	result, err := sample.Replayer().Execute(context.Background(), utils.ExecuteOptions{Command: "/usr/bin/npm", Args: []string{"list", "--json"}})
+	if err != nil || result.Stdout != "{}\n" {
+		t.Errorf("Expected replayed output, got %+v, %v", result, err)
+	}
+
+	if samples, err := Load(fsys, KindAudit); err != nil || len(samples) != 0 {
+		t.Errorf("Expected no audit samples, got %d, %v", len(samples), err)
+	}
+
+	fsys["list/empty.json"] = &fstest.MapFile{Data: []byte(`{}`)}
+	if _, err := Load(fsys); err == nil || !strings.Contains(err.Error(), "invalid sample path") {
+		t.Errorf("Expected invalid path error, got %v", err)
+	}
+	delete(fsys, "list/empty.json")
+
+	fsys["npm-11.0.0/list/broken.json"] = &fstest.MapFile{Data: []byte(`{`)}
+	if _, err := Load(fsys); err == nil {
+		t.Error("Expected parse error")
+	}
+}
@@ -0,0 +1,306 @@
+package lockfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Format 锁文件格式
+type Format string
+
+const (
+	FormatNpm        Format = "package-lock.json"
+	FormatShrinkwrap Format = "npm-shrinkwrap.json" // 与package-lock.json格式相同，会随包一起发布
+	FormatYarn       Format = "yarn.lock"
+	FormatPnpm       Format = "pnpm-lock.yaml"
+)
+
+// lockfileNames 按npm的优先级排列的锁文件名，npm-shrinkwrap.json存在时npm忽略package-lock.json
+var lockfileNames = []Format{FormatShrinkwrap, FormatNpm, FormatYarn, FormatPnpm}
+
+// Package 锁文件中的一个已解析包版本
+type Package struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Resolved     string            `json:"resolved,omitempty"`  // tarball地址
+	Integrity    string            `json:"integrity,omitempty"` // SRI格式的完整性校验值
+	Dev          bool              `json:"dev,omitempty"`
+	Optional     bool              `json:"optional,omitempty"`
+	License      string            `json:"license,omitempty"`      // 许可证，只有npm锁文件记录
+	Dependencies map[string]string `json:"dependencies,omitempty"` // 依赖名 -> 版本范围
+	Ranges       []string          `json:"ranges,omitempty"`       // 解析到该版本的请求范围
+	Paths        []string          `json:"paths,omitempty"`        // 安装位置，例如node_modules/a/node_modules/b，只有npm锁文件记录
+}
+
+// Key 包的唯一标识，格式为name@version
+func (p *Package) Key() string {
+	return p.Name + "@" + p.Version
+}
+
+// Lockfile 与具体格式无关的锁文件模型
+type Lockfile struct {
+	Format   Format     `json:"format"`
+	Name     string     `json:"name,omitempty"`
+	Version  string     `json:"version,omitempty"`
+	Root     *RootDeps  `json:"root,omitempty"` // 项目直接依赖，yarn.lock中不包含该信息
+	Packages []*Package `json:"packages"`
+}
+
+// RootDeps 项目直接依赖的版本范围
+type RootDeps struct {
+	Dependencies         map[string]string `json:"dependencies,omitempty"`
+	DevDependencies      map[string]string `json:"devDependencies,omitempty"`
+	OptionalDependencies map[string]string `json:"optionalDependencies,omitempty"`
+}
+
+// Loss 转换过程中丢失或近似处理的信息
+type Loss struct {
+	Package string `json:"package,omitempty"` // 为空表示整个锁文件
+	Field   string `json:"field"`
+	Reason  string `json:"reason"`
+}
+
+// ConversionReport 锁文件转换报告
+type ConversionReport struct {
+	From     Format `json:"from"`
+	To       Format `json:"to"`
+	Packages int    `json:"packages"`
+	Losses   []Loss `json:"losses,omitempty"`
+}
+
+// Lossless 转换是否没有丢失信息
+func (r *ConversionReport) Lossless() bool {
+	return len(r.Losses) == 0
+}
+
+// addLoss 记录信息丢失
+func (r *ConversionReport) addLoss(pkg, field, reason string) {
+	r.Losses = append(r.Losses, Loss{Package: pkg, Field: field, Reason: reason})
+}
+
+// DetectFormat 根据文件名判断锁文件格式
+func DetectFormat(path string) (Format, error) {
+	switch filepath.Base(path) {
+	case "package-lock.json":
+		return FormatNpm, nil
+	case "npm-shrinkwrap.json":
+		return FormatShrinkwrap, nil
+	case "yarn.lock":
+		return FormatYarn, nil
+	case "pnpm-lock.yaml":
+		return FormatPnpm, nil
+	default:
+		return "", fmt.Errorf("unrecognized lockfile: %s", path)
+	}
+}
+
+// Locate 返回目录中的锁文件路径，同时存在多个时按npm-shrinkwrap.json、package-lock.json、
+// yarn.lock、pnpm-lock.yaml的顺序选择
+func Locate(dir string) (string, error) {
+	for _, name := range lockfileNames {
+		path := filepath.Join(dir, string(name))
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no lockfile found in %s", dir)
+}
+
+// Load 读取并解析锁文件，格式由文件名决定
+func Load(path string) (*Lockfile, error) {
+	format, err := DetectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	return Parse(format, data)
+}
+
+// Parse 按指定格式解析锁文件内容
+func Parse(format Format, data []byte) (*Lockfile, error) {
+	switch format {
+	case FormatNpm:
+		return ParseNpm(data)
+	case FormatShrinkwrap:
+		lock, err := ParseNpm(data)
+		if err != nil {
+			return nil, err
+		}
+		lock.Format = FormatShrinkwrap
+		return lock, nil
+	case FormatYarn:
+		return ParseYarn(data)
+	case FormatPnpm:
+		return ParsePnpm(data)
+	default:
+		return nil, fmt.Errorf("unsupported lockfile format: %s", format)
+	}
+}
+
+// Convert 将锁文件转换为目标格式
+//
+// 转换是尽力而为的：各格式记录的信息并不对等（例如yarn.lock没有依赖的安装位置，
+// pnpm-lock.yaml没有传递依赖的版本范围），无法还原的信息会记录在报告的Losses中。
+func Convert(lock *Lockfile, target Format) ([]byte, *ConversionReport, error) {
+	if lock == nil {
+		return nil, nil, fmt.Errorf("lockfile is nil")
+	}
+
+	report := &ConversionReport{
+		From:     lock.Format,
+		To:       target,
+		Packages: len(lock.Packages),
+	}
+
+	var data []byte
+	var err error
+	switch target {
+	case FormatNpm, FormatShrinkwrap:
+		data, err = writeNpm(lock, report)
+	case FormatYarn:
+		data, err = writeYarn(lock, report)
+	case FormatPnpm:
+		data, err = writePnpm(lock, report)
+	default:
+		return nil, nil, fmt.Errorf("unsupported lockfile format: %s", target)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, report, nil
+}
+
+// ResolvedSet 返回每个包解析到的版本集合，版本按字典序排列
+func (l *Lockfile) ResolvedSet() map[string][]string {
+	set := make(map[string][]string)
+	for _, pkg := range l.Packages {
+		set[pkg.Name] = appendUnique(set[pkg.Name], pkg.Version)
+	}
+	for name := range set {
+		sort.Strings(set[name])
+	}
+	return set
+}
+
+// Find 查找指定名称和版本的包
+func (l *Lockfile) Find(name, version string) *Package {
+	for _, pkg := range l.Packages {
+		if pkg.Name == name && pkg.Version == version {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// resolver 根据依赖名和版本范围查找已解析的包
+type resolver struct {
+	byRange map[string]*Package
+	byName  map[string][]*Package
+}
+
+// newResolver 创建解析器
+func newResolver(lock *Lockfile) *resolver {
+	r := &resolver{
+		byRange: make(map[string]*Package),
+		byName:  make(map[string][]*Package),
+	}
+	for _, pkg := range lock.Packages {
+		r.byName[pkg.Name] = append(r.byName[pkg.Name], pkg)
+		r.byRange[pkg.Key()] = pkg
+		for _, rng := range pkg.Ranges {
+			r.byRange[pkg.Name+"@"+rng] = pkg
+		}
+	}
+	return r
+}
+
+// resolve 查找依赖对应的包，范围无法匹配但只有一个版本时返回该版本
+func (r *resolver) resolve(name, rng string) *Package {
+	if pkg, ok := r.byRange[name+"@"+rng]; ok {
+		return pkg
+	}
+	if candidates := r.byName[name]; len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// rootRequests 返回项目直接依赖，按名称排序
+func (l *Lockfile) rootRequests() []dependencyRequest {
+	if l.Root == nil {
+		return nil
+	}
+
+	var requests []dependencyRequest
+	add := func(deps map[string]string, dev, optional bool) {
+		for _, name := range sortedKeys(deps) {
+			requests = append(requests, dependencyRequest{name: name, rng: deps[name], dev: dev, optional: optional})
+		}
+	}
+	add(l.Root.Dependencies, false, false)
+	add(l.Root.OptionalDependencies, false, true)
+	add(l.Root.DevDependencies, true, false)
+	return requests
+}
+
+// dependencyRequest 对某个依赖的请求
+type dependencyRequest struct {
+	name     string
+	rng      string
+	dev      bool
+	optional bool
+}
+
+// sortedPackages 按名称和版本排序的包列表
+func (l *Lockfile) sortedPackages() []*Package {
+	packages := make([]*Package, len(l.Packages))
+	copy(packages, l.Packages)
+	sort.SliceStable(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+	return packages
+}
+
+// splitSpec 拆分name@range，正确处理scope和别名（例如foo@npm:bar@^1.0.0）
+func splitSpec(spec string) (string, string) {
+	if spec == "" {
+		return "", ""
+	}
+	idx := strings.Index(spec[1:], "@")
+	if idx < 0 {
+		return spec, ""
+	}
+	return spec[:idx+1], spec[idx+2:]
+}
+
+// appendUnique 追加不重复的值
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,242 @@
+package lockfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/parse"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]Format{
+		"package-lock.json":         FormatNpm,
+		"app/npm-shrinkwrap.json":   FormatShrinkwrap,
+		"/repo/yarn.lock":           FormatYarn,
+		"nested/dir/pnpm-lock.yaml": FormatPnpm,
+	}
+	for path, expected := range tests {
+		format, err := DetectFormat(path)
+		if err != nil {
+			t.Errorf("DetectFormat(%q) failed: %v", path, err)
+			continue
+		}
+		if format != expected {
+			t.Errorf("DetectFormat(%q) = %s, expected %s", path, format, expected)
+		}
+	}
+
+	if _, err := DetectFormat("bun.lockb"); err == nil {
+		t.Error("Expected error for unsupported lockfile")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "yarn.lock")
+	if err := os.WriteFile(path, []byte(testYarnClassic), 0644); err != nil {
+		t.Fatalf("Failed to write lockfile: %v", err)
+	}
+
+	lock, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if lock.Format != FormatYarn || len(lock.Packages) != 5 {
+		t.Errorf("Unexpected lockfile: format=%s packages=%d", lock.Format, len(lock.Packages))
+	}
+
+	if _, err := Load(filepath.Join(dir, "package-lock.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestLocate(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Locate(dir); err == nil {
+		t.Error("Expected error for directory without lockfile")
+	}
+
+	for _, name := range []string{"yarn.lock", "package-lock.json", "npm-shrinkwrap.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(testNpmLockV3), 0644); err != nil {
+			t.Fatalf("Failed to write lockfile: %v", err)
+		}
+		path, err := Locate(dir)
+		if err != nil {
+			t.Fatalf("Locate() failed: %v", err)
+		}
+		if filepath.Base(path) != name {
+			t.Errorf("Locate() = %s, expected %s to take precedence", path, name)
+		}
+	}
+
+	lock, err := Load(filepath.Join(dir, "npm-shrinkwrap.json"))
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if lock.Format != FormatShrinkwrap || len(lock.Packages) == 0 {
+		t.Errorf("Unexpected shrinkwrap lockfile: format=%s packages=%d", lock.Format, len(lock.Packages))
+	}
+}
+
+func TestConvertRoundTrips(t *testing.T) {
+	sources := map[string]struct {
+		format Format
+		data   string
+	}{
+		"npm":        {FormatNpm, testNpmLockV3},
+		"shrinkwrap": {FormatShrinkwrap, testNpmLockV3},
+		"yarn":       {FormatYarn, testYarnClassic},
+		"pnpm":       {FormatPnpm, testPnpmV6},
+	}
+
+	for name, source := range sources {
+		lock, err := Parse(source.format, []byte(source.data))
+		if err != nil {
+			t.Fatalf("%s: Parse() failed: %v", name, err)
+		}
+
+		for _, target := range []Format{FormatNpm, FormatShrinkwrap, FormatYarn, FormatPnpm} {
+			data, report, err := Convert(lock, target)
+			if err != nil {
+				t.Fatalf("%s -> %s: Convert() failed: %v", name, target, err)
+			}
+			if report.Packages != len(lock.Packages) {
+				t.Errorf("%s -> %s: expected %d packages in report, got %d", name, target, len(lock.Packages), report.Packages)
+			}
+
+			converted, err := Parse(target, data)
+			if err != nil {
+				t.Fatalf("%s -> %s: failed to parse output: %v\n%s", name, target, err, data)
+			}
+			assertSameResolvedSet(t, lock, converted)
+		}
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	if _, _, err := Convert(nil, FormatNpm); err == nil {
+		t.Error("Expected error for nil lockfile")
+	}
+	if _, _, err := Convert(&Lockfile{}, Format("bun.lockb")); err == nil {
+		t.Error("Expected error for unsupported target")
+	}
+	if _, err := Parse(Format("bun.lockb"), nil); err == nil {
+		t.Error("Expected error for unsupported source")
+	}
+}
+
+func TestSplitSpec(t *testing.T) {
+	tests := []struct {
+		spec, name, rng string
+	}{
+		{"lodash@^4.17.21", "lodash", "^4.17.21"},
+		{"@babel/core@7.23.0", "@babel/core", "7.23.0"},
+		{"alias@npm:real@^1.0.0", "alias", "npm:real@^1.0.0"},
+		{"lodash", "lodash", ""},
+		{"@scope/pkg", "@scope/pkg", ""},
+	}
+	for _, tt := range tests {
+		name, rng := splitSpec(tt.spec)
+		if name != tt.name || rng != tt.rng {
+			t.Errorf("splitSpec(%q) = (%s, %s), expected (%s, %s)", tt.spec, name, rng, tt.name, tt.rng)
+		}
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	doc, err := parseYAML("test.yaml", []byte(`# comment
+key: value # trailing
+quoted: 'it''s'
+"double": "a\"b"
+flow: {integrity: sha512-x, tarball: 'https://x/y.tgz'}
+list: [x64, arm64]
+nested:
+  child:
+    leaf: 1
+block:
+- one
+- two
+empty:
+`))
+	if err != nil {
+		t.Fatalf("parseYAML() failed: %v", err)
+	}
+
+	if doc.getString("key") != "value" {
+		t.Errorf("Expected trailing comment to be stripped, got %q", doc.getString("key"))
+	}
+	if doc.getString("quoted") != "it's" || doc.getString("double") != `a"b` {
+		t.Errorf("Unexpected quoted values: %q %q", doc.getString("quoted"), doc.getString("double"))
+	}
+	if doc.getMap("flow").getString("tarball") != "https://x/y.tgz" {
+		t.Errorf("Unexpected flow mapping: %+v", doc.getMap("flow"))
+	}
+	if list, ok := doc.get("list").([]interface{}); !ok || len(list) != 2 {
+		t.Errorf("Unexpected flow sequence: %v", doc.get("list"))
+	}
+	if doc.getMap("nested").getMap("child").getString("leaf") != "1" {
+		t.Error("Expected nested mapping to be parsed")
+	}
+	if block, ok := doc.get("block").([]interface{}); !ok || len(block) != 2 || block[1] != "two" {
+		t.Errorf("Unexpected block sequence: %v", doc.get("block"))
+	}
+	if doc.getString("empty") != "" {
+		t.Error("Expected empty value")
+	}
+
+	_, err = parseYAML("test.yaml", []byte("a: 1\n   b: 2\n"))
+	expectParseError(t, err, 2, 4)
+	_, err = parseYAML("test.yaml", []byte("a:\n\tb: 1\n"))
+	expectParseError(t, err, 2, 1)
+}
+
+func TestParseYAMLLimits(t *testing.T) {
+	limits := parse.DefaultLimits
+	defer func() { parse.DefaultLimits = limits }()
+	parse.DefaultLimits = parse.Limits{MaxSize: 64, MaxDepth: 3}
+
+	if _, err := parseYAML("test.yaml", []byte("a:\n  b:\n    c: 1\n")); err != nil {
+		t.Errorf("Expected document within limits to parse, got %v", err)
+	}
+
+	_, err := parseYAML("test.yaml", []byte("a:\n  b:\n    c:\n      d: 1\n"))
+	if parseErr := expectParseError(t, err, 4, 7); !errors.Is(parseErr, parse.ErrNestingTooDeep) {
+		t.Errorf("Expected ErrNestingTooDeep, got %v", err)
+	}
+	// 流式集合同样计入深度
+	if _, err := parseYAML("test.yaml", []byte("a: [[[1]]]\n")); !errors.Is(err, parse.ErrNestingTooDeep) {
+		t.Errorf("Expected ErrNestingTooDeep for flow sequence, got %v", err)
+	}
+	if _, err := parseYAML("test.yaml", make([]byte, 65)); !errors.Is(err, parse.ErrInputTooLarge) {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+}
+
+// checkParseError 检查解析错误的位置信息是否在输入范围内，供各fuzz目标使用
+func checkParseError(t *testing.T, data []byte, err error) {
+	t.Helper()
+
+	var parseErr *parse.Error
+	if !errors.As(err, &parseErr) {
+		return
+	}
+	if parseErr.Offset < 0 || parseErr.Offset > int64(len(data)) || parseErr.Line < 1 || parseErr.Column < 1 {
+		t.Errorf("Invalid error position for %q: %+v", data, parseErr)
+	}
+}
+
+// expectParseError 检查错误是否为指定行列的*parse.Error
+func expectParseError(t *testing.T, err error, line, column int) *parse.Error {
+	t.Helper()
+
+	var parseErr *parse.Error
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *parse.Error, got %v", err)
+	}
+	if parseErr.Line != line || parseErr.Column != column {
+		t.Errorf("Expected error at %d:%d, got %d:%d (%v)", line, column, parseErr.Line, parseErr.Column, err)
+	}
+	return parseErr
+}
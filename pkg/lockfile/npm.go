@@ -0,0 +1,413 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/parse"
+)
+
+// npmLockJSON package-lock.json文件结构
+type npmLockJSON struct {
+	Name            string                     `json:"name,omitempty"`
+	Version         string                     `json:"version,omitempty"`
+	LockfileVersion int                        `json:"lockfileVersion"`
+	Requires        bool                       `json:"requires,omitempty"`
+	Packages        map[string]*npmLockPackage `json:"packages,omitempty"`
+	Dependencies    map[string]*npmLockV1Entry `json:"dependencies,omitempty"`
+}
+
+// npmLockPackage lockfileVersion 2/3中packages字段的条目
+type npmLockPackage struct {
+	Name                 string            `json:"name,omitempty"`
+	Version              string            `json:"version,omitempty"`
+	Resolved             string            `json:"resolved,omitempty"`
+	Integrity            string            `json:"integrity,omitempty"`
+	Link                 bool              `json:"link,omitempty"`
+	Dev                  bool              `json:"dev,omitempty"`
+	Optional             bool              `json:"optional,omitempty"`
+	DevOptional          bool              `json:"devOptional,omitempty"`
+	License              string            `json:"license,omitempty"`
+	Dependencies         map[string]string `json:"dependencies,omitempty"`
+	DevDependencies      map[string]string `json:"devDependencies,omitempty"`
+	OptionalDependencies map[string]string `json:"optionalDependencies,omitempty"`
+	PeerDependencies     map[string]string `json:"peerDependencies,omitempty"`
+}
+
+// npmLockV1Entry lockfileVersion 1中dependencies字段的条目
+type npmLockV1Entry struct {
+	Version      string                     `json:"version"`
+	Resolved     string                     `json:"resolved,omitempty"`
+	Integrity    string                     `json:"integrity,omitempty"`
+	Dev          bool                       `json:"dev,omitempty"`
+	Optional     bool                       `json:"optional,omitempty"`
+	Requires     map[string]string          `json:"requires,omitempty"`
+	Dependencies map[string]*npmLockV1Entry `json:"dependencies,omitempty"`
+}
+
+// ParseNpm 解析package-lock.json，支持lockfileVersion 1到3
+func ParseNpm(data []byte) (*Lockfile, error) {
+	var raw npmLockJSON
+	if err := parse.DecodeJSON("package-lock.json", data, &raw); err != nil {
+		return nil, err
+	}
+
+	lock := &Lockfile{
+		Format:  FormatNpm,
+		Name:    raw.Name,
+		Version: raw.Version,
+	}
+
+	if len(raw.Packages) > 0 {
+		parseNpmPackages(lock, raw.Packages)
+	} else {
+		parseNpmV1(lock, raw.Dependencies)
+	}
+
+	return lock, nil
+}
+
+// parseNpmPackages 解析lockfileVersion 2/3的packages字段
+//
+// 依赖的版本范围按照Node.js的模块查找规则定位到具体安装路径上的包，
+// 从而得到每个版本被哪些范围请求。
+func parseNpmPackages(lock *Lockfile, entries map[string]*npmLockPackage) {
+	if root, ok := entries[""]; ok {
+		lock.Root = &RootDeps{
+			Dependencies:         root.Dependencies,
+			DevDependencies:      root.DevDependencies,
+			OptionalDependencies: root.OptionalDependencies,
+		}
+		if lock.Name == "" {
+			lock.Name = root.Name
+		}
+		if lock.Version == "" {
+			lock.Version = root.Version
+		}
+	}
+
+	paths := make([]string, 0, len(entries))
+	for path := range entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	byPath := make(map[string]*Package)
+	byKey := make(map[string]*Package)
+	for _, path := range paths {
+		entry := entries[path]
+		// 跳过根项目、工作区源码目录和符号链接
+		if !strings.Contains(path, "node_modules/") || entry.Link {
+			continue
+		}
+
+		name := entry.Name
+		if name == "" {
+			name = path[strings.LastIndex(path, "node_modules/")+len("node_modules/"):]
+		}
+
+		key := name + "@" + entry.Version
+		pkg, exists := byKey[key]
+		if !exists {
+			pkg = &Package{
+				Name:         name,
+				Version:      entry.Version,
+				Resolved:     entry.Resolved,
+				Integrity:    entry.Integrity,
+				Dev:          entry.Dev,
+				Optional:     entry.Optional,
+				License:      entry.License,
+				Dependencies: mergeDependencies(entry.Dependencies, entry.OptionalDependencies),
+			}
+			byKey[key] = pkg
+			lock.Packages = append(lock.Packages, pkg)
+		} else {
+			// 同一版本出现在多个位置时，只要有一处是生产依赖就不是dev
+			pkg.Dev = pkg.Dev && entry.Dev
+			pkg.Optional = pkg.Optional && entry.Optional
+		}
+		pkg.Paths = append(pkg.Paths, path)
+		byPath[path] = pkg
+	}
+
+	for _, path := range paths {
+		entry := entries[path]
+		if path != "" && (!strings.Contains(path, "node_modules/") || entry.Link) {
+			continue
+		}
+		deps := mergeDependencies(entry.Dependencies, entry.OptionalDependencies)
+		if path == "" {
+			deps = mergeDependencies(deps, entry.DevDependencies)
+		}
+		for name, rng := range deps {
+			if target := resolveNodePath(byPath, path, name); target != nil {
+				target.Ranges = appendUnique(target.Ranges, rng)
+			}
+		}
+	}
+
+	for _, pkg := range lock.Packages {
+		sort.Strings(pkg.Ranges)
+	}
+}
+
+// resolveNodePath 按照Node.js模块查找规则，从from目录开始向上查找依赖
+func resolveNodePath(byPath map[string]*Package, from, name string) *Package {
+	dir := from
+	for {
+		candidate := "node_modules/" + name
+		if dir != "" {
+			candidate = dir + "/node_modules/" + name
+		}
+		if pkg, ok := byPath[candidate]; ok {
+			return pkg
+		}
+		if dir == "" {
+			return nil
+		}
+
+		idx := strings.LastIndex(dir, "/node_modules/")
+		if idx < 0 {
+			dir = ""
+			continue
+		}
+		dir = dir[:idx]
+	}
+}
+
+// parseNpmV1 解析lockfileVersion 1的嵌套dependencies字段
+func parseNpmV1(lock *Lockfile, deps map[string]*npmLockV1Entry) {
+	byKey := make(map[string]*Package)
+	lookup := func(name, version string) *Package {
+		key := name + "@" + version
+		pkg, exists := byKey[key]
+		if !exists {
+			pkg = &Package{Name: name, Version: version}
+			byKey[key] = pkg
+			lock.Packages = append(lock.Packages, pkg)
+		}
+		return pkg
+	}
+
+	var walk func(deps map[string]*npmLockV1Entry, scopes []map[string]*npmLockV1Entry, prefix string)
+	walk = func(deps map[string]*npmLockV1Entry, scopes []map[string]*npmLockV1Entry, prefix string) {
+		scopes = append([]map[string]*npmLockV1Entry{deps}, scopes...)
+		for _, name := range sortedEntryNames(deps) {
+			entry := deps[name]
+			pkg := lookup(name, entry.Version)
+			path := prefix + "node_modules/" + name
+			pkg.Paths = append(pkg.Paths, path)
+			if pkg.Resolved == "" && pkg.Integrity == "" {
+				pkg.Resolved = entry.Resolved
+				pkg.Integrity = entry.Integrity
+				pkg.Dev = entry.Dev
+				pkg.Optional = entry.Optional
+				pkg.Dependencies = entry.Requires
+			}
+
+			// requires中的范围在当前条目的嵌套依赖或外层作用域中解析
+			nested := append([]map[string]*npmLockV1Entry{entry.Dependencies}, scopes...)
+			for depName, rng := range entry.Requires {
+				for _, scope := range nested {
+					if target, ok := scope[depName]; ok {
+						resolved := lookup(depName, target.Version)
+						resolved.Ranges = appendUnique(resolved.Ranges, rng)
+						break
+					}
+				}
+			}
+
+			if len(entry.Dependencies) > 0 {
+				walk(entry.Dependencies, scopes, path+"/")
+			}
+		}
+	}
+	walk(deps, nil, "")
+
+	for _, pkg := range lock.Packages {
+		sort.Strings(pkg.Ranges)
+	}
+}
+
+// sortedEntryNames 返回排序后的v1条目名称
+func sortedEntryNames(deps map[string]*npmLockV1Entry) []string {
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mergeDependencies 合并依赖表，前面的表优先
+func mergeDependencies(tables ...map[string]string) map[string]string {
+	var merged map[string]string
+	for _, table := range tables {
+		for name, rng := range table {
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			if _, exists := merged[name]; !exists {
+				merged[name] = rng
+			}
+		}
+	}
+	return merged
+}
+
+// writeNpm 生成lockfileVersion 3的package-lock.json（也用于npm-shrinkwrap.json）
+//
+// 其他格式不记录node_modules中的安装位置，这里按照先到先得的方式提升到顶层，
+// 版本冲突时嵌套安装到依赖方目录下，结果可能与npm实际的提升结果不同。
+func writeNpm(lock *Lockfile, report *ConversionReport) ([]byte, error) {
+	if lock.Format != FormatNpm && lock.Format != FormatShrinkwrap {
+		report.addLoss("", "packages", "node_modules layout recomputed; hoisting may differ from what npm would produce")
+	}
+
+	res := newResolver(lock)
+	entries := make(map[string]*npmLockPackage)
+	placed := make(map[string]*Package)
+
+	root := &npmLockPackage{Name: lock.Name, Version: lock.Version}
+	if lock.Root != nil {
+		root.Dependencies = lock.Root.Dependencies
+		root.DevDependencies = lock.Root.DevDependencies
+		root.OptionalDependencies = lock.Root.OptionalDependencies
+	} else {
+		report.addLoss("", "root", "source lockfile does not record the project's direct dependencies")
+	}
+	entries[""] = root
+
+	type queued struct {
+		path string
+		pkg  *Package
+	}
+	var queue []queued
+
+	place := func(from string, name string, pkg *Package) {
+		// 沿查找路径已存在同一版本则无需安装
+		dir := from
+		for {
+			candidate := "node_modules/" + name
+			if dir != "" {
+				candidate = dir + "/node_modules/" + name
+			}
+			if existing, ok := placed[candidate]; ok {
+				if existing == pkg {
+					return
+				}
+				break
+			}
+			if dir == "" {
+				// 顶层空闲，提升到顶层
+				placed[candidate] = pkg
+				queue = append(queue, queued{path: candidate, pkg: pkg})
+				return
+			}
+			idx := strings.LastIndex(dir, "/node_modules/")
+			if idx < 0 {
+				dir = ""
+			} else {
+				dir = dir[:idx]
+			}
+		}
+
+		// 版本冲突，嵌套到依赖方目录下
+		path := "node_modules/" + name
+		if from != "" {
+			path = from + "/node_modules/" + name
+		}
+		if strings.Count(path, "node_modules/") > 32 {
+			report.addLoss(pkg.Key(), "packages", "dependency chain too deep to place")
+			return
+		}
+		placed[path] = pkg
+		queue = append(queue, queued{path: path, pkg: pkg})
+	}
+
+	requests := lock.rootRequests()
+	if len(requests) == 0 {
+		// 没有直接依赖信息时，以每个包名的第一个版本作为顶层
+		for _, pkg := range lock.sortedPackages() {
+			if _, ok := placed["node_modules/"+pkg.Name]; !ok {
+				place("", pkg.Name, pkg)
+			}
+		}
+	}
+	for _, request := range requests {
+		pkg := res.resolve(request.name, request.rng)
+		if pkg == nil {
+			report.addLoss(request.name+"@"+request.rng, "dependencies", "no resolved version found for root dependency")
+			continue
+		}
+		place("", request.name, pkg)
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, name := range sortedKeys(current.pkg.Dependencies) {
+			rng := current.pkg.Dependencies[name]
+			pkg := res.resolve(name, rng)
+			if pkg == nil {
+				if current.pkg.Optional || isOptionalDependency(lock, name) {
+					continue
+				}
+				report.addLoss(current.pkg.Key(), "dependencies", fmt.Sprintf("no resolved version found for %s@%s", name, rng))
+				continue
+			}
+			place(current.path, name, pkg)
+		}
+	}
+
+	used := make(map[*Package]bool)
+	for path, pkg := range placed {
+		if !used[pkg] && pkg.Integrity == "" {
+			report.addLoss(pkg.Key(), "integrity", "no integrity hash available")
+		}
+		used[pkg] = true
+		entries[path] = &npmLockPackage{
+			Version:      pkg.Version,
+			Resolved:     pkg.Resolved,
+			Integrity:    pkg.Integrity,
+			Dev:          pkg.Dev,
+			Optional:     pkg.Optional,
+			License:      pkg.License,
+			Dependencies: pkg.Dependencies,
+		}
+	}
+	for _, pkg := range lock.sortedPackages() {
+		if !used[pkg] {
+			report.addLoss(pkg.Key(), "packages", "not reachable from the project's dependencies, dropped")
+		}
+	}
+
+	data, err := json.MarshalIndent(npmLockJSON{
+		Name:            lock.Name,
+		Version:         lock.Version,
+		LockfileVersion: 3,
+		Requires:        true,
+		Packages:        entries,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode package-lock.json: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// isOptionalDependency 检查依赖是否被标记为可选
+func isOptionalDependency(lock *Lockfile, name string) bool {
+	if lock.Root != nil {
+		if _, ok := lock.Root.OptionalDependencies[name]; ok {
+			return true
+		}
+	}
+	for _, pkg := range lock.Packages {
+		if pkg.Name == name && pkg.Optional {
+			return true
+		}
+	}
+	return false
+}
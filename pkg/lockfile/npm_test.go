@@ -0,0 +1,222 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testNpmLockV3 = `{
+  "name": "demo",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "requires": true,
+  "packages": {
+    "": {
+      "name": "demo",
+      "version": "1.0.0",
+      "dependencies": {
+        "a": "^1.0.0",
+        "b": "^2.0.0"
+      },
+      "devDependencies": {
+        "@scope/tool": "~3.1.0"
+      }
+    },
+    "node_modules/a": {
+      "version": "1.2.0",
+      "resolved": "https://registry.npmjs.org/a/-/a-1.2.0.tgz",
+      "integrity": "sha512-aaa",
+      "dependencies": {
+        "b": "^1.0.0"
+      }
+    },
+    "node_modules/a/node_modules/b": {
+      "version": "1.5.0",
+      "resolved": "https://registry.npmjs.org/b/-/b-1.5.0.tgz",
+      "integrity": "sha512-b15"
+    },
+    "node_modules/b": {
+      "version": "2.3.0",
+      "resolved": "https://registry.npmjs.org/b/-/b-2.3.0.tgz",
+      "integrity": "sha512-b23"
+    },
+    "node_modules/@scope/tool": {
+      "version": "3.1.4",
+      "resolved": "https://registry.npmjs.org/@scope/tool/-/tool-3.1.4.tgz",
+      "integrity": "sha512-tool",
+      "dev": true,
+      "dependencies": {
+        "b": "^2.1.0"
+      }
+    },
+    "packages/local": {
+      "name": "local",
+      "version": "0.0.1"
+    },
+    "node_modules/local": {
+      "resolved": "packages/local",
+      "link": true
+    }
+  }
+}`
+
+func TestParseNpmV3(t *testing.T) {
+	lock, err := ParseNpm([]byte(testNpmLockV3))
+	if err != nil {
+		t.Fatalf("ParseNpm() failed: %v", err)
+	}
+
+	if lock.Name != "demo" || lock.Version != "1.0.0" {
+		t.Errorf("Expected root demo@1.0.0, got %s@%s", lock.Name, lock.Version)
+	}
+	if len(lock.Packages) != 4 {
+		t.Fatalf("Expected 4 packages (workspace links skipped), got %d", len(lock.Packages))
+	}
+	if lock.Root == nil || lock.Root.DevDependencies["@scope/tool"] != "~3.1.0" {
+		t.Errorf("Expected root dev dependency to be recorded, got %+v", lock.Root)
+	}
+
+	nested := lock.Find("b", "1.5.0")
+	if nested == nil {
+		t.Fatal("Expected nested b@1.5.0")
+	}
+	if len(nested.Ranges) != 1 || nested.Ranges[0] != "^1.0.0" {
+		t.Errorf("Expected nested b to be requested by ^1.0.0, got %v", nested.Ranges)
+	}
+	if len(nested.Paths) != 1 || nested.Paths[0] != "node_modules/a/node_modules/b" {
+		t.Errorf("Expected nested b to be installed under a, got %v", nested.Paths)
+	}
+
+	hoisted := lock.Find("b", "2.3.0")
+	if hoisted == nil {
+		t.Fatal("Expected hoisted b@2.3.0")
+	}
+	if len(hoisted.Ranges) != 2 || hoisted.Ranges[0] != "^2.0.0" || hoisted.Ranges[1] != "^2.1.0" {
+		t.Errorf("Expected hoisted b ranges [^2.0.0 ^2.1.0], got %v", hoisted.Ranges)
+	}
+
+	tool := lock.Find("@scope/tool", "3.1.4")
+	if tool == nil || !tool.Dev {
+		t.Errorf("Expected scoped dev package, got %+v", tool)
+	}
+
+	set := lock.ResolvedSet()
+	if len(set["b"]) != 2 || set["b"][0] != "1.5.0" || set["b"][1] != "2.3.0" {
+		t.Errorf("Unexpected resolved set for b: %v", set["b"])
+	}
+}
+
+func TestParseNpmV1(t *testing.T) {
+	data := `{
+  "name": "legacy",
+  "lockfileVersion": 1,
+  "requires": true,
+  "dependencies": {
+    "a": {
+      "version": "1.0.0",
+      "integrity": "sha512-a",
+      "requires": {"b": "^1.0.0", "c": "^3.0.0"},
+      "dependencies": {
+        "b": {"version": "1.1.0", "integrity": "sha512-b11"}
+      }
+    },
+    "b": {"version": "2.0.0", "integrity": "sha512-b2"},
+    "c": {"version": "3.2.0", "integrity": "sha512-c"}
+  }
+}`
+
+	lock, err := ParseNpm([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseNpm() failed: %v", err)
+	}
+
+	if len(lock.Packages) != 4 {
+		t.Fatalf("Expected 4 packages, got %d", len(lock.Packages))
+	}
+	if lock.Root != nil {
+		t.Error("Expected no root dependency info for lockfile v1")
+	}
+
+	nested := lock.Find("b", "1.1.0")
+	if nested == nil || nested.Integrity != "sha512-b11" {
+		t.Fatalf("Expected nested b@1.1.0 with integrity, got %+v", nested)
+	}
+	if len(nested.Ranges) != 1 || nested.Ranges[0] != "^1.0.0" {
+		t.Errorf("Expected nested b range ^1.0.0, got %v", nested.Ranges)
+	}
+	if len(nested.Paths) != 1 || nested.Paths[0] != "node_modules/a/node_modules/b" {
+		t.Errorf("Expected nested b to be installed under a, got %v", nested.Paths)
+	}
+	if c := lock.Find("c", "3.2.0"); c == nil || len(c.Ranges) != 1 {
+		t.Errorf("Expected c to be resolved from the outer scope, got %+v", c)
+	}
+}
+
+func TestParseNpmInvalid(t *testing.T) {
+	if _, err := ParseNpm([]byte("{invalid")); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+
+	_, err := ParseNpm([]byte("{\n  \"name\": x\n}"))
+	if parseErr := expectParseError(t, err, 2, 11); parseErr.Source != "package-lock.json" || parseErr.Offset != 12 {
+		t.Errorf("Unexpected error: %+v", parseErr)
+	}
+
+	// lockfileVersion不是数字
+	_, err = ParseNpm([]byte(`{"lockfileVersion": "3"}`))
+	expectParseError(t, err, 1, 23)
+}
+
+func FuzzParseNpm(f *testing.F) {
+	f.Add([]byte(testNpmLockV3))
+	f.Add([]byte(`{"lockfileVersion": 1, "dependencies": {"a": {"version": "1.0.0", "dependencies": {"b": {"version": "2.0.0"}}}}}`))
+	f.Add([]byte(`{"packages": {"node_modules/": {}, "": {"dependencies": {"a": "^1"}}}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		lock, err := ParseNpm(data)
+		if err != nil {
+			checkParseError(t, data, err)
+			return
+		}
+		// 能解析的锁文件也必须能转换
+		for _, format := range []Format{FormatNpm, FormatYarn, FormatPnpm} {
+			if _, _, err := Convert(lock, format); err != nil {
+				t.Errorf("Convert(%s) failed: %v", format, err)
+			}
+		}
+	})
+}
+
+func TestWriteNpmPlacesConflictingVersions(t *testing.T) {
+	lock := &Lockfile{
+		Format: FormatYarn,
+		Packages: []*Package{
+			{Name: "a", Version: "1.2.0", Integrity: "sha512-a", Ranges: []string{"^1.0.0"}, Dependencies: map[string]string{"b": "^1.0.0"}},
+			{Name: "b", Version: "1.5.0", Integrity: "sha512-b15", Ranges: []string{"^1.0.0"}},
+			{Name: "b", Version: "2.3.0", Integrity: "sha512-b23", Ranges: []string{"^2.0.0"}},
+		},
+		Root: &RootDeps{Dependencies: map[string]string{"a": "^1.0.0", "b": "^2.0.0"}},
+	}
+
+	data, report, err := Convert(lock, FormatNpm)
+	if err != nil {
+		t.Fatalf("Convert() failed: %v", err)
+	}
+
+	var out npmLockJSON
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if out.LockfileVersion != 3 {
+		t.Errorf("Expected lockfileVersion 3, got %d", out.LockfileVersion)
+	}
+	if entry := out.Packages["node_modules/b"]; entry == nil || entry.Version != "2.3.0" {
+		t.Errorf("Expected b@2.3.0 at top level, got %+v", entry)
+	}
+	if entry := out.Packages["node_modules/a/node_modules/b"]; entry == nil || entry.Version != "1.5.0" {
+		t.Errorf("Expected b@1.5.0 nested under a, got %+v", entry)
+	}
+	if report.Lossless() {
+		t.Error("Expected recomputed layout to be reported")
+	}
+}
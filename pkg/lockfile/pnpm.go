@@ -0,0 +1,269 @@
+package lockfile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParsePnpm 解析pnpm-lock.yaml，支持lockfileVersion 5.x、6.0和9.0
+func ParsePnpm(data []byte) (*Lockfile, error) {
+	doc, err := parseYAML("pnpm-lock.yaml", data)
+	if err != nil {
+		return nil, err
+	}
+
+	lockfileVersion := doc.getString("lockfileVersion")
+	if lockfileVersion == "" {
+		return nil, fmt.Errorf("pnpm-lock.yaml is missing lockfileVersion")
+	}
+	legacy := strings.HasPrefix(lockfileVersion, "5")
+
+	lock := &Lockfile{Format: FormatPnpm}
+	byKey := make(map[string]*Package)
+
+	packages := doc.getMap("packages")
+	for _, key := range packages.keysOrNil() {
+		name, version := parsePnpmKey(key, legacy)
+		if name == "" || version == "" {
+			continue
+		}
+		entry, _ := packages.get(key).(*yamlMap)
+
+		pkg, exists := byKey[name+"@"+version]
+		if !exists {
+			pkg = &Package{Name: name, Version: version}
+			byKey[pkg.Key()] = pkg
+			lock.Packages = append(lock.Packages, pkg)
+		}
+
+		resolution := entry.getMap("resolution")
+		pkg.Integrity = resolution.getString("integrity")
+		pkg.Resolved = resolution.getString("tarball")
+		pkg.Dev = entry.getString("dev") == "true"
+		pkg.Optional = entry.getString("optional") == "true"
+		addPnpmDependencies(pkg, entry, legacy)
+	}
+
+	// lockfileVersion 9将依赖关系放在snapshots中
+	snapshots := doc.getMap("snapshots")
+	for _, key := range snapshots.keysOrNil() {
+		name, version := parsePnpmKey(key, legacy)
+		pkg := byKey[name+"@"+version]
+		if pkg == nil {
+			continue
+		}
+		entry, _ := snapshots.get(key).(*yamlMap)
+		if entry.getString("optional") == "true" {
+			pkg.Optional = true
+		}
+		addPnpmDependencies(pkg, entry, legacy)
+	}
+
+	importer := doc
+	if importers := doc.getMap("importers"); importers != nil {
+		importer = importers.getMap(".")
+	}
+	lock.Root = parsePnpmImporter(importer, byKey, legacy)
+
+	for _, pkg := range lock.Packages {
+		sort.Strings(pkg.Ranges)
+	}
+
+	return lock, nil
+}
+
+// parsePnpmKey 解析packages中的键，得到包名和版本
+//
+// 键的格式随版本变化：5.x为/name/1.0.0_peer，6.0为/name@1.0.0(peer)，9.0为name@1.0.0(peer)。
+func parsePnpmKey(key string, legacy bool) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+	if idx := strings.Index(key, "("); idx >= 0 {
+		key = key[:idx]
+	}
+
+	if legacy {
+		idx := strings.LastIndex(key, "/")
+		if idx <= 0 {
+			return "", ""
+		}
+		version := key[idx+1:]
+		if peer := strings.Index(version, "_"); peer >= 0 {
+			version = version[:peer]
+		}
+		return key[:idx], version
+	}
+
+	return splitSpec(key)
+}
+
+// normalizePnpmVersion 去除依赖版本中的peer后缀
+func normalizePnpmVersion(version string, legacy bool) string {
+	if idx := strings.Index(version, "("); idx >= 0 {
+		version = version[:idx]
+	}
+	if legacy {
+		if idx := strings.Index(version, "_"); idx >= 0 {
+			version = version[:idx]
+		}
+	}
+	return version
+}
+
+// addPnpmDependencies 读取条目中的依赖
+//
+// pnpm只记录依赖解析到的版本，不记录原始的版本范围。
+func addPnpmDependencies(pkg *Package, entry *yamlMap, legacy bool) {
+	for _, section := range []string{"dependencies", "optionalDependencies"} {
+		deps := entry.getMap(section)
+		for _, name := range deps.keysOrNil() {
+			version := normalizePnpmVersion(deps.getString(name), legacy)
+			if strings.HasPrefix(version, "link:") || strings.HasPrefix(version, "file:") {
+				continue
+			}
+			if pkg.Dependencies == nil {
+				pkg.Dependencies = make(map[string]string)
+			}
+			pkg.Dependencies[name] = version
+		}
+	}
+}
+
+// parsePnpmImporter 读取项目直接依赖，并把声明的范围记录到对应的包上
+func parsePnpmImporter(importer *yamlMap, byKey map[string]*Package, legacy bool) *RootDeps {
+	if importer == nil {
+		return nil
+	}
+
+	specifiers := importer.getMap("specifiers")
+	root := &RootDeps{}
+	found := false
+	for _, section := range []string{"dependencies", "devDependencies", "optionalDependencies"} {
+		deps := importer.getMap(section)
+		if deps == nil {
+			continue
+		}
+
+		table := make(map[string]string)
+		for _, name := range deps.keys {
+			var specifier, version string
+			if entry, ok := deps.get(name).(*yamlMap); ok {
+				specifier = entry.getString("specifier")
+				version = entry.getString("version")
+			} else {
+				specifier = specifiers.getString(name)
+				version = deps.getString(name)
+			}
+			version = normalizePnpmVersion(version, legacy)
+			if specifier == "" {
+				specifier = version
+			}
+			table[name] = specifier
+			found = true
+
+			if pkg := byKey[name+"@"+version]; pkg != nil {
+				pkg.Ranges = appendUnique(pkg.Ranges, specifier)
+			}
+		}
+
+		switch section {
+		case "dependencies":
+			root.Dependencies = table
+		case "devDependencies":
+			root.DevDependencies = table
+		case "optionalDependencies":
+			root.OptionalDependencies = table
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return root
+}
+
+// writePnpm 生成lockfileVersion 6.0的pnpm-lock.yaml
+func writePnpm(lock *Lockfile, report *ConversionReport) ([]byte, error) {
+	res := newResolver(lock)
+
+	var builder strings.Builder
+	builder.WriteString("lockfileVersion: '6.0'\n\n")
+	builder.WriteString("settings:\n  autoInstallPeers: true\n  excludeLinksFromLockfile: false\n")
+
+	if lock.Root == nil {
+		report.addLoss("", "root", "source lockfile does not record the project's direct dependencies")
+	} else {
+		sections := []struct {
+			name string
+			deps map[string]string
+		}{
+			{"dependencies", lock.Root.Dependencies},
+			{"optionalDependencies", lock.Root.OptionalDependencies},
+			{"devDependencies", lock.Root.DevDependencies},
+		}
+		for _, section := range sections {
+			if len(section.deps) == 0 {
+				continue
+			}
+			builder.WriteString("\n" + section.name + ":\n")
+			for _, name := range sortedKeys(section.deps) {
+				rng := section.deps[name]
+				pkg := res.resolve(name, rng)
+				if pkg == nil {
+					report.addLoss(name+"@"+rng, "dependencies", "no resolved version found for root dependency")
+					continue
+				}
+				builder.WriteString("  " + quoteYAML(name) + ":\n")
+				builder.WriteString("    specifier: " + quoteYAML(rng) + "\n")
+				builder.WriteString("    version: " + quoteYAML(pkg.Version) + "\n")
+			}
+		}
+	}
+
+	rangesDropped := false
+	if len(lock.Packages) > 0 {
+		builder.WriteString("\npackages:\n")
+	}
+	for _, pkg := range lock.sortedPackages() {
+		builder.WriteString("\n  " + quoteYAML("/"+pkg.Key()) + ":\n")
+		switch {
+		case pkg.Integrity != "":
+			builder.WriteString("    resolution: {integrity: " + pkg.Integrity + "}\n")
+		case pkg.Resolved != "":
+			builder.WriteString("    resolution: {tarball: " + pkg.Resolved + "}\n")
+			report.addLoss(pkg.Key(), "integrity", "no integrity hash available, using tarball resolution")
+		default:
+			builder.WriteString("    resolution: {}\n")
+			report.addLoss(pkg.Key(), "resolution", "neither integrity nor tarball URL available")
+		}
+
+		if len(pkg.Dependencies) > 0 {
+			builder.WriteString("    dependencies:\n")
+			for _, name := range sortedKeys(pkg.Dependencies) {
+				rng := pkg.Dependencies[name]
+				target := res.resolve(name, rng)
+				if target == nil {
+					if !isOptionalDependency(lock, name) {
+						report.addLoss(pkg.Key(), "dependencies", fmt.Sprintf("no resolved version found for %s@%s", name, rng))
+					}
+					continue
+				}
+				if target.Version != rng {
+					rangesDropped = true
+				}
+				builder.WriteString("      " + quoteYAML(name) + ": " + quoteYAML(target.Version) + "\n")
+			}
+		}
+
+		if pkg.Optional {
+			builder.WriteString("    optional: true\n")
+		}
+		builder.WriteString(fmt.Sprintf("    dev: %t\n", pkg.Dev))
+	}
+
+	if rangesDropped {
+		report.addLoss("", "dependencies", "pnpm-lock.yaml records resolved versions only; transitive version ranges were dropped")
+	}
+
+	return []byte(builder.String()), nil
+}
@@ -0,0 +1,230 @@
+package lockfile
+
+import (
+	"strings"
+	"testing"
+)
+
+const testPnpmV6 = `lockfileVersion: '6.0'
+
+settings:
+  autoInstallPeers: true
+  excludeLinksFromLockfile: false
+
+dependencies:
+  a:
+    specifier: ^1.0.0
+    version: 1.2.0
+  react-dom:
+    specifier: ^18.0.0
+    version: 18.2.0(react@18.2.0)
+
+devDependencies:
+  '@scope/tool':
+    specifier: ~3.1.0
+    version: 3.1.4
+
+packages:
+
+  /@scope/tool@3.1.4:
+    resolution: {integrity: sha512-tool}
+    dev: true
+
+  /a@1.2.0:
+    resolution: {integrity: sha512-aaa}
+    dependencies:
+      b: 1.5.0
+    dev: false
+
+  /b@1.5.0:
+    resolution: {integrity: sha512-b15}
+    dev: false
+
+  /react-dom@18.2.0(react@18.2.0):
+    resolution: {integrity: sha512-rd}
+    peerDependencies:
+      react: ^18.2.0
+    dependencies:
+      react: 18.2.0
+    dev: false
+
+  /react@18.2.0:
+    resolution: {integrity: sha512-react}
+    engines: {node: '>=0.10.0'}
+    dev: false
+`
+
+const testPnpmV9 = `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      a:
+        specifier: ^1.0.0
+        version: 1.2.0
+
+packages:
+
+  a@1.2.0:
+    resolution: {integrity: sha512-aaa}
+
+  b@1.5.0:
+    resolution: {integrity: sha512-b15}
+    os: [darwin]
+
+snapshots:
+
+  a@1.2.0:
+    dependencies:
+      b: 1.5.0
+
+  b@1.5.0:
+    optional: true
+`
+
+const testPnpmV5 = `lockfileVersion: 5.4
+
+specifiers:
+  a: ^1.0.0
+
+dependencies:
+  a: 1.2.0
+
+packages:
+
+  /a/1.2.0:
+    resolution: {integrity: sha512-aaa}
+    dependencies:
+      '@scope/b': 1.5.0_react@18.2.0
+    dev: false
+
+  /@scope/b/1.5.0_react@18.2.0:
+    resolution: {integrity: sha512-b15}
+    dev: false
+`
+
+func TestParsePnpmV6(t *testing.T) {
+	lock, err := ParsePnpm([]byte(testPnpmV6))
+	if err != nil {
+		t.Fatalf("ParsePnpm() failed: %v", err)
+	}
+
+	if len(lock.Packages) != 5 {
+		t.Fatalf("Expected 5 packages, got %d", len(lock.Packages))
+	}
+	if lock.Root == nil || lock.Root.Dependencies["react-dom"] != "^18.0.0" {
+		t.Errorf("Expected root dependencies from importer, got %+v", lock.Root)
+	}
+
+	reactDom := lock.Find("react-dom", "18.2.0")
+	if reactDom == nil || reactDom.Dependencies["react"] != "18.2.0" {
+		t.Fatalf("Expected peer suffix to be stripped, got %+v", reactDom)
+	}
+	if len(reactDom.Ranges) != 1 || reactDom.Ranges[0] != "^18.0.0" {
+		t.Errorf("Expected root specifier to be recorded, got %v", reactDom.Ranges)
+	}
+	if tool := lock.Find("@scope/tool", "3.1.4"); tool == nil || !tool.Dev {
+		t.Errorf("Expected dev scoped package, got %+v", tool)
+	}
+}
+
+func TestParsePnpmV9(t *testing.T) {
+	lock, err := ParsePnpm([]byte(testPnpmV9))
+	if err != nil {
+		t.Fatalf("ParsePnpm() failed: %v", err)
+	}
+
+	a := lock.Find("a", "1.2.0")
+	if a == nil || a.Dependencies["b"] != "1.5.0" {
+		t.Fatalf("Expected dependencies from snapshots, got %+v", a)
+	}
+	if b := lock.Find("b", "1.5.0"); b == nil || !b.Optional {
+		t.Errorf("Expected optional flag from snapshots, got %+v", b)
+	}
+	if lock.Root == nil || lock.Root.Dependencies["a"] != "^1.0.0" {
+		t.Errorf("Expected root dependencies from importers, got %+v", lock.Root)
+	}
+}
+
+func TestParsePnpmV5(t *testing.T) {
+	lock, err := ParsePnpm([]byte(testPnpmV5))
+	if err != nil {
+		t.Fatalf("ParsePnpm() failed: %v", err)
+	}
+
+	b := lock.Find("@scope/b", "1.5.0")
+	if b == nil {
+		t.Fatal("Expected @scope/b@1.5.0 with peer suffix stripped")
+	}
+	if a := lock.Find("a", "1.2.0"); a == nil || a.Dependencies["@scope/b"] != "1.5.0" || len(a.Ranges) != 1 {
+		t.Errorf("Unexpected a: %+v", a)
+	}
+}
+
+func TestParsePnpmInvalid(t *testing.T) {
+	if _, err := ParsePnpm([]byte("packages: {}\n")); err == nil {
+		t.Error("Expected error for missing lockfileVersion")
+	}
+
+	_, err := ParsePnpm([]byte("lockfileVersion: '9.0'\npackages:\n  a@1.0.0:\n    resolution: {integrity: 'sha512-x}\n"))
+	if parseErr := expectParseError(t, err, 4, 5); parseErr.Source != "pnpm-lock.yaml" {
+		t.Errorf("Unexpected source %q", parseErr.Source)
+	}
+}
+
+func FuzzParsePnpm(f *testing.F) {
+	f.Add([]byte(testPnpmV5))
+	f.Add([]byte(testPnpmV6))
+	f.Add([]byte(testPnpmV9))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		lock, err := ParsePnpm(data)
+		if err != nil {
+			checkParseError(t, data, err)
+			return
+		}
+		if _, _, err := Convert(lock, FormatPnpm); err != nil {
+			t.Errorf("Convert() failed: %v", err)
+		}
+	})
+}
+
+func TestWritePnpm(t *testing.T) {
+	lock, err := ParseYarn([]byte(testYarnClassic))
+	if err != nil {
+		t.Fatalf("ParseYarn() failed: %v", err)
+	}
+
+	data, report, err := Convert(lock, FormatPnpm)
+	if err != nil {
+		t.Fatalf("Convert() failed: %v", err)
+	}
+
+	content := string(data)
+	for _, expected := range []string{
+		"lockfileVersion: '6.0'",
+		"  /@scope/tool@3.1.4:\n    resolution: {integrity: sha512-tool}",
+		"  /a@1.2.0:\n    resolution: {integrity: sha512-aaa}\n    dependencies:\n      b: 1.5.0\n      fsevents: 2.3.3\n",
+		"  /fsevents@2.3.3:\n    resolution: {integrity: sha512-fse}\n    optional: true\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, content)
+		}
+	}
+
+	var fields []string
+	for _, loss := range report.Losses {
+		fields = append(fields, loss.Field)
+	}
+	joined := strings.Join(fields, ",")
+	if !strings.Contains(joined, "root") || !strings.Contains(joined, "dependencies") {
+		t.Errorf("Expected root and dropped range losses, got %v", report.Losses)
+	}
+
+	roundTrip, err := ParsePnpm(data)
+	if err != nil {
+		t.Fatalf("Failed to parse generated pnpm-lock.yaml: %v", err)
+	}
+	assertSameResolvedSet(t, lock, roundTrip)
+}
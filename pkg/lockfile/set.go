@@ -0,0 +1,234 @@
+package lockfile
+
+import (
+	"slices"
+	"sync"
+)
+
+// Interner 字符串驻留表，相同的字符串只保存一份并分配从0开始的连续ID
+//
+// 分析大量锁文件时，所有PackageSet共用一个Interner，包名和版本号各只保存一次。
+// 可以被多个goroutine同时使用。
+type Interner struct {
+	mu      sync.RWMutex
+	ids     map[string]uint32
+	strings []string
+}
+
+// NewInterner 创建字符串驻留表
+func NewInterner() *Interner {
+	return &Interner{ids: make(map[string]uint32)}
+}
+
+// Intern 返回字符串的ID，不存在时分配新ID
+func (t *Interner) Intern(s string) uint32 {
+	t.mu.RLock()
+	id, ok := t.ids[s]
+	t.mu.RUnlock()
+	if ok {
+		return id
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if id, ok := t.ids[s]; ok {
+		return id
+	}
+	id = uint32(len(t.strings))
+	t.ids[s] = id
+	t.strings = append(t.strings, s)
+	return id
+}
+
+// Lookup 返回已有字符串的ID，不分配新ID
+func (t *Interner) Lookup(s string) (uint32, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	id, ok := t.ids[s]
+	return id, ok
+}
+
+// String 返回ID对应的字符串
+func (t *Interner) String(id uint32) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.strings[id]
+}
+
+// Len 返回驻留的字符串数量
+func (t *Interner) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.strings)
+}
+
+// PackageID 包名和版本号在Interner中的ID组合，高32位为包名，低32位为版本号
+type PackageID uint64
+
+// NewPackageID 组合包名和版本号的ID
+func NewPackageID(name, version uint32) PackageID {
+	return PackageID(uint64(name)<<32 | uint64(version))
+}
+
+// Name 包名的ID
+func (id PackageID) Name() uint32 {
+	return uint32(id >> 32)
+}
+
+// Version 版本号的ID
+func (id PackageID) Version() uint32 {
+	return uint32(id)
+}
+
+// PackageSet 紧凑的已解析包集合，每个name@version占8字节
+//
+// 集合内部是有序的PackageID切片，集合运算按归并方式进行。
+// 参与运算的集合必须来自同一个Interner。
+type PackageSet struct {
+	table *Interner
+	ids   []PackageID
+}
+
+// NewPackageSet 用name@version列表创建集合，pkgs中的每一项为{name, version}
+func NewPackageSet(table *Interner, pkgs ...[2]string) *PackageSet {
+	ids := make([]PackageID, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		ids = append(ids, NewPackageID(table.Intern(pkg[0]), table.Intern(pkg[1])))
+	}
+	return newPackageSet(table, ids)
+}
+
+// PackageSet 返回锁文件中已解析包的紧凑集合
+func (l *Lockfile) PackageSet(table *Interner) *PackageSet {
+	ids := make([]PackageID, 0, len(l.Packages))
+	for _, pkg := range l.Packages {
+		ids = append(ids, NewPackageID(table.Intern(pkg.Name), table.Intern(pkg.Version)))
+	}
+	return newPackageSet(table, ids)
+}
+
+// newPackageSet 排序并去重
+func newPackageSet(table *Interner, ids []PackageID) *PackageSet {
+	slices.Sort(ids)
+	return &PackageSet{table: table, ids: slices.Clip(slices.Compact(ids))}
+}
+
+// Len 返回集合中的包数量
+func (s *PackageSet) Len() int {
+	return len(s.ids)
+}
+
+// IDs 返回有序的PackageID，调用方不能修改返回的切片
+func (s *PackageSet) IDs() []PackageID {
+	return s.ids
+}
+
+// Contains 检查集合中是否有name@version
+func (s *PackageSet) Contains(name, version string) bool {
+	nameID, ok := s.table.Lookup(name)
+	if !ok {
+		return false
+	}
+	versionID, ok := s.table.Lookup(version)
+	if !ok {
+		return false
+	}
+	_, found := slices.BinarySearch(s.ids, NewPackageID(nameID, versionID))
+	return found
+}
+
+// Each 按ID顺序遍历集合中的包，fn返回false时停止
+func (s *PackageSet) Each(fn func(name, version string) bool) {
+	for _, id := range s.ids {
+		if !fn(s.table.String(id.Name()), s.table.String(id.Version())) {
+			return
+		}
+	}
+}
+
+// ResolvedSet 返回与Lockfile.ResolvedSet相同格式的结果
+func (s *PackageSet) ResolvedSet() map[string][]string {
+	set := make(map[string][]string)
+	s.Each(func(name, version string) bool {
+		set[name] = append(set[name], version)
+		return true
+	})
+	for name := range set {
+		slices.Sort(set[name])
+	}
+	return set
+}
+
+// Equal 检查两个集合是否相同
+func (s *PackageSet) Equal(other *PackageSet) bool {
+	s.checkTable(other)
+	return slices.Equal(s.ids, other.ids)
+}
+
+// Union 返回并集
+func (s *PackageSet) Union(other *PackageSet) *PackageSet {
+	s.checkTable(other)
+	ids := make([]PackageID, 0, len(s.ids)+len(other.ids))
+	i, j := 0, 0
+	for i < len(s.ids) && j < len(other.ids) {
+		switch {
+		case s.ids[i] < other.ids[j]:
+			ids = append(ids, s.ids[i])
+			i++
+		case s.ids[i] > other.ids[j]:
+			ids = append(ids, other.ids[j])
+			j++
+		default:
+			ids = append(ids, s.ids[i])
+			i++
+			j++
+		}
+	}
+	ids = append(ids, s.ids[i:]...)
+	ids = append(ids, other.ids[j:]...)
+	return &PackageSet{table: s.table, ids: slices.Clip(ids)}
+}
+
+// Intersect 返回交集
+func (s *PackageSet) Intersect(other *PackageSet) *PackageSet {
+	s.checkTable(other)
+	var ids []PackageID
+	i, j := 0, 0
+	for i < len(s.ids) && j < len(other.ids) {
+		switch {
+		case s.ids[i] < other.ids[j]:
+			i++
+		case s.ids[i] > other.ids[j]:
+			j++
+		default:
+			ids = append(ids, s.ids[i])
+			i++
+			j++
+		}
+	}
+	return &PackageSet{table: s.table, ids: ids}
+}
+
+// Diff 返回在s中但不在other中的包
+func (s *PackageSet) Diff(other *PackageSet) *PackageSet {
+	s.checkTable(other)
+	var ids []PackageID
+	j := 0
+	for _, id := range s.ids {
+		for j < len(other.ids) && other.ids[j] < id {
+			j++
+		}
+		if j < len(other.ids) && other.ids[j] == id {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return &PackageSet{table: s.table, ids: ids}
+}
+
+// checkTable 集合运算要求两个集合使用同一个Interner，否则ID没有可比性
+func (s *PackageSet) checkTable(other *PackageSet) {
+	if s.table != other.table {
+		panic("lockfile: package sets use different interners")
+	}
+}
@@ -0,0 +1,112 @@
+package lockfile
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestInterner(t *testing.T) {
+	table := NewInterner()
+	a := table.Intern("lodash")
+	b := table.Intern("4.17.21")
+	if table.Intern("lodash") != a || a == b {
+		t.Errorf("Expected stable distinct IDs, got %d and %d", a, b)
+	}
+	if table.String(b) != "4.17.21" || table.Len() != 2 {
+		t.Errorf("Unexpected table contents: %q, %d", table.String(b), table.Len())
+	}
+	if _, ok := table.Lookup("react"); ok || table.Len() != 2 {
+		t.Error("Expected Lookup not to intern new strings")
+	}
+
+	// 并发驻留同一批字符串得到相同的ID
+	var wg sync.WaitGroup
+	ids := make([][]uint32, 8)
+	for g := range ids {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				ids[g] = append(ids[g], table.Intern(fmt.Sprintf("pkg-%d", i)))
+			}
+		}(g)
+	}
+	wg.Wait()
+	for g := 1; g < len(ids); g++ {
+		if !reflect.DeepEqual(ids[g], ids[0]) {
+			t.Fatal("Expected concurrent Intern calls to agree on IDs")
+		}
+	}
+	if table.Len() != 102 {
+		t.Errorf("Expected 102 interned strings, got %d", table.Len())
+	}
+}
+
+func TestPackageSet(t *testing.T) {
+	table := NewInterner()
+	lock, err := ParseNpm([]byte(testNpmLockV3))
+	if err != nil {
+		t.Fatalf("ParseNpm() failed: %v", err)
+	}
+
+	set := lock.PackageSet(table)
+	if set.Len() != 4 || !set.Contains("b", "1.5.0") || set.Contains("b", "9.9.9") || set.Contains("missing", "1.0.0") {
+		t.Errorf("Unexpected package set: %d packages", set.Len())
+	}
+	if !reflect.DeepEqual(set.ResolvedSet(), lock.ResolvedSet()) {
+		t.Errorf("Expected ResolvedSet to match the lockfile, got %v", set.ResolvedSet())
+	}
+
+	other := NewPackageSet(table,
+		[2]string{"a", "1.2.0"},
+		[2]string{"b", "2.4.0"},
+		[2]string{"c", "1.0.0"},
+		[2]string{"a", "1.2.0"},
+	)
+	if other.Len() != 3 {
+		t.Errorf("Expected duplicates to be removed, got %d", other.Len())
+	}
+
+	tests := []struct {
+		name     string
+		result   *PackageSet
+		expected []string
+	}{
+		{"union", set.Union(other), []string{"@scope/tool@3.1.4", "a@1.2.0", "b@1.5.0", "b@2.3.0", "b@2.4.0", "c@1.0.0"}},
+		{"intersect", set.Intersect(other), []string{"a@1.2.0"}},
+		{"diff", set.Diff(other), []string{"@scope/tool@3.1.4", "b@1.5.0", "b@2.3.0"}},
+		{"reverse diff", other.Diff(set), []string{"b@2.4.0", "c@1.0.0"}},
+		{"empty", set.Diff(set), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			tt.result.Each(func(name, version string) bool {
+				got = append(got, name+"@"+version)
+				return true
+			})
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if !set.Union(other).Intersect(set).Equal(set) || set.Equal(other) {
+		t.Error("Unexpected Equal results")
+	}
+}
+
+func TestPackageSetDifferentInterners(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic when combining sets from different interners")
+		}
+	}()
+	a := NewPackageSet(NewInterner(), [2]string{"a", "1.0.0"})
+	b := NewPackageSet(NewInterner(), [2]string{"a", "1.0.0"})
+	a.Union(b)
+}
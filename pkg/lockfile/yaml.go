@@ -0,0 +1,426 @@
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/parse"
+)
+
+// yamlMap 保持键顺序的YAML映射
+type yamlMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// newYAMLMap 创建YAML映射
+func newYAMLMap() *yamlMap {
+	return &yamlMap{values: make(map[string]interface{})}
+}
+
+// set 设置键值
+func (m *yamlMap) set(key string, value interface{}) {
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// get 获取键值
+func (m *yamlMap) get(key string) interface{} {
+	if m == nil {
+		return nil
+	}
+	return m.values[key]
+}
+
+// getString 获取字符串值
+func (m *yamlMap) getString(key string) string {
+	value, _ := m.get(key).(string)
+	return value
+}
+
+// getMap 获取嵌套映射
+func (m *yamlMap) getMap(key string) *yamlMap {
+	value, _ := m.get(key).(*yamlMap)
+	return value
+}
+
+// keysOrNil 返回映射的键，映射为nil时返回nil
+func (m *yamlMap) keysOrNil() []string {
+	if m == nil {
+		return nil
+	}
+	return m.keys
+}
+
+// yamlLine 预处理后的YAML行
+type yamlLine struct {
+	indent int
+	text   string
+	number int
+	offset int // 行内第一个非空格字符的字节偏移
+}
+
+// yamlSyntaxError 带位置的YAML语法错误，由parseYAML转换为parse.Error
+type yamlSyntaxError struct {
+	offset int
+	err    error
+}
+
+func (e *yamlSyntaxError) Error() string {
+	return e.err.Error()
+}
+
+// errorAt 创建位于line处的语法错误
+func errorAt(line yamlLine, err error) error {
+	return &yamlSyntaxError{offset: line.offset, err: err}
+}
+
+// parseYAML 解析锁文件使用的YAML子集，错误为带位置信息的*parse.Error
+//
+// 支持块映射、块序列、引号字符串以及单行的流式映射和序列，
+// 足以读取pnpm-lock.yaml和Yarn Berry的yarn.lock，不支持锚点、多行字符串等特性。
+func parseYAML(source string, data []byte) (*yamlMap, error) {
+	if err := parse.CheckSize(source, data); err != nil {
+		return nil, err
+	}
+
+	root, err := parseYAMLDocument(string(data))
+	if err != nil {
+		var syntaxErr *yamlSyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, parse.NewError(source, data, int64(syntaxErr.offset), syntaxErr.err)
+		}
+		return nil, parse.NewError(source, data, 0, err)
+	}
+	return root, nil
+}
+
+// parseYAMLDocument 解析整个文档，根节点必须是映射
+func parseYAMLDocument(content string) (*yamlMap, error) {
+	var lines []yamlLine
+	offset := 0
+	for i, raw := range strings.Split(content, "\n") {
+		lineOffset := offset
+		offset += len(raw) + 1
+
+		raw = strings.TrimRight(raw, " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		if text == "" || strings.HasPrefix(text, "#") || text == "---" {
+			continue
+		}
+		line := yamlLine{
+			indent: len(raw) - len(text),
+			text:   stripYAMLComment(text),
+			number: i + 1,
+			offset: lineOffset + len(raw) - len(text),
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, errorAt(line, fmt.Errorf("tabs are not allowed for indentation"))
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return newYAMLMap(), nil
+	}
+
+	value, next, err := parseYAMLBlock(lines, 0, lines[0].indent, 1)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, errorAt(lines[next], fmt.Errorf("unexpected indentation"))
+	}
+
+	root, ok := value.(*yamlMap)
+	if !ok {
+		return nil, errorAt(lines[0], fmt.Errorf("document root must be a mapping"))
+	}
+	return root, nil
+}
+
+// checkYAMLDepth 检查嵌套深度是否超过parse.DefaultLimits.MaxDepth
+func checkYAMLDepth(depth int) error {
+	if limit := parse.DefaultLimits.MaxDepth; limit > 0 && depth > limit {
+		return fmt.Errorf("%w: exceeds limit of %d", parse.ErrNestingTooDeep, limit)
+	}
+	return nil
+}
+
+// parseYAMLBlock 解析指定缩进的块，depth为块的嵌套深度
+func parseYAMLBlock(lines []yamlLine, start, indent, depth int) (interface{}, int, error) {
+	if err := checkYAMLDepth(depth); err != nil {
+		return nil, start, errorAt(lines[start], err)
+	}
+	if strings.HasPrefix(lines[start].text, "- ") || lines[start].text == "-" {
+		return parseYAMLSequence(lines, start, indent, depth)
+	}
+	return parseYAMLMapping(lines, start, indent, depth)
+}
+
+// parseYAMLMapping 解析块映射
+func parseYAMLMapping(lines []yamlLine, start, indent, depth int) (interface{}, int, error) {
+	result := newYAMLMap()
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, i, errorAt(line, fmt.Errorf("unexpected indentation"))
+		}
+
+		key, rest, err := splitYAMLKey(line.text)
+		if err != nil {
+			return nil, i, errorAt(line, err)
+		}
+
+		i++
+		if rest != "" {
+			value, err := parseYAMLScalar(rest, depth+1)
+			if err != nil {
+				return nil, i, errorAt(line, err)
+			}
+			result.set(key, value)
+			continue
+		}
+
+		// 值在下一层缩进中，序列允许与键同级缩进
+		if i < len(lines) && (lines[i].indent > indent ||
+			lines[i].indent == indent && strings.HasPrefix(lines[i].text, "- ")) {
+			value, next, err := parseYAMLBlock(lines, i, lines[i].indent, depth+1)
+			if err != nil {
+				return nil, next, err
+			}
+			result.set(key, value)
+			i = next
+			continue
+		}
+
+		result.set(key, "")
+	}
+	return result, i, nil
+}
+
+// parseYAMLSequence 解析块序列
+func parseYAMLSequence(lines []yamlLine, start, indent, depth int) (interface{}, int, error) {
+	var result []interface{}
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+		if line.indent != indent || !(strings.HasPrefix(line.text, "- ") || line.text == "-") {
+			break
+		}
+
+		item := strings.TrimSpace(strings.TrimPrefix(line.text, "-"))
+		i++
+		if item == "" {
+			if i < len(lines) && lines[i].indent > indent {
+				value, next, err := parseYAMLBlock(lines, i, lines[i].indent, depth+1)
+				if err != nil {
+					return nil, next, err
+				}
+				result = append(result, value)
+				i = next
+				continue
+			}
+			result = append(result, "")
+			continue
+		}
+
+		value, err := parseYAMLScalar(item, depth+1)
+		if err != nil {
+			return nil, i, errorAt(line, err)
+		}
+		result = append(result, value)
+	}
+	return result, i, nil
+}
+
+// splitYAMLKey 拆分"key: value"
+func splitYAMLKey(text string) (string, string, error) {
+	if text == "" {
+		return "", "", fmt.Errorf("empty mapping entry")
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		end := findClosingQuote(text, 0)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		key, err := unquoteYAML(text[:end+1])
+		if err != nil {
+			return "", "", err
+		}
+		rest := strings.TrimSpace(text[end+1:])
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", fmt.Errorf("expected ':' after key")
+		}
+		return key, strings.TrimSpace(rest[1:]), nil
+	}
+
+	if idx := strings.Index(text, ": "); idx >= 0 {
+		return text[:idx], strings.TrimSpace(text[idx+2:]), nil
+	}
+	if strings.HasSuffix(text, ":") {
+		return text[:len(text)-1], "", nil
+	}
+	return "", "", fmt.Errorf("expected mapping entry, got %q", text)
+}
+
+// parseYAMLScalar 解析标量或单行流式集合，depth为值的嵌套深度
+func parseYAMLScalar(text string, depth int) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	if text != "" && (text[0] == '{' || text[0] == '[') {
+		if err := checkYAMLDepth(depth); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case text == "":
+		return "", nil
+	case text[0] == '{':
+		if !strings.HasSuffix(text, "}") {
+			return nil, fmt.Errorf("unterminated flow mapping")
+		}
+		result := newYAMLMap()
+		for _, part := range splitFlowItems(text[1 : len(text)-1]) {
+			key, rest, err := splitYAMLKey(part)
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseYAMLScalar(rest, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			result.set(key, value)
+		}
+		return result, nil
+	case text[0] == '[':
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence")
+		}
+		result := []interface{}{}
+		for _, part := range splitFlowItems(text[1 : len(text)-1]) {
+			value, err := parseYAMLScalar(part, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	case text[0] == '"' || text[0] == '\'':
+		return unquoteYAML(text)
+	default:
+		return text, nil
+	}
+}
+
+// splitFlowItems 按顶层逗号拆分流式集合内容
+func splitFlowItems(content string) []string {
+	var items []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '"', '\'':
+			if end := findClosingQuote(content, i); end > 0 {
+				i = end
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, strings.TrimSpace(content[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(content[start:]); last != "" {
+		items = append(items, last)
+	}
+	return items
+}
+
+// findClosingQuote 查找与start处引号配对的结束引号
+func findClosingQuote(text string, start int) int {
+	quote := text[start]
+	for i := start + 1; i < len(text); i++ {
+		if quote == '"' && text[i] == '\\' {
+			i++
+			continue
+		}
+		if text[i] == quote {
+			if quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// unquoteYAML 去除YAML字符串的引号
+func unquoteYAML(text string) (string, error) {
+	if len(text) < 2 || text[len(text)-1] != text[0] {
+		return "", fmt.Errorf("invalid quoted string %s", text)
+	}
+	inner := text[1 : len(text)-1]
+	if text[0] == '\'' {
+		return strings.ReplaceAll(inner, "''", "'"), nil
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(inner); i++ {
+		if inner[i] != '\\' || i+1 >= len(inner) {
+			builder.WriteByte(inner[i])
+			continue
+		}
+		i++
+		switch inner[i] {
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		default:
+			builder.WriteByte(inner[i])
+		}
+	}
+	return builder.String(), nil
+}
+
+// stripYAMLComment 去除行尾注释
+func stripYAMLComment(text string) string {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			if end := findClosingQuote(text, i); end > 0 {
+				i = end
+			}
+		case '#':
+			if i > 0 && (text[i-1] == ' ' || text[i-1] == '\t') {
+				return strings.TrimRight(text[:i], " \t")
+			}
+		}
+	}
+	return text
+}
+
+// quoteYAML 在需要时为YAML字符串加引号
+func quoteYAML(value string) string {
+	if value == "" {
+		return "''"
+	}
+	if strings.ContainsAny(value[:1], "!&*{}[]|>'\"%@`#,?:-") ||
+		strings.Contains(value, ": ") || strings.Contains(value, " #") ||
+		value == "true" || value == "false" || value == "null" || value == "~" {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return value
+}
@@ -0,0 +1,329 @@
+package lockfile
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/parse"
+)
+
+// ParseYarn 解析yarn.lock，同时支持Yarn 1的自定义格式和Yarn Berry的YAML格式
+func ParseYarn(data []byte) (*Lockfile, error) {
+	if err := parse.CheckSize("yarn.lock", data); err != nil {
+		return nil, err
+	}
+	if isBerryLockfile(string(data)) {
+		return parseYarnBerry(data)
+	}
+	return parseYarnClassic(data)
+}
+
+// isBerryLockfile 检查是否为Yarn Berry生成的锁文件
+func isBerryLockfile(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "__metadata:") {
+			return true
+		}
+	}
+	return false
+}
+
+// yarnEntry yarn.lock中的一个条目
+type yarnEntry struct {
+	descriptors  []string
+	fields       map[string]string
+	dependencies map[string]string
+	optional     map[string]string
+}
+
+// parseYarnClassic 解析Yarn 1的yarn.lock
+func parseYarnClassic(data []byte) (*Lockfile, error) {
+	var entries []*yarnEntry
+	var current *yarnEntry
+	var section map[string]string
+
+	offset := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		lineOffset := offset
+		offset += len(line) + 1
+
+		line = strings.TrimRight(line, " \r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := len(line) - len(trimmed)
+		switch {
+		case indent == 0:
+			if !strings.HasSuffix(trimmed, ":") {
+				return nil, parse.NewError("yarn.lock", data, int64(lineOffset), fmt.Errorf("expected entry header"))
+			}
+			current = &yarnEntry{
+				descriptors: splitYarnDescriptors(strings.TrimSuffix(trimmed, ":")),
+				fields:      make(map[string]string),
+			}
+			entries = append(entries, current)
+			section = nil
+		case current == nil:
+			return nil, parse.NewError("yarn.lock", data, int64(lineOffset+indent), fmt.Errorf("field outside of entry"))
+		case indent == 2:
+			section = nil
+			if strings.HasSuffix(trimmed, ":") {
+				switch strings.TrimSuffix(trimmed, ":") {
+				case "dependencies":
+					current.dependencies = make(map[string]string)
+					section = current.dependencies
+				case "optionalDependencies":
+					current.optional = make(map[string]string)
+					section = current.optional
+				}
+				continue
+			}
+			key, value := splitYarnField(trimmed)
+			current.fields[key] = value
+		default:
+			if section == nil {
+				continue
+			}
+			key, value := splitYarnField(trimmed)
+			section[key] = value
+		}
+	}
+
+	lock := &Lockfile{Format: FormatYarn}
+	byKey := make(map[string]*Package)
+	for _, entry := range entries {
+		if len(entry.descriptors) == 0 {
+			continue
+		}
+		name, _ := splitSpec(entry.descriptors[0])
+		version := entry.fields["version"]
+		key := name + "@" + version
+
+		pkg, exists := byKey[key]
+		if !exists {
+			pkg = &Package{
+				Name:         name,
+				Version:      version,
+				Resolved:     entry.fields["resolved"],
+				Integrity:    entry.fields["integrity"],
+				Dependencies: mergeDependencies(entry.dependencies, entry.optional),
+			}
+			byKey[key] = pkg
+			lock.Packages = append(lock.Packages, pkg)
+		}
+		for _, descriptor := range entry.descriptors {
+			_, rng := splitSpec(descriptor)
+			pkg.Ranges = appendUnique(pkg.Ranges, rng)
+		}
+		sort.Strings(pkg.Ranges)
+	}
+
+	optional := make(map[string]bool)
+	for _, entry := range entries {
+		for name := range entry.optional {
+			optional[name] = true
+		}
+	}
+	for _, entry := range entries {
+		for name := range entry.dependencies {
+			delete(optional, name)
+		}
+	}
+	markOptional(lock, optional)
+
+	return lock, nil
+}
+
+// splitYarnDescriptors 拆分条目头中逗号分隔的描述符
+func splitYarnDescriptors(header string) []string {
+	var descriptors []string
+	for _, part := range strings.Split(header, ",") {
+		part = strings.Trim(strings.TrimSpace(part), `"`)
+		if part != "" {
+			descriptors = append(descriptors, part)
+		}
+	}
+	return descriptors
+}
+
+// splitYarnField 拆分"key value"形式的字段
+func splitYarnField(text string) (string, string) {
+	var key string
+	if strings.HasPrefix(text, `"`) {
+		end := strings.Index(text[1:], `"`)
+		if end < 0 {
+			return strings.Trim(text, `"`), ""
+		}
+		key = text[1 : end+1]
+		text = text[end+2:]
+	} else {
+		idx := strings.IndexAny(text, " ")
+		if idx < 0 {
+			return text, ""
+		}
+		key = text[:idx]
+		text = text[idx:]
+	}
+	return key, strings.Trim(strings.TrimSpace(text), `"`)
+}
+
+// parseYarnBerry 解析Yarn Berry的yarn.lock
+func parseYarnBerry(data []byte) (*Lockfile, error) {
+	doc, err := parseYAML("yarn.lock", data)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lockfile{Format: FormatYarn}
+	byKey := make(map[string]*Package)
+	optional := make(map[string]bool)
+	for _, header := range doc.keys {
+		if header == "__metadata" {
+			continue
+		}
+		entry, ok := doc.get(header).(*yamlMap)
+		if !ok {
+			continue
+		}
+
+		resolution := entry.getString("resolution")
+		name, reference := splitSpec(resolution)
+		// 工作区和本地链接不是注册表中的包
+		if strings.HasPrefix(reference, "workspace:") || strings.HasPrefix(reference, "link:") || strings.HasPrefix(reference, "portal:") {
+			continue
+		}
+
+		version := entry.getString("version")
+		key := name + "@" + version
+		pkg, exists := byKey[key]
+		if !exists {
+			pkg = &Package{
+				Name:    name,
+				Version: version,
+			}
+			// npm协议的包在Berry锁文件中不记录tarball地址
+			if !strings.HasPrefix(reference, "npm:") {
+				pkg.Resolved = reference
+			}
+			deps := yamlStringMap(entry.getMap("dependencies"))
+			for depName, rng := range deps {
+				deps[depName] = strings.TrimPrefix(rng, "npm:")
+			}
+			pkg.Dependencies = deps
+			byKey[key] = pkg
+			lock.Packages = append(lock.Packages, pkg)
+		}
+
+		for _, descriptor := range splitYarnDescriptors(header) {
+			_, rng := splitSpec(descriptor)
+			pkg.Ranges = appendUnique(pkg.Ranges, strings.TrimPrefix(rng, "npm:"))
+		}
+		sort.Strings(pkg.Ranges)
+
+		if meta := entry.getMap("dependenciesMeta"); meta != nil {
+			for _, depName := range meta.keys {
+				if meta.getMap(depName).getString("optional") == "true" {
+					optional[depName] = true
+				}
+			}
+		}
+	}
+	markOptional(lock, optional)
+
+	return lock, nil
+}
+
+// yamlStringMap 将YAML映射转换为字符串表
+func yamlStringMap(m *yamlMap) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m.keys))
+	for _, key := range m.keys {
+		result[key] = m.getString(key)
+	}
+	return result
+}
+
+// markOptional 将只通过可选依赖引入的包标记为可选
+func markOptional(lock *Lockfile, optional map[string]bool) {
+	for _, pkg := range lock.Packages {
+		if optional[pkg.Name] {
+			pkg.Optional = true
+		}
+	}
+}
+
+// yarnNeedsQuote 与yarn的lockfile序列化规则一致的引号判断
+var yarnNeedsQuote = regexp.MustCompile(`[:\s\n\\",\[\]]`)
+
+// yarnQuote 在需要时为yarn.lock中的字符串加引号
+func yarnQuote(value string) string {
+	if strings.HasPrefix(value, "true") || strings.HasPrefix(value, "false") ||
+		yarnNeedsQuote.MatchString(value) || value == "" ||
+		!(value[0] >= 'a' && value[0] <= 'z' || value[0] >= 'A' && value[0] <= 'Z') {
+		return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	}
+	return value
+}
+
+// writeYarn 生成Yarn 1格式的yarn.lock
+func writeYarn(lock *Lockfile, report *ConversionReport) ([]byte, error) {
+	if lock.Root != nil {
+		report.addLoss("", "root", "yarn.lock does not record the project's direct dependencies; they stay in package.json")
+	}
+
+	type block struct {
+		header string
+		pkg    *Package
+	}
+	var blocks []block
+	for _, pkg := range lock.sortedPackages() {
+		ranges := pkg.Ranges
+		if len(ranges) == 0 {
+			ranges = []string{pkg.Version}
+			report.addLoss(pkg.Key(), "ranges", "requested ranges unknown, pinned to the resolved version")
+		}
+
+		descriptors := make([]string, len(ranges))
+		for i, rng := range ranges {
+			descriptors[i] = yarnQuote(pkg.Name + "@" + rng)
+		}
+		sort.Strings(descriptors)
+		blocks = append(blocks, block{header: strings.Join(descriptors, ", "), pkg: pkg})
+
+		if pkg.Integrity == "" {
+			report.addLoss(pkg.Key(), "integrity", "no integrity hash available")
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return strings.Trim(blocks[i].header, `"`) < strings.Trim(blocks[j].header, `"`)
+	})
+
+	var builder strings.Builder
+	builder.WriteString("# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.\n")
+	builder.WriteString("# yarn lockfile v1\n\n")
+	for _, b := range blocks {
+		builder.WriteString("\n")
+		builder.WriteString(b.header + ":\n")
+		builder.WriteString("  version " + yarnQuote(b.pkg.Version) + "\n")
+		if b.pkg.Resolved != "" {
+			builder.WriteString("  resolved " + yarnQuote(b.pkg.Resolved) + "\n")
+		}
+		if b.pkg.Integrity != "" {
+			builder.WriteString("  integrity " + yarnQuote(b.pkg.Integrity) + "\n")
+		}
+		if len(b.pkg.Dependencies) > 0 {
+			builder.WriteString("  dependencies:\n")
+			for _, name := range sortedKeys(b.pkg.Dependencies) {
+				builder.WriteString("    " + yarnQuote(name) + " " + yarnQuote(b.pkg.Dependencies[name]) + "\n")
+			}
+		}
+	}
+
+	return []byte(builder.String()), nil
+}
@@ -0,0 +1,219 @@
+package lockfile
+
+import (
+	"strings"
+	"testing"
+)
+
+const testYarnClassic = `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@scope/tool@~3.1.0":
+  version "3.1.4"
+  resolved "https://registry.yarnpkg.com/@scope/tool/-/tool-3.1.4.tgz#abc"
+  integrity sha512-tool
+  dependencies:
+    b "^2.1.0"
+
+a@^1.0.0:
+  version "1.2.0"
+  resolved "https://registry.yarnpkg.com/a/-/a-1.2.0.tgz#abc"
+  integrity sha512-aaa
+  dependencies:
+    b "^1.0.0"
+  optionalDependencies:
+    fsevents "^2.0.0"
+
+b@^1.0.0:
+  version "1.5.0"
+  resolved "https://registry.yarnpkg.com/b/-/b-1.5.0.tgz#abc"
+  integrity sha512-b15
+
+b@^2.0.0, b@^2.1.0:
+  version "2.3.0"
+  resolved "https://registry.yarnpkg.com/b/-/b-2.3.0.tgz#abc"
+  integrity sha512-b23
+
+fsevents@^2.0.0:
+  version "2.3.3"
+  resolved "https://registry.yarnpkg.com/fsevents/-/fsevents-2.3.3.tgz#abc"
+  integrity sha512-fse
+`
+
+const testYarnBerry = `# This file is generated by running "yarn install" inside your project.
+
+__metadata:
+  version: 8
+  cacheKey: 10c0
+
+"a@npm:^1.0.0":
+  version: 1.2.0
+  resolution: "a@npm:1.2.0"
+  dependencies:
+    b: "npm:^1.0.0"
+  checksum: 10c0/deadbeef
+  languageName: node
+  linkType: hard
+
+"b@npm:^1.0.0, b@npm:^1.1.0":
+  version: 1.5.0
+  resolution: "b@npm:1.5.0"
+  checksum: 10c0/cafe
+  languageName: node
+  linkType: hard
+
+"demo@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "demo@workspace:."
+  dependencies:
+    a: "npm:^1.0.0"
+  languageName: unknown
+  linkType: soft
+`
+
+func TestParseYarnClassic(t *testing.T) {
+	lock, err := ParseYarn([]byte(testYarnClassic))
+	if err != nil {
+		t.Fatalf("ParseYarn() failed: %v", err)
+	}
+
+	if len(lock.Packages) != 5 {
+		t.Fatalf("Expected 5 packages, got %d", len(lock.Packages))
+	}
+	if lock.Root != nil {
+		t.Error("Expected yarn.lock to carry no root dependency info")
+	}
+
+	b := lock.Find("b", "2.3.0")
+	if b == nil || len(b.Ranges) != 2 || b.Integrity != "sha512-b23" {
+		t.Fatalf("Unexpected b@2.3.0: %+v", b)
+	}
+	tool := lock.Find("@scope/tool", "3.1.4")
+	if tool == nil || tool.Dependencies["b"] != "^2.1.0" {
+		t.Errorf("Expected scoped package with dependencies, got %+v", tool)
+	}
+	if fsevents := lock.Find("fsevents", "2.3.3"); fsevents == nil || !fsevents.Optional {
+		t.Errorf("Expected fsevents to be optional, got %+v", fsevents)
+	}
+}
+
+func TestParseYarnBerry(t *testing.T) {
+	lock, err := ParseYarn([]byte(testYarnBerry))
+	if err != nil {
+		t.Fatalf("ParseYarn() failed: %v", err)
+	}
+
+	if len(lock.Packages) != 2 {
+		t.Fatalf("Expected workspace entry to be skipped, got %d packages", len(lock.Packages))
+	}
+
+	b := lock.Find("b", "1.5.0")
+	if b == nil {
+		t.Fatal("Expected b@1.5.0")
+	}
+	if len(b.Ranges) != 2 || b.Ranges[0] != "^1.0.0" || b.Ranges[1] != "^1.1.0" {
+		t.Errorf("Expected npm: protocol to be stripped from ranges, got %v", b.Ranges)
+	}
+	if a := lock.Find("a", "1.2.0"); a == nil || a.Dependencies["b"] != "^1.0.0" {
+		t.Errorf("Expected a to depend on b@^1.0.0, got %+v", a)
+	}
+}
+
+func TestParseYarnInvalid(t *testing.T) {
+	_, err := ParseYarn([]byte("  version \"1.0.0\"\n"))
+	expectParseError(t, err, 1, 3)
+
+	_, err = ParseYarn([]byte("# yarn lockfile v1\r\n\r\na@^1.0.0:\r\n  version \"1.0.0\"\r\nbroken\r\n"))
+	if parseErr := expectParseError(t, err, 5, 1); parseErr.Source != "yarn.lock" {
+		t.Errorf("Unexpected source %q", parseErr.Source)
+	}
+
+	_, err = ParseYarn([]byte("__metadata:\n  version: 6\n\"a@npm:^1.0.0\":\n    version: 1.0.0\n  resolution: x\n"))
+	expectParseError(t, err, 5, 3)
+}
+
+func FuzzParseYarn(f *testing.F) {
+	f.Add([]byte(testYarnClassic))
+	f.Add([]byte(testYarnBerry))
+	f.Add([]byte("__metadata:\n  version: 6\n\"a@npm:^1\": {version: 1.0.0, dependencies: {b: [1, {c: d}]}}\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		lock, err := ParseYarn(data)
+		if err != nil {
+			checkParseError(t, data, err)
+			return
+		}
+		if _, _, err := Convert(lock, FormatYarn); err != nil {
+			t.Errorf("Convert() failed: %v", err)
+		}
+	})
+}
+
+func TestWriteYarn(t *testing.T) {
+	lock, err := ParseNpm([]byte(testNpmLockV3))
+	if err != nil {
+		t.Fatalf("ParseNpm() failed: %v", err)
+	}
+
+	data, report, err := Convert(lock, FormatYarn)
+	if err != nil {
+		t.Fatalf("Convert() failed: %v", err)
+	}
+
+	content := string(data)
+	for _, expected := range []string{
+		"# yarn lockfile v1",
+		"b@^2.0.0, b@^2.1.0:\n  version \"2.3.0\"",
+		"\"@scope/tool@~3.1.0\":",
+		"  integrity sha512-b15\n",
+		"    b \"^1.0.0\"\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, content)
+		}
+	}
+
+	if report.From != FormatNpm || report.To != FormatYarn {
+		t.Errorf("Unexpected report formats: %s -> %s", report.From, report.To)
+	}
+	if report.Lossless() {
+		t.Error("Expected dropped root dependencies to be reported")
+	}
+
+	roundTrip, err := ParseYarn(data)
+	if err != nil {
+		t.Fatalf("Failed to parse generated yarn.lock: %v", err)
+	}
+	assertSameResolvedSet(t, lock, roundTrip)
+}
+
+func TestYarnQuote(t *testing.T) {
+	tests := map[string]string{
+		"lodash@^4.17.21":    "lodash@^4.17.21",
+		"@babel/core@^7.0.0": `"@babel/core@^7.0.0"`,
+		"1.2.0":              `"1.2.0"`,
+		"sha512-abc+/=":      "sha512-abc+/=",
+		"foo@>= 1.0":         `"foo@>= 1.0"`,
+		"https://x":          `"https://x"`,
+	}
+	for input, expected := range tests {
+		if got := yarnQuote(input); got != expected {
+			t.Errorf("yarnQuote(%q) = %s, expected %s", input, got, expected)
+		}
+	}
+}
+
+func assertSameResolvedSet(t *testing.T, expected, actual *Lockfile) {
+	t.Helper()
+	want := expected.ResolvedSet()
+	got := actual.ResolvedSet()
+	if len(want) != len(got) {
+		t.Fatalf("Expected %d package names, got %d", len(want), len(got))
+	}
+	for name, versions := range want {
+		if strings.Join(versions, ",") != strings.Join(got[name], ",") {
+			t.Errorf("Package %s: expected versions %v, got %v", name, versions, got[name])
+		}
+	}
+}
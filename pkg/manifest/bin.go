@@ -0,0 +1,177 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BinEntry 包提供的一个命令
+type BinEntry struct {
+	Command string `json:"command"` // 命令名，不含路径
+	Path    string `json:"path"`    // 可执行文件相对包目录的路径，使用"/"分隔
+}
+
+// GetBin 获取bin字段的原始JSON，可能是字符串或命令到路径的映射
+func (p *PackageJSON) GetBin() json.RawMessage {
+	return p.data.Bin
+}
+
+// SetBin 设置bin字段，value可以是字符串或map[string]string，为nil时删除
+func (p *PackageJSON) SetBin(value any) error {
+	data, err := rawFieldValue("bin", value)
+	if err != nil {
+		return err
+	}
+	p.data.Bin = data
+	return nil
+}
+
+// GetFiles 获取发布时包含的文件
+func (p *PackageJSON) GetFiles() []string {
+	return p.data.Files
+}
+
+// SetFiles 设置发布时包含的文件
+func (p *PackageJSON) SetFiles(files []string) {
+	p.data.Files = files
+}
+
+// AddFile 添加发布时包含的文件，已存在时不重复添加
+func (p *PackageJSON) AddFile(file string) {
+	for _, f := range p.data.Files {
+		if f == file {
+			return
+		}
+	}
+	p.data.Files = append(p.data.Files, file)
+}
+
+// GetSideEffects 获取sideEffects字段的原始JSON，可能是布尔值或文件列表
+func (p *PackageJSON) GetSideEffects() json.RawMessage {
+	return p.data.SideEffects
+}
+
+// SetSideEffects 设置sideEffects字段，value可以是bool或[]string，为nil时删除
+func (p *PackageJSON) SetSideEffects(value any) error {
+	data, err := rawFieldValue("sideEffects", value)
+	if err != nil {
+		return err
+	}
+	p.data.SideEffects = data
+	return nil
+}
+
+// IsSideEffectFree 包是否声明了"sideEffects": false，打包工具可以移除未使用的模块
+func (p *PackageJSON) IsSideEffectFree() bool {
+	return string(bytes.TrimSpace(p.data.SideEffects)) == "false"
+}
+
+// GetDirectories 获取directories字段
+func (p *PackageJSON) GetDirectories() map[string]string {
+	if p.data.Directories == nil {
+		p.data.Directories = make(map[string]string)
+	}
+	return p.data.Directories
+}
+
+// SetDirectories 设置directories字段
+func (p *PackageJSON) SetDirectories(directories map[string]string) {
+	p.data.Directories = directories
+}
+
+// ResolveBinEntries 返回包安装后链接的命令，按命令名排序
+//
+// 规范化规则与npm相同：bin为字符串时命令名为不含scope的包名；命令名只保留最后一段，
+// 路径去掉开头的"./"并限制在包目录内，为空的项被忽略。没有bin时列出directories.bin目录
+// 中的文件（不含以"."开头的文件），该目录相对package.json所在目录。
+func (p *PackageJSON) ResolveBinEntries() ([]BinEntry, error) {
+	bins := make(map[string]string)
+	bin := bytes.TrimSpace(p.data.Bin)
+	switch {
+	case len(bin) == 0 || string(bin) == "null":
+		if dir := p.data.Directories["bin"]; dir != "" {
+			var err error
+			if bins, err = p.directoryBins(dir); err != nil {
+				return nil, err
+			}
+		}
+	case bin[0] == '"':
+		var target string
+		if err := json.Unmarshal(bin, &target); err != nil {
+			return nil, fmt.Errorf("invalid bin: %w", err)
+		}
+		if p.data.Name == "" {
+			return nil, fmt.Errorf("bin requires a package name when it is a string")
+		}
+		bins[p.data.Name] = target
+	default:
+		if err := json.Unmarshal(bin, &bins); err != nil {
+			return nil, fmt.Errorf("invalid bin: %w", err)
+		}
+	}
+
+	entries := make([]BinEntry, 0, len(bins))
+	for command, target := range bins {
+		command = binCommandName(command)
+		target = binTargetPath(target)
+		if command == "" || target == "" {
+			continue
+		}
+		entries = append(entries, BinEntry{Command: command, Path: target})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Command < entries[j].Command
+	})
+	return entries, nil
+}
+
+// directoryBins 列出directories.bin中的文件，命令名为文件名
+func (p *PackageJSON) directoryBins(dir string) (map[string]string, error) {
+	dir = binTargetPath(dir)
+	if dir == "" {
+		return nil, nil
+	}
+	root := filepath.Join(filepath.Dir(p.filePath), filepath.FromSlash(dir))
+	bins := make(map[string]string)
+	err := filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") && file != root {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		bins[entry.Name()] = path.Join(dir, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directories.bin: %w", err)
+	}
+	return bins, nil
+}
+
+// binCommandName 命令名只保留最后一段，与npm一样把"\"和":"视为路径分隔符
+func binCommandName(command string) string {
+	command = strings.NewReplacer(`\`, "/", ":", "/").Replace(command)
+	return strings.TrimPrefix(path.Join("/", path.Base(command)), "/")
+}
+
+// binTargetPath 把路径规范为相对包目录的形式，".."不能越出包目录
+func binTargetPath(target string) string {
+	return strings.TrimPrefix(path.Join("/", strings.ReplaceAll(target, `\`, "/")), "/")
+}
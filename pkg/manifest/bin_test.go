@@ -0,0 +1,99 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPackageJSONResolveBinEntries(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []BinEntry
+	}{
+		{"string", `{"name": "@scope/tool", "bin": "./bin/cli.js"}`, []BinEntry{{"tool", "bin/cli.js"}}},
+		{"map", `{"name": "tool", "bin": {"b-cmd": "./dist/b.js", "a-cmd": "dist\\a.js"}}`, []BinEntry{{"a-cmd", "dist/a.js"}, {"b-cmd", "dist/b.js"}}},
+		{"unsafe", `{"name": "tool", "bin": {"../../evil": "../../../etc/passwd", "": "./x.js", "win:cmd": "./y.js"}}`, []BinEntry{{"cmd", "y.js"}, {"evil", "etc/passwd"}}},
+		{"none", `{"name": "tool"}`, []BinEntry{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := loadTestPackageJSON(t, tt.content)
+			entries, err := pkg.ResolveBinEntries()
+			if err != nil {
+				t.Fatalf("ResolveBinEntries() failed: %v", err)
+			}
+			if !reflect.DeepEqual(entries, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, entries)
+			}
+		})
+	}
+
+	pkg := loadTestPackageJSON(t, `{"bin": "./cli.js"}`)
+	if _, err := pkg.ResolveBinEntries(); err == nil {
+		t.Error("Expected error for string bin without name")
+	}
+}
+
+func TestPackageJSONDirectoriesBin(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"scripts/run", "scripts/sub/deploy", "scripts/.hidden"} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "package.json")
+	if err := os.WriteFile(path, []byte(`{"name": "tool", "directories": {"bin": "./scripts"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg := NewPackageJSON(path)
+	if err := pkg.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	entries, err := pkg.ResolveBinEntries()
+	if err != nil {
+		t.Fatalf("ResolveBinEntries() failed: %v", err)
+	}
+	expected := []BinEntry{{"deploy", "scripts/sub/deploy"}, {"run", "scripts/run"}}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %v, got %v", expected, entries)
+	}
+	if pkg.GetDirectories()["bin"] != "./scripts" {
+		t.Errorf("Unexpected directories: %v", pkg.GetDirectories())
+	}
+}
+
+func TestPackageJSONFilesAndSideEffects(t *testing.T) {
+	pkg := loadTestPackageJSON(t, `{"name": "lib", "version": "1.0.0", "files": ["dist"], "sideEffects": ["*.css"]}`)
+	if pkg.IsSideEffectFree() {
+		t.Error("Expected side effects")
+	}
+	if string(pkg.GetSideEffects()) != `["*.css"]` {
+		t.Errorf("Unexpected sideEffects: %s", pkg.GetSideEffects())
+	}
+
+	pkg.AddFile("dist")
+	pkg.AddFile("README.md")
+	if !reflect.DeepEqual(pkg.GetFiles(), []string{"dist", "README.md"}) {
+		t.Errorf("Unexpected files: %v", pkg.GetFiles())
+	}
+	if err := pkg.SetSideEffects(false); err != nil {
+		t.Fatalf("SetSideEffects() failed: %v", err)
+	}
+	if !pkg.IsSideEffectFree() {
+		t.Error("Expected side effect free")
+	}
+	if err := pkg.SetBin(map[string]string{"lib": "./cli.js"}); err != nil {
+		t.Fatalf("SetBin() failed: %v", err)
+	}
+	if entries, _ := pkg.ResolveBinEntries(); len(entries) != 1 || entries[0].Command != "lib" {
+		t.Errorf("Unexpected bin entries: %v", entries)
+	}
+}
@@ -0,0 +1,257 @@
+package manifest
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// dependencyFields 按类型比较的依赖字段
+var dependencyFields = []string{"dependencies", "devDependencies", "optionalDependencies", "peerDependencies"}
+
+// DependencyChange 一个依赖的变化，From为空表示新增，To为空表示删除
+type DependencyChange struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // dependencies、devDependencies、optionalDependencies或peerDependencies
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// ScriptChange 一个脚本的变化，From为空表示新增，To为空表示删除
+type ScriptChange struct {
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// FieldChange 依赖和脚本之外的一个顶层字段的变化，值为原始JSON，From为nil表示新增，To为nil表示删除
+type FieldChange struct {
+	Key  string          `json:"key"`
+	From json.RawMessage `json:"from,omitempty"`
+	To   json.RawMessage `json:"to,omitempty"`
+}
+
+// PackageJSONDiff 两个package.json之间的差异
+type PackageJSONDiff struct {
+	Dependencies []DependencyChange `json:"dependencies,omitempty"` // 按类型和名称排序
+	Scripts      []ScriptChange     `json:"scripts,omitempty"`      // 按名称排序
+	Fields       []FieldChange      `json:"fields,omitempty"`       // 按字段在文件中的顺序
+}
+
+// Empty 是否没有差异
+func (d *PackageJSONDiff) Empty() bool {
+	return len(d.Dependencies) == 0 && len(d.Scripts) == 0 && len(d.Fields) == 0
+}
+
+// MergeConflict 应用差异时当前值既不是差异的原值也不是新值
+type MergeConflict struct {
+	Field  string `json:"field"`          // 依赖类型、scripts或顶层字段名
+	Name   string `json:"name,omitempty"` // 依赖名或脚本名，顶层字段为空
+	Base   string `json:"base,omitempty"` // 差异的原值，为空表示原来不存在
+	Ours   string `json:"ours,omitempty"` // 当前值，为空表示不存在
+	Theirs string `json:"theirs,omitempty"`
+}
+
+// DiffPackageJSON 比较a和b，返回把a变为b的差异
+//
+// 依赖按类型逐项比较，脚本逐项比较，其他顶层字段（包括自定义字段）按JSON的值比较，
+// 对象中键的顺序不同不算差异。
+func DiffPackageJSON(a, b *PackageJSON) (*PackageJSONDiff, error) {
+	diff := &PackageJSONDiff{}
+	for _, field := range dependencyFields {
+		from, to := a.dependencyMap(field), b.dependencyMap(field)
+		for _, name := range changedKeys(from, to) {
+			diff.Dependencies = append(diff.Dependencies, DependencyChange{Name: name, Type: field, From: from[name], To: to[name]})
+		}
+	}
+	for _, name := range changedKeys(a.data.Scripts, b.data.Scripts) {
+		diff.Scripts = append(diff.Scripts, ScriptChange{Name: name, From: a.data.Scripts[name], To: b.data.Scripts[name]})
+	}
+
+	from, err := a.members()
+	if err != nil {
+		return nil, err
+	}
+	to, err := b.members()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(from)+len(to))
+	for _, member := range from {
+		keys = append(keys, member.key)
+	}
+	for _, member := range to {
+		if _, ok := findMember(from, member.key); !ok {
+			keys = append(keys, member.key)
+		}
+	}
+	for _, key := range keys {
+		if key == "scripts" || isDependencyField(key) {
+			continue
+		}
+		oldValue, _ := findMember(from, key)
+		newValue, _ := findMember(to, key)
+		if !jsonEqual(oldValue, newValue) {
+			diff.Fields = append(diff.Fields, FieldChange{Key: key, From: compactJSON(oldValue), To: compactJSON(newValue)})
+		}
+	}
+	return diff, nil
+}
+
+// ApplyDiff 把差异应用到p，用于把一个package.json上的修改合并到另一个
+//
+// 每一项只在当前值等于差异的原值时修改；当前值已经等于新值时跳过；
+// 两者都不等时不修改并作为冲突返回。返回的冲突按差异中的顺序排列。
+func (p *PackageJSON) ApplyDiff(diff *PackageJSONDiff) []MergeConflict {
+	var conflicts []MergeConflict
+	for _, change := range diff.Dependencies {
+		deps := p.dependencyMap(change.Type)
+		current := deps[change.Name]
+		switch current {
+		case change.To:
+		case change.From:
+			p.setDependency(change.Type, change.Name, change.To)
+		default:
+			conflicts = append(conflicts, MergeConflict{Field: change.Type, Name: change.Name, Base: change.From, Ours: current, Theirs: change.To})
+		}
+	}
+	for _, change := range diff.Scripts {
+		current := p.data.Scripts[change.Name]
+		switch current {
+		case change.To:
+		case change.From:
+			if change.To == "" {
+				p.RemoveScript(change.Name)
+			} else {
+				p.AddScript(change.Name, change.To)
+			}
+		default:
+			conflicts = append(conflicts, MergeConflict{Field: "scripts", Name: change.Name, Base: change.From, Ours: current, Theirs: change.To})
+		}
+	}
+	for _, change := range diff.Fields {
+		current, _ := p.Get(change.Key)
+		if jsonEqual(current, change.To) {
+			continue
+		}
+		if jsonEqual(current, change.From) {
+			if change.To == nil {
+				p.Delete(change.Key)
+				continue
+			}
+			// 新值不符合类型化字段的类型时同样作为冲突
+			if p.Set(change.Key, change.To) == nil {
+				continue
+			}
+		}
+		conflicts = append(conflicts, MergeConflict{Field: change.Key, Base: string(change.From), Ours: string(compactJSON(current)), Theirs: string(change.To)})
+	}
+	return conflicts
+}
+
+// MergePackageJSON 三方合并：把base到theirs的修改应用到ours，返回无法自动合并的冲突
+//
+// ours在原处修改，调用方检查冲突后再Save。
+func MergePackageJSON(base, ours, theirs *PackageJSON) ([]MergeConflict, error) {
+	diff, err := DiffPackageJSON(base, theirs)
+	if err != nil {
+		return nil, err
+	}
+	return ours.ApplyDiff(diff), nil
+}
+
+// dependencyMap 返回指定类型的依赖，类型无效时返回nil
+func (p *PackageJSON) dependencyMap(field string) map[string]string {
+	switch field {
+	case "dependencies":
+		return p.data.Dependencies
+	case "devDependencies":
+		return p.data.DevDeps
+	case "optionalDependencies":
+		return p.data.OptionalDeps
+	case "peerDependencies":
+		return p.data.PeerDeps
+	}
+	return nil
+}
+
+// setDependency 设置或删除（version为空时）指定类型的依赖
+func (p *PackageJSON) setDependency(field, name, version string) {
+	switch field {
+	case "dependencies":
+		if version == "" {
+			p.RemoveDependency(name)
+		} else {
+			p.AddDependency(name, version)
+		}
+	case "devDependencies":
+		if version == "" {
+			p.RemoveDevDependency(name)
+		} else {
+			p.AddDevDependency(name, version)
+		}
+	case "optionalDependencies":
+		if version == "" {
+			p.RemoveOptionalDependency(name)
+		} else {
+			p.AddOptionalDependency(name, version)
+		}
+	case "peerDependencies":
+		if version == "" {
+			p.RemovePeerDependency(name)
+		} else {
+			p.AddPeerDependency(name, version)
+		}
+	}
+}
+
+// isDependencyField 是否为按项比较的依赖字段
+func isDependencyField(key string) bool {
+	for _, field := range dependencyFields {
+		if key == field {
+			return true
+		}
+	}
+	return false
+}
+
+// changedKeys 返回from和to中值不同的键，按名称排序
+func changedKeys(from, to map[string]string) []string {
+	var keys []string
+	for key, value := range from {
+		if newValue, ok := to[key]; !ok || newValue != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonEqual 两个JSON值是否相等，nil表示不存在
+func jsonEqual(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// compactJSON 去掉JSON中的空白，nil保持nil
+func compactJSON(value json.RawMessage) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	data, err := marshalJSON(value)
+	if err != nil {
+		return value
+	}
+	return data
+}
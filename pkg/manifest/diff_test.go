@@ -0,0 +1,123 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDiffPackageJSON(t *testing.T) {
+	a := loadTestPackageJSON(t, `{
+  "name": "app",
+  "version": "1.0.0",
+  "engines": {"node": ">=18", "npm": ">=9"},
+  "scripts": {"build": "tsc", "lint": "eslint ."},
+  "dependencies": {"lodash": "^4.17.20", "left-pad": "^1.3.0"},
+  "devDependencies": {"typescript": "^5.0.0"},
+  "husky": {"hooks": {}}
+}`)
+	b := loadTestPackageJSON(t, `{
+  "name": "app",
+  "version": "1.1.0",
+  "engines": {"npm": ">=9", "node": ">=18"},
+  "scripts": {"build": "tsc -p .", "test": "vitest"},
+  "dependencies": {"lodash": "^4.17.21", "zod": "^3.22.0"},
+  "devDependencies": {"typescript": "^5.0.0"},
+  "type": "module"
+}`)
+
+	diff, err := DiffPackageJSON(a, b)
+	if err != nil {
+		t.Fatalf("DiffPackageJSON() failed: %v", err)
+	}
+	expectedDeps := []DependencyChange{
+		{Name: "left-pad", Type: "dependencies", From: "^1.3.0"},
+		{Name: "lodash", Type: "dependencies", From: "^4.17.20", To: "^4.17.21"},
+		{Name: "zod", Type: "dependencies", To: "^3.22.0"},
+	}
+	if !reflect.DeepEqual(diff.Dependencies, expectedDeps) {
+		t.Errorf("Unexpected dependency changes: %+v", diff.Dependencies)
+	}
+	expectedScripts := []ScriptChange{
+		{Name: "build", From: "tsc", To: "tsc -p ."},
+		{Name: "lint", From: "eslint ."},
+		{Name: "test", To: "vitest"},
+	}
+	if !reflect.DeepEqual(diff.Scripts, expectedScripts) {
+		t.Errorf("Unexpected script changes: %+v", diff.Scripts)
+	}
+	expectedFields := []FieldChange{
+		{Key: "version", From: json.RawMessage(`"1.0.0"`), To: json.RawMessage(`"1.1.0"`)},
+		{Key: "husky", From: json.RawMessage(`{"hooks":{}}`)},
+		{Key: "type", To: json.RawMessage(`"module"`)},
+	}
+	if !reflect.DeepEqual(diff.Fields, expectedFields) {
+		t.Errorf("Unexpected field changes: %+v", diff.Fields)
+	}
+
+	if diff, _ := DiffPackageJSON(a, a); !diff.Empty() {
+		t.Errorf("Expected empty diff, got %+v", diff)
+	}
+}
+
+func TestMergePackageJSON(t *testing.T) {
+	base := loadTestPackageJSON(t, `{"name": "app", "version": "1.0.0", "dependencies": {"lodash": "^4.17.20", "axios": "^1.5.0"}, "scripts": {"build": "tsc"}}`)
+	theirs := loadTestPackageJSON(t, `{"name": "app", "version": "1.0.0", "dependencies": {"lodash": "^4.17.21", "axios": "^1.6.0", "zod": "^3.22.0"}, "scripts": {"build": "tsc"}, "license": "MIT"}`)
+	ours := loadTestPackageJSON(t, `{
+  "name": "app",
+  "version": "1.0.0",
+  "dependencies": {
+    "axios": "^1.5.2",
+    "lodash": "^4.17.20",
+    "express": "^4.18.0"
+  },
+  "scripts": {
+    "build": "tsc -b"
+  },
+  "license": "MIT"
+}`)
+
+	conflicts, err := MergePackageJSON(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("MergePackageJSON() failed: %v", err)
+	}
+	expected := []MergeConflict{{Field: "dependencies", Name: "axios", Base: "^1.5.0", Ours: "^1.5.2", Theirs: "^1.6.0"}}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("Unexpected conflicts: %+v", conflicts)
+	}
+	if err := ours.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	content := `{
+  "name": "app",
+  "version": "1.0.0",
+  "dependencies": {
+    "axios": "^1.5.2",
+    "lodash": "^4.17.21",
+    "express": "^4.18.0",
+    "zod": "^3.22.0"
+  },
+  "scripts": {
+    "build": "tsc -b"
+  },
+  "license": "MIT"
+}`
+	if data, _ := os.ReadFile(ours.filePath); string(data) != content {
+		t.Errorf("Unexpected package.json:\n%s", data)
+	}
+
+	// 字段冲突和删除
+	pkg := loadTestPackageJSON(t, `{"name": "app", "version": "2.0.0", "private": true, "husky": {"hooks": {}}}`)
+	conflicts = pkg.ApplyDiff(&PackageJSONDiff{Fields: []FieldChange{
+		{Key: "version", From: json.RawMessage(`"1.0.0"`), To: json.RawMessage(`"1.1.0"`)},
+		{Key: "husky", From: json.RawMessage(`{"hooks": {}}`)},
+		{Key: "private", From: json.RawMessage(`true`), To: json.RawMessage(`"yes"`)},
+	}})
+	if len(conflicts) != 2 || conflicts[0].Field != "version" || conflicts[0].Ours != `"2.0.0"` || conflicts[1].Field != "private" {
+		t.Errorf("Unexpected conflicts: %+v", conflicts)
+	}
+	if _, ok := pkg.Get("husky"); ok {
+		t.Error("Expected husky to be removed")
+	}
+}
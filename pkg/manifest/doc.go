@@ -0,0 +1,6 @@
+// Package manifest 定义package.json的读写和校验
+//
+// 该包和pkg/parse、pkg/semver、pkg/lockfile、pkg/npmiface一样是纯数据包：只依赖标准库，
+// 不执行命令也不访问网络，可以用GOOS=js GOARCH=wasm或TinyGo编译，在浏览器中分析
+// package.json和锁文件。需要执行npm的功能在pkg/npm中，那里的PackageJSON是这里的类型别名。
+package manifest
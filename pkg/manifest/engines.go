@@ -0,0 +1,126 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+)
+
+// EngineMismatch 不满足engines要求的运行时
+type EngineMismatch struct {
+	Engine   string `json:"engine"`   // 运行时名称，node或npm
+	Required string `json:"required"` // engines中声明的版本范围
+	Actual   string `json:"actual"`   // 当前运行时的版本
+}
+
+// EnginesError 当前运行时不满足package.json的engines要求
+type EnginesError struct {
+	Mismatches []EngineMismatch `json:"mismatches"`
+}
+
+func (e *EnginesError) Error() string {
+	parts := make([]string, len(e.Mismatches))
+	for i, mismatch := range e.Mismatches {
+		parts[i] = fmt.Sprintf("%s %s does not satisfy %q", mismatch.Engine, mismatch.Actual, mismatch.Required)
+	}
+	return "unsupported engine: " + strings.Join(parts, "; ")
+}
+
+// GetEngines 获取engines，键为运行时名称，值为版本范围
+func (p *PackageJSON) GetEngines() map[string]string {
+	if p.data.Engines == nil {
+		p.data.Engines = make(map[string]string)
+	}
+	return p.data.Engines
+}
+
+// GetEngine 获取单个运行时的版本范围，例如GetEngine("node")，没有声明时返回空字符串
+func (p *PackageJSON) GetEngine(name string) string {
+	return p.data.Engines[name]
+}
+
+// SetEngine 设置单个运行时的版本范围，rng为空时删除该运行时
+func (p *PackageJSON) SetEngine(name, rng string) {
+	if rng == "" {
+		delete(p.data.Engines, name)
+		return
+	}
+	if p.data.Engines == nil {
+		p.data.Engines = make(map[string]string)
+	}
+	p.data.Engines[name] = rng
+}
+
+// GetOS 获取支持的操作系统列表
+func (p *PackageJSON) GetOS() []string {
+	return p.data.OS
+}
+
+// SetOS 设置支持的操作系统列表，例如[]string{"darwin", "linux"}或[]string{"!win32"}
+func (p *PackageJSON) SetOS(os []string) {
+	p.data.OS = os
+}
+
+// GetCPU 获取支持的CPU架构列表
+func (p *PackageJSON) GetCPU() []string {
+	return p.data.CPU
+}
+
+// SetCPU 设置支持的CPU架构列表，例如[]string{"x64", "arm64"}
+func (p *PackageJSON) SetCPU(cpu []string) {
+	p.data.CPU = cpu
+}
+
+// GetPackageManager 获取packageManager，例如"pnpm@9.1.0"
+func (p *PackageJSON) GetPackageManager() string {
+	return p.data.PackageManager
+}
+
+// SetPackageManager 设置packageManager
+func (p *PackageJSON) SetPackageManager(packageManager string) {
+	p.data.PackageManager = packageManager
+}
+
+// ParsePackageManager 把packageManager拆分为包管理器名称和版本，忽略"+"之后的哈希
+//
+// 例如"pnpm@9.1.0+sha512.abc"返回"pnpm"和"9.1.0"，格式不是name@version时返回错误。
+func ParsePackageManager(value string) (name, version string, err error) {
+	name, version, ok := strings.Cut(value, "@")
+	version, _, _ = strings.Cut(version, "+")
+	if !ok || name == "" || !semver.Valid(version) {
+		return "", "", npmiface.NewValidationError("packageManager", value, "expected name@version")
+	}
+	return name, version, nil
+}
+
+// CheckEngines 检查当前的Node.js和npm版本是否满足engines.node和engines.npm
+//
+// 版本为空时不检查对应的运行时，engines中没有声明的运行时也不检查。
+// 声明的范围无法解析时返回ValidationError，不满足时返回列出所有不满足项的*EnginesError。
+func (p *PackageJSON) CheckEngines(nodeVersion, npmVersion string) error {
+	var mismatches []EngineMismatch
+	for _, runtime := range [][2]string{{"node", nodeVersion}, {"npm", npmVersion}} {
+		engine, version := runtime[0], runtime[1]
+		required := p.data.Engines[engine]
+		if version == "" || required == "" {
+			continue
+		}
+		rng, err := semver.ParseRange(required)
+		if err != nil {
+			return npmiface.NewValidationError("engines."+engine, required, "invalid version range")
+		}
+		v, err := semver.Parse(version)
+		if err != nil {
+			return npmiface.NewValidationError(engine, version, "invalid version")
+		}
+		if !rng.Contains(v) {
+			mismatches = append(mismatches, EngineMismatch{Engine: engine, Required: required, Actual: version})
+		}
+	}
+	if len(mismatches) > 0 {
+		return &EnginesError{Mismatches: mismatches}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package manifest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+)
+
+func TestPackageJSONEngines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	content := `{
+  "name": "app",
+  "version": "1.0.0",
+  "engines": {
+    "npm": ">=9",
+    "node": ">=18.17.0 <23"
+  },
+  "os": [
+    "darwin",
+    "linux"
+  ],
+  "cpu": [
+    "!arm"
+  ],
+  "packageManager": "pnpm@9.1.0+sha512.abc"
+}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg := NewPackageJSON(path)
+	if err := pkg.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if pkg.GetEngine("node") != ">=18.17.0 <23" || pkg.GetEngines()["npm"] != ">=9" {
+		t.Errorf("Unexpected engines: %v", pkg.GetEngines())
+	}
+	if !reflect.DeepEqual(pkg.GetOS(), []string{"darwin", "linux"}) || !reflect.DeepEqual(pkg.GetCPU(), []string{"!arm"}) {
+		t.Errorf("Unexpected os/cpu: %v %v", pkg.GetOS(), pkg.GetCPU())
+	}
+	name, version, err := ParsePackageManager(pkg.GetPackageManager())
+	if err != nil || name != "pnpm" || version != "9.1.0" {
+		t.Errorf("ParsePackageManager() = %q, %q, %v", name, version, err)
+	}
+	if _, _, err := ParsePackageManager("pnpm"); err == nil {
+		t.Error("Expected error for packageManager without version")
+	}
+
+	tests := []struct {
+		node, npm  string
+		mismatches []string
+	}{
+		{"20.11.1", "10.2.4", nil},
+		{"v18.17.0", "", nil},
+		{"", "", nil},
+		{"16.20.2", "10.2.4", []string{"node"}},
+		{"23.0.0", "8.19.4", []string{"node", "npm"}},
+	}
+	for _, tt := range tests {
+		err := pkg.CheckEngines(tt.node, tt.npm)
+		var enginesErr *EnginesError
+		if tt.mismatches == nil {
+			if err != nil {
+				t.Errorf("CheckEngines(%q, %q) failed: %v", tt.node, tt.npm, err)
+			}
+			continue
+		}
+		if !errors.As(err, &enginesErr) {
+			t.Errorf("CheckEngines(%q, %q) = %v, expected EnginesError", tt.node, tt.npm, err)
+			continue
+		}
+		var engines []string
+		for _, mismatch := range enginesErr.Mismatches {
+			engines = append(engines, mismatch.Engine)
+		}
+		if !reflect.DeepEqual(engines, tt.mismatches) {
+			t.Errorf("CheckEngines(%q, %q) mismatches = %v, expected %v", tt.node, tt.npm, engines, tt.mismatches)
+		}
+	}
+
+	var validationErr *npmiface.ValidationError
+	if err := pkg.CheckEngines("latest", ""); !errors.As(err, &validationErr) {
+		t.Errorf("Expected ValidationError for invalid version, got %v", err)
+	}
+	pkg.SetEngine("node", "not a range")
+	if err := pkg.CheckEngines("20.0.0", ""); !errors.As(err, &validationErr) || validationErr.Field != "engines.node" {
+		t.Errorf("Expected ValidationError for invalid range, got %v", err)
+	}
+
+	// 修改后保留engines中原有的键顺序
+	pkg.SetEngine("node", ">=20")
+	pkg.SetEngine("pnpm", ">=9")
+	pkg.SetOS(nil)
+	pkg.SetPackageManager("pnpm@9.4.0")
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	expected := `{
+  "name": "app",
+  "version": "1.0.0",
+  "engines": {
+    "npm": ">=9",
+    "node": ">=20",
+    "pnpm": ">=9"
+  },
+  "cpu": [
+    "!arm"
+  ],
+  "packageManager": "pnpm@9.4.0"
+}`
+	if data, _ := os.ReadFile(path); string(data) != expected {
+		t.Errorf("Unexpected package.json:\n%s", data)
+	}
+}
@@ -0,0 +1,359 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrPathNotExported exports中没有导出请求的子路径，对应Node.js的ERR_PACKAGE_PATH_NOT_EXPORTED
+	ErrPathNotExported = errors.New("package path not exported")
+	// ErrImportNotDefined imports中没有定义请求的说明符，对应Node.js的ERR_PACKAGE_IMPORT_NOT_DEFINED
+	ErrImportNotDefined = errors.New("package import not defined")
+	// ErrInvalidPackageTarget exports或imports中的目标不合法，例如不以"./"开头或包含".."
+	ErrInvalidPackageTarget = errors.New("invalid package target")
+)
+
+// GetModule 获取module字段
+func (p *PackageJSON) GetModule() string {
+	return p.data.Module
+}
+
+// SetModule 设置module字段
+func (p *PackageJSON) SetModule(module string) {
+	p.data.Module = module
+}
+
+// GetTypes 获取类型声明入口，没有types时读取旧的typings字段
+func (p *PackageJSON) GetTypes() string {
+	if p.data.Types != "" {
+		return p.data.Types
+	}
+	var typings string
+	if value, ok := p.Get("typings"); ok {
+		json.Unmarshal(value, &typings)
+	}
+	return typings
+}
+
+// SetTypes 设置types字段
+func (p *PackageJSON) SetTypes(types string) {
+	p.data.Types = types
+}
+
+// GetBrowser 获取browser字段的原始JSON，可能是字符串或文件替换表
+func (p *PackageJSON) GetBrowser() json.RawMessage {
+	return p.data.Browser
+}
+
+// SetBrowser 设置browser字段，value为nil时删除
+func (p *PackageJSON) SetBrowser(value any) error {
+	data, err := rawFieldValue("browser", value)
+	if err != nil {
+		return err
+	}
+	p.data.Browser = data
+	return nil
+}
+
+// GetExports 获取exports字段的原始JSON
+func (p *PackageJSON) GetExports() json.RawMessage {
+	return p.data.Exports
+}
+
+// SetExports 设置exports字段，value可以是字符串、数组或按顺序编码的json.RawMessage，为nil时删除
+//
+// 条件的顺序决定匹配的优先级，而map编码时按键排序，需要控制条件顺序时传入json.RawMessage。
+func (p *PackageJSON) SetExports(value any) error {
+	data, err := rawFieldValue("exports", value)
+	if err != nil {
+		return err
+	}
+	p.data.Exports = data
+	return nil
+}
+
+// GetImports 获取imports字段的原始JSON
+func (p *PackageJSON) GetImports() json.RawMessage {
+	return p.data.Imports
+}
+
+// SetImports 设置imports字段，value的含义与SetExports相同
+func (p *PackageJSON) SetImports(value any) error {
+	data, err := rawFieldValue("imports", value)
+	if err != nil {
+		return err
+	}
+	p.data.Imports = data
+	return nil
+}
+
+// rawFieldValue 把value编码为字段的原始JSON，nil返回nil
+func rawFieldValue(field string, value any) (json.RawMessage, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case json.RawMessage:
+		if !json.Valid(v) {
+			return nil, fmt.Errorf("invalid value for %s: invalid JSON", field)
+		}
+		return v, nil
+	}
+	data, err := marshalJSON(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", field, err)
+	}
+	return data, nil
+}
+
+// ResolveExport 按Node.js的exports解析算法返回子路径在给定条件下加载的文件
+//
+// subpath为"."或空表示包的主入口，其他子路径可以写成"./feature"或"feature"。conditions是
+// 启用的条件集合，例如Node.js的ESM加载器为[]string{"node", "import"}；优先级由exports中
+// 条件的书写顺序决定，"default"总是匹配。返回的路径相对包目录，以"./"开头。
+//
+// 没有exports时按旧的入口字段解析：主入口依次使用conditions中的types、browser（字符串）、
+// module对应的字段，然后是main，都没有时为"./index.js"；其他子路径原样返回。
+// 子路径没有导出时返回ErrPathNotExported。
+func (p *PackageJSON) ResolveExport(subpath string, conditions []string) (string, error) {
+	subpath = normalizeSubpath(subpath)
+	if len(bytes.TrimSpace(p.data.Exports)) == 0 {
+		if subpath == "." {
+			return p.legacyEntry(conditions), nil
+		}
+		return subpath, nil
+	}
+
+	exports, err := normalizeExports(p.data.Exports)
+	if err != nil {
+		return "", err
+	}
+	target, err := resolvePackageMap(subpath, exports, conditions, false)
+	if err != nil {
+		return "", err
+	}
+	if target == "" {
+		return "", fmt.Errorf("%w: %s", ErrPathNotExported, subpath)
+	}
+	return target, nil
+}
+
+// ResolveImport 按Node.js的imports解析算法返回以#开头的说明符在给定条件下加载的目标
+//
+// 目标可能是包内以"./"开头的文件，也可能是其他包的说明符，例如"#dep": "dep-polyfill"。
+// 没有定义时返回ErrImportNotDefined。
+func (p *PackageJSON) ResolveImport(specifier string, conditions []string) (string, error) {
+	if !strings.HasPrefix(specifier, "#") || specifier == "#" || strings.HasPrefix(specifier, "#/") {
+		return "", fmt.Errorf("%w: %s", ErrImportNotDefined, specifier)
+	}
+	var imports []rawMember
+	if len(bytes.TrimSpace(p.data.Imports)) > 0 {
+		members, err := decodeMembers(p.data.Imports)
+		if err != nil {
+			return "", fmt.Errorf("invalid imports: %w", err)
+		}
+		imports = members
+	}
+	target, err := resolvePackageMap(specifier, imports, conditions, true)
+	if err != nil {
+		return "", err
+	}
+	if target == "" {
+		return "", fmt.Errorf("%w: %s", ErrImportNotDefined, specifier)
+	}
+	return target, nil
+}
+
+// legacyEntry 没有exports时主入口对应的文件
+func (p *PackageJSON) legacyEntry(conditions []string) string {
+	candidates := map[string]string{"types": p.GetTypes(), "module": p.data.Module}
+	var browser string
+	if json.Unmarshal(p.data.Browser, &browser) == nil {
+		candidates["browser"] = browser
+	}
+	for _, condition := range []string{"types", "browser", "module"} {
+		if hasCondition(conditions, condition) && candidates[condition] != "" {
+			return normalizeSubpath(candidates[condition])
+		}
+	}
+	if p.data.Main != "" {
+		return normalizeSubpath(p.data.Main)
+	}
+	return "./index.js"
+}
+
+// normalizeSubpath 把子路径规范为"."或"./"开头的形式
+func normalizeSubpath(subpath string) string {
+	subpath = strings.TrimPrefix(subpath, "./")
+	if subpath == "" || subpath == "." {
+		return "."
+	}
+	return "./" + subpath
+}
+
+// normalizeExports 把exports规范为子路径到目标的映射，字符串、数组和只有条件的对象视为"."的目标
+func normalizeExports(exports json.RawMessage) ([]rawMember, error) {
+	trimmed := bytes.TrimSpace(exports)
+	if trimmed[0] != '{' {
+		return []rawMember{{key: ".", value: trimmed}}, nil
+	}
+	members, err := decodeMembers(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exports: %w", err)
+	}
+	subpaths := 0
+	for _, member := range members {
+		if strings.HasPrefix(member.key, ".") {
+			subpaths++
+		}
+	}
+	switch {
+	case subpaths == 0:
+		return []rawMember{{key: ".", value: trimmed}}, nil
+	case subpaths != len(members):
+		return nil, fmt.Errorf("invalid exports: cannot mix subpaths and conditions")
+	}
+	return members, nil
+}
+
+// resolvePackageMap 在exports或imports映射中查找key，先精确匹配，再匹配带*的模式
+//
+// 没有匹配或目标为null时返回空字符串。
+func resolvePackageMap(key string, entries []rawMember, conditions []string, internal bool) (string, error) {
+	if value, ok := findMember(entries, key); ok && !strings.Contains(key, "*") {
+		return resolvePackageTarget(value, "", false, conditions, internal)
+	}
+
+	best, match := -1, ""
+	for i, entry := range entries {
+		prefix, trailer, ok := strings.Cut(entry.key, "*")
+		if !ok || strings.Contains(trailer, "*") {
+			continue
+		}
+		if !strings.HasPrefix(key, prefix) || key == prefix {
+			continue
+		}
+		if trailer != "" && (!strings.HasSuffix(key, trailer) || len(key) < len(entry.key)) {
+			continue
+		}
+		if best < 0 || comparePatternKeys(entry.key, entries[best].key) < 0 {
+			best, match = i, key[len(prefix):len(key)-len(trailer)]
+		}
+	}
+	if best < 0 {
+		return "", nil
+	}
+	return resolvePackageTarget(entries[best].value, match, true, conditions, internal)
+}
+
+// comparePatternKeys 模式的优先级，*之前的部分更长的优先，其次整个键更长的优先
+func comparePatternKeys(a, b string) int {
+	baseA, baseB := strings.Index(a, "*"), strings.Index(b, "*")
+	switch {
+	case baseA > baseB:
+		return -1
+	case baseA < baseB:
+		return 1
+	case len(a) > len(b):
+		return -1
+	case len(a) < len(b):
+		return 1
+	}
+	return 0
+}
+
+// resolvePackageTarget 解析一个目标：字符串、按顺序尝试的数组、条件对象或null
+func resolvePackageTarget(target json.RawMessage, match string, pattern bool, conditions []string, internal bool) (string, error) {
+	target = bytes.TrimSpace(target)
+	switch {
+	case len(target) == 0 || string(target) == "null":
+		return "", nil
+	case target[0] == '"':
+		var value string
+		if err := json.Unmarshal(target, &value); err != nil {
+			return "", err
+		}
+		return resolveTargetString(value, match, pattern, internal)
+	case target[0] == '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(target, &items); err != nil {
+			return "", err
+		}
+		var lastErr error
+		for _, item := range items {
+			resolved, err := resolvePackageTarget(item, match, pattern, conditions, internal)
+			if errors.Is(err, ErrInvalidPackageTarget) {
+				lastErr = err
+				continue
+			}
+			if err != nil || resolved != "" {
+				return resolved, err
+			}
+		}
+		return "", lastErr
+	case target[0] == '{':
+		members, err := decodeMembers(target)
+		if err != nil {
+			return "", err
+		}
+		for _, member := range members {
+			if member.key != "default" && !hasCondition(conditions, member.key) {
+				continue
+			}
+			resolved, err := resolvePackageTarget(member.value, match, pattern, conditions, internal)
+			if err != nil || resolved != "" {
+				return resolved, err
+			}
+		}
+		return "", nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidPackageTarget, target)
+}
+
+// resolveTargetString 校验字符串目标并替换模式中的*
+func resolveTargetString(target, match string, pattern bool, internal bool) (string, error) {
+	if !strings.HasPrefix(target, "./") {
+		// imports的目标可以是其他包
+		if internal && !strings.HasPrefix(target, "../") && !strings.HasPrefix(target, "/") && !strings.Contains(target, ":") {
+			if pattern {
+				return strings.ReplaceAll(target, "*", match), nil
+			}
+			return target, nil
+		}
+		return "", fmt.Errorf("%w: %s", ErrInvalidPackageTarget, target)
+	}
+	if hasInvalidSegment(target[2:]) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPackageTarget, target)
+	}
+	if !pattern {
+		return target, nil
+	}
+	if hasInvalidSegment(match) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPackageTarget, match)
+	}
+	return strings.ReplaceAll(target, "*", match), nil
+}
+
+// hasInvalidSegment 路径中是否有"."、".."或node_modules段
+func hasInvalidSegment(path string) bool {
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' }) {
+		switch strings.ToLower(segment) {
+		case ".", "..", "node_modules":
+			return true
+		}
+	}
+	return false
+}
+
+// hasCondition conditions中是否包含condition
+func hasCondition(conditions []string, condition string) bool {
+	for _, c := range conditions {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadTestPackageJSON(t *testing.T, content string) *PackageJSON {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg := NewPackageJSON(path)
+	if err := pkg.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	return pkg
+}
+
+func TestPackageJSONResolveExport(t *testing.T) {
+	pkg := loadTestPackageJSON(t, `{
+  "name": "lib",
+  "version": "1.0.0",
+  "main": "./dist/index.cjs",
+  "exports": {
+    ".": {
+      "types": "./dist/index.d.ts",
+      "import": "./dist/index.mjs",
+      "require": "./dist/index.cjs"
+    },
+    "./package.json": "./package.json",
+    "./features/*.js": "./dist/features/*.mjs",
+    "./features/internal/*": null,
+    "./utils/*": {
+      "browser": "./dist/utils/*.browser.js",
+      "default": "./dist/utils/*.js"
+    },
+    "./fallback": ["invalid:target", "./dist/fallback.js"],
+    "./escape": "../outside.js"
+  }
+}`)
+
+	tests := []struct {
+		subpath    string
+		conditions []string
+		expected   string
+		err        error
+	}{
+		{".", []string{"node", "import"}, "./dist/index.mjs", nil},
+		{"", []string{"node", "require"}, "./dist/index.cjs", nil},
+		{".", []string{"types", "require"}, "./dist/index.d.ts", nil},
+		{".", nil, "", ErrPathNotExported},
+		{"package.json", nil, "./package.json", nil},
+		{"./features/a/b.js", nil, "./dist/features/a/b.mjs", nil},
+		{"./features/internal/x", nil, "", ErrPathNotExported},
+		{"./utils/path", []string{"browser", "import"}, "./dist/utils/path.browser.js", nil},
+		{"./utils/path", []string{"node", "import"}, "./dist/utils/path.js", nil},
+		{"./utils/../../secret", nil, "", ErrInvalidPackageTarget},
+		{"./fallback", nil, "./dist/fallback.js", nil},
+		{"./escape", nil, "", ErrInvalidPackageTarget},
+		{"./dist/index.mjs", nil, "", ErrPathNotExported},
+	}
+	for _, tt := range tests {
+		resolved, err := pkg.ResolveExport(tt.subpath, tt.conditions)
+		if resolved != tt.expected || !errors.Is(err, tt.err) {
+			t.Errorf("ResolveExport(%q, %v) = %q, %v, expected %q, %v", tt.subpath, tt.conditions, resolved, err, tt.expected, tt.err)
+		}
+	}
+
+	// 字符串和只有条件的对象是主入口的简写
+	pkg = loadTestPackageJSON(t, `{"name": "lib", "exports": {"import": "./index.mjs", "default": "./index.js"}}`)
+	if resolved, err := pkg.ResolveExport(".", []string{"require"}); err != nil || resolved != "./index.js" {
+		t.Errorf("ResolveExport() = %q, %v", resolved, err)
+	}
+	if _, err := pkg.ResolveExport("./other", nil); !errors.Is(err, ErrPathNotExported) {
+		t.Errorf("Expected ErrPathNotExported, got %v", err)
+	}
+	pkg = loadTestPackageJSON(t, `{"name": "lib", "exports": {".": "./index.js", "import": "./index.mjs"}}`)
+	if _, err := pkg.ResolveExport(".", nil); err == nil {
+		t.Error("Expected error for exports mixing subpaths and conditions")
+	}
+}
+
+func TestPackageJSONResolveExportWithoutExports(t *testing.T) {
+	pkg := loadTestPackageJSON(t, `{"name": "lib", "main": "lib/index.js", "module": "es/index.js", "typings": "types/index.d.ts", "browser": "dist/browser.js"}`)
+	tests := []struct {
+		subpath    string
+		conditions []string
+		expected   string
+	}{
+		{".", nil, "./lib/index.js"},
+		{".", []string{"import", "module"}, "./es/index.js"},
+		{".", []string{"types", "module"}, "./types/index.d.ts"},
+		{".", []string{"browser", "module"}, "./dist/browser.js"},
+		{"lib/other.js", nil, "./lib/other.js"},
+	}
+	for _, tt := range tests {
+		if resolved, err := pkg.ResolveExport(tt.subpath, tt.conditions); err != nil || resolved != tt.expected {
+			t.Errorf("ResolveExport(%q, %v) = %q, %v, expected %q", tt.subpath, tt.conditions, resolved, err, tt.expected)
+		}
+	}
+	if pkg.GetTypes() != "types/index.d.ts" {
+		t.Errorf("Expected typings fallback, got %q", pkg.GetTypes())
+	}
+
+	pkg = loadTestPackageJSON(t, `{"name": "lib", "browser": {"./server.js": false}}`)
+	if resolved, _ := pkg.ResolveExport(".", []string{"browser"}); resolved != "./index.js" {
+		t.Errorf("Expected default entry, got %q", resolved)
+	}
+}
+
+func TestPackageJSONResolveImport(t *testing.T) {
+	pkg := loadTestPackageJSON(t, `{
+  "name": "app",
+  "imports": {
+    "#dep": {
+      "node": "dep-node-native",
+      "default": "./dep-polyfill.js"
+    },
+    "#internal/*.js": "./src/internal/*.js",
+    "#bad": "../outside.js"
+  }
+}`)
+	tests := []struct {
+		specifier  string
+		conditions []string
+		expected   string
+		err        error
+	}{
+		{"#dep", []string{"node", "import"}, "dep-node-native", nil},
+		{"#dep", []string{"browser"}, "./dep-polyfill.js", nil},
+		{"#internal/db/client.js", nil, "./src/internal/db/client.js", nil},
+		{"#missing", nil, "", ErrImportNotDefined},
+		{"#bad", nil, "", ErrInvalidPackageTarget},
+		{"dep", nil, "", ErrImportNotDefined},
+		{"#/x", nil, "", ErrImportNotDefined},
+	}
+	for _, tt := range tests {
+		resolved, err := pkg.ResolveImport(tt.specifier, tt.conditions)
+		if resolved != tt.expected || !errors.Is(err, tt.err) {
+			t.Errorf("ResolveImport(%q, %v) = %q, %v, expected %q, %v", tt.specifier, tt.conditions, resolved, err, tt.expected, tt.err)
+		}
+	}
+}
+
+func TestPackageJSONEntrypointFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte(`{"name": "lib", "version": "1.0.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg := NewPackageJSON(path)
+	if err := pkg.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	pkg.SetTypes("./dist/index.d.ts")
+	pkg.SetModule("./dist/index.mjs")
+	if err := pkg.SetExports(json.RawMessage(`{".": {"import": "./dist/index.mjs", "default": "./dist/index.cjs"}}`)); err != nil {
+		t.Fatalf("SetExports() failed: %v", err)
+	}
+	if err := pkg.SetImports(map[string]string{"#config": "./config.js"}); err != nil {
+		t.Fatalf("SetImports() failed: %v", err)
+	}
+	if err := pkg.SetBrowser("./dist/browser.js"); err != nil {
+		t.Fatalf("SetBrowser() failed: %v", err)
+	}
+	if err := pkg.SetExports(json.RawMessage(`{`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	expected := `{
+  "name": "lib",
+  "version": "1.0.0",
+  "module": "./dist/index.mjs",
+  "types": "./dist/index.d.ts",
+  "browser": "./dist/browser.js",
+  "exports": {
+    ".": {
+      "import": "./dist/index.mjs",
+      "default": "./dist/index.cjs"
+    }
+  },
+  "imports": {
+    "#config": "./config.js"
+  }
+}`
+	if data, _ := os.ReadFile(path); string(data) != expected {
+		t.Errorf("Unexpected package.json:\n%s", data)
+	}
+
+	if err := pkg.SetBrowser(nil); err != nil || pkg.GetBrowser() != nil {
+		t.Errorf("SetBrowser(nil) = %v, browser %s", err, pkg.GetBrowser())
+	}
+}
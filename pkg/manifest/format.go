@@ -0,0 +1,338 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SaveOptions 保存package.json时的格式选项，零值沿用Load时文件的格式
+type SaveOptions struct {
+	Indent          string `json:"indent,omitempty"`           // 缩进，只能由空格或制表符组成，例如"\t"或"    "；为空时沿用文件原来的缩进，新文件缩进两个空格
+	TrailingNewline *bool  `json:"trailing_newline,omitempty"` // 文件末尾是否换行，为nil时沿用文件原来的写法，新文件不换行
+	SortKeys        bool   `json:"sort_keys,omitempty"`        // 按sort-package-json的规则排序字段
+}
+
+// fileFormat Load时检测到的文件格式
+type fileFormat struct {
+	indent          string
+	trailingNewline bool
+	crlf            bool
+}
+
+// detectFormat 检测JSON文件的缩进、末尾换行和换行符
+func detectFormat(data []byte) fileFormat {
+	format := fileFormat{
+		indent:          "  ",
+		trailingNewline: bytes.HasSuffix(data, []byte("\n")),
+		crlf:            bytes.Contains(data, []byte("\r\n")),
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if len(trimmed) < len(line) && strings.HasPrefix(trimmed, `"`) {
+			format.indent = line[:len(line)-len(trimmed)]
+			break
+		}
+	}
+	return format
+}
+
+// SaveWithOptions 按指定格式保存package.json文件
+//
+// 没有指定的选项沿用Load时文件的格式，CRLF换行符总是保留。SortKeys排序后的顺序在之后的
+// Save中保持。
+func (p *PackageJSON) SaveWithOptions(options SaveOptions) error {
+	format := p.format
+	if format.indent == "" {
+		format.indent = "  "
+	}
+	if options.Indent != "" {
+		if strings.Trim(options.Indent, " \t") != "" {
+			return fmt.Errorf("invalid indent %q: only spaces and tabs are allowed", options.Indent)
+		}
+		format.indent = options.Indent
+	}
+	if options.TrailingNewline != nil {
+		format.trailingNewline = *options.TrailingNewline
+	}
+
+	// 确保目录存在
+	dir := filepath.Dir(p.filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	members, err := p.members()
+	if err != nil {
+		return err
+	}
+	if options.SortKeys {
+		members = sortPackageMembers(members)
+	}
+	data, err := encodeMembers(members, format.indent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal package.json: %w", err)
+	}
+	if format.trailingNewline {
+		data = append(data, '\n')
+	}
+	if format.crlf {
+		data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+	}
+
+	if err := os.WriteFile(p.filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write package.json: %w", err)
+	}
+
+	// 文件内容就是新的原始成员，之后的修改以保存的内容为准
+	p.raw = members
+	p.format = format
+	return p.snapshot()
+}
+
+// packageKeyOrder sort-package-json的顶层字段顺序
+var packageKeyOrder = []string{
+	"$schema", "name", "displayName", "version", "stableVersion", "private", "description", "categories",
+	"keywords", "homepage", "bugs", "repository", "funding", "license", "qna", "author", "maintainers",
+	"contributors", "publisher", "sideEffects", "type", "imports", "exports", "main", "svelte", "umd:main",
+	"jsdelivr", "unpkg", "module", "source", "jsnext:main", "browser", "react-native", "types", "typesVersions",
+	"typings", "style", "example", "examplestyle", "assets", "bin", "man", "directories", "files", "workspaces",
+	"binary", "scripts", "betterScripts", "l10n", "contributes", "activationEvents", "husky", "simple-git-hooks",
+	"pre-commit", "commitlint", "lint-staged", "nano-staged", "config", "nodemonConfig", "browserify", "babel",
+	"browserslist", "xo", "prettier", "eslintConfig", "eslintIgnore", "npmpackagejsonlint", "release",
+	"remarkConfig", "stylelint", "ava", "jest", "jest-junit", "jest-stare", "mocha", "nyc", "c8", "tap", "oclif",
+	"resolutions", "dependencies", "devDependencies", "dependenciesMeta", "peerDependencies",
+	"peerDependenciesMeta", "optionalDependencies", "bundledDependencies", "bundleDependencies", "extensionPack",
+	"extensionDependencies", "flat", "packageManager", "engines", "engineStrict", "volta", "languageName", "os",
+	"cpu", "preferGlobal", "publishConfig", "icon", "badges", "galleryBanner", "preview", "markdown", "pnpm",
+}
+
+// packageKeyRank 顶层字段在packageKeyOrder中的位置
+var packageKeyRank = func() map[string]int {
+	rank := make(map[string]int, len(packageKeyOrder))
+	for i, key := range packageKeyOrder {
+		rank[key] = i
+	}
+	return rank
+}()
+
+// nestedKeyOrder 按固定顺序排列键的对象字段
+var nestedKeyOrder = map[string][]string{
+	"bugs":         {"url", "email"},
+	"repository":   {"type", "url"},
+	"funding":      {"type", "url"},
+	"author":       {"name", "email", "url"},
+	"maintainers":  {"name", "email", "url"},
+	"contributors": {"name", "email", "url"},
+	"directories":  {"lib", "bin", "man", "doc", "example", "test"},
+	"volta":        {"node", "npm", "yarn"},
+}
+
+// sortedObjectFields 键按字母排序的对象字段
+var sortedObjectFields = map[string]bool{
+	"bin": true, "dependencies": true, "devDependencies": true, "dependenciesMeta": true, "peerDependencies": true,
+	"peerDependenciesMeta": true, "optionalDependencies": true, "resolutions": true, "overrides": true,
+	"engines": true, "publishConfig": true,
+}
+
+// sortPackageMembers 按sort-package-json的规则排序
+//
+// 已知字段按packageKeyOrder排列，其他字段按字母顺序排在后面，以"_"开头的私有字段排在最后。
+// 依赖、bin、engines等对象的键按字母排序，scripts中pre和post钩子紧挨着对应的脚本，
+// keywords、files、os和cpu去掉重复项，bundledDependencies去重后排序。
+func sortPackageMembers(members []rawMember) []rawMember {
+	sorted := make([]rawMember, len(members))
+	for i, member := range members {
+		sorted[i] = rawMember{key: member.key, value: sortPackageField(member.key, member.value, members)}
+	}
+	group := func(key string) int {
+		switch _, known := packageKeyRank[key]; {
+		case known:
+			return 0
+		case strings.HasPrefix(key, "_"):
+			return 2
+		}
+		return 1
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].key, sorted[j].key
+		if group(a) != group(b) {
+			return group(a) < group(b)
+		}
+		if group(a) == 0 {
+			return packageKeyRank[a] < packageKeyRank[b]
+		}
+		return a < b
+	})
+	return sorted
+}
+
+// sortPackageField 排序顶层字段内部的键或数组项，不需要排序或无法解析的值原样返回
+func sortPackageField(key string, value json.RawMessage, members []rawMember) json.RawMessage {
+	switch key {
+	case "keywords", "files", "os", "cpu":
+		return sortStringArray(value, false)
+	case "bundledDependencies", "bundleDependencies":
+		return sortStringArray(value, true)
+	case "scripts", "betterScripts":
+		return sortScripts(value, members)
+	case "maintainers", "contributors":
+		// 人员列表中每个对象形式的人员按name、email、url排列
+		var items []json.RawMessage
+		if json.Unmarshal(value, &items) != nil {
+			return value
+		}
+		for i, item := range items {
+			items[i] = sortObjectKeys(item, nestedKeyOrder[key])
+		}
+		data, err := marshalJSON(items)
+		if err != nil {
+			return value
+		}
+		return data
+	}
+	if order, ok := nestedKeyOrder[key]; ok {
+		return sortObjectKeys(value, order)
+	}
+	if sortedObjectFields[key] {
+		return sortObjectKeys(value, nil)
+	}
+	return value
+}
+
+// sortObjectKeys 把对象中order列出的键按顺序排在前面，其余的键按字母排序；不是对象时原样返回
+func sortObjectKeys(value json.RawMessage, order []string) json.RawMessage {
+	members, err := decodeMembers(value)
+	if err != nil {
+		return value
+	}
+	rank := make(map[string]int, len(order))
+	for i, key := range order {
+		rank[key] = i
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		ri, iok := rank[members[i].key]
+		rj, jok := rank[members[j].key]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		}
+		return members[i].key < members[j].key
+	})
+	data, err := encodeMembers(members, "  ")
+	if err != nil {
+		return value
+	}
+	return data
+}
+
+// sortStringArray 去掉字符串数组中的重复项，sorted为true时同时排序；不是字符串数组时原样返回
+func sortStringArray(value json.RawMessage, sorted bool) json.RawMessage {
+	var items []string
+	if json.Unmarshal(value, &items) != nil {
+		return value
+	}
+	seen := make(map[string]bool, len(items))
+	unique := make([]string, 0, len(items))
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			unique = append(unique, item)
+		}
+	}
+	if sorted {
+		sort.Strings(unique)
+	}
+	data, err := marshalJSON(unique)
+	if err != nil {
+		return value
+	}
+	return data
+}
+
+// npmLifecycleScripts npm自动运行pre和post钩子的内置脚本
+var npmLifecycleScripts = map[string]bool{
+	"install": true, "pack": true, "prepare": true, "publish": true, "restart": true, "shrinkwrap": true,
+	"start": true, "stop": true, "test": true, "uninstall": true, "version": true,
+}
+
+// sequentialScriptPattern 按定义顺序运行通配脚本的npm-run-all命令，此时脚本的顺序有意义
+var sequentialScriptPattern = regexp.MustCompile(`(?:^|[\s&;|])(?:npm-run-all\s(?:[^&;|]*\s)?(?:-s|--sequential|--serial)\s|run-s\s)[^&;|]*\*`)
+
+// sortScripts 按名称排序脚本，pre<name>和post<name>紧挨着<name>
+//
+// 与sort-package-json一样，如果有脚本用npm-run-all或run-s按顺序运行通配的脚本，只把钩子移到
+// 对应的脚本旁边，不按名称排序。
+func sortScripts(value json.RawMessage, members []rawMember) json.RawMessage {
+	scripts, err := decodeMembers(value)
+	if err != nil {
+		return value
+	}
+	names := make(map[string]bool, len(scripts))
+	for _, script := range scripts {
+		names[script.key] = true
+	}
+
+	// 钩子按去掉前缀后的名称归组
+	base := func(name string) string {
+		for _, prefix := range []string{"pre", "post"} {
+			if omitted, ok := strings.CutPrefix(name, prefix); ok && (npmLifecycleScripts[omitted] || names[omitted]) {
+				return omitted
+			}
+		}
+		return name
+	}
+	var keys []string
+	seen := make(map[string]bool, len(scripts))
+	for _, script := range scripts {
+		if key := base(script.key); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if !hasSequentialScript(scripts, members) {
+		sort.Strings(keys)
+	}
+
+	sorted := make([]rawMember, 0, len(scripts))
+	for _, key := range keys {
+		for _, name := range []string{"pre" + key, key, "post" + key} {
+			if script, ok := findMember(scripts, name); ok && (name == key || base(name) == key) {
+				sorted = append(sorted, rawMember{key: name, value: script})
+			}
+		}
+	}
+	data, err := encodeMembers(sorted, "  ")
+	if err != nil {
+		return value
+	}
+	return data
+}
+
+// hasSequentialScript 是否依赖npm-run-all并有脚本按顺序运行通配的脚本
+func hasSequentialScript(scripts []rawMember, members []rawMember) bool {
+	devDependencies, _ := findMember(members, "devDependencies")
+	var deps map[string]json.RawMessage
+	if json.Unmarshal(devDependencies, &deps) != nil {
+		return false
+	}
+	if _, ok := deps["npm-run-all"]; !ok {
+		if _, ok := deps["npm-run-all2"]; !ok {
+			return false
+		}
+	}
+	for _, script := range scripts {
+		var command string
+		if json.Unmarshal(script.value, &command) == nil && sequentialScriptPattern.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
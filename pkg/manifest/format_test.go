@@ -0,0 +1,135 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSavePreservesFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "tabs with trailing newline",
+			content:  "{\n\t\"name\": \"app\",\n\t\"version\": \"1.0.0\"\n}\n",
+			expected: "{\n\t\"name\": \"app\",\n\t\"version\": \"1.0.1\"\n}\n",
+		},
+		{
+			name:     "four spaces",
+			content:  "{\n    \"name\": \"app\",\n    \"version\": \"1.0.0\"\n}",
+			expected: "{\n    \"name\": \"app\",\n    \"version\": \"1.0.1\"\n}",
+		},
+		{
+			name:     "crlf",
+			content:  "{\r\n  \"name\": \"app\",\r\n  \"version\": \"1.0.0\"\r\n}\r\n",
+			expected: "{\r\n  \"name\": \"app\",\r\n  \"version\": \"1.0.1\"\r\n}\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := loadTestPackageJSON(t, tt.content)
+			pkg.SetVersion("1.0.1")
+			if err := pkg.Save(); err != nil {
+				t.Fatalf("Save() failed: %v", err)
+			}
+			if data, _ := os.ReadFile(pkg.filePath); string(data) != tt.expected {
+				t.Errorf("Unexpected package.json: %q", data)
+			}
+		})
+	}
+}
+
+func TestSaveWithOptions(t *testing.T) {
+	pkg := NewPackageJSON(filepath.Join(t.TempDir(), "package.json"))
+	pkg.SetName("app")
+	pkg.AddDependency("zod", "^3.22.0")
+
+	newline := true
+	if err := pkg.SaveWithOptions(SaveOptions{Indent: "\t", TrailingNewline: &newline}); err != nil {
+		t.Fatalf("SaveWithOptions() failed: %v", err)
+	}
+	expected := "{\n\t\"name\": \"app\",\n\t\"dependencies\": {\n\t\t\"zod\": \"^3.22.0\"\n\t}\n}\n"
+	if data, _ := os.ReadFile(pkg.filePath); string(data) != expected {
+		t.Errorf("Unexpected package.json: %q", data)
+	}
+
+	// 之后的Save沿用上次保存的格式
+	pkg.SetVersion("1.0.0")
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	expected = "{\n\t\"name\": \"app\",\n\t\"dependencies\": {\n\t\t\"zod\": \"^3.22.0\"\n\t},\n\t\"version\": \"1.0.0\"\n}\n"
+	if data, _ := os.ReadFile(pkg.filePath); string(data) != expected {
+		t.Errorf("Unexpected package.json: %q", data)
+	}
+
+	if err := pkg.SaveWithOptions(SaveOptions{Indent: "--"}); err == nil {
+		t.Error("Expected error for invalid indent")
+	}
+}
+
+func TestSaveWithSortKeys(t *testing.T) {
+	pkg := loadTestPackageJSON(t, `{
+  "_id": "app@1.0.0",
+  "dependencies": {"zod": "^3.22.0", "axios": "^1.6.0"},
+  "scripts": {"test": "vitest", "build": "tsc", "postbuild": "node copy.js", "prebuild": "rimraf dist", "pretest": "eslint ."},
+  "custom": true,
+  "version": "1.0.0",
+  "repository": {"url": "https://github.com/example/app.git", "type": "git"},
+  "keywords": ["cli", "npm", "cli"],
+  "name": "app",
+  "alpha": 1
+}`)
+	if err := pkg.SaveWithOptions(SaveOptions{SortKeys: true}); err != nil {
+		t.Fatalf("SaveWithOptions() failed: %v", err)
+	}
+	expected := `{
+  "name": "app",
+  "version": "1.0.0",
+  "keywords": [
+    "cli",
+    "npm"
+  ],
+  "repository": {
+    "type": "git",
+    "url": "https://github.com/example/app.git"
+  },
+  "scripts": {
+    "prebuild": "rimraf dist",
+    "build": "tsc",
+    "postbuild": "node copy.js",
+    "pretest": "eslint .",
+    "test": "vitest"
+  },
+  "dependencies": {
+    "axios": "^1.6.0",
+    "zod": "^3.22.0"
+  },
+  "alpha": 1,
+  "custom": true,
+  "_id": "app@1.0.0"
+}`
+	if data, _ := os.ReadFile(pkg.filePath); string(data) != expected {
+		t.Errorf("Unexpected package.json:\n%s", data)
+	}
+
+	// 排序后的顺序在之后的Save中保持
+	pkg.AddDependency("lodash", "^4.17.21")
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if keys := pkg.Keys(); keys[0] != "name" || keys[len(keys)-1] != "_id" {
+		t.Errorf("Unexpected keys after Save: %v", keys)
+	}
+}
+
+func TestSortScriptsSequential(t *testing.T) {
+	members, _ := decodeMembers([]byte(`{"devDependencies": {"npm-run-all": "^4.1.5"}}`))
+	value := sortScripts([]byte(`{"build": "run-s build:*", "build:b": "tsc", "build:a": "vite build"}`), members)
+	if string(compactJSON(value)) != `{"build":"run-s build:*","build:b":"tsc","build:a":"vite build"}` {
+		t.Errorf("Expected sequential scripts to keep their order, got %s", value)
+	}
+}
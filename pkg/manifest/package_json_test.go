@@ -1,11 +1,15 @@
-package npm
+package manifest
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/parse"
 )
 
 func TestNewPackageJSON(t *testing.T) {
@@ -224,7 +228,7 @@ func TestPackageJSONRepository(t *testing.T) {
 	}
 
 	// 设置完整仓库信息
-	newRepo := &Repository{
+	newRepo := &npmiface.Repository{
 		Type: "git",
 		URL:  "https://github.com/user/another-repo.git",
 	}
@@ -252,7 +256,7 @@ func TestPackageJSONBugs(t *testing.T) {
 	}
 
 	// 设置完整bugs信息
-	newBugs := &Bugs{
+	newBugs := &npmiface.Bugs{
 		URL:   "https://github.com/user/repo/issues",
 		Email: "bugs@example.com",
 	}
@@ -624,7 +628,7 @@ func TestPackageJSONComplexRepository(t *testing.T) {
 	pkg := NewPackageJSON("/tmp/test.json")
 
 	// 测试复杂的仓库配置
-	repo := &Repository{
+	repo := &npmiface.Repository{
 		Type: "git",
 		URL:  "https://github.com/user/repo.git",
 	}
@@ -668,7 +672,7 @@ func TestPackageJSONComplexBugs(t *testing.T) {
 	pkg := NewPackageJSON("/tmp/test.json")
 
 	// 测试复杂的bugs配置
-	bugs := &Bugs{
+	bugs := &npmiface.Bugs{
 		URL:   "https://github.com/user/repo/issues",
 		Email: "bugs@example.com",
 	}
@@ -695,3 +699,50 @@ func TestPackageJSONComplexBugs(t *testing.T) {
 		t.Errorf("Expected bugs URL to be updated")
 	}
 }
+
+func TestPackageJSONLoadParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte("{\n  \"name\": \"demo\",\n  \"version\": 1\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewPackageJSON(path).Load()
+	var parseErr *parse.Error
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *parse.Error, got %v", err)
+	}
+	if parseErr.Source != path || parseErr.Line != 3 || parseErr.Column != 14 {
+		t.Errorf("Unexpected error position: %+v", parseErr)
+	}
+}
+
+func FuzzPackageJSONLoad(f *testing.F) {
+	f.Add([]byte(`{"name": "demo", "version": "1.0.0", "dependencies": {"lodash": "^4.17.21"}}`))
+	f.Add([]byte(`{"repository": {"type": "git", "url": "x"}, "bugs": "https://x", "keywords": ["a"]}`))
+	f.Add([]byte(`[[[[[[]]]]]]`))
+
+	dir := f.TempDir()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		path := filepath.Join(dir, "package.json")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		pkg := NewPackageJSON(path)
+		if err := pkg.Load(); err != nil {
+			var parseErr *parse.Error
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected *parse.Error, got %v", err)
+			}
+			if parseErr.Offset < 0 || parseErr.Offset > int64(len(data)) || parseErr.Line < 1 || parseErr.Column < 1 {
+				t.Errorf("Invalid error position for %q: %+v", data, parseErr)
+			}
+			return
+		}
+		// 能加载的文件也必须能校验和保存
+		_ = pkg.Validate()
+		if err := pkg.Save(); err != nil {
+			t.Errorf("Save() failed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package manifest
+
+import "github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+
+// GetAuthorPerson 获取作者，没有作者时返回nil
+func (p *PackageJSON) GetAuthorPerson() *npmiface.Person {
+	return p.data.AuthorPerson()
+}
+
+// SetAuthorPerson 设置作者，person为nil时删除
+//
+// 已有作者时保持原来的字符串或对象形式，新的作者写成对象。
+func (p *PackageJSON) SetAuthorPerson(person *npmiface.Person) {
+	if person == nil {
+		p.data.Author = ""
+		return
+	}
+	p.setAuthor(*person, true)
+}
+
+// setAuthor 设置作者，已有作者时保持原来的形式，否则使用object指定的形式
+func (p *PackageJSON) setAuthor(person npmiface.Person, object bool) {
+	if p.data.Author != "" {
+		object = p.data.AuthorObject
+	}
+	p.data.Author = person.String()
+	p.data.AuthorObject = object
+}
+
+// GetContributors 获取贡献者
+func (p *PackageJSON) GetContributors() []npmiface.Person {
+	return people(p.data.Contributors)
+}
+
+// SetContributors 设置贡献者，形式与已有的贡献者相同，没有时写成对象
+func (p *PackageJSON) SetContributors(contributors []npmiface.Person) {
+	p.data.Contributors = packagePeople(contributors, p.data.Contributors)
+}
+
+// AddContributor 添加贡献者，名称和邮箱都相同的贡献者已存在时不重复添加
+func (p *PackageJSON) AddContributor(contributor npmiface.Person) {
+	p.data.Contributors = addPackagePerson(p.data.Contributors, contributor)
+}
+
+// GetMaintainers 获取维护者
+func (p *PackageJSON) GetMaintainers() []npmiface.Person {
+	return people(p.data.Maintainers)
+}
+
+// SetMaintainers 设置维护者，形式与已有的维护者相同，没有时写成对象
+func (p *PackageJSON) SetMaintainers(maintainers []npmiface.Person) {
+	p.data.Maintainers = packagePeople(maintainers, p.data.Maintainers)
+}
+
+// AddMaintainer 添加维护者，名称和邮箱都相同的维护者已存在时不重复添加
+func (p *PackageJSON) AddMaintainer(maintainer npmiface.Person) {
+	p.data.Maintainers = addPackagePerson(p.data.Maintainers, maintainer)
+}
+
+// NormalizePeople 把author、contributors和maintainers统一为对象形式或"Name <email> (url)"字符串形式
+//
+// 同时去掉名称、邮箱和URL首尾的空白，删除三者都为空的项。
+func (p *PackageJSON) NormalizePeople(object bool) {
+	normalize := func(person *npmiface.PackagePerson) bool {
+		person.Person = npmiface.ParsePerson(person.String())
+		person.Object = object
+		return person.Name != "" || person.Email != "" || person.URL != ""
+	}
+	if p.data.Author != "" {
+		author := npmiface.PackagePerson{Person: npmiface.ParsePerson(p.data.Author)}
+		if normalize(&author) {
+			p.data.Author, p.data.AuthorObject = author.String(), object
+		} else {
+			p.data.Author = ""
+		}
+	}
+	for _, list := range []*[]npmiface.PackagePerson{&p.data.Contributors, &p.data.Maintainers} {
+		kept := (*list)[:0]
+		for _, person := range *list {
+			if normalize(&person) {
+				kept = append(kept, person)
+			}
+		}
+		if len(kept) == 0 {
+			kept = nil
+		}
+		*list = kept
+	}
+}
+
+// people 返回人员信息的副本
+func people(list []npmiface.PackagePerson) []npmiface.Person {
+	if len(list) == 0 {
+		return nil
+	}
+	result := make([]npmiface.Person, len(list))
+	for i, person := range list {
+		result[i] = person.Person
+	}
+	return result
+}
+
+// packagePeople 转换为PackagePerson，形式与existing的第一项相同，existing为空时使用对象形式
+func packagePeople(list []npmiface.Person, existing []npmiface.PackagePerson) []npmiface.PackagePerson {
+	if len(list) == 0 {
+		return nil
+	}
+	object := len(existing) == 0 || existing[0].Object
+	result := make([]npmiface.PackagePerson, len(list))
+	for i, person := range list {
+		result[i] = npmiface.PackagePerson{Person: person, Object: object}
+	}
+	return result
+}
+
+// addPackagePerson 追加人员，已存在时返回原列表
+func addPackagePerson(list []npmiface.PackagePerson, person npmiface.Person) []npmiface.PackagePerson {
+	for _, existing := range list {
+		if existing.Name == person.Name && existing.Email == person.Email {
+			return list
+		}
+	}
+	return append(list, packagePeople([]npmiface.Person{person}, list)...)
+}
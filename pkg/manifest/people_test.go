@@ -0,0 +1,127 @@
+package manifest
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+)
+
+func TestParsePerson(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected npmiface.Person
+	}{
+		{"Barney Rubble <b@rubble.com> (http://barnyrubble.tumblr.com/)", npmiface.Person{Name: "Barney Rubble", Email: "b@rubble.com", URL: "http://barnyrubble.tumblr.com/"}},
+		{"Barney Rubble", npmiface.Person{Name: "Barney Rubble"}},
+		{"  Barney   (https://b.com)  <b@rubble.com>", npmiface.Person{Name: "Barney", Email: "b@rubble.com", URL: "https://b.com"}},
+		{"<b@rubble.com>", npmiface.Person{Email: "b@rubble.com"}},
+	}
+	for _, tt := range tests {
+		if person := npmiface.ParsePerson(tt.value); person != tt.expected {
+			t.Errorf("ParsePerson(%q) = %+v, expected %+v", tt.value, person, tt.expected)
+		}
+	}
+	if s := tests[0].expected.String(); s != tests[0].value {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestPackageJSONPeople(t *testing.T) {
+	pkg := loadTestPackageJSON(t, `{
+  "name": "app",
+  "version": "1.0.0",
+  "author": {
+    "name": "Barney Rubble",
+    "email": "b@rubble.com"
+  },
+  "contributors": [
+    "Fred Flintstone <fred@example.com>",
+    "Wilma <wilma@example.com> (https://wilma.dev)"
+  ]
+}`)
+
+	if pkg.GetAuthor() != "Barney Rubble <b@rubble.com>" {
+		t.Errorf("Unexpected author: %q", pkg.GetAuthor())
+	}
+	if author := pkg.GetAuthorPerson(); author == nil || author.Email != "b@rubble.com" {
+		t.Errorf("Unexpected author: %+v", author)
+	}
+	expected := []npmiface.Person{
+		{Name: "Fred Flintstone", Email: "fred@example.com"},
+		{Name: "Wilma", Email: "wilma@example.com", URL: "https://wilma.dev"},
+	}
+	if contributors := pkg.GetContributors(); !reflect.DeepEqual(contributors, expected) {
+		t.Errorf("Unexpected contributors: %+v", contributors)
+	}
+
+	// 修改时保持原来的形式
+	pkg.SetAuthor("Barney Rubble <barney@rubble.com>")
+	pkg.AddContributor(npmiface.Person{Name: "Betty"})
+	pkg.AddContributor(npmiface.Person{Name: "Fred Flintstone", Email: "fred@example.com"})
+	pkg.AddMaintainer(npmiface.Person{Name: "ops", Email: "ops@example.com"})
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	content := `{
+  "name": "app",
+  "version": "1.0.0",
+  "author": {
+    "name": "Barney Rubble",
+    "email": "barney@rubble.com"
+  },
+  "contributors": [
+    "Fred Flintstone <fred@example.com>",
+    "Wilma <wilma@example.com> (https://wilma.dev)",
+    "Betty"
+  ],
+  "maintainers": [
+    {
+      "name": "ops",
+      "email": "ops@example.com"
+    }
+  ]
+}`
+	if data, _ := os.ReadFile(pkg.filePath); string(data) != content {
+		t.Errorf("Unexpected package.json:\n%s", data)
+	}
+
+	pkg.NormalizePeople(false)
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	content = `{
+  "name": "app",
+  "version": "1.0.0",
+  "author": "Barney Rubble <barney@rubble.com>",
+  "contributors": [
+    "Fred Flintstone <fred@example.com>",
+    "Wilma <wilma@example.com> (https://wilma.dev)",
+    "Betty"
+  ],
+  "maintainers": [
+    "ops <ops@example.com>"
+  ]
+}`
+	if data, _ := os.ReadFile(pkg.filePath); string(data) != content {
+		t.Errorf("Unexpected package.json:\n%s", data)
+	}
+
+	pkg.SetAuthor("")
+	pkg.SetContributors(nil)
+	if pkg.GetAuthorPerson() != nil || pkg.GetContributors() != nil {
+		t.Error("Expected author and contributors to be removed")
+	}
+	pkg.SetAuthorPerson(&npmiface.Person{Name: "New"})
+	if data := pkg.GetData(); data.Author != "New" || !data.AuthorObject {
+		t.Errorf("Expected object form for new author, got %q", data.Author)
+	}
+
+	if err := pkg.Set("author", map[string]string{"name": "Set", "email": "set@example.com"}); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if data := pkg.GetData(); data.Author != "Set <set@example.com>" || !data.AuthorObject {
+		t.Errorf("Unexpected author after Set: %q", data.Author)
+	}
+}
@@ -0,0 +1,59 @@
+package manifest
+
+import (
+	"go/build"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// 纯数据包不能依赖执行命令、访问网络的包，否则无法编译到WASM
+var (
+	purePackages = []string{"manifest", "parse", "semver", "lockfile", "npmiface"}
+
+	forbiddenImports = []string{"os/exec", "net", "net/http"}
+)
+
+func TestPurePackagesImports(t *testing.T) {
+	ctx := build.Default
+	ctx.GOOS, ctx.GOARCH = "js", "wasm"
+	const module = "github.com/scagogogo/go-npm-sdk/pkg/"
+
+	visited := make(map[string]bool)
+	var visit func(path, via string)
+	visit = func(path, via string) {
+		if visited[path] {
+			return
+		}
+		visited[path] = true
+		for _, forbidden := range forbiddenImports {
+			if path == forbidden {
+				t.Errorf("%s imports %s", via, path)
+				return
+			}
+		}
+
+		var pkg *build.Package
+		var err error
+		if name, ok := strings.CutPrefix(path, module); ok {
+			if !slices.Contains(purePackages, name) {
+				t.Errorf("%s imports non-pure package %s", via, path)
+				return
+			}
+			pkg, err = ctx.ImportDir(filepath.Join("..", filepath.FromSlash(name)), 0)
+		} else {
+			pkg, err = ctx.Import(path, "", 0)
+		}
+		if err != nil {
+			t.Errorf("Failed to import %s: %v", path, err)
+			return
+		}
+		for _, imported := range pkg.Imports {
+			visit(imported, path)
+		}
+	}
+	for _, name := range purePackages {
+		visit(module+name, "test")
+	}
+}
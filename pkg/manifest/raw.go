@@ -0,0 +1,280 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+)
+
+// rawMember package.json中的一个顶层成员
+type rawMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// packageFields npmiface.Package中各JSON字段对应的结构体字段下标
+var packageFields = func() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeOf(npmiface.Package{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+	return fields
+}()
+
+// decodeMembers 按原顺序解码JSON对象的成员
+func decodeMembers(data []byte) ([]rawMember, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	var members []rawMember
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		// 重复的键与encoding/json一样以最后一个为准
+		members = removeMember(members, key)
+		members = append(members, rawMember{key: key, value: value})
+	}
+	return members, nil
+}
+
+// encodeMembers 把成员编码为按indent缩进的JSON对象
+func encodeMembers(members []rawMember, indent string) ([]byte, error) {
+	var compact bytes.Buffer
+	compact.WriteByte('{')
+	for i, member := range members {
+		if i > 0 {
+			compact.WriteByte(',')
+		}
+		key, err := marshalJSON(member.key)
+		if err != nil {
+			return nil, err
+		}
+		compact.Write(key)
+		compact.WriteByte(':')
+		if err := json.Compact(&compact, member.value); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", member.key, err)
+		}
+	}
+	compact.WriteByte('}')
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, compact.Bytes(), "", indent); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+// marshalJSON 编码JSON值，不转义HTML字符
+func marshalJSON(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// mergeMemberOrder 用updated的内容和original的键顺序合并两个值，对象中新增的键追加到末尾
+func mergeMemberOrder(original, updated json.RawMessage) json.RawMessage {
+	originalMembers, err := decodeMembers(original)
+	if err != nil {
+		return updated
+	}
+	updatedMembers, err := decodeMembers(updated)
+	if err != nil {
+		return updated
+	}
+
+	merged := make([]rawMember, 0, len(updatedMembers))
+	for _, member := range originalMembers {
+		if value, ok := findMember(updatedMembers, member.key); ok {
+			merged = append(merged, rawMember{key: member.key, value: mergeMemberOrder(member.value, value)})
+		}
+	}
+	for _, member := range updatedMembers {
+		if _, ok := findMember(originalMembers, member.key); !ok {
+			merged = append(merged, member)
+		}
+	}
+	data, err := encodeMembers(merged, "  ")
+	if err != nil {
+		return updated
+	}
+	return data
+}
+
+// findMember 按键查找成员
+func findMember(members []rawMember, key string) (json.RawMessage, bool) {
+	for _, member := range members {
+		if member.key == key {
+			return member.value, true
+		}
+	}
+	return nil, false
+}
+
+// removeMember 删除键为key的成员
+func removeMember(members []rawMember, key string) []rawMember {
+	for i, member := range members {
+		if member.key == key {
+			return append(members[:i], members[i+1:]...)
+		}
+	}
+	return members
+}
+
+// typedMembers 按结构体字段顺序编码类型化的字段
+func (p *PackageJSON) typedMembers() ([]rawMember, error) {
+	data, err := marshalJSON(p.data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal package.json: %w", err)
+	}
+	return decodeMembers(data)
+}
+
+// members 合并类型化字段和文件中的原始成员
+//
+// 文件中的键保持原顺序：未知字段原样保留，Load之后没有修改的已知字段保留原文，
+// 修改过的对象保留原有键顺序；新设置的已知字段按结构体字段顺序追加到末尾。
+func (p *PackageJSON) members() ([]rawMember, error) {
+	typed, err := p.typedMembers()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make([]rawMember, 0, len(p.raw)+len(typed))
+	for _, member := range p.raw {
+		if _, known := packageFields[member.key]; !known {
+			merged = append(merged, member)
+			continue
+		}
+		loaded, wasLoaded := p.loaded[member.key]
+		value, ok := findMember(typed, member.key)
+		switch {
+		case !ok && !wasLoaded, ok && wasLoaded && string(value) == loaded:
+			// 类型化字段无法表示或没有修改
+			merged = append(merged, member)
+		case ok:
+			merged = append(merged, rawMember{key: member.key, value: mergeMemberOrder(member.value, value)})
+		}
+	}
+	for _, member := range typed {
+		if _, ok := findMember(p.raw, member.key); ok {
+			continue
+		}
+		// name和version没有omitempty，为空时不写入，没有version的文件保存后不会多出"version": ""
+		if string(member.value) == `""` {
+			continue
+		}
+		merged = append(merged, member)
+	}
+	return merged, nil
+}
+
+// snapshot 记录Load时已知字段的编码结果
+func (p *PackageJSON) snapshot() error {
+	typed, err := p.typedMembers()
+	if err != nil {
+		return err
+	}
+	p.loaded = make(map[string]string, len(typed))
+	for _, member := range typed {
+		p.loaded[member.key] = string(member.value)
+	}
+	return nil
+}
+
+// Keys 按文件中的顺序返回所有顶层字段名，包括类型化字段之外的自定义字段
+func (p *PackageJSON) Keys() []string {
+	members, err := p.members()
+	if err != nil {
+		return nil
+	}
+	keys := make([]string, len(members))
+	for i, member := range members {
+		keys[i] = member.key
+	}
+	return keys
+}
+
+// Get 获取顶层字段的原始JSON，例如exports、engines等没有类型化访问方法的字段
+func (p *PackageJSON) Get(key string) (json.RawMessage, bool) {
+	members, err := p.members()
+	if err != nil {
+		return nil, false
+	}
+	return findMember(members, key)
+}
+
+// Set 设置顶层字段，value按JSON编码，已有的字段保持原位置，新字段追加到末尾
+//
+// 替换对象时保留对象中原有键的顺序，新增的键追加到末尾。
+// 有类型化访问方法的字段同时更新GetData返回的数据，值无法解码为对应类型时返回错误。
+func (p *PackageJSON) Set(key string, value any) error {
+	data, ok := value.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = marshalJSON(value); err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", key, err)
+		}
+	}
+
+	if key == "author" {
+		// author可以是字符串或对象，Author中统一保存为字符串
+		var author *npmiface.PackagePerson
+		if err := json.Unmarshal(data, &author); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		p.data.Author, p.data.AuthorObject = "", false
+		if author != nil {
+			p.data.Author, p.data.AuthorObject = author.String(), author.Object
+		}
+	} else if index, known := packageFields[key]; known {
+		field := reflect.ValueOf(p.data).Elem().Field(index)
+		decoded := reflect.New(field.Type())
+		if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		field.Set(decoded.Elem())
+	}
+
+	for i := range p.raw {
+		if p.raw[i].key == key {
+			p.raw[i].value = mergeMemberOrder(p.raw[i].value, data)
+			return nil
+		}
+	}
+	p.raw = append(p.raw, rawMember{key: key, value: data})
+	return nil
+}
+
+// Delete 删除顶层字段
+func (p *PackageJSON) Delete(key string) {
+	if index, known := packageFields[key]; known {
+		field := reflect.ValueOf(p.data).Elem().Field(index)
+		field.Set(reflect.Zero(field.Type()))
+	}
+	p.raw = removeMember(p.raw, key)
+}
@@ -0,0 +1,176 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const customFieldsManifest = `{
+  "name": "app",
+  "type": "module",
+  "version": "1.0.0",
+  "exports": {
+    ".": {
+      "import": "./dist/index.js",
+      "require": "./dist/index.cjs"
+    }
+  },
+  "engines": {
+    "node": ">=18"
+  },
+  "dependencies": {
+    "zod": "^3.0.0",
+    "axios": "^1.0.0"
+  },
+  "devDependencies": {
+    "vitest": "^1.0.0"
+  },
+  "browserslist": [
+    "defaults"
+  ],
+  "husky": {
+    "hooks": {
+      "pre-commit": "lint-staged"
+    }
+  }
+}`
+
+func TestPackageJSONPreservesUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte(customFieldsManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg := NewPackageJSON(path)
+	if err := pkg.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	// 没有修改时原样保存
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != customFieldsManifest {
+		t.Errorf("Unmodified save changed the file:\n%s", data)
+	}
+
+	pkg.SetVersion("1.1.0")
+	pkg.AddDependency("lodash", "^4.17.21")
+	pkg.GetDependencies()["zod"] = "^3.22.0"
+	pkg.RemoveDevDependency("vitest")
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	expected := `{
+  "name": "app",
+  "type": "module",
+  "version": "1.1.0",
+  "exports": {
+    ".": {
+      "import": "./dist/index.js",
+      "require": "./dist/index.cjs"
+    }
+  },
+  "engines": {
+    "node": ">=18"
+  },
+  "dependencies": {
+    "zod": "^3.22.0",
+    "axios": "^1.0.0",
+    "lodash": "^4.17.21"
+  },
+  "browserslist": [
+    "defaults"
+  ],
+  "husky": {
+    "hooks": {
+      "pre-commit": "lint-staged"
+    }
+  }
+}`
+	if data, _ := os.ReadFile(path); string(data) != expected {
+		t.Errorf("Unexpected package.json:\n%s", data)
+	}
+}
+
+func TestPackageJSONRawAccess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte(customFieldsManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg := NewPackageJSON(path)
+	if err := pkg.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	expectedKeys := []string{"name", "type", "version", "exports", "engines", "dependencies", "devDependencies", "browserslist", "husky"}
+	if keys := pkg.Keys(); !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("Expected keys %v, got %v", expectedKeys, keys)
+	}
+	if value, ok := pkg.Get("type"); !ok || string(value) != `"module"` {
+		t.Errorf("Unexpected type: %s, %v", value, ok)
+	}
+	if _, ok := pkg.Get("missing"); ok {
+		t.Error("Expected missing field")
+	}
+
+	if err := pkg.Set("engines", map[string]string{"node": ">=20"}); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := pkg.Set("sideEffects", false); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := pkg.Set("description", "An app"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if pkg.GetDescription() != "An app" {
+		t.Errorf("Set() did not update typed field, got %q", pkg.GetDescription())
+	}
+	if err := pkg.Set("private", "yes"); err == nil {
+		t.Error("Expected error for invalid typed value")
+	}
+	pkg.Delete("husky")
+	pkg.Delete("devDependencies")
+	if pkg.HasDevDependency("vitest") {
+		t.Error("Delete() did not clear typed field")
+	}
+
+	expectedKeys = []string{"name", "type", "version", "exports", "engines", "dependencies", "browserslist", "sideEffects", "description"}
+	if keys := pkg.Keys(); !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("Expected keys %v, got %v", expectedKeys, keys)
+	}
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	reloaded := NewPackageJSON(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if value, _ := reloaded.Get("engines"); string(value) != "{\n    \"node\": \">=20\"\n  }" {
+		t.Errorf("Unexpected engines: %s", value)
+	}
+	if value, _ := reloaded.Get("sideEffects"); string(value) != "false" {
+		t.Errorf("Unexpected sideEffects: %s", value)
+	}
+}
+
+func TestPackageJSONSaveWithoutVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte(`{"private": true, "scripts": {"dev": "vite"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg := NewPackageJSON(path)
+	if err := pkg.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	pkg.AddScript("build", "vite build")
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	expected := "{\n  \"private\": true,\n  \"scripts\": {\n    \"dev\": \"vite\",\n    \"build\": \"vite build\"\n  }\n}"
+	if data, _ := os.ReadFile(path); string(data) != expected {
+		t.Errorf("Unexpected package.json:\n%s", data)
+	}
+}
@@ -0,0 +1,241 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// spdxLicenses 常用的SPDX许可证标识符
+//
+// 列表不是完整的SPDX许可证列表，不在列表中的标识符只产生警告，不影响表达式是否有效。
+var spdxLicenses = spdxSet(
+	"0BSD", "AAL", "AFL-1.1", "AFL-1.2", "AFL-2.0", "AFL-2.1", "AFL-3.0", "AGPL-1.0-only", "AGPL-1.0-or-later",
+	"AGPL-3.0-only", "AGPL-3.0-or-later", "Apache-1.0", "Apache-1.1", "Apache-2.0", "APSL-2.0", "Artistic-1.0",
+	"Artistic-2.0", "Beerware", "BitTorrent-1.1", "BlueOak-1.0.0", "BSD-1-Clause", "BSD-2-Clause",
+	"BSD-2-Clause-Patent", "BSD-3-Clause", "BSD-3-Clause-Clear", "BSD-3-Clause-LBNL", "BSD-4-Clause", "BSL-1.0",
+	"BUSL-1.1", "bzip2-1.0.6", "CAL-1.0", "CC-BY-1.0", "CC-BY-2.0", "CC-BY-2.5", "CC-BY-3.0", "CC-BY-4.0",
+	"CC-BY-NC-4.0", "CC-BY-NC-ND-4.0", "CC-BY-NC-SA-4.0", "CC-BY-ND-4.0", "CC-BY-SA-3.0", "CC-BY-SA-4.0",
+	"CC-PDDC", "CC0-1.0", "CDDL-1.0", "CDDL-1.1", "CECILL-2.1", "CPAL-1.0", "CPL-1.0", "ECL-2.0", "EFL-2.0",
+	"Elastic-2.0", "EPL-1.0", "EPL-2.0", "EUPL-1.1", "EUPL-1.2", "GFDL-1.3-only", "GFDL-1.3-or-later",
+	"GPL-1.0-only", "GPL-1.0-or-later", "GPL-2.0-only", "GPL-2.0-or-later", "GPL-3.0-only", "GPL-3.0-or-later",
+	"Hippocratic-2.1", "HPND", "ICU", "IJG", "Imlib2", "IPA", "IPL-1.0", "ISC", "JSON", "LGPL-2.0-only",
+	"LGPL-2.0-or-later", "LGPL-2.1-only", "LGPL-2.1-or-later", "LGPL-3.0-only", "LGPL-3.0-or-later", "Libpng",
+	"libpng-2.0", "LPL-1.02", "LPPL-1.3c", "MIT", "MIT-0", "MIT-CMU", "MIT-Modern-Variant", "MirOS", "MPL-1.0",
+	"MPL-1.1", "MPL-2.0", "MPL-2.0-no-copyleft-exception", "MS-PL", "MS-RL", "MulanPSL-2.0", "NCSA", "NTP",
+	"ODbL-1.0", "OFL-1.0", "OFL-1.1", "OGL-UK-3.0", "OpenSSL", "OSL-1.0", "OSL-2.0", "OSL-2.1", "OSL-3.0",
+	"PDDL-1.0", "PHP-3.0", "PHP-3.01", "PolyForm-Noncommercial-1.0.0", "PolyForm-Small-Business-1.0.0",
+	"PostgreSQL", "PSF-2.0", "Python-2.0", "QPL-1.0", "Ruby", "SGI-B-2.0", "SISSL", "Sleepycat", "SSPL-1.0",
+	"TCL", "Unicode-3.0", "Unicode-DFS-2016", "Unlicense", "UPL-1.0", "Vim", "W3C", "W3C-20150513", "WTFPL",
+	"X11", "XFree86-1.1", "Zend-2.0", "Zlib", "zlib-acknowledgement", "ZPL-2.0", "ZPL-2.1",
+)
+
+// spdxDeprecated 已弃用的SPDX标识符及替代的标识符
+var spdxDeprecated = map[string]string{
+	"AGPL-1.0":             "AGPL-1.0-only",
+	"AGPL-3.0":             "AGPL-3.0-only",
+	"GFDL-1.3":             "GFDL-1.3-only",
+	"GPL-1.0":              "GPL-1.0-only",
+	"GPL-1.0+":             "GPL-1.0-or-later",
+	"GPL-2.0":              "GPL-2.0-only",
+	"GPL-2.0+":             "GPL-2.0-or-later",
+	"GPL-3.0":              "GPL-3.0-only",
+	"GPL-3.0+":             "GPL-3.0-or-later",
+	"LGPL-2.0":             "LGPL-2.0-only",
+	"LGPL-2.0+":            "LGPL-2.0-or-later",
+	"LGPL-2.1":             "LGPL-2.1-only",
+	"LGPL-2.1+":            "LGPL-2.1-or-later",
+	"LGPL-3.0":             "LGPL-3.0-only",
+	"LGPL-3.0+":            "LGPL-3.0-or-later",
+	"BSD-2-Clause-FreeBSD": "BSD-2-Clause",
+	"BSD-2-Clause-NetBSD":  "BSD-2-Clause",
+	"eCos-2.0":             "GPL-2.0-or-later WITH eCos-exception-2.0",
+	"wxWindows":            "GPL-2.0-or-later WITH WxWindows-exception-3.1",
+}
+
+// spdxExceptions 常用的SPDX许可证例外标识符，用在WITH之后
+var spdxExceptions = spdxSet(
+	"389-exception", "Autoconf-exception-2.0", "Autoconf-exception-3.0", "Bison-exception-2.2",
+	"Bootloader-exception", "Classpath-exception-2.0", "CLISP-exception-2.0", "eCos-exception-2.0",
+	"FLTK-exception", "Font-exception-2.0", "freertos-exception-2.0", "GCC-exception-2.0", "GCC-exception-3.1",
+	"gnu-javamail-exception", "GPL-3.0-linking-exception", "GPL-CC-1.0", "LGPL-3.0-linking-exception",
+	"Libtool-exception", "Linux-syscall-note", "LLVM-exception", "OCaml-LGPL-linking-exception",
+	"OpenJDK-assembly-exception-1.0", "Qt-GPL-exception-1.0", "Qt-LGPL-exception-1.1", "Swift-exception",
+	"u-boot-exception-2.0", "Universal-FOSS-exception-1.0", "WxWindows-exception-3.1",
+)
+
+// spdxSet 按小写建立标识符索引，值为规范的写法
+func spdxSet(ids ...string) map[string]string {
+	set := make(map[string]string, len(ids))
+	for _, id := range ids {
+		set[strings.ToLower(id)] = id
+	}
+	return set
+}
+
+// spdxParser SPDX许可证表达式的递归下降解析器
+//
+//	expression = and-expression *("OR" and-expression)
+//	and-expression = with-expression *("AND" with-expression)
+//	with-expression = "(" expression ")" / license ["WITH" exception]
+//	license = license-id ["+"] / ["DocumentRef-" idstring ":"] "LicenseRef-" idstring
+type spdxParser struct {
+	tokens   []string
+	pos      int
+	warnings []string
+}
+
+// tokenizeSPDX 把表达式拆分为括号、"+"和单词
+func tokenizeSPDX(expression string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == '+':
+			tokens = append(tokens, string(c))
+			i++
+		case isSPDXIDChar(c) || c == ':':
+			start := i
+			for i < len(expression) && (isSPDXIDChar(expression[i]) || expression[i] == ':') {
+				i++
+			}
+			tokens = append(tokens, expression[start:i])
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+// isSPDXIDChar 是否为SPDX标识符中允许的字符
+func isSPDXIDChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.'
+}
+
+func (p *spdxParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *spdxParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *spdxParser) parseExpression() error {
+	if err := p.parseAnd(); err != nil {
+		return err
+	}
+	for p.peek() == "OR" {
+		p.pos++
+		if err := p.parseAnd(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *spdxParser) parseAnd() error {
+	if err := p.parseWith(); err != nil {
+		return err
+	}
+	for p.peek() == "AND" {
+		p.pos++
+		if err := p.parseWith(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *spdxParser) parseWith() error {
+	if p.peek() == "(" {
+		p.pos++
+		if err := p.parseExpression(); err != nil {
+			return err
+		}
+		if p.next() != ")" {
+			return fmt.Errorf("missing closing parenthesis")
+		}
+		return nil
+	}
+	if err := p.parseLicense(); err != nil {
+		return err
+	}
+	if p.peek() == "WITH" {
+		p.pos++
+		exception := p.next()
+		if !isSPDXIdentifier(exception) {
+			return fmt.Errorf("expected a license exception after WITH")
+		}
+		if id, ok := spdxExceptions[strings.ToLower(exception)]; !ok {
+			p.warnings = append(p.warnings, fmt.Sprintf("%s is not a known SPDX license exception", exception))
+		} else if id != exception {
+			p.warnings = append(p.warnings, fmt.Sprintf("license exception %s should be written as %s", exception, id))
+		}
+	}
+	return nil
+}
+
+func (p *spdxParser) parseLicense() error {
+	token := p.next()
+	switch token {
+	case "":
+		return fmt.Errorf("unexpected end of expression")
+	case "AND", "OR", "WITH", "(", ")", "+":
+		return fmt.Errorf("unexpected %q", token)
+	}
+
+	if strings.HasPrefix(token, "LicenseRef-") || strings.HasPrefix(token, "DocumentRef-") {
+		document, ref, hasDocument := strings.Cut(token, ":")
+		if !hasDocument {
+			ref = document
+		} else if !strings.HasPrefix(document, "DocumentRef-") || len(document) == len("DocumentRef-") {
+			return fmt.Errorf("invalid document reference %q", token)
+		}
+		if !strings.HasPrefix(ref, "LicenseRef-") || len(ref) == len("LicenseRef-") || strings.Contains(ref, ":") {
+			return fmt.Errorf("invalid license reference %q", token)
+		}
+		return nil
+	}
+	if !isSPDXIdentifier(token) {
+		return fmt.Errorf("invalid license identifier %q", token)
+	}
+	switch strings.ToLower(token) {
+	case "and", "or", "with":
+		return fmt.Errorf("operator %q must be uppercase", token)
+	}
+
+	id := token
+	if p.peek() == "+" {
+		p.pos++
+		id += "+"
+	}
+	if replacement, ok := spdxDeprecated[id]; ok {
+		p.warnings = append(p.warnings, fmt.Sprintf("license %s is deprecated, use %s", id, replacement))
+		return nil
+	}
+	if known, ok := spdxLicenses[strings.ToLower(token)]; !ok {
+		p.warnings = append(p.warnings, fmt.Sprintf("%s is not a known SPDX license identifier", token))
+	} else if known != token {
+		p.warnings = append(p.warnings, fmt.Sprintf("license %s should be written as %s", token, known))
+	}
+	return nil
+}
+
+// isSPDXIdentifier 是否为合法的idstring
+func isSPDXIdentifier(token string) bool {
+	if token == "" {
+		return false
+	}
+	for i := 0; i < len(token); i++ {
+		if !isSPDXIDChar(token[i]) {
+			return false
+		}
+	}
+	return true
+}
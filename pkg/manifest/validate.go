@@ -0,0 +1,193 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+)
+
+// ValidationResult 包名或许可证的校验结果
+//
+// Errors中的问题使值无效；Warnings中的问题npm对已发布的旧包仍然接受，但新包不应该有。
+type ValidationResult struct {
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Valid 是否没有错误
+func (r ValidationResult) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// ValidForNewPackages 是否既没有错误也没有警告，即可以用于新发布的包
+func (r ValidationResult) ValidForNewPackages() bool {
+	return len(r.Errors) == 0 && len(r.Warnings) == 0
+}
+
+// ValidationReport package.json的完整校验结果
+type ValidationReport struct {
+	Errors   []*npmiface.ValidationError `json:"errors,omitempty"`
+	Warnings []*npmiface.ValidationError `json:"warnings,omitempty"`
+}
+
+// Valid 是否没有错误
+func (r *ValidationReport) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// scopedPackagePattern 带scope的包名，例如@scope/name
+var scopedPackagePattern = regexp.MustCompile(`^(?:@([^/]+?)/)?([^/]+?)$`)
+
+// packageNameBlacklist 不能使用的包名
+var packageNameBlacklist = []string{"node_modules", "favicon.ico"}
+
+// nodeBuiltinModules Node.js内置模块名，作为包名时会被内置模块遮盖
+var nodeBuiltinModules = []string{
+	"assert", "async_hooks", "buffer", "child_process", "cluster", "console", "constants", "crypto", "dgram",
+	"diagnostics_channel", "dns", "domain", "events", "fs", "http", "http2", "https", "inspector", "module", "net",
+	"os", "path", "perf_hooks", "process", "punycode", "querystring", "readline", "repl", "stream",
+	"string_decoder", "sys", "timers", "tls", "trace_events", "tty", "url", "util", "v8", "vm", "wasi",
+	"worker_threads", "zlib",
+}
+
+// ValidatePackageName 按validate-npm-package-name的规则校验包名
+//
+// 空名称、以"."或"_"开头、首尾有空格、黑名单中的名称以及URL中不安全的字符是错误；
+// 超过214个字符、大写字母、~'!()*等特殊字符以及与Node.js内置模块同名是警告。
+func ValidatePackageName(name string) ValidationResult {
+	var result ValidationResult
+	if name == "" {
+		result.Errors = append(result.Errors, "name length must be greater than zero")
+		return result
+	}
+	if strings.HasPrefix(name, ".") {
+		result.Errors = append(result.Errors, "name cannot start with a period")
+	}
+	if strings.HasPrefix(name, "_") {
+		result.Errors = append(result.Errors, "name cannot start with an underscore")
+	}
+	if strings.TrimSpace(name) != name {
+		result.Errors = append(result.Errors, "name cannot contain leading or trailing spaces")
+	}
+	lower := strings.ToLower(name)
+	for _, blacklisted := range packageNameBlacklist {
+		if lower == blacklisted {
+			result.Errors = append(result.Errors, blacklisted+" is a blacklisted name")
+		}
+	}
+	for _, builtin := range nodeBuiltinModules {
+		if lower == builtin {
+			result.Warnings = append(result.Warnings, builtin+" is a core module name")
+		}
+	}
+	if len(name) > 214 {
+		result.Warnings = append(result.Warnings, "name can no longer contain more than 214 characters")
+	}
+	if lower != name {
+		result.Warnings = append(result.Warnings, "name can no longer contain capital letters")
+	}
+	if base := name[strings.LastIndex(name, "/")+1:]; strings.ContainsAny(base, "~'!()*") {
+		result.Warnings = append(result.Warnings, `name can no longer contain special characters ("~'!()*")`)
+	}
+
+	if !isURIComponentSafe(name) {
+		match := scopedPackagePattern.FindStringSubmatch(name)
+		if match != nil && strings.HasPrefix(match[2], ".") {
+			result.Errors = append(result.Errors, "name cannot start with a period")
+		}
+		// 只有scope和名称之间的"/"可以不编码
+		if match == nil || !strings.HasPrefix(name, "@") || !isURIComponentSafe(match[1]) || !isURIComponentSafe(match[2]) {
+			result.Errors = append(result.Errors, "name can only contain URL-friendly characters")
+		}
+	}
+	return result
+}
+
+// isURIComponentSafe 字符串经encodeURIComponent编码后是否不变
+func isURIComponentSafe(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("-_.!~*'()", c) >= 0 {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// ValidateLicense 校验license字段是否为合法的SPDX许可证表达式
+//
+// 除SPDX表达式外也接受npm约定的"UNLICENSED"和"SEE LICENSE IN <文件>"。语法错误是错误；
+// 不认识的标识符、已弃用的标识符（例如GPL-2.0）和大小写不规范的标识符是警告。
+func ValidateLicense(license string) ValidationResult {
+	var result ValidationResult
+	expression := strings.TrimSpace(license)
+	switch {
+	case expression == "":
+		result.Errors = append(result.Errors, "license cannot be empty")
+		return result
+	case expression == "UNLICENSED" || expression == "UNLICENCED":
+		return result
+	case strings.HasPrefix(expression, "SEE LICENSE IN ") || strings.HasPrefix(expression, "SEE LICENCE IN "):
+		if strings.TrimSpace(expression[len("SEE LICENSE IN "):]) == "" {
+			result.Errors = append(result.Errors, "license file name is missing")
+		}
+		return result
+	}
+
+	tokens, err := tokenizeSPDX(expression)
+	if err == nil {
+		parser := &spdxParser{tokens: tokens}
+		if err = parser.parseExpression(); err == nil && parser.pos < len(tokens) {
+			err = fmt.Errorf("unexpected %q", tokens[parser.pos])
+		}
+		result.Warnings = parser.warnings
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid SPDX license expression: %v", err))
+		result.Warnings = nil
+	}
+	return result
+}
+
+// ValidateDetailed 校验package.json，返回所有错误和警告
+//
+// 包名中npm只对旧包接受的问题（大写字母、超长等）也是错误，因为这样的名称不能再发布；
+// 许可证的语法错误是错误，不认识或已弃用的标识符是警告，非私有包没有license时也给出警告。
+func (p *PackageJSON) ValidateDetailed() *ValidationReport {
+	report := &ValidationReport{}
+	addErrors := func(field, value string, reasons []string) {
+		for _, reason := range reasons {
+			report.Errors = append(report.Errors, npmiface.NewValidationError(field, value, reason))
+		}
+	}
+
+	if p.data.Name == "" {
+		addErrors("name", "", []string{"package name is required"})
+	} else {
+		name := ValidatePackageName(p.data.Name)
+		addErrors("name", p.data.Name, name.Errors)
+		addErrors("name", p.data.Name, name.Warnings)
+	}
+
+	if p.data.Version == "" {
+		addErrors("version", "", []string{"package version is required"})
+	} else if !isValidVersion(p.data.Version) {
+		addErrors("version", p.data.Version, []string{"invalid version format"})
+	}
+
+	if p.data.License == "" {
+		if !p.data.Private {
+			report.Warnings = append(report.Warnings, npmiface.NewValidationError("license", "", "no license field"))
+		}
+	} else {
+		license := ValidateLicense(p.data.License)
+		addErrors("license", p.data.License, license.Errors)
+		for _, warning := range license.Warnings {
+			report.Warnings = append(report.Warnings, npmiface.NewValidationError("license", p.data.License, warning))
+		}
+	}
+	return report
+}
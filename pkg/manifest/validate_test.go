@@ -0,0 +1,118 @@
+package manifest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidatePackageName(t *testing.T) {
+	tests := []struct {
+		name     string
+		errors   []string
+		warnings []string
+	}{
+		{"some-package", nil, nil},
+		{"example.com", nil, nil},
+		{"under_score", nil, nil},
+		{"@npm/thingy", nil, nil},
+		{"@jane/foo.js", nil, nil},
+		{"", []string{"name length must be greater than zero"}, nil},
+		{".start-with-period", []string{"name cannot start with a period"}, nil},
+		{"@npm/.thingy", []string{"name cannot start with a period"}, nil},
+		{"_start-with-underscore", []string{"name cannot start with an underscore"}, nil},
+		{" leading-space", []string{"name cannot contain leading or trailing spaces", "name can only contain URL-friendly characters"}, nil},
+		{"node_modules", []string{"node_modules is a blacklisted name"}, nil},
+		{"favicon.ico", []string{"favicon.ico is a blacklisted name"}, nil},
+		{"s/l/a/s/h/e/s", []string{"name can only contain URL-friendly characters"}, nil},
+		{"@npm-zors/money!time.js", nil, []string{`name can no longer contain special characters ("~'!()*")`}},
+		{"http", nil, []string{"http is a core module name"}},
+		{"CAPITAL-LETTERS", nil, []string{"name can no longer contain capital letters"}},
+		{strings.Repeat("a", 215), nil, []string{"name can no longer contain more than 214 characters"}},
+		{"ünicode", []string{"name can only contain URL-friendly characters"}, nil},
+	}
+	for _, tt := range tests {
+		result := ValidatePackageName(tt.name)
+		if !reflect.DeepEqual(result.Errors, tt.errors) || !reflect.DeepEqual(result.Warnings, tt.warnings) {
+			t.Errorf("ValidatePackageName(%q) = %+v, expected errors %v and warnings %v", tt.name, result, tt.errors, tt.warnings)
+		}
+		if result.Valid() != (len(tt.errors) == 0) || result.ValidForNewPackages() != (len(tt.errors) == 0 && len(tt.warnings) == 0) {
+			t.Errorf("ValidatePackageName(%q) validity mismatch: %+v", tt.name, result)
+		}
+	}
+}
+
+func TestValidateLicense(t *testing.T) {
+	tests := []struct {
+		license  string
+		valid    bool
+		warnings int
+	}{
+		{"MIT", true, 0},
+		{"(MIT OR Apache-2.0)", true, 0},
+		{"Apache-2.0 AND (MIT OR GPL-3.0-or-later)", true, 0},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0", true, 0},
+		{"LicenseRef-Proprietary", true, 0},
+		{"DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2", true, 0},
+		{"UNLICENSED", true, 0},
+		{"SEE LICENSE IN LICENSE.txt", true, 0},
+		{"EPL-1.0+", true, 0},
+		{"GPL-2.0", true, 1},
+		{"GPL-2.0+", true, 1},
+		{"mit", true, 1},
+		{"Some-Custom-License", true, 1},
+		{"MIT WITH Unknown-exception", true, 1},
+		{"", false, 0},
+		{"MIT OR", false, 0},
+		{"MIT or Apache-2.0", false, 0},
+		{"(MIT", false, 0},
+		{"MIT)", false, 0},
+		{"Apache 2.0", false, 0},
+		{"MIT/X11", false, 0},
+		{"LicenseRef-", false, 0},
+		{"SEE LICENSE IN ", false, 0},
+	}
+	for _, tt := range tests {
+		result := ValidateLicense(tt.license)
+		if result.Valid() != tt.valid || len(result.Warnings) != tt.warnings {
+			t.Errorf("ValidateLicense(%q) = %+v, expected valid %v with %d warnings", tt.license, result, tt.valid, tt.warnings)
+		}
+	}
+	if result := ValidateLicense("GPL-3.0"); result.Warnings[0] != "license GPL-3.0 is deprecated, use GPL-3.0-only" {
+		t.Errorf("Unexpected warning: %v", result.Warnings)
+	}
+}
+
+func TestPackageJSONValidateDetailed(t *testing.T) {
+	pkg := NewPackageJSON("package.json")
+	pkg.SetName("My-Package")
+	pkg.SetVersion("1.0.0")
+	pkg.SetLicense("GPL-2.0")
+
+	report := pkg.ValidateDetailed()
+	if report.Valid() || len(report.Errors) != 1 || report.Errors[0].Field != "name" {
+		t.Errorf("Unexpected errors: %+v", report.Errors)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Field != "license" {
+		t.Errorf("Unexpected warnings: %+v", report.Warnings)
+	}
+	if err := pkg.Validate(); err == nil || !strings.Contains(err.Error(), "capital letters") {
+		t.Errorf("Expected capital letters error, got %v", err)
+	}
+
+	pkg.SetName("my-package")
+	pkg.SetLicense("")
+	report = pkg.ValidateDetailed()
+	if !report.Valid() || len(report.Warnings) != 1 || report.Warnings[0].Reason != "no license field" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	pkg.SetPrivate(true)
+	if report := pkg.ValidateDetailed(); len(report.Warnings) != 0 {
+		t.Errorf("Expected no license warning for private package, got %+v", report.Warnings)
+	}
+
+	pkg.SetLicense("MIT OR")
+	if err := pkg.Validate(); err == nil || !strings.Contains(err.Error(), "license") {
+		t.Errorf("Expected license error, got %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// AddOwner 添加包所有者
+func (c *client) AddOwner(ctx context.Context, user, pkg string, options OwnerOptions) error {
+	executeOptions, err := c.addOwnerCommand(user, pkg, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runRegistryCommand(ctx, "owner", pkg, executeOptions)
+	return err
+}
+
+// addOwnerCommand 构造npm owner add的执行选项
+func (c *client) addOwnerCommand(user, pkg string, options OwnerOptions) (utils.ExecuteOptions, error) {
+	if err := validateOwnerArgs(user, pkg); err != nil {
+		return utils.ExecuteOptions{}, err
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "owner", "add", user, pkg), nil
+}
+
+// RemoveOwner 移除包所有者
+func (c *client) RemoveOwner(ctx context.Context, user, pkg string, options OwnerOptions) error {
+	executeOptions, err := c.removeOwnerCommand(user, pkg, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runRegistryCommand(ctx, "owner", pkg, executeOptions)
+	return err
+}
+
+// removeOwnerCommand 构造npm owner rm的执行选项
+func (c *client) removeOwnerCommand(user, pkg string, options OwnerOptions) (utils.ExecuteOptions, error) {
+	if err := validateOwnerArgs(user, pkg); err != nil {
+		return utils.ExecuteOptions{}, err
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "owner", "rm", user, pkg), nil
+}
+
+// ListOwners 列出包所有者
+func (c *client) ListOwners(ctx context.Context, pkg string, options OwnerOptions) ([]Owner, error) {
+	executeOptions, err := c.listOwnersCommand(pkg, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.runRegistryCommand(ctx, "owner", pkg, executeOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseOwnerList(result.Stdout), nil
+}
+
+// listOwnersCommand 构造npm owner ls的执行选项
+func (c *client) listOwnersCommand(pkg string, options OwnerOptions) (utils.ExecuteOptions, error) {
+	if pkg == "" {
+		return utils.ExecuteOptions{}, NewValidationError("package", pkg, "package name cannot be empty")
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "owner", "ls", pkg), nil
+}
+
+// GetAccess 获取包访问级别
+func (c *client) GetAccess(ctx context.Context, pkg string, options AccessOptions) (AccessLevel, error) {
+	executeOptions, err := c.getAccessCommand(pkg, options)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := c.runRegistryCommand(ctx, "access", pkg, executeOptions)
+	if err != nil {
+		return "", err
+	}
+
+	return parseAccessStatus(result.Stdout, pkg)
+}
+
+// getAccessCommand 构造npm access get status的执行选项
+func (c *client) getAccessCommand(pkg string, options AccessOptions) (utils.ExecuteOptions, error) {
+	if pkg == "" {
+		return utils.ExecuteOptions{}, NewValidationError("package", pkg, "package name cannot be empty")
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "access", "get", "status", pkg, "--json"), nil
+}
+
+// SetAccess 设置包访问级别
+func (c *client) SetAccess(ctx context.Context, pkg string, level AccessLevel, options AccessOptions) error {
+	executeOptions, err := c.setAccessCommand(pkg, level, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runRegistryCommand(ctx, "access", pkg, executeOptions)
+	return err
+}
+
+// setAccessCommand 构造npm access set的执行选项
+func (c *client) setAccessCommand(pkg string, level AccessLevel, options AccessOptions) (utils.ExecuteOptions, error) {
+	if pkg == "" {
+		return utils.ExecuteOptions{}, NewValidationError("package", pkg, "package name cannot be empty")
+	}
+
+	// npm 9起使用status=public|private代替access public|restricted
+	var status string
+	switch level {
+	case AccessPublic:
+		status = "status=public"
+	case AccessRestricted:
+		status = "status=private"
+	default:
+		return utils.ExecuteOptions{}, NewValidationError("level", string(level), "access level must be public or restricted")
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "access", "set", status, pkg), nil
+}
+
+// GrantAccess 授予团队权限，team格式为scope:team
+func (c *client) GrantAccess(ctx context.Context, pkg, team string, permission Permission, options AccessOptions) error {
+	executeOptions, err := c.grantAccessCommand(pkg, team, permission, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runRegistryCommand(ctx, "access", pkg, executeOptions)
+	return err
+}
+
+// grantAccessCommand 构造npm access grant的执行选项
+func (c *client) grantAccessCommand(pkg, team string, permission Permission, options AccessOptions) (utils.ExecuteOptions, error) {
+	if err := validateTeamArgs(pkg, team); err != nil {
+		return utils.ExecuteOptions{}, err
+	}
+	if permission != PermissionReadOnly && permission != PermissionReadWrite {
+		return utils.ExecuteOptions{}, NewValidationError("permission", string(permission), "permission must be read-only or read-write")
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "access", "grant", string(permission), team, pkg), nil
+}
+
+// RevokeAccess 撤销团队权限，team格式为scope:team
+func (c *client) RevokeAccess(ctx context.Context, pkg, team string, options AccessOptions) error {
+	executeOptions, err := c.revokeAccessCommand(pkg, team, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runRegistryCommand(ctx, "access", pkg, executeOptions)
+	return err
+}
+
+// revokeAccessCommand 构造npm access revoke的执行选项
+func (c *client) revokeAccessCommand(pkg, team string, options AccessOptions) (utils.ExecuteOptions, error) {
+	if err := validateTeamArgs(pkg, team); err != nil {
+		return utils.ExecuteOptions{}, err
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "access", "revoke", team, pkg), nil
+}
+
+// ListCollaborators 列出包协作者及其权限
+func (c *client) ListCollaborators(ctx context.Context, pkg string, options AccessOptions) (map[string]Permission, error) {
+	executeOptions, err := c.listCollaboratorsCommand(pkg, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.runRegistryCommand(ctx, "access", pkg, executeOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	collaborators := make(map[string]Permission)
+	output := strings.TrimSpace(result.Stdout)
+	if output == "" {
+		return collaborators, nil
+	}
+	if err := json.Unmarshal([]byte(output), &collaborators); err != nil {
+		return nil, fmt.Errorf("failed to parse collaborators: %w", err)
+	}
+
+	return collaborators, nil
+}
+
+// listCollaboratorsCommand 构造npm access list collaborators的执行选项
+func (c *client) listCollaboratorsCommand(pkg string, options AccessOptions) (utils.ExecuteOptions, error) {
+	if pkg == "" {
+		return utils.ExecuteOptions{}, NewValidationError("package", pkg, "package name cannot be empty")
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "access", "list", "collaborators", pkg, "--json"), nil
+}
+
+// registryCommand 构造需要访问registry的npm命令的执行选项
+func (c *client) registryCommand(registry, otp string, env map[string]string, args ...string) utils.ExecuteOptions {
+	if registry != "" {
+		args = append(args, "--registry", registry)
+	}
+	if otp != "" {
+		args = append(args, "--otp", otp)
+	}
+
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          args,
+		Env:           env,
+		CaptureOutput: true,
+		Timeout:       1 * time.Minute,
+	}
+}
+
+// runRegistryCommand 执行需要访问registry的npm命令
+func (c *client) runRegistryCommand(ctx context.Context, op, pkg string, executeOptions utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return nil, NewNpmError(op, pkg, -1, "", "", err)
+		}
+		return nil, NewNpmError(op, pkg, result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	if !result.Success {
+		return nil, NewNpmError(op, pkg, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm %s failed", op))
+	}
+
+	return result, nil
+}
+
+// validateOwnerArgs 校验所有者管理参数
+func validateOwnerArgs(user, pkg string) error {
+	if user == "" {
+		return NewValidationError("user", user, "user name cannot be empty")
+	}
+	if pkg == "" {
+		return NewValidationError("package", pkg, "package name cannot be empty")
+	}
+	return nil
+}
+
+// validateTeamArgs 校验团队权限参数
+func validateTeamArgs(pkg, team string) error {
+	if pkg == "" {
+		return NewValidationError("package", pkg, "package name cannot be empty")
+	}
+	scope, name, found := strings.Cut(team, ":")
+	if !found || strings.TrimPrefix(scope, "@") == "" || name == "" {
+		return NewValidationError("team", team, "team must be in the form scope:team")
+	}
+	return nil
+}
+
+// parseOwnerList 解析npm owner ls的输出，每行格式为"name <email>"
+func parseOwnerList(output string) []Owner {
+	var owners []Owner
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		owner := Owner{Name: line}
+		if idx := strings.Index(line, " <"); idx >= 0 {
+			owner.Name = line[:idx]
+			owner.Email = strings.TrimSuffix(line[idx+2:], ">")
+		}
+		owners = append(owners, owner)
+	}
+	return owners
+}
+
+// parseAccessStatus 解析npm access get status --json的输出
+func parseAccessStatus(output, pkg string) (AccessLevel, error) {
+	var status map[string]string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &status); err != nil {
+		return "", fmt.Errorf("failed to parse access status: %w", err)
+	}
+
+	value, ok := status[pkg]
+	if !ok {
+		for _, v := range status {
+			value = v
+			break
+		}
+	}
+
+	switch value {
+	case "public":
+		return AccessPublic, nil
+	case "private", "restricted":
+		return AccessRestricted, nil
+	default:
+		return "", fmt.Errorf("unexpected access status %q", value)
+	}
+}
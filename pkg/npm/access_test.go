@@ -0,0 +1,84 @@
+package npm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAccessValidation(t *testing.T) {
+	c := &client{}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"AddOwner without user", c.AddOwner(ctx, "", "pkg", OwnerOptions{})},
+		{"RemoveOwner without package", c.RemoveOwner(ctx, "alice", "", OwnerOptions{})},
+		{"SetAccess with invalid level", c.SetAccess(ctx, "pkg", AccessLevel("secret"), AccessOptions{})},
+		{"GrantAccess with invalid team", c.GrantAccess(ctx, "pkg", "team", PermissionReadOnly, AccessOptions{})},
+		{"GrantAccess with invalid permission", c.GrantAccess(ctx, "pkg", "@org:devs", Permission("admin"), AccessOptions{})},
+		{"RevokeAccess with empty scope", c.RevokeAccess(ctx, "pkg", ":devs", AccessOptions{})},
+		{"Unpublish without spec", c.Unpublish(ctx, "", UnpublishOptions{})},
+		{"Unpublish scoped package without version", c.Unpublish(ctx, "@scope/pkg", UnpublishOptions{})},
+	}
+
+	for _, tt := range tests {
+		if !IsValidationError(tt.err, nil) {
+			t.Errorf("%s: expected validation error, got %v", tt.name, tt.err)
+		}
+	}
+
+	if _, err := c.ListOwners(ctx, "", OwnerOptions{}); !IsValidationError(err, nil) {
+		t.Errorf("ListOwners: expected validation error, got %v", err)
+	}
+	if _, err := c.GetAccess(ctx, "", AccessOptions{}); !IsValidationError(err, nil) {
+		t.Errorf("GetAccess: expected validation error, got %v", err)
+	}
+	if _, err := c.ListCollaborators(ctx, "", AccessOptions{}); !IsValidationError(err, nil) {
+		t.Errorf("ListCollaborators: expected validation error, got %v", err)
+	}
+}
+
+func TestParseOwnerList(t *testing.T) {
+	owners := parseOwnerList("alice <alice@example.com>\nbob <bob@example.com>\n\ncarol\n")
+
+	if len(owners) != 3 {
+		t.Fatalf("Expected 3 owners, got %d", len(owners))
+	}
+	if owners[0].Name != "alice" || owners[0].Email != "alice@example.com" {
+		t.Errorf("Unexpected first owner: %+v", owners[0])
+	}
+	if owners[2].Name != "carol" || owners[2].Email != "" {
+		t.Errorf("Expected owner without email, got %+v", owners[2])
+	}
+}
+
+func TestParseAccessStatus(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected AccessLevel
+	}{
+		{`{"@org/pkg": "public"}`, AccessPublic},
+		{`{"@org/pkg": "private"}`, AccessRestricted},
+		{`{"other": "restricted"}`, AccessRestricted},
+	}
+
+	for _, tt := range tests {
+		level, err := parseAccessStatus(tt.output, "@org/pkg")
+		if err != nil {
+			t.Errorf("parseAccessStatus(%s) failed: %v", tt.output, err)
+			continue
+		}
+		if level != tt.expected {
+			t.Errorf("parseAccessStatus(%s) = %s, expected %s", tt.output, level, tt.expected)
+		}
+	}
+
+	if _, err := parseAccessStatus("not json", "pkg"); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+	if _, err := parseAccessStatus(`{"pkg": "unknown"}`, "pkg"); err == nil {
+		t.Error("Expected error for unknown status")
+	}
+}
@@ -0,0 +1,230 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// Severity 漏洞严重程度
+type Severity = npmiface.Severity
+
+const (
+	SeverityInfo     = npmiface.SeverityInfo
+	SeverityLow      = npmiface.SeverityLow
+	SeverityModerate = npmiface.SeverityModerate
+	SeverityHigh     = npmiface.SeverityHigh
+	SeverityCritical = npmiface.SeverityCritical
+)
+
+// AuditOptions 安全审计选项
+type AuditOptions = npmiface.AuditOptions
+
+// Advisory 安全公告
+type Advisory = npmiface.Advisory
+
+// AuditFix npm给出的修复方式
+type AuditFix = npmiface.AuditFix
+
+// AuditFinding 单个受影响的包
+type AuditFinding = npmiface.AuditFinding
+
+// AuditReport 安全审计报告
+type AuditReport = npmiface.AuditReport
+
+// Audit 运行npm audit并解析结果
+func (c *client) Audit(ctx context.Context, options AuditOptions) (*AuditReport, error) {
+	executeOptions := c.auditCommand(options)
+
+	report, err := c.audit(ctx, options, executeOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.IgnoreFile != "" {
+		ignores, err := LoadAuditIgnoreFile(options.IgnoreFile)
+		if err != nil {
+			return nil, err
+		}
+		report = report.ApplyIgnores(ignores, time.Now())
+	}
+
+	return report, nil
+}
+
+// auditCommand 构造npm audit的执行选项
+func (c *client) auditCommand(options AuditOptions) utils.ExecuteOptions {
+	args := []string{"audit", "--json"}
+
+	// 构建参数
+	if options.Production {
+		args = append(args, "--omit=dev")
+	}
+	if options.Registry != "" {
+		args = append(args, "--registry", options.Registry)
+	}
+
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          args,
+		Env:           commandEnv(options.Env, options.UserConfig),
+		WorkingDir:    options.WorkingDir,
+		CaptureOutput: true,
+		Timeout:       2 * time.Minute,
+	}
+}
+
+// audit 运行npm audit，失败时使用WithOSVAudit设置的扫描器，找不到npm时回退到registry的批量安全公告接口
+func (c *client) audit(ctx context.Context, options AuditOptions, executeOptions utils.ExecuteOptions) (*AuditReport, error) {
+	// 存在漏洞时npm audit以非零状态退出，此时输出仍然是完整的报告
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if result == nil {
+		return nil, NewNpmError("audit", "", -1, "", "", err)
+	}
+
+	report, parseErr := ParseAuditJSON([]byte(result.Stdout))
+	if parseErr != nil {
+		if err == nil {
+			err = parseErr
+		}
+		npmErr := NewNpmError("audit", "", result.ExitCode, result.Stdout, result.Stderr, err)
+		if c.auditScanner != nil {
+			return c.auditScanner.Scan(ctx, options)
+		}
+		if c.canFallback(npmErr) {
+			return registryAudit(ctx, c.fallback, options)
+		}
+		return nil, npmErr
+	}
+	return report, nil
+}
+
+// ParseAuditJSON 解析npm audit --json的输出（auditReportVersion 2）
+func ParseAuditJSON(data []byte) (*AuditReport, error) {
+	var raw struct {
+		Error *struct {
+			Code    string `json:"code"`
+			Summary string `json:"summary"`
+		} `json:"error"`
+		AuditReportVersion int `json:"auditReportVersion"`
+		Vulnerabilities    map[string]struct {
+			Name         string            `json:"name"`
+			Severity     Severity          `json:"severity"`
+			IsDirect     bool              `json:"isDirect"`
+			Via          []json.RawMessage `json:"via"`
+			Effects      []string          `json:"effects"`
+			Range        string            `json:"range"`
+			Nodes        []string          `json:"nodes"`
+			FixAvailable json.RawMessage   `json:"fixAvailable"`
+		} `json:"vulnerabilities"`
+		Metadata struct {
+			Vulnerabilities map[string]int `json:"vulnerabilities"`
+			Dependencies    struct {
+				Total int `json:"total"`
+			} `json:"dependencies"`
+		} `json:"metadata"`
+	}
+
+	if err := utils.DecodeJSON("npm audit output", data, &raw); err != nil {
+		return nil, err
+	}
+	if raw.Error != nil {
+		return nil, fmt.Errorf("npm audit failed: %s: %s", raw.Error.Code, raw.Error.Summary)
+	}
+	if raw.AuditReportVersion != 0 && raw.AuditReportVersion != 2 {
+		return nil, fmt.Errorf("unsupported audit report version %d", raw.AuditReportVersion)
+	}
+
+	report := &AuditReport{
+		Counts:       make(map[Severity]int),
+		Dependencies: raw.Metadata.Dependencies.Total,
+	}
+
+	for name, vuln := range raw.Vulnerabilities {
+		finding := AuditFinding{
+			Name:     vuln.Name,
+			Severity: vuln.Severity,
+			IsDirect: vuln.IsDirect,
+			Effects:  vuln.Effects,
+			Range:    vuln.Range,
+			Nodes:    vuln.Nodes,
+		}
+		if finding.Name == "" {
+			finding.Name = name
+		}
+
+		for _, via := range vuln.Via {
+			var dependency string
+			if json.Unmarshal(via, &dependency) == nil {
+				finding.Via = append(finding.Via, dependency)
+				continue
+			}
+
+			var advisory struct {
+				Source   int      `json:"source"`
+				Name     string   `json:"name"`
+				Title    string   `json:"title"`
+				URL      string   `json:"url"`
+				Severity Severity `json:"severity"`
+				CWE      []string `json:"cwe"`
+				CVSS     struct {
+					Score float64 `json:"score"`
+				} `json:"cvss"`
+				Range string `json:"range"`
+			}
+			if err := json.Unmarshal(via, &advisory); err != nil {
+				return nil, fmt.Errorf("failed to parse advisory for %s: %w", name, err)
+			}
+			finding.Advisories = append(finding.Advisories, Advisory{
+				Source:    advisory.Source,
+				Name:      advisory.Name,
+				Title:     advisory.Title,
+				URL:       advisory.URL,
+				Severity:  advisory.Severity,
+				CWE:       advisory.CWE,
+				CVSSScore: advisory.CVSS.Score,
+				Range:     advisory.Range,
+			})
+		}
+
+		// fixAvailable可能是布尔值或描述需要更新的包的对象
+		var available bool
+		if json.Unmarshal(vuln.FixAvailable, &available) == nil {
+			finding.Fix.Available = available
+		} else {
+			var fix struct {
+				Name          string `json:"name"`
+				Version       string `json:"version"`
+				IsSemVerMajor bool   `json:"isSemVerMajor"`
+			}
+			if err := json.Unmarshal(vuln.FixAvailable, &fix); err == nil {
+				finding.Fix = AuditFix{Available: true, Name: fix.Name, Version: fix.Version, IsSemVerMajor: fix.IsSemVerMajor}
+			}
+		}
+
+		report.Findings = append(report.Findings, finding)
+	}
+
+	sort.Slice(report.Findings, func(i, j int) bool {
+		return report.Findings[i].Name < report.Findings[j].Name
+	})
+
+	if len(raw.Metadata.Vulnerabilities) > 0 {
+		for _, severity := range []Severity{SeverityInfo, SeverityLow, SeverityModerate, SeverityHigh, SeverityCritical} {
+			report.Counts[severity] = raw.Metadata.Vulnerabilities[string(severity)]
+		}
+		report.Total = raw.Metadata.Vulnerabilities["total"]
+	} else {
+		for _, finding := range report.Findings {
+			report.Counts[finding.Severity]++
+		}
+		report.Total = len(report.Findings)
+	}
+
+	return report, nil
+}
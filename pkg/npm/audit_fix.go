@@ -0,0 +1,392 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/lockfile"
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// FixKind 修复方式
+type FixKind string
+
+const (
+	FixDirectBump  FixKind = "direct"   // 提升package.json中直接依赖的版本范围
+	FixOverride    FixKind = "override" // 通过overrides强制传递依赖的版本
+	FixUnavailable FixKind = "none"     // 没有不受影响的版本
+)
+
+// FixAction 单个包的修复动作
+type FixAction struct {
+	Package    string         `json:"package"`
+	Kind       FixKind        `json:"kind"`
+	Type       DependencyType `json:"type,omitempty"` // 直接依赖的类型
+	Installed  string         `json:"installed,omitempty"`
+	From       string         `json:"from,omitempty"` // 修改前的版本范围
+	To         string         `json:"to,omitempty"`   // 修改后的版本范围
+	Breaking   bool           `json:"breaking"`       // 是否跨越了不兼容的版本
+	Severity   Severity       `json:"severity"`
+	Advisories []string       `json:"advisories"`
+	Reason     string         `json:"reason,omitempty"`
+}
+
+// FixPlan 修复计划
+type FixPlan struct {
+	Actions []FixAction `json:"actions"`
+}
+
+// Applicable 可以自动应用的修复动作
+func (p *FixPlan) Applicable() []FixAction {
+	var actions []FixAction
+	for _, action := range p.Actions {
+		if action.Kind != FixUnavailable {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// Unfixable 没有可用修复的动作
+func (p *FixPlan) Unfixable() []FixAction {
+	var actions []FixAction
+	for _, action := range p.Actions {
+		if action.Kind == FixUnavailable {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// AuditFixOptions 自动修复选项
+type AuditFixOptions struct {
+	Production  bool `json:"production,omitempty"`   // 只审计生产依赖
+	DryRun      bool `json:"dry_run,omitempty"`      // 只生成计划，不修改文件
+	SkipInstall bool `json:"skip_install,omitempty"` // 修改后不重新安装，重新审计的结果可能不准确
+}
+
+// AuditFixResult 自动修复结果
+type AuditFixResult struct {
+	Plan     *FixPlan     `json:"plan"`
+	Applied  []FixAction  `json:"applied,omitempty"`
+	Before   *AuditReport `json:"before"`
+	After    *AuditReport `json:"after,omitempty"`
+	Resolved []string     `json:"resolved,omitempty"` // 修复后不再出现的公告
+}
+
+// VersionLister 列出包在registry上的所有版本
+type VersionLister func(ctx context.Context, name string) ([]string, error)
+
+// AuditFixer 根据审计结果生成并应用最小修复
+//
+// 对每个直接受公告影响的包，选择高于当前安装版本且不在任何公告范围内的最低版本：
+// 直接依赖提升package.json中的版本范围，传递依赖写入overrides，找不到这样的版本时
+// 记录为无法修复。只因依赖其他受影响包而出现在报告中的包不单独处理。
+type AuditFixer struct {
+	client     Client
+	workingDir string
+	versions   VersionLister
+}
+
+// NewAuditFixer 创建自动修复器，默认通过npm view获取版本列表
+func NewAuditFixer(client Client, workingDir string) *AuditFixer {
+	executor := utils.NewExecutor()
+	return &AuditFixer{
+		client:     client,
+		workingDir: workingDir,
+		versions: func(ctx context.Context, name string) ([]string, error) {
+			return npmViewVersions(ctx, executor, name)
+		},
+	}
+}
+
+// SetVersionLister 设置版本列表来源
+func (f *AuditFixer) SetVersionLister(lister VersionLister) {
+	f.versions = lister
+}
+
+// Fix 审计、生成计划、应用修复并重新审计
+func (f *AuditFixer) Fix(ctx context.Context, options AuditFixOptions) (*AuditFixResult, error) {
+	auditOptions := AuditOptions{
+		Production: options.Production,
+		WorkingDir: f.workingDir,
+	}
+
+	before, err := f.client.Audit(ctx, auditOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := f.Plan(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AuditFixResult{
+		Plan:   plan,
+		Before: before,
+	}
+	if options.DryRun || len(plan.Applicable()) == 0 {
+		return result, nil
+	}
+
+	if result.Applied, err = f.Apply(plan); err != nil {
+		return nil, err
+	}
+
+	if !options.SkipInstall {
+		if err := f.client.InstallPackages(ctx, nil, InstallOptions{WorkingDir: f.workingDir}); err != nil {
+			return nil, fmt.Errorf("failed to reinstall after applying fixes: %w", err)
+		}
+	}
+
+	if result.After, err = f.client.Audit(ctx, auditOptions); err != nil {
+		return nil, err
+	}
+
+	remaining := make(map[string]bool)
+	for _, finding := range result.After.Findings {
+		for _, advisory := range finding.Advisories {
+			remaining[advisory.ID()] = true
+		}
+	}
+	for _, finding := range before.Findings {
+		for _, advisory := range finding.Advisories {
+			if !remaining[advisory.ID()] {
+				result.Resolved = appendUnique(result.Resolved, advisory.ID())
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Plan 为审计报告中的每个问题确定最小修复方式
+func (f *AuditFixer) Plan(ctx context.Context, report *AuditReport) (*FixPlan, error) {
+	pkg := NewPackageJSON(filepath.Join(f.workingDir, "package.json"))
+	if err := pkg.Load(); err != nil {
+		return nil, err
+	}
+	direct := map[DependencyType]map[string]string{
+		Production:  pkg.GetDependencies(),
+		Development: pkg.GetDevDependencies(),
+		Optional:    pkg.GetOptionalDependencies(),
+	}
+
+	installed := make(map[string][]string)
+	// npm-shrinkwrap.json存在时npm忽略package-lock.json
+	for _, name := range []string{"npm-shrinkwrap.json", "package-lock.json"} {
+		if lock, err := lockfile.Load(filepath.Join(f.workingDir, name)); err == nil {
+			installed = lock.ResolvedSet()
+			break
+		}
+	}
+
+	plan := &FixPlan{}
+	for _, finding := range report.Findings {
+		if len(finding.Advisories) == 0 {
+			continue
+		}
+
+		action := FixAction{
+			Package:  finding.Name,
+			Kind:     FixOverride,
+			Severity: finding.Severity,
+		}
+		for _, advisory := range finding.Advisories {
+			action.Advisories = appendUnique(action.Advisories, advisory.ID())
+		}
+		if finding.IsDirect {
+			for _, depType := range []DependencyType{Production, Development, Optional} {
+				if spec, ok := direct[depType][finding.Name]; ok {
+					action.Kind = FixDirectBump
+					action.Type = depType
+					action.From = spec
+					break
+				}
+			}
+		}
+
+		versions, err := f.versions(ctx, finding.Name)
+		if err != nil {
+			action.Kind = FixUnavailable
+			action.Reason = fmt.Sprintf("failed to list versions: %v", err)
+			plan.Actions = append(plan.Actions, action)
+			continue
+		}
+
+		floor, safe := minimalSafeVersion(versions, finding, installed[finding.Name])
+		action.Installed = floor
+		if safe == "" {
+			action.Kind = FixUnavailable
+			action.Reason = "no published version is outside the vulnerable ranges"
+			plan.Actions = append(plan.Actions, action)
+			continue
+		}
+
+		action.Breaking = floor != "" && !semver.Satisfies(safe, "^"+floor)
+		if action.Kind == FixDirectBump {
+			action.To = rangeWithOperator(action.From, safe)
+		} else {
+			action.To = "^" + safe
+		}
+		plan.Actions = append(plan.Actions, action)
+	}
+
+	return plan, nil
+}
+
+// Apply 把修复计划写入package.json，返回已应用的动作
+func (f *AuditFixer) Apply(plan *FixPlan) ([]FixAction, error) {
+	path := filepath.Join(f.workingDir, "package.json")
+
+	var updates []DependencyUpdate
+	var overrides []FixAction
+	for _, action := range plan.Applicable() {
+		switch action.Kind {
+		case FixDirectBump:
+			updates = append(updates, DependencyUpdate{Name: action.Package, From: action.From, To: action.To, Type: action.Type})
+		case FixOverride:
+			overrides = append(overrides, action)
+		}
+	}
+
+	var applied []FixAction
+	if len(updates) > 0 {
+		if _, err := updateManifestRanges(path, updates); err != nil {
+			return nil, err
+		}
+		for _, action := range plan.Applicable() {
+			if action.Kind == FixDirectBump {
+				applied = append(applied, action)
+			}
+		}
+	}
+
+	if len(overrides) > 0 {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package.json: %w", err)
+		}
+		content := string(data)
+		for _, action := range overrides {
+			if content, err = setJSONStringMember(content, []string{"overrides"}, action.Package, action.To); err != nil {
+				return nil, fmt.Errorf("failed to add override for %s: %w", action.Package, err)
+			}
+			applied = append(applied, action)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write package.json: %w", err)
+		}
+	}
+
+	return applied, nil
+}
+
+// minimalSafeVersion 返回当前受影响的最高安装版本，以及高于它且不受任何公告影响的最低正式版本
+func minimalSafeVersion(versions []string, finding AuditFinding, installed []string) (string, string) {
+	var ranges []*semver.Range
+	for _, advisory := range finding.Advisories {
+		if r, err := semver.ParseRange(advisory.Range); err == nil {
+			ranges = append(ranges, r)
+		}
+	}
+	affected := func(raw string) bool {
+		v, err := semver.Parse(raw)
+		if err != nil {
+			return false
+		}
+		for _, r := range ranges {
+			if r.Contains(v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// 以受影响的最高安装版本为起点，没有锁文件时退回到受影响的最低发布版本
+	floor := ""
+	for _, version := range installed {
+		if affected(version) && (floor == "" || semver.Compare(version, floor) > 0) {
+			floor = version
+		}
+	}
+	if floor == "" {
+		floor = semver.MinSatisfying(versions, finding.Range)
+	}
+
+	sorted := append([]string(nil), versions...)
+	semver.Sort(sorted)
+	for _, version := range sorted {
+		v, err := semver.Parse(version)
+		if err != nil || v.IsPrerelease() || affected(version) {
+			continue
+		}
+		if floor != "" && semver.Compare(version, floor) <= 0 {
+			continue
+		}
+		return floor, version
+	}
+	return floor, ""
+}
+
+// rangeWithOperator 保留原版本范围的^或~前缀，精确版本保持精确
+func rangeWithOperator(spec, version string) string {
+	switch {
+	case strings.HasPrefix(spec, "~"):
+		return "~" + version
+	case semver.Valid(spec):
+		return version
+	}
+	return "^" + version
+}
+
+// npmViewVersions 通过npm view获取包的所有版本
+func npmViewVersions(ctx context.Context, executor *utils.Executor, name string) ([]string, error) {
+	executeOptions := utils.ExecuteOptions{
+		Command:       "npm",
+		Args:          []string{"view", name, "versions", "--json"},
+		CaptureOutput: true,
+		Timeout:       30 * time.Second,
+	}
+
+	result, err := executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return nil, NewNpmError("view", name, -1, "", "", err)
+		}
+		return nil, NewNpmError("view", name, result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	return parseVersionsJSON([]byte(result.Stdout))
+}
+
+// parseVersionsJSON 解析npm view versions --json的输出，只有一个版本时输出为字符串
+func parseVersionsJSON(data []byte) ([]string, error) {
+	var versions []string
+	if err := json.Unmarshal(data, &versions); err == nil {
+		return versions, nil
+	}
+
+	var version string
+	if err := json.Unmarshal(data, &version); err != nil {
+		return nil, fmt.Errorf("failed to parse versions: %w", err)
+	}
+	return []string{version}, nil
+}
+
+// appendUnique 追加不重复的值
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,194 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupAuditFixProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{
+  "name": "app",
+  "dependencies": {
+    "express": "^4.17.1",
+    "lodash": "~4.17.15"
+  }
+}
+`)
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"express": "^4.17.1", "lodash": "~4.17.15"}},
+    "node_modules/express": {"version": "4.17.1", "dependencies": {"qs": "6.7.0"}},
+    "node_modules/lodash": {"version": "4.17.15"},
+    "node_modules/qs": {"version": "6.7.0"}
+  }
+}
+`)
+	return dir
+}
+
+func testVersionLister(ctx context.Context, name string) ([]string, error) {
+	switch name {
+	case "qs":
+		return []string{"6.5.3", "6.7.0", "6.7.1", "6.7.2", "6.7.3-beta", "6.7.3", "6.8.0"}, nil
+	case "lodash":
+		return []string{"4.17.15", "4.17.20", "4.17.21"}, nil
+	case "minimist":
+		return []string{"0.0.8", "1.2.5"}, nil
+	}
+	return nil, fmt.Errorf("package %s not found", name)
+}
+
+func TestAuditFixerPlan(t *testing.T) {
+	dir := setupAuditFixProject(t)
+	report, err := ParseAuditJSON([]byte(auditFixture))
+	if err != nil {
+		t.Fatalf("ParseAuditJSON() failed: %v", err)
+	}
+	report.Findings = append(report.Findings, AuditFinding{
+		Name:       "minimist",
+		Severity:   SeverityModerate,
+		Advisories: []Advisory{{Source: 2, Range: "<=1.2.5"}},
+		Range:      "<=1.2.5",
+	}, AuditFinding{
+		Name:       "unlisted",
+		Severity:   SeverityLow,
+		Advisories: []Advisory{{Source: 3, Range: "<1.0.0"}},
+	})
+
+	fixer := NewAuditFixer(NewMockClient(), dir)
+	fixer.SetVersionLister(testVersionLister)
+	plan, err := fixer.Plan(context.Background(), report)
+	if err != nil {
+		t.Fatalf("Plan() failed: %v", err)
+	}
+
+	actions := make(map[string]FixAction)
+	for _, action := range plan.Actions {
+		actions[action.Package] = action
+	}
+	if _, ok := actions["express"]; ok {
+		t.Error("Expected express to be fixed through qs instead of its own action")
+	}
+
+	qs := actions["qs"]
+	if qs.Kind != FixOverride || qs.Installed != "6.7.0" || qs.To != "^6.7.3" || qs.Breaking {
+		t.Errorf("Unexpected qs action: %+v", qs)
+	}
+	lodash := actions["lodash"]
+	if lodash.Kind != FixDirectBump || lodash.Type != Production || lodash.From != "~4.17.15" || lodash.To != "~4.17.21" {
+		t.Errorf("Unexpected lodash action: %+v", lodash)
+	}
+	if actions["minimist"].Kind != FixUnavailable || actions["unlisted"].Kind != FixUnavailable {
+		t.Errorf("Expected minimist and unlisted to be unfixable, got %+v", plan.Actions)
+	}
+	if len(plan.Applicable()) != 2 || len(plan.Unfixable()) != 2 {
+		t.Errorf("Unexpected plan split: %+v", plan.Actions)
+	}
+}
+
+func TestAuditFixerFix(t *testing.T) {
+	dir := setupAuditFixProject(t)
+	before, _ := ParseAuditJSON([]byte(auditFixture))
+	after, _ := ParseAuditJSON([]byte(`{"auditReportVersion": 2, "vulnerabilities": {}}`))
+
+	client := NewMockClient()
+	client.audits = []*AuditReport{before, after}
+	fixer := NewAuditFixer(client, dir)
+	fixer.SetVersionLister(testVersionLister)
+
+	result, err := fixer.Fix(context.Background(), AuditFixOptions{})
+	if err != nil {
+		t.Fatalf("Fix() failed: %v", err)
+	}
+	if len(result.Applied) != 2 || result.After != after {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if strings.Join(result.Resolved, ",") != "1,GHSA-hrpp-h998-j3pp" {
+		t.Errorf("Unexpected resolved advisories: %v", result.Resolved)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "package.json"))
+	expected := `{
+  "name": "app",
+  "dependencies": {
+    "express": "^4.17.1",
+    "lodash": "~4.17.21"
+  },
+  "overrides": {
+    "qs": "^6.7.3"
+  }
+}
+`
+	if string(data) != expected {
+		t.Errorf("Unexpected package.json:\n%s", data)
+	}
+}
+
+func TestAuditFixerDryRun(t *testing.T) {
+	dir := setupAuditFixProject(t)
+	before, _ := ParseAuditJSON([]byte(auditFixture))
+
+	client := NewMockClient()
+	client.audits = []*AuditReport{before}
+	fixer := NewAuditFixer(client, dir)
+	fixer.SetVersionLister(testVersionLister)
+
+	result, err := fixer.Fix(context.Background(), AuditFixOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Fix() failed: %v", err)
+	}
+	if result.After != nil || len(result.Applied) != 0 || len(result.Plan.Applicable()) != 2 {
+		t.Errorf("Expected plan only, got %+v", result)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "package.json"))
+	if strings.Contains(string(data), "overrides") {
+		t.Error("Expected package.json to be unchanged in dry run")
+	}
+}
+
+func TestDependencyManagerAuditAndFix(t *testing.T) {
+	dir := setupAuditFixProject(t)
+	before, _ := ParseAuditJSON([]byte(auditFixture))
+
+	client := NewMockClient()
+	client.audits = []*AuditReport{before}
+	dm, _ := NewDependencyManager(client, dir)
+	report, err := dm.AuditReport(context.Background())
+	if err != nil || report != before {
+		t.Errorf("AuditReport() = %v, %v", report, err)
+	}
+
+	// 只返回错误的旧方法保持不变，没有漏洞时不修改任何内容
+	if err := dm.Audit(context.Background()); err != nil {
+		t.Errorf("Audit() failed: %v", err)
+	}
+	if err := dm.Fix(context.Background()); err != nil {
+		t.Errorf("Fix() failed: %v", err)
+	}
+	result, err := dm.FixWithResult(context.Background())
+	if err != nil || len(result.Applied) != 0 {
+		t.Errorf("FixWithResult() = %+v, %v", result, err)
+	}
+}
+
+func TestParseVersionsJSON(t *testing.T) {
+	versions, err := parseVersionsJSON([]byte(`["1.0.0", "1.1.0"]`))
+	if err != nil || len(versions) != 2 {
+		t.Errorf("Unexpected versions: %v, %v", versions, err)
+	}
+	versions, err = parseVersionsJSON([]byte(`"1.0.0"`))
+	if err != nil || len(versions) != 1 || versions[0] != "1.0.0" {
+		t.Errorf("Unexpected versions: %v, %v", versions, err)
+	}
+	if _, err := parseVersionsJSON([]byte(`{}`)); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
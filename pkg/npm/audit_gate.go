@@ -0,0 +1,38 @@
+package npm
+
+import (
+	"context"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+)
+
+// AuditGate 审计门禁规则，返回违反规则的问题和原因，没有违反时返回nil
+type AuditGate = npmiface.AuditGate
+
+// GateResult 门禁检查结果
+type GateResult = npmiface.GateResult
+
+// FailOn 存在指定严重程度及以上的问题时失败
+func FailOn(severity Severity) AuditGate {
+	return npmiface.FailOn(severity)
+}
+
+// MaxAllowed 某个严重程度的问题数量超过上限时失败，未列出的严重程度不受限制
+func MaxAllowed(limits map[Severity]int) AuditGate {
+	return npmiface.MaxAllowed(limits)
+}
+
+// RunAuditGate 运行审计并用门禁规则检查结果，适合在CI步骤中直接调用
+//
+//	result, err := npm.RunAuditGate(ctx, client, npm.AuditOptions{WorkingDir: dir}, npm.FailOn(npm.SeverityHigh))
+//	if err != nil {
+//		return err
+//	}
+//	return result.Err()
+func RunAuditGate(ctx context.Context, client Client, options AuditOptions, gates ...AuditGate) (*GateResult, error) {
+	report, err := client.Audit(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return report.Gate(gates...), nil
+}
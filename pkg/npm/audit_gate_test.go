@@ -0,0 +1,60 @@
+package npm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAuditGates(t *testing.T) {
+	report, err := ParseAuditJSON([]byte(auditFixture))
+	if err != nil {
+		t.Fatalf("ParseAuditJSON() failed: %v", err)
+	}
+
+	result := report.Gate(FailOn(SeverityCritical))
+	if result.Passed || len(result.Violations) != 1 || result.Violations[0].Name != "lodash" {
+		t.Errorf("Expected lodash to fail the critical gate, got %+v", result)
+	}
+	if err := result.Err(); err == nil || !strings.Contains(err.Error(), "lodash (critical)") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	result = report.Gate(FailOn(SeverityHigh), MaxAllowed(map[Severity]int{SeverityCritical: 0}))
+	if result.Passed || len(result.Violations) != 3 || len(result.Reasons) != 2 {
+		t.Errorf("Expected deduplicated violations from both gates, got %+v", result)
+	}
+
+	result = report.Gate(MaxAllowed(map[Severity]int{SeverityHigh: 2, SeverityCritical: 1}))
+	if !result.Passed || result.Err() != nil {
+		t.Errorf("Expected gate to pass within limits, got %+v", result)
+	}
+
+	result = report.Gate(MaxAllowed(map[Severity]int{SeverityHigh: 1}))
+	if result.Passed || len(result.Violations) != 2 || result.Reasons[0] != "2 high finding(s), at most 1 allowed" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if result := report.Gate(); !result.Passed {
+		t.Error("Expected no gates to pass")
+	}
+}
+
+func TestRunAuditGate(t *testing.T) {
+	report, _ := ParseAuditJSON([]byte(auditFixture))
+	client := NewMockClient()
+	client.audits = []*AuditReport{report}
+
+	result, err := RunAuditGate(context.Background(), client, AuditOptions{}, FailOn(SeverityModerate))
+	if err != nil {
+		t.Fatalf("RunAuditGate() failed: %v", err)
+	}
+	if result.Passed || result.Report != report {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	result, err = RunAuditGate(context.Background(), client, AuditOptions{}, FailOn(SeverityLow))
+	if err != nil || !result.Passed {
+		t.Errorf("Expected empty report to pass, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,338 @@
+package npm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/store"
+)
+
+// AuditRecord 一次审计的记录
+type AuditRecord struct {
+	Project    string                `json:"project"`
+	Time       time.Time             `json:"time"`
+	Total      int                   `json:"total"`
+	Counts     map[Severity]int      `json:"counts"`
+	Advisories []AuditRecordAdvisory `json:"advisories"`
+}
+
+// AuditRecordAdvisory 审计记录中的公告
+type AuditRecordAdvisory struct {
+	ID       string   `json:"id"`
+	Package  string   `json:"package"`
+	Severity Severity `json:"severity"`
+	Title    string   `json:"title,omitempty"`
+}
+
+// key 公告与包的组合标识，同一公告可能影响多个包
+func (a AuditRecordAdvisory) key() string {
+	return a.Package + "\x00" + a.ID
+}
+
+// AuditHistoryStore 审计记录存储
+type AuditHistoryStore interface {
+	// 追加一条记录
+	Append(record AuditRecord) error
+
+	// 按时间顺序返回项目的所有记录
+	Records(project string) ([]AuditRecord, error)
+}
+
+// JSONAuditHistoryStore 保存在单个JSON文件中的审计记录
+type JSONAuditHistoryStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewJSONAuditHistoryStore 创建JSON文件存储，文件不存在时在首次写入时创建
+func NewJSONAuditHistoryStore(path string) *JSONAuditHistoryStore {
+	return &JSONAuditHistoryStore{path: path}
+}
+
+// Append 追加一条记录
+func (s *JSONAuditHistoryStore) Append(record AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects, err := s.load()
+	if err != nil {
+		return err
+	}
+	projects[record.Project] = append(projects[record.Project], record)
+
+	data, err := json.MarshalIndent(projects, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode audit history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit history directory: %w", err)
+	}
+
+	// 先写临时文件再重命名，避免中断时留下损坏的文件
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write audit history: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write audit history: %w", err)
+	}
+	return nil
+}
+
+// Records 按时间顺序返回项目的所有记录
+func (s *JSONAuditHistoryStore) Records(project string) ([]AuditRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	records := projects[project]
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
+
+// load 读取所有项目的记录
+func (s *JSONAuditHistoryStore) load() (map[string][]AuditRecord, error) {
+	projects := make(map[string][]AuditRecord)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return projects, nil
+		}
+		return nil, fmt.Errorf("failed to read audit history: %w", err)
+	}
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse audit history: %w", err)
+	}
+	return projects, nil
+}
+
+// auditHistoryBucket 审计记录在store中的桶名
+const auditHistoryBucket = "audit-history"
+
+// StoreAuditHistoryStore 保存在store.Store中的审计记录，每个项目一个条目
+type StoreAuditHistoryStore struct {
+	store *store.Store
+	mu    sync.Mutex
+}
+
+// NewStoreAuditHistoryStore 创建基于store.Store的审计记录存储
+func NewStoreAuditHistoryStore(s *store.Store) *StoreAuditHistoryStore {
+	return &StoreAuditHistoryStore{store: s}
+}
+
+// Append 追加一条记录
+func (s *StoreAuditHistoryStore) Append(record AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.records(record.Project)
+	if err != nil {
+		return err
+	}
+	records = append(records, record)
+	if err := s.store.PutJSON(auditHistoryBucket, record.Project, records, 0); err != nil {
+		return fmt.Errorf("failed to write audit history: %w", err)
+	}
+	return nil
+}
+
+// Records 按时间顺序返回项目的所有记录
+func (s *StoreAuditHistoryStore) Records(project string) ([]AuditRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.records(project)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
+
+// records 读取项目的记录，没有记录时返回nil
+func (s *StoreAuditHistoryStore) records(project string) ([]AuditRecord, error) {
+	var records []AuditRecord
+	if err := s.store.GetJSON(auditHistoryBucket, project, &records); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read audit history: %w", err)
+	}
+	return records, nil
+}
+
+// FixedAdvisory 已修复的公告
+type FixedAdvisory struct {
+	AuditRecordAdvisory
+	FirstSeen time.Time     `json:"first_seen"`
+	FixedAt   time.Time     `json:"fixed_at"` // 首次不再出现的审计时间
+	Duration  time.Duration `json:"duration"`
+}
+
+// OpenAdvisory 仍未修复的公告
+type OpenAdvisory struct {
+	AuditRecordAdvisory
+	FirstSeen time.Time     `json:"first_seen"`
+	Age       time.Duration `json:"age"` // 截至最近一次审计的时长
+}
+
+// TimeToFixReport 修复时长统计
+type TimeToFixReport struct {
+	Fixed []FixedAdvisory            `json:"fixed"`
+	Open  []OpenAdvisory             `json:"open"`
+	Mean  map[Severity]time.Duration `json:"mean"` // 各严重程度已修复公告的平均修复时长
+}
+
+// AuditHistory 记录项目的审计结果并提供趋势查询
+type AuditHistory struct {
+	store AuditHistoryStore
+}
+
+// NewAuditHistory 创建审计历史
+func NewAuditHistory(store AuditHistoryStore) *AuditHistory {
+	return &AuditHistory{store: store}
+}
+
+// Record 记录一次审计结果，at为零值时使用当前时间
+func (h *AuditHistory) Record(project string, report *AuditReport, at time.Time) (*AuditRecord, error) {
+	if project == "" {
+		return nil, NewValidationError("project", project, "project cannot be empty")
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	record := AuditRecord{
+		Project: project,
+		Time:    at.UTC(),
+		Total:   report.Total,
+		Counts:  make(map[Severity]int),
+	}
+	for severity, count := range report.Counts {
+		record.Counts[severity] = count
+	}
+	for _, finding := range report.Findings {
+		for _, advisory := range finding.Advisories {
+			record.Advisories = append(record.Advisories, AuditRecordAdvisory{
+				ID:       advisory.ID(),
+				Package:  finding.Name,
+				Severity: advisory.Severity,
+				Title:    advisory.Title,
+			})
+		}
+	}
+
+	if err := h.store.Append(record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Latest 返回项目最近一次审计记录，没有记录时返回nil
+func (h *AuditHistory) Latest(project string) (*AuditRecord, error) {
+	records, err := h.store.Records(project)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	return &records[len(records)-1], nil
+}
+
+// NewSinceLastRun 返回最近一次审计中新出现的公告（上一次审计中没有的）
+func (h *AuditHistory) NewSinceLastRun(project string) ([]AuditRecordAdvisory, error) {
+	records, err := h.store.Records(project)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	previous := make(map[string]bool)
+	if len(records) > 1 {
+		for _, advisory := range records[len(records)-2].Advisories {
+			previous[advisory.key()] = true
+		}
+	}
+
+	var added []AuditRecordAdvisory
+	for _, advisory := range records[len(records)-1].Advisories {
+		if !previous[advisory.key()] {
+			added = append(added, advisory)
+		}
+	}
+	return added, nil
+}
+
+// TimeToFix 统计每个公告从首次出现到消失的时长
+//
+// 公告消失后再次出现时按新的一次出现计算。
+func (h *AuditHistory) TimeToFix(project string) (*TimeToFixReport, error) {
+	records, err := h.store.Records(project)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TimeToFixReport{Mean: make(map[Severity]time.Duration)}
+	open := make(map[string]OpenAdvisory)
+	var order []string
+
+	for _, record := range records {
+		present := make(map[string]bool)
+		for _, advisory := range record.Advisories {
+			key := advisory.key()
+			present[key] = true
+			if _, ok := open[key]; !ok {
+				open[key] = OpenAdvisory{AuditRecordAdvisory: advisory, FirstSeen: record.Time}
+				order = append(order, key)
+			}
+		}
+
+		remaining := order[:0]
+		for _, key := range order {
+			if present[key] {
+				remaining = append(remaining, key)
+				continue
+			}
+			advisory := open[key]
+			report.Fixed = append(report.Fixed, FixedAdvisory{
+				AuditRecordAdvisory: advisory.AuditRecordAdvisory,
+				FirstSeen:           advisory.FirstSeen,
+				FixedAt:             record.Time,
+				Duration:            record.Time.Sub(advisory.FirstSeen),
+			})
+			delete(open, key)
+		}
+		order = remaining
+	}
+
+	if len(records) > 0 {
+		last := records[len(records)-1].Time
+		for _, key := range order {
+			advisory := open[key]
+			advisory.Age = last.Sub(advisory.FirstSeen)
+			report.Open = append(report.Open, advisory)
+		}
+	}
+
+	totals := make(map[Severity]time.Duration)
+	counts := make(map[Severity]int)
+	for _, fixed := range report.Fixed {
+		totals[fixed.Severity] += fixed.Duration
+		counts[fixed.Severity]++
+	}
+	for severity, total := range totals {
+		report.Mean[severity] = total / time.Duration(counts[severity])
+	}
+
+	return report, nil
+}
@@ -0,0 +1,138 @@
+package npm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/store"
+)
+
+func auditReportWith(advisories map[string]Severity) *AuditReport {
+	report := &AuditReport{Counts: make(map[Severity]int)}
+	for name, severity := range advisories {
+		report.Findings = append(report.Findings, AuditFinding{
+			Name:       name,
+			Severity:   severity,
+			Advisories: []Advisory{{Source: 1, URL: "https://github.com/advisories/GHSA-" + name, Severity: severity}},
+		})
+		report.Counts[severity]++
+		report.Total++
+	}
+	return report
+}
+
+func TestAuditHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "audit.json")
+	history := NewAuditHistory(NewJSONAuditHistoryStore(path))
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	runs := []map[string]Severity{
+		{"qs": SeverityHigh},
+		{"qs": SeverityHigh, "lodash": SeverityCritical},
+		{"lodash": SeverityCritical},
+		{},
+	}
+	for i, run := range runs {
+		if _, err := history.Record("app", auditReportWith(run), start.Add(time.Duration(i)*24*time.Hour)); err != nil {
+			t.Fatalf("Record() failed: %v", err)
+		}
+	}
+	if _, err := history.Record("other", auditReportWith(map[string]Severity{"minimist": SeverityLow}), start); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+
+	// 重新打开文件，确认记录已持久化
+	history = NewAuditHistory(NewJSONAuditHistoryStore(path))
+	latest, err := history.Latest("app")
+	if err != nil || latest == nil || latest.Total != 0 || !latest.Time.Equal(start.Add(72*time.Hour)) {
+		t.Fatalf("Unexpected latest record: %+v, %v", latest, err)
+	}
+
+	report, err := history.TimeToFix("app")
+	if err != nil {
+		t.Fatalf("TimeToFix() failed: %v", err)
+	}
+	if len(report.Fixed) != 2 || len(report.Open) != 0 {
+		t.Fatalf("Unexpected time-to-fix report: %+v", report)
+	}
+	if report.Fixed[0].Package != "qs" || report.Fixed[0].Duration != 48*time.Hour {
+		t.Errorf("Unexpected qs fix: %+v", report.Fixed[0])
+	}
+	if report.Mean[SeverityCritical] != 48*time.Hour || report.Mean[SeverityHigh] != 48*time.Hour {
+		t.Errorf("Unexpected means: %v", report.Mean)
+	}
+
+	other, err := history.TimeToFix("other")
+	if err != nil || len(other.Open) != 1 || other.Open[0].ID != "GHSA-minimist" || other.Open[0].Age != 0 {
+		t.Errorf("Unexpected open advisories: %+v, %v", other, err)
+	}
+}
+
+func TestAuditHistoryNewSinceLastRun(t *testing.T) {
+	history := NewAuditHistory(NewJSONAuditHistoryStore(filepath.Join(t.TempDir(), "audit.json")))
+
+	added, err := history.NewSinceLastRun("app")
+	if err != nil || len(added) != 0 {
+		t.Errorf("Expected nothing for empty history, got %v, %v", added, err)
+	}
+
+	history.Record("app", auditReportWith(map[string]Severity{"qs": SeverityHigh}), time.Time{})
+	added, _ = history.NewSinceLastRun("app")
+	if len(added) != 1 || added[0].Package != "qs" {
+		t.Errorf("Expected first run findings to be new, got %+v", added)
+	}
+
+	history.Record("app", auditReportWith(map[string]Severity{"qs": SeverityHigh, "lodash": SeverityCritical}), time.Time{})
+	added, _ = history.NewSinceLastRun("app")
+	if len(added) != 1 || added[0].Package != "lodash" || added[0].Severity != SeverityCritical {
+		t.Errorf("Expected lodash to be new, got %+v", added)
+	}
+
+	if _, err := history.Record("", &AuditReport{}, time.Time{}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for empty project, got %v", err)
+	}
+}
+
+func TestJSONAuditHistoryStoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.json")
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewJSONAuditHistoryStore(path).Records("app"); err == nil {
+		t.Error("Expected error for corrupt history file")
+	}
+}
+
+func TestStoreAuditHistoryStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := store.Open(dir, store.Options{})
+	if err != nil {
+		t.Fatalf("store.Open() failed: %v", err)
+	}
+	history := NewAuditHistory(NewStoreAuditHistoryStore(s))
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if latest, err := history.Latest("app"); err != nil || latest != nil {
+		t.Fatalf("Latest() on empty store = %+v, %v", latest, err)
+	}
+	for i, run := range []map[string]Severity{{"qs": SeverityHigh}, {"qs": SeverityHigh, "lodash": SeverityCritical}} {
+		if _, err := history.Record("app", auditReportWith(run), start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("Record() failed: %v", err)
+		}
+	}
+
+	// 重新打开存储，确认记录已持久化
+	s, err = store.Open(dir, store.Options{})
+	if err != nil {
+		t.Fatalf("store.Open() failed: %v", err)
+	}
+	added, err := NewAuditHistory(NewStoreAuditHistoryStore(s)).NewSinceLastRun("app")
+	if err != nil {
+		t.Fatalf("NewSinceLastRun() failed: %v", err)
+	}
+	if len(added) != 1 || added[0].Package != "lodash" {
+		t.Errorf("NewSinceLastRun() = %+v, want lodash", added)
+	}
+}
@@ -0,0 +1,56 @@
+package npm
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// DefaultAuditIgnoreFile 默认的审计忽略文件名
+const DefaultAuditIgnoreFile = ".npm-audit-ignore.json"
+
+// AuditIgnore 单条忽略规则
+type AuditIgnore = npmiface.AuditIgnore
+
+// AuditIgnoreList 审计忽略文件内容
+type AuditIgnoreList = npmiface.AuditIgnoreList
+
+// IgnoredAdvisory 被忽略的公告
+type IgnoredAdvisory = npmiface.IgnoredAdvisory
+
+// LoadAuditIgnoreFile 读取并校验审计忽略文件
+func LoadAuditIgnoreFile(path string) (*AuditIgnoreList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit ignore file: %w", err)
+	}
+	return ParseAuditIgnore(data)
+}
+
+// ParseAuditIgnore 解析并校验审计忽略规则
+func ParseAuditIgnore(data []byte) (*AuditIgnoreList, error) {
+	var list AuditIgnoreList
+	if err := utils.DecodeJSON("audit ignore file", data, &list); err != nil {
+		return nil, err
+	}
+
+	for _, ignore := range list.Ignores {
+		if ignore.ID == "" {
+			return nil, NewValidationError("id", "", "advisory id cannot be empty")
+		}
+		if strings.TrimSpace(ignore.Reason) == "" {
+			return nil, NewValidationError("reason", ignore.ID, "a justification is required for every ignored advisory")
+		}
+		if ignore.Expires == "" {
+			return nil, NewValidationError("expires", ignore.ID, "an expiry date is required for every ignored advisory")
+		}
+		if _, err := ignore.ExpiresAt(); err != nil {
+			return nil, NewValidationError("expires", ignore.Expires, "expiry must be a date (2006-01-02) or RFC3339 timestamp")
+		}
+	}
+
+	return &list, nil
+}
@@ -0,0 +1,106 @@
+package npm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseAuditIgnore(t *testing.T) {
+	list, err := ParseAuditIgnore([]byte(`{"ignores": [
+		{"id": "GHSA-hrpp-h998-j3pp", "expires": "2026-03-01", "reason": "qs is only used server-side with trusted input"},
+		{"id": "1", "package": "lodash", "expires": "2026-03-01T12:00:00Z", "reason": "template() is never called"}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseAuditIgnore() failed: %v", err)
+	}
+	if len(list.Ignores) != 2 {
+		t.Fatalf("Expected 2 ignores, got %d", len(list.Ignores))
+	}
+	expires, _ := list.Ignores[0].ExpiresAt()
+	if !expires.Equal(time.Date(2026, 3, 1, 23, 59, 59, 999999999, time.UTC)) {
+		t.Errorf("Expected date to expire at end of day, got %v", expires)
+	}
+
+	invalid := []string{
+		`{"ignores": [{"expires": "2026-03-01", "reason": "x"}]}`,
+		`{"ignores": [{"id": "1", "expires": "2026-03-01", "reason": " "}]}`,
+		`{"ignores": [{"id": "1", "reason": "x"}]}`,
+		`{"ignores": [{"id": "1", "expires": "next month", "reason": "x"}]}`,
+	}
+	for _, content := range invalid {
+		if _, err := ParseAuditIgnore([]byte(content)); !IsValidationError(err, nil) {
+			t.Errorf("Expected validation error for %s, got %v", content, err)
+		}
+	}
+	if _, err := ParseAuditIgnore([]byte(`[`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestAuditReportApplyIgnores(t *testing.T) {
+	report, err := ParseAuditJSON([]byte(auditFixture))
+	if err != nil {
+		t.Fatalf("ParseAuditJSON() failed: %v", err)
+	}
+	list := &AuditIgnoreList{Ignores: []AuditIgnore{
+		{ID: "ghsa-hrpp-h998-j3pp", Expires: "2026-03-01", Reason: "accepted"},
+		{ID: "1", Package: "lodash", Expires: "2025-01-01", Reason: "expired"},
+		{ID: "GHSA-0000-0000-0000", Expires: "2026-03-01", Reason: "stale"},
+	}}
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	filtered := report.ApplyIgnores(list, now)
+
+	// qs被忽略后，只因qs受影响的express也不再计入
+	if filtered.Total != 1 || filtered.Findings[0].Name != "lodash" {
+		t.Errorf("Expected only lodash to remain, got %+v", filtered.Findings)
+	}
+	if filtered.Counts[SeverityCritical] != 1 || filtered.Counts[SeverityHigh] != 0 {
+		t.Errorf("Unexpected counts: %v", filtered.Counts)
+	}
+	if len(filtered.Ignored) != 1 || filtered.Ignored[0].Package != "qs" || filtered.Ignored[0].Reason != "accepted" {
+		t.Errorf("Unexpected ignored advisories: %+v", filtered.Ignored)
+	}
+	if len(filtered.ExpiredIgnores) != 1 || filtered.ExpiredIgnores[0].Reason != "expired" {
+		t.Errorf("Unexpected expired ignores: %+v", filtered.ExpiredIgnores)
+	}
+	if len(filtered.UnusedIgnores) != 1 || filtered.UnusedIgnores[0].ID != "GHSA-0000-0000-0000" {
+		t.Errorf("Unexpected unused ignores: %+v", filtered.UnusedIgnores)
+	}
+	if report.Total != 3 {
+		t.Error("Expected original report to be unchanged")
+	}
+
+	// 到期后忽略规则不再生效
+	later := report.ApplyIgnores(list, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	if later.Total != 3 || len(later.ExpiredIgnores) != 3 {
+		t.Errorf("Expected all findings after expiry, got %+v", later)
+	}
+}
+
+func TestDependencyManagerAuditUsesIgnoreFile(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFile(t, filepath.Join(tempDir, DefaultAuditIgnoreFile), `{"ignores": [{"id": "1", "expires": "2999-01-01", "reason": "x"}]}`)
+
+	client := &auditOptionsRecorder{MockClient: NewMockClient()}
+	dm, _ := NewDependencyManager(client, tempDir)
+	if _, err := dm.AuditReport(context.Background()); err != nil {
+		t.Fatalf("AuditReport() failed: %v", err)
+	}
+	if client.options.IgnoreFile != filepath.Join(tempDir, DefaultAuditIgnoreFile) {
+		t.Errorf("Expected ignore file to be passed, got %+v", client.options)
+	}
+}
+
+// auditOptionsRecorder 记录Audit调用参数
+type auditOptionsRecorder struct {
+	*MockClient
+	options AuditOptions
+}
+
+func (r *auditOptionsRecorder) Audit(ctx context.Context, options AuditOptions) (*AuditReport, error) {
+	r.options = options
+	return r.MockClient.Audit(ctx, options)
+}
@@ -0,0 +1,121 @@
+package npm
+
+import "testing"
+
+const auditFixture = `{
+  "auditReportVersion": 2,
+  "vulnerabilities": {
+    "express": {
+      "name": "express",
+      "severity": "high",
+      "isDirect": true,
+      "via": ["qs"],
+      "effects": [],
+      "range": "4.0.0-rc1 - 4.17.2",
+      "nodes": ["node_modules/express"],
+      "fixAvailable": {"name": "express", "version": "4.18.2", "isSemVerMajor": false}
+    },
+    "qs": {
+      "name": "qs",
+      "severity": "high",
+      "isDirect": false,
+      "via": [{
+        "source": 1088953,
+        "name": "qs",
+        "dependency": "qs",
+        "title": "qs vulnerable to Prototype Pollution",
+        "url": "https://github.com/advisories/GHSA-hrpp-h998-j3pp",
+        "severity": "high",
+        "cwe": ["CWE-1321"],
+        "cvss": {"score": 7.5, "vectorString": "CVSS:3.1/AV:N"},
+        "range": ">=6.7.0 <6.7.3"
+      }],
+      "effects": ["express"],
+      "range": "6.7.0 - 6.7.2",
+      "nodes": ["node_modules/qs"],
+      "fixAvailable": true
+    },
+    "lodash": {
+      "name": "lodash",
+      "severity": "critical",
+      "isDirect": true,
+      "via": [{"source": 1, "name": "lodash", "title": "Command Injection", "url": "https://npmjs.com/advisories/1", "severity": "critical", "range": "<4.17.21"}],
+      "effects": [],
+      "range": "<4.17.21",
+      "nodes": ["node_modules/lodash"],
+      "fixAvailable": false
+    }
+  },
+  "metadata": {
+    "vulnerabilities": {"info": 0, "low": 0, "moderate": 0, "high": 2, "critical": 1, "total": 3},
+    "dependencies": {"prod": 60, "dev": 0, "total": 60}
+  }
+}`
+
+func TestParseAuditJSON(t *testing.T) {
+	report, err := ParseAuditJSON([]byte(auditFixture))
+	if err != nil {
+		t.Fatalf("ParseAuditJSON() failed: %v", err)
+	}
+
+	if len(report.Findings) != 3 || report.Findings[0].Name != "express" {
+		t.Fatalf("Expected 3 sorted findings, got %+v", report.Findings)
+	}
+	if report.Total != 3 || report.Counts[SeverityHigh] != 2 || report.Counts[SeverityCritical] != 1 || report.Dependencies != 60 {
+		t.Errorf("Unexpected counts: %+v", report)
+	}
+
+	express := report.Finding("express")
+	if len(express.Advisories) != 0 || len(express.Via) != 1 || express.Via[0] != "qs" {
+		t.Errorf("Expected express to be affected via qs, got %+v", express)
+	}
+	if !express.Fix.Available || express.Fix.Version != "4.18.2" || express.Fix.IsSemVerMajor {
+		t.Errorf("Unexpected fix: %+v", express.Fix)
+	}
+
+	qs := report.Finding("qs")
+	if len(qs.Advisories) != 1 {
+		t.Fatalf("Expected one advisory for qs, got %+v", qs.Advisories)
+	}
+	advisory := qs.Advisories[0]
+	if advisory.ID() != "GHSA-hrpp-h998-j3pp" || advisory.CVSSScore != 7.5 || advisory.Range != ">=6.7.0 <6.7.3" {
+		t.Errorf("Unexpected advisory: %+v", advisory)
+	}
+	if !qs.Fix.Available || qs.Fix.Name != "" {
+		t.Errorf("Unexpected fix: %+v", qs.Fix)
+	}
+
+	lodash := report.Finding("lodash")
+	if lodash.Fix.Available || lodash.Advisories[0].ID() != "1" {
+		t.Errorf("Unexpected lodash finding: %+v", lodash)
+	}
+	if report.Finding("missing") != nil {
+		t.Error("Expected nil for unknown package")
+	}
+}
+
+func TestParseAuditJSONErrors(t *testing.T) {
+	if _, err := ParseAuditJSON([]byte(`{"error": {"code": "ENOLOCK", "summary": "This command requires an existing lockfile."}}`)); err == nil {
+		t.Error("Expected error for npm error output")
+	}
+	if _, err := ParseAuditJSON([]byte(`not json`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if _, err := ParseAuditJSON([]byte(`{"auditReportVersion": 1}`)); err == nil {
+		t.Error("Expected error for unsupported report version")
+	}
+
+	report, err := ParseAuditJSON([]byte(`{"auditReportVersion": 2, "vulnerabilities": {}}`))
+	if err != nil || report.Total != 0 || len(report.Findings) != 0 {
+		t.Errorf("Expected empty report, got %+v, %v", report, err)
+	}
+}
+
+func TestSeverityRank(t *testing.T) {
+	if SeverityCritical.Rank() <= SeverityHigh.Rank() || SeverityLow.Rank() <= SeverityInfo.Rank() {
+		t.Error("Expected severities to be ordered")
+	}
+	if Severity("unknown").Rank() != -1 {
+		t.Error("Expected -1 for unknown severity")
+	}
+}
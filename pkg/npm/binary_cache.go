@@ -0,0 +1,244 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/store"
+)
+
+// binaryCacheBucket 缓存二进制文件的桶
+const binaryCacheBucket = "binaries"
+
+// BinaryCacheStats 二进制缓存代理的统计
+type BinaryCacheStats struct {
+	Hits   int64       `json:"hits"`
+	Misses int64       `json:"misses"` // 从上游下载的请求，包括未缓存的失败响应
+	Store  store.Stats `json:"store"`
+}
+
+// BinaryCache 预编译二进制下载的本地缓存代理
+//
+// node-pre-gyp、prebuild-install、electron等安装脚本从镜像地址下载预编译二进制，
+// 把镜像地址改为代理地址后，同一台机器或CI runner上的多个项目共享下载结果。
+// 代理地址的格式为<URL>/<scheme>/<host>/<path>，只缓存GET请求的200响应，
+// 其他响应原样转发。缓存保存在store中，超出store.Options.MaxBytes时按最近访问时间淘汰。
+type BinaryCache struct {
+	store      *store.Store
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewBinaryCache 创建使用st保存二进制文件的缓存代理，多个进程可以使用同一个目录
+func NewBinaryCache(st *store.Store) *BinaryCache {
+	return &BinaryCache{
+		store: st,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Minute,
+		},
+	}
+}
+
+// Start 在addr上启动代理，addr为空时监听127.0.0.1上的随机端口
+func (c *BinaryCache) Start(addr string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.server != nil {
+		return fmt.Errorf("binary cache is already running at %s", c.listener.Addr())
+	}
+
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	c.listener = listener
+	c.server = &http.Server{Handler: c, ReadHeaderTimeout: 30 * time.Second}
+	go c.server.Serve(listener)
+	return nil
+}
+
+// Close 停止代理，等待进行中的下载完成或ctx取消
+func (c *BinaryCache) Close(ctx context.Context) error {
+	c.mu.Lock()
+	server := c.server
+	c.server = nil
+	c.listener = nil
+	c.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// URL 代理的地址，未启动时为空字符串
+func (c *BinaryCache) URL() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.listener == nil {
+		return ""
+	}
+	return "http://" + c.listener.Addr().String()
+}
+
+// Wrap 返回通过代理访问upstream的地址，代理未启动或upstream不是http(s)地址时原样返回
+func (c *BinaryCache) Wrap(upstream string) string {
+	base := c.URL()
+	scheme, rest, found := strings.Cut(upstream, "://")
+	if base == "" || !found || (scheme != "http" && scheme != "https") {
+		return upstream
+	}
+	return base + "/" + scheme + "/" + rest
+}
+
+// Mirror 返回disturl和二进制镜像经过代理的镜像配置，registry保持不变
+//
+// 返回值可以传给WithMirror或DependencyManager.ConfigureMirror。
+func (c *BinaryCache) Mirror(mirror Mirror) Mirror {
+	wrapped := Mirror{
+		Registry: mirror.Registry,
+		Disturl:  c.Wrap(mirror.Disturl),
+	}
+	if mirror.BinaryMirrors != nil {
+		wrapped.BinaryMirrors = make(map[string]string, len(mirror.BinaryMirrors))
+		for key, value := range mirror.BinaryMirrors {
+			wrapped.BinaryMirrors[key] = c.Wrap(value)
+		}
+	}
+	return wrapped
+}
+
+// Stats 返回命中统计和缓存占用
+func (c *BinaryCache) Stats() BinaryCacheStats {
+	return BinaryCacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Store:  c.store.Stats(),
+	}
+}
+
+// ServeHTTP 实现http.Handler，可以挂载到已有的HTTP服务上
+func (c *BinaryCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	upstream, ok := upstreamURL(r)
+	if !ok {
+		http.Error(w, "expected /<scheme>/<host>/<path>", http.StatusBadRequest)
+		return
+	}
+
+	if entry, err := c.store.Open(binaryCacheBucket, upstream); err == nil {
+		defer entry.Close()
+		c.hits.Add(1)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("X-Cache", "HIT")
+		if entry.SHA256 != "" {
+			w.Header().Set("ETag", `"`+entry.SHA256+`"`)
+		}
+		// ServeContent处理HEAD、Range和If-None-Match请求
+		http.ServeContent(w, r, "", time.Time{}, entry)
+		return
+	}
+
+	c.misses.Add(1)
+	c.forward(w, r, upstream)
+}
+
+// forward 从上游下载，边转发边写入缓存，只保存完整的200响应
+func (c *BinaryCache) forward(w http.ResponseWriter, r *http.Request, upstream string) {
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, upstream, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if userAgent := r.Header.Get("User-Agent"); userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to download %s: %v", upstream, err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for _, name := range []string{"Content-Type", "Content-Length"} {
+		if value := resp.Header.Get(name); value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+	w.Header().Set("X-Cache", "MISS")
+	w.WriteHeader(resp.StatusCode)
+
+	if r.Method != http.MethodGet || resp.StatusCode != http.StatusOK {
+		io.Copy(w, resp.Body)
+		return
+	}
+
+	writer, err := c.store.Create(binaryCacheBucket, upstream, 0)
+	if err != nil {
+		io.Copy(w, resp.Body)
+		return
+	}
+	defer writer.Abort()
+
+	tee := &cacheTee{writer: writer}
+	n, err := io.Copy(w, io.TeeReader(resp.Body, tee))
+	// 下载中断、客户端断开或写入缓存失败时不缓存不完整的文件
+	if err != nil || tee.err != nil || (resp.ContentLength >= 0 && n != resp.ContentLength) {
+		return
+	}
+	writer.Commit()
+}
+
+// cacheTee 把转发的内容写入缓存，写入失败时记录错误并停止写入，不影响转发
+type cacheTee struct {
+	writer *store.Writer
+	err    error
+}
+
+func (t *cacheTee) Write(p []byte) (int, error) {
+	if t.err == nil {
+		_, t.err = t.writer.Write(p)
+	}
+	return len(p), nil
+}
+
+// upstreamURL 从代理路径中还原上游地址
+func upstreamURL(r *http.Request) (string, bool) {
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/")
+	scheme, rest, found := strings.Cut(path, "/")
+	if !found || (scheme != "http" && scheme != "https") {
+		return "", false
+	}
+	host, _, _ := strings.Cut(rest, "/")
+	if host == "" {
+		return "", false
+	}
+
+	upstream := scheme + "://" + rest
+	if r.URL.RawQuery != "" {
+		upstream += "?" + r.URL.RawQuery
+	}
+	return upstream, true
+}
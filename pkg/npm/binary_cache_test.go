@@ -0,0 +1,136 @@
+package npm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/store"
+)
+
+func TestBinaryCache(t *testing.T) {
+	var requests atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/releases/v1.0.0/binding.tar.gz":
+			w.Write([]byte("binary-" + r.URL.RawQuery))
+		case "/latest":
+			http.Redirect(w, r, "/releases/v1.0.0/binding.tar.gz", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer upstream.Close()
+
+	st, err := store.Open(t.TempDir(), store.Options{MaxBytes: 15})
+	if err != nil {
+		t.Fatalf("store.Open() failed: %v", err)
+	}
+	cache := NewBinaryCache(st)
+	if cache.Wrap(upstream.URL) != upstream.URL {
+		t.Error("Expected Wrap() to leave URLs unchanged before Start()")
+	}
+	if err := cache.Start(""); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer cache.Close(context.Background())
+	if err := cache.Start(""); err == nil {
+		t.Error("Expected error when starting twice")
+	}
+
+	get := func(url string) (int, string, string) {
+		t.Helper()
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", url, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body), resp.Header.Get("X-Cache")
+	}
+
+	url := cache.Wrap(upstream.URL + "/releases/v1.0.0/binding.tar.gz?a=1")
+	if !strings.HasPrefix(url, cache.URL()+"/http/127.0.0.1:") {
+		t.Fatalf("Unexpected wrapped URL: %s", url)
+	}
+	for i, expected := range []string{"MISS", "HIT"} {
+		status, body, xcache := get(url)
+		if status != http.StatusOK || body != "binary-a=1" || xcache != expected {
+			t.Errorf("Request %d = %d %q %s, expected %s", i, status, body, xcache, expected)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", requests.Load())
+	}
+
+	// 命中时按Range返回部分内容，ETag为内容的SHA-256
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Range", "bytes=7-")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Range request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	sum := sha256.Sum256([]byte("binary-a=1"))
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "a=1" || resp.Header.Get("ETag") != `"`+hex.EncodeToString(sum[:])+`"` {
+		t.Errorf("Range request = %d %q (ETag %s)", resp.StatusCode, body, resp.Header.Get("ETag"))
+	}
+
+	// 跟随重定向并按原地址缓存
+	get(cache.Wrap(upstream.URL + "/latest"))
+	if _, body, xcache := get(cache.Wrap(upstream.URL + "/latest")); body != "binary-" || xcache != "HIT" {
+		t.Errorf("Expected redirected download to be cached, got %q %s", body, xcache)
+	}
+
+	// 失败的响应不缓存
+	for i := 0; i < 2; i++ {
+		if status, _, xcache := get(cache.Wrap(upstream.URL + "/missing")); status != http.StatusNotFound || xcache != "MISS" {
+			t.Errorf("Expected uncached 404, got %d %s", status, xcache)
+		}
+	}
+
+	if status, _, _ := get(cache.URL() + "/ftp/example.com/file"); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unsupported scheme, got %d", status)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 3 || stats.Misses != 4 || stats.Store.Bytes > 15 || stats.Store.Evictions == 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestBinaryCacheMirror(t *testing.T) {
+	st, err := store.Open(t.TempDir(), store.Options{})
+	if err != nil {
+		t.Fatalf("store.Open() failed: %v", err)
+	}
+	cache := NewBinaryCache(st)
+	if err := cache.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	mirror := cache.Mirror(NpmMirror())
+	if err := cache.Close(context.Background()); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	if mirror.Registry != "https://registry.npmmirror.com/" {
+		t.Errorf("Expected registry to be unchanged, got %s", mirror.Registry)
+	}
+	if !strings.HasSuffix(mirror.Disturl, "/https/npmmirror.com/mirrors/node/") || !strings.HasPrefix(mirror.Disturl, "http://127.0.0.1:") {
+		t.Errorf("Unexpected disturl: %s", mirror.Disturl)
+	}
+	if !strings.HasSuffix(mirror.BinaryMirrors["electron_mirror"], "/https/npmmirror.com/mirrors/electron/") {
+		t.Errorf("Unexpected electron mirror: %s", mirror.BinaryMirrors["electron_mirror"])
+	}
+	if cache.URL() != "" {
+		t.Error("Expected empty URL after Close()")
+	}
+}
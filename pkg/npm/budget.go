@@ -0,0 +1,332 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// DefaultBudgetPollInterval 检查下载量的默认间隔
+const DefaultBudgetPollInterval = time.Second
+
+// BudgetResource 预算限制的资源
+type BudgetResource string
+
+const (
+	BudgetWallTime      BudgetResource = "wall_time"      // 执行命令的时间
+	BudgetDownloadBytes BudgetResource = "download_bytes" // 下载到npm缓存的字节数
+	BudgetProcesses     BudgetResource = "processes"      // 启动的命令数
+)
+
+// BudgetLimits 预算上限，为0的项不限制
+type BudgetLimits struct {
+	// MaxWallTime 命令执行的总时间，并发执行的命令重叠的时间只计算一次
+	MaxWallTime time.Duration `json:"max_wall_time,omitempty"`
+
+	// MaxDownloadBytes npm缓存目录（_cacache）增长的字节数，用于衡量下载量
+	MaxDownloadBytes int64 `json:"max_download_bytes,omitempty"`
+
+	// MaxProcesses 客户端启动的命令数，包括npm命令和SDK运行的生命周期脚本
+	MaxProcesses int `json:"max_processes,omitempty"`
+
+	// CacheDir npm缓存目录，为空时使用npm_config_cache或npm的默认目录
+	CacheDir string `json:"cache_dir,omitempty"`
+
+	// PollInterval 命令执行期间检查下载量的间隔，为0时使用DefaultBudgetPollInterval
+	PollInterval time.Duration `json:"poll_interval,omitempty"`
+}
+
+// BudgetUsage 已使用的预算
+type BudgetUsage struct {
+	WallTime      time.Duration `json:"wall_time"`
+	DownloadBytes int64         `json:"download_bytes"`
+	Processes     int           `json:"processes"`
+}
+
+// BudgetExceededError 超出预算，Limit和Used的单位为纳秒、字节或命令数
+type BudgetExceededError struct {
+	Resource BudgetResource
+	Limit    int64
+	Used     int64
+}
+
+// Error 实现error接口
+func (e *BudgetExceededError) Error() string {
+	switch e.Resource {
+	case BudgetWallTime:
+		return fmt.Sprintf("budget exceeded: wall time %v reached limit %v", time.Duration(e.Used), time.Duration(e.Limit))
+	case BudgetDownloadBytes:
+		return fmt.Sprintf("budget exceeded: downloaded %d bytes, limit is %d", e.Used, e.Limit)
+	}
+	return fmt.Sprintf("budget exceeded: %s %d reached limit %d", e.Resource, e.Used, e.Limit)
+}
+
+// IsBudgetExceeded 检查是否为超出预算错误
+func IsBudgetExceeded(err error) bool {
+	var budgetErr *BudgetExceededError
+	return errors.As(err, &budgetErr)
+}
+
+// Budget 限制客户端执行命令的时间、下载量和命令数
+//
+// 超出任何一项后正在执行的命令被取消，之后的命令不再启动，都返回*BudgetExceededError，
+// 适合在共享的CI机器上截断失控的安装。同一个Budget可以通过WithBudget用于多个客户端，共享额度。
+type Budget struct {
+	limits  BudgetLimits
+	measure func() int64 // 缓存目录的当前大小
+
+	mu          sync.Mutex
+	exceeded    *BudgetExceededError
+	processes   int
+	active      int
+	activeStart time.Time
+	wallUsed    time.Duration
+	downloaded  int64
+	baseline    int64
+	nextID      int
+	cancels     map[int]context.CancelCauseFunc
+	stopPoll    chan struct{}
+}
+
+// NewBudget 创建预算
+func NewBudget(limits BudgetLimits) *Budget {
+	if limits.PollInterval <= 0 {
+		limits.PollInterval = DefaultBudgetPollInterval
+	}
+	cacheDir := limits.CacheDir
+	if cacheDir == "" {
+		cacheDir = defaultNpmCacheDir()
+	}
+	return &Budget{
+		limits:  limits,
+		measure: func() int64 { return dirSize(filepath.Join(cacheDir, "_cacache")) },
+		cancels: make(map[int]context.CancelCauseFunc),
+	}
+}
+
+// WithBudget 客户端的所有命令受预算限制，被WithBeforeOperation钩子拒绝的命令不计入预算
+func WithBudget(budget *Budget) ClientOption {
+	return func(c *client) {
+		c.budgets = append(c.budgets, budget)
+	}
+}
+
+// Limits 返回预算上限
+func (b *Budget) Limits() BudgetLimits {
+	return b.limits
+}
+
+// Usage 返回已使用的预算，包括正在执行的命令
+func (b *Budget) Usage() BudgetUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	usage := BudgetUsage{WallTime: b.wallUsed, DownloadBytes: b.downloaded, Processes: b.processes}
+	if b.active > 0 {
+		usage.WallTime += time.Since(b.activeStart)
+	}
+	return usage
+}
+
+// Err 返回超出预算的错误，没有超出时返回nil
+func (b *Budget) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.exceeded == nil {
+		return nil
+	}
+	return b.exceeded
+}
+
+// start 登记一条命令，返回它的ID和时间预算的截止时间，已超出预算时返回错误
+func (b *Budget) start(cancel context.CancelCauseFunc) (int, time.Time, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.exceeded != nil {
+		return 0, time.Time{}, b.exceeded
+	}
+	if b.limits.MaxProcesses > 0 && b.processes >= b.limits.MaxProcesses {
+		b.exceeded = &BudgetExceededError{Resource: BudgetProcesses, Limit: int64(b.limits.MaxProcesses), Used: int64(b.processes)}
+		return 0, time.Time{}, b.exceeded
+	}
+
+	if b.limits.MaxWallTime > 0 && b.active == 0 && b.wallUsed >= b.limits.MaxWallTime {
+		b.exceeded = &BudgetExceededError{Resource: BudgetWallTime, Limit: int64(b.limits.MaxWallTime), Used: int64(b.wallUsed)}
+		return 0, time.Time{}, b.exceeded
+	}
+
+	b.processes++
+	if b.active == 0 {
+		b.activeStart = time.Now()
+		if b.limits.MaxDownloadBytes > 0 {
+			b.baseline = b.measure()
+			b.stopPoll = make(chan struct{})
+			go b.poll(b.stopPoll)
+		}
+	}
+	b.active++
+	b.nextID++
+	b.cancels[b.nextID] = cancel
+
+	var deadline time.Time
+	if b.limits.MaxWallTime > 0 {
+		// 有命令执行时已用时间随时钟增长，并发的命令共用同一个截止时间
+		deadline = b.activeStart.Add(b.limits.MaxWallTime - b.wallUsed)
+	}
+	return b.nextID, deadline, nil
+}
+
+// finish 结束一条命令，下载量在命令结束后超出时返回错误
+func (b *Budget) finish(id int) *BudgetExceededError {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.cancels, id)
+	b.active--
+	if b.active > 0 {
+		return nil
+	}
+
+	b.wallUsed += time.Since(b.activeStart)
+	if b.limits.MaxDownloadBytes > 0 {
+		close(b.stopPoll)
+		b.downloaded += max(b.measure()-b.baseline, 0)
+		if b.downloaded > b.limits.MaxDownloadBytes && b.exceeded == nil {
+			b.exceeded = &BudgetExceededError{Resource: BudgetDownloadBytes, Limit: b.limits.MaxDownloadBytes, Used: b.downloaded}
+			return b.exceeded
+		}
+	}
+	return nil
+}
+
+// exceed 记录超出预算并取消所有正在执行的命令，已有记录时返回先前的错误
+func (b *Budget) exceed(resource BudgetResource, limit, used int64) *BudgetExceededError {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lockedExceed(resource, limit, used)
+}
+
+// lockedExceed 同exceed，调用方需持有mu
+func (b *Budget) lockedExceed(resource BudgetResource, limit, used int64) *BudgetExceededError {
+	if b.exceeded == nil {
+		b.exceeded = &BudgetExceededError{Resource: resource, Limit: limit, Used: used}
+	}
+	for _, cancel := range b.cancels {
+		cancel(b.exceeded)
+	}
+	return b.exceeded
+}
+
+// poll 命令执行期间定期检查缓存目录的增长
+func (b *Budget) poll(stop chan struct{}) {
+	ticker := time.NewTicker(b.limits.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		b.mu.Lock()
+		select {
+		case <-stop:
+			b.mu.Unlock()
+			return
+		default:
+		}
+		used := b.downloaded + max(b.measure()-b.baseline, 0)
+		if used > b.limits.MaxDownloadBytes {
+			b.lockedExceed(BudgetDownloadBytes, b.limits.MaxDownloadBytes, used)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// budgetExecutor 按预算启动和取消命令的执行器
+type budgetExecutor struct {
+	executor utils.CommandExecutor
+	budget   *Budget
+}
+
+// Execute 实现utils.CommandExecutor接口，超出预算时返回*BudgetExceededError
+func (e *budgetExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	cmdCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	id, deadline, err := e.budget.start(cancel)
+	if err != nil {
+		return &utils.ExecuteResult{Error: err}, err
+	}
+	if !deadline.IsZero() {
+		limit := e.budget.limits.MaxWallTime
+		timer := time.AfterFunc(time.Until(deadline), func() {
+			e.budget.exceed(BudgetWallTime, int64(limit), int64(limit))
+		})
+		defer timer.Stop()
+	}
+
+	result, err := e.executor.Execute(cmdCtx, options)
+	finishErr := e.budget.finish(id)
+	if result == nil {
+		result = &utils.ExecuteResult{}
+	}
+
+	var budgetErr *BudgetExceededError
+	switch {
+	case ctx.Err() == nil && errors.As(context.Cause(cmdCtx), &budgetErr):
+		err = budgetErr
+	case err == nil && finishErr != nil:
+		err = finishErr
+	default:
+		return result, err
+	}
+	result.Success = false
+	result.Error = err
+	return result, err
+}
+
+// SetLogger 设置被包装执行器的日志记录器
+func (e *budgetExecutor) SetLogger(logger *slog.Logger) {
+	if executor, ok := e.executor.(interface{ SetLogger(*slog.Logger) }); ok {
+		executor.SetLogger(logger)
+	}
+}
+
+// defaultNpmCacheDir npm默认的缓存目录
+func defaultNpmCacheDir() string {
+	if dir := os.Getenv("npm_config_cache"); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return filepath.Join(dir, "npm-cache")
+		}
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".npm")
+}
+
+// dirSize 目录中普通文件的总大小，目录不存在时为0
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,137 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// slowExecutor 在duration后成功，ctx结束时与utils.Executor一样返回取消的结果
+type slowExecutor struct {
+	duration time.Duration
+	onStart  func()
+}
+
+func (e *slowExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	if e.onStart != nil {
+		e.onStart()
+	}
+	select {
+	case <-time.After(e.duration):
+		return &utils.ExecuteResult{Success: true}, nil
+	case <-ctx.Done():
+		return &utils.ExecuteResult{Cancelled: true, Error: context.Canceled}, context.Canceled
+	}
+}
+
+func TestBudgetProcesses(t *testing.T) {
+	budget := NewBudget(BudgetLimits{MaxProcesses: 2})
+	client, err := NewClientWithExecutor("npm", &slowExecutor{}, WithBudget(budget))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := client.Init(ctx, InitOptions{}); err != nil {
+			t.Fatalf("Init() %d failed: %v", i, err)
+		}
+	}
+
+	err = client.Init(ctx, InitOptions{})
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || budgetErr.Resource != BudgetProcesses || budgetErr.Limit != 2 {
+		t.Fatalf("Expected process budget error, got %v", err)
+	}
+	if !IsBudgetExceeded(budget.Err()) || budget.Usage().Processes != 2 {
+		t.Errorf("Unexpected budget state: %v %+v", budget.Err(), budget.Usage())
+	}
+}
+
+func TestBudgetWallTime(t *testing.T) {
+	budget := NewBudget(BudgetLimits{MaxWallTime: 50 * time.Millisecond})
+	executor := &budgetExecutor{executor: &slowExecutor{duration: time.Hour}, budget: budget}
+
+	start := time.Now()
+	result, err := executor.Execute(context.Background(), utils.ExecuteOptions{Command: "npm", Args: []string{"install"}})
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || budgetErr.Resource != BudgetWallTime {
+		t.Fatalf("Expected wall time budget error, got %v", err)
+	}
+	if result.Success || result.Error != err {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Command was not cut off, took %v", elapsed)
+	}
+
+	// 超出后不再启动命令
+	if _, err := executor.Execute(context.Background(), utils.ExecuteOptions{Command: "npm"}); !IsBudgetExceeded(err) {
+		t.Errorf("Expected later commands to be rejected, got %v", err)
+	}
+}
+
+func TestBudgetWallTimeSharedByConcurrentCommands(t *testing.T) {
+	budget := NewBudget(BudgetLimits{MaxWallTime: time.Second})
+	executor := &budgetExecutor{executor: &slowExecutor{duration: 20 * time.Millisecond}, budget: budget}
+
+	done := make(chan error, 4)
+	for range 4 {
+		go func() {
+			_, err := executor.Execute(context.Background(), utils.ExecuteOptions{Command: "npm"})
+			done <- err
+		}()
+	}
+	for range 4 {
+		if err := <-done; err != nil {
+			t.Fatalf("Execute() failed: %v", err)
+		}
+	}
+	// 并发的命令重叠的时间只计算一次
+	if usage := budget.Usage(); usage.WallTime >= 80*time.Millisecond || usage.Processes != 4 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+}
+
+func TestBudgetDownloadBytes(t *testing.T) {
+	var cacheSize atomic.Int64
+	cacheSize.Store(1000)
+
+	budget := NewBudget(BudgetLimits{MaxDownloadBytes: 500, PollInterval: 5 * time.Millisecond})
+	budget.measure = cacheSize.Load
+
+	// 下载量在限制内
+	executor := &budgetExecutor{executor: &slowExecutor{onStart: func() { cacheSize.Add(300) }}, budget: budget}
+	if _, err := executor.Execute(context.Background(), utils.ExecuteOptions{Command: "npm"}); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if usage := budget.Usage(); usage.DownloadBytes != 300 {
+		t.Errorf("Expected 300 downloaded bytes, got %+v", usage)
+	}
+
+	// 执行期间超出限制时取消命令
+	executor.executor = &slowExecutor{duration: time.Hour, onStart: func() { cacheSize.Add(300) }}
+	_, err := executor.Execute(context.Background(), utils.ExecuteOptions{Command: "npm"})
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || budgetErr.Resource != BudgetDownloadBytes || budgetErr.Used != 600 {
+		t.Fatalf("Expected download budget error, got %v", err)
+	}
+}
+
+func TestBudgetParentCancel(t *testing.T) {
+	budget := NewBudget(BudgetLimits{MaxWallTime: time.Hour})
+	executor := &budgetExecutor{executor: &slowExecutor{duration: time.Hour}, budget: budget}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := executor.Execute(ctx, utils.ExecuteOptions{Command: "npm"}); !errors.Is(err, context.Canceled) || IsBudgetExceeded(err) {
+		t.Errorf("Expected the caller's cancellation, got %v", err)
+	}
+	if budget.Err() != nil {
+		t.Errorf("Expected budget not to be exceeded, got %v", budget.Err())
+	}
+}
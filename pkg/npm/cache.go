@@ -0,0 +1,196 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/store"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// Cache GetPackageInfo和Search结果的缓存，值为JSON编码的结果
+//
+// 过期时间由实现决定，实现需要支持并发调用。
+type Cache interface {
+	// 读取缓存，不存在或已过期时返回false
+	Get(key string) ([]byte, bool)
+
+	// 写入缓存
+	Set(key string, value []byte)
+}
+
+// ClientOption 客户端选项
+type ClientOption func(*client)
+
+// WithCache 缓存GetPackageInfo和Search的结果，键包含命令、registry和包名或查询
+//
+// 依赖扫描等需要反复查询同一批包的场景可以避免重复访问registry，失败的结果不缓存。
+func WithCache(cache Cache) ClientOption {
+	return func(c *client) {
+		c.cache = cache
+	}
+}
+
+// MemoryCache 进程内的缓存
+type MemoryCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	now     func() time.Time
+}
+
+// memoryCacheEntry 内存缓存条目
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache 创建内存缓存，ttl为0表示不过期
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		ttl:     ttl,
+		entries: make(map[string]memoryCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// Get 实现Cache接口
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !entry.expires.IsZero() && !m.now().Before(entry.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set 实现Cache接口
+func (m *MemoryCache) Set(key string, value []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := memoryCacheEntry{value: value}
+	if m.ttl > 0 {
+		entry.expires = m.now().Add(m.ttl)
+	}
+	m.entries[key] = entry
+}
+
+// Len 返回缓存的条目数，包括尚未清理的过期条目
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// Purge 清理过期的条目，返回清理的条目数
+func (m *MemoryCache) Purge() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	purged := 0
+	for key, entry := range m.entries {
+		if !entry.expires.IsZero() && !now.Before(entry.expires) {
+			delete(m.entries, key)
+			purged++
+		}
+	}
+	return purged
+}
+
+// Clear 清空缓存
+func (m *MemoryCache) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = make(map[string]memoryCacheEntry)
+}
+
+// packageCacheBucket StoreCache使用的存储桶
+const packageCacheBucket = "package-cache"
+
+// StoreCache 保存在store.Store中的缓存，进程重启后仍然可用
+type StoreCache struct {
+	store *store.Store
+	ttl   time.Duration
+}
+
+// NewStoreCache 创建基于store.Store的缓存，ttl为0表示不过期
+func NewStoreCache(s *store.Store, ttl time.Duration) *StoreCache {
+	return &StoreCache{store: s, ttl: ttl}
+}
+
+// Get 实现Cache接口，读取失败按未命中处理
+func (s *StoreCache) Get(key string) ([]byte, bool) {
+	value, err := s.store.Get(packageCacheBucket, key)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set 实现Cache接口，写入失败时忽略
+func (s *StoreCache) Set(key string, value []byte) {
+	_ = s.store.Put(packageCacheBucket, key, value, s.ttl)
+}
+
+// cacheKey 缓存键
+func cacheKey(op, registry, name string) string {
+	return op + "\x00" + registry + "\x00" + name
+}
+
+// cacheRegistry 返回缓存键使用的registry，结果在客户端内复用
+//
+// registry可能来自环境变量、项目或用户的.npmrc，直接以npm config get registry的结果为准，
+// 获取失败时为空字符串，下次调用时重新获取。
+func (c *client) cacheRegistry(ctx context.Context) string {
+	c.registryMu.Lock()
+	defer c.registryMu.Unlock()
+	if c.registry != "" {
+		return c.registry
+	}
+
+	result, err := c.executor.Execute(ctx, utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"config", "get", "registry"},
+		CaptureOutput: true,
+		Timeout:       30 * time.Second,
+	})
+	if err == nil && result != nil && result.Success {
+		c.registry = strings.TrimSpace(result.Stdout)
+	}
+	return c.registry
+}
+
+// cached 从缓存读取结果，没有配置缓存或未命中时调用fetch并缓存成功的结果
+func cached[T any](ctx context.Context, c *client, op, name string, fetch func() (T, error)) (T, error) {
+	if c.cache == nil {
+		return fetch()
+	}
+
+	key := cacheKey(op, c.cacheRegistry(ctx), name)
+	if data, ok := c.cache.Get(key); ok {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	if data, err := json.Marshal(value); err == nil {
+		c.cache.Set(key, data)
+	}
+	return value, nil
+}
@@ -0,0 +1,149 @@
+package npm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/store"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+func TestMemoryCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewMemoryCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected miss on empty cache")
+	}
+	cache.Set("a", []byte("1"))
+	if value, ok := cache.Get("a"); !ok || string(value) != "1" {
+		t.Errorf("Get() = %q, %v", value, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected entry to expire after TTL")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Expected expired entry to be removed, got %d entries", cache.Len())
+	}
+
+	cache.Set("b", []byte("2"))
+	cache.Set("c", []byte("3"))
+	now = now.Add(time.Minute)
+	cache.Set("d", []byte("4"))
+	if purged := cache.Purge(); purged != 2 || cache.Len() != 1 {
+		t.Errorf("Expected 2 expired entries to be purged, got %d with %d left", purged, cache.Len())
+	}
+
+	cache.Set("b", []byte("2"))
+	cache.Clear()
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected cache to be cleared")
+	}
+
+	// ttl为0时不过期
+	forever := NewMemoryCache(0)
+	forever.Set("a", []byte("1"))
+	forever.now = func() time.Time { return now.Add(100 * 365 * 24 * time.Hour) }
+	if _, ok := forever.Get("a"); !ok {
+		t.Error("Expected entry without TTL not to expire")
+	}
+}
+
+func TestStoreCache(t *testing.T) {
+	dir := t.TempDir()
+	s, err := store.Open(dir, store.Options{})
+	if err != nil {
+		t.Fatalf("store.Open() failed: %v", err)
+	}
+	cache := NewStoreCache(s, time.Hour)
+	cache.Set("view\x00https://registry.npmjs.org/\x00lodash", []byte(`{"name":"lodash"}`))
+
+	// 重新打开后仍然可用
+	reopened, err := store.Open(dir, store.Options{})
+	if err != nil {
+		t.Fatalf("store.Open() failed: %v", err)
+	}
+	value, ok := NewStoreCache(reopened, time.Hour).Get("view\x00https://registry.npmjs.org/\x00lodash")
+	if !ok || string(value) != `{"name":"lodash"}` {
+		t.Errorf("Get() after reopen = %q, %v", value, ok)
+	}
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("Expected miss for unknown key")
+	}
+}
+
+func TestClientWithCache(t *testing.T) {
+	ctx := context.Background()
+	replayer := utils.NewReplayer(&utils.Fixture{Interactions: []utils.Interaction{
+		{Command: "npm", Args: []string{"config", "get", "registry"}, Stdout: "https://registry.npmjs.org/\n"},
+		{Command: "npm", Args: []string{"view", "lodash", "--json"}, Stdout: `{"name": "lodash", "version": "4.17.21"}`},
+		{Command: "npm", Args: []string{"search", "react", "--json"}, Stdout: `[{"name": "react", "version": "18.3.1"}]`},
+		{Command: "npm", Args: []string{"view", "missing", "--json"}, ExitCode: 1, Stderr: "npm error code E404\n"},
+		{Command: "npm", Args: []string{"view", "missing", "--json"}, ExitCode: 1, Stderr: "npm error code E404\n"},
+	}})
+	cache := NewMemoryCache(time.Minute)
+	client, err := NewClientWithExecutor("npm", replayer, WithCache(cache))
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		info, err := client.GetPackageInfo(ctx, "lodash")
+		if err != nil || info.Name != "lodash" || info.Version != "4.17.21" {
+			t.Fatalf("GetPackageInfo() #%d = %+v, %v", i, info, err)
+		}
+		// 调用方修改结果不影响缓存
+		info.Version = "modified"
+
+		results, err := client.Search(ctx, "react")
+		if err != nil || len(results) != 1 || results[0].Package.Name != "react" {
+			t.Fatalf("Search() #%d = %+v, %v", i, results, err)
+		}
+	}
+	if _, ok := cache.Get(cacheKey("view", "https://registry.npmjs.org/", "lodash")); !ok {
+		t.Error("Expected cache key to include the registry")
+	}
+
+	// 失败的结果不缓存
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetPackageInfo(ctx, "missing"); !IsPackageNotFound(err) {
+			t.Fatalf("Expected package not found, got %v", err)
+		}
+	}
+
+	if remaining := replayer.Remaining(); len(remaining) != 0 {
+		t.Errorf("Expected every interaction to be used once, %d left", len(remaining))
+	}
+}
+
+func TestClientCacheRegistryRetriesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	replayer := utils.NewReplayer(&utils.Fixture{Interactions: []utils.Interaction{
+		{Command: "npm", Args: []string{"config", "get", "registry"}, ExitCode: 1, Stderr: "npm error code ETIMEDOUT\n"},
+		{Command: "npm", Args: []string{"view", "lodash", "--json"}, Stdout: `{"name": "lodash", "version": "4.17.21"}`},
+		{Command: "npm", Args: []string{"config", "get", "registry"}, Stdout: "https://registry.npmjs.org/\n"},
+		{Command: "npm", Args: []string{"view", "lodash", "--json"}, Stdout: `{"name": "lodash", "version": "4.17.21"}`},
+	}})
+	cache := NewMemoryCache(time.Minute)
+	client, err := NewClientWithExecutor("npm", replayer, WithCache(cache))
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+
+	// 获取registry失败时不缓存，下次调用重新获取，之后复用成功的结果
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetPackageInfo(ctx, "lodash"); err != nil {
+			t.Fatalf("GetPackageInfo() #%d failed: %v", i, err)
+		}
+	}
+	if _, ok := cache.Get(cacheKey("view", "https://registry.npmjs.org/", "lodash")); !ok {
+		t.Error("Expected cache key to include the registry after a retry")
+	}
+	if remaining := replayer.Remaining(); len(remaining) != 0 {
+		t.Errorf("Expected every interaction to be used once, %d left", len(remaining))
+	}
+}
@@ -0,0 +1,282 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/vcs"
+)
+
+// CanaryOptions canary版本发布选项
+type CanaryOptions struct {
+	WorkingDir string            `json:"working_dir,omitempty"`
+	PreID      string            `json:"pre_id,omitempty"` // 预发布标识，默认canary
+	Tag        string            `json:"tag,omitempty"`    // dist-tag，默认与PreID相同
+	Base       string            `json:"base,omitempty"`   // 基础版本，默认为package.json版本的下一个补丁版本
+	Commit     string            `json:"commit,omitempty"` // 提交ID，为空时读取git HEAD
+	Keep       int               `json:"keep,omitempty"`   // 大于0时发布后只保留最新的Keep个同标识版本，其余撤销
+	Access     string            `json:"access,omitempty"`
+	Registry   string            `json:"registry,omitempty"`
+	OTP        string            `json:"otp,omitempty"`
+	DryRun     bool              `json:"dry_run,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// CanaryResult canary版本发布结果
+type CanaryResult struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version"`
+	Spec    string   `json:"spec"` // <name>@<version>，可以直接用于npm install
+	Tag     string   `json:"tag"`
+	Commit  string   `json:"commit"`
+	Removed []string `json:"removed,omitempty"` // 清理时撤销的旧版本
+}
+
+// CanaryPublisher 发布canary/snapshot预发布版本，常用于PR预览包
+//
+// 发布时临时修改package.json中的version，发布完成后恢复原文件。
+type CanaryPublisher struct {
+	client   Client
+	registry *registry.Client
+	now      func() time.Time
+}
+
+// NewCanaryPublisher 创建canary发布器，registryClient只在清理旧版本时使用，可以为nil
+func NewCanaryPublisher(npmClient Client, registryClient *registry.Client) *CanaryPublisher {
+	return &CanaryPublisher{
+		client:   npmClient,
+		registry: registryClient,
+		now:      time.Now,
+	}
+}
+
+// CanaryVersion 计算canary版本号，格式为<base>-<preid>.<提交ID前7位>.<UTC时间戳>
+//
+// 例如1.2.4-canary.1a2b3c4.20240305060709。提交ID全是数字时加g前缀，避免以0开头的数字标识
+// 不符合semver；commit为空时省略该部分。同一基础版本的canary按时间戳而不是semver顺序排列。
+func CanaryVersion(base, preid, commit string, t time.Time) (string, error) {
+	v, err := semver.Parse(base)
+	if err != nil {
+		return "", NewValidationError("base", base, err.Error())
+	}
+	if preid == "" {
+		preid = "canary"
+	}
+	if !isPrereleaseIdentifier(preid) {
+		return "", NewValidationError("pre_id", preid, "must contain only alphanumerics, hyphens and dots")
+	}
+
+	version := fmt.Sprintf("%d.%d.%d-%s", v.Major, v.Minor, v.Patch, preid)
+	if commit != "" {
+		short := strings.ToLower(commit)
+		if len(short) > 7 {
+			short = short[:7]
+		}
+		if !isPrereleaseIdentifier(short) || strings.Contains(short, ".") {
+			return "", NewValidationError("commit", commit, "must be a commit hash")
+		}
+		if isNumericIdentifier(short) {
+			short = "g" + short
+		}
+		version += "." + short
+	}
+	return version + "." + t.UTC().Format(canaryTimeFormat), nil
+}
+
+// canaryTimeFormat canary版本中时间戳的格式
+const canaryTimeFormat = "20060102150405"
+
+// isNumericIdentifier 是否为纯数字的标识
+func isNumericIdentifier(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
+
+// canaryTimestamp 返回canary版本中的时间戳标识，没有时返回空字符串
+func canaryTimestamp(v *semver.Version) string {
+	for _, id := range v.Prerelease {
+		if len(id) == len(canaryTimeFormat) && isNumericIdentifier(id) {
+			return id
+		}
+	}
+	return ""
+}
+
+// isPrereleaseIdentifier 检查是否为合法的预发布标识片段
+func isPrereleaseIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return !strings.HasPrefix(s, ".") && !strings.HasSuffix(s, ".") && !strings.Contains(s, "..")
+}
+
+// Publish 计算canary版本并以options.Tag发布
+func (p *CanaryPublisher) Publish(ctx context.Context, options CanaryOptions) (*CanaryResult, error) {
+	if options.PreID == "" {
+		options.PreID = "canary"
+	}
+	if options.Tag == "" {
+		options.Tag = options.PreID
+	}
+	if options.Tag == "latest" {
+		return nil, NewValidationError("tag", options.Tag, "canary versions must not be published as latest")
+	}
+	if options.Keep > 0 && p.registry == nil {
+		return nil, NewValidationError("keep", fmt.Sprint(options.Keep), "cleanup requires a registry client")
+	}
+
+	path := filepath.Join(options.WorkingDir, "package.json")
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+	pkg := NewPackageJSON(path)
+	if err := pkg.Load(); err != nil {
+		return nil, err
+	}
+	if pkg.GetName() == "" {
+		return nil, NewValidationError("name", "", "package.json must have a name")
+	}
+
+	base := options.Base
+	if base == "" {
+		if base, err = nextPatchVersion(pkg.GetVersion()); err != nil {
+			return nil, err
+		}
+	}
+
+	commit := options.Commit
+	if commit == "" {
+		if commit, err = vcs.NewGit(options.WorkingDir).Head(ctx); err != nil {
+			return nil, fmt.Errorf("failed to read git commit: %w", err)
+		}
+	}
+
+	version, err := CanaryVersion(base, options.PreID, commit, p.now())
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := setJSONStringMember(string(original), nil, "version", version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set version: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write package.json: %w", err)
+	}
+	restore := func() error {
+		if err := os.WriteFile(path, original, 0644); err != nil {
+			return fmt.Errorf("failed to restore package.json: %w", err)
+		}
+		return nil
+	}
+
+	err = p.client.Publish(ctx, PublishOptions{
+		Tag:        options.Tag,
+		Access:     options.Access,
+		Registry:   options.Registry,
+		WorkingDir: options.WorkingDir,
+		DryRun:     options.DryRun,
+		OTP:        options.OTP,
+		Env:        options.Env,
+		UserConfig: options.UserConfig,
+	})
+	if restoreErr := restore(); err == nil {
+		err = restoreErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CanaryResult{
+		Name:    pkg.GetName(),
+		Version: version,
+		Spec:    pkg.GetName() + "@" + version,
+		Tag:     options.Tag,
+		Commit:  commit,
+	}
+	if options.Keep > 0 && !options.DryRun {
+		result.Removed, err = p.cleanup(ctx, result.Name, version, options)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// cleanup 撤销除最新Keep个之外的同标识canary版本
+func (p *CanaryPublisher) cleanup(ctx context.Context, name, published string, options CanaryOptions) ([]string, error) {
+	// 只需要版本号，不解码各版本的清单
+	packument, err := p.registry.GetPackumentWithOptions(ctx, name, registry.PackumentOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of %s: %w", name, err)
+	}
+
+	// registry可能还没有返回刚发布的版本
+	versions := []*semver.Version{semver.MustParse(published)}
+	for version := range packument.Versions {
+		if version == published {
+			continue
+		}
+		v, err := semver.Parse(version)
+		if err == nil && strings.HasPrefix(strings.Join(v.Prerelease, "."), options.PreID+".") {
+			versions = append(versions, v)
+		}
+	}
+	if len(versions) <= options.Keep {
+		return nil, nil
+	}
+
+	// 按发布时间从早到晚排列，提交ID在时间戳之前，不能按semver排序；刚发布的版本始终保留
+	sort.SliceStable(versions, func(i, j int) bool {
+		ti, tj := canaryTimestamp(versions[i]), canaryTimestamp(versions[j])
+		if ti != tj {
+			return ti < tj
+		}
+		return versions[i].LessThan(versions[j])
+	})
+	stale := versions[:len(versions)-options.Keep]
+
+	var removed []string
+	for _, v := range stale {
+		version := v.String()
+		if version == published {
+			continue
+		}
+		err := p.client.Unpublish(ctx, name+"@"+version, UnpublishOptions{
+			Registry:   options.Registry,
+			OTP:        options.OTP,
+			Env:        options.Env,
+			UserConfig: options.UserConfig,
+		})
+		if err != nil {
+			return removed, err
+		}
+		removed = append(removed, version)
+	}
+	return removed, nil
+}
+
+// nextPatchVersion 计算下一个补丁版本，预发布版本直接去掉预发布部分
+func nextPatchVersion(version string) (string, error) {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return "", NewValidationError("version", version, err.Error())
+	}
+	if len(v.Prerelease) == 0 {
+		v.Patch++
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch), nil
+}
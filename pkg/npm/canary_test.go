@@ -0,0 +1,173 @@
+package npm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// canaryTestClient 记录发布和撤销调用的客户端
+type canaryTestClient struct {
+	*MockClient
+	published   []string // 发布时package.json中的版本
+	tags        []string
+	unpublished []string
+}
+
+func (c *canaryTestClient) Publish(ctx context.Context, options PublishOptions) error {
+	pkg := NewPackageJSON(filepath.Join(options.WorkingDir, "package.json"))
+	if err := pkg.Load(); err != nil {
+		return err
+	}
+	c.published = append(c.published, pkg.GetVersion())
+	c.tags = append(c.tags, options.Tag)
+	return nil
+}
+
+func (c *canaryTestClient) Unpublish(ctx context.Context, spec string, options UnpublishOptions) error {
+	c.unpublished = append(c.unpublished, spec)
+	return nil
+}
+
+func TestCanaryVersion(t *testing.T) {
+	at := time.Date(2024, 3, 5, 14, 7, 9, 0, time.FixedZone("CST", 8*3600))
+
+	version, err := CanaryVersion("1.2.4", "", "0A1B2C3D4E5F", at)
+	if err != nil {
+		t.Fatalf("CanaryVersion() failed: %v", err)
+	}
+	if version != "1.2.4-canary.0a1b2c3.20240305060709" {
+		t.Errorf("CanaryVersion() = %s", version)
+	}
+
+	version, _ = CanaryVersion("v2.0.0-beta.1", "pr-42", "", at)
+	if version != "2.0.0-pr-42.20240305060709" {
+		t.Errorf("CanaryVersion() = %s", version)
+	}
+
+	// 全是数字的提交ID加g前缀
+	version, _ = CanaryVersion("1.2.4", "", "0123456789", at)
+	if version != "1.2.4-canary.g0123456.20240305060709" {
+		t.Errorf("CanaryVersion() = %s", version)
+	}
+
+	for _, args := range [][2]string{{"not-a-version", "canary"}, {"1.0.0", "bad id"}, {"1.0.0", "x..y"}} {
+		if _, err := CanaryVersion(args[0], args[1], "", at); !IsValidationError(err, nil) {
+			t.Errorf("CanaryVersion(%q, %q): expected validation error, got %v", args[0], args[1], err)
+		}
+	}
+	if _, err := CanaryVersion("1.0.0", "canary", "not/a/hash", at); err == nil {
+		t.Error("Expected error for invalid commit")
+	}
+}
+
+func TestCanaryPublisherPublish(t *testing.T) {
+	dir := t.TempDir()
+	manifest := "{\n  \"name\": \"@scope/pkg\",\n  \"version\": \"1.2.3\"\n}\n"
+	writeTestFile(t, filepath.Join(dir, "package.json"), manifest)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"@scope/pkg","versions":{
+			"1.2.3":{"version":"1.2.3"},
+			"1.2.4-canary.ccccccc.20240101000000":{"version":"1.2.4-canary.ccccccc.20240101000000"},
+			"1.2.4-canary.bbbbbbb.20240102000000":{"version":"1.2.4-canary.bbbbbbb.20240102000000"},
+			"1.2.4-canary.aaaaaaa.20240103000000":{"version":"1.2.4-canary.aaaaaaa.20240103000000"},
+			"1.2.4-beta.0":{"version":"1.2.4-beta.0"}
+		}}`))
+	}))
+	defer server.Close()
+
+	npmClient := &canaryTestClient{MockClient: NewMockClient()}
+	publisher := NewCanaryPublisher(npmClient, registry.NewClient(server.URL))
+	publisher.now = func() time.Time { return time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC) }
+
+	result, err := publisher.Publish(context.Background(), CanaryOptions{
+		WorkingDir: dir,
+		Commit:     "1234abc890def",
+		Keep:       2,
+	})
+	if err != nil {
+		t.Fatalf("Publish() failed: %v", err)
+	}
+
+	expected := "1.2.4-canary.1234abc.20240104000000"
+	if result.Version != expected || result.Tag != "canary" || result.Name != "@scope/pkg" || result.Spec != "@scope/pkg@"+expected {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(npmClient.published) != 1 || npmClient.published[0] != expected || npmClient.tags[0] != "canary" {
+		t.Errorf("Expected publish of %s under canary, got %v %v", expected, npmClient.published, npmClient.tags)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "package.json")); string(data) != manifest {
+		t.Errorf("Expected package.json to be restored, got:\n%s", data)
+	}
+
+	// 按时间戳保留最新的2个，其中包括刚发布的版本
+	if strings.Join(npmClient.unpublished, " ") != "@scope/pkg@1.2.4-canary.ccccccc.20240101000000 @scope/pkg@1.2.4-canary.bbbbbbb.20240102000000" {
+		t.Errorf("Unexpected unpublished versions: %v", npmClient.unpublished)
+	}
+	if len(result.Removed) != 2 {
+		t.Errorf("Unexpected removed versions: %v", result.Removed)
+	}
+
+	if _, err := publisher.Publish(context.Background(), CanaryOptions{WorkingDir: dir, Tag: "latest", Commit: "abc"}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for latest tag, got %v", err)
+	}
+	if _, err := NewCanaryPublisher(npmClient, nil).Publish(context.Background(), CanaryOptions{WorkingDir: dir, Keep: 1, Commit: "abc"}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for cleanup without registry, got %v", err)
+	}
+}
+
+// canaryExecutor 在publishExecutor的基础上记录打包时package.json中的版本
+type canaryExecutor struct {
+	publishExecutor
+	packed string
+}
+
+func (e *canaryExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	if options.Args[0] == "pack" {
+		pkg := NewPackageJSON(filepath.Join(options.WorkingDir, "package.json"))
+		if err := pkg.Load(); err != nil {
+			return nil, err
+		}
+		e.packed = pkg.GetVersion()
+	}
+	return e.publishExecutor.Execute(ctx, options)
+}
+
+func TestPublisherPublishCanary(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"name": "@scope/pkg", "version": "1.2.3"}`
+	writeTestFile(t, filepath.Join(dir, "package.json"), manifest)
+
+	executor := &canaryExecutor{publishExecutor: publishExecutor{t: t, name: "@scope/pkg"}}
+	c, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+
+	result, err := NewPublisher(c, nil).PublishCanary(context.Background(), CanaryOptions{WorkingDir: dir, Commit: "1a2b3c4d5e"})
+	if err != nil {
+		t.Fatalf("PublishCanary() failed: %v", err)
+	}
+	if !regexp.MustCompile(`^1\.2\.4-canary\.1a2b3c4\.\d{14}$`).MatchString(result.Version) || result.Spec != "@scope/pkg@"+result.Version {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if executor.packed != result.Version {
+		t.Errorf("Expected %s to be packed, got %s", result.Version, executor.packed)
+	}
+	if args := strings.Join(executor.publish, " "); !strings.Contains(args, "--tag canary") || strings.Contains(args, "latest") {
+		t.Errorf("Expected publish under canary tag, got %s", args)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "package.json")); string(data) != manifest {
+		t.Errorf("Expected package.json to be restored, got:\n%s", data)
+	}
+}
@@ -0,0 +1,233 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+)
+
+// Channel 发布渠道，把分支映射到dist-tag
+type Channel struct {
+	Branch     string `json:"branch"`               // 分支名，支持path.Match通配符，例如release/*
+	Tag        string `json:"tag"`                  // dist-tag
+	Prerelease bool   `json:"prerelease,omitempty"` // 是否允许指向预发布版本
+}
+
+// DefaultChannels 默认渠道：main和master发布到latest，next发布到next
+func DefaultChannels() []Channel {
+	return []Channel{
+		{Branch: "main", Tag: "latest"},
+		{Branch: "master", Tag: "latest"},
+		{Branch: "next", Tag: "next", Prerelease: true},
+	}
+}
+
+// ChannelRelease 某个分支上产生的版本
+type ChannelRelease struct {
+	Branch  string `json:"branch"`
+	Package string `json:"package"`
+	Version string `json:"version"`
+}
+
+// ChannelOptions 渠道操作选项
+type ChannelOptions struct {
+	DryRun         bool              `json:"dry_run,omitempty"`         // 只计算需要的tag移动
+	AllowDowngrade bool              `json:"allow_downgrade,omitempty"` // 允许把tag移到更低的版本
+	Registry       string            `json:"registry,omitempty"`
+	OTP            string            `json:"otp,omitempty"`
+	Env            map[string]string `json:"env,omitempty"`
+	UserConfig     string            `json:"user_config,omitempty"`
+}
+
+// TagMove 一次dist-tag移动
+type TagMove struct {
+	Package string `json:"package"`
+	Branch  string `json:"branch"`
+	Tag     string `json:"tag"`
+	From    string `json:"from,omitempty"` // 移动前的版本，为空表示新建tag
+	To      string `json:"to"`
+}
+
+// ChannelResult 渠道操作结果
+type ChannelResult struct {
+	Moves      []TagMove `json:"moves"`
+	DryRun     bool      `json:"dry_run,omitempty"`
+	RolledBack []TagMove `json:"rolled_back,omitempty"` // 失败后已恢复的移动
+}
+
+// ChannelManager 按分支管理dist-tag，移动前校验，失败时回滚已完成的移动
+type ChannelManager struct {
+	client   Client
+	channels []Channel
+}
+
+// NewChannelManager 创建渠道管理器，channels为空时使用DefaultChannels
+func NewChannelManager(npmClient Client, channels []Channel) (*ChannelManager, error) {
+	if len(channels) == 0 {
+		channels = DefaultChannels()
+	}
+	for _, channel := range channels {
+		if channel.Branch == "" {
+			return nil, NewValidationError("branch", channel.Branch, "branch cannot be empty")
+		}
+		if _, err := path.Match(channel.Branch, ""); err != nil {
+			return nil, NewValidationError("branch", channel.Branch, err.Error())
+		}
+		if err := validateDistTag(channel.Tag); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ChannelManager{
+		client:   npmClient,
+		channels: channels,
+	}, nil
+}
+
+// Channels 返回配置的渠道
+func (m *ChannelManager) Channels() []Channel {
+	return m.channels
+}
+
+// ChannelFor 查找分支对应的渠道，按配置顺序取第一个匹配项
+func (m *ChannelManager) ChannelFor(branch string) (Channel, bool) {
+	for _, channel := range m.channels {
+		if matched, _ := path.Match(channel.Branch, branch); matched {
+			return channel, true
+		}
+	}
+	return Channel{}, false
+}
+
+// Plan 校验版本并计算需要的tag移动，已指向目标版本的tag不包含在结果中
+func (m *ChannelManager) Plan(ctx context.Context, releases []ChannelRelease, options ChannelOptions) ([]TagMove, error) {
+	current := make(map[string]map[string]string)
+	planned := make(map[string]string)
+	var moves []TagMove
+
+	for _, release := range releases {
+		channel, ok := m.ChannelFor(release.Branch)
+		if !ok {
+			return nil, NewValidationError("branch", release.Branch, "no release channel configured")
+		}
+		if release.Package == "" {
+			return nil, NewValidationError("package", release.Package, "package name cannot be empty")
+		}
+		version, err := semver.Parse(release.Version)
+		if err != nil {
+			return nil, NewValidationError("version", release.Version, err.Error())
+		}
+		if version.IsPrerelease() && !channel.Prerelease {
+			return nil, NewValidationError("version", release.Version, fmt.Sprintf("prerelease versions cannot be published to %s", channel.Tag))
+		}
+
+		key := release.Package + " " + channel.Tag
+		if other, ok := planned[key]; ok && other != release.Version {
+			return nil, fmt.Errorf("conflicting releases for %s@%s: %s and %s", release.Package, channel.Tag, other, release.Version)
+		}
+		planned[key] = release.Version
+
+		tags, ok := current[release.Package]
+		if !ok {
+			tags, err = m.client.DistTagList(ctx, release.Package, m.distTagOptions(options))
+			if err != nil {
+				return nil, err
+			}
+			current[release.Package] = tags
+		}
+
+		from := tags[channel.Tag]
+		if from == release.Version {
+			continue
+		}
+		if from != "" && !options.AllowDowngrade && semver.Compare(release.Version, from) < 0 {
+			return nil, NewValidationError("version", release.Version, fmt.Sprintf("%s@%s is already at %s", release.Package, channel.Tag, from))
+		}
+
+		moves = append(moves, TagMove{
+			Package: release.Package,
+			Branch:  release.Branch,
+			Tag:     channel.Tag,
+			From:    from,
+			To:      release.Version,
+		})
+	}
+	return moves, nil
+}
+
+// Apply 按Plan的结果移动tag并校验，任一步失败时按相反顺序恢复已完成的移动
+func (m *ChannelManager) Apply(ctx context.Context, releases []ChannelRelease, options ChannelOptions) (*ChannelResult, error) {
+	moves, err := m.Plan(ctx, releases, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ChannelResult{Moves: moves, DryRun: options.DryRun}
+	if options.DryRun {
+		return result, nil
+	}
+
+	distTagOptions := m.distTagOptions(options)
+	for i, move := range moves {
+		if err := m.client.DistTagAdd(ctx, move.Package+"@"+move.To, move.Tag, distTagOptions); err != nil {
+			return m.rollback(ctx, result, moves[:i], distTagOptions, err)
+		}
+	}
+
+	// 确认registry上的tag与预期一致
+	verified := make(map[string]map[string]string)
+	for _, move := range moves {
+		tags, ok := verified[move.Package]
+		if !ok {
+			tags, err = m.client.DistTagList(ctx, move.Package, distTagOptions)
+			if err != nil {
+				return m.rollback(ctx, result, moves, distTagOptions, err)
+			}
+			verified[move.Package] = tags
+		}
+		if tags[move.Tag] != move.To {
+			err := fmt.Errorf("%s@%s points to %s after update, expected %s", move.Package, move.Tag, tags[move.Tag], move.To)
+			return m.rollback(ctx, result, moves, distTagOptions, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Promote 把分支对应渠道的tag移到指定版本
+func (m *ChannelManager) Promote(ctx context.Context, branch, pkg, version string, options ChannelOptions) (*ChannelResult, error) {
+	return m.Apply(ctx, []ChannelRelease{{Branch: branch, Package: pkg, Version: version}}, options)
+}
+
+// rollback 恢复已完成的移动，返回的错误包含原始错误和回滚中的错误
+func (m *ChannelManager) rollback(ctx context.Context, result *ChannelResult, done []TagMove, options DistTagOptions, cause error) (*ChannelResult, error) {
+	errs := []error{cause}
+	for i := len(done) - 1; i >= 0; i-- {
+		move := done[i]
+		var err error
+		if move.From != "" {
+			err = m.client.DistTagAdd(ctx, move.Package+"@"+move.From, move.Tag, options)
+		} else {
+			err = m.client.DistTagRemove(ctx, move.Package, move.Tag, options)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to roll back %s@%s to %q: %w", move.Package, move.Tag, move.From, err))
+			continue
+		}
+		result.RolledBack = append(result.RolledBack, move)
+	}
+	return result, errors.Join(errs...)
+}
+
+// distTagOptions 转换为dist-tag命令选项
+func (m *ChannelManager) distTagOptions(options ChannelOptions) DistTagOptions {
+	return DistTagOptions{
+		Registry:   options.Registry,
+		OTP:        options.OTP,
+		Env:        options.Env,
+		UserConfig: options.UserConfig,
+	}
+}
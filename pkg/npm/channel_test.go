@@ -0,0 +1,199 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// distTagTestClient 在内存中维护dist-tag的客户端
+type distTagTestClient struct {
+	*MockClient
+	tags    map[string]map[string]string
+	calls   []string
+	failAdd string // 对该spec的DistTagAdd返回错误
+	ignore  string // 对该spec的DistTagAdd不生效
+}
+
+func newDistTagTestClient(tags map[string]map[string]string) *distTagTestClient {
+	return &distTagTestClient{MockClient: NewMockClient(), tags: tags}
+}
+
+func (c *distTagTestClient) DistTagList(ctx context.Context, pkg string, options DistTagOptions) (map[string]string, error) {
+	tags := make(map[string]string)
+	for tag, version := range c.tags[pkg] {
+		tags[tag] = version
+	}
+	return tags, nil
+}
+
+func (c *distTagTestClient) DistTagAdd(ctx context.Context, spec, tag string, options DistTagOptions) error {
+	c.calls = append(c.calls, "add "+spec+" "+tag)
+	if spec == c.failAdd {
+		return errors.New("registry error")
+	}
+	if spec == c.ignore {
+		return nil
+	}
+	at := strings.LastIndex(spec, "@")
+	if c.tags[spec[:at]] == nil {
+		c.tags[spec[:at]] = make(map[string]string)
+	}
+	c.tags[spec[:at]][tag] = spec[at+1:]
+	return nil
+}
+
+func (c *distTagTestClient) DistTagRemove(ctx context.Context, pkg, tag string, options DistTagOptions) error {
+	c.calls = append(c.calls, "rm "+pkg+" "+tag)
+	delete(c.tags[pkg], tag)
+	return nil
+}
+
+func TestChannelManagerChannelFor(t *testing.T) {
+	manager, err := NewChannelManager(NewMockClient(), []Channel{
+		{Branch: "main", Tag: "latest"},
+		{Branch: "maintenance", Tag: "v1-lts"},
+		{Branch: "release/*", Tag: "next", Prerelease: true},
+	})
+	if err != nil {
+		t.Fatalf("NewChannelManager() failed: %v", err)
+	}
+
+	if channel, ok := manager.ChannelFor("maintenance"); !ok || channel.Tag != "v1-lts" {
+		t.Errorf("ChannelFor(maintenance) = %+v, %v", channel, ok)
+	}
+	if channel, ok := manager.ChannelFor("release/2.0"); !ok || channel.Tag != "next" {
+		t.Errorf("ChannelFor(release/2.0) = %+v, %v", channel, ok)
+	}
+	if _, ok := manager.ChannelFor("feature/x"); ok {
+		t.Error("Expected no channel for feature branch")
+	}
+
+	defaults, _ := NewChannelManager(NewMockClient(), nil)
+	if channel, ok := defaults.ChannelFor("master"); !ok || channel.Tag != "latest" {
+		t.Errorf("Expected default channel for master, got %+v", channel)
+	}
+
+	for _, channels := range [][]Channel{{{Branch: "", Tag: "latest"}}, {{Branch: "main", Tag: "1.x"}}, {{Branch: "[", Tag: "latest"}}} {
+		if _, err := NewChannelManager(NewMockClient(), channels); !IsValidationError(err, nil) {
+			t.Errorf("Expected validation error for %+v, got %v", channels, err)
+		}
+	}
+}
+
+func TestChannelManagerApply(t *testing.T) {
+	npmClient := newDistTagTestClient(map[string]map[string]string{
+		"pkg": {"latest": "1.2.0", "next": "2.0.0-beta.1"},
+	})
+	manager, _ := NewChannelManager(npmClient, nil)
+	ctx := context.Background()
+
+	releases := []ChannelRelease{
+		{Branch: "main", Package: "pkg", Version: "1.3.0"},
+		{Branch: "next", Package: "pkg", Version: "2.0.0-beta.2"},
+		{Branch: "next", Package: "other", Version: "0.1.0"},
+	}
+
+	// dry-run不修改tag
+	result, err := manager.Apply(ctx, releases, ChannelOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Apply() dry-run failed: %v", err)
+	}
+	if !result.DryRun || len(result.Moves) != 3 || len(npmClient.calls) != 0 {
+		t.Errorf("Unexpected dry-run result: %+v, calls %v", result, npmClient.calls)
+	}
+	if result.Moves[0] != (TagMove{Package: "pkg", Branch: "main", Tag: "latest", From: "1.2.0", To: "1.3.0"}) {
+		t.Errorf("Unexpected move: %+v", result.Moves[0])
+	}
+	if result.Moves[2].From != "" {
+		t.Errorf("Expected new tag for other, got %+v", result.Moves[2])
+	}
+
+	result, err = manager.Apply(ctx, releases, ChannelOptions{})
+	if err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+	if npmClient.tags["pkg"]["latest"] != "1.3.0" || npmClient.tags["pkg"]["next"] != "2.0.0-beta.2" || npmClient.tags["other"]["next"] != "0.1.0" {
+		t.Errorf("Unexpected tags after apply: %v", npmClient.tags)
+	}
+
+	// 已指向目标版本时不移动
+	if result, err := manager.Promote(ctx, "main", "pkg", "1.3.0", ChannelOptions{}); err != nil || len(result.Moves) != 0 {
+		t.Errorf("Expected no moves, got %+v, %v", result, err)
+	}
+}
+
+func TestChannelManagerValidation(t *testing.T) {
+	npmClient := newDistTagTestClient(map[string]map[string]string{"pkg": {"latest": "1.2.0"}})
+	manager, _ := NewChannelManager(npmClient, nil)
+	ctx := context.Background()
+
+	invalid := [][]ChannelRelease{
+		{{Branch: "feature/x", Package: "pkg", Version: "1.3.0"}},
+		{{Branch: "main", Package: "pkg", Version: "1.3.0-rc.1"}},
+		{{Branch: "main", Package: "pkg", Version: "1.1.0"}},
+		{{Branch: "main", Package: "pkg", Version: "not-a-version"}},
+		{{Branch: "main", Package: "", Version: "1.3.0"}},
+	}
+	for _, releases := range invalid {
+		if _, err := manager.Apply(ctx, releases, ChannelOptions{}); !IsValidationError(err, nil) {
+			t.Errorf("Expected validation error for %+v, got %v", releases, err)
+		}
+	}
+
+	conflicting := []ChannelRelease{
+		{Branch: "main", Package: "pkg", Version: "1.3.0"},
+		{Branch: "master", Package: "pkg", Version: "1.4.0"},
+	}
+	if _, err := manager.Apply(ctx, conflicting, ChannelOptions{}); err == nil || !strings.Contains(err.Error(), "conflicting releases") {
+		t.Errorf("Expected conflict error, got %v", err)
+	}
+
+	if _, err := manager.Promote(ctx, "main", "pkg", "1.1.0", ChannelOptions{AllowDowngrade: true}); err != nil {
+		t.Errorf("Expected downgrade to be allowed, got %v", err)
+	}
+	if len(npmClient.calls) != 1 {
+		t.Errorf("Expected only the allowed downgrade to run, got %v", npmClient.calls)
+	}
+}
+
+func TestChannelManagerRollback(t *testing.T) {
+	npmClient := newDistTagTestClient(map[string]map[string]string{
+		"pkg": {"latest": "1.2.0"},
+	})
+	npmClient.failAdd = "pkg@2.0.0-beta.1"
+	manager, _ := NewChannelManager(npmClient, nil)
+
+	result, err := manager.Apply(context.Background(), []ChannelRelease{
+		{Branch: "main", Package: "pkg", Version: "1.3.0"},
+		{Branch: "next", Package: "pkg", Version: "2.0.0-beta.1"},
+	}, ChannelOptions{})
+	if err == nil || !strings.Contains(err.Error(), "registry error") {
+		t.Fatalf("Expected registry error, got %v", err)
+	}
+	if npmClient.tags["pkg"]["latest"] != "1.2.0" {
+		t.Errorf("Expected latest to be rolled back, got %v", npmClient.tags["pkg"])
+	}
+	if len(result.RolledBack) != 1 || result.RolledBack[0].Tag != "latest" {
+		t.Errorf("Unexpected rolled back moves: %+v", result.RolledBack)
+	}
+
+	// 校验失败时回滚所有移动，新建的tag被删除
+	npmClient = newDistTagTestClient(map[string]map[string]string{"pkg": {"latest": "1.2.0"}})
+	npmClient.ignore = "pkg@1.3.0"
+	manager, _ = NewChannelManager(npmClient, nil)
+	result, err = manager.Apply(context.Background(), []ChannelRelease{
+		{Branch: "next", Package: "pkg", Version: "2.0.0-beta.1"},
+		{Branch: "main", Package: "pkg", Version: "1.3.0"},
+	}, ChannelOptions{})
+	if err == nil || !strings.Contains(err.Error(), "points to 1.2.0 after update") {
+		t.Fatalf("Expected verification error, got %v", err)
+	}
+	if _, ok := npmClient.tags["pkg"]["next"]; ok || len(result.RolledBack) != 2 {
+		t.Errorf("Expected next to be removed, got %v, rolled back %+v", npmClient.tags["pkg"], result.RolledBack)
+	}
+	if npmClient.calls[len(npmClient.calls)-1] != "rm pkg next" {
+		t.Errorf("Expected rollback in reverse order, got %v", npmClient.calls)
+	}
+}
@@ -0,0 +1,156 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/platform"
+)
+
+// CleanOptions 清理选项
+type CleanOptions struct {
+	RemoveLockfile bool                  `json:"remove_lockfile,omitempty"` // 同时删除package-lock.json，重新解析所有依赖的版本
+	SkipInstall    bool                  `json:"skip_install,omitempty"`    // 只删除，不重新安装
+	Progress       platform.EventHandler `json:"-"`                         // 进度事件处理器
+}
+
+// Clean 删除node_modules后重新安装所有依赖，保留锁文件
+func (dm *DependencyManager) Clean(ctx context.Context) error {
+	return dm.CleanWithOptions(ctx, CleanOptions{})
+}
+
+// CleanWithOptions 按选项删除node_modules，默认删除后重新安装所有依赖
+//
+// 按node_modules中的顶层条目逐个删除并发送StageRemoving进度事件，删除过程中可以通过ctx取消。
+// node_modules是符号链接时只删除链接本身。npm-shrinkwrap.json属于发布内容，不会被删除。
+func (dm *DependencyManager) CleanWithOptions(ctx context.Context, options CleanOptions) error {
+	dir, err := filepath.Abs(dm.workingDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+
+	platform.EmitProgress(options.Progress, platform.ProgressEvent{
+		Stage:   platform.StageStarting,
+		Message: "Removing node_modules",
+	})
+	if err := removeNodeModules(ctx, filepath.Join(dir, "node_modules"), options.Progress); err != nil {
+		return err
+	}
+
+	if options.RemoveLockfile {
+		lockPath := longPath(filepath.Join(dir, "package-lock.json"), runtime.GOOS)
+		if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove package-lock.json: %w", err)
+		}
+	}
+
+	if !options.SkipInstall {
+		platform.EmitProgress(options.Progress, platform.ProgressEvent{
+			Stage:   platform.StageInstalling,
+			Percent: -1,
+			Message: "Reinstalling dependencies",
+		})
+		if err := dm.Install(ctx); err != nil {
+			return err
+		}
+	}
+
+	platform.EmitProgress(options.Progress, platform.ProgressEvent{
+		Stage:   platform.StageCompleted,
+		Percent: 100,
+		Message: "Clean completed",
+	})
+	return nil
+}
+
+// removeNodeModules 逐个删除node_modules的顶层条目，最后删除目录本身
+func removeNodeModules(ctx context.Context, nodeModules string, progress platform.EventHandler) error {
+	path := longPath(nodeModules, runtime.GOOS)
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat node_modules: %w", err)
+	}
+	if !info.IsDir() {
+		// 符号链接或文件，不进入目标目录
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove node_modules: %w", err)
+		}
+		return nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("failed to read node_modules: %w", err)
+	}
+	for i, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		platform.EmitProgress(progress, platform.ProgressEvent{
+			Stage:   platform.StageRemoving,
+			Percent: float64(i) / float64(len(entries)) * 100,
+			Message: fmt.Sprintf("Removing %s", entry.Name()),
+		})
+		if err := forceRemoveAll(filepath.Join(path, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", filepath.Join(nodeModules, entry.Name()), err)
+		}
+	}
+
+	if err := forceRemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove node_modules: %w", err)
+	}
+	platform.EmitProgress(progress, platform.ProgressEvent{
+		Stage:   platform.StageRemoving,
+		Percent: 100,
+		Message: fmt.Sprintf("Removed %d entries", len(entries)),
+	})
+	return nil
+}
+
+// forceRemoveAll 删除path，失败时加上写权限后重试
+//
+// Windows上只读文件无法删除，Unix上没有写权限的目录中的文件也无法删除，一些包会以这种权限发布文件。
+func forceRemoveAll(path string) error {
+	if err := os.RemoveAll(path); err == nil {
+		return nil
+	}
+
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		if d.IsDir() {
+			os.Chmod(p, 0755)
+		} else {
+			os.Chmod(p, 0644)
+		}
+		return nil
+	})
+	return os.RemoveAll(path)
+}
+
+// longPath 返回目标平台上不受路径长度限制的路径
+//
+// Windows上深层嵌套的node_modules经常超过260个字符，绝对路径加上\\?\前缀后可以访问，
+// UNC路径改为\\?\UNC\server\share形式。其他平台原样返回。
+func longPath(path, goos string) string {
+	if goos != "windows" || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	path = strings.ReplaceAll(path, "/", `\`)
+	if strings.HasPrefix(path, `\\`) {
+		return `\\?\UNC\` + path[2:]
+	}
+	if len(path) >= 3 && path[1] == ':' && path[2] == '\\' {
+		return `\\?\` + path
+	}
+	return path
+}
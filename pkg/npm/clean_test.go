@@ -0,0 +1,131 @@
+package npm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/platform"
+)
+
+func TestDependencyManagerClean(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name": "app"}`)
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), `{}`)
+	writeTestFile(t, filepath.Join(dir, "node_modules", "lodash", "package.json"), `{}`)
+	readOnly := filepath.Join(dir, "node_modules", "locked", "lib")
+	writeTestFile(t, filepath.Join(readOnly, "index.js"), "")
+	if err := os.Chmod(filepath.Join(readOnly, "index.js"), 0444); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(readOnly, 0555); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := &recordingExecutor{}
+	client, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	dm, err := NewDependencyManager(client, dir)
+	if err != nil {
+		t.Fatalf("NewDependencyManager() failed: %v", err)
+	}
+
+	var events []platform.ProgressEvent
+	err = dm.CleanWithOptions(context.Background(), CleanOptions{
+		RemoveLockfile: true,
+		Progress:       platform.EventHandlerFunc(func(event platform.ProgressEvent) { events = append(events, event) }),
+	})
+	if err != nil {
+		t.Fatalf("CleanWithOptions() failed: %v", err)
+	}
+
+	for _, name := range []string{"node_modules", "package-lock.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got %v", name, err)
+		}
+	}
+	if strings.Join(executor.options.Args, " ") != "install" || executor.options.WorkingDir != dir {
+		t.Errorf("Expected reinstall in %s, got %v in %s", dir, executor.options.Args, executor.options.WorkingDir)
+	}
+
+	var removing int
+	for _, event := range events {
+		if event.Stage == platform.StageRemoving {
+			removing++
+		}
+	}
+	if removing != 3 || events[len(events)-1].Stage != platform.StageCompleted {
+		t.Errorf("Unexpected progress events: %+v", events)
+	}
+}
+
+func TestDependencyManagerCleanSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := t.TempDir()
+	writeTestFile(t, filepath.Join(target, "lodash", "package.json"), `{}`)
+	if err := os.Symlink(target, filepath.Join(dir, "node_modules")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	dm, err := NewDependencyManager(&MockClient{}, dir)
+	if err != nil {
+		t.Fatalf("NewDependencyManager() failed: %v", err)
+	}
+	if err := dm.CleanWithOptions(context.Background(), CleanOptions{SkipInstall: true}); err != nil {
+		t.Fatalf("CleanWithOptions() failed: %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, "node_modules")); !os.IsNotExist(err) {
+		t.Errorf("Expected link to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "lodash", "package.json")); err != nil {
+		t.Errorf("Expected link target to be kept: %v", err)
+	}
+
+	// 没有node_modules时不报错
+	if err := dm.CleanWithOptions(context.Background(), CleanOptions{SkipInstall: true}); err != nil {
+		t.Errorf("CleanWithOptions() without node_modules failed: %v", err)
+	}
+}
+
+func TestDependencyManagerCleanCanceled(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "node_modules", "lodash", "package.json"), `{}`)
+
+	dm, err := NewDependencyManager(&MockClient{}, dir)
+	if err != nil {
+		t.Fatalf("NewDependencyManager() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := dm.Clean(ctx); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "node_modules", "lodash")); err != nil {
+		t.Errorf("Expected node_modules to be kept after cancel: %v", err)
+	}
+}
+
+func TestLongPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		goos     string
+		expected string
+	}{
+		{"/work/app/node_modules", "linux", "/work/app/node_modules"},
+		{`C:\work\app\node_modules`, "windows", `\\?\C:\work\app\node_modules`},
+		{`C:/work/app`, "windows", `\\?\C:\work\app`},
+		{`\\server\share\app`, "windows", `\\?\UNC\server\share\app`},
+		{`\\?\C:\work`, "windows", `\\?\C:\work`},
+		{`work\app`, "windows", `work\app`},
+	}
+	for _, tt := range tests {
+		if got := longPath(tt.path, tt.goos); got != tt.expected {
+			t.Errorf("longPath(%q, %q) = %q, expected %q", tt.path, tt.goos, got, tt.expected)
+		}
+	}
+}
@@ -1,59 +1,140 @@
 package npm
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/scagogogo/go-npm-sdk/pkg/lockfile"
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
 	"github.com/scagogogo/go-npm-sdk/pkg/utils"
 )
 
 // client npm客户端实现
 type client struct {
 	npmPath   string
-	executor  *utils.Executor
+	executor  utils.CommandExecutor
 	detector  *Detector
 	installer *Installer
+	events    *EventBus
+
+	retryPolicy *RetryPolicy
+
+	cache      Cache
+	registry   string     // 缓存键使用的registry，获取成功后复用
+	registryMu sync.Mutex // 保护registry
+
+	fallback     *registry.Client // 找不到npm时只读操作使用的registry客户端
+	auditScanner *OSVScanner      // npm audit失败时使用的扫描器，由WithOSVAudit设置
+
+	defaultEnv map[string]string // 每条命令默认使用的环境变量，由WithMirror设置
+
+	budgets     []*Budget             // WithBudget设置的预算
+	beforeHooks []BeforeOperationHook // WithBeforeOperation设置的钩子
+	afterHooks  []AfterOperationHook  // WithAfterOperation设置的钩子
+
+	experiments Experiments
+
+	resolution *NpmResolution // WithNpmResolver选择npm的结果
 }
 
 // NewClient 创建新的npm客户端
-func NewClient() (Client, error) {
+func NewClient(opts ...ClientOption) (Client, error) {
 	detector := NewDetector()
 	installer, err := NewInstaller()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create installer: %w", err)
 	}
 
-	return &client{
+	return newClient(&client{
 		npmPath:   "npm",
 		executor:  utils.NewExecutor(),
 		detector:  detector,
 		installer: installer,
-	}, nil
+		events:    NewEventBus(),
+	}, opts), nil
 }
 
 // NewClientWithPath 使用指定路径创建npm客户端
-func NewClientWithPath(npmPath string) (Client, error) {
+func NewClientWithPath(npmPath string, opts ...ClientOption) (Client, error) {
 	detector := NewDetector()
 	installer, err := NewInstaller()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create installer: %w", err)
 	}
 
-	return &client{
+	return newClient(&client{
 		npmPath:   npmPath,
 		executor:  utils.NewExecutor(),
 		detector:  detector,
 		installer: installer,
-	}, nil
+		events:    NewEventBus(),
+	}, opts), nil
+}
+
+// NewClientWithExecutor 创建使用指定命令执行器的npm客户端
+//
+// executor可以是utils.Recorder或utils.Replayer，用于录制真实的npm调用并在测试中回放。
+func NewClientWithExecutor(npmPath string, executor utils.CommandExecutor, opts ...ClientOption) (Client, error) {
+	if executor == nil {
+		return nil, NewValidationError("executor", "", "executor cannot be nil")
+	}
+	if npmPath == "" {
+		npmPath = "npm"
+	}
+	installer, err := NewInstaller()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installer: %w", err)
+	}
+
+	return newClient(&client{
+		npmPath:   npmPath,
+		executor:  executor,
+		detector:  NewDetector(),
+		installer: installer,
+		events:    NewEventBus(),
+	}, opts), nil
+}
+
+// newClient 设置默认的回退registry，应用客户端选项后包装执行器
+func newClient(c *client, opts []ClientOption) *client {
+	c.fallback = defaultFallback()
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.wrapExecutor()
+	return c
+}
+
+// wrapExecutor 按固定顺序包装执行器，与选项的顺序无关
+//
+// 由外到内依次是镜像的环境变量、钩子和预算：钩子收到的Env包含镜像的环境变量并且可以覆盖，
+// 被钩子拒绝的命令不计入预算，钩子的耗时也不计入MaxWallTime。
+func (c *client) wrapExecutor() {
+	for _, budget := range c.budgets {
+		c.executor = &budgetExecutor{executor: c.executor, budget: budget}
+	}
+	if len(c.beforeHooks) > 0 || len(c.afterHooks) > 0 {
+		c.executor = &hookExecutor{executor: c.executor, before: c.beforeHooks, after: c.afterHooks}
+	}
+	if c.defaultEnv != nil {
+		c.executor = &envExecutor{executor: c.executor, env: c.defaultEnv}
+	}
 }
 
 // IsAvailable 检查npm是否可用
 func (c *client) IsAvailable(ctx context.Context) bool {
-	result, err := c.executor.ExecuteSimple(ctx, c.npmPath, "--version")
-	return err == nil && result.Success
+	result, err := c.executor.Execute(ctx, c.versionOptions())
+	return err == nil && result != nil && result.Success
 }
 
 // Install 安装npm
@@ -82,8 +163,11 @@ func (c *client) Install(ctx context.Context) error {
 
 // Version 获取npm版本
 func (c *client) Version(ctx context.Context) (string, error) {
-	result, err := c.executor.ExecuteSimple(ctx, c.npmPath, "--version")
+	result, err := c.executor.Execute(ctx, c.versionOptions())
 	if err != nil {
+		if result == nil {
+			return "", NewNpmError("version", "", -1, "", "", err)
+		}
 		return "", NewNpmError("version", "", result.ExitCode, result.Stdout, result.Stderr, err)
 	}
 
@@ -94,60 +178,270 @@ func (c *client) Version(ctx context.Context) (string, error) {
 	return strings.TrimSpace(result.Stdout), nil
 }
 
+// versionOptions npm --version的执行选项
+func (c *client) versionOptions() utils.ExecuteOptions {
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"--version"},
+		CaptureOutput: true,
+	}
+}
+
 // Init 项目初始化
+//
+// npm init只通过init-*配置应用版本、作者和许可证，名称、描述和private没有对应的参数，
+// 所以npm init完成后把InitOptions中设置的字段直接写入package.json，保留npm生成的其他字段和格式。
 func (c *client) Init(ctx context.Context, options InitOptions) error {
+	executeOptions, err := c.initCommand(options)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return NewNpmError("init", "", -1, "", "", err)
+		}
+		return NewNpmError("init", "", result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	if !result.Success {
+		return NewNpmError("init", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm init failed"))
+	}
+
+	return applyInitOptions(options)
+}
+
+// initCommand 构造npm init的执行选项
+//
+// npm把--version当作打印自身版本的选项，版本、作者和许可证通过init-*配置传入。
+// 设置Initializer时运行npm init <initializer>，Args放在--之后原样传给初始化器。
+func (c *client) initCommand(options InitOptions) (utils.ExecuteOptions, error) {
+	if options.Initializer != "" {
+		return c.initializerCommand(options)
+	}
+	if len(options.Args) > 0 {
+		return utils.ExecuteOptions{}, NewValidationError("args", strings.Join(options.Args, " "), "args require an initializer")
+	}
+
 	args := []string{"init"}
 
 	// 构建参数
-	if options.Name != "" {
-		args = append(args, "--name", options.Name)
-	}
 	if options.Version != "" {
-		args = append(args, "--version", options.Version)
-	}
-	if options.Description != "" {
-		args = append(args, "--description", options.Description)
+		args = append(args, "--init-version", options.Version)
 	}
 	if options.Author != "" {
-		args = append(args, "--author", options.Author)
+		args = append(args, "--init-author-name", options.Author)
 	}
 	if options.License != "" {
-		args = append(args, "--license", options.License)
+		args = append(args, "--init-license", options.License)
 	}
-	if options.Private {
-		args = append(args, "--private")
+	if options.Force {
+		args = append(args, "--yes")
+	}
+
+	return utils.ExecuteOptions{
+		Command:        c.npmPath,
+		Args:           args,
+		Env:            commandEnv(options.Env, options.UserConfig),
+		WorkingDir:     options.WorkingDir,
+		CaptureOutput:  true,
+		StreamOutput:   options.OnOutput != nil,
+		OutputCallback: outputCallback(options.OnOutput),
+		Timeout:        2 * time.Minute,
+	}, nil
+}
+
+// initializerCommand 构造npm init <initializer>的执行选项
+//
+// package.json由初始化器生成，位置也由初始化器决定（通常是Args中的项目目录），
+// 所以不能同时设置名称、版本等包字段。Force时传入--yes，跳过安装初始化器的确认。
+func (c *client) initializerCommand(options InitOptions) (utils.ExecuteOptions, error) {
+	if strings.HasPrefix(options.Initializer, "-") || strings.TrimSpace(options.Initializer) != options.Initializer {
+		return utils.ExecuteOptions{}, NewValidationError("initializer", options.Initializer, "invalid initializer")
+	}
+	if options.Name != "" || options.Version != "" || options.Description != "" || options.Author != "" || options.License != "" || options.Private {
+		return utils.ExecuteOptions{}, NewValidationError("initializer", options.Initializer, "cannot be combined with package fields, the initializer creates package.json")
 	}
+
+	args := []string{"init", options.Initializer}
 	if options.Force {
 		args = append(args, "--yes")
 	}
+	if len(options.Args) > 0 {
+		args = append(args, "--")
+		args = append(args, options.Args...)
+	}
 
-	executeOptions := utils.ExecuteOptions{
-		Command:       c.npmPath,
-		Args:          args,
-		WorkingDir:    options.WorkingDir,
-		CaptureOutput: true,
-		Timeout:       2 * time.Minute,
+	return utils.ExecuteOptions{
+		Command:        c.npmPath,
+		Args:           args,
+		Env:            commandEnv(options.Env, options.UserConfig),
+		WorkingDir:     options.WorkingDir,
+		CaptureOutput:  true,
+		StreamOutput:   options.OnOutput != nil,
+		OutputCallback: outputCallback(options.OnOutput),
+		Timeout:        10 * time.Minute,
+	}, nil
+}
+
+// applyInitOptions 把InitOptions中设置的字段写入工作目录的package.json
+//
+// npm没有创建package.json时按npm init --yes的默认内容创建。
+func applyInitOptions(options InitOptions) error {
+	var fields [][2]string
+	for _, field := range [][2]string{
+		{"name", options.Name},
+		{"version", options.Version},
+		{"description", options.Description},
+		{"author", options.Author},
+		{"license", options.License},
+	} {
+		if field[1] != "" {
+			literal, err := encodeJSONString(field[1])
+			if err != nil {
+				return err
+			}
+			fields = append(fields, [2]string{field[0], literal})
+		}
+	}
+	if options.Private {
+		fields = append(fields, [2]string{"private", "true"})
+	}
+	if len(fields) == 0 {
+		return nil
 	}
 
-	result, err := c.executor.Execute(ctx, executeOptions)
+	path := filepath.Join(options.WorkingDir, "package.json")
+	original, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		original, err = defaultPackageJSON(options.WorkingDir)
+	}
 	if err != nil {
-		return NewNpmError("init", "", result.ExitCode, result.Stdout, result.Stderr, err)
+		return fmt.Errorf("failed to read package.json: %w", err)
 	}
 
-	if !result.Success {
-		return NewNpmError("init", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm init failed"))
+	content := string(original)
+	for _, field := range fields {
+		if content, err = setJSONMember(content, field[0], field[1]); err != nil {
+			return fmt.Errorf("failed to set %s in package.json: %w", field[0], err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write package.json: %w", err)
 	}
-
 	return nil
 }
 
+// defaultPackageJSON npm init --yes在dir中创建的package.json
+func defaultPackageJSON(dir string) ([]byte, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.ToLower(strings.Join(strings.Fields(filepath.Base(absDir)), "-"))
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(struct {
+		Name        string            `json:"name"`
+		Version     string            `json:"version"`
+		Description string            `json:"description"`
+		Main        string            `json:"main"`
+		Scripts     map[string]string `json:"scripts"`
+		Keywords    []string          `json:"keywords"`
+		Author      string            `json:"author"`
+		License     string            `json:"license"`
+	}{name, "1.0.0", "", "index.js", map[string]string{"test": `echo "Error: no test specified" && exit 1`}, []string{}, "", "ISC"})
+	return buf.Bytes(), err
+}
+
 // InstallPackage 安装包
 func (c *client) InstallPackage(ctx context.Context, pkg string, options InstallOptions) error {
 	if pkg == "" {
 		return NewValidationError("package", pkg, "package name cannot be empty")
 	}
 
-	args := []string{"install", pkg}
+	return c.install(ctx, []string{pkg}, options)
+}
+
+// InstallPackages 在一次npm install中安装多个包，pkgs为空时安装package.json中的所有依赖
+func (c *client) InstallPackages(ctx context.Context, pkgs []string, options InstallOptions) error {
+	for _, pkg := range pkgs {
+		if pkg == "" {
+			return NewValidationError("packages", strings.Join(pkgs, " "), "package name cannot be empty")
+		}
+	}
+
+	return c.install(ctx, pkgs, options)
+}
+
+// install 运行npm install
+func (c *client) install(ctx context.Context, pkgs []string, options InstallOptions) error {
+	executeOptions, err := c.installCommand(pkgs, options)
+	if err != nil {
+		return err
+	}
+
+	pkg := strings.Join(pkgs, " ")
+	return c.withRetry(ctx, "install", pkg, func() error {
+		result, err := c.executor.Execute(ctx, executeOptions)
+		if err != nil {
+			if result == nil {
+				return NewInstallError(pkg, "execution failed", NewNpmError("install", pkg, -1, "", "", err))
+			}
+			return NewInstallError(pkg, "execution failed", NewNpmError("install", pkg, result.ExitCode, result.Stdout, result.Stderr, err))
+		}
+
+		if !result.Success {
+			return NewInstallError(pkg, "npm install failed", NewNpmError("install", pkg, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("install failed")))
+		}
+
+		return nil
+	})
+}
+
+// installCommand 构造npm install的执行选项
+func (c *client) installCommand(pkgs []string, options InstallOptions) (utils.ExecuteOptions, error) {
+	args, err := installArgs(pkgs, options)
+	if err != nil {
+		return utils.ExecuteOptions{}, err
+	}
+
+	return utils.ExecuteOptions{
+		Command:        c.npmPath,
+		Args:           args,
+		Env:            commandEnv(options.Env, options.UserConfig),
+		WorkingDir:     options.WorkingDir,
+		CaptureOutput:  true,
+		StreamOutput:   options.OnOutput != nil,
+		OutputCallback: outputCallback(options.OnOutput),
+		Timeout:        10 * time.Minute,
+	}, nil
+}
+
+// installArgs 构建npm install的参数
+func installArgs(pkgs []string, options InstallOptions) ([]string, error) {
+	if options.LegacyPeerDeps && options.StrictPeerDeps {
+		return nil, NewValidationError("strict_peer_deps", "true", "cannot be combined with legacy_peer_deps")
+	}
+	if options.NoPackageLock && options.PackageLockOnly {
+		return nil, NewValidationError("package_lock_only", "true", "cannot be combined with no_package_lock")
+	}
+	for _, omit := range options.Omit {
+		if omit != "dev" && omit != "optional" && omit != "peer" {
+			return nil, NewValidationError("omit", omit, "must be one of dev, optional, peer")
+		}
+	}
+	// 以"-"开头的包名会被npm当作参数，例如--global或--prefix=/，绕过选项的限制
+	for _, pkg := range pkgs {
+		if strings.HasPrefix(pkg, "-") {
+			return nil, NewValidationError("packages", pkg, "package spec cannot start with \"-\"")
+		}
+	}
+
+	args := append([]string{"install"}, pkgs...)
 
 	// 构建参数
 	if options.SaveDev {
@@ -174,31 +468,57 @@ func (c *client) InstallPackage(ctx context.Context, pkg string, options Install
 	if options.IgnoreScripts {
 		args = append(args, "--ignore-scripts")
 	}
+	if options.LegacyPeerDeps {
+		args = append(args, "--legacy-peer-deps")
+	}
+	if options.StrictPeerDeps {
+		args = append(args, "--strict-peer-deps")
+	}
+	if options.NoPackageLock {
+		args = append(args, "--no-package-lock")
+	}
+	if options.PackageLockOnly {
+		args = append(args, "--package-lock-only")
+	}
+	if options.SaveBundle {
+		args = append(args, "--save-bundle")
+	}
+	if options.SavePeer {
+		args = append(args, "--save-peer")
+	}
+	for _, omit := range options.Omit {
+		args = append(args, "--omit", omit)
+	}
 
-	executeOptions := utils.ExecuteOptions{
-		Command:       c.npmPath,
-		Args:          args,
-		WorkingDir:    options.WorkingDir,
-		CaptureOutput: true,
-		Timeout:       10 * time.Minute,
+	return args, nil
+}
+
+// UninstallPackage 卸载包
+func (c *client) UninstallPackage(ctx context.Context, pkg string, options UninstallOptions) error {
+	executeOptions, err := c.uninstallCommand(pkg, options)
+	if err != nil {
+		return err
 	}
 
 	result, err := c.executor.Execute(ctx, executeOptions)
 	if err != nil {
-		return NewInstallError(pkg, "execution failed", NewNpmError("install", pkg, result.ExitCode, result.Stdout, result.Stderr, err))
+		if result == nil {
+			return NewUninstallError(pkg, "execution failed", NewNpmError("uninstall", pkg, -1, "", "", err))
+		}
+		return NewUninstallError(pkg, "execution failed", NewNpmError("uninstall", pkg, result.ExitCode, result.Stdout, result.Stderr, err))
 	}
 
 	if !result.Success {
-		return NewInstallError(pkg, "npm install failed", NewNpmError("install", pkg, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("install failed")))
+		return NewUninstallError(pkg, "npm uninstall failed", NewNpmError("uninstall", pkg, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("uninstall failed")))
 	}
 
 	return nil
 }
 
-// UninstallPackage 卸载包
-func (c *client) UninstallPackage(ctx context.Context, pkg string, options UninstallOptions) error {
+// uninstallCommand 构造npm uninstall的执行选项
+func (c *client) uninstallCommand(pkg string, options UninstallOptions) (utils.ExecuteOptions, error) {
 	if pkg == "" {
-		return NewValidationError("package", pkg, "package name cannot be empty")
+		return utils.ExecuteOptions{}, NewValidationError("package", pkg, "package name cannot be empty")
 	}
 
 	args := []string{"uninstall", pkg}
@@ -211,55 +531,339 @@ func (c *client) UninstallPackage(ctx context.Context, pkg string, options Unins
 		args = append(args, "--global")
 	}
 
-	executeOptions := utils.ExecuteOptions{
+	return utils.ExecuteOptions{
 		Command:       c.npmPath,
 		Args:          args,
+		Env:           commandEnv(options.Env, options.UserConfig),
 		WorkingDir:    options.WorkingDir,
 		CaptureOutput: true,
 		Timeout:       5 * time.Minute,
+	}, nil
+}
+
+// UpdatePackage 更新包
+func (c *client) UpdatePackage(ctx context.Context, pkg string) error {
+	return c.UpdatePackageWithOptions(ctx, pkg, UpdateOptions{})
+}
+
+// UpdatePackageWithOptions 按选项更新包
+func (c *client) UpdatePackageWithOptions(ctx context.Context, pkg string, options UpdateOptions) error {
+	if pkg == "" {
+		return NewValidationError("package", pkg, "package name cannot be empty")
+	}
+
+	_, err := c.update(ctx, pkg, c.updateCommand([]string{pkg}, options))
+	return err
+}
+
+// UpdateAll 更新所有依赖，返回对比更新前后锁文件得到的版本变化
+//
+// 锁文件按npm的优先级在WorkingDir中查找，全局更新或项目没有锁文件时Changes为空。
+func (c *client) UpdateAll(ctx context.Context, options UpdateOptions) (*UpdateResult, error) {
+	var before *lockfile.Lockfile
+	if !options.Global {
+		before = loadProjectLockfile(options.WorkingDir)
 	}
 
+	if _, err := c.update(ctx, "", c.updateCommand(nil, options)); err != nil {
+		return nil, err
+	}
+
+	result := &UpdateResult{}
+	if !options.Global {
+		result.Changes = diffLockfiles(before, loadProjectLockfile(options.WorkingDir))
+	}
+	return result, nil
+}
+
+// update 执行npm update
+func (c *client) update(ctx context.Context, pkg string, executeOptions utils.ExecuteOptions) (*utils.ExecuteResult, error) {
 	result, err := c.executor.Execute(ctx, executeOptions)
 	if err != nil {
-		return NewUninstallError(pkg, "execution failed", NewNpmError("uninstall", pkg, result.ExitCode, result.Stdout, result.Stderr, err))
+		if result == nil {
+			return nil, NewNpmError("update", pkg, -1, "", "", err)
+		}
+		return nil, NewNpmError("update", pkg, result.ExitCode, result.Stdout, result.Stderr, err)
 	}
 
 	if !result.Success {
-		return NewUninstallError(pkg, "npm uninstall failed", NewNpmError("uninstall", pkg, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("uninstall failed")))
+		return nil, NewNpmError("update", pkg, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm update failed"))
 	}
 
-	return nil
+	return result, nil
 }
 
-// UpdatePackage 更新包
-func (c *client) UpdatePackage(ctx context.Context, pkg string) error {
-	if pkg == "" {
-		return NewValidationError("package", pkg, "package name cannot be empty")
+// updateCommand 构造npm update的执行选项，pkgs为空时更新所有依赖
+func (c *client) updateCommand(pkgs []string, options UpdateOptions) utils.ExecuteOptions {
+	args := append([]string{"update"}, pkgs...)
+	if options.Global {
+		args = append(args, "--global")
+	}
+	if options.Save {
+		args = append(args, "--save")
+	}
+	if options.Registry != "" {
+		args = append(args, "--registry", options.Registry)
 	}
 
-	args := []string{"update", pkg}
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          args,
+		WorkingDir:    options.WorkingDir,
+		Env:           commandEnv(options.Env, options.UserConfig),
+		CaptureOutput: true,
+		Timeout:       10 * time.Minute,
+	}
+}
 
-	executeOptions := utils.ExecuteOptions{
+// loadProjectLockfile 读取项目的锁文件，不存在或无法解析时返回nil
+func loadProjectLockfile(dir string) *lockfile.Lockfile {
+	if dir == "" {
+		dir = "."
+	}
+	path, err := lockfile.Locate(dir)
+	if err != nil {
+		return nil
+	}
+	lock, err := lockfile.Load(path)
+	if err != nil {
+		return nil
+	}
+	return lock
+}
+
+// diffLockfiles 对比两个锁文件中每个包解析到的版本，nil按空锁文件处理
+func diffLockfiles(before, after *lockfile.Lockfile) []UpdateChange {
+	if before == nil && after == nil {
+		return nil
+	}
+	from := make(map[string][]string)
+	if before != nil {
+		from = before.ResolvedSet()
+	}
+	to := make(map[string][]string)
+	if after != nil {
+		to = after.ResolvedSet()
+	}
+
+	names := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []UpdateChange
+	for _, name := range names {
+		if !slices.Equal(from[name], to[name]) {
+			changes = append(changes, UpdateChange{Name: name, From: from[name], To: to[name]})
+		}
+	}
+	return changes
+}
+
+// Prune 移除node_modules中未在package.json声明的包
+func (c *client) Prune(ctx context.Context, options PruneOptions) error {
+	executeOptions := c.pruneCommand(options)
+
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return NewNpmError("prune", "", -1, "", "", err)
+		}
+		return NewNpmError("prune", "", result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	if !result.Success {
+		return NewNpmError("prune", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm prune failed"))
+	}
+
+	return nil
+}
+
+// pruneCommand 构造npm prune的执行选项
+func (c *client) pruneCommand(options PruneOptions) utils.ExecuteOptions {
+	args := []string{"prune"}
+
+	// 构建参数
+	if options.Production {
+		args = append(args, "--production")
+	}
+	if options.DryRun {
+		args = append(args, "--dry-run")
+	}
+
+	return utils.ExecuteOptions{
 		Command:       c.npmPath,
 		Args:          args,
+		Env:           commandEnv(options.Env, options.UserConfig),
+		WorkingDir:    options.WorkingDir,
+		CaptureOutput: true,
+		Timeout:       5 * time.Minute,
+	}
+}
+
+// Dedupe 合并node_modules中重复的包
+func (c *client) Dedupe(ctx context.Context, options DedupeOptions) error {
+	executeOptions := c.dedupeCommand(options)
+
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return NewNpmError("dedupe", "", -1, "", "", err)
+		}
+		return NewNpmError("dedupe", "", result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	if !result.Success {
+		return NewNpmError("dedupe", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm dedupe failed"))
+	}
+
+	return nil
+}
+
+// dedupeCommand 构造npm dedupe的执行选项
+func (c *client) dedupeCommand(options DedupeOptions) utils.ExecuteOptions {
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"dedupe"},
+		Env:           commandEnv(options.Env, options.UserConfig),
+		WorkingDir:    options.WorkingDir,
 		CaptureOutput: true,
 		Timeout:       10 * time.Minute,
 	}
+}
+
+// Shrinkwrap 将项目的package-lock.json转换为npm-shrinkwrap.json
+//
+// 没有package-lock.json时npm会先根据package.json生成依赖树。
+func (c *client) Shrinkwrap(ctx context.Context, workingDir string) error {
+	return c.ShrinkwrapWithOptions(ctx, ShrinkwrapOptions{WorkingDir: workingDir})
+}
+
+// ShrinkwrapWithOptions 按选项生成npm-shrinkwrap.json
+func (c *client) ShrinkwrapWithOptions(ctx context.Context, options ShrinkwrapOptions) error {
+	executeOptions := c.shrinkwrapCommand(options)
 
 	result, err := c.executor.Execute(ctx, executeOptions)
 	if err != nil {
-		return NewNpmError("update", pkg, result.ExitCode, result.Stdout, result.Stderr, err)
+		if result == nil {
+			return NewNpmError("shrinkwrap", "", -1, "", "", err)
+		}
+		return NewNpmError("shrinkwrap", "", result.ExitCode, result.Stdout, result.Stderr, err)
 	}
 
 	if !result.Success {
-		return NewNpmError("update", pkg, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm update failed"))
+		return NewNpmError("shrinkwrap", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm shrinkwrap failed"))
 	}
 
 	return nil
 }
 
+// shrinkwrapCommand 构造npm shrinkwrap的执行选项
+func (c *client) shrinkwrapCommand(options ShrinkwrapOptions) utils.ExecuteOptions {
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"shrinkwrap"},
+		Env:           commandEnv(options.Env, options.UserConfig),
+		WorkingDir:    options.WorkingDir,
+		CaptureOutput: true,
+		Timeout:       5 * time.Minute,
+	}
+}
+
+// ConfigList 列出workingDir中生效的npm配置，包括环境变量和各级.npmrc
+//
+// 键与npm config list --json一致：npm_config_*环境变量中的下划线变为连字符，.npmrc中的键保持原样。
+// 非字符串的值转换为字符串，未设置的值不包含在结果中。
+func (c *client) ConfigList(ctx context.Context, workingDir string) (map[string]string, error) {
+	return c.ConfigListWithOptions(ctx, ConfigListOptions{WorkingDir: workingDir})
+}
+
+// ConfigListWithOptions 按选项列出生效的npm配置，UserConfig替代用户级.npmrc
+func (c *client) ConfigListWithOptions(ctx context.Context, options ConfigListOptions) (map[string]string, error) {
+	result, err := c.executor.Execute(ctx, c.configListCommand(options))
+	if err != nil {
+		if result == nil {
+			return nil, NewNpmError("config", "", -1, "", "", err)
+		}
+		return nil, NewNpmError("config", "", result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	if !result.Success {
+		return nil, NewNpmError("config", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm config list failed"))
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Stdout), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse npm config: %w", err)
+	}
+	config := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			config[key] = v
+		default:
+			config[key] = fmt.Sprintf("%v", v)
+		}
+	}
+	return config, nil
+}
+
+// configListCommand 构造npm config list --json的执行选项
+func (c *client) configListCommand(options ConfigListOptions) utils.ExecuteOptions {
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"config", "list", "--json"},
+		Env:           commandEnv(options.Env, options.UserConfig),
+		WorkingDir:    options.WorkingDir,
+		CaptureOutput: true,
+		Timeout:       30 * time.Second,
+	}
+}
+
 // ListPackages 列出已安装的包
+//
+// JSON为true时返回完整的依赖树，Depth为-1时包含所有层级。依赖缺失或版本无效时
+// 同时返回已解析的依赖树和*ListProblemsError，调用方可以据此判断安装是否损坏。
 func (c *client) ListPackages(ctx context.Context, options ListOptions) ([]Package, error) {
+	executeOptions := c.listCommand(options)
+
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if result == nil {
+		return nil, NewNpmError("list", "", -1, "", "", err)
+	}
+
+	if err != nil || !result.Success {
+		if err == nil {
+			err = fmt.Errorf("npm list failed")
+		}
+		npmErr := NewNpmError("list", "", result.ExitCode, result.Stdout, result.Stderr, err)
+		// 依赖缺失或无效时npm list以ELSPROBLEMS结束，--json的输出仍然是完整的依赖树
+		if options.JSON {
+			if root, parseErr := ParseListTree([]byte(result.Stdout)); parseErr == nil && len(root.Problems) > 0 {
+				return root.Children, &ListProblemsError{Problems: root.Problems, Err: npmErr}
+			}
+		}
+		return nil, npmErr
+	}
+
+	// 解析JSON输出
+	if options.JSON {
+		return ParseListJSON([]byte(result.Stdout))
+	}
+
+	// 解析文本输出
+	return c.parseListText(result.Stdout)
+}
+
+// listCommand 构造npm list的执行选项
+func (c *client) listCommand(options ListOptions) utils.ExecuteOptions {
 	args := []string{"list"}
 
 	// 构建参数
@@ -268,6 +872,8 @@ func (c *client) ListPackages(ctx context.Context, options ListOptions) ([]Packa
 	}
 	if options.Depth > 0 {
 		args = append(args, "--depth", fmt.Sprintf("%d", options.Depth))
+	} else if options.Depth < 0 {
+		args = append(args, "--all")
 	}
 	if options.Production {
 		args = append(args, "--production")
@@ -276,54 +882,74 @@ func (c *client) ListPackages(ctx context.Context, options ListOptions) ([]Packa
 		args = append(args, "--json")
 	}
 
-	executeOptions := utils.ExecuteOptions{
+	return utils.ExecuteOptions{
 		Command:       c.npmPath,
 		Args:          args,
+		Env:           commandEnv(options.Env, options.UserConfig),
 		WorkingDir:    options.WorkingDir,
 		CaptureOutput: true,
 		Timeout:       2 * time.Minute,
 	}
+}
+
+// Outdated 运行npm outdated，返回按名称排序的过期依赖
+//
+// 找不到npm时根据package.json、node_modules和registry计算，结果只包含直接依赖。
+func (c *client) Outdated(ctx context.Context, options OutdatedOptions) ([]OutdatedPackage, error) {
+	executeOptions := c.outdatedCommand(options)
 
+	// 存在过期依赖时npm outdated以退出码1结束，输出仍然是完整的结果
 	result, err := c.executor.Execute(ctx, executeOptions)
-	if err != nil {
-		return nil, NewNpmError("list", "", result.ExitCode, result.Stdout, result.Stderr, err)
+	if result == nil {
+		return nil, NewNpmError("outdated", "", -1, "", "", err)
 	}
-
-	if !result.Success {
-		return nil, NewNpmError("list", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm list failed"))
+	if strings.TrimSpace(result.Stdout) == "" && err == nil {
+		return nil, nil
 	}
 
-	// 解析JSON输出
-	if options.JSON {
-		return c.parseListJSON(result.Stdout)
+	packages, parseErr := ParseOutdatedJSON([]byte(result.Stdout))
+	if parseErr != nil {
+		if err == nil {
+			err = parseErr
+		}
+		npmErr := NewNpmError("outdated", "", result.ExitCode, result.Stdout, result.Stderr, err)
+		if c.canFallback(npmErr) {
+			return c.fallbackOutdated(ctx, options)
+		}
+		return nil, npmErr
 	}
+	return packages, nil
+}
 
-	// 解析文本输出
-	return c.parseListText(result.Stdout)
+// outdatedCommand 构造npm outdated的执行选项
+func (c *client) outdatedCommand(options OutdatedOptions) utils.ExecuteOptions {
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"outdated", "--json"},
+		Env:           commandEnv(options.Env, options.UserConfig),
+		WorkingDir:    options.WorkingDir,
+		CaptureOutput: true,
+		Timeout:       2 * time.Minute,
+	}
 }
 
 // RunScript 运行脚本
 func (c *client) RunScript(ctx context.Context, script string, args ...string) error {
-	if script == "" {
-		return NewValidationError("script", script, "script name cannot be empty")
-	}
-
-	cmdArgs := []string{"run", script}
-	if len(args) > 0 {
-		cmdArgs = append(cmdArgs, "--")
-		cmdArgs = append(cmdArgs, args...)
-	}
+	return c.RunScriptWithOptions(ctx, script, RunScriptOptions{Args: args})
+}
 
-	executeOptions := utils.ExecuteOptions{
-		Command:       c.npmPath,
-		Args:          cmdArgs,
-		CaptureOutput: true,
-		StreamOutput:  true,
-		Timeout:       30 * time.Minute,
+// RunScriptWithOptions 按选项运行脚本
+func (c *client) RunScriptWithOptions(ctx context.Context, script string, options RunScriptOptions) error {
+	executeOptions, err := c.runScriptCommand(script, options)
+	if err != nil {
+		return err
 	}
 
 	result, err := c.executor.Execute(ctx, executeOptions)
 	if err != nil {
+		if result == nil {
+			return NewNpmError("run", script, -1, "", "", err)
+		}
 		return NewNpmError("run", script, result.ExitCode, result.Stdout, result.Stderr, err)
 	}
 
@@ -334,31 +960,57 @@ func (c *client) RunScript(ctx context.Context, script string, args ...string) e
 	return nil
 }
 
-// parseListJSON 解析JSON格式的list输出
-func (c *client) parseListJSON(output string) ([]Package, error) {
-	var data map[string]interface{}
-	if err := json.Unmarshal([]byte(output), &data); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON output: %w", err)
+// runScriptCommand 构造npm run的执行选项
+func (c *client) runScriptCommand(script string, options RunScriptOptions) (utils.ExecuteOptions, error) {
+	if script == "" {
+		return utils.ExecuteOptions{}, NewValidationError("script", script, "script name cannot be empty")
 	}
 
-	var packages []Package
+	cmdArgs := []string{"run", script}
+	if len(options.Args) > 0 {
+		cmdArgs = append(cmdArgs, "--")
+		cmdArgs = append(cmdArgs, options.Args...)
+	}
 
-	// 解析dependencies
-	if deps, ok := data["dependencies"].(map[string]interface{}); ok {
-		for name, info := range deps {
-			if pkgInfo, ok := info.(map[string]interface{}); ok {
-				pkg := Package{
-					Name: name,
-				}
-				if version, ok := pkgInfo["version"].(string); ok {
-					pkg.Version = version
-				}
-				packages = append(packages, pkg)
+	executeOptions := utils.ExecuteOptions{
+		Command:          c.npmPath,
+		Args:             cmdArgs,
+		Env:              commandEnv(options.Env, options.UserConfig),
+		WorkingDir:       options.WorkingDir,
+		CaptureOutput:    true,
+		StreamOutput:     true,
+		OutputCallback:   outputCallback(options.OnOutput),
+		Timeout:          options.Timeout,
+		KillProcessGroup: options.ProcessGroup,
+	}
+	if executeOptions.Timeout == 0 {
+		executeOptions.Timeout = 30 * time.Minute
+	}
+
+	return executeOptions, nil
+}
+
+// outputCallback 把执行器的输出回调转换为OutputLine，fn为nil时返回nil
+//
+// 执行器在两个goroutine中分别读取stdout和stderr，这里加锁保证fn不会被并发调用。
+func outputCallback(fn func(OutputLine)) func(string) {
+	if fn == nil {
+		return nil
+	}
+	var mu sync.Mutex
+	return func(raw string) {
+		line := OutputLine{Stream: "stdout", Time: time.Now(), Text: raw}
+		for _, stream := range []string{"stdout", "stderr"} {
+			if text, ok := strings.CutPrefix(raw, "["+stream+"] "); ok {
+				line.Stream, line.Text = stream, text
+				break
 			}
 		}
-	}
 
-	return packages, nil
+		mu.Lock()
+		defer mu.Unlock()
+		fn(line)
+	}
 }
 
 // parseListText 解析文本格式的list输出
@@ -394,9 +1046,123 @@ func (c *client) parseListText(output string) ([]Package, error) {
 	return packages, nil
 }
 
+// Events 返回客户端的事件总线
+func (c *client) Events() *EventBus {
+	return c.events
+}
+
+// SetLogger 设置日志记录器，同时用于命令执行器和安装器，nil表示不记录
+func (c *client) SetLogger(logger *slog.Logger) {
+	if executor, ok := c.executor.(interface{ SetLogger(*slog.Logger) }); ok {
+		executor.SetLogger(logger)
+	}
+	if c.installer != nil {
+		c.installer.SetLogger(logger)
+	}
+}
+
 // Publish 发布包
+//
+// 先将项目打包到临时目录，再发布生成的tarball，过程中在事件总线上发送PublishEvent。
+// npm发布tarball时不运行生命周期脚本，因此prepublishOnly、publish和postpublish
+// 脚本由这里按npm发布目录时的顺序运行。
+// options中没有设置的registry、access和tag取自package.json的publishConfig，见MergePublishConfig。
+// 启用Experiments.EnableDirectRegistryPublish时改为由RegistryPublisher直接上传。
 func (c *client) Publish(ctx context.Context, options PublishOptions) error {
-	args := []string{"publish"}
+	if c.experiments.EnableDirectRegistryPublish {
+		if c.fallback == nil {
+			return NewValidationError("registry", "", "direct registry publish requires a registry client")
+		}
+		_, err := NewRegistryPublisher(c, c.fallback).Publish(ctx, options)
+		return err
+	}
+
+	event := PublishEvent{DryRun: options.DryRun}
+	emit := func(stage PublishStage) {
+		event.Stage = stage
+		event.Time = time.Now()
+		c.events.Emit(event)
+	}
+	fail := func(err error) error {
+		event.Err = err
+		emit(PublishStageFailed)
+		return err
+	}
+
+	// package.json无法读取时由npm pack报告错误
+	pkg := NewPackageJSON(filepath.Join(options.WorkingDir, "package.json"))
+	if err := pkg.Load(); err != nil {
+		pkg = NewPackageJSON("")
+	}
+	options, event.Conflicts = MergePublishConfig(pkg, options)
+	event.Registry = options.Registry
+
+	emit(PublishStagePacking)
+
+	env := commandEnv(options.Env, options.UserConfig)
+	if pkg.HasScript("prepublishOnly") {
+		if err := c.runLifecycleScript(ctx, options.WorkingDir, "prepublishOnly", env); err != nil {
+			return fail(err)
+		}
+	}
+
+	tempDir, err := os.MkdirTemp("", "npm-publish-")
+	if err != nil {
+		return fail(fmt.Errorf("failed to create temp directory: %w", err))
+	}
+	defer os.RemoveAll(tempDir)
+
+	packed, err := c.Pack(ctx, PackOptions{
+		WorkingDir:      options.WorkingDir,
+		PackDestination: tempDir,
+		Normalize:       true,
+		Env:             options.Env,
+		UserConfig:      options.UserConfig,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	event.Package = packed.Name
+	event.Version = packed.Version
+	event.Tarball = packed.Filename
+	event.BytesTotal = packed.Size
+
+	executeOptions := c.publishCommand(packed.Path, options)
+	if scope := packageScope(packed.Name); scope != "" && options.Registry != "" {
+		// .npmrc中的@scope:registry优先于--registry，同时指定才能发布到确定的registry
+		executeOptions.Args = append(executeOptions.Args, "--"+scope+":registry="+options.Registry)
+	}
+
+	// npm CLI不报告上传进度，上传开始和完成时各发送一次事件
+	emit(PublishStageUploading)
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return fail(NewNpmError("publish", packed.Name, -1, "", "", err))
+		}
+		return fail(NewNpmError("publish", packed.Name, result.ExitCode, result.Stdout, result.Stderr, err))
+	}
+
+	if !result.Success {
+		return fail(NewNpmError("publish", packed.Name, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm publish failed")))
+	}
+	event.BytesSent = packed.Size
+
+	for _, script := range []string{"publish", "postpublish"} {
+		if pkg.HasScript(script) {
+			if err := c.runLifecycleScript(ctx, options.WorkingDir, script, env); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	emit(PublishStageDone)
+	return nil
+}
+
+// publishCommand 构造发布tarball的npm publish执行选项
+func (c *client) publishCommand(tarball string, options PublishOptions) utils.ExecuteOptions {
+	args := []string{"publish", tarball}
 
 	// 构建参数
 	if options.Tag != "" {
@@ -411,49 +1177,200 @@ func (c *client) Publish(ctx context.Context, options PublishOptions) error {
 	if options.DryRun {
 		args = append(args, "--dry-run")
 	}
+	if options.OTP != "" {
+		args = append(args, "--otp", options.OTP)
+	}
+	if options.ProvenanceFile != "" {
+		args = append(args, "--provenance-file", options.ProvenanceFile)
+	}
 
-	executeOptions := utils.ExecuteOptions{
+	return utils.ExecuteOptions{
 		Command:       c.npmPath,
 		Args:          args,
+		Env:           commandEnv(options.Env, options.UserConfig),
 		WorkingDir:    options.WorkingDir,
 		CaptureOutput: true,
 		Timeout:       10 * time.Minute,
 	}
+}
+
+// runLifecycleScript 在项目目录中运行生命周期脚本
+func (c *client) runLifecycleScript(ctx context.Context, workingDir, script string, env map[string]string) error {
+	executeOptions := utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"run", script},
+		Env:           env,
+		WorkingDir:    workingDir,
+		CaptureOutput: true,
+		Timeout:       30 * time.Minute,
+	}
 
 	result, err := c.executor.Execute(ctx, executeOptions)
 	if err != nil {
-		return NewNpmError("publish", "", result.ExitCode, result.Stdout, result.Stderr, err)
+		if result == nil {
+			return NewNpmError("run", script, -1, "", "", err)
+		}
+		return NewNpmError("run", script, result.ExitCode, result.Stdout, result.Stderr, err)
 	}
-
 	if !result.Success {
-		return NewNpmError("publish", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm publish failed"))
+		return NewNpmError("run", script, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("script %s failed", script))
 	}
-
 	return nil
 }
 
-// GetPackageInfo 获取包信息
-func (c *client) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
-	if pkg == "" {
-		return nil, NewValidationError("package", pkg, "package name cannot be empty")
+// commandEnv 合并调用选项中的环境变量，userConfig通过npm_config_userconfig指定替代的.npmrc
+func commandEnv(env map[string]string, userConfig string) map[string]string {
+	if userConfig == "" {
+		return env
 	}
 
-	args := []string{"view", pkg, "--json"}
+	merged := make(map[string]string, len(env)+1)
+	for key, value := range env {
+		merged[key] = value
+	}
+	merged["npm_config_userconfig"] = userConfig
+	return merged
+}
 
-	executeOptions := utils.ExecuteOptions{
-		Command:       c.npmPath,
-		Args:          args,
-		CaptureOutput: true,
-		Timeout:       30 * time.Second,
+// Unpublish 撤销发布，spec为name@version；撤销整个包需要设置Force
+func (c *client) Unpublish(ctx context.Context, spec string, options UnpublishOptions) error {
+	executeOptions, err := c.unpublishCommand(spec, options)
+	if err != nil {
+		return err
+	}
+	_, err = c.runRegistryCommand(ctx, "unpublish", spec, executeOptions)
+	return err
+}
+
+// unpublishCommand 构造npm unpublish的执行选项
+func (c *client) unpublishCommand(spec string, options UnpublishOptions) (utils.ExecuteOptions, error) {
+	if spec == "" {
+		return utils.ExecuteOptions{}, NewValidationError("spec", spec, "package spec cannot be empty")
+	}
+	if !strings.Contains(spec[1:], "@") && !options.Force {
+		return utils.ExecuteOptions{}, NewValidationError("spec", spec, "version is required unless force is set")
 	}
 
+	args := []string{"unpublish", spec}
+	if options.Force {
+		args = append(args, "--force")
+	}
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), args...), nil
+}
+
+// Pack 打包
+func (c *client) Pack(ctx context.Context, options PackOptions) (*PackResult, error) {
+	executeOptions := c.packCommand(options)
+
 	result, err := c.executor.Execute(ctx, executeOptions)
 	if err != nil {
-		return nil, NewNpmError("view", pkg, result.ExitCode, result.Stdout, result.Stderr, err)
+		if result == nil {
+			return nil, NewNpmError("pack", options.Spec, -1, "", "", err)
+		}
+		return nil, NewNpmError("pack", options.Spec, result.ExitCode, result.Stdout, result.Stderr, err)
 	}
 
 	if !result.Success {
-		return nil, NewNpmError("view", pkg, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm view failed"))
+		return nil, NewNpmError("pack", options.Spec, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm pack failed"))
+	}
+
+	packResult, err := parsePackJSON(result.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.DryRun {
+		packResult.Path = resolvePackPath(options, packResult.Filename)
+		if err := finishPackedTarball(packResult, options.Normalize); err != nil {
+			return nil, err
+		}
+	}
+
+	return packResult, nil
+}
+
+// packCommand 构造npm pack的执行选项
+func (c *client) packCommand(options PackOptions) utils.ExecuteOptions {
+	args := []string{"pack", "--json"}
+
+	// 构建参数
+	if options.Spec != "" {
+		args = append(args, options.Spec)
+	}
+	if options.PackDestination != "" {
+		args = append(args, "--pack-destination", options.PackDestination)
+	}
+	if options.DryRun {
+		args = append(args, "--dry-run")
+	}
+
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          args,
+		Env:           commandEnv(options.Env, options.UserConfig),
+		WorkingDir:    options.WorkingDir,
+		CaptureOutput: true,
+		Timeout:       5 * time.Minute,
+	}
+}
+
+// parsePackJSON 解析npm pack --json的输出
+func parsePackJSON(output string) (*PackResult, error) {
+	// 生命周期脚本的输出可能出现在JSON之前
+	start := strings.Index(output, "[")
+	if start < 0 {
+		return nil, fmt.Errorf("failed to parse pack output: no JSON array found")
+	}
+
+	var results []PackResult
+	if err := json.Unmarshal([]byte(output[start:]), &results); err != nil {
+		return nil, fmt.Errorf("failed to parse pack output: %w", err)
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("failed to parse pack output: empty result")
+	}
+
+	return &results[0], nil
+}
+
+// resolvePackPath 计算tarball的完整路径
+func resolvePackPath(options PackOptions, filename string) string {
+	dir := options.PackDestination
+	if dir == "" {
+		dir = options.WorkingDir
+	} else if !filepath.IsAbs(dir) && options.WorkingDir != "" {
+		dir = filepath.Join(options.WorkingDir, dir)
+	}
+
+	path := filepath.Join(dir, filename)
+	if absPath, err := filepath.Abs(path); err == nil {
+		return absPath
+	}
+	return path
+}
+
+// GetPackageInfo 获取包信息
+func (c *client) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
+	if pkg == "" {
+		return nil, NewValidationError("package", pkg, "package name cannot be empty")
+	}
+
+	return cached(ctx, c, "view", pkg, func() (*PackageInfo, error) {
+		return c.getPackageInfo(ctx, pkg)
+	})
+}
+
+// getPackageInfo 运行npm view获取包信息
+func (c *client) getPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
+	executeOptions := c.viewCommand(pkg)
+
+	result, err := c.executeWithRetry(ctx, "view", pkg, executeOptions)
+	if err != nil {
+		if c.canFallback(err) {
+			return registryPackageInfo(ctx, c.fallback, pkg)
+		}
+		return nil, err
 	}
 
 	var info PackageInfo
@@ -464,34 +1381,52 @@ func (c *client) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo,
 	return &info, nil
 }
 
+// viewCommand 构造npm view的执行选项
+func (c *client) viewCommand(pkg string) utils.ExecuteOptions {
+	args := []string{"view", pkg, "--json"}
+
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          args,
+		CaptureOutput: true,
+		Timeout:       30 * time.Second,
+	}
+}
+
 // Search 搜索包
 func (c *client) Search(ctx context.Context, query string) ([]SearchResult, error) {
 	if query == "" {
 		return nil, NewValidationError("query", query, "search query cannot be empty")
 	}
 
-	args := []string{"search", query, "--json"}
+	return cached(ctx, c, "search", query, func() ([]SearchResult, error) {
+		return c.search(ctx, query)
+	})
+}
 
-	executeOptions := utils.ExecuteOptions{
-		Command:       c.npmPath,
-		Args:          args,
-		CaptureOutput: true,
-		Timeout:       30 * time.Second,
-	}
+// search 运行npm search
+func (c *client) search(ctx context.Context, query string) ([]SearchResult, error) {
+	executeOptions := c.searchCommand(query)
 
-	result, err := c.executor.Execute(ctx, executeOptions)
+	result, err := c.executeWithRetry(ctx, "search", query, executeOptions)
 	if err != nil {
-		return nil, NewNpmError("search", query, result.ExitCode, result.Stdout, result.Stderr, err)
+		if c.canFallback(err) {
+			return registrySearch(ctx, c.fallback, query)
+		}
+		return nil, err
 	}
 
-	if !result.Success {
-		return nil, NewNpmError("search", query, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm search failed"))
-	}
+	return ParseSearchJSON([]byte(result.Stdout))
+}
 
-	var results []SearchResult
-	if err := json.Unmarshal([]byte(result.Stdout), &results); err != nil {
-		return nil, fmt.Errorf("failed to parse search results: %w", err)
-	}
+// searchCommand 构造npm search的执行选项
+func (c *client) searchCommand(query string) utils.ExecuteOptions {
+	args := []string{"search", query, "--json"}
 
-	return results, nil
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          args,
+		CaptureOutput: true,
+		Timeout:       30 * time.Second,
+	}
 }
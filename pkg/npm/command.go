@@ -0,0 +1,264 @@
+package npm
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// Command 客户端为一次操作构造的npm命令
+type Command = npmiface.Command
+
+// commandBuilder 一个操作的命令构造方式
+type commandBuilder struct {
+	args  int // 位置参数个数，-1表示任意个
+	build func(c *client, options interface{}, args []string) (utils.ExecuteOptions, error)
+}
+
+// withOptions 构造使用选项结构体T的操作，options为nil时使用T的零值
+func withOptions[T any](args int, build func(c *client, options T, args []string) (utils.ExecuteOptions, error)) commandBuilder {
+	return commandBuilder{args: args, build: func(c *client, options interface{}, args []string) (utils.ExecuteOptions, error) {
+		var typed T
+		switch o := options.(type) {
+		case nil:
+		case T:
+			typed = o
+		case *T:
+			if o != nil {
+				typed = *o
+			}
+		default:
+			return utils.ExecuteOptions{}, NewValidationError("options", fmt.Sprintf("%T", options), fmt.Sprintf("expected %T", typed))
+		}
+		return build(c, typed, args)
+	}}
+}
+
+// withoutOptions 构造没有选项的操作，options必须为nil
+func withoutOptions(args int, build func(c *client, args []string) (utils.ExecuteOptions, error)) commandBuilder {
+	return commandBuilder{args: args, build: func(c *client, options interface{}, args []string) (utils.ExecuteOptions, error) {
+		if options != nil {
+			return utils.ExecuteOptions{}, NewValidationError("options", fmt.Sprintf("%T", options), "operation takes no options")
+		}
+		return build(c, args)
+	}}
+}
+
+// commandBuilders 按Client方法名索引的命令构造方式，与各方法实际执行的命令共用同一份构造代码
+var commandBuilders = map[string]commandBuilder{
+	"Version": withoutOptions(0, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		return c.versionOptions(), nil
+	}),
+	"Init": withOptions(0, func(c *client, options InitOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.initCommand(options)
+	}),
+	"InstallPackage": withOptions(1, func(c *client, options InstallOptions, args []string) (utils.ExecuteOptions, error) {
+		if args[0] == "" {
+			return utils.ExecuteOptions{}, NewValidationError("package", args[0], "package name cannot be empty")
+		}
+		return c.installCommand(args, options)
+	}),
+	"InstallPackages": withOptions(-1, func(c *client, options InstallOptions, args []string) (utils.ExecuteOptions, error) {
+		for _, pkg := range args {
+			if pkg == "" {
+				return utils.ExecuteOptions{}, NewValidationError("packages", strings.Join(args, " "), "package name cannot be empty")
+			}
+		}
+		return c.installCommand(args, options)
+	}),
+	"UninstallPackage": withOptions(1, func(c *client, options UninstallOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.uninstallCommand(args[0], options)
+	}),
+	"UpdatePackage": withoutOptions(1, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		if args[0] == "" {
+			return utils.ExecuteOptions{}, NewValidationError("package", args[0], "package name cannot be empty")
+		}
+		return c.updateCommand(args, UpdateOptions{}), nil
+	}),
+	"UpdatePackageWithOptions": withOptions(1, func(c *client, options UpdateOptions, args []string) (utils.ExecuteOptions, error) {
+		if args[0] == "" {
+			return utils.ExecuteOptions{}, NewValidationError("package", args[0], "package name cannot be empty")
+		}
+		return c.updateCommand(args, options), nil
+	}),
+	"UpdateAll": withOptions(0, func(c *client, options UpdateOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.updateCommand(nil, options), nil
+	}),
+	"ListPackages": withOptions(0, func(c *client, options ListOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.listCommand(options), nil
+	}),
+	"ListGlobal": withoutOptions(0, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		return c.listCommand(ListOptions{Global: true, JSON: true}), nil
+	}),
+	"InstallGlobal": withOptions(1, func(c *client, options InstallOptions, args []string) (utils.ExecuteOptions, error) {
+		if args[0] == "" {
+			return utils.ExecuteOptions{}, NewValidationError("package", args[0], "package name cannot be empty")
+		}
+		options.Global = true
+		return c.installCommand(args, options)
+	}),
+	"UninstallGlobal": withOptions(1, func(c *client, options UninstallOptions, args []string) (utils.ExecuteOptions, error) {
+		options.Global = true
+		return c.uninstallCommand(args[0], options)
+	}),
+	"GlobalRoot": withoutOptions(0, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		return c.globalPrefixCommand(), nil
+	}),
+	"Outdated": withOptions(0, func(c *client, options OutdatedOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.outdatedCommand(options), nil
+	}),
+	"Prune": withOptions(0, func(c *client, options PruneOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.pruneCommand(options), nil
+	}),
+	"Dedupe": withOptions(0, func(c *client, options DedupeOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.dedupeCommand(options), nil
+	}),
+	"Shrinkwrap": withoutOptions(1, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		return c.shrinkwrapCommand(ShrinkwrapOptions{WorkingDir: args[0]}), nil
+	}),
+	"ShrinkwrapWithOptions": withOptions(0, func(c *client, options ShrinkwrapOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.shrinkwrapCommand(options), nil
+	}),
+	"ConfigList": withoutOptions(1, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		return c.configListCommand(ConfigListOptions{WorkingDir: args[0]}), nil
+	}),
+	"ConfigListWithOptions": withOptions(0, func(c *client, options ConfigListOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.configListCommand(options), nil
+	}),
+	"Fund": withOptions(0, func(c *client, options FundOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.fundCommand(options), nil
+	}),
+	"Audit": withOptions(0, func(c *client, options AuditOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.auditCommand(options), nil
+	}),
+	"Explain": withoutOptions(1, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		return c.explainCommand(args[0])
+	}),
+	"Query": withoutOptions(1, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		return c.queryCommand(args[0])
+	}),
+	"RunScript": withoutOptions(-1, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		if len(args) == 0 {
+			return utils.ExecuteOptions{}, NewValidationError("script", "", "script name cannot be empty")
+		}
+		return c.runScriptCommand(args[0], RunScriptOptions{Args: args[1:]})
+	}),
+	"RunScriptWithOptions": withOptions(1, func(c *client, options RunScriptOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.runScriptCommand(args[0], options)
+	}),
+	"Publish": withOptions(1, func(c *client, options PublishOptions, args []string) (utils.ExecuteOptions, error) {
+		if c.experiments.EnableDirectRegistryPublish {
+			return utils.ExecuteOptions{}, NewValidationError("op", "Publish", "direct registry publish does not run npm")
+		}
+		if args[0] == "" {
+			return utils.ExecuteOptions{}, NewValidationError("tarball", args[0], "tarball path cannot be empty")
+		}
+		return c.publishCommand(args[0], options), nil
+	}),
+	"Unpublish": withOptions(1, func(c *client, options UnpublishOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.unpublishCommand(args[0], options)
+	}),
+	"Pack": withOptions(0, func(c *client, options PackOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.packCommand(options), nil
+	}),
+	"GetPackageInfo": withoutOptions(1, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		if args[0] == "" {
+			return utils.ExecuteOptions{}, NewValidationError("package", args[0], "package name cannot be empty")
+		}
+		return c.viewCommand(args[0]), nil
+	}),
+	"Search": withoutOptions(1, func(c *client, args []string) (utils.ExecuteOptions, error) {
+		if args[0] == "" {
+			return utils.ExecuteOptions{}, NewValidationError("query", args[0], "search query cannot be empty")
+		}
+		return c.searchCommand(args[0]), nil
+	}),
+	"DistTagList": withOptions(1, func(c *client, options DistTagOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.distTagListCommand(args[0], options)
+	}),
+	"DistTagAdd": withOptions(2, func(c *client, options DistTagOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.distTagAddCommand(args[0], args[1], options)
+	}),
+	"DistTagRemove": withOptions(2, func(c *client, options DistTagOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.distTagRemoveCommand(args[0], args[1], options)
+	}),
+	"AddOwner": withOptions(2, func(c *client, options OwnerOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.addOwnerCommand(args[0], args[1], options)
+	}),
+	"RemoveOwner": withOptions(2, func(c *client, options OwnerOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.removeOwnerCommand(args[0], args[1], options)
+	}),
+	"ListOwners": withOptions(1, func(c *client, options OwnerOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.listOwnersCommand(args[0], options)
+	}),
+	"GetAccess": withOptions(1, func(c *client, options AccessOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.getAccessCommand(args[0], options)
+	}),
+	"SetAccess": withOptions(2, func(c *client, options AccessOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.setAccessCommand(args[0], AccessLevel(args[1]), options)
+	}),
+	"GrantAccess": withOptions(3, func(c *client, options AccessOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.grantAccessCommand(args[0], args[1], Permission(args[2]), options)
+	}),
+	"RevokeAccess": withOptions(2, func(c *client, options AccessOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.revokeAccessCommand(args[0], args[1], options)
+	}),
+	"ListCollaborators": withOptions(1, func(c *client, options AccessOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.listCollaboratorsCommand(args[0], options)
+	}),
+	"TokenCreate": withOptions(0, func(c *client, options TokenCreateOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.tokenCreateCommand(options), nil
+	}),
+	"TokenList": withOptions(0, func(c *client, options TokenOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.tokenListCommand(options), nil
+	}),
+	"TokenRevoke": withOptions(1, func(c *client, options TokenOptions, args []string) (utils.ExecuteOptions, error) {
+		return c.tokenRevokeCommand(args[0], options)
+	}),
+}
+
+// CommandOperations 返回BuildCommand支持的操作，按名称排序
+//
+// Install、Doctor、Ping等不对应单条npm命令的方法不在其中。
+func CommandOperations() []string {
+	ops := make([]string, 0, len(commandBuilders))
+	for op := range commandBuilders {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// BuildCommand 返回op将要运行的npm命令而不执行，供安全审查确认SDK实际运行的内容
+//
+// op为Client的方法名，options为该方法的选项结构体（值或指针，nil表示零值），
+// args依次为方法除ctx和选项以外的参数，例如BuildCommand("DistTagAdd", opts, "pkg@1.0.0", "beta")。
+// RunScript的args为脚本名及脚本参数，InstallPackages的args为包名列表。
+// Publish先打包再发布tarball，args为tarball路径，返回的是发布这一步的命令。
+// 参数校验与实际调用相同；GetPackageInfo、Search等方法的缓存和registry回退不影响返回的命令。
+func (c *client) BuildCommand(op string, options interface{}, args ...string) (*Command, error) {
+	builder, ok := commandBuilders[op]
+	if !ok {
+		return nil, NewValidationError("op", op, "unsupported operation")
+	}
+	if builder.args >= 0 && len(args) != builder.args {
+		return nil, NewValidationError("args", strings.Join(args, " "), fmt.Sprintf("%s expects %d arguments", op, builder.args))
+	}
+
+	executeOptions, err := builder.build(c, options, append([]string(nil), args...))
+	if err != nil {
+		return nil, err
+	}
+	return &Command{
+		Path:       executeOptions.Command,
+		Args:       append([]string(nil), executeOptions.Args...),
+		Env:        maps.Clone(mergeEnv(c.defaultEnv, executeOptions.Env)),
+		WorkingDir: executeOptions.WorkingDir,
+		Input:      executeOptions.Input,
+		Timeout:    executeOptions.Timeout,
+	}, nil
+}
@@ -0,0 +1,167 @@
+package npm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// commandCase BuildCommand的golden用例
+type commandCase struct {
+	name    string
+	op      string
+	options interface{}
+	args    []string
+}
+
+var commandCases = []commandCase{
+	{"version", "Version", nil, nil},
+	{"init", "Init", InitOptions{Name: "app", Version: "1.0.0", License: "MIT", Private: true, Force: true, WorkingDir: "/work/app"}, nil},
+	{"init-initializer", "Init", InitOptions{Initializer: "vite@latest", Args: []string{"my-app", "--template", "react"}, Force: true, WorkingDir: "/work"}, nil},
+	{"install-packages", "InstallPackages", InstallOptions{SaveDev: true, SaveExact: true, Registry: "https://registry.example.com/", IgnoreScripts: true, Omit: []string{"optional"}, WorkingDir: "/work/app", UserConfig: "/work/.npmrc"}, []string{"lodash@^4.17.0", "left-pad"}},
+	{"install-all", "InstallPackages", &InstallOptions{LegacyPeerDeps: true, Env: map[string]string{"CI": "true"}}, nil},
+	{"uninstall", "UninstallPackage", UninstallOptions{SaveDev: true}, []string{"lodash"}},
+	{"update", "UpdatePackage", nil, []string{"lodash"}},
+	{"update-options", "UpdatePackageWithOptions", UpdateOptions{WorkingDir: "/work/app", Save: true}, []string{"lodash"}},
+	{"update-all", "UpdateAll", UpdateOptions{Save: true, Registry: "https://registry.example.com/", WorkingDir: "/work/app"}, nil},
+	{"list-all", "ListPackages", ListOptions{Depth: -1, JSON: true, Production: true}, nil},
+	{"list-global", "ListGlobal", nil, nil},
+	{"install-global", "InstallGlobal", InstallOptions{Registry: "https://registry.example.com/"}, []string{"typescript"}},
+	{"uninstall-global", "UninstallGlobal", nil, []string{"typescript"}},
+	{"global-root", "GlobalRoot", nil, nil},
+	{"outdated", "Outdated", OutdatedOptions{WorkingDir: "/work/app"}, nil},
+	{"prune", "Prune", PruneOptions{Production: true, DryRun: true}, nil},
+	{"dedupe", "Dedupe", DedupeOptions{WorkingDir: "/work/app", UserConfig: "/work/.npmrc"}, nil},
+	{"shrinkwrap", "Shrinkwrap", nil, []string{"/work/app"}},
+	{"shrinkwrap-options", "ShrinkwrapWithOptions", ShrinkwrapOptions{WorkingDir: "/work/app", Env: map[string]string{"npm_config_loglevel": "warn"}}, nil},
+	{"config-list", "ConfigList", nil, []string{"/work/app"}},
+	{"config-list-options", "ConfigListWithOptions", ConfigListOptions{WorkingDir: "/work/app", UserConfig: "/work/.npmrc"}, nil},
+	{"fund", "Fund", FundOptions{Workspaces: []string{"a", "b"}}, nil},
+	{"audit", "Audit", AuditOptions{Production: true, Registry: "https://registry.example.com/"}, nil},
+	{"explain", "Explain", nil, []string{"lodash"}},
+	{"query", "Query", nil, []string{":attr(scripts, [postinstall])"}},
+	{"run-script", "RunScript", nil, []string{"build", "--prod"}},
+	{"run-script-options", "RunScriptWithOptions", RunScriptOptions{Args: []string{"--port", "3000"}, Timeout: -1, WorkingDir: "/work/app"}, []string{"dev"}},
+	{"publish", "Publish", PublishOptions{Tag: "next", Access: "public", OTP: "123456", ProvenanceFile: "bundle.json"}, []string{"/tmp/app-1.0.0.tgz"}},
+	{"unpublish", "Unpublish", UnpublishOptions{Force: true, Registry: "https://registry.example.com/"}, []string{"app"}},
+	{"pack", "Pack", PackOptions{PackDestination: "dist", DryRun: true}, nil},
+	{"view", "GetPackageInfo", nil, []string{"lodash"}},
+	{"search", "Search", nil, []string{"left pad"}},
+	{"dist-tag-add", "DistTagAdd", DistTagOptions{OTP: "123456"}, []string{"app@1.0.0", "beta"}},
+	{"owner-add", "AddOwner", OwnerOptions{}, []string{"alice", "@scope/app"}},
+	{"access-set", "SetAccess", AccessOptions{}, []string{"@scope/app", "restricted"}},
+	{"access-grant", "GrantAccess", AccessOptions{}, []string{"@scope/app", "scope:devs", "read-write"}},
+	{"token-create", "TokenCreate", TokenCreateOptions{ReadOnly: true, CIDR: []string{"10.0.0.0/8"}, Password: "secret"}, nil},
+	{"token-revoke", "TokenRevoke", TokenOptions{}, []string{"abc123"}},
+}
+
+// noExecExecutor BuildCommand不应该执行任何命令
+type noExecExecutor struct {
+	t *testing.T
+}
+
+func (e noExecExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	e.t.Errorf("Unexpected execution of %v", options.Args)
+	return &utils.ExecuteResult{Success: true}, nil
+}
+
+// TestBuildCommandGolden 检查各操作构造的命令，设置GO_NPM_SDK_UPDATE_GOLDEN=1时重新生成golden文件
+func TestBuildCommandGolden(t *testing.T) {
+	client, err := NewClientWithExecutor("/usr/local/bin/npm", noExecExecutor{t})
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+
+	update := os.Getenv("GO_NPM_SDK_UPDATE_GOLDEN") != ""
+	for _, tc := range commandCases {
+		t.Run(tc.name, func(t *testing.T) {
+			command, err := client.BuildCommand(tc.op, tc.options, tc.args...)
+			if err != nil {
+				t.Fatalf("BuildCommand() failed: %v", err)
+			}
+			got, err := json.MarshalIndent(command, "", "  ")
+			if err != nil {
+				t.Fatalf("Failed to marshal command: %v", err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", "golden", "commands", tc.name+".json")
+			if update {
+				writeTestFile(t, golden, string(got))
+				return
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with GO_NPM_SDK_UPDATE_GOLDEN=1 to create it): %v", err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Errorf("Command differs from %s:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestBuildCommandMatchesExecution(t *testing.T) {
+	executor := &listExecutor{result: &utils.ExecuteResult{Success: true, Stdout: "{}"}}
+	client, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	ctx := context.Background()
+
+	options := InstallOptions{SaveDev: true, Omit: []string{"peer"}}
+	client.InstallPackages(ctx, []string{"a", "b"}, options)
+	command, err := client.BuildCommand("InstallPackages", options, "a", "b")
+	if err != nil || !reflect.DeepEqual(command.Args, executor.args) {
+		t.Errorf("BuildCommand() = %v, %v, executed %v", command, err, executor.args)
+	}
+
+	distTag := DistTagOptions{Registry: "https://registry.example.com/"}
+	client.DistTagRemove(ctx, "app", "beta", distTag)
+	command, err = client.BuildCommand("DistTagRemove", &distTag, "app", "beta")
+	if err != nil || !reflect.DeepEqual(command.Args, executor.args) {
+		t.Errorf("BuildCommand() = %v, %v, executed %v", command, err, executor.args)
+	}
+}
+
+func TestBuildCommandValidation(t *testing.T) {
+	client, err := NewClientWithExecutor("npm", noExecExecutor{t})
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+
+	tests := []commandCase{
+		{"unknown operation", "Doctor", nil, nil},
+		{"wrong argument count", "UninstallPackage", nil, nil},
+		{"wrong options type", "Init", InstallOptions{}, nil},
+		{"options for operation without options", "GlobalRoot", InstallOptions{}, nil},
+		{"same validation as the method", "DistTagRemove", nil, []string{"app", "latest"}},
+		{"empty script", "RunScript", nil, nil},
+	}
+	for _, tt := range tests {
+		if _, err := client.BuildCommand(tt.op, tt.options, tt.args...); !IsValidationError(err, nil) {
+			t.Errorf("%s: expected validation error, got %v", tt.name, err)
+		}
+	}
+
+	for _, op := range CommandOperations() {
+		if op == "BuildCommand" || op == "Install" {
+			t.Errorf("Unexpected operation %s", op)
+		}
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	command := Command{Path: "/usr/bin/npm", Args: []string{"query", ":attr(scripts, [postinstall])", "it's", "", "@scope/pkg@^1.0.0"}}
+	expected := `/usr/bin/npm query ':attr(scripts, [postinstall])' 'it'\''s' '' @scope/pkg@^1.0.0`
+	if got := command.String(); got != expected {
+		t.Errorf("String() = %s, expected %s", got, expected)
+	}
+}
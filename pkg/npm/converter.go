@@ -0,0 +1,364 @@
+package npm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// ConvertOptions 包管理器迁移选项
+type ConvertOptions struct {
+	Target        ProjectManager `json:"target"`                   // 目标包管理器（pnpm或yarn）
+	TargetVersion string         `json:"target_version,omitempty"` // 目标包管理器版本，用于选择命令参数
+	DryRun        bool           `json:"dry_run,omitempty"`        // 只生成报告，不修改文件
+	SkipImport    bool           `json:"skip_import,omitempty"`    // 不转换锁文件
+	KeepLockfile  bool           `json:"keep_lockfile,omitempty"`  // 转换后保留package-lock.json
+	CIFiles       []string       `json:"ci_files,omitempty"`       // 额外需要改写的CI文件（相对项目目录）
+}
+
+// ConversionReport 迁移报告
+type ConversionReport struct {
+	From             ProjectManager    `json:"from"`
+	To               ProjectManager    `json:"to"`
+	DryRun           bool              `json:"dry_run"`
+	LockfileImported bool              `json:"lockfile_imported"`
+	ScriptChanges    map[string]string `json:"script_changes,omitempty"` // 脚本名 -> 新命令
+	ChangedFiles     []string          `json:"changed_files,omitempty"`
+	RemovedFiles     []string          `json:"removed_files,omitempty"`
+	FollowUps        []string          `json:"follow_ups,omitempty"` // 需要手动处理的事项
+}
+
+// Converter 在包管理器之间迁移项目
+type Converter struct {
+	workingDir string
+	pnpm       *Pnpm
+	yarn       *Yarn
+}
+
+// defaultCIFiles 默认检查的CI配置文件
+var defaultCIFiles = []string{
+	".gitlab-ci.yml",
+	".circleci/config.yml",
+	".travis.yml",
+	"azure-pipelines.yml",
+	"bitbucket-pipelines.yml",
+	"Jenkinsfile",
+	"Dockerfile",
+}
+
+// NewConverter 创建迁移器
+func NewConverter(workingDir string) *Converter {
+	return &Converter{
+		workingDir: workingDir,
+		pnpm:       NewPnpm(workingDir),
+		yarn:       NewYarn(workingDir),
+	}
+}
+
+// Convert 把npm项目迁移到目标包管理器
+//
+// 锁文件通过pnpm import或yarn import转换，package.json脚本和CI配置中的npm命令
+// 会被改写。无法可靠自动处理的内容（npx调用、CI中缺少的安装步骤等）记录在FollowUps中。
+func (c *Converter) Convert(ctx context.Context, options ConvertOptions) (*ConversionReport, error) {
+	if options.Target != ManagerPnpm && options.Target != ManagerYarn {
+		return nil, NewValidationError("target", string(options.Target), "target must be pnpm or yarn")
+	}
+
+	info, err := DetectProjectManager(c.workingDir)
+	if err != nil {
+		return nil, err
+	}
+	if info.Manager != ManagerNpm {
+		return nil, fmt.Errorf("project already uses %s, only npm projects can be converted", info.Manager)
+	}
+
+	report := &ConversionReport{
+		From:          ManagerNpm,
+		To:            options.Target,
+		DryRun:        options.DryRun,
+		ScriptChanges: make(map[string]string),
+	}
+	rewriter := newCommandRewriter(options.Target, options.TargetVersion)
+
+	if err := c.convertLockfile(ctx, options, report); err != nil {
+		return nil, err
+	}
+
+	if err := c.rewriteScripts(rewriter, options, report); err != nil {
+		return nil, err
+	}
+
+	for _, file := range c.ciFiles(options) {
+		if err := c.rewriteCIFile(file, rewriter, options, report); err != nil {
+			return nil, err
+		}
+	}
+
+	report.FollowUps = append(report.FollowUps, rewriter.followUps()...)
+	report.FollowUps = append(report.FollowUps,
+		fmt.Sprintf("set \"packageManager\" in package.json to pin the %s version", options.Target))
+
+	return report, nil
+}
+
+// convertLockfile 转换锁文件
+func (c *Converter) convertLockfile(ctx context.Context, options ConvertOptions, report *ConversionReport) error {
+	lockPath := filepath.Join(c.workingDir, "package-lock.json")
+	if _, err := os.Stat(lockPath); err != nil {
+		report.FollowUps = append(report.FollowUps,
+			fmt.Sprintf("no package-lock.json found, run \"%s install\" to create a fresh lockfile", options.Target))
+		return nil
+	}
+
+	if options.SkipImport || options.DryRun {
+		report.FollowUps = append(report.FollowUps,
+			fmt.Sprintf("lockfile not imported, run \"%s import\" to translate package-lock.json", options.Target))
+		return nil
+	}
+
+	var err error
+	switch options.Target {
+	case ManagerPnpm:
+		err = c.pnpm.Import(ctx)
+	case ManagerYarn:
+		err = c.yarn.Import(ctx)
+	}
+	if err != nil {
+		report.FollowUps = append(report.FollowUps,
+			fmt.Sprintf("lockfile import failed (%v), run \"%s install\" and review resolved versions", err, options.Target))
+		return nil
+	}
+	report.LockfileImported = true
+
+	if !options.KeepLockfile {
+		if err := os.Remove(lockPath); err != nil {
+			return fmt.Errorf("failed to remove package-lock.json: %w", err)
+		}
+		report.RemovedFiles = append(report.RemovedFiles, "package-lock.json")
+	}
+
+	return nil
+}
+
+// rewriteScripts 改写package.json中的脚本
+//
+// 直接在原始文本上替换脚本值，保留文件中的其他字段和键顺序。
+func (c *Converter) rewriteScripts(rewriter *commandRewriter, options ConvertOptions, report *ConversionReport) error {
+	path := filepath.Join(c.workingDir, "package.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read package.json: %w", err)
+	}
+
+	var manifest struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := utils.DecodeJSON(path, data, &manifest); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(manifest.Scripts))
+	for name := range manifest.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	content := string(data)
+	scriptsStart := strings.Index(content, `"scripts"`)
+	changed := false
+	for _, name := range names {
+		command := manifest.Scripts[name]
+		rewritten := rewriter.rewrite(command, fmt.Sprintf("script %q", name))
+		if rewritten == command {
+			continue
+		}
+
+		updated, ok := replaceJSONString(content, scriptsStart, command, rewritten)
+		if !ok {
+			report.FollowUps = append(report.FollowUps,
+				fmt.Sprintf("script %q could not be rewritten automatically, change it to %q", name, rewritten))
+			continue
+		}
+		content = updated
+		changed = true
+		report.ScriptChanges[name] = rewritten
+	}
+
+	if !changed {
+		return nil
+	}
+	report.ChangedFiles = append(report.ChangedFiles, "package.json")
+	if options.DryRun {
+		return nil
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// rewriteCIFile 改写CI配置文件中的命令
+func (c *Converter) rewriteCIFile(file string, rewriter *commandRewriter, options ConvertOptions, report *ConversionReport) error {
+	path := filepath.Join(c.workingDir, file)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	content := string(data)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = rewriter.rewrite(line, file)
+	}
+	rewritten := strings.Join(lines, "\n")
+
+	if options.Target == ManagerPnpm && strings.Contains(content, "actions/setup-node") &&
+		!strings.Contains(content, "pnpm/action-setup") {
+		report.FollowUps = append(report.FollowUps,
+			fmt.Sprintf("%s: add a pnpm/action-setup step before actions/setup-node", file))
+	}
+
+	if rewritten == content {
+		return nil
+	}
+	report.ChangedFiles = append(report.ChangedFiles, file)
+	if options.DryRun {
+		return nil
+	}
+	return os.WriteFile(path, []byte(rewritten), 0644)
+}
+
+// ciFiles 获取需要检查的CI文件列表
+func (c *Converter) ciFiles(options ConvertOptions) []string {
+	files := append([]string{}, defaultCIFiles...)
+
+	workflows, _ := filepath.Glob(filepath.Join(c.workingDir, ".github", "workflows", "*.y*ml"))
+	for _, workflow := range workflows {
+		if rel, err := filepath.Rel(c.workingDir, workflow); err == nil {
+			files = append(files, rel)
+		}
+	}
+
+	return append(files, options.CIFiles...)
+}
+
+// replaceJSONString 在JSON文本中替换from位置之后第一次出现的字符串值
+func replaceJSONString(content string, from int, oldValue, newValue string) (string, bool) {
+	if from < 0 {
+		return content, false
+	}
+
+	oldLiteral, err := encodeJSONString(oldValue)
+	if err != nil {
+		return content, false
+	}
+	newLiteral, err := encodeJSONString(newValue)
+	if err != nil {
+		return content, false
+	}
+
+	idx := strings.Index(content[from:], oldLiteral)
+	if idx < 0 {
+		return content, false
+	}
+	idx += from
+	return content[:idx] + newLiteral + content[idx+len(oldLiteral):], true
+}
+
+// encodeJSONString 按npm写入package.json的方式编码字符串
+func encodeJSONString(value string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// commandRewriter npm命令改写器
+type commandRewriter struct {
+	target  ProjectManager
+	berry   bool
+	notes   []string
+	noteSet map[string]bool
+}
+
+var (
+	npmCIPattern        = regexp.MustCompile(`\bnpm ci\b`)
+	npmInstallPattern   = regexp.MustCompile(`\bnpm (?:install|i)\b((?:\s+-{1,2}[\w-]+(?:=\S+)?)*)(\s+[^-&|;\s]\S*)?`)
+	npmRunPattern       = regexp.MustCompile(`\bnpm run(?:-script)? `)
+	npmLifecyclePattern = regexp.MustCompile(`\bnpm (test|start|stop|restart)\b`)
+	npmCachePattern     = regexp.MustCompile(`(cache:\s*['"]?)npm(['"]?)`)
+	npxPattern          = regexp.MustCompile(`\bnpx\s`)
+	npmOtherPattern     = regexp.MustCompile(`\bnpm ([a-z][\w-]*)`)
+)
+
+// newCommandRewriter 创建命令改写器
+func newCommandRewriter(target ProjectManager, version string) *commandRewriter {
+	major, ok := majorVersion(version)
+	return &commandRewriter{
+		target:  target,
+		berry:   target == ManagerYarn && ok && major >= 2,
+		noteSet: make(map[string]bool),
+	}
+}
+
+// rewrite 改写一行命令，source用于描述命令来源
+func (r *commandRewriter) rewrite(line, source string) string {
+	tool := string(r.target)
+
+	frozen := "--frozen-lockfile"
+	if r.berry {
+		frozen = "--immutable"
+	}
+	line = npmCIPattern.ReplaceAllString(line, tool+" install "+frozen)
+
+	line = npmInstallPattern.ReplaceAllStringFunc(line, func(match string) string {
+		parts := npmInstallPattern.FindStringSubmatch(match)
+		flags, pkg := parts[1], parts[2]
+		if pkg == "" {
+			return tool + " install" + flags
+		}
+		r.note(fmt.Sprintf("%s: review flags of converted \"%s add\" command", source, tool))
+		return tool + " add" + flags + pkg
+	})
+
+	line = npmRunPattern.ReplaceAllString(line, tool+" run ")
+	line = npmLifecyclePattern.ReplaceAllString(line, tool+" $1")
+	line = npmCachePattern.ReplaceAllString(line, "${1}"+tool+"${2}")
+
+	if npxPattern.MatchString(line) {
+		r.note(fmt.Sprintf("%s: replace npx with \"%s exec\" for local binaries or \"%s dlx\" for one-off packages", source, tool, tool))
+	}
+	for _, match := range npmOtherPattern.FindAllStringSubmatch(line, -1) {
+		r.note(fmt.Sprintf("%s: \"npm %s\" was not converted automatically", source, match[1]))
+	}
+
+	return line
+}
+
+// note 记录需要手动处理的事项
+func (r *commandRewriter) note(message string) {
+	if r.noteSet[message] {
+		return
+	}
+	r.noteSet[message] = true
+	r.notes = append(r.notes, message)
+}
+
+// followUps 获取需要手动处理的事项
+func (r *commandRewriter) followUps() []string {
+	return r.notes
+}
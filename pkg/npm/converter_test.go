@@ -0,0 +1,153 @@
+package npm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandRewriter(t *testing.T) {
+	tests := []struct {
+		target   ProjectManager
+		version  string
+		input    string
+		expected string
+	}{
+		{ManagerPnpm, "", "npm ci", "pnpm install --frozen-lockfile"},
+		{ManagerYarn, "1.22.19", "npm ci", "yarn install --frozen-lockfile"},
+		{ManagerYarn, "4.1.0", "npm ci", "yarn install --immutable"},
+		{ManagerPnpm, "", "npm install && npm test", "pnpm install && pnpm test"},
+		{ManagerPnpm, "", "npm i --save-dev typescript", "pnpm add --save-dev typescript"},
+		{ManagerPnpm, "", "npm run build -- --watch", "pnpm run build -- --watch"},
+		{ManagerYarn, "", "npm run-script lint", "yarn run lint"},
+		{ManagerPnpm, "", "          cache: 'npm'", "          cache: 'pnpm'"},
+		{ManagerPnpm, "", "tsc && node dist/index.js", "tsc && node dist/index.js"},
+		{ManagerPnpm, "", "pnpm install", "pnpm install"},
+	}
+
+	for _, tt := range tests {
+		rewriter := newCommandRewriter(tt.target, tt.version)
+		if result := rewriter.rewrite(tt.input, "test"); result != tt.expected {
+			t.Errorf("rewrite(%q) to %s = %q, expected %q", tt.input, tt.target, result, tt.expected)
+		}
+	}
+}
+
+func TestCommandRewriterFollowUps(t *testing.T) {
+	rewriter := newCommandRewriter(ManagerPnpm, "")
+	rewriter.rewrite("npx eslint . && npm publish", "script \"lint\"")
+	rewriter.rewrite("npx eslint .", "script \"lint\"")
+
+	notes := rewriter.followUps()
+	if len(notes) != 2 {
+		t.Fatalf("Expected 2 deduplicated follow-ups, got %d: %v", len(notes), notes)
+	}
+	if !strings.Contains(notes[0], "npx") || !strings.Contains(notes[1], "npm publish") {
+		t.Errorf("Unexpected follow-ups: %v", notes)
+	}
+}
+
+func setupConvertProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, ".git"), 0755)
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{
+  "name": "convert-me",
+  "version": "1.0.0",
+  "engines": {"node": ">=18"},
+  "scripts": {
+    "build": "tsc",
+    "ci": "npm run build && npm test",
+    "setup": "npm ci"
+  }
+}
+`)
+	writeTestFile(t, filepath.Join(dir, ".github", "workflows", "ci.yml"), `jobs:
+  test:
+    steps:
+      - uses: actions/setup-node@v4
+        with:
+          cache: npm
+      - run: npm ci
+      - run: npm test
+`)
+	return dir
+}
+
+func TestConverterConvert(t *testing.T) {
+	dir := setupConvertProject(t)
+
+	report, err := NewConverter(dir).Convert(context.Background(), ConvertOptions{Target: ManagerPnpm})
+	if err != nil {
+		t.Fatalf("Convert() failed: %v", err)
+	}
+
+	if report.From != ManagerNpm || report.To != ManagerPnpm {
+		t.Errorf("Unexpected conversion direction: %s -> %s", report.From, report.To)
+	}
+	if len(report.ScriptChanges) != 2 {
+		t.Errorf("Expected 2 script changes, got %v", report.ScriptChanges)
+	}
+	if report.ScriptChanges["setup"] != "pnpm install --frozen-lockfile" {
+		t.Errorf("Unexpected setup script: %q", report.ScriptChanges["setup"])
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "package.json"))
+	manifest := string(data)
+	if !strings.Contains(manifest, `"ci": "pnpm run build && pnpm test"`) {
+		t.Errorf("Expected ci script to be rewritten, got:\n%s", manifest)
+	}
+	if !strings.Contains(manifest, `"engines": {"node": ">=18"}`) {
+		t.Error("Expected unrelated fields to be preserved verbatim")
+	}
+
+	workflow, _ := os.ReadFile(filepath.Join(dir, ".github", "workflows", "ci.yml"))
+	if strings.Contains(string(workflow), "npm ci") || !strings.Contains(string(workflow), "cache: pnpm") {
+		t.Errorf("Expected workflow to be rewritten, got:\n%s", workflow)
+	}
+
+	foundSetupNote := false
+	for _, note := range report.FollowUps {
+		if strings.Contains(note, "pnpm/action-setup") {
+			foundSetupNote = true
+		}
+	}
+	if !foundSetupNote {
+		t.Errorf("Expected follow-up about pnpm/action-setup, got %v", report.FollowUps)
+	}
+}
+
+func TestConverterDryRun(t *testing.T) {
+	dir := setupConvertProject(t)
+	before, _ := os.ReadFile(filepath.Join(dir, "package.json"))
+
+	report, err := NewConverter(dir).Convert(context.Background(), ConvertOptions{Target: ManagerYarn, DryRun: true})
+	if err != nil {
+		t.Fatalf("Convert() failed: %v", err)
+	}
+
+	if len(report.ChangedFiles) != 2 {
+		t.Errorf("Expected 2 files reported as changed, got %v", report.ChangedFiles)
+	}
+
+	after, _ := os.ReadFile(filepath.Join(dir, "package.json"))
+	if string(before) != string(after) {
+		t.Error("Expected dry-run to leave package.json untouched")
+	}
+}
+
+func TestConverterValidation(t *testing.T) {
+	dir := setupConvertProject(t)
+	converter := NewConverter(dir)
+
+	if _, err := converter.Convert(context.Background(), ConvertOptions{Target: ManagerBun}); err == nil {
+		t.Error("Expected error for unsupported target")
+	}
+
+	writeTestFile(t, filepath.Join(dir, "yarn.lock"), "")
+	if _, err := converter.Convert(context.Background(), ConvertOptions{Target: ManagerPnpm}); err == nil {
+		t.Error("Expected error for non-npm project")
+	}
+}
@@ -0,0 +1,180 @@
+package npm
+
+import (
+	"context"
+	"slices"
+	"sort"
+)
+
+// maxDependencyCycles Cycles最多返回的环数，高度互相依赖的图中环的数量随节点数指数增长
+const maxDependencyCycles = 10000
+
+// DependencyCycle 依赖图中的一个环
+type DependencyCycle struct {
+	// Path 环上的节点ID，从ID最小的节点开始，最后一个元素与第一个相同，例如[a@1.0.0 b@1.0.0 a@1.0.0]
+	Path []string `json:"path"`
+
+	// Types 每一步依赖的类型，与Path中相邻的两个节点对应，两个包之间有多种依赖时取第一种
+	Types []DependencyType `json:"types"`
+
+	// Workspace 环上的节点都是工作区包
+	Workspace bool `json:"workspace,omitempty"`
+}
+
+// FindCycles 查找项目依赖图中的所有环
+//
+// 依赖图来自锁文件或npm ls，配置了工作区时包含工作区包之间的依赖，参见Graph。
+func (dm *DependencyManager) FindCycles(ctx context.Context) ([]DependencyCycle, error) {
+	graph, err := dm.Graph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graph.Cycles(), nil
+}
+
+// Cycles 返回依赖图中的所有简单环，按长度和路径排序
+//
+// 同一组节点按不同顺序构成的环分别返回，自依赖按长度为1的环返回；环的数量超过10000时只返回前10000个。
+func (g *DependencyGraph) Cycles() []DependencyCycle {
+	ids := make([]string, len(g.Nodes))
+	index := make(map[string]int, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[i] = node.ID
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	// 两个包之间有多种依赖时只保留一条边
+	adjacency := make([][]int, len(ids))
+	edgeTypes := make(map[[2]int]DependencyType)
+	for _, edge := range g.Edges {
+		from, okFrom := index[edge.From]
+		to, okTo := index[edge.To]
+		if !okFrom || !okTo {
+			continue
+		}
+		key := [2]int{from, to}
+		if _, exists := edgeTypes[key]; exists {
+			continue
+		}
+		edgeTypes[key] = edge.Type
+		adjacency[from] = append(adjacency[from], to)
+	}
+	for _, next := range adjacency {
+		slices.Sort(next)
+	}
+
+	workspace := make(map[string]bool)
+	for _, node := range g.Nodes {
+		workspace[node.ID] = node.Workspace
+	}
+
+	// 环上的节点一定属于同一个强连通分量，只在分量内搜索
+	component := stronglyConnectedComponents(adjacency)
+
+	var cycles []DependencyCycle
+	var path []int
+	onPath := make([]bool, len(ids))
+	var search func(start, current int) bool
+	search = func(start, current int) bool {
+		path = append(path, current)
+		onPath[current] = true
+		defer func() {
+			path = path[:len(path)-1]
+			onPath[current] = false
+		}()
+
+		for _, next := range adjacency[current] {
+			if component[next] != component[start] || next < start {
+				continue
+			}
+			if next == start {
+				cycle := DependencyCycle{Workspace: true}
+				for i, node := range path {
+					cycle.Path = append(cycle.Path, ids[node])
+					following := start
+					if i+1 < len(path) {
+						following = path[i+1]
+					}
+					cycle.Types = append(cycle.Types, edgeTypes[[2]int{node, following}])
+					cycle.Workspace = cycle.Workspace && workspace[ids[node]]
+				}
+				cycle.Path = append(cycle.Path, ids[start])
+				cycles = append(cycles, cycle)
+				if len(cycles) >= maxDependencyCycles {
+					return false
+				}
+				continue
+			}
+			if !onPath[next] && !search(start, next) {
+				return false
+			}
+		}
+		return true
+	}
+	for start := range ids {
+		if !search(start, start) {
+			break
+		}
+	}
+
+	sort.SliceStable(cycles, func(i, j int) bool {
+		if len(cycles[i].Path) != len(cycles[j].Path) {
+			return len(cycles[i].Path) < len(cycles[j].Path)
+		}
+		return slices.Compare(cycles[i].Path, cycles[j].Path) < 0
+	})
+	return cycles
+}
+
+// stronglyConnectedComponents 使用Tarjan算法计算每个节点所属的强连通分量编号
+func stronglyConnectedComponents(adjacency [][]int) []int {
+	n := len(adjacency)
+	component := make([]int, n)
+	order := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range order {
+		order[i] = -1
+	}
+
+	var stack []int
+	counter, components := 0, 0
+	var visit func(v int)
+	visit = func(v int) {
+		order[v], low[v] = counter, counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range adjacency[v] {
+			if order[w] < 0 {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], order[w])
+			}
+		}
+
+		if low[v] == order[v] {
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component[w] = components
+				if w == v {
+					break
+				}
+			}
+			components++
+		}
+	}
+	for v := range adjacency {
+		if order[v] < 0 {
+			visit(v)
+		}
+	}
+	return component
+}
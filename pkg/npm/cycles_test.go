@@ -0,0 +1,80 @@
+package npm
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDependencyGraphCycles(t *testing.T) {
+	graph := &DependencyGraph{
+		Root: "app",
+		Nodes: []GraphNode{
+			{ID: "app", Root: true}, {ID: "a@1.0.0"}, {ID: "b@1.0.0"}, {ID: "c@1.0.0"}, {ID: "d@1.0.0"}, {ID: "e@1.0.0"},
+		},
+		Edges: []GraphEdge{
+			{From: "app", To: "a@1.0.0", Type: Production},
+			{From: "a@1.0.0", To: "b@1.0.0", Type: Production},
+			{From: "a@1.0.0", To: "b@1.0.0", Type: Peer},
+			{From: "b@1.0.0", To: "c@1.0.0", Type: Production},
+			{From: "c@1.0.0", To: "a@1.0.0", Type: Development},
+			{From: "b@1.0.0", To: "a@1.0.0", Type: Production},
+			{From: "c@1.0.0", To: "d@1.0.0", Type: Production},
+			{From: "e@1.0.0", To: "e@1.0.0", Type: Production},
+		},
+	}
+
+	cycles := graph.Cycles()
+	want := []DependencyCycle{
+		{Path: []string{"e@1.0.0", "e@1.0.0"}, Types: []DependencyType{Production}},
+		{Path: []string{"a@1.0.0", "b@1.0.0", "a@1.0.0"}, Types: []DependencyType{Production, Production}},
+		{Path: []string{"a@1.0.0", "b@1.0.0", "c@1.0.0", "a@1.0.0"}, Types: []DependencyType{Production, Production, Development}},
+	}
+	if !reflect.DeepEqual(cycles, want) {
+		t.Errorf("Expected cycles %+v, got %+v", want, cycles)
+	}
+
+	if cycles := (&DependencyGraph{Nodes: graph.Nodes[:3], Edges: graph.Edges[:3]}).Cycles(); len(cycles) != 0 {
+		t.Errorf("Expected no cycles, got %+v", cycles)
+	}
+}
+
+func TestDependencyManagerFindWorkspaceCycles(t *testing.T) {
+	root := writeTestMonorepo(t)
+	// core通过开发依赖引用app，形成core -> app -> core和core -> app -> utils -> core
+	writeTestFile(t, filepath.Join(root, "packages", "core", "package.json"), `{"name":"@repo/core","version":"1.2.0","devDependencies":{"@repo/app":"workspace:*"}}`)
+	writeTestFile(t, filepath.Join(root, "package-lock.json"), `{
+  "name": "root",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "root", "workspaces": ["packages/*"]},
+    "node_modules/@repo/app": {"resolved": "packages/app", "link": true},
+    "node_modules/lodash": {"version": "4.17.21"}
+  }
+}`)
+
+	dm, _ := NewDependencyManager(NewMockClient(), root)
+	cycles, err := dm.FindCycles(context.Background())
+	if err != nil {
+		t.Fatalf("FindCycles() failed: %v", err)
+	}
+
+	var paths [][]string
+	for _, cycle := range cycles {
+		if !cycle.Workspace {
+			t.Errorf("Expected workspace cycle, got %+v", cycle)
+		}
+		paths = append(paths, cycle.Path)
+	}
+	want := [][]string{
+		{"@repo/app@2.0.0", "@repo/core@1.2.0", "@repo/app@2.0.0"},
+		{"@repo/app@2.0.0", "@repo/utils@0.3.1", "@repo/core@1.2.0", "@repo/app@2.0.0"},
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected cycles %v, got %v", want, paths)
+	}
+	if cycles[0].Types[1] != Development {
+		t.Errorf("Expected core -> app to be a dev dependency, got %v", cycles[0].Types)
+	}
+}
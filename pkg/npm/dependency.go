@@ -3,6 +3,7 @@ package npm
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 )
@@ -212,7 +213,7 @@ func (dm *DependencyManager) Update(ctx context.Context, packageName string) (*D
 	}
 
 	// 更新包
-	if err := dm.client.UpdatePackage(ctx, packageName); err != nil {
+	if err := dm.client.UpdatePackageWithOptions(ctx, packageName, UpdateOptions{WorkingDir: dm.workingDir}); err != nil {
 		operation.Error = fmt.Errorf("failed to update package: %w", err)
 		return operation, operation.Error
 	}
@@ -233,17 +234,9 @@ func (dm *DependencyManager) List(ctx context.Context) ([]*DependencyInfo, error
 	}
 
 	// 获取已安装的包列表
-	installedPackages, err := dm.client.ListPackages(ctx, ListOptions{
-		WorkingDir: dm.workingDir,
-		Depth:      0,
-	})
+	installedMap, err := dm.installedPackages(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list installed packages: %w", err)
-	}
-
-	installedMap := make(map[string]bool)
-	for _, pkg := range installedPackages {
-		installedMap[pkg.Name] = true
+		return nil, err
 	}
 
 	// 处理生产依赖
@@ -293,6 +286,43 @@ func (dm *DependencyManager) List(ctx context.Context) ([]*DependencyInfo, error
 	return dependencies, nil
 }
 
+// ProjectInfo 检测项目使用的包管理器及依赖链接方式
+func (dm *DependencyManager) ProjectInfo() (*ProjectManagerInfo, error) {
+	return DetectProjectManager(dm.workingDir)
+}
+
+// installedPackages 获取已安装包的名称集合
+//
+// Yarn Plug'n'Play项目没有node_modules，npm ls会把所有依赖报告为缺失，
+// 因此改用yarn info查询。
+func (dm *DependencyManager) installedPackages(ctx context.Context) (map[string]bool, error) {
+	installedMap := make(map[string]bool)
+
+	if info, err := dm.ProjectInfo(); err == nil && info.PnP {
+		packages, err := NewYarn(dm.workingDir).Info(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed packages: %w", err)
+		}
+		for _, pkg := range packages {
+			installedMap[pkg.Name] = true
+		}
+		return installedMap, nil
+	}
+
+	installedPackages, err := dm.client.ListPackages(ctx, ListOptions{
+		WorkingDir: dm.workingDir,
+		Depth:      0,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed packages: %w", err)
+	}
+
+	for _, pkg := range installedPackages {
+		installedMap[pkg.Name] = true
+	}
+	return installedMap, nil
+}
+
 // CheckOutdated 检查过期的依赖
 func (dm *DependencyManager) CheckOutdated(ctx context.Context) ([]*DependencyInfo, error) {
 	dependencies, err := dm.List(ctx)
@@ -327,23 +357,44 @@ func (dm *DependencyManager) CheckOutdated(ctx context.Context) ([]*DependencyIn
 }
 
 // Install 安装所有依赖
+//
+// 项目中有EnvironmentLockFile时先比较执行环境，有差异时发送EnvironmentDriftEvent。
 func (dm *DependencyManager) Install(ctx context.Context) error {
+	dm.warnEnvironmentDrift(ctx)
 	installOptions := InstallOptions{
 		WorkingDir: dm.workingDir,
 	}
 
 	// 不指定包名时，npm install会安装package.json中的所有依赖
-	return dm.client.InstallPackage(ctx, "", installOptions)
+	return dm.client.InstallPackages(ctx, nil, installOptions)
 }
 
-// Clean 清理node_modules并重新安装
-func (dm *DependencyManager) Clean(ctx context.Context) error {
-	// 这里可以添加删除node_modules的逻辑
-	// 然后重新安装所有依赖
-	return dm.Install(ctx)
+// Prune 移除node_modules中多余的包，production为true时同时移除开发依赖
+func (dm *DependencyManager) Prune(ctx context.Context, production bool) error {
+	return dm.client.Prune(ctx, PruneOptions{
+		Production: production,
+		WorkingDir: dm.workingDir,
+	})
+}
+
+// Dedupe 合并node_modules中重复的包
+func (dm *DependencyManager) Dedupe(ctx context.Context) error {
+	return dm.client.Dedupe(ctx, DedupeOptions{
+		WorkingDir: dm.workingDir,
+	})
+}
+
+// Why 解释包为什么被安装，返回每个已安装实例的依赖链
+func (dm *DependencyManager) Why(ctx context.Context, packageName string) ([]ExplainNode, error) {
+	if packageName == "" {
+		return nil, NewValidationError("package", packageName, "package name cannot be empty")
+	}
+	return dm.client.Explain(ctx, packageName)
 }
 
 // GetDependencyTree 获取依赖树
+//
+// 依赖缺失或版本无效时同时返回依赖树和*ListProblemsError。
 func (dm *DependencyManager) GetDependencyTree(ctx context.Context) ([]Package, error) {
 	return dm.client.ListPackages(ctx, ListOptions{
 		WorkingDir: dm.workingDir,
@@ -352,16 +403,31 @@ func (dm *DependencyManager) GetDependencyTree(ctx context.Context) ([]Package,
 	})
 }
 
-// Audit 安全审计
+// Audit 安全审计，只返回审计是否成功执行，需要审计结果时使用AuditReport
 func (dm *DependencyManager) Audit(ctx context.Context) error {
-	// 这里可以实现npm audit功能
-	// 目前返回未实现错误
-	return fmt.Errorf("audit functionality not implemented yet")
+	_, err := dm.AuditReport(ctx)
+	return err
+}
+
+// AuditReport 安全审计并返回结果，项目中存在审计忽略文件时自动应用
+func (dm *DependencyManager) AuditReport(ctx context.Context) (*AuditReport, error) {
+	options := AuditOptions{
+		WorkingDir: dm.workingDir,
+	}
+	ignoreFile := filepath.Join(dm.workingDir, DefaultAuditIgnoreFile)
+	if _, err := os.Stat(ignoreFile); err == nil {
+		options.IgnoreFile = ignoreFile
+	}
+	return dm.client.Audit(ctx, options)
 }
 
-// Fix 修复安全漏洞
+// Fix 修复安全漏洞，需要修复结果时使用FixWithResult
 func (dm *DependencyManager) Fix(ctx context.Context) error {
-	// 这里可以实现npm audit fix功能
-	// 目前返回未实现错误
-	return fmt.Errorf("fix functionality not implemented yet")
+	_, err := dm.FixWithResult(ctx)
+	return err
+}
+
+// FixWithResult 修复安全漏洞并返回结果，直接依赖提升版本范围，传递依赖通过overrides修复
+func (dm *DependencyManager) FixWithResult(ctx context.Context) (*AuditFixResult, error) {
+	return NewAuditFixer(dm.client, dm.workingDir).Fix(ctx, AuditFixOptions{})
 }
@@ -2,14 +2,22 @@ package npm
 
 import (
 	"context"
+	"log/slog"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // MockClient 用于测试的模拟客户端
 type MockClient struct {
 	packages  map[string]*PackageInfo
 	installed map[string]bool
+	pruned    *PruneOptions
+	deduped   *DedupeOptions
+	audits    []*AuditReport
+	outdated  []OutdatedPackage
+
+	installCalls int
 }
 
 func NewMockClient() *MockClient {
@@ -43,6 +51,14 @@ func (m *MockClient) InstallPackage(ctx context.Context, pkg string, options Ins
 	return nil
 }
 
+func (m *MockClient) InstallPackages(ctx context.Context, pkgs []string, options InstallOptions) error {
+	m.installCalls++
+	for _, pkg := range pkgs {
+		m.installed[pkg] = true
+	}
+	return nil
+}
+
 func (m *MockClient) UninstallPackage(ctx context.Context, pkg string, options UninstallOptions) error {
 	delete(m.installed, pkg)
 	return nil
@@ -52,6 +68,18 @@ func (m *MockClient) UpdatePackage(ctx context.Context, pkg string) error {
 	return nil
 }
 
+func (m *MockClient) UpdatePackageWithOptions(ctx context.Context, pkg string, options UpdateOptions) error {
+	return nil
+}
+
+func (m *MockClient) UpdateAll(ctx context.Context, options UpdateOptions) (*UpdateResult, error) {
+	return &UpdateResult{}, nil
+}
+
+func (m *MockClient) Outdated(ctx context.Context, options OutdatedOptions) ([]OutdatedPackage, error) {
+	return m.outdated, nil
+}
+
 func (m *MockClient) ListPackages(ctx context.Context, options ListOptions) ([]Package, error) {
 	var packages []Package
 	for name := range m.installed {
@@ -63,14 +91,97 @@ func (m *MockClient) ListPackages(ctx context.Context, options ListOptions) ([]P
 	return packages, nil
 }
 
+func (m *MockClient) Prune(ctx context.Context, options PruneOptions) error {
+	m.pruned = &options
+	return nil
+}
+
+func (m *MockClient) Dedupe(ctx context.Context, options DedupeOptions) error {
+	m.deduped = &options
+	return nil
+}
+
+// Audit 依次返回预设的审计报告，用完后返回空报告
+func (m *MockClient) Shrinkwrap(ctx context.Context, workingDir string) error {
+	return nil
+}
+
+func (m *MockClient) ShrinkwrapWithOptions(ctx context.Context, options ShrinkwrapOptions) error {
+	return nil
+}
+
+func (m *MockClient) Fund(ctx context.Context, options FundOptions) (*FundResult, error) {
+	return &FundResult{}, nil
+}
+
+func (m *MockClient) Audit(ctx context.Context, options AuditOptions) (*AuditReport, error) {
+	if len(m.audits) == 0 {
+		return &AuditReport{Counts: make(map[Severity]int)}, nil
+	}
+	report := m.audits[0]
+	m.audits = m.audits[1:]
+	return report, nil
+}
+
+func (m *MockClient) Doctor(ctx context.Context) (*DoctorResult, error) {
+	return &DoctorResult{OK: true}, nil
+}
+
+func (m *MockClient) Ping(ctx context.Context, registry string) (time.Duration, error) {
+	return 10 * time.Millisecond, nil
+}
+
+func (m *MockClient) Explain(ctx context.Context, pkg string) ([]ExplainNode, error) {
+	if !m.installed[pkg] {
+		return nil, ErrPackageNotFound
+	}
+	return []ExplainNode{{Name: pkg, Version: "1.0.0", Location: "node_modules/" + pkg}}, nil
+}
+
+func (m *MockClient) Query(ctx context.Context, selector string) ([]QueryResult, error) {
+	return nil, nil
+}
+
 func (m *MockClient) RunScript(ctx context.Context, script string, args ...string) error {
 	return nil
 }
 
+func (m *MockClient) RunScriptWithOptions(ctx context.Context, script string, options RunScriptOptions) error {
+	return m.RunScript(ctx, script, options.Args...)
+}
+
 func (m *MockClient) Publish(ctx context.Context, options PublishOptions) error {
 	return nil
 }
 
+func (m *MockClient) Events() *EventBus {
+	return NewEventBus()
+}
+
+func (m *MockClient) SetLogger(logger *slog.Logger) {}
+
+func (m *MockClient) SetRetryPolicy(policy *RetryPolicy) {}
+
+func (m *MockClient) DistTagList(ctx context.Context, pkg string, options DistTagOptions) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (m *MockClient) DistTagAdd(ctx context.Context, spec, tag string, options DistTagOptions) error {
+	return nil
+}
+
+func (m *MockClient) DistTagRemove(ctx context.Context, pkg, tag string, options DistTagOptions) error {
+	return nil
+}
+
+func (m *MockClient) Unpublish(ctx context.Context, spec string, options UnpublishOptions) error {
+	return nil
+}
+
+func (m *MockClient) Pack(ctx context.Context, options PackOptions) (*PackResult, error) {
+	return &PackResult{}, nil
+}
+
 func (m *MockClient) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
 	if info, exists := m.packages[pkg]; exists {
 		return info, nil
@@ -85,10 +196,90 @@ func (m *MockClient) GetPackageInfo(ctx context.Context, pkg string) (*PackageIn
 	}, nil
 }
 
+func (m *MockClient) GetPackagesInfo(ctx context.Context, pkgs []string, concurrency int) (map[string]*PackageInfo, error) {
+	results := make(map[string]*PackageInfo, len(pkgs))
+	for _, pkg := range pkgs {
+		results[pkg], _ = m.GetPackageInfo(ctx, pkg)
+	}
+	return results, nil
+}
+
 func (m *MockClient) Search(ctx context.Context, query string) ([]SearchResult, error) {
 	return []SearchResult{}, nil
 }
 
+func (m *MockClient) AddOwner(ctx context.Context, user, pkg string, options OwnerOptions) error {
+	return nil
+}
+
+func (m *MockClient) RemoveOwner(ctx context.Context, user, pkg string, options OwnerOptions) error {
+	return nil
+}
+
+func (m *MockClient) ListOwners(ctx context.Context, pkg string, options OwnerOptions) ([]Owner, error) {
+	return []Owner{}, nil
+}
+
+func (m *MockClient) GetAccess(ctx context.Context, pkg string, options AccessOptions) (AccessLevel, error) {
+	return AccessPublic, nil
+}
+
+func (m *MockClient) SetAccess(ctx context.Context, pkg string, level AccessLevel, options AccessOptions) error {
+	return nil
+}
+
+func (m *MockClient) GrantAccess(ctx context.Context, pkg, team string, permission Permission, options AccessOptions) error {
+	return nil
+}
+
+func (m *MockClient) RevokeAccess(ctx context.Context, pkg, team string, options AccessOptions) error {
+	return nil
+}
+
+func (m *MockClient) ListCollaborators(ctx context.Context, pkg string, options AccessOptions) (map[string]Permission, error) {
+	return map[string]Permission{}, nil
+}
+
+func (m *MockClient) TokenCreate(ctx context.Context, options TokenCreateOptions) (*Token, error) {
+	return &Token{}, nil
+}
+
+func (m *MockClient) TokenList(ctx context.Context, options TokenOptions) ([]Token, error) {
+	return []Token{}, nil
+}
+
+func (m *MockClient) TokenRevoke(ctx context.Context, id string, options TokenOptions) error {
+	return nil
+}
+
+func (m *MockClient) ListGlobal(ctx context.Context) ([]Package, error) {
+	return nil, nil
+}
+
+func (m *MockClient) InstallGlobal(ctx context.Context, pkg string, options InstallOptions) error {
+	return nil
+}
+
+func (m *MockClient) UninstallGlobal(ctx context.Context, pkg string, options UninstallOptions) error {
+	return nil
+}
+
+func (m *MockClient) GlobalRoot(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+func (m *MockClient) BuildCommand(op string, options interface{}, args ...string) (*Command, error) {
+	return &Command{Path: "npm"}, nil
+}
+
+func (m *MockClient) ConfigList(ctx context.Context, workingDir string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (m *MockClient) ConfigListWithOptions(ctx context.Context, options ConfigListOptions) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
 func (m *MockClient) AddPackage(name, version, description string) {
 	m.packages[name] = &PackageInfo{
 		Name:        name,
@@ -412,3 +603,61 @@ func TestDependencyManagerValidation(t *testing.T) {
 		t.Error("Expected operation to fail")
 	}
 }
+
+func TestDependencyManagerPruneAndDedupe(t *testing.T) {
+	mockClient := NewMockClient()
+	tempDir := t.TempDir()
+	dm, err := NewDependencyManager(mockClient, tempDir)
+	if err != nil {
+		t.Fatalf("NewDependencyManager() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := dm.Prune(ctx, true); err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	if mockClient.pruned == nil || !mockClient.pruned.Production || mockClient.pruned.WorkingDir != tempDir {
+		t.Errorf("Unexpected prune options: %+v", mockClient.pruned)
+	}
+
+	if err := dm.Dedupe(ctx); err != nil {
+		t.Fatalf("Dedupe() failed: %v", err)
+	}
+	if mockClient.deduped == nil || mockClient.deduped.WorkingDir != tempDir {
+		t.Errorf("Unexpected dedupe options: %+v", mockClient.deduped)
+	}
+}
+
+func TestDependencyManagerInstallAll(t *testing.T) {
+	mockClient := NewMockClient()
+	dm, err := NewDependencyManager(mockClient, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDependencyManager() failed: %v", err)
+	}
+
+	if err := dm.Install(context.Background()); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if mockClient.installCalls != 1 {
+		t.Errorf("Expected a single install call, got %d", mockClient.installCalls)
+	}
+}
+
+func TestDependencyManagerWhy(t *testing.T) {
+	mockClient := NewMockClient()
+	dm, _ := NewDependencyManager(mockClient, t.TempDir())
+	ctx := context.Background()
+
+	if _, err := dm.Why(ctx, ""); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for empty package name, got %v", err)
+	}
+	if _, err := dm.Why(ctx, "lodash"); !IsPackageNotFound(err) {
+		t.Errorf("Expected package not found, got %v", err)
+	}
+
+	mockClient.installed["lodash"] = true
+	nodes, err := dm.Why(ctx, "lodash")
+	if err != nil || len(nodes) != 1 || nodes[0].String() != "lodash@1.0.0" {
+		t.Errorf("Unexpected result: %+v, %v", nodes, err)
+	}
+}
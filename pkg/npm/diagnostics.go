@@ -0,0 +1,318 @@
+package npm
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/platform"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// DefaultOperationRecords OperationRecorder默认保留的日志条数
+const DefaultOperationRecords = 500
+
+// OperationRecord 一条SDK操作日志
+type OperationRecord struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+// OperationRecorder 在内存中保留最近的SDK操作日志，用于诊断包
+//
+// 实现了slog.Handler，通过client.SetLogger(slog.New(recorder))记录客户端执行的命令。
+// 记录所有级别的日志，超过容量时丢弃最早的记录。
+type OperationRecorder struct {
+	state *recorderState
+	attrs []slog.Attr
+	group string
+}
+
+// recorderState 同一个OperationRecorder派生出的Handler共享的记录
+type recorderState struct {
+	mu       sync.Mutex
+	capacity int
+	records  []OperationRecord
+}
+
+// NewOperationRecorder 创建操作日志记录器，capacity小于等于0时使用DefaultOperationRecords
+func NewOperationRecorder(capacity int) *OperationRecorder {
+	if capacity <= 0 {
+		capacity = DefaultOperationRecords
+	}
+	return &OperationRecorder{state: &recorderState{capacity: capacity}}
+}
+
+// Enabled 记录所有级别的日志
+func (r *OperationRecorder) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+// Handle 保存一条日志
+func (r *OperationRecorder) Handle(ctx context.Context, record slog.Record) error {
+	entry := OperationRecord{Time: record.Time, Level: record.Level.String(), Message: record.Message}
+	attrs := make(map[string]string)
+	for _, attr := range r.attrs {
+		addRecordAttr(attrs, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addRecordAttr(attrs, r.group, attr)
+		return true
+	})
+	if len(attrs) > 0 {
+		entry.Attrs = attrs
+	}
+
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	if len(r.state.records) >= r.state.capacity {
+		r.state.records = r.state.records[1:]
+	}
+	r.state.records = append(r.state.records, entry)
+	return nil
+}
+
+// WithAttrs 返回附加了属性的Handler，与原记录器共享记录
+func (r *OperationRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *r
+	clone.attrs = slices.Clone(r.attrs)
+	for _, attr := range attrs {
+		if r.group != "" {
+			attr.Key = r.group + "." + attr.Key
+		}
+		clone.attrs = append(clone.attrs, attr)
+	}
+	return &clone
+}
+
+// WithGroup 返回属性带有分组前缀的Handler，与原记录器共享记录
+func (r *OperationRecorder) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return r
+	}
+	clone := *r
+	if r.group != "" {
+		name = r.group + "." + name
+	}
+	clone.group = name
+	return &clone
+}
+
+// Records 返回保留的日志，按时间顺序
+func (r *OperationRecorder) Records() []OperationRecord {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	return slices.Clone(r.state.records)
+}
+
+// addRecordAttr 把属性展开为字符串，分组属性的键以"."连接
+func addRecordAttr(attrs map[string]string, prefix string, attr slog.Attr) {
+	key := attr.Key
+	if prefix != "" {
+		key = prefix + "." + key
+	}
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		for _, child := range value.Group() {
+			addRecordAttr(attrs, key, child)
+		}
+		return
+	}
+	attrs[key] = value.String()
+}
+
+// DiagnosticBundle 收集提交问题时需要的诊断信息，打包为一个zip文件
+//
+// 包含平台信息、npm检测结果、生效的npm配置、最近的SDK操作日志和失败命令的输出。
+// 所有内容在写入前隐藏令牌、密码等敏感信息，单项信息收集失败时记录在manifest.json中，不影响其他内容。
+type DiagnosticBundle struct {
+	client     Client
+	detector   *Detector
+	recorder   *OperationRecorder
+	workingDir string
+	failures   []error
+	secrets    []string
+}
+
+// DiagnosticManifest 诊断包的清单，记录生成环境和收集失败的项目
+type DiagnosticManifest struct {
+	CreatedAt time.Time         `json:"created_at"`
+	GoVersion string            `json:"go_version"`
+	OS        string            `json:"os"`
+	Arch      string            `json:"arch"`
+	Files     []string          `json:"files"`
+	Errors    map[string]string `json:"errors,omitempty"` // 文件名 -> 收集失败的原因
+}
+
+// DiagnosticFailure 诊断包中记录的一次失败操作
+type DiagnosticFailure struct {
+	Error    string `json:"error"`
+	Op       string `json:"op,omitempty"`
+	Package  string `json:"package,omitempty"`
+	ExitCode int    `json:"exit_code,omitempty"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+}
+
+// NewDiagnosticBundle 创建诊断包，client为nil时不收集npm配置
+func NewDiagnosticBundle(client Client) *DiagnosticBundle {
+	return &DiagnosticBundle{client: client, detector: NewDetector()}
+}
+
+// SetDetector 设置npm检测器，nil表示不检测npm
+func (b *DiagnosticBundle) SetDetector(detector *Detector) {
+	b.detector = detector
+}
+
+// SetRecorder 设置操作日志记录器，通常与客户端的SetLogger使用同一个记录器
+func (b *DiagnosticBundle) SetRecorder(recorder *OperationRecorder) {
+	b.recorder = recorder
+}
+
+// SetWorkingDir 设置读取npm配置的项目目录
+func (b *DiagnosticBundle) SetWorkingDir(dir string) {
+	b.workingDir = dir
+}
+
+// AddFailure 添加失败操作返回的错误，*NpmError中的命令输出一起写入诊断包
+func (b *DiagnosticBundle) AddFailure(err error) {
+	if err != nil {
+		b.failures = append(b.failures, err)
+	}
+}
+
+// AddSecret 添加需要隐藏的敏感值，例如调用方使用的令牌，出现在任何内容中都会被替换
+func (b *DiagnosticBundle) AddSecret(values ...string) {
+	for _, value := range values {
+		if value != "" {
+			b.secrets = append(b.secrets, value)
+		}
+	}
+}
+
+// WriteFile 把诊断包写入path
+func (b *DiagnosticBundle) WriteFile(ctx context.Context, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create diagnostic bundle: %w", err)
+	}
+	if err := b.Write(ctx, file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Write 收集诊断信息并以zip格式写入w
+func (b *DiagnosticBundle) Write(ctx context.Context, w io.Writer) error {
+	manifest := DiagnosticManifest{
+		CreatedAt: time.Now().UTC(),
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Errors:    make(map[string]string),
+	}
+	files := make(map[string]interface{})
+
+	if info, err := platform.NewDetector().Detect(); err != nil {
+		manifest.Errors["platform.json"] = err.Error()
+	} else {
+		files["platform.json"] = info
+	}
+
+	if b.detector != nil {
+		info, err := b.detector.Detect(ctx)
+		if err != nil {
+			manifest.Errors["npm.json"] = err.Error()
+		}
+		if info != nil {
+			files["npm.json"] = info
+		}
+	}
+
+	// 敏感配置项的值本身也作为敏感值，避免出现在日志和命令输出中
+	secrets := slices.Clone(b.secrets)
+	if b.client != nil {
+		config, err := b.client.ConfigList(ctx, b.workingDir)
+		if err != nil {
+			manifest.Errors["npm-config.json"] = err.Error()
+		} else {
+			for key, value := range config {
+				if utils.IsSensitiveKey(key) {
+					secrets = append(secrets, value)
+					config[key] = "***"
+				}
+			}
+			files["npm-config.json"] = config
+		}
+	}
+
+	if b.recorder != nil {
+		files["operations.json"] = b.recorder.Records()
+	}
+
+	if len(b.failures) > 0 {
+		failures := make([]DiagnosticFailure, len(b.failures))
+		for i, err := range b.failures {
+			failures[i] = DiagnosticFailure{Error: err.Error()}
+			var npmErr *NpmError
+			if errors.As(err, &npmErr) {
+				failures[i].Op = npmErr.Op
+				failures[i].Package = npmErr.Package
+				failures[i].ExitCode = npmErr.ExitCode
+				failures[i].Stdout = npmErr.Stdout
+				failures[i].Stderr = npmErr.Stderr
+			}
+		}
+		files["failures.json"] = failures
+	}
+
+	manifest.Files = slices.Sorted(maps.Keys(files))
+	if len(manifest.Errors) == 0 {
+		manifest.Errors = nil
+	}
+	files["manifest.json"] = manifest
+
+	archive := zip.NewWriter(w)
+	for _, name := range append([]string{"manifest.json"}, manifest.Files...) {
+		data, err := json.MarshalIndent(files[name], "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: manifest.CreatedAt})
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := io.WriteString(entry, redactDiagnostic(string(data), secrets)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return archive.Close()
+}
+
+// redactDiagnostic 隐藏JSON文本中已知的敏感值和常见格式的凭据
+func redactDiagnostic(text string, secrets []string) string {
+	for _, secret := range secrets {
+		// 过短的值容易误伤正常内容，例如"true"
+		if len(secret) < 6 {
+			continue
+		}
+		// 敏感值在JSON中可能被转义
+		encoded, _ := json.Marshal(secret)
+		text = strings.ReplaceAll(text, strings.Trim(string(encoded), `"`), "***")
+	}
+	return utils.RedactText(text)
+}
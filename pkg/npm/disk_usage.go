@@ -0,0 +1,186 @@
+package npm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PackageUsage node_modules中一个已安装包的磁盘占用
+type PackageUsage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Path    string `json:"path"`  // 相对项目目录，使用/分隔，例如node_modules/a/node_modules/lodash
+	Size    int64  `json:"size"`  // 文件的总字节数，不包括嵌套的node_modules
+	Files   int    `json:"files"` // 文件数，不包括嵌套的node_modules
+}
+
+// DiskUsageReport node_modules的磁盘占用报告
+type DiskUsageReport struct {
+	Packages []PackageUsage `json:"packages"` // 按Size从大到小排序，相同时按Path排序
+
+	// TotalSize、TotalFiles node_modules中所有文件，包括.bin、缓存等不属于任何包的文件
+	TotalSize  int64 `json:"total_size"`
+	TotalFiles int   `json:"total_files"`
+}
+
+// Top 返回占用最大的n个包，n大于包数时返回全部
+func (r *DiskUsageReport) Top(n int) []PackageUsage {
+	return r.Packages[:min(n, len(r.Packages))]
+}
+
+// ExportJSON 以缩进的JSON写入报告
+func (r *DiskUsageReport) ExportJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// DiskUsage 统计项目node_modules的磁盘占用
+func (dm *DependencyManager) DiskUsage() (*DiskUsageReport, error) {
+	return AnalyzeNodeModules(dm.workingDir)
+}
+
+// AnalyzeNodeModules 统计dir/node_modules中每个已安装包的大小和文件数
+//
+// dir可以是项目目录，也可以是node_modules目录本身。嵌套的node_modules和scope包都会统计，
+// 符号链接（工作区、pnpm的布局）不计入。
+func AnalyzeNodeModules(dir string) (*DiskUsageReport, error) {
+	projectDir, root := dir, "node_modules"
+	if filepath.Base(filepath.Clean(dir)) == "node_modules" {
+		projectDir = filepath.Dir(filepath.Clean(dir))
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, root)); err != nil {
+		return nil, fmt.Errorf("failed to read node_modules: %w", err)
+	}
+
+	report := &DiskUsageReport{Packages: []PackageUsage{}}
+	err := walkNodeModules(projectDir, root, func(usage PackageUsage) {
+		report.Packages = append(report.Packages, usage)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 总量直接统计整个目录，包括不属于任何包的文件
+	err = filepath.WalkDir(filepath.Join(projectDir, root), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			report.TotalSize += info.Size()
+			report.TotalFiles++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure node_modules: %w", err)
+	}
+
+	sort.Slice(report.Packages, func(i, j int) bool {
+		if report.Packages[i].Size != report.Packages[j].Size {
+			return report.Packages[i].Size > report.Packages[j].Size
+		}
+		return report.Packages[i].Path < report.Packages[j].Path
+	})
+	return report, nil
+}
+
+// walkNodeModules 递归遍历dir（相对projectDir）中安装的包，包括嵌套的node_modules和scope包
+func walkNodeModules(projectDir, dir string, fn func(PackageUsage)) error {
+	entries, err := os.ReadDir(filepath.Join(projectDir, filepath.FromSlash(dir)))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var packageDirs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if strings.HasPrefix(name, "@") {
+			scoped, err := os.ReadDir(filepath.Join(projectDir, filepath.FromSlash(dir), name))
+			if err != nil {
+				return fmt.Errorf("failed to read %s/%s: %w", dir, name, err)
+			}
+			for _, child := range scoped {
+				if child.IsDir() {
+					packageDirs = append(packageDirs, path.Join(dir, name, child.Name()))
+				}
+			}
+			continue
+		}
+		packageDirs = append(packageDirs, path.Join(dir, name))
+	}
+
+	for _, pkgDir := range packageDirs {
+		abs := filepath.Join(projectDir, filepath.FromSlash(pkgDir))
+		data, err := os.ReadFile(filepath.Join(abs, "package.json"))
+		if err != nil {
+			continue
+		}
+		var manifest struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(data, &manifest) != nil || manifest.Version == "" {
+			continue
+		}
+		name := manifest.Name
+		if name == "" {
+			name = strings.TrimPrefix(pkgDir[strings.LastIndex(pkgDir, "node_modules/"):], "node_modules/")
+		}
+
+		size, files, err := packageDiskUsage(abs)
+		if err != nil {
+			return err
+		}
+		fn(PackageUsage{Name: name, Version: manifest.Version, Path: pkgDir, Size: size, Files: files})
+
+		nested := path.Join(pkgDir, "node_modules")
+		if info, err := os.Stat(filepath.Join(projectDir, filepath.FromSlash(nested))); err == nil && info.IsDir() {
+			if err := walkNodeModules(projectDir, nested, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// packageDiskUsage 包目录中文件的总大小和文件数，不包括嵌套的node_modules和符号链接
+func packageDiskUsage(dir string) (int64, int, error) {
+	var size int64
+	var files int
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "node_modules" && p != dir {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+			files++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to measure %s: %w", dir, err)
+	}
+	return size, files, nil
+}
@@ -0,0 +1,65 @@
+package npm
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeNodeModules(t *testing.T) {
+	root := t.TempDir()
+	var total int64
+	write := func(path, content string) int64 {
+		writeTestFile(t, filepath.Join(root, filepath.FromSlash(path)), content)
+		total += int64(len(content))
+		return int64(len(content))
+	}
+	a := write("node_modules/a/package.json", `{"name":"a","version":"1.0.0"}`) + write("node_modules/a/lib/index.js", strings.Repeat("a", 500))
+	b := write("node_modules/a/node_modules/b/package.json", `{"name":"b","version":"2.0.0"}`)
+	c := write("node_modules/@s/c/package.json", `{"name":"@s/c","version":"3.0.0"}`) + write("node_modules/@s/c/dist.js", strings.Repeat("c", 2000))
+	write("node_modules/.bin/tool", "#!/bin/sh\n")
+	write("node_modules/broken/README", "no manifest")
+
+	dm, _ := NewDependencyManager(NewMockClient(), root)
+	report, err := dm.DiskUsage()
+	if err != nil {
+		t.Fatalf("DiskUsage() failed: %v", err)
+	}
+
+	want := []PackageUsage{
+		{Name: "@s/c", Version: "3.0.0", Path: "node_modules/@s/c", Size: c, Files: 2},
+		{Name: "a", Version: "1.0.0", Path: "node_modules/a", Size: a, Files: 2},
+		{Name: "b", Version: "2.0.0", Path: "node_modules/a/node_modules/b", Size: b, Files: 1},
+	}
+	if !reflect.DeepEqual(report.Packages, want) {
+		t.Errorf("Expected packages %+v, got %+v", want, report.Packages)
+	}
+	if report.TotalFiles != 7 || report.TotalSize != total {
+		t.Errorf("Unexpected totals: %d bytes in %d files", report.TotalSize, report.TotalFiles)
+	}
+	if top := report.Top(1); len(top) != 1 || top[0].Name != "@s/c" || len(report.Top(10)) != 3 {
+		t.Errorf("Unexpected Top(): %+v", top)
+	}
+
+	// 也可以直接传入node_modules目录
+	direct, err := AnalyzeNodeModules(filepath.Join(root, "node_modules"))
+	if err != nil || !reflect.DeepEqual(direct, report) {
+		t.Errorf("Expected same report for node_modules dir, got %+v, %v", direct, err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.ExportJSON(&buf); err != nil {
+		t.Fatalf("ExportJSON() failed: %v", err)
+	}
+	var decoded DiskUsageReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || !reflect.DeepEqual(&decoded, report) {
+		t.Errorf("JSON round trip failed: %v\n%s", err, buf.String())
+	}
+
+	if _, err := AnalyzeNodeModules(t.TempDir()); err == nil {
+		t.Error("Expected error without node_modules")
+	}
+}
@@ -0,0 +1,108 @@
+package npm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// DistTagList 列出包的dist-tag，返回tag到版本的映射
+func (c *client) DistTagList(ctx context.Context, pkg string, options DistTagOptions) (map[string]string, error) {
+	executeOptions, err := c.distTagListCommand(pkg, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.runRegistryCommand(ctx, "dist-tag", pkg, executeOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDistTags(result.Stdout), nil
+}
+
+// distTagListCommand 构造npm dist-tag ls的执行选项
+func (c *client) distTagListCommand(pkg string, options DistTagOptions) (utils.ExecuteOptions, error) {
+	if pkg == "" {
+		return utils.ExecuteOptions{}, NewValidationError("package", pkg, "package name cannot be empty")
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "dist-tag", "ls", pkg), nil
+}
+
+// DistTagAdd 将tag指向spec（name@version）
+func (c *client) DistTagAdd(ctx context.Context, spec, tag string, options DistTagOptions) error {
+	executeOptions, err := c.distTagAddCommand(spec, tag, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runRegistryCommand(ctx, "dist-tag", spec, executeOptions)
+	return err
+}
+
+// distTagAddCommand 构造npm dist-tag add的执行选项
+func (c *client) distTagAddCommand(spec, tag string, options DistTagOptions) (utils.ExecuteOptions, error) {
+	if spec == "" || !strings.Contains(spec[1:], "@") {
+		return utils.ExecuteOptions{}, NewValidationError("spec", spec, "spec must be name@version")
+	}
+	if err := validateDistTag(tag); err != nil {
+		return utils.ExecuteOptions{}, err
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "dist-tag", "add", spec, tag), nil
+}
+
+// DistTagRemove 删除包的tag
+func (c *client) DistTagRemove(ctx context.Context, pkg, tag string, options DistTagOptions) error {
+	executeOptions, err := c.distTagRemoveCommand(pkg, tag, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runRegistryCommand(ctx, "dist-tag", pkg, executeOptions)
+	return err
+}
+
+// distTagRemoveCommand 构造npm dist-tag rm的执行选项
+func (c *client) distTagRemoveCommand(pkg, tag string, options DistTagOptions) (utils.ExecuteOptions, error) {
+	if pkg == "" {
+		return utils.ExecuteOptions{}, NewValidationError("package", pkg, "package name cannot be empty")
+	}
+	if err := validateDistTag(tag); err != nil {
+		return utils.ExecuteOptions{}, err
+	}
+	if tag == "latest" {
+		return utils.ExecuteOptions{}, NewValidationError("tag", tag, "the latest tag cannot be removed")
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "dist-tag", "rm", pkg, tag), nil
+}
+
+// validateDistTag 校验tag名称，与npm一致不允许可以解析为版本范围的tag
+func validateDistTag(tag string) error {
+	if tag == "" {
+		return NewValidationError("tag", tag, "tag cannot be empty")
+	}
+	if strings.ContainsAny(tag, " \t\n/@") {
+		return NewValidationError("tag", tag, "tag contains invalid characters")
+	}
+	if semver.ValidRange(tag) {
+		return NewValidationError("tag", tag, "tag must not be a valid semver range")
+	}
+	return nil
+}
+
+// parseDistTags 解析npm dist-tag ls的输出，每行为"tag: version"
+func parseDistTags(output string) map[string]string {
+	tags := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		tag, version, found := strings.Cut(strings.TrimSpace(line), ": ")
+		if found && tag != "" && version != "" {
+			tags[tag] = strings.TrimSpace(version)
+		}
+	}
+	return tags
+}
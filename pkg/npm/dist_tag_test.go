@@ -0,0 +1,44 @@
+package npm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDistTagValidation(t *testing.T) {
+	c := &client{}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"DistTagAdd without version", c.DistTagAdd(ctx, "@scope/pkg", "next", DistTagOptions{})},
+		{"DistTagAdd with range tag", c.DistTagAdd(ctx, "pkg@1.0.0", "v1", DistTagOptions{})},
+		{"DistTagAdd with empty tag", c.DistTagAdd(ctx, "pkg@1.0.0", "", DistTagOptions{})},
+		{"DistTagRemove latest", c.DistTagRemove(ctx, "pkg", "latest", DistTagOptions{})},
+		{"DistTagRemove without package", c.DistTagRemove(ctx, "", "next", DistTagOptions{})},
+	}
+	for _, tt := range tests {
+		if !IsValidationError(tt.err, nil) {
+			t.Errorf("%s: expected validation error, got %v", tt.name, tt.err)
+		}
+	}
+
+	if _, err := c.DistTagList(ctx, "", DistTagOptions{}); !IsValidationError(err, nil) {
+		t.Errorf("DistTagList: expected validation error, got %v", err)
+	}
+	if err := validateDistTag("v1-lts"); err != nil {
+		t.Errorf("Expected v1-lts to be a valid tag, got %v", err)
+	}
+}
+
+func TestParseDistTags(t *testing.T) {
+	tags := parseDistTags("latest: 1.2.3\nnext: 2.0.0-beta.1\n\nv1-lts: 1.9.9\n")
+	if len(tags) != 3 || tags["latest"] != "1.2.3" || tags["next"] != "2.0.0-beta.1" || tags["v1-lts"] != "1.9.9" {
+		t.Errorf("Unexpected tags: %v", tags)
+	}
+	if tags := parseDistTags(""); len(tags) != 0 {
+		t.Errorf("Expected no tags, got %v", tags)
+	}
+}
@@ -0,0 +1,167 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// DoctorCheck npm doctor的单项检查
+type DoctorCheck = npmiface.DoctorCheck
+
+// DoctorResult npm doctor检查结果
+type DoctorResult = npmiface.DoctorResult
+
+// doctorColumnPattern 旧版npm doctor表格输出的列分隔
+var doctorColumnPattern = regexp.MustCompile(`\s{2,}`)
+
+// Doctor 运行npm doctor并解析检查结果
+func (c *client) Doctor(ctx context.Context) (*DoctorResult, error) {
+	executeOptions := utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"doctor", "--color=false"},
+		CaptureOutput: true,
+		Timeout:       5 * time.Minute,
+	}
+
+	// 有检查未通过时npm doctor以非零状态退出，输出中仍然包含所有检查结果
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if result == nil {
+		return nil, NewNpmError("doctor", "", -1, "", "", err)
+	}
+
+	doctor := ParseDoctorOutput(result.Stdout)
+	if len(doctor.Checks) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no checks found in npm doctor output")
+		}
+		return nil, NewNpmError("doctor", "", result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	return doctor, nil
+}
+
+// ParseDoctorOutput 解析npm doctor的输出
+//
+// 支持npm 9及以后的分段格式（检查标题、Ok或Not ok、可选的说明）以及更早版本的表格格式。
+func ParseDoctorOutput(output string) *DoctorResult {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "Check") && strings.Contains(line, "Value") {
+			return parseDoctorTable(lines)
+		}
+	}
+
+	result := &DoctorResult{OK: true}
+	var current *DoctorCheck
+	var detail []string
+	flush := func() {
+		if current != nil {
+			current.Detail = strings.TrimSpace(strings.Join(detail, "\n"))
+			result.Checks = append(result.Checks, *current)
+			if !current.OK {
+				result.OK = false
+			}
+		}
+		current = nil
+		detail = nil
+	}
+
+	title := ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case current == nil && (trimmed == "Ok" || trimmed == "Not ok"):
+			current = &DoctorCheck{Title: title, OK: trimmed == "Ok"}
+		case current == nil:
+			if trimmed != "" {
+				title = trimmed
+			}
+		case trimmed == "":
+			flush()
+			title = ""
+		default:
+			detail = append(detail, trimmed)
+		}
+	}
+	flush()
+
+	return result
+}
+
+// parseDoctorTable 解析旧版npm doctor的表格输出
+func parseDoctorTable(lines []string) *DoctorResult {
+	result := &DoctorResult{OK: true}
+	header := true
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if header {
+			header = !strings.HasPrefix(trimmed, "Check")
+			continue
+		}
+
+		columns := doctorColumnPattern.Split(trimmed, -1)
+		if len(columns) < 2 {
+			continue
+		}
+		check := DoctorCheck{
+			Title:  columns[0],
+			OK:     !strings.HasPrefix(strings.ToLower(columns[1]), "not ok"),
+			Detail: strings.Join(columns[1:], " "),
+		}
+		if !check.OK {
+			result.OK = false
+		}
+		result.Checks = append(result.Checks, check)
+	}
+	return result
+}
+
+// Ping 检查registry连通性并返回延迟，registry为空时使用npm配置的registry
+func (c *client) Ping(ctx context.Context, registry string) (time.Duration, error) {
+	args := []string{"ping", "--json"}
+	if registry != "" {
+		args = append(args, "--registry", registry)
+	}
+
+	executeOptions := utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          args,
+		CaptureOutput: true,
+		Timeout:       30 * time.Second,
+	}
+
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return 0, NewNpmError("ping", registry, -1, "", "", err)
+		}
+		return 0, NewNpmError("ping", registry, result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	if latency, ok := parsePingJSON(result.Stdout); ok {
+		return latency, nil
+	}
+	// 旧版npm不支持--json，使用命令耗时作为近似值
+	return result.Duration, nil
+}
+
+// parsePingJSON 解析npm ping --json输出中的耗时（毫秒）
+func parsePingJSON(output string) (time.Duration, bool) {
+	var ping struct {
+		Time *float64 `json:"time"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &ping); err != nil || ping.Time == nil {
+		return 0, false
+	}
+	return time.Duration(*ping.Time * float64(time.Millisecond)), true
+}
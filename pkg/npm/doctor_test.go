@@ -0,0 +1,79 @@
+package npm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDoctorOutput(t *testing.T) {
+	output := `Connecting to the registry
+Ok
+
+Checking npm version
+Not ok
+Use npm v10.9.0
+
+Checking node version
+Ok
+current: v20.11.0, recommended: v20.11.0
+
+Checking for git executable in PATH
+Ok
+/usr/bin/git
+
+`
+	result := ParseDoctorOutput(output)
+	if result.OK {
+		t.Error("Expected overall result to fail")
+	}
+	if len(result.Checks) != 4 {
+		t.Fatalf("Expected 4 checks, got %+v", result.Checks)
+	}
+	if result.Checks[0].Title != "Connecting to the registry" || !result.Checks[0].OK || result.Checks[0].Detail != "" {
+		t.Errorf("Unexpected first check: %+v", result.Checks[0])
+	}
+	if result.Checks[2].Detail != "current: v20.11.0, recommended: v20.11.0" {
+		t.Errorf("Unexpected detail: %q", result.Checks[2].Detail)
+	}
+
+	failed := result.Failed()
+	if len(failed) != 1 || failed[0].Title != "Checking npm version" || failed[0].Detail != "Use npm v10.9.0" {
+		t.Errorf("Unexpected failed checks: %+v", failed)
+	}
+}
+
+func TestParseDoctorOutputTable(t *testing.T) {
+	output := `Check                               Value   Recommendation/Notes
+npm ping                            ok
+npm -v                              not ok  Use npm v8.19.4
+node -v                             ok      current: v16.20.2, recommended: v16.20.2
+Perms check on cached files         ok
+`
+	result := ParseDoctorOutput(output)
+	if result.OK || len(result.Checks) != 4 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result.Checks[1].Title != "npm -v" || result.Checks[1].OK || result.Checks[1].Detail != "not ok Use npm v8.19.4" {
+		t.Errorf("Unexpected check: %+v", result.Checks[1])
+	}
+	if !result.Checks[3].OK {
+		t.Errorf("Expected cached files check to pass: %+v", result.Checks[3])
+	}
+
+	if empty := ParseDoctorOutput(""); !empty.OK || len(empty.Checks) != 0 {
+		t.Errorf("Expected no checks for empty output, got %+v", empty)
+	}
+}
+
+func TestParsePingJSON(t *testing.T) {
+	latency, ok := parsePingJSON(`{"registry": "https://registry.npmjs.org/", "time": 123, "details": {}}`)
+	if !ok || latency != 123*time.Millisecond {
+		t.Errorf("Expected 123ms, got %v, %v", latency, ok)
+	}
+	if _, ok := parsePingJSON(`{}`); ok {
+		t.Error("Expected missing time to fail")
+	}
+	if _, ok := parsePingJSON(`PONG 12ms`); ok {
+		t.Error("Expected non-JSON output to fail")
+	}
+}
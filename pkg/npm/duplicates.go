@@ -0,0 +1,182 @@
+package npm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+)
+
+// DuplicateFix 消除重复安装的方式
+type DuplicateFix string
+
+const (
+	DuplicateFixDedupe   DuplicateFix = "dedupe"   // 所有请求的范围都能满足同一个版本，npm dedupe可以合并
+	DuplicateFixOverride DuplicateFix = "override" // 范围互相冲突，需要通过overrides强制使用同一个版本
+)
+
+// DuplicateInstance 包在node_modules中的一份安装
+type DuplicateInstance struct {
+	Version string `json:"version"`
+	Path    string `json:"path"` // 相对项目目录，使用/分隔，例如node_modules/a/node_modules/lodash
+	Size    int64  `json:"size"` // 磁盘占用的字节数，不包括嵌套的node_modules
+}
+
+// DuplicateRequest 锁文件中对该包的一次依赖请求
+type DuplicateRequest struct {
+	From  string `json:"from"` // 声明依赖的包name@version，项目本身为"."
+	Range string `json:"range"`
+}
+
+// DuplicatePackage 被安装了多份的包
+type DuplicatePackage struct {
+	Name      string              `json:"name"`
+	Versions  []string            `json:"versions"` // 已安装的版本，按semver排序
+	Instances []DuplicateInstance `json:"instances"`
+	Requests  []DuplicateRequest  `json:"requests,omitempty"` // 没有锁文件时为空
+	Size      int64               `json:"size"`               // 所有安装的总大小
+
+	// Fix 建议的处理方式，Target为建议统一使用的版本，Savings为统一后可以节省的字节数
+	Fix     DuplicateFix `json:"fix"`
+	Target  string       `json:"target"`
+	Savings int64        `json:"savings"`
+
+	// Conflicts 不满足Target的请求，Fix为override时才有
+	Conflicts []DuplicateRequest `json:"conflicts,omitempty"`
+}
+
+// DuplicateReport 重复安装报告，包按可以节省的空间从大到小排序
+type DuplicateReport struct {
+	Packages []DuplicatePackage `json:"packages"`
+	Size     int64              `json:"size"`    // 重复安装的包的总大小
+	Savings  int64              `json:"savings"` // 全部处理后可以节省的字节数
+}
+
+// Overrides 返回Fix为override的包应写入package.json overrides字段的版本
+func (r *DuplicateReport) Overrides() map[string]string {
+	overrides := make(map[string]string)
+	for _, pkg := range r.Packages {
+		if pkg.Fix == DuplicateFixOverride {
+			overrides[pkg.Name] = pkg.Target
+		}
+	}
+	return overrides
+}
+
+// Duplicates 分析项目中被安装了多份的包
+func (dm *DependencyManager) Duplicates() (*DuplicateReport, error) {
+	return AnalyzeDuplicates(dm.workingDir)
+}
+
+// AnalyzeDuplicates 扫描projectDir/node_modules，找出被安装了多份的包并计算磁盘占用
+//
+// 有锁文件时根据各包请求的版本范围给出建议：存在满足所有范围的已安装版本时建议npm dedupe，
+// 否则建议通过overrides统一为已安装的最高版本。符号链接（工作区、pnpm的布局）不计入。
+func AnalyzeDuplicates(projectDir string) (*DuplicateReport, error) {
+	root := filepath.Join(projectDir, "node_modules")
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("failed to read node_modules: %w", err)
+	}
+
+	instances := make(map[string][]DuplicateInstance)
+	err := walkNodeModules(projectDir, "node_modules", func(usage PackageUsage) {
+		instances[usage.Name] = append(instances[usage.Name], DuplicateInstance{Version: usage.Version, Path: usage.Path, Size: usage.Size})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make(map[string][]DuplicateRequest)
+	if lock := loadProjectLockfile(projectDir); lock != nil {
+		add := func(from string, deps map[string]string) {
+			for name, rng := range deps {
+				if len(instances[name]) > 1 {
+					requests[name] = append(requests[name], DuplicateRequest{From: from, Range: rng})
+				}
+			}
+		}
+		if lock.Root != nil {
+			add(".", lock.Root.Dependencies)
+			add(".", lock.Root.DevDependencies)
+			add(".", lock.Root.OptionalDependencies)
+		}
+		for _, pkg := range lock.Packages {
+			add(pkg.Key(), pkg.Dependencies)
+		}
+	}
+
+	report := &DuplicateReport{Packages: []DuplicatePackage{}}
+	for name, list := range instances {
+		if len(list) < 2 {
+			continue
+		}
+		pkg := DuplicatePackage{Name: name, Instances: list, Requests: requests[name]}
+		sort.Slice(pkg.Instances, func(i, j int) bool { return pkg.Instances[i].Path < pkg.Instances[j].Path })
+		sort.Slice(pkg.Requests, func(i, j int) bool {
+			if pkg.Requests[i].From != pkg.Requests[j].From {
+				return pkg.Requests[i].From < pkg.Requests[j].From
+			}
+			return pkg.Requests[i].Range < pkg.Requests[j].Range
+		})
+		for _, instance := range list {
+			pkg.Size += instance.Size
+			if !slices.Contains(pkg.Versions, instance.Version) {
+				pkg.Versions = append(pkg.Versions, instance.Version)
+			}
+		}
+		semver.Sort(pkg.Versions)
+		suggestDuplicateFix(&pkg)
+
+		report.Packages = append(report.Packages, pkg)
+		report.Size += pkg.Size
+		report.Savings += pkg.Savings
+	}
+
+	sort.Slice(report.Packages, func(i, j int) bool {
+		if report.Packages[i].Savings != report.Packages[j].Savings {
+			return report.Packages[i].Savings > report.Packages[j].Savings
+		}
+		return report.Packages[i].Name < report.Packages[j].Name
+	})
+	return report, nil
+}
+
+// suggestDuplicateFix 选择满足所有请求的最高已安装版本，没有时改为override到最高版本
+func suggestDuplicateFix(pkg *DuplicatePackage) {
+	pkg.Fix = DuplicateFixOverride
+	pkg.Target = pkg.Versions[len(pkg.Versions)-1]
+	for i := len(pkg.Versions) - 1; i >= 0; i-- {
+		satisfied := true
+		for _, request := range pkg.Requests {
+			if !semver.Satisfies(pkg.Versions[i], request.Range) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			pkg.Fix = DuplicateFixDedupe
+			pkg.Target = pkg.Versions[i]
+			break
+		}
+	}
+
+	if pkg.Fix == DuplicateFixOverride {
+		for _, request := range pkg.Requests {
+			if !semver.Satisfies(pkg.Target, request.Range) {
+				pkg.Conflicts = append(pkg.Conflicts, request)
+			}
+		}
+	}
+
+	// 统一后只保留一份目标版本
+	var kept int64
+	for _, instance := range pkg.Instances {
+		if instance.Version == pkg.Target {
+			kept = max(kept, instance.Size)
+		}
+	}
+	pkg.Savings = pkg.Size - kept
+}
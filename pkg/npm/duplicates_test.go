@@ -0,0 +1,86 @@
+package npm
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeDuplicates(t *testing.T) {
+	root := t.TempDir()
+	install := func(dir, name, version string, size int) int64 {
+		manifest := `{"name":"` + name + `","version":"` + version + `"}`
+		writeTestFile(t, filepath.Join(root, dir, "package.json"), manifest)
+		writeTestFile(t, filepath.Join(root, dir, "index.js"), strings.Repeat("x", size))
+		return int64(len(manifest) + size)
+	}
+	lodash4 := install("node_modules/lodash", "lodash", "4.17.21", 1000)
+	lodash420 := install("node_modules/a/node_modules/lodash", "lodash", "4.17.20", 900)
+	lodash3 := install("node_modules/@s/b/node_modules/lodash", "lodash", "3.10.1", 500)
+	ms3 := install("node_modules/ms", "ms", "2.1.3", 100)
+	ms2 := install("node_modules/a/node_modules/ms", "ms", "2.1.2", 100)
+	install("node_modules/a", "a", "1.0.0", 10)
+	install("node_modules/@s/b", "@s/b", "1.0.0", 10)
+	writeTestFile(t, filepath.Join(root, "node_modules", ".package-lock.json"), `{}`)
+
+	writeTestFile(t, filepath.Join(root, "package-lock.json"), `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"a": "^1.0.0", "@s/b": "^1.0.0", "lodash": "^4.17.0", "ms": "^2.1.0"}},
+    "node_modules/a": {"version": "1.0.0", "dependencies": {"lodash": "~4.17.20", "ms": "2.1.2 || 2.1.3"}},
+    "node_modules/a/node_modules/lodash": {"version": "4.17.20"},
+    "node_modules/a/node_modules/ms": {"version": "2.1.2"},
+    "node_modules/@s/b": {"version": "1.0.0", "dependencies": {"lodash": "^3.0.0"}},
+    "node_modules/@s/b/node_modules/lodash": {"version": "3.10.1"},
+    "node_modules/lodash": {"version": "4.17.21"},
+    "node_modules/ms": {"version": "2.1.3"}
+  }
+}`)
+
+	dm, _ := NewDependencyManager(NewMockClient(), root)
+	report, err := dm.Duplicates()
+	if err != nil {
+		t.Fatalf("Duplicates() failed: %v", err)
+	}
+	if len(report.Packages) != 2 {
+		t.Fatalf("Expected 2 duplicated packages, got %+v", report.Packages)
+	}
+
+	lodash := report.Packages[0]
+	if lodash.Name != "lodash" || !reflect.DeepEqual(lodash.Versions, []string{"3.10.1", "4.17.20", "4.17.21"}) {
+		t.Errorf("Unexpected lodash entry: %+v", lodash)
+	}
+	if lodash.Fix != DuplicateFixOverride || lodash.Target != "4.17.21" {
+		t.Errorf("Expected override to 4.17.21, got %s %s", lodash.Fix, lodash.Target)
+	}
+	if want := []DuplicateRequest{{From: "@s/b@1.0.0", Range: "^3.0.0"}}; !reflect.DeepEqual(lodash.Conflicts, want) {
+		t.Errorf("Expected conflicts %+v, got %+v", want, lodash.Conflicts)
+	}
+	if lodash.Size != lodash4+lodash420+lodash3 || lodash.Savings != lodash420+lodash3 {
+		t.Errorf("Unexpected lodash sizes: size=%d savings=%d", lodash.Size, lodash.Savings)
+	}
+	if lodash.Instances[0].Path != "node_modules/@s/b/node_modules/lodash" {
+		t.Errorf("Expected instances sorted by path, got %+v", lodash.Instances)
+	}
+
+	ms := report.Packages[1]
+	if ms.Name != "ms" || ms.Fix != DuplicateFixDedupe || ms.Target != "2.1.3" || len(ms.Conflicts) != 0 {
+		t.Errorf("Expected dedupe of ms to 2.1.3, got %+v", ms)
+	}
+	if ms.Savings != ms2 || ms.Size != ms2+ms3 {
+		t.Errorf("Unexpected ms sizes: size=%d savings=%d", ms.Size, ms.Savings)
+	}
+
+	if report.Savings != lodash.Savings+ms.Savings {
+		t.Errorf("Expected total savings %d, got %d", lodash.Savings+ms.Savings, report.Savings)
+	}
+	if overrides := report.Overrides(); !reflect.DeepEqual(overrides, map[string]string{"lodash": "4.17.21"}) {
+		t.Errorf("Unexpected overrides: %v", overrides)
+	}
+
+	if _, err := AnalyzeDuplicates(t.TempDir()); err == nil {
+		t.Error("Expected error without node_modules")
+	}
+}
@@ -0,0 +1,240 @@
+package npm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PeerConflict 从ERESOLVE错误中解析出的依赖冲突
+//
+// npm没有为ERESOLVE提供结构化的输出，--json的error.detail和stderr中都只有说明文字，
+// 这里按npm的说明格式（While resolving/Found/Could not resolve dependency/
+// Conflicting peer dependency）解析。
+type PeerConflict struct {
+	WhileResolving string         `json:"while_resolving,omitempty"` // 正在解析的包，例如my-app@1.0.0
+	Requested      ConflictEdge   `json:"requested"`                 // 无法满足的依赖请求
+	Current        ConflictNode   `json:"current"`                   // 树中已有的版本
+	Conflicting    *ConflictNode  `json:"conflicting,omitempty"`     // 与请求冲突的peer依赖，没有时为nil
+	Dependers      []ConflictEdge `json:"dependers,omitempty"`       // 依赖已有版本的包
+	RetryFlags     []string       `json:"retry_flags,omitempty"`     // npm建议的重试参数，例如--legacy-peer-deps
+	SuggestedFixes []string       `json:"suggested_fixes,omitempty"` // 可以展示给用户的修复建议
+	Explanation    string         `json:"explanation"`               // npm的原始说明
+}
+
+// ConflictNode 冲突中涉及的已安装包
+type ConflictNode struct {
+	Name       string         `json:"name"`
+	Version    string         `json:"version,omitempty"`
+	Location   string         `json:"location,omitempty"` // 例如node_modules/react
+	Dependents []ConflictEdge `json:"dependents,omitempty"`
+}
+
+// ConflictEdge 冲突中涉及的依赖关系
+type ConflictEdge struct {
+	Type         string `json:"type"` // prod、dev、optional、peer、peerOptional或workspace
+	Name         string `json:"name"`
+	Spec         string `json:"spec"`                    // 请求的版本范围
+	From         string `json:"from,omitempty"`          // 依赖方，例如react-dom@18.3.1，根项目为空
+	FromLocation string `json:"from_location,omitempty"` // 依赖方的位置
+}
+
+// String 返回npm说明中的格式，例如peer react@"^18.3.1" from react-dom@18.3.1
+func (e ConflictEdge) String() string {
+	prefix := ""
+	if e.Type != "" && e.Type != "prod" {
+		prefix = e.Type + " "
+	}
+	return fmt.Sprintf("%s%s@%q from %s", prefix, e.Name, e.Spec, e.edgeSource())
+}
+
+// conflictEdgePattern 匹配依赖关系行，例如peer react@"^18.3.1" from react-dom@18.3.1
+var conflictEdgePattern = regexp.MustCompile(`^(?:(dev|optional|peer|peerOptional|workspace) )?(\S+?)@"([^"]*)" from (.+)$`)
+
+// retryFlagPattern 匹配修复说明中的命令行参数
+var retryFlagPattern = regexp.MustCompile(`--[a-z][a-z-]*`)
+
+// rootProject npm对根项目的称呼
+const rootProject = "the root project"
+
+// ParsePeerConflict 解析ERESOLVE错误的输出，不是ERESOLVE错误或无法识别时返回nil
+func ParsePeerConflict(stdout, stderr string) *PeerConflict {
+	output := ParseErrorOutput(stdout, stderr)
+	if output == nil || output.Code != "ERESOLVE" {
+		return nil
+	}
+	return parsePeerConflictExplanation(output.Detail)
+}
+
+// ExtractPeerConflict 从错误链中的NpmError解析ERESOLVE冲突
+func ExtractPeerConflict(err error) (*PeerConflict, bool) {
+	var npmErr *NpmError
+	if !errors.As(err, &npmErr) {
+		return nil, false
+	}
+	conflict := npmErr.PeerConflict()
+	return conflict, conflict != nil
+}
+
+// PeerConflict 解析ERESOLVE冲突，不是ERESOLVE错误时返回nil
+func (e *NpmError) PeerConflict() *PeerConflict {
+	return ParsePeerConflict(e.Stdout, e.Stderr)
+}
+
+// parsePeerConflictExplanation 解析npm的ERESOLVE说明文字
+func parsePeerConflictExplanation(explanation string) *PeerConflict {
+	conflict := &PeerConflict{Explanation: strings.TrimSpace(explanation)}
+	lines := strings.Split(explanation, "\n")
+
+	found := false
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \r")
+		switch {
+		case strings.HasPrefix(line, "While resolving: "):
+			conflict.WhileResolving = strings.TrimSpace(strings.TrimPrefix(line, "While resolving: "))
+		case strings.HasPrefix(line, "Found: "):
+			conflict.Current, i = parseConflictNode(strings.TrimPrefix(line, "Found: "), lines, i+1)
+			found = true
+		case line == "Could not resolve dependency:" && i+1 < len(lines):
+			edge, ok := parseConflictEdge(lines[i+1])
+			if !ok {
+				continue
+			}
+			i++
+			if i+1 < len(lines) && isConflictLocation(lines[i+1]) {
+				i++
+				edge.FromLocation = strings.TrimSpace(lines[i])
+			}
+			conflict.Requested = edge
+		case strings.HasPrefix(line, "Conflicting peer dependency: "):
+			var node ConflictNode
+			node, i = parseConflictNode(strings.TrimPrefix(line, "Conflicting peer dependency: "), lines, i+1)
+			conflict.Conflicting = &node
+		case strings.HasPrefix(line, "Fix the upstream dependency conflict"):
+			// 修复说明到空行为止
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				for _, flag := range retryFlagPattern.FindAllString(lines[i], -1) {
+					conflict.RetryFlags = appendUnique(conflict.RetryFlags, flag)
+				}
+			}
+		}
+	}
+
+	if !found || conflict.Requested.Name == "" {
+		return nil
+	}
+	conflict.Dependers = conflict.Current.Dependents
+	conflict.SuggestedFixes = suggestConflictFixes(conflict)
+	return conflict
+}
+
+// parseConflictNode 解析"react@17.0.2"形式的包及其后的位置和依赖方，返回最后使用的行
+func parseConflictNode(text string, lines []string, next int) (ConflictNode, int) {
+	var node ConflictNode
+	text = strings.TrimSpace(text)
+
+	// 树中没有该包时npm给出的是依赖关系
+	if edge, ok := parseConflictEdge(text); ok {
+		node.Name = edge.Name
+		node.Dependents = []ConflictEdge{edge}
+	} else if fields := strings.Fields(text); len(fields) > 0 {
+		spec := fields[0]
+		if at := strings.LastIndex(spec, "@"); at > 0 {
+			node.Name, node.Version = spec[:at], spec[at+1:]
+		} else {
+			node.Name = spec
+		}
+	}
+
+	last := next - 1
+	if next < len(lines) && isConflictLocation(lines[next]) {
+		node.Location = strings.TrimSpace(lines[next])
+		last = next
+	}
+
+	// 缩进两格的行是直接依赖方，更深的缩进是依赖方自己的依赖链
+	for i := last + 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \r")
+		if !strings.HasPrefix(line, "  ") {
+			break
+		}
+		last = i
+		if strings.HasPrefix(line, "   ") {
+			continue
+		}
+		edge, ok := parseConflictEdge(line)
+		if !ok {
+			continue
+		}
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "  ") && isConflictLocation(lines[i+1]) {
+			edge.FromLocation = strings.TrimSpace(lines[i+1])
+		}
+		node.Dependents = append(node.Dependents, edge)
+	}
+	return node, last
+}
+
+// parseConflictEdge 解析依赖关系行
+func parseConflictEdge(line string) (ConflictEdge, bool) {
+	match := conflictEdgePattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return ConflictEdge{}, false
+	}
+	edge := ConflictEdge{Type: match[1], Name: match[2], Spec: match[3], From: match[4]}
+	if edge.Type == "" {
+		edge.Type = "prod"
+	}
+	if edge.From == rootProject {
+		edge.From = ""
+	}
+	return edge, true
+}
+
+// isConflictLocation 检查是否为包位置行，例如node_modules/react或packages/a
+func isConflictLocation(line string) bool {
+	line = strings.TrimSpace(line)
+	return line != "" && !strings.Contains(line, " ") && (strings.HasPrefix(line, "node_modules/") || strings.Contains(line, "/"))
+}
+
+// suggestConflictFixes 根据冲突生成修复建议
+func suggestConflictFixes(conflict *PeerConflict) []string {
+	var fixes []string
+	requested := conflict.Requested
+	current := conflict.Current
+
+	if current.Version != "" {
+		fixes = append(fixes, fmt.Sprintf("change %s from %s to a version matching %q (required by %s)",
+			current.Name, current.Version, requested.Spec, requested.edgeSource()))
+		if requested.From != "" {
+			fixes = append(fixes, fmt.Sprintf("use a version of %s that accepts %s@%s",
+				packageNameOf(requested.From), current.Name, current.Version))
+		}
+	} else {
+		fixes = append(fixes, fmt.Sprintf("install %s@%q (required by %s)", requested.Name, requested.Spec, requested.edgeSource()))
+	}
+	if conflict.Conflicting != nil && conflict.Conflicting.Name != current.Name {
+		fixes = append(fixes, fmt.Sprintf("align the %s version required by %s", conflict.Conflicting.Name, requested.edgeSource()))
+	}
+
+	for _, flag := range conflict.RetryFlags {
+		fixes = append(fixes, "retry with "+flag+" to accept a possibly broken dependency tree")
+	}
+	return fixes
+}
+
+// edgeSource 依赖方的描述
+func (e ConflictEdge) edgeSource() string {
+	if e.From == "" {
+		return rootProject
+	}
+	return e.From
+}
+
+// packageNameOf 去掉name@version中的版本
+func packageNameOf(spec string) string {
+	if at := strings.LastIndex(spec, "@"); at > 0 {
+		return spec[:at]
+	}
+	return spec
+}
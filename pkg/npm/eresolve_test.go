@@ -0,0 +1,168 @@
+package npm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/fixtures"
+)
+
+// testStrictPeerConflict npm在strict-peer-deps下报告的peer依赖冲突
+const testStrictPeerConflict = `While resolving: @scope/app@1.0.0
+Found: react@18.3.1
+node_modules/react
+  react@"^18.0.0" from the root project
+  peer react@">=16" from @testing-library/react@14.0.0
+  node_modules/@testing-library/react
+    dev @testing-library/react@"^14.0.0" from the root project
+
+Could not resolve dependency:
+peerOptional react@"^17.0.0" from legacy-ui@2.1.0
+node_modules/legacy-ui
+  legacy-ui@"^2.1.0" from the root project
+
+Conflicting peer dependency: react@17.0.2
+node_modules/react
+  peerOptional react@"^17.0.0" from legacy-ui@2.1.0
+  node_modules/legacy-ui
+    legacy-ui@"^2.1.0" from the root project
+
+Fix the upstream dependency conflict, or retry
+this command with --no-strict-peer-deps, --force, or --legacy-peer-deps
+to accept an incorrect (and potentially broken) dependency resolution.`
+
+func TestParsePeerConflictCorpus(t *testing.T) {
+	for _, name := range []string{"eresolve", "eresolve-json"} {
+		t.Run(name, func(t *testing.T) {
+			samples, err := fixtures.Samples(fixtures.KindError)
+			if err != nil {
+				t.Fatalf("Samples() failed: %v", err)
+			}
+			var sample *fixtures.Sample
+			for i := range samples {
+				if samples[i].ID() == "npm-10.8.2/error/"+name {
+					sample = &samples[i]
+				}
+			}
+			if sample == nil {
+				t.Fatalf("Sample %s not found", name)
+			}
+
+			conflict := ParsePeerConflict(sample.Stdout, sample.Stderr)
+			if conflict == nil {
+				t.Fatal("Expected peer conflict")
+			}
+			if conflict.WhileResolving != "peer@1.0.0" {
+				t.Errorf("Unexpected WhileResolving %q", conflict.WhileResolving)
+			}
+			expectedCurrent := ConflictNode{
+				Name:       "react",
+				Version:    "17.0.2",
+				Location:   "node_modules/react",
+				Dependents: []ConflictEdge{{Type: "prod", Name: "react", Spec: "17.0.2"}},
+			}
+			if !reflect.DeepEqual(conflict.Current, expectedCurrent) {
+				t.Errorf("Unexpected current: %+v", conflict.Current)
+			}
+			expectedRequested := ConflictEdge{Type: "peer", Name: "react", Spec: "^18.3.1", From: "react-dom@18.3.1", FromLocation: "node_modules/react-dom"}
+			if conflict.Requested != expectedRequested {
+				t.Errorf("Unexpected requested: %+v", conflict.Requested)
+			}
+			if !reflect.DeepEqual(conflict.Dependers, expectedCurrent.Dependents) {
+				t.Errorf("Unexpected dependers: %+v", conflict.Dependers)
+			}
+			if !reflect.DeepEqual(conflict.RetryFlags, []string{"--force", "--legacy-peer-deps"}) {
+				t.Errorf("Unexpected retry flags: %v", conflict.RetryFlags)
+			}
+			if conflict.Conflicting != nil {
+				t.Errorf("Expected no conflicting peer, got %+v", conflict.Conflicting)
+			}
+			if len(conflict.SuggestedFixes) != 4 || !strings.Contains(conflict.SuggestedFixes[0], `change react from 17.0.2 to a version matching "^18.3.1" (required by react-dom@18.3.1)`) ||
+				conflict.SuggestedFixes[1] != "use a version of react-dom that accepts react@17.0.2" {
+				t.Errorf("Unexpected suggested fixes: %q", conflict.SuggestedFixes)
+			}
+		})
+	}
+}
+
+func TestParsePeerConflictStrict(t *testing.T) {
+	stderr := "npm error code ERESOLVE\nnpm error ERESOLVE could not resolve\nnpm error\n" +
+		"npm error " + strings.ReplaceAll(testStrictPeerConflict, "\n", "\nnpm error ") + "\n"
+
+	conflict := ParsePeerConflict("", stderr)
+	if conflict == nil {
+		t.Fatal("Expected peer conflict")
+	}
+	if conflict.WhileResolving != "@scope/app@1.0.0" {
+		t.Errorf("Unexpected WhileResolving %q", conflict.WhileResolving)
+	}
+
+	expectedDependers := []ConflictEdge{
+		{Type: "prod", Name: "react", Spec: "^18.0.0"},
+		{Type: "peer", Name: "react", Spec: ">=16", From: "@testing-library/react@14.0.0", FromLocation: "node_modules/@testing-library/react"},
+	}
+	if !reflect.DeepEqual(conflict.Dependers, expectedDependers) {
+		t.Errorf("Unexpected dependers: %+v", conflict.Dependers)
+	}
+	if conflict.Requested.Type != "peerOptional" || conflict.Requested.From != "legacy-ui@2.1.0" {
+		t.Errorf("Unexpected requested: %+v", conflict.Requested)
+	}
+	if conflict.Conflicting == nil || conflict.Conflicting.Version != "17.0.2" || len(conflict.Conflicting.Dependents) != 1 {
+		t.Errorf("Unexpected conflicting peer: %+v", conflict.Conflicting)
+	}
+	if !reflect.DeepEqual(conflict.RetryFlags, []string{"--no-strict-peer-deps", "--force", "--legacy-peer-deps"}) {
+		t.Errorf("Unexpected retry flags: %v", conflict.RetryFlags)
+	}
+	if conflict.Explanation != testStrictPeerConflict {
+		t.Errorf("Unexpected explanation:\n%s", conflict.Explanation)
+	}
+
+	// 依赖关系按npm的格式输出
+	if got := conflict.Requested.String(); got != `peerOptional react@"^17.0.0" from legacy-ui@2.1.0` {
+		t.Errorf("Unexpected String(): %s", got)
+	}
+	if got := conflict.Dependers[0].String(); got != `react@"^18.0.0" from the root project` {
+		t.Errorf("Unexpected String(): %s", got)
+	}
+}
+
+func TestExtractPeerConflict(t *testing.T) {
+	stderr := "npm error code ERESOLVE\nnpm error ERESOLVE unable to resolve dependency tree\nnpm error\nnpm error " +
+		strings.ReplaceAll(testStrictPeerConflict, "\n", "\nnpm error ") + "\n"
+	err := fmt.Errorf("setup failed: %w", NewNpmError("install", "", 1, "", stderr, errors.New("exit status 1")))
+
+	conflict, ok := ExtractPeerConflict(err)
+	if !ok || conflict.Requested.Name != "react" {
+		t.Errorf("Expected conflict, got %+v, %v", conflict, ok)
+	}
+	if !IsPeerDepConflict(err) {
+		t.Error("Expected IsPeerDepConflict to return true")
+	}
+
+	// 其它错误
+	notFound := NewNpmError("install", "x", 1, "", "npm error code E404\nnpm error 404 Not Found\n", errors.New("exit status 1"))
+	if conflict, ok := ExtractPeerConflict(notFound); ok || conflict != nil {
+		t.Errorf("Expected no conflict for E404, got %+v", conflict)
+	}
+	if _, ok := ExtractPeerConflict(errors.New("plain")); ok {
+		t.Error("Expected no conflict for plain error")
+	}
+	// 无法识别的说明
+	if conflict := ParsePeerConflict("", "npm error code ERESOLVE\nnpm error something new\n"); conflict != nil {
+		t.Errorf("Expected nil for unrecognized explanation, got %+v", conflict)
+	}
+}
+
+func FuzzParsePeerConflict(f *testing.F) {
+	f.Add(testStrictPeerConflict)
+	f.Add("Found: \t\nCould not resolve dependency:\nreact@\"1\" from the root project\n")
+	f.Fuzz(func(t *testing.T, explanation string) {
+		conflict := parsePeerConflictExplanation(explanation)
+		if conflict != nil && conflict.Requested.Name == "" {
+			t.Errorf("Expected requested dependency, got %+v", conflict)
+		}
+	})
+}
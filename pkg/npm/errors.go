@@ -3,53 +3,109 @@ package npm
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/platform"
 )
 
 // 预定义错误
 var (
 	// ErrNpmNotFound npm未找到
-	ErrNpmNotFound = errors.New("npm not found")
+	ErrNpmNotFound = npmiface.ErrNpmNotFound
 
 	// ErrNpmNotInstalled npm未安装
-	ErrNpmNotInstalled = errors.New("npm is not installed")
+	ErrNpmNotInstalled = npmiface.ErrNpmNotInstalled
 
 	// ErrInvalidPackageName 无效的包名
-	ErrInvalidPackageName = errors.New("invalid package name")
+	ErrInvalidPackageName = npmiface.ErrInvalidPackageName
 
 	// ErrPackageNotFound 包未找到
-	ErrPackageNotFound = errors.New("package not found")
+	ErrPackageNotFound = npmiface.ErrPackageNotFound
 
 	// ErrPackageAlreadyExists 包已存在
-	ErrPackageAlreadyExists = errors.New("package already exists")
+	ErrPackageAlreadyExists = npmiface.ErrPackageAlreadyExists
 
 	// ErrInvalidVersion 无效版本
-	ErrInvalidVersion = errors.New("invalid version")
+	ErrInvalidVersion = npmiface.ErrInvalidVersion
 
 	// ErrNetworkError 网络错误
-	ErrNetworkError = errors.New("network error")
+	ErrNetworkError = npmiface.ErrNetworkError
 
 	// ErrPermissionDenied 权限被拒绝
-	ErrPermissionDenied = errors.New("permission denied")
+	ErrPermissionDenied = npmiface.ErrPermissionDenied
 
 	// ErrInvalidWorkingDirectory 无效的工作目录
-	ErrInvalidWorkingDirectory = errors.New("invalid working directory")
+	ErrInvalidWorkingDirectory = npmiface.ErrInvalidWorkingDirectory
 
 	// ErrCommandTimeout 命令超时
-	ErrCommandTimeout = errors.New("command timeout")
+	ErrCommandTimeout = npmiface.ErrCommandTimeout
 
 	// ErrInvalidPackageJSON 无效的package.json
-	ErrInvalidPackageJSON = errors.New("invalid package.json")
+	ErrInvalidPackageJSON = npmiface.ErrInvalidPackageJSON
 
 	// ErrRegistryError registry错误
-	ErrRegistryError = errors.New("registry error")
+	ErrRegistryError = npmiface.ErrRegistryError
 
 	// ErrAuthenticationFailed 认证失败
-	ErrAuthenticationFailed = errors.New("authentication failed")
+	ErrAuthenticationFailed = npmiface.ErrAuthenticationFailed
 
 	// ErrUnsupportedPlatform 不支持的平台
-	ErrUnsupportedPlatform = errors.New("unsupported platform")
+	ErrUnsupportedPlatform = npmiface.ErrUnsupportedPlatform
+
+	// ErrPeerDepConflict 依赖树无法解析，通常是peer依赖冲突（ERESOLVE）
+	ErrPeerDepConflict = npmiface.ErrPeerDepConflict
+
+	// ErrNoMatchingVersion 没有满足版本范围的版本（ETARGET）
+	ErrNoMatchingVersion = npmiface.ErrNoMatchingVersion
+
+	// ErrOTPRequired 操作需要一次性密码（EOTP）
+	ErrOTPRequired = npmiface.ErrOTPRequired
+
+	// ErrDiskFull 磁盘空间不足（ENOSPC）
+	ErrDiskFull = npmiface.ErrDiskFull
 )
 
+// errorCodes npm错误码对应的预定义错误
+var errorCodes = map[string]error{
+	"E404":         ErrPackageNotFound,
+	"ERESOLVE":     ErrPeerDepConflict,
+	"ETARGET":      ErrNoMatchingVersion,
+	"EACCES":       ErrPermissionDenied,
+	"EPERM":        ErrPermissionDenied,
+	"E403":         ErrPermissionDenied,
+	"EOTP":         ErrOTPRequired,
+	"E401":         ErrAuthenticationFailed,
+	"ENEEDAUTH":    ErrAuthenticationFailed,
+	"ENOTFOUND":    ErrNetworkError,
+	"ECONNREFUSED": ErrNetworkError,
+	"ECONNRESET":   ErrNetworkError,
+	"ETIMEDOUT":    ErrNetworkError,
+	"EAI_AGAIN":    ErrNetworkError,
+	"ENOTCACHED":   ErrNetworkError,
+	"ENOSPC":       ErrDiskFull,
+}
+
+// ClassifyErrorCode 返回npm错误码对应的预定义错误，未知错误码返回nil
+//
+// registry返回5xx时npm的错误码为E加状态码，例如E503，归为ErrRegistryError。
+func ClassifyErrorCode(code string) error {
+	if err, ok := errorCodes[code]; ok {
+		return err
+	}
+	if len(code) == 4 && code[0] == 'E' && code[1] == '5' && isDigit(code[2]) && isDigit(code[3]) {
+		return ErrRegistryError
+	}
+	return nil
+}
+
+// isDigit 检查是否为ASCII数字
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
 // NpmError npm操作错误
 type NpmError struct {
 	Op       string // 操作名称
@@ -71,6 +127,39 @@ func (e *NpmError) Unwrap() error {
 	return e.Err
 }
 
+// Code 从npm的输出中解析错误码，例如E404、ERESOLVE，无法识别时返回空字符串
+func (e *NpmError) Code() string {
+	if output := ParseErrorOutput(e.Stdout, e.Stderr); output != nil && output.Code != "" {
+		return output.Code
+	}
+	// 没有错误码行时只能从消息判断，例如npm view的"404 Not Found"
+	if strings.Contains(e.Stderr, "404 Not Found") {
+		return "E404"
+	}
+	return ""
+}
+
+// Is 按退出码和npm错误码匹配预定义错误，
+// 例如errors.Is(err, ErrPeerDepConflict)可以判断安装是否因ERESOLVE失败
+func (e *NpmError) Is(target error) bool {
+	if target == ErrNpmNotFound && (e.ExitCode == 127 || isCommandNotFound(e.Err)) {
+		return true
+	}
+	classified := ClassifyErrorCode(e.Code())
+	return classified != nil && classified == target
+}
+
+// isCommandNotFound 判断命令是否因为可执行文件不存在而无法启动
+//
+// 在PATH中找不到命令时为exec.ErrNotFound，指定的路径不存在时为fork/exec的ENOENT。
+func isCommandNotFound(err error) bool {
+	if errors.Is(err, exec.ErrNotFound) {
+		return true
+	}
+	var pathErr *fs.PathError
+	return errors.As(err, &pathErr) && pathErr.Op == "fork/exec" && errors.Is(pathErr.Err, fs.ErrNotExist)
+}
+
 // NewNpmError 创建npm错误
 func NewNpmError(op, pkg string, exitCode int, stdout, stderr string, err error) *NpmError {
 	return &NpmError{
@@ -131,24 +220,29 @@ func NewUninstallError(pkg, reason string, err error) *UninstallError {
 	}
 }
 
-// ValidationError 验证错误
-type ValidationError struct {
-	Field   string
-	Value   string
-	Reason  string
+// ListProblemsError npm list发现依赖缺失或版本无效，ListPackages同时返回已解析的依赖树
+type ListProblemsError struct {
+	Problems []string // npm报告的问题，例如"missing: left-pad@1.0.0, required by app@1.0.0"
+	Err      *NpmError
+}
+
+func (e *ListProblemsError) Error() string {
+	if len(e.Problems) == 1 {
+		return fmt.Sprintf("npm list found a problem: %s", e.Problems[0])
+	}
+	return fmt.Sprintf("npm list found %d problems: %s", len(e.Problems), strings.Join(e.Problems, "; "))
 }
 
-func (e *ValidationError) Error() string {
-	return fmt.Sprintf("validation failed for field '%s' with value '%s': %s", e.Field, e.Value, e.Reason)
+func (e *ListProblemsError) Unwrap() error {
+	return e.Err
 }
 
+// ValidationError 验证错误
+type ValidationError = npmiface.ValidationError
+
 // NewValidationError 创建验证错误
 func NewValidationError(field, value, reason string) *ValidationError {
-	return &ValidationError{
-		Field:  field,
-		Value:  value,
-		Reason: reason,
-	}
+	return npmiface.NewValidationError(field, value, reason)
 }
 
 // PlatformError 平台相关错误
@@ -199,6 +293,9 @@ func NewDownloadError(url, reason string, err error) *DownloadError {
 	}
 }
 
+// ChecksumError 下载的文件与发布的SHA-256校验和不一致，参见platform.ChecksumError
+type ChecksumError = platform.ChecksumError
+
 // IsNpmNotFound 检查是否为npm未找到错误
 func IsNpmNotFound(err error) bool {
 	return errors.Is(err, ErrNpmNotFound)
@@ -228,3 +325,33 @@ func IsTimeout(err error) bool {
 func IsUnsupportedPlatform(err error) bool {
 	return errors.Is(err, ErrUnsupportedPlatform)
 }
+
+// IsPeerDepConflict 检查是否为依赖树无法解析（ERESOLVE）错误
+func IsPeerDepConflict(err error) bool {
+	return errors.Is(err, ErrPeerDepConflict)
+}
+
+// IsNoMatchingVersion 检查是否为没有匹配版本（ETARGET）错误
+func IsNoMatchingVersion(err error) bool {
+	return errors.Is(err, ErrNoMatchingVersion)
+}
+
+// IsAuthError 检查是否为认证错误，包括未登录、令牌无效和需要一次性密码
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrAuthenticationFailed) || errors.Is(err, ErrOTPRequired)
+}
+
+// IsOTPRequired 检查是否为需要一次性密码（EOTP）错误
+func IsOTPRequired(err error) bool {
+	return errors.Is(err, ErrOTPRequired)
+}
+
+// IsRegistryError 检查是否为registry服务端错误（5xx）
+func IsRegistryError(err error) bool {
+	return errors.Is(err, ErrRegistryError)
+}
+
+// IsDiskFull 检查是否为磁盘空间不足（ENOSPC）错误
+func IsDiskFull(err error) bool {
+	return errors.Is(err, ErrDiskFull)
+}
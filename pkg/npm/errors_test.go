@@ -2,7 +2,13 @@ package npm
 
 import (
 	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"syscall"
 	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/fixtures"
 )
 
 func TestNpmError(t *testing.T) {
@@ -327,6 +333,15 @@ func TestErrorTypeChecking(t *testing.T) {
 		t.Error("Expected IsNpmNotFound to return true for command not found error")
 	}
 
+	for _, err := range []error{&exec.Error{Name: "npm", Err: exec.ErrNotFound}, &fs.PathError{Op: "fork/exec", Path: "/missing/npm", Err: syscall.ENOENT}} {
+		if !IsNpmNotFound(&NpmError{Op: "view", Err: err}) {
+			t.Errorf("Expected IsNpmNotFound to return true for %v", err)
+		}
+	}
+	if IsNpmNotFound(&NpmError{Op: "install", Err: &fs.PathError{Op: "chdir", Path: "/missing", Err: syscall.ENOENT}}) {
+		t.Error("Expected a missing working directory not to count as npm not found")
+	}
+
 	otherErr := &NpmError{Op: "install", ExitCode: 1, Err: errors.New("other error")}
 	if IsNpmNotFound(otherErr) {
 		t.Error("Expected IsNpmNotFound to return false for other errors")
@@ -401,3 +416,90 @@ func TestErrorWrapping(t *testing.T) {
 		t.Errorf("Expected Op 'test', got '%s'", targetNpmErr.Op)
 	}
 }
+
+func TestNpmErrorClassification(t *testing.T) {
+	// 语料库中的真实错误输出
+	expected := map[string]error{
+		"npm-10.8.2/error/e404":           ErrPackageNotFound,
+		"npm-10.8.2/error/e404-json":      ErrPackageNotFound,
+		"npm-10.8.2/error/eresolve":       ErrPeerDepConflict,
+		"npm-10.8.2/error/eresolve-json":  ErrPeerDepConflict,
+		"npm-10.8.2/error/etarget":        ErrNoMatchingVersion,
+		"npm-10.8.2/error/eotp":           ErrOTPRequired,
+		"npm-10.8.2/error/eneedauth":      ErrAuthenticationFailed,
+		"npm-10.8.2/error/econnrefused":   ErrNetworkError,
+		"npm-10.8.2/error/enotcached":     ErrNetworkError,
+		"npm-10.8.2/error/ejsonparse":     nil,
+		"npm-10.8.2/error/missing-script": nil,
+		"npm-9.8.1/error/e404":            ErrPackageNotFound,
+		"npm-6.14.18/error/e404":          ErrPackageNotFound,
+	}
+
+	samples, err := fixtures.Samples(fixtures.KindError)
+	if err != nil {
+		t.Fatalf("Samples() failed: %v", err)
+	}
+	for _, sample := range samples {
+		want, ok := expected[sample.ID()]
+		if !ok {
+			t.Errorf("No expected classification for %s", sample.ID())
+			continue
+		}
+
+		var npmErr error = NewNpmError("install", "", sample.ExitCode, sample.Stdout, sample.Stderr, errors.New("exit status 1"))
+		// 经过多层包装后仍能识别
+		npmErr = fmt.Errorf("deploy failed: %w", NewInstallError("pkg", "npm failed", npmErr))
+
+		got := ClassifyErrorCode(ParseErrorOutput(sample.Stdout, sample.Stderr).Code)
+		if got != want {
+			t.Errorf("%s: expected %v, got %v", sample.ID(), want, got)
+		}
+		if want != nil && !errors.Is(npmErr, want) {
+			t.Errorf("%s: expected errors.Is to match %v", sample.ID(), want)
+		}
+	}
+}
+
+func TestNpmErrorPredicates(t *testing.T) {
+	newErr := func(code string) error {
+		return NewNpmError("install", "pkg", 1, "", "npm error code "+code+"\nnpm error message\n", errors.New("exit status 1"))
+	}
+
+	tests := []struct {
+		code      string
+		predicate func(error) bool
+		name      string
+	}{
+		{"ERESOLVE", IsPeerDepConflict, "IsPeerDepConflict"},
+		{"EOTP", IsAuthError, "IsAuthError"},
+		{"EOTP", IsOTPRequired, "IsOTPRequired"},
+		{"E401", IsAuthError, "IsAuthError"},
+		{"ENEEDAUTH", IsAuthError, "IsAuthError"},
+		{"ENOTFOUND", IsNetworkError, "IsNetworkError"},
+		{"ETIMEDOUT", IsNetworkError, "IsNetworkError"},
+		{"ENOSPC", IsDiskFull, "IsDiskFull"},
+		{"EACCES", IsPermissionDenied, "IsPermissionDenied"},
+		{"ETARGET", IsNoMatchingVersion, "IsNoMatchingVersion"},
+		{"E404", IsPackageNotFound, "IsPackageNotFound"},
+	}
+	for _, tt := range tests {
+		if !tt.predicate(newErr(tt.code)) {
+			t.Errorf("Expected %s to return true for %s", tt.name, tt.code)
+		}
+	}
+
+	// 不同类别之间互不匹配
+	if IsAuthError(newErr("ENOSPC")) || IsDiskFull(newErr("EOTP")) || IsPeerDepConflict(newErr("E404")) {
+		t.Error("Expected predicates to only match their own codes")
+	}
+	unknown := newErr("EUNKNOWN")
+	if IsNetworkError(unknown) || IsAuthError(unknown) || IsDiskFull(unknown) || IsPeerDepConflict(unknown) {
+		t.Error("Expected unknown code not to be classified")
+	}
+	if code := unknown.(*NpmError).Code(); code != "EUNKNOWN" {
+		t.Errorf("Expected code EUNKNOWN, got %q", code)
+	}
+	if IsDiskFull(nil) || IsAuthError(errors.New("ENOSPC")) {
+		t.Error("Expected plain errors not to be classified")
+	}
+}
@@ -0,0 +1,31 @@
+package npm
+
+import "github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+
+// Event 事件总线上传递的事件，订阅者按具体类型区分
+type Event = npmiface.Event
+
+// EventListener 事件订阅函数
+type EventListener = npmiface.EventListener
+
+// EventBus 进程内事件总线
+type EventBus = npmiface.EventBus
+
+// NewEventBus 创建事件总线
+func NewEventBus() *EventBus {
+	return npmiface.NewEventBus()
+}
+
+// PublishStage 发布阶段
+type PublishStage = npmiface.PublishStage
+
+const (
+	PublishStagePacking    = npmiface.PublishStagePacking
+	PublishStageUploading  = npmiface.PublishStageUploading
+	PublishStageProcessing = npmiface.PublishStageProcessing
+	PublishStageDone       = npmiface.PublishStageDone
+	PublishStageFailed     = npmiface.PublishStageFailed
+)
+
+// PublishEvent 发布过程中的阶段事件
+type PublishEvent = npmiface.PublishEvent
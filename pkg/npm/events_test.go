@@ -0,0 +1,172 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+func TestEventBus(t *testing.T) {
+	bus := NewEventBus()
+
+	var first, second []string
+	unsubscribe := bus.Subscribe(func(event Event) {
+		first = append(first, event.EventType())
+	})
+	bus.Subscribe(func(event Event) {
+		second = append(second, event.EventType())
+	})
+
+	bus.Emit(PublishEvent{Stage: PublishStagePacking})
+	unsubscribe()
+	bus.Emit(PublishEvent{Stage: PublishStageDone})
+
+	if len(first) != 1 || first[0] != "publish.packing" {
+		t.Errorf("Unexpected events for unsubscribed listener: %v", first)
+	}
+	if len(second) != 2 || second[1] != "publish.done" {
+		t.Errorf("Unexpected events for listener: %v", second)
+	}
+
+	// nil总线上发送事件不应panic
+	var nilBus *EventBus
+	nilBus.Emit(PublishEvent{Stage: PublishStageDone})
+}
+
+func TestPublishEventPercent(t *testing.T) {
+	if percent := (PublishEvent{BytesSent: 50, BytesTotal: 200}).Percent(); percent != 25 {
+		t.Errorf("Percent() = %v, expected 25", percent)
+	}
+	if percent := (PublishEvent{BytesSent: 50}).Percent(); percent != 0 {
+		t.Errorf("Percent() without total = %v, expected 0", percent)
+	}
+}
+
+func TestClientPublishEvents(t *testing.T) {
+	c, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if !c.IsAvailable(ctx) {
+		t.Skip("npm not available")
+	}
+
+	tempDir := t.TempDir()
+	writeTestFile(t, filepath.Join(tempDir, "package.json"), `{
+  "name": "publish-events-test",
+  "version": "1.0.0",
+  "scripts": {
+    "prepublishOnly": "node -e \"require('fs').writeFileSync('prepublish.txt', '')\"",
+    "postpublish": "node -e \"require('fs').writeFileSync('postpublish.txt', '')\""
+  }
+}`)
+
+	var events []PublishEvent
+	unsubscribe := c.Events().Subscribe(func(event Event) {
+		if publish, ok := event.(PublishEvent); ok {
+			events = append(events, publish)
+		}
+	})
+	defer unsubscribe()
+
+	if err := c.Publish(ctx, PublishOptions{WorkingDir: tempDir, DryRun: true}); err != nil {
+		t.Fatalf("Publish() failed: %v", err)
+	}
+
+	stages := make([]PublishStage, 0, len(events))
+	for _, event := range events {
+		stages = append(stages, event.Stage)
+	}
+	expected := []PublishStage{PublishStagePacking, PublishStageUploading, PublishStageDone}
+	if len(stages) != len(expected) {
+		t.Fatalf("Stages = %v, expected %v", stages, expected)
+	}
+	for i := range expected {
+		if stages[i] != expected[i] {
+			t.Fatalf("Stages = %v, expected %v", stages, expected)
+		}
+	}
+
+	done := events[len(events)-1]
+	if done.Package != "publish-events-test" || done.Version != "1.0.0" || !done.DryRun {
+		t.Errorf("Unexpected done event: %+v", done)
+	}
+	if done.BytesTotal == 0 || done.Percent() != 100 {
+		t.Errorf("Expected full upload progress, got %d/%d", done.BytesSent, done.BytesTotal)
+	}
+	if events[1].BytesSent != 0 || events[1].BytesTotal != done.BytesTotal {
+		t.Errorf("Unexpected uploading event: %+v", events[1])
+	}
+
+	for _, name := range []string{"prepublish.txt", "postpublish.txt"} {
+		if _, err := os.Stat(filepath.Join(tempDir, name)); err != nil {
+			t.Errorf("Expected lifecycle script to create %s: %v", name, err)
+		}
+	}
+}
+
+func TestClientPublishFailedEvent(t *testing.T) {
+	c, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if !c.IsAvailable(ctx) {
+		t.Skip("npm not available")
+	}
+
+	var failed *PublishEvent
+	c.Events().Subscribe(func(event Event) {
+		if publish, ok := event.(PublishEvent); ok && publish.Stage == PublishStageFailed {
+			failed = &publish
+		}
+	})
+
+	err = c.Publish(ctx, PublishOptions{WorkingDir: t.TempDir(), DryRun: true})
+	if err == nil {
+		t.Fatal("Expected error when publishing a directory without package.json")
+	}
+	if failed == nil || !errors.Is(failed.Err, err) {
+		t.Errorf("Expected failed event carrying the error, got %+v", failed)
+	}
+}
+
+// failedScriptExecutor npm run以非零退出码结束但不返回错误，其他命令成功
+type failedScriptExecutor struct {
+	commands []string
+}
+
+func (e *failedScriptExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	e.commands = append(e.commands, options.Args[0])
+	if options.Args[0] == "run" {
+		return &utils.ExecuteResult{ExitCode: 1, Stderr: "lint failed"}, nil
+	}
+	return &utils.ExecuteResult{Success: true}, nil
+}
+
+func TestPublishFailedLifecycleScript(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name": "pkg", "version": "1.0.0", "scripts": {"prepublishOnly": "eslint ."}}`)
+
+	executor := &failedScriptExecutor{}
+	c, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+
+	err = c.Publish(context.Background(), PublishOptions{WorkingDir: dir})
+	var npmErr *NpmError
+	if !errors.As(err, &npmErr) || npmErr.ExitCode != 1 || npmErr.Stderr != "lint failed" {
+		t.Fatalf("Expected NpmError from failed prepublishOnly, got %v", err)
+	}
+	if len(executor.commands) != 1 {
+		t.Errorf("Expected publish to stop after the failed script, ran %v", executor.commands)
+	}
+}
@@ -0,0 +1,26 @@
+package npm
+
+// Experiments 实验性功能开关，默认全部关闭
+//
+// 较大的新子系统先放在开关后面发布，按客户端选择启用，不影响现有行为。
+// 实验性功能的行为和开关本身都可能在后续版本中调整，稳定后开关会被移除并默认启用。
+type Experiments struct {
+	// Publish通过registry的HTTP接口直接上传，不运行npm publish，效果与RegistryPublisher相同。
+	// 使用WithRegistryFallback设置的registry客户端，需要在该客户端上设置令牌，
+	// PublishOptions.Registry不生效。
+	EnableDirectRegistryPublish bool `json:"enable_direct_registry_publish,omitempty"`
+
+	// Install安装便携版Node.js时用Go标准库解压.tar.gz和.zip，不依赖系统的tar和unzip命令。
+	// 标准库不支持xz，.tar.xz仍然使用tar命令。
+	EnablePureGoExtraction bool `json:"enable_pure_go_extraction,omitempty"`
+}
+
+// WithExperiments 启用实验性功能
+func WithExperiments(experiments Experiments) ClientOption {
+	return func(c *client) {
+		c.experiments = experiments
+		if c.installer != nil {
+			c.installer.pureGoExtraction = experiments.EnablePureGoExtraction
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package npm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+func TestWithExperiments(t *testing.T) {
+	c, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	if impl := c.(*client); impl.experiments != (Experiments{}) || impl.installer.pureGoExtraction {
+		t.Error("Expected experiments to be disabled by default")
+	}
+
+	c, err = NewClient(WithExperiments(Experiments{EnablePureGoExtraction: true}))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	if !c.(*client).installer.pureGoExtraction {
+		t.Error("Expected installer to use pure Go extraction")
+	}
+}
+
+func TestClientDirectRegistryPublish(t *testing.T) {
+	c, err := NewClient(WithExperiments(Experiments{EnableDirectRegistryPublish: true}), WithRegistryFallback(nil))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := c.Publish(ctx, PublishOptions{}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error without registry client, got %v", err)
+	}
+	if !c.IsAvailable(ctx) {
+		t.Skip("npm not available")
+	}
+
+	var method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method + " " + r.URL.Path
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	writeTestFile(t, filepath.Join(tempDir, "package.json"), `{"name": "direct-publish-test", "version": "1.0.0"}`)
+	writeTestFile(t, filepath.Join(tempDir, "index.js"), "module.exports = 1\n")
+
+	c, err = NewClient(WithExperiments(Experiments{EnableDirectRegistryPublish: true}), WithRegistryFallback(registry.NewClient(server.URL)))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	if err := c.Publish(ctx, PublishOptions{WorkingDir: tempDir}); err != nil {
+		t.Fatalf("Publish() failed: %v", err)
+	}
+	if method != "PUT /direct-publish-test" {
+		t.Errorf("Expected package to be uploaded to the registry, got %q", method)
+	}
+}
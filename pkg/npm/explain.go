@@ -0,0 +1,64 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// ExplainNode npm explain输出中的一个已安装包
+type ExplainNode = npmiface.ExplainNode
+
+// ExplainDependent 依赖某个包的上层包
+type ExplainDependent = npmiface.ExplainDependent
+
+// Explain 运行npm explain，返回包的每个已安装实例及依赖它的上层包
+//
+// 与UpdatePackage一样在当前目录运行。
+func (c *client) Explain(ctx context.Context, pkg string) ([]ExplainNode, error) {
+	executeOptions, err := c.explainCommand(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return nil, NewNpmError("explain", pkg, -1, "", "", err)
+		}
+		if strings.Contains(result.Stdout+result.Stderr, "No dependencies found matching") {
+			return nil, NewNpmError("explain", pkg, result.ExitCode, result.Stdout, result.Stderr, ErrPackageNotFound)
+		}
+		return nil, NewNpmError("explain", pkg, result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	return parseExplainJSON([]byte(result.Stdout))
+}
+
+// explainCommand 构造npm explain的执行选项
+func (c *client) explainCommand(pkg string) (utils.ExecuteOptions, error) {
+	if pkg == "" {
+		return utils.ExecuteOptions{}, NewValidationError("package", pkg, "package name cannot be empty")
+	}
+
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"explain", pkg, "--json"},
+		CaptureOutput: true,
+		Timeout:       time.Minute,
+	}, nil
+}
+
+// parseExplainJSON 解析npm explain --json的输出
+func parseExplainJSON(data []byte) ([]ExplainNode, error) {
+	var nodes []ExplainNode
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to parse explain output: %w", err)
+	}
+	return nodes, nil
+}
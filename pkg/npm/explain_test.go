@@ -0,0 +1,77 @@
+package npm
+
+import (
+	"reflect"
+	"testing"
+)
+
+const explainFixture = `[
+  {
+    "name": "b",
+    "version": "2.1.0",
+    "location": "node_modules/b",
+    "isWorkspace": false,
+    "dependents": [
+      {"type": "dev", "name": "b", "spec": "^2.0.0", "from": {"location": "/tmp/app"}},
+      {
+        "type": "prod",
+        "name": "b",
+        "spec": "^2.0.0",
+        "from": {
+          "name": "a",
+          "version": "1.0.0",
+          "location": "node_modules/a",
+          "isWorkspace": false,
+          "dependents": [
+            {"type": "prod", "name": "a", "spec": "^1.0.0", "from": {"location": "/tmp/app"}}
+          ]
+        }
+      }
+    ],
+    "dev": false,
+    "optional": false,
+    "devOptional": false,
+    "peer": false,
+    "bundled": false,
+    "overridden": false
+  }
+]`
+
+func TestParseExplainJSON(t *testing.T) {
+	nodes, err := parseExplainJSON([]byte(explainFixture))
+	if err != nil {
+		t.Fatalf("parseExplainJSON() failed: %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("Expected 1 node, got %d", len(nodes))
+	}
+
+	node := nodes[0]
+	if node.String() != "b@2.1.0" || len(node.Dependents) != 2 {
+		t.Errorf("Unexpected node: %+v", node)
+	}
+	if node.Dependents[0].Type != "dev" || !node.Dependents[0].From.IsRoot() || node.Dependents[0].From.String() != "/tmp/app" {
+		t.Errorf("Expected first dependent to be the root project, got %+v", node.Dependents[0])
+	}
+
+	expected := [][]string{{"b@2.1.0"}, {"b@2.1.0", "a@1.0.0"}}
+	if chains := node.Chains(); !reflect.DeepEqual(chains, expected) {
+		t.Errorf("Chains() = %v, expected %v", chains, expected)
+	}
+
+	if _, err := parseExplainJSON([]byte(`{"error": {}}`)); err == nil {
+		t.Error("Expected error for error output")
+	}
+}
+
+func TestExplainNodeChainsCycle(t *testing.T) {
+	a := &ExplainNode{Name: "a", Version: "1.0.0", Location: "node_modules/a"}
+	b := &ExplainNode{Name: "b", Version: "1.0.0", Location: "node_modules/b"}
+	a.Dependents = []ExplainDependent{{Name: "a", From: b}}
+	b.Dependents = []ExplainDependent{{Name: "b", From: a}, {Name: "b", From: &ExplainNode{Location: "."}}}
+
+	expected := [][]string{{"a@1.0.0", "b@1.0.0", "a@1.0.0"}, {"a@1.0.0", "b@1.0.0"}}
+	if chains := a.Chains(); !reflect.DeepEqual(chains, expected) {
+		t.Errorf("Chains() = %v, expected %v", chains, expected)
+	}
+}
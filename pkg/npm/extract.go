@@ -0,0 +1,164 @@
+package npm
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extractTarGz 用Go标准库解压tar.gz，结果与tar -xzf archive -C destPath相同
+//
+// 支持目录、普通文件、符号链接和硬链接，路径或链接目标超出destPath的条目返回错误。
+func extractTarGz(archivePath, destPath string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to read gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		target, err := archiveTarget(destPath, header.Name)
+		if err != nil {
+			return err
+		}
+		mode := os.FileMode(header.Mode).Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, mode|0700)
+		case tar.TypeReg:
+			err = writeArchiveFile(target, tr, mode)
+		case tar.TypeSymlink:
+			err = writeArchiveSymlink(destPath, target, header.Linkname)
+		case tar.TypeLink:
+			var source string
+			if source, err = archiveTarget(destPath, header.Linkname); err == nil {
+				err = writeArchiveHardlink(source, target)
+			}
+		default:
+			// 设备文件、FIFO以及pax扩展头等不需要解压
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+	}
+}
+
+// extractZip 用Go标准库解压zip
+func extractZip(archivePath, destPath string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	defer reader.Close()
+
+	for _, entry := range reader.File {
+		target, err := archiveTarget(destPath, entry.Name)
+		if err != nil {
+			return err
+		}
+		if err := extractZipEntry(destPath, target, entry); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", entry.Name, err)
+		}
+	}
+	return nil
+}
+
+// extractZipEntry 解压zip中的一项
+func extractZipEntry(destPath, target string, entry *zip.File) error {
+	info := entry.FileInfo()
+	if info.IsDir() {
+		return os.MkdirAll(target, info.Mode().Perm()|0700)
+	}
+
+	rc, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		linkname, err := io.ReadAll(io.LimitReader(rc, 4096))
+		if err != nil {
+			return err
+		}
+		return writeArchiveSymlink(destPath, target, string(linkname))
+	}
+	mode := info.Mode().Perm()
+	if mode == 0 {
+		mode = 0644
+	}
+	return writeArchiveFile(target, rc, mode)
+}
+
+// archiveTarget 返回条目在destPath中的路径，拒绝绝对路径和..越界
+func archiveTarget(destPath, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(cleaned) || filepath.VolumeName(cleaned) != "" || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %q escapes the destination directory", name)
+	}
+	return filepath.Join(destPath, cleaned), nil
+}
+
+// writeArchiveFile 写入普通文件，父目录不存在时自动创建
+func writeArchiveFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeArchiveSymlink 创建符号链接，链接目标必须是destPath内的相对路径
+func writeArchiveSymlink(destPath, target, linkname string) error {
+	if filepath.IsAbs(linkname) {
+		return fmt.Errorf("symlink target %q is absolute", linkname)
+	}
+	rel, err := filepath.Rel(destPath, filepath.Join(filepath.Dir(target), linkname))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("symlink target %q escapes the destination directory", linkname)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	os.Remove(target)
+	return os.Symlink(linkname, target)
+}
+
+// writeArchiveHardlink 创建硬链接，source为归档中更早解压的文件
+func writeArchiveHardlink(source, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	os.Remove(target)
+	return os.Link(source, target)
+}
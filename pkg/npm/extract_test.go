@@ -0,0 +1,121 @@
+package npm
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeTestTarGz 生成tar.gz，entries中每项为tar头和内容
+func writeTestTarGz(t *testing.T, path string, entries []tar.Header, contents map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, header := range entries {
+		header := header
+		content := contents[header.Name]
+		header.Size = int64(len(content))
+		if err := tw.WriteHeader(&header); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	writeTestFile(t, path, buf.String())
+}
+
+func TestExtractTarGz(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on Windows")
+	}
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "node.tar.gz")
+	writeTestTarGz(t, archive, []tar.Header{
+		{Name: "node-v20/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "node-v20/bin/node", Typeflag: tar.TypeReg, Mode: 0755},
+		{Name: "node-v20/lib/npm-cli.js", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "node-v20/bin/npm", Typeflag: tar.TypeSymlink, Linkname: "../lib/npm-cli.js"},
+		{Name: "node-v20/bin/node-copy", Typeflag: tar.TypeLink, Linkname: "node-v20/bin/node"},
+	}, map[string]string{"node-v20/bin/node": "#!node", "node-v20/lib/npm-cli.js": "cli"})
+
+	dest := filepath.Join(dir, "out")
+	if err := extractTarGz(archive, dest); err != nil {
+		t.Fatalf("extractTarGz() failed: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dest, "node-v20", "bin", "node"))
+	if err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("Expected executable node binary, got %v, %v", info, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "node-v20", "bin", "npm")); err != nil || string(data) != "cli" {
+		t.Errorf("Expected symlink to resolve to npm-cli.js, got %q, %v", data, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "node-v20", "bin", "node-copy")); err != nil || string(data) != "#!node" {
+		t.Errorf("Expected hard link to node, got %q, %v", data, err)
+	}
+
+	tests := []struct {
+		name   string
+		header tar.Header
+	}{
+		{"parent path", tar.Header{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0644}},
+		{"absolute path", tar.Header{Name: "/tmp/evil", Typeflag: tar.TypeReg, Mode: 0644}},
+		{"escaping symlink", tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd"}},
+		{"absolute symlink", tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+		{"escaping hard link", tar.Header{Name: "link", Typeflag: tar.TypeLink, Linkname: "../outside"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+			writeTestTarGz(t, archive, []tar.Header{tt.header}, nil)
+			if err := extractTarGz(archive, t.TempDir()); err == nil || !strings.Contains(err.Error(), "escapes") && !strings.Contains(err.Error(), "absolute") {
+				t.Errorf("Expected unsafe entry to be rejected, got %v", err)
+			}
+		})
+	}
+
+	if err := extractTarGz(filepath.Join(dir, "missing.tar.gz"), dest); err == nil {
+		t.Error("Expected error for missing archive")
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	zw.Create("node-v20-win-x64/")
+	w, _ := zw.Create("node-v20-win-x64/node.exe")
+	w.Write([]byte("MZ"))
+	w, _ = zw.Create("node-v20-win-x64/node_modules/npm/package.json")
+	w.Write([]byte(`{"name": "npm"}`))
+	zw.Close()
+	archive := filepath.Join(dir, "node.zip")
+	writeTestFile(t, archive, buf.String())
+
+	dest := filepath.Join(dir, "out")
+	if err := extractZip(archive, dest); err != nil {
+		t.Fatalf("extractZip() failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "node-v20-win-x64", "node.exe")); err != nil || string(data) != "MZ" {
+		t.Errorf("Unexpected node.exe: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "node-v20-win-x64", "node_modules", "npm", "package.json")); err != nil {
+		t.Errorf("Expected nested file to be extracted: %v", err)
+	}
+
+	buf.Reset()
+	zw = zip.NewWriter(&buf)
+	zw.Create("..\\evil.txt")
+	zw.Close()
+	writeTestFile(t, archive, buf.String())
+	if err := extractZip(archive, dest); err == nil {
+		t.Error("Expected zip entry with backslash traversal to be rejected")
+	}
+}
@@ -0,0 +1,348 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/lockfile"
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// WithRegistryFallback 设置npm不可用时只读操作使用的registry客户端，nil表示不回退
+//
+// 找不到npm命令时，GetPackageInfo、Search、Outdated和Audit直接通过registry的HTTP接口完成，
+// 没有安装Node.js的机器上也可以运行分析工具。默认使用npm_config_registry环境变量指定的registry，
+// 未设置时使用官方registry。回退时不读取.npmrc，私有registry需要通过该选项传入带令牌的客户端。
+func WithRegistryFallback(registryClient *registry.Client) ClientOption {
+	return func(c *client) {
+		c.fallback = registryClient
+	}
+}
+
+// defaultFallback 默认的回退registry客户端
+func defaultFallback() *registry.Client {
+	registryURL := os.Getenv("npm_config_registry")
+	if registryURL == "" {
+		registryURL = os.Getenv("NPM_CONFIG_REGISTRY")
+	}
+	return registry.NewClient(registryURL)
+}
+
+// canFallback 判断失败是否因为找不到npm命令，并且可以回退到registry
+func (c *client) canFallback(err error) bool {
+	return c.fallback != nil && IsNpmNotFound(err)
+}
+
+// registryPackageInfo 从registry获取包信息，结果与npm view --json的主要字段一致
+//
+// pkg可以带版本号、版本范围或dist-tag，例如lodash@4、react@next，不带时使用latest。
+func registryPackageInfo(ctx context.Context, registryClient *registry.Client, pkg string) (*PackageInfo, error) {
+	name, spec := splitPackageSpec(pkg)
+	data, err := registryClient.Download(ctx, registryClient.PackageURL(name))
+	if err != nil {
+		if registry.IsNotFound(err) {
+			err = fmt.Errorf("%w: %w", ErrPackageNotFound, err)
+		}
+		return nil, NewNpmError("view", pkg, -1, "", "", err)
+	}
+
+	var doc struct {
+		DistTags map[string]string          `json:"dist-tags"`
+		Versions map[string]json.RawMessage `json:"versions"`
+		Time     map[string]string          `json:"time"`
+	}
+	if err := utils.DecodeJSON("packument", data, &doc); err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(doc.Versions))
+	for version := range doc.Versions {
+		versions = append(versions, version)
+	}
+	version := resolveSpec(spec, doc.DistTags, versions)
+	if version == "" {
+		return nil, NewNpmError("view", pkg, -1, "", "", fmt.Errorf("%w: no version matching %q", ErrPackageNotFound, spec))
+	}
+
+	var info PackageInfo
+	if err := json.Unmarshal(doc.Versions[version], &info); err != nil {
+		return nil, fmt.Errorf("failed to parse package info: %w", err)
+	}
+	info.DistTags = doc.DistTags
+	info.Versions = make(map[string]interface{}, len(doc.Versions))
+	for v, raw := range doc.Versions {
+		info.Versions[v] = raw
+	}
+	info.Time = make(map[string]time.Time, len(doc.Time))
+	for key, value := range doc.Time {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			info.Time[key] = t
+		}
+	}
+	return &info, nil
+}
+
+// registrySearch 通过registry的搜索接口搜索包
+func registrySearch(ctx context.Context, registryClient *registry.Client, query string) ([]SearchResult, error) {
+	data, err := registryClient.Search(ctx, query, 0)
+	if err != nil {
+		return nil, NewNpmError("search", query, -1, "", "", err)
+	}
+	return ParseSearchJSON(data)
+}
+
+// fallbackOutdated 根据package.json、node_modules和registry计算过期的依赖
+//
+// 与npm outdated一样列出未安装、不是wanted版本或wanted不是latest的直接依赖，
+// 不是registry版本范围的依赖（git、file等）被跳过。
+func (c *client) fallbackOutdated(ctx context.Context, options OutdatedOptions) ([]OutdatedPackage, error) {
+	dir, err := filepath.Abs(options.WorkingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	pkgJSON := NewPackageJSON(filepath.Join(dir, "package.json"))
+	if err := pkgJSON.Load(); err != nil {
+		return nil, err
+	}
+	dependent := pkgJSON.GetName()
+	if dependent == "" {
+		dependent = filepath.Base(dir)
+	}
+
+	type request struct {
+		name, rng, depType string
+	}
+	var requests []request
+	for _, group := range []struct {
+		depType string
+		deps    map[string]string
+	}{
+		{"dependencies", pkgJSON.GetDependencies()},
+		{"devDependencies", pkgJSON.GetDevDependencies()},
+		{"optionalDependencies", pkgJSON.GetOptionalDependencies()},
+	} {
+		for name, rng := range group.deps {
+			if isRegistrySpec(rng) {
+				requests = append(requests, request{name, rng, group.depType})
+			}
+		}
+	}
+
+	outdated := make([]*OutdatedPackage, len(requests))
+	tasks := make([]utils.Task, len(requests))
+	for i, req := range requests {
+		tasks[i] = utils.Task{
+			Name: req.name,
+			Run: func(ctx context.Context) error {
+				packument, err := c.fallback.GetPackumentWithOptions(ctx, req.name, registry.PackumentOptions{})
+				if err != nil {
+					return NewNpmError("outdated", req.name, -1, "", "", err)
+				}
+				versions := make([]string, 0, len(packument.Versions))
+				for version := range packument.Versions {
+					versions = append(versions, version)
+				}
+				wanted := resolveSpec(req.rng, packument.DistTags, versions)
+				if wanted == "" {
+					return nil
+				}
+
+				latest := packument.DistTags["latest"]
+				current := installedVersion(dir, req.name)
+				if current != "" && current == wanted && wanted == latest {
+					return nil
+				}
+				entry := &OutdatedPackage{
+					Name:      req.name,
+					Current:   current,
+					Wanted:    wanted,
+					Latest:    latest,
+					Dependent: dependent,
+					Type:      req.depType,
+				}
+				if current != "" {
+					entry.Location = filepath.Join(dir, "node_modules", req.name)
+				}
+				outdated[i] = entry
+				return nil
+			},
+		}
+	}
+
+	var packages []OutdatedPackage
+	for i, result := range utils.NewWorkerPool(DefaultPackagesInfoConcurrency).Run(ctx, tasks) {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		if outdated[i] != nil {
+			packages = append(packages, *outdated[i])
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// auditTargets 锁文件中需要审计的包版本
+type auditTargets struct {
+	versions     map[string][]string // 包名 -> 锁文件中的版本
+	dependencies int                 // 包版本的数量
+	direct       map[string]bool     // 项目的直接依赖
+}
+
+// loadAuditTargets 读取工作目录的锁文件，Production时跳过开发依赖
+func loadAuditTargets(options AuditOptions) (*auditTargets, error) {
+	dir := options.WorkingDir
+	if dir == "" {
+		dir = "."
+	}
+	path, err := lockfile.Locate(dir)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := lockfile.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := &auditTargets{versions: make(map[string][]string), direct: make(map[string]bool)}
+	for _, pkg := range lock.Packages {
+		if options.Production && pkg.Dev {
+			continue
+		}
+		targets.dependencies++
+		if !slices.Contains(targets.versions[pkg.Name], pkg.Version) {
+			targets.versions[pkg.Name] = append(targets.versions[pkg.Name], pkg.Version)
+		}
+	}
+	if lock.Root != nil {
+		for _, deps := range []map[string]string{lock.Root.Dependencies, lock.Root.DevDependencies, lock.Root.OptionalDependencies} {
+			for name := range deps {
+				targets.direct[name] = true
+			}
+		}
+	}
+	return targets, nil
+}
+
+// registryAudit 用锁文件中的包版本查询registry的批量安全公告接口
+//
+// 结果只包含直接受影响的包，没有npm audit计算的依赖路径和修复方案。
+func registryAudit(ctx context.Context, registryClient *registry.Client, options AuditOptions) (*AuditReport, error) {
+	targets, err := loadAuditTargets(options)
+	if err != nil {
+		return nil, NewNpmError("audit", "", -1, "", "", fmt.Errorf("audit without npm requires a lockfile: %w", err))
+	}
+	versions, direct, dependencies := targets.versions, targets.direct, targets.dependencies
+
+	if options.Registry != "" {
+		registryClient = registry.NewClient(options.Registry)
+	}
+	advisories, err := registryClient.BulkAdvisories(ctx, versions)
+	if err != nil {
+		return nil, NewNpmError("audit", "", -1, "", "", err)
+	}
+
+	report := &AuditReport{Counts: make(map[Severity]int), Dependencies: dependencies}
+	for name, list := range advisories {
+		finding := AuditFinding{Name: name, IsDirect: direct[name]}
+		var ranges []string
+		for _, advisory := range list {
+			if !anySatisfies(versions[name], advisory.VulnerableVersions) {
+				continue
+			}
+			severity := Severity(advisory.Severity)
+			finding.Advisories = append(finding.Advisories, Advisory{
+				Source:    advisory.ID,
+				Name:      name,
+				Title:     advisory.Title,
+				URL:       advisory.URL,
+				Severity:  severity,
+				CWE:       advisory.CWE,
+				CVSSScore: advisory.CVSS.Score,
+				Range:     advisory.VulnerableVersions,
+			})
+			ranges = append(ranges, advisory.VulnerableVersions)
+			if severity.Rank() > finding.Severity.Rank() {
+				finding.Severity = severity
+			}
+		}
+		if len(finding.Advisories) == 0 {
+			continue
+		}
+		finding.Range = strings.Join(ranges, " || ")
+		report.Findings = append(report.Findings, finding)
+		report.Counts[finding.Severity]++
+	}
+	sort.Slice(report.Findings, func(i, j int) bool {
+		return report.Findings[i].Name < report.Findings[j].Name
+	})
+	report.Total = len(report.Findings)
+	return report, nil
+}
+
+// splitPackageSpec 拆分name@spec，正确处理scope
+func splitPackageSpec(pkg string) (string, string) {
+	if idx := strings.LastIndex(pkg, "@"); idx > 0 {
+		return pkg[:idx], pkg[idx+1:]
+	}
+	return pkg, ""
+}
+
+// resolveSpec 按npm的规则选择版本：dist-tag、latest满足范围时优先latest、否则满足范围的最高版本
+func resolveSpec(spec string, distTags map[string]string, versions []string) string {
+	if spec == "" {
+		spec = "latest"
+	}
+	if version, ok := distTags[spec]; ok {
+		return version
+	}
+	if !semver.ValidRange(spec) {
+		return ""
+	}
+	if latest := distTags["latest"]; latest != "" && semver.Satisfies(latest, spec) {
+		return latest
+	}
+	return semver.MaxSatisfying(versions, spec)
+}
+
+// isRegistrySpec 判断依赖是否为registry上的版本范围或dist-tag，
+// file:、git、npm:别名、github简写等返回false
+func isRegistrySpec(spec string) bool {
+	return semver.ValidRange(spec) || (spec != "" && !strings.ContainsAny(spec, ":/"))
+}
+
+// installedVersion 读取node_modules中已安装包的版本，未安装时返回空字符串
+func installedVersion(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "node_modules", name, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	return pkg.Version
+}
+
+// anySatisfies 判断是否有版本在范围内
+func anySatisfies(versions []string, rng string) bool {
+	for _, version := range versions {
+		if semver.Satisfies(version, rng) {
+			return true
+		}
+	}
+	return false
+}
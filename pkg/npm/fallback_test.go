@@ -0,0 +1,171 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// missingNpmExecutor 模拟PATH中没有npm
+type missingNpmExecutor struct{}
+
+func (e *missingNpmExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	err := &exec.Error{Name: options.Command, Err: exec.ErrNotFound}
+	return &utils.ExecuteResult{Error: err}, err
+}
+
+// fallbackServer 模拟registry的文档、搜索和批量安全公告接口
+func fallbackServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/lodash":
+			w.Write([]byte(`{"name": "lodash", "dist-tags": {"latest": "4.17.21"},
+				"versions": {
+					"3.10.1": {"name": "lodash", "version": "3.10.1", "license": "MIT"},
+					"4.17.11": {"name": "lodash", "version": "4.17.11", "license": "MIT"},
+					"4.17.21": {"name": "lodash", "version": "4.17.21", "description": "Lodash modular utilities.", "license": "MIT", "keywords": ["modules"]}
+				},
+				"time": {"created": "2012-04-23T16:37:11.912Z", "4.17.21": "2021-02-20T15:42:16.891Z"}}`))
+		case "/minimist":
+			w.Write([]byte(`{"name": "minimist", "dist-tags": {"latest": "1.2.8"}, "versions": {"1.2.0": {}, "1.2.8": {}}}`))
+		case "/-/v1/search":
+			w.Write([]byte(`{"objects": [{"package": {"name": "lodash", "version": "4.17.21"}, "score": {"final": 0.9}, "searchScore": 100}]}`))
+		case "/-/npm/v1/security/advisories/bulk":
+			var pkgs map[string][]string
+			json.NewDecoder(r.Body).Decode(&pkgs)
+			if _, ok := pkgs["minimist"]; ok {
+				t.Errorf("Expected dev dependencies to be omitted, got %v", pkgs)
+			}
+			w.Write([]byte(`{"lodash": [
+				{"id": 1, "url": "https://github.com/advisories/GHSA-jf85-cpcp-j695", "title": "Prototype Pollution", "severity": "critical", "vulnerable_versions": "<4.17.12"},
+				{"id": 2, "url": "https://github.com/advisories/GHSA-p6mc-m468-83gw", "title": "Old issue", "severity": "low", "vulnerable_versions": "<3.0.0"}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientRegistryFallback(t *testing.T) {
+	server := fallbackServer(t)
+	executor := &missingNpmExecutor{}
+	client, err := NewClientWithExecutor("npm", executor, WithRegistryFallback(registry.NewClient(server.URL)))
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	ctx := context.Background()
+
+	info, err := client.GetPackageInfo(ctx, "lodash")
+	if err != nil {
+		t.Fatalf("GetPackageInfo() failed: %v", err)
+	}
+	if info.Version != "4.17.21" || info.Description != "Lodash modular utilities." || len(info.Versions) != 3 || info.Time["4.17.21"].Year() != 2021 {
+		t.Errorf("Unexpected package info: %+v", info)
+	}
+	if info, err := client.GetPackageInfo(ctx, "lodash@^3.0.0"); err != nil || info.Version != "3.10.1" {
+		t.Errorf("GetPackageInfo(lodash@^3.0.0) = %+v, %v", info, err)
+	}
+	if _, err := client.GetPackageInfo(ctx, "missing"); !IsPackageNotFound(err) {
+		t.Errorf("Expected package not found error, got %v", err)
+	}
+
+	results, err := client.Search(ctx, "lodash")
+	if err != nil || len(results) != 1 || results[0].Package.Name != "lodash" || results[0].Score.Final != 0.9 {
+		t.Errorf("Search() = %+v, %v", results, err)
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name": "app", "dependencies": {"lodash": "^4.17.0", "local": "file:../local"}, "devDependencies": {"minimist": "^1.2.0"}}`)
+	writeTestFile(t, filepath.Join(dir, "node_modules", "lodash", "package.json"), `{"name": "lodash", "version": "4.17.11"}`)
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), `{"name": "app", "lockfileVersion": 3, "packages": {
+		"": {"name": "app", "dependencies": {"lodash": "^4.17.0"}, "devDependencies": {"minimist": "^1.2.0"}},
+		"node_modules/lodash": {"version": "4.17.11"},
+		"node_modules/minimist": {"version": "1.2.0", "dev": true}
+	}}`)
+
+	outdated, err := client.Outdated(ctx, OutdatedOptions{WorkingDir: dir})
+	if err != nil {
+		t.Fatalf("Outdated() failed: %v", err)
+	}
+	expected := []OutdatedPackage{
+		{Name: "lodash", Current: "4.17.11", Wanted: "4.17.21", Latest: "4.17.21", Dependent: "app", Location: filepath.Join(dir, "node_modules", "lodash"), Type: "dependencies"},
+		{Name: "minimist", Wanted: "1.2.8", Latest: "1.2.8", Dependent: "app", Type: "devDependencies"},
+	}
+	if len(outdated) != len(expected) || outdated[0] != expected[0] || outdated[1] != expected[1] {
+		t.Errorf("Outdated() = %+v, expected %+v", outdated, expected)
+	}
+
+	report, err := client.Audit(ctx, AuditOptions{WorkingDir: dir, Production: true})
+	if err != nil {
+		t.Fatalf("Audit() failed: %v", err)
+	}
+	finding := report.Finding("lodash")
+	if report.Total != 1 || report.Counts[SeverityCritical] != 1 || report.Dependencies != 1 || finding == nil {
+		t.Fatalf("Unexpected audit report: %+v", report)
+	}
+	if !finding.IsDirect || finding.Severity != SeverityCritical || len(finding.Advisories) != 1 || finding.Advisories[0].ID() != "GHSA-jf85-cpcp-j695" {
+		t.Errorf("Unexpected finding: %+v", finding)
+	}
+
+	// 关闭回退时返回npm的错误
+	client, _ = NewClientWithExecutor("npm", executor, WithRegistryFallback(nil))
+	if _, err := client.GetPackageInfo(ctx, "lodash"); !IsNpmNotFound(err) {
+		t.Errorf("Expected npm not found error without fallback, got %v", err)
+	}
+}
+
+func TestClientRegistryFallbackMissingPath(t *testing.T) {
+	server := fallbackServer(t)
+	client, err := NewClientWithPath(filepath.Join(t.TempDir(), "npm"), WithRegistryFallback(registry.NewClient(server.URL)))
+	if err != nil {
+		t.Fatalf("NewClientWithPath() failed: %v", err)
+	}
+	if info, err := client.GetPackageInfo(context.Background(), "lodash@latest"); err != nil || info.Version != "4.17.21" {
+		t.Errorf("GetPackageInfo() = %+v, %v", info, err)
+	}
+}
+
+func TestResolveSpec(t *testing.T) {
+	tags := map[string]string{"latest": "2.0.0", "next": "3.0.0-beta.1"}
+	versions := []string{"1.0.0", "1.5.0", "2.0.0", "2.1.0", "3.0.0-beta.1"}
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"", "2.0.0"},
+		{"next", "3.0.0-beta.1"},
+		{"^1.0.0", "1.5.0"},
+		{"^2.0.0", "2.0.0"}, // latest满足范围时优先latest
+		{">=2.1.0", "2.1.0"},
+		{"^9.0.0", ""},
+		{"github:user/repo", ""},
+	}
+	for _, tt := range tests {
+		if got := resolveSpec(tt.spec, tags, versions); got != tt.expected {
+			t.Errorf("resolveSpec(%q) = %q, expected %q", tt.spec, got, tt.expected)
+		}
+	}
+
+	for spec, expected := range map[string]bool{"^1.0.0": true, "latest": true, "file:../local": false, "user/repo": false, "npm:other@1": false} {
+		if isRegistrySpec(spec) != expected {
+			t.Errorf("isRegistrySpec(%q) = %v", spec, !expected)
+		}
+	}
+
+	if name, spec := splitPackageSpec("@scope/pkg@^1.0.0"); name != "@scope/pkg" || spec != "^1.0.0" {
+		t.Errorf("splitPackageSpec() = %q, %q", name, spec)
+	}
+	if name, spec := splitPackageSpec("@scope/pkg"); name != "@scope/pkg" || spec != "" {
+		t.Errorf("splitPackageSpec() = %q, %q", name, spec)
+	}
+}
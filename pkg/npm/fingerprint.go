@@ -0,0 +1,232 @@
+package npm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/lockfile"
+	"github.com/scagogogo/go-npm-sdk/pkg/platform"
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// EnvironmentLockFile 项目中记录执行环境指纹的文件，应与锁文件一起提交
+const EnvironmentLockFile = ".npm-sdk.lock"
+
+// EnvironmentFingerprint 执行npm的环境指纹
+type EnvironmentFingerprint struct {
+	Node     string                `json:"node"`
+	Npm      string                `json:"npm"`
+	Platform platform.Platform     `json:"platform"`
+	Arch     platform.Architecture `json:"arch"`
+	Libc     platform.Libc         `json:"libc,omitempty"` // 只在Linux上检测
+	Registry string                `json:"registry"`
+
+	// Lockfile 记录指纹时锁文件内容的SHA-256，没有锁文件时为空
+	Lockfile string `json:"lockfile,omitempty"`
+}
+
+// FingerprintSeverity 环境差异的严重程度
+type FingerprintSeverity string
+
+const (
+	FingerprintWarning FingerprintSeverity = "warning" // 可能导致安装结果不同，例如Node.js主版本、平台、registry
+	FingerprintInfo    FingerprintSeverity = "info"    // 通常不影响结果，例如补丁版本
+)
+
+// FingerprintDifference 记录的环境和当前环境的一项差异
+type FingerprintDifference struct {
+	Field    string              `json:"field"`
+	Recorded string              `json:"recorded"`
+	Current  string              `json:"current"`
+	Severity FingerprintSeverity `json:"severity"`
+	Message  string              `json:"message"`
+}
+
+// EnvironmentDriftEvent DependencyManager.Install之前发现当前环境与项目记录的指纹不同时发送的事件
+type EnvironmentDriftEvent struct {
+	WorkingDir  string                  `json:"working_dir"`
+	Differences []FingerprintDifference `json:"differences"`
+}
+
+// EventType 实现Event接口
+func (e EnvironmentDriftEvent) EventType() string {
+	return "environment.drift"
+}
+
+// FingerprintCheck 当前环境与项目记录的指纹的比较结果
+type FingerprintCheck struct {
+	Recorded    *EnvironmentFingerprint `json:"recorded"` // 项目中没有记录时为nil
+	Current     *EnvironmentFingerprint `json:"current"`
+	Differences []FingerprintDifference `json:"differences,omitempty"`
+}
+
+// Warnings 返回严重程度为warning的差异
+func (c *FingerprintCheck) Warnings() []FingerprintDifference {
+	var warnings []FingerprintDifference
+	for _, diff := range c.Differences {
+		if diff.Severity == FingerprintWarning {
+			warnings = append(warnings, diff)
+		}
+	}
+	return warnings
+}
+
+// ComputeFingerprint 计算在dir中执行npm的环境指纹
+//
+// Node.js版本和registry取自npm config list，与npm实际使用的一致。
+func ComputeFingerprint(ctx context.Context, client Client, dir string) (*EnvironmentFingerprint, error) {
+	npmVersion, err := client.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config, err := client.ConfigList(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := &EnvironmentFingerprint{
+		Node:     strings.TrimPrefix(config["node-version"], "v"),
+		Npm:      npmVersion,
+		Platform: platform.Platform(runtime.GOOS),
+		Arch:     platform.Architecture(runtime.GOARCH),
+		Libc:     platform.DetectLibc(),
+		Registry: config["registry"],
+	}
+	if fingerprint.Registry != "" && !strings.HasSuffix(fingerprint.Registry, "/") {
+		fingerprint.Registry += "/"
+	}
+	fingerprint.Lockfile, err = lockfileHash(dir)
+	if err != nil {
+		return nil, err
+	}
+	return fingerprint, nil
+}
+
+// LoadFingerprint 读取dir中的EnvironmentLockFile，文件不存在时错误满足errors.Is(err, fs.ErrNotExist)
+func LoadFingerprint(dir string) (*EnvironmentFingerprint, error) {
+	data, err := os.ReadFile(filepath.Join(dir, EnvironmentLockFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", EnvironmentLockFile, err)
+	}
+	var fingerprint EnvironmentFingerprint
+	if err := utils.DecodeJSON(EnvironmentLockFile, data, &fingerprint); err != nil {
+		return nil, err
+	}
+	return &fingerprint, nil
+}
+
+// Save 把指纹写入dir中的EnvironmentLockFile
+func (f *EnvironmentFingerprint) Save(dir string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fingerprint: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, EnvironmentLockFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", EnvironmentLockFile, err)
+	}
+	return nil
+}
+
+// CompareFingerprints 比较记录的指纹和当前的指纹，返回的差异按字段的固定顺序排列
+func CompareFingerprints(recorded, current *EnvironmentFingerprint) []FingerprintDifference {
+	var diffs []FingerprintDifference
+	add := func(field, old, cur string, severity FingerprintSeverity, message string) {
+		diffs = append(diffs, FingerprintDifference{Field: field, Recorded: old, Current: cur, Severity: severity, Message: message})
+	}
+	version := func(field, name, old, cur string) {
+		if old == cur || old == "" || cur == "" {
+			return
+		}
+		oldVersion, oldErr := semver.Parse(old)
+		curVersion, curErr := semver.Parse(cur)
+		if oldErr == nil && curErr == nil && oldVersion.Major == curVersion.Major {
+			add(field, old, cur, FingerprintInfo, fmt.Sprintf("%s version changed from %s to %s", name, old, cur))
+			return
+		}
+		add(field, old, cur, FingerprintWarning, fmt.Sprintf("%s major version changed from %s to %s", name, old, cur))
+	}
+	exact := func(field, old, cur, message string) {
+		if old != cur && old != "" && cur != "" {
+			add(field, old, cur, FingerprintWarning, fmt.Sprintf("%s: recorded %s, current %s", message, old, cur))
+		}
+	}
+
+	version("node", "Node.js", recorded.Node, current.Node)
+	version("npm", "npm", recorded.Npm, current.Npm)
+	exact("platform", string(recorded.Platform), string(current.Platform), "platform differs, optional native dependencies may resolve differently")
+	exact("arch", string(recorded.Arch), string(current.Arch), "architecture differs, optional native dependencies may resolve differently")
+	exact("libc", string(recorded.Libc), string(current.Libc), "libc differs, prebuilt native modules may not load")
+	exact("registry", recorded.Registry, current.Registry, "registry differs, resolved versions and tarball URLs may change")
+	if recorded.Lockfile != current.Lockfile && recorded.Lockfile != "" && current.Lockfile != "" {
+		add("lockfile", recorded.Lockfile, current.Lockfile, FingerprintInfo, "lockfile changed since the fingerprint was recorded")
+	}
+	return diffs
+}
+
+// RecordFingerprint 计算当前环境的指纹并写入项目的EnvironmentLockFile
+func (dm *DependencyManager) RecordFingerprint(ctx context.Context) (*EnvironmentFingerprint, error) {
+	fingerprint, err := ComputeFingerprint(ctx, dm.client, dm.workingDir)
+	if err != nil {
+		return nil, err
+	}
+	return fingerprint, fingerprint.Save(dm.workingDir)
+}
+
+// CheckFingerprint 计算当前环境的指纹并与项目中记录的比较，适合在安装等耗时操作之前调用
+//
+// 项目中没有EnvironmentLockFile时Recorded为nil，不报告差异。
+func (dm *DependencyManager) CheckFingerprint(ctx context.Context) (*FingerprintCheck, error) {
+	current, err := ComputeFingerprint(ctx, dm.client, dm.workingDir)
+	if err != nil {
+		return nil, err
+	}
+	check := &FingerprintCheck{Current: current}
+	recorded, err := LoadFingerprint(dm.workingDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return check, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	check.Recorded = recorded
+	check.Differences = CompareFingerprints(recorded, current)
+	return check, nil
+}
+
+// warnEnvironmentDrift 项目记录了指纹时比较当前环境，有差异时在事件总线上发送EnvironmentDriftEvent
+//
+// 只是提醒，检查失败不影响后续操作。
+func (dm *DependencyManager) warnEnvironmentDrift(ctx context.Context) {
+	if _, err := os.Stat(filepath.Join(dm.workingDir, EnvironmentLockFile)); err != nil {
+		return
+	}
+	check, err := dm.CheckFingerprint(ctx)
+	if err != nil || len(check.Differences) == 0 {
+		return
+	}
+	dm.client.Events().Emit(EnvironmentDriftEvent{WorkingDir: dm.workingDir, Differences: check.Differences})
+}
+
+// lockfileHash 项目锁文件内容的SHA-256，没有锁文件时返回空字符串
+func lockfileHash(dir string) (string, error) {
+	path, err := lockfile.Locate(dir)
+	if err != nil {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,120 @@
+package npm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/platform"
+)
+
+// envClient 返回固定npm版本和配置的客户端
+type envClient struct {
+	*MockClient
+	npmVersion string
+	config     map[string]string
+	events     *EventBus
+}
+
+func (c *envClient) Version(ctx context.Context) (string, error) {
+	return c.npmVersion, nil
+}
+
+func (c *envClient) ConfigList(ctx context.Context, workingDir string) (map[string]string, error) {
+	return c.config, nil
+}
+
+func (c *envClient) Events() *EventBus {
+	return c.events
+}
+
+func TestCompareFingerprints(t *testing.T) {
+	recorded := &EnvironmentFingerprint{Node: "20.11.0", Npm: "10.2.4", Platform: "linux", Arch: "amd64", Libc: platform.Glibc, Registry: "https://registry.npmjs.org/", Lockfile: "aaa"}
+
+	if diffs := CompareFingerprints(recorded, recorded); len(diffs) != 0 {
+		t.Errorf("Expected no differences, got %+v", diffs)
+	}
+
+	current := *recorded
+	current.Node = "22.1.0"
+	current.Npm = "10.5.0"
+	current.Libc = platform.Musl
+	current.Registry = "https://npm.example.com/"
+	current.Lockfile = "bbb"
+	var got []string
+	for _, diff := range CompareFingerprints(recorded, &current) {
+		got = append(got, diff.Field+":"+string(diff.Severity))
+	}
+	expected := []string{"node:warning", "npm:info", "libc:warning", "registry:warning", "lockfile:info"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected differences: %v", got)
+	}
+
+	// 旧的记录没有libc时不报告
+	current = *recorded
+	current.Libc = ""
+	if diffs := CompareFingerprints(recorded, &current); len(diffs) != 0 {
+		t.Errorf("Expected unknown libc to be ignored, got %+v", diffs)
+	}
+}
+
+func TestCheckFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name":"app","version":"1.0.0"}`)
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), `{"lockfileVersion":3,"packages":{}}`)
+
+	client := &envClient{
+		MockClient: NewMockClient(),
+		npmVersion: "10.2.4",
+		config:     map[string]string{"node-version": "v20.11.0", "registry": "https://registry.npmjs.org"},
+		events:     NewEventBus(),
+	}
+	dm, err := NewDependencyManager(client, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check, err := dm.CheckFingerprint(context.Background())
+	if err != nil {
+		t.Fatalf("CheckFingerprint() failed: %v", err)
+	}
+	if check.Recorded != nil || check.Current.Node != "20.11.0" || check.Current.Registry != "https://registry.npmjs.org/" || check.Current.Lockfile == "" {
+		t.Errorf("Unexpected check without a recorded fingerprint: %+v", check.Current)
+	}
+
+	recorded, err := dm.RecordFingerprint(context.Background())
+	if err != nil {
+		t.Fatalf("RecordFingerprint() failed: %v", err)
+	}
+	loaded, err := LoadFingerprint(dir)
+	if err != nil || !reflect.DeepEqual(loaded, recorded) {
+		t.Errorf("LoadFingerprint() = %+v, %v", loaded, err)
+	}
+
+	// 换了Node.js主版本后安装前发送事件
+	client.config["node-version"] = "v22.1.0"
+	var drift []EnvironmentDriftEvent
+	client.events.Subscribe(func(event Event) {
+		if e, ok := event.(EnvironmentDriftEvent); ok {
+			drift = append(drift, e)
+		}
+	})
+	if err := dm.Install(context.Background()); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if len(drift) != 1 || len(drift[0].Differences) != 1 || drift[0].Differences[0].Field != "node" {
+		t.Fatalf("Expected one node drift event, got %+v", drift)
+	}
+
+	check, err = dm.CheckFingerprint(context.Background())
+	if err != nil || len(check.Warnings()) != 1 {
+		t.Errorf("Expected one warning, got %+v, %v", check, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, EnvironmentLockFile), []byte("{"), 0644)
+	if _, err := dm.CheckFingerprint(context.Background()); err == nil {
+		t.Error("Expected error for a corrupt fingerprint file")
+	}
+}
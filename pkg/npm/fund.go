@@ -0,0 +1,98 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// FundingSource 一个资助渠道
+type FundingSource = npmiface.FundingSource
+
+// Funding package.json的funding字段
+type Funding = npmiface.Funding
+
+// FundOptions npm fund选项
+type FundOptions = npmiface.FundOptions
+
+// FundingNode 资助树中的一个包
+type FundingNode = npmiface.FundingNode
+
+// FundResult npm fund的结果，根节点为项目本身
+type FundResult = npmiface.FundResult
+
+// Fund 运行npm fund --json，返回依赖的资助信息树
+func (c *client) Fund(ctx context.Context, options FundOptions) (*FundResult, error) {
+	executeOptions := c.fundCommand(options)
+
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return nil, NewNpmError("fund", "", -1, "", "", err)
+		}
+		return nil, NewNpmError("fund", "", result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	return parseFundJSON([]byte(result.Stdout))
+}
+
+// fundCommand 构造npm fund的执行选项
+func (c *client) fundCommand(options FundOptions) utils.ExecuteOptions {
+	args := []string{"fund", "--json"}
+	for _, workspace := range options.Workspaces {
+		args = append(args, "--workspace", workspace)
+	}
+
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          args,
+		Env:           commandEnv(options.Env, options.UserConfig),
+		WorkingDir:    options.WorkingDir,
+		CaptureOutput: true,
+		Timeout:       time.Minute,
+	}
+}
+
+// fundJSONNode npm fund --json输出中的节点，依赖以对象形式给出
+type fundJSONNode struct {
+	Name         string                  `json:"name"`
+	Version      string                  `json:"version"`
+	Funding      Funding                 `json:"funding"`
+	Dependencies map[string]fundJSONNode `json:"dependencies"`
+}
+
+// parseFundJSON 解析npm fund --json的输出
+func parseFundJSON(data []byte) (*FundResult, error) {
+	var root struct {
+		fundJSONNode
+		Length int `json:"length"`
+	}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse fund output: %w", err)
+	}
+
+	return &FundResult{
+		FundingNode: root.fundJSONNode.toNode(root.Name),
+		Length:      root.Length,
+	}, nil
+}
+
+// toNode 转换为FundingNode，依赖按名称排序
+func (n fundJSONNode) toNode(name string) FundingNode {
+	node := FundingNode{Name: name, Version: n.Version, Funding: n.Funding}
+
+	names := make([]string, 0, len(n.Dependencies))
+	for dependency := range n.Dependencies {
+		names = append(names, dependency)
+	}
+	sort.Strings(names)
+	for _, dependency := range names {
+		node.Dependencies = append(node.Dependencies, n.Dependencies[dependency].toNode(dependency))
+	}
+	return node
+}
@@ -0,0 +1,151 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const fundFixture = `{
+  "length": 2,
+  "name": "app",
+  "version": "1.0.0",
+  "funding": {"url": "https://example.com/app"},
+  "dependencies": {
+    "a": {
+      "version": "1.0.0",
+      "funding": [
+        {"type": "github", "url": "https://github.com/sponsors/a"},
+        {"url": "https://opencollective.com/a"}
+      ],
+      "dependencies": {
+        "c": {"version": "3.0.0", "funding": {"type": "github", "url": "https://github.com/sponsors/a"}}
+      }
+    },
+    "b": {"dependencies": {}}
+  }
+}`
+
+func TestFundingJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Funding
+		output   string
+	}{
+		{`"https://example.com"`, Funding{{URL: "https://example.com"}}, `"https://example.com"`},
+		{`{"type":"github","url":"https://github.com/sponsors/x"}`, Funding{{Type: "github", URL: "https://github.com/sponsors/x"}}, `{"type":"github","url":"https://github.com/sponsors/x"}`},
+		{`["https://a.example", {"type":"patreon","url":"https://b.example"}]`, Funding{{URL: "https://a.example"}, {Type: "patreon", URL: "https://b.example"}}, `[{"url":"https://a.example"},{"type":"patreon","url":"https://b.example"}]`},
+	}
+
+	for _, test := range tests {
+		var funding Funding
+		if err := json.Unmarshal([]byte(test.input), &funding); err != nil {
+			t.Errorf("Unmarshal(%s) failed: %v", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(funding, test.expected) {
+			t.Errorf("Unmarshal(%s) = %+v, expected %+v", test.input, funding, test.expected)
+		}
+		data, err := json.Marshal(funding)
+		if err != nil || string(data) != test.output {
+			t.Errorf("Marshal(%+v) = %s, %v, expected %s", funding, data, err, test.output)
+		}
+	}
+
+	var funding Funding
+	if err := json.Unmarshal([]byte(`42`), &funding); err == nil {
+		t.Error("Expected error for invalid funding")
+	}
+}
+
+func TestPackageJSONFunding(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	writeTestFile(t, path, `{"name":"app","version":"1.0.0","funding":"https://example.com/app"}`)
+
+	pkg := NewPackageJSON(path)
+	if err := pkg.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if urls := pkg.GetFunding().URLs(); len(urls) != 1 || urls[0] != "https://example.com/app" {
+		t.Fatalf("GetFunding() = %v", urls)
+	}
+
+	pkg.AddFunding("github", "https://github.com/sponsors/app")
+	pkg.AddFunding("github", "https://github.com/sponsors/app")
+	if err := pkg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	reloaded := NewPackageJSON(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	expected := Funding{{URL: "https://example.com/app"}, {Type: "github", URL: "https://github.com/sponsors/app"}}
+	if !reflect.DeepEqual(reloaded.GetFunding(), expected) {
+		t.Errorf("GetFunding() = %+v, expected %+v", reloaded.GetFunding(), expected)
+	}
+
+	reloaded.SetFunding(nil)
+	if len(reloaded.GetFunding()) != 0 {
+		t.Error("SetFunding(nil) should clear funding")
+	}
+}
+
+func TestParseFundJSON(t *testing.T) {
+	result, err := parseFundJSON([]byte(fundFixture))
+	if err != nil {
+		t.Fatalf("parseFundJSON() failed: %v", err)
+	}
+	if result.Name != "app" || result.Length != 2 || len(result.Dependencies) != 2 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result.Dependencies[0].Name != "a" || result.Dependencies[0].Dependencies[0].Name != "c" {
+		t.Errorf("Expected dependencies sorted by name, got %+v", result.Dependencies)
+	}
+
+	var names []string
+	for _, pkg := range result.Packages() {
+		names = append(names, pkg.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"app", "a", "c"}) {
+		t.Errorf("Packages() = %v", names)
+	}
+
+	groups := result.ByURL()
+	if !reflect.DeepEqual(groups["https://github.com/sponsors/a"], []string{"a@1.0.0", "c@3.0.0"}) {
+		t.Errorf("ByURL() = %v", groups)
+	}
+
+	if _, err := parseFundJSON([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+func TestClientFund(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if !client.IsAvailable(ctx) {
+		t.Skip("npm not available")
+	}
+
+	tempDir := t.TempDir()
+	writeTestFile(t, filepath.Join(tempDir, "package.json"), `{"name":"fund-test","version":"1.0.0","dependencies":{"a":"1.0.0"}}`)
+	writeTestFile(t, filepath.Join(tempDir, "node_modules", "a", "package.json"), `{"name":"a","version":"1.0.0","funding":{"type":"github","url":"https://github.com/sponsors/a"}}`)
+
+	result, err := client.Fund(ctx, FundOptions{WorkingDir: tempDir})
+	if err != nil {
+		t.Fatalf("Fund() failed: %v", err)
+	}
+	if result.Name != "fund-test" || len(result.Dependencies) != 1 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if urls := result.Dependencies[0].Funding.URLs(); len(urls) != 1 || urls[0] != "https://github.com/sponsors/a" {
+		t.Errorf("Unexpected funding: %v", urls)
+	}
+}
@@ -0,0 +1,73 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// ListGlobal 列出全局安装的顶层包
+func (c *client) ListGlobal(ctx context.Context) ([]Package, error) {
+	return c.ListPackages(ctx, ListOptions{Global: true, JSON: true})
+}
+
+// InstallGlobal 全局安装包，options.Global总是为true
+//
+// 安装到npm的全局prefix下，可以通过options.Env设置npm_config_prefix安装到其他目录。
+func (c *client) InstallGlobal(ctx context.Context, pkg string, options InstallOptions) error {
+	options.Global = true
+	return c.InstallPackage(ctx, pkg, options)
+}
+
+// UninstallGlobal 卸载全局安装的包，options.Global总是为true
+func (c *client) UninstallGlobal(ctx context.Context, pkg string, options UninstallOptions) error {
+	options.Global = true
+	return c.UninstallPackage(ctx, pkg, options)
+}
+
+// GlobalRoot 返回全局node_modules目录
+//
+// 根据npm prefix -g计算：Windows上全局包直接安装在<prefix>\node_modules，
+// 其他平台安装在<prefix>/lib/node_modules。
+func (c *client) GlobalRoot(ctx context.Context) (string, error) {
+	result, err := c.executor.Execute(ctx, c.globalPrefixCommand())
+	if err != nil {
+		if result == nil {
+			return "", NewNpmError("prefix", "", -1, "", "", err)
+		}
+		return "", NewNpmError("prefix", "", result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	if !result.Success {
+		return "", NewNpmError("prefix", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm prefix failed"))
+	}
+
+	prefix := strings.TrimSpace(result.Stdout)
+	if prefix == "" {
+		return "", NewNpmError("prefix", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm prefix returned an empty path"))
+	}
+	return globalRootDir(prefix, runtime.GOOS), nil
+}
+
+// globalPrefixCommand 构造npm prefix -g的执行选项
+func (c *client) globalPrefixCommand() utils.ExecuteOptions {
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"prefix", "--global"},
+		CaptureOutput: true,
+		Timeout:       30 * time.Second,
+	}
+}
+
+// globalRootDir 根据全局prefix计算目标平台上的全局node_modules目录
+func globalRootDir(prefix, goos string) string {
+	if goos == "windows" {
+		return strings.TrimRight(prefix, `\/`) + `\node_modules`
+	}
+	return path.Join(prefix, "lib", "node_modules")
+}
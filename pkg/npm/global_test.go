@@ -0,0 +1,76 @@
+package npm
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+func TestGlobalRootDir(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		goos     string
+		expected string
+	}{
+		{"/usr/local", "linux", "/usr/local/lib/node_modules"},
+		{"/opt/homebrew/", "darwin", "/opt/homebrew/lib/node_modules"},
+		{`C:\Users\me\AppData\Roaming\npm`, "windows", `C:\Users\me\AppData\Roaming\npm\node_modules`},
+		{`C:\Program Files\nodejs\`, "windows", `C:\Program Files\nodejs\node_modules`},
+	}
+	for _, tt := range tests {
+		if got := globalRootDir(tt.prefix, tt.goos); got != tt.expected {
+			t.Errorf("globalRootDir(%q, %q) = %q, expected %q", tt.prefix, tt.goos, got, tt.expected)
+		}
+	}
+}
+
+func TestClientGlobalHelpers(t *testing.T) {
+	executor := &listExecutor{result: &utils.ExecuteResult{Success: true, Stdout: "/home/me/.npm-global\n"}}
+	client, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	ctx := context.Background()
+
+	root, err := client.GlobalRoot(ctx)
+	if err != nil {
+		t.Fatalf("GlobalRoot() failed: %v", err)
+	}
+	if strings.Join(executor.args, " ") != "prefix --global" || root != globalRootDir("/home/me/.npm-global", runtime.GOOS) {
+		t.Errorf("Unexpected root %q from %v", root, executor.args)
+	}
+
+	if err := client.InstallGlobal(ctx, "typescript", InstallOptions{Registry: "https://registry.example.com/"}); err != nil {
+		t.Fatalf("InstallGlobal() failed: %v", err)
+	}
+	if strings.Join(executor.args, " ") != "install typescript --global --registry https://registry.example.com/" {
+		t.Errorf("Unexpected install args: %v", executor.args)
+	}
+
+	if err := client.UninstallGlobal(ctx, "typescript", UninstallOptions{}); err != nil {
+		t.Fatalf("UninstallGlobal() failed: %v", err)
+	}
+	if strings.Join(executor.args, " ") != "uninstall typescript --global" {
+		t.Errorf("Unexpected uninstall args: %v", executor.args)
+	}
+
+	executor.result.Stdout = `{"dependencies": {"typescript": {"version": "5.4.5"}, "npm": {"version": "10.8.2"}}}`
+	packages, err := client.ListGlobal(ctx)
+	if err != nil || len(packages) != 2 || packages[1].Name != "typescript" {
+		t.Errorf("ListGlobal() = %+v, %v", packages, err)
+	}
+	if strings.Join(executor.args, " ") != "list --global --json" {
+		t.Errorf("Unexpected list args: %v", executor.args)
+	}
+
+	if err := client.InstallGlobal(ctx, "", InstallOptions{}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+	executor.result = &utils.ExecuteResult{Success: true, Stdout: "\n"}
+	if _, err := client.GlobalRoot(ctx); err == nil {
+		t.Error("Expected error for empty prefix")
+	}
+}
@@ -0,0 +1,374 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/lockfile"
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+)
+
+// GraphNode 依赖图中的一个包版本
+type GraphNode struct {
+	ID        string `json:"id"` // name@version，项目本身为项目名或"root"
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	Root      bool   `json:"root,omitempty"`
+	Workspace bool   `json:"workspace,omitempty"` // monorepo中的工作区包
+	Dev       bool   `json:"dev,omitempty"`       // 只被开发依赖引用
+	Optional  bool   `json:"optional,omitempty"`  // 只被可选依赖引用
+	Missing   bool   `json:"missing,omitempty"`   // 被依赖但没有解析到版本，ID为name@range
+}
+
+// GraphEdge 依赖图中的一条依赖关系
+type GraphEdge struct {
+	From  string         `json:"from"`
+	To    string         `json:"to"`
+	Type  DependencyType `json:"type"`
+	Range string         `json:"range,omitempty"` // 依赖要求的版本范围
+}
+
+// DependencyGraph 项目的依赖图
+//
+// 节点按ID排序，项目本身排在最前面；边按起点、终点和类型排序。
+type DependencyGraph struct {
+	Root  string      `json:"root"`
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// Graph 获取项目的依赖图
+//
+// 有锁文件时根据锁文件构建，不需要安装依赖；没有锁文件时使用npm ls的依赖树。
+// 项目直接依赖的边按package.json中的类型区分，传递依赖的边都是dependencies类型。
+// 项目配置了工作区时，工作区包作为节点加入，工作区包之间的依赖按各自package.json中的类型添加边。
+func (dm *DependencyManager) Graph(ctx context.Context) (*DependencyGraph, error) {
+	// package.json不存在时使用锁文件记录的直接依赖
+	hasPackageJSON := dm.LoadPackageJSON() == nil
+
+	var builder *graphBuilder
+	if lock := loadProjectLockfile(dm.workingDir); lock != nil {
+		builder = newGraphBuilder(dm.graphRootID(hasPackageJSON, lock.Name, lock.Version), dm.graphRootName(hasPackageJSON, lock.Name))
+		builder.addLockfile(lock, dm.rootDependencies(hasPackageJSON, lock))
+	} else {
+		tree, err := dm.GetDependencyTree(ctx)
+		if err != nil && len(tree) == 0 {
+			return nil, err
+		}
+		builder = newGraphBuilder(dm.graphRootID(hasPackageJSON, "", ""), dm.graphRootName(hasPackageJSON, ""))
+		builder.addTree(tree, dm.rootDependencies(hasPackageJSON, nil))
+	}
+
+	// 没有配置工作区时Workspaces返回错误
+	if workspaces, err := NewWorkspaceManager(dm.workingDir).Workspaces(); err == nil {
+		builder.addWorkspaces(workspaces)
+	}
+	return builder.build(), nil
+}
+
+// graphRootID 项目节点的ID
+func (dm *DependencyManager) graphRootID(hasPackageJSON bool, name, version string) string {
+	if hasPackageJSON {
+		name, version = dm.packageJSON.GetName(), dm.packageJSON.GetVersion()
+	}
+	switch {
+	case name == "":
+		return "root"
+	case version == "":
+		return name
+	}
+	return name + "@" + version
+}
+
+// graphRootName 项目节点的名称
+func (dm *DependencyManager) graphRootName(hasPackageJSON bool, name string) string {
+	if hasPackageJSON {
+		name = dm.packageJSON.GetName()
+	}
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
+// rootDependencies 项目直接依赖按类型分组
+func (dm *DependencyManager) rootDependencies(hasPackageJSON bool, lock *lockfile.Lockfile) map[DependencyType]map[string]string {
+	deps := make(map[DependencyType]map[string]string)
+	switch {
+	case hasPackageJSON:
+		deps[Production] = dm.packageJSON.GetDependencies()
+		deps[Development] = dm.packageJSON.GetDevDependencies()
+		deps[Optional] = dm.packageJSON.GetOptionalDependencies()
+		deps[Peer] = dm.packageJSON.GetPeerDependencies()
+	case lock != nil && lock.Root != nil:
+		deps[Production] = lock.Root.Dependencies
+		deps[Development] = lock.Root.DevDependencies
+		deps[Optional] = lock.Root.OptionalDependencies
+	}
+	return deps
+}
+
+// graphBuilder 构建依赖图
+type graphBuilder struct {
+	root  string
+	nodes map[string]*GraphNode
+	edges map[GraphEdge]bool
+}
+
+// newGraphBuilder 创建只包含项目节点的构建器
+func newGraphBuilder(rootID, rootName string) *graphBuilder {
+	return &graphBuilder{
+		root:  rootID,
+		nodes: map[string]*GraphNode{rootID: {ID: rootID, Name: rootName, Root: true}},
+		edges: make(map[GraphEdge]bool),
+	}
+}
+
+// addNode 添加节点，已存在时返回已有节点
+func (b *graphBuilder) addNode(node GraphNode) *GraphNode {
+	if existing, ok := b.nodes[node.ID]; ok {
+		return existing
+	}
+	b.nodes[node.ID] = &node
+	return &node
+}
+
+// addLockfile 根据锁文件添加所有包和依赖关系
+func (b *graphBuilder) addLockfile(lock *lockfile.Lockfile, root map[DependencyType]map[string]string) {
+	byRange := make(map[string]*lockfile.Package)
+	versions := make(map[string][]string)
+	for _, pkg := range lock.Packages {
+		b.addNode(GraphNode{ID: pkg.Key(), Name: pkg.Name, Version: pkg.Version, Dev: pkg.Dev, Optional: pkg.Optional})
+		byRange[pkg.Key()] = pkg
+		for _, rng := range pkg.Ranges {
+			byRange[pkg.Name+"@"+rng] = pkg
+		}
+		if !slices.Contains(versions[pkg.Name], pkg.Version) {
+			versions[pkg.Name] = append(versions[pkg.Name], pkg.Version)
+		}
+	}
+
+	// resolve 依次按锁文件记录的范围、唯一版本和满足范围的最高版本查找
+	resolve := func(name, rng string) string {
+		if pkg, ok := byRange[name+"@"+rng]; ok {
+			return pkg.Key()
+		}
+		if candidates := versions[name]; len(candidates) == 1 {
+			return name + "@" + candidates[0]
+		} else if version := semver.MaxSatisfying(candidates, rng); version != "" {
+			return name + "@" + version
+		}
+		return ""
+	}
+
+	link := func(from, name, rng string, depType DependencyType) {
+		to := resolve(name, rng)
+		if to == "" {
+			// 未安装的可选依赖和同级依赖不算缺失
+			if depType == Optional || depType == Peer {
+				return
+			}
+			to = name + "@" + rng
+			b.addNode(GraphNode{ID: to, Name: name, Missing: true})
+		}
+		b.edges[GraphEdge{From: from, To: to, Type: depType, Range: rng}] = true
+	}
+
+	for _, depType := range []DependencyType{Production, Development, Optional, Peer} {
+		for name, rng := range root[depType] {
+			link(b.root, name, rng, depType)
+		}
+	}
+	for _, pkg := range lock.Packages {
+		for name, rng := range pkg.Dependencies {
+			link(pkg.Key(), name, rng, Production)
+		}
+	}
+}
+
+// addTree 根据npm ls的依赖树添加包和依赖关系
+func (b *graphBuilder) addTree(tree []Package, root map[DependencyType]map[string]string) {
+	types := make(map[string]DependencyType)
+	for _, depType := range []DependencyType{Peer, Optional, Development, Production} {
+		for name := range root[depType] {
+			types[name] = depType
+		}
+	}
+
+	var walk func(from string, children []Package, direct bool)
+	walk = func(from string, children []Package, direct bool) {
+		for _, child := range children {
+			node := GraphNode{ID: child.Name + "@" + child.Version, Name: child.Name, Version: child.Version}
+			if child.Missing || child.Version == "" {
+				node = GraphNode{ID: child.Name + "@" + child.Required, Name: child.Name, Missing: true}
+			}
+			depType := Production
+			if direct && types[child.Name] != "" {
+				depType = types[child.Name]
+			}
+			_, seen := b.nodes[node.ID]
+			b.addNode(node)
+			b.edges[GraphEdge{From: from, To: node.ID, Type: depType, Range: child.Required}] = true
+			// npm ls对重复出现的包只展开一次，已经展开过的节点不再遍历
+			if !seen {
+				walk(node.ID, child.Children, false)
+			}
+		}
+	}
+	walk(b.root, tree, true)
+}
+
+// addWorkspaces 添加工作区包和工作区包之间的依赖
+//
+// 锁文件不记录工作区源码目录中的包，npm ls中的工作区包已经在图中时只标记为工作区。
+func (b *graphBuilder) addWorkspaces(workspaces []Workspace) {
+	ids := make(map[string]string, len(workspaces))
+	for _, workspace := range workspaces {
+		id := workspace.Name
+		if workspace.Version != "" {
+			id += "@" + workspace.Version
+		}
+		ids[workspace.Dir] = id
+		b.addNode(GraphNode{ID: id, Name: workspace.Name, Version: workspace.Version}).Workspace = true
+	}
+
+	exists := make(map[[3]string]bool)
+	for edge := range b.edges {
+		exists[[3]string{edge.From, edge.To, string(edge.Type)}] = true
+	}
+	forEachDependency(workspaces, func(workspace *Workspace, depType DependencyType, name, spec string) {
+		target := findWorkspaceByName(workspaces, name)
+		if rest, found := strings.CutPrefix(spec, workspaceProtocol); found && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/")) {
+			target = findWorkspaceByDir(workspaces, workspace.Dir, rest)
+		} else if prefix, path, found := strings.Cut(spec, ":"); found && (prefix == "file" || prefix == "link") {
+			target = findWorkspaceByDir(workspaces, workspace.Dir, path)
+		}
+		if target == nil {
+			return
+		}
+		edge := GraphEdge{From: ids[workspace.Dir], To: ids[target.Dir], Type: depType, Range: spec}
+		if key := [3]string{edge.From, edge.To, string(edge.Type)}; !exists[key] {
+			exists[key] = true
+			b.edges[edge] = true
+		}
+	})
+}
+
+// build 生成排序后的依赖图
+func (b *graphBuilder) build() *DependencyGraph {
+	graph := &DependencyGraph{Root: b.root, Nodes: []GraphNode{*b.nodes[b.root]}, Edges: []GraphEdge{}}
+	for _, id := range slices.Sorted(maps.Keys(b.nodes)) {
+		if id != b.root {
+			graph.Nodes = append(graph.Nodes, *b.nodes[id])
+		}
+	}
+	for edge := range b.edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, c := graph.Edges[i], graph.Edges[j]
+		if a.From != c.From {
+			return a.From < c.From
+		}
+		if a.To != c.To {
+			return a.To < c.To
+		}
+		if a.Type != c.Type {
+			return a.Type < c.Type
+		}
+		return a.Range < c.Range
+	})
+	return graph
+}
+
+// Node 查找节点，不存在时返回nil
+func (g *DependencyGraph) Node(id string) *GraphNode {
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == id {
+			return &g.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// EdgesFrom 返回从id出发的所有边
+func (g *DependencyGraph) EdgesFrom(id string) []GraphEdge {
+	var edges []GraphEdge
+	for _, edge := range g.Edges {
+		if edge.From == id {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// dotEdgeStyles 各依赖类型的边在DOT中的样式
+var dotEdgeStyles = map[DependencyType]string{
+	Production:  "",
+	Development: "style=dashed, color=blue",
+	Optional:    "style=dotted",
+	Peer:        "style=dashed, color=gray",
+}
+
+// ExportDOT 以Graphviz的DOT格式输出依赖图，例如用dot -Tsvg渲染
+//
+// 开发依赖的边为蓝色虚线，可选依赖为点线，同级依赖为灰色虚线，工作区包显示为文件夹形状，缺失的包显示为红色。
+func (g *DependencyGraph) ExportDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph dependencies {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	for _, node := range g.Nodes {
+		var attrs []string
+		switch {
+		case node.Root:
+			attrs = append(attrs, "style=bold")
+		case node.Workspace:
+			attrs = append(attrs, "shape=folder")
+		case node.Missing:
+			attrs = append(attrs, "color=red", "fontcolor=red")
+		}
+		fmt.Fprintf(&sb, "  %s", dotQuote(node.ID))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&sb, " [%s]", strings.Join(attrs, ", "))
+		}
+		sb.WriteString(";\n")
+	}
+	for _, edge := range g.Edges {
+		var attrs []string
+		if style := dotEdgeStyles[edge.Type]; style != "" {
+			attrs = append(attrs, style)
+		}
+		if edge.Range != "" {
+			attrs = append(attrs, "label="+dotQuote(edge.Range))
+		}
+		fmt.Fprintf(&sb, "  %s -> %s", dotQuote(edge.From), dotQuote(edge.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&sb, " [%s]", strings.Join(attrs, ", "))
+		}
+		sb.WriteString(";\n")
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ExportJSON 以JSON格式输出依赖图
+func (g *DependencyGraph) ExportJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(g)
+}
+
+// dotQuote 把字符串转换为DOT的带引号ID
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
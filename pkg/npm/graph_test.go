@@ -0,0 +1,158 @@
+package npm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+const graphTestLockfile = `{
+  "name": "app",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0", "dependencies": {"mkdirp": "^0.5.0"}, "devDependencies": {"minimist": "^1.2.0"}},
+    "node_modules/mkdirp": {"version": "0.5.5", "dependencies": {"minimist": "^1.2.5", "left-pad": "^1.0.0"}},
+    "node_modules/minimist": {"version": "1.2.8"}
+  }
+}`
+
+func TestDependencyManagerGraphFromLockfile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), graphTestLockfile)
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{
+  "name": "app",
+  "version": "1.0.0",
+  "dependencies": {"mkdirp": "^0.5.0"},
+  "devDependencies": {"minimist": "^1.2.0"},
+  "peerDependencies": {"react": "^18.0.0"}
+}`)
+
+	dm, _ := NewDependencyManager(NewMockClient(), dir)
+	graph, err := dm.Graph(context.Background())
+	if err != nil {
+		t.Fatalf("Graph() failed: %v", err)
+	}
+
+	var ids []string
+	for _, node := range graph.Nodes {
+		ids = append(ids, node.ID)
+	}
+	if want := []string{"app@1.0.0", "left-pad@^1.0.0", "minimist@1.2.8", "mkdirp@0.5.5"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Expected nodes %v, got %v", want, ids)
+	}
+	if graph.Root != "app@1.0.0" || !graph.Nodes[0].Root || !graph.Node("left-pad@^1.0.0").Missing {
+		t.Errorf("Unexpected nodes: %+v", graph.Nodes)
+	}
+
+	// 未安装的同级依赖react不出现在图中
+	want := []GraphEdge{
+		{From: "app@1.0.0", To: "minimist@1.2.8", Type: Development, Range: "^1.2.0"},
+		{From: "app@1.0.0", To: "mkdirp@0.5.5", Type: Production, Range: "^0.5.0"},
+		{From: "mkdirp@0.5.5", To: "left-pad@^1.0.0", Type: Production, Range: "^1.0.0"},
+		{From: "mkdirp@0.5.5", To: "minimist@1.2.8", Type: Production, Range: "^1.2.5"},
+	}
+	if !reflect.DeepEqual(graph.Edges, want) {
+		t.Errorf("Expected edges %+v, got %+v", want, graph.Edges)
+	}
+	if edges := graph.EdgesFrom("mkdirp@0.5.5"); len(edges) != 2 {
+		t.Errorf("Expected 2 edges from mkdirp, got %+v", edges)
+	}
+}
+
+func TestDependencyManagerGraphFromTree(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name": "app", "dependencies": {"is-odd": "^3.0.0"}, "devDependencies": {"is-number": "^6.0.0"}}`)
+
+	executor := &listExecutor{
+		result: &utils.ExecuteResult{ExitCode: 1, Stdout: `{
+  "name": "app",
+  "problems": ["missing: left-pad@^1.0.0, required by is-odd@3.0.1"],
+  "dependencies": {
+    "is-number": {"version": "6.0.0"},
+    "is-odd": {
+      "version": "3.0.1",
+      "dependencies": {
+        "is-number": {"version": "6.0.0"},
+        "left-pad": {"required": "^1.0.0", "missing": true}
+      }
+    }
+  },
+  "error": {"code": "ELSPROBLEMS", "summary": "missing: left-pad@^1.0.0, required by is-odd@3.0.1"}
+}`},
+		err: errors.New("command failed with exit code 1"),
+	}
+	client, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	dm, _ := NewDependencyManager(client, dir)
+
+	graph, err := dm.Graph(context.Background())
+	if err != nil {
+		t.Fatalf("Graph() failed: %v", err)
+	}
+	want := []GraphEdge{
+		{From: "app", To: "is-number@6.0.0", Type: Development},
+		{From: "app", To: "is-odd@3.0.1", Type: Production},
+		{From: "is-odd@3.0.1", To: "is-number@6.0.0", Type: Production},
+		{From: "is-odd@3.0.1", To: "left-pad@^1.0.0", Type: Production, Range: "^1.0.0"},
+	}
+	if !reflect.DeepEqual(graph.Edges, want) {
+		t.Errorf("Expected edges %+v, got %+v", want, graph.Edges)
+	}
+	if node := graph.Node("left-pad@^1.0.0"); node == nil || !node.Missing {
+		t.Errorf("Expected missing left-pad node, got %+v", node)
+	}
+}
+
+func TestDependencyGraphExport(t *testing.T) {
+	graph := &DependencyGraph{
+		Root: "app@1.0.0",
+		Nodes: []GraphNode{
+			{ID: "app@1.0.0", Name: "app", Root: true},
+			{ID: "left-pad@^1.0.0", Name: "left-pad", Missing: true},
+			{ID: "mocha@10.0.0", Name: "mocha", Version: "10.0.0", Dev: true},
+		},
+		Edges: []GraphEdge{
+			{From: "app@1.0.0", To: "left-pad@^1.0.0", Type: Production, Range: "^1.0.0"},
+			{From: "app@1.0.0", To: "mocha@10.0.0", Type: Development, Range: `"10"`},
+		},
+	}
+
+	var dot bytes.Buffer
+	if err := graph.ExportDOT(&dot); err != nil {
+		t.Fatalf("ExportDOT() failed: %v", err)
+	}
+	for _, line := range []string{
+		`digraph dependencies {`,
+		`  "app@1.0.0" [style=bold];`,
+		`  "left-pad@^1.0.0" [color=red, fontcolor=red];`,
+		`  "mocha@10.0.0";`,
+		`  "app@1.0.0" -> "left-pad@^1.0.0" [label="^1.0.0"];`,
+		`  "app@1.0.0" -> "mocha@10.0.0" [style=dashed, color=blue, label="\"10\""];`,
+	} {
+		if !strings.Contains(dot.String(), line+"\n") {
+			t.Errorf("Expected DOT to contain %q, got:\n%s", line, dot.String())
+		}
+	}
+
+	var data bytes.Buffer
+	if err := graph.ExportJSON(&data); err != nil {
+		t.Fatalf("ExportJSON() failed: %v", err)
+	}
+	var decoded DependencyGraph
+	if err := json.Unmarshal(data.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, graph) {
+		t.Errorf("JSON round trip mismatch: %s", data.String())
+	}
+}
@@ -0,0 +1,521 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/lockfile"
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+)
+
+// HealthCategory 项目健康度的评估类别
+type HealthCategory string
+
+const (
+	HealthOutdated   HealthCategory = "outdated"   // 直接依赖落后于latest的程度
+	HealthSecurity   HealthCategory = "security"   // npm audit发现的漏洞
+	HealthDeprecated HealthCategory = "deprecated" // 使用中的已弃用版本
+	HealthLicense    HealthCategory = "license"    // 缺少许可证或使用被禁止的许可证的包
+	HealthNode       HealthCategory = "node"       // 项目使用的Node.js版本是否仍在维护
+)
+
+// healthCategories 报告中类别的顺序
+var healthCategories = []HealthCategory{HealthSecurity, HealthOutdated, HealthDeprecated, HealthLicense, HealthNode}
+
+// DefaultHealthWeights 返回各类别的默认权重
+func DefaultHealthWeights() map[HealthCategory]float64 {
+	return map[HealthCategory]float64{
+		HealthSecurity:   0.35,
+		HealthOutdated:   0.2,
+		HealthDeprecated: 0.15,
+		HealthLicense:    0.15,
+		HealthNode:       0.15,
+	}
+}
+
+// nodeEndOfLife 各Node.js主版本停止维护的日期
+var nodeEndOfLife = map[int]string{
+	8: "2019-12-31", 10: "2021-04-30", 12: "2022-04-30", 14: "2023-04-30",
+	16: "2023-09-11", 17: "2022-06-01", 18: "2025-04-30", 19: "2023-06-01",
+	20: "2026-04-30", 21: "2024-06-01", 22: "2027-04-30", 23: "2025-06-01",
+	24: "2028-04-30", 25: "2026-06-01",
+}
+
+// nodeEndOfLifeWarning 距离停止维护不足该时长时扣分
+const nodeEndOfLifeWarning = 180 * 24 * time.Hour
+
+// HealthOptions 健康度评估选项
+type HealthOptions struct {
+	// Weights 各类别的权重，为nil时使用DefaultHealthWeights，权重为0的类别不评估
+	Weights map[HealthCategory]float64
+
+	// DeniedLicenses 不允许使用的许可证，例如GPL-3.0、AGPL-3.0，不区分大小写
+	DeniedLicenses []string
+
+	// NodeVersion 项目使用的Node.js版本，为空时依次从.nvmrc、.node-version和engines.node中读取
+	NodeVersion string
+
+	// Registry 查询弃用信息的registry客户端，为nil时使用npm_config_registry或官方registry
+	Registry *registry.Client
+
+	// Now 判断Node.js版本是否停止维护的时间，为零时使用当前时间
+	Now time.Time
+}
+
+// DeprecatedDependency 锁文件中被弃用的直接依赖版本
+type DeprecatedDependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Message string `json:"message"`
+}
+
+// HealthInput 计算健康度使用的数据，已经取得审计结果等数据时可以直接传给ScoreHealth
+type HealthInput struct {
+	Dependencies int                      `json:"dependencies"`           // 直接依赖的数量，用于计算过期比例
+	Outdated     []OutdatedPackage        `json:"outdated,omitempty"`     // 过期的直接依赖
+	Audit        *AuditReport             `json:"audit,omitempty"`        // 审计结果，nil表示没有漏洞
+	Deprecated   []DeprecatedDependency   `json:"deprecated,omitempty"`   // 弃用的直接依赖
+	Licenses     map[string]string        `json:"licenses,omitempty"`     // name@version -> 许可证
+	NodeVersion  string                   `json:"node_version,omitempty"` // 为空时不评估Node.js版本
+	Errors       map[HealthCategory]error `json:"-"`                      // 数据获取失败的类别，不计入总分
+}
+
+// CategoryHealth 单个类别的评估结果
+type CategoryHealth struct {
+	Category HealthCategory `json:"category"`
+	Score    float64        `json:"score"`  // 0-100
+	Weight   float64        `json:"weight"` // 归一化后在总分中的权重，类别不可用时为0
+	Issues   []string       `json:"issues,omitempty"`
+	Error    string         `json:"error,omitempty"` // 数据获取失败的原因
+}
+
+// ProjectHealth 项目健康度
+type ProjectHealth struct {
+	Score      float64          `json:"score"` // 可用类别的加权平均分，0-100
+	Grade      string           `json:"grade"` // A-F
+	Categories []CategoryHealth `json:"categories"`
+}
+
+// Category 按类别查找评估结果
+func (h *ProjectHealth) Category(category HealthCategory) *CategoryHealth {
+	for i := range h.Categories {
+		if h.Categories[i].Category == category {
+			return &h.Categories[i]
+		}
+	}
+	return nil
+}
+
+// Health 收集项目的过期依赖、审计结果、弃用版本、许可证和Node.js版本，计算健康度
+//
+// 弃用和许可证信息来自锁文件，没有锁文件时这两个类别不可用。
+// 某个类别的数据获取失败时记录在该类别的Error中，不影响其他类别，只有ctx取消时返回错误。
+func (dm *DependencyManager) Health(ctx context.Context, options HealthOptions) (*ProjectHealth, error) {
+	input, err := dm.collectHealth(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return ScoreHealth(input, options), nil
+}
+
+// collectHealth 收集权重不为0的类别的数据
+func (dm *DependencyManager) collectHealth(ctx context.Context, options HealthOptions) (HealthInput, error) {
+	weights := options.Weights
+	if weights == nil {
+		weights = DefaultHealthWeights()
+	}
+	enabled := func(category HealthCategory) bool {
+		return weights[category] > 0
+	}
+
+	input := HealthInput{Errors: make(map[HealthCategory]error)}
+	lock := loadProjectLockfile(dm.workingDir)
+	input.Dependencies = dm.directDependencyCount(lock)
+
+	if enabled(HealthOutdated) {
+		outdated, err := dm.client.Outdated(ctx, OutdatedOptions{WorkingDir: dm.workingDir})
+		if err != nil {
+			input.Errors[HealthOutdated] = err
+		}
+		input.Outdated = outdated
+	}
+
+	if enabled(HealthSecurity) {
+		report, err := dm.AuditReport(ctx)
+		if err != nil {
+			input.Errors[HealthSecurity] = err
+		}
+		input.Audit = report
+	}
+
+	if enabled(HealthDeprecated) {
+		if lock == nil {
+			input.Errors[HealthDeprecated] = fmt.Errorf("no lockfile found")
+		} else {
+			registryClient := options.Registry
+			if registryClient == nil {
+				registryClient = defaultFallback()
+			}
+			deprecated, err := findDeprecated(ctx, registryClient, lock)
+			if err != nil {
+				input.Errors[HealthDeprecated] = err
+			}
+			input.Deprecated = deprecated
+		}
+	}
+
+	if enabled(HealthLicense) {
+		if lock == nil || (lock.Format != lockfile.FormatNpm && lock.Format != lockfile.FormatShrinkwrap) {
+			input.Errors[HealthLicense] = fmt.Errorf("licenses are only recorded in package-lock.json")
+		} else {
+			input.Licenses = make(map[string]string, len(lock.Packages))
+			for _, pkg := range lock.Packages {
+				input.Licenses[pkg.Key()] = pkg.License
+			}
+		}
+	}
+
+	if enabled(HealthNode) {
+		input.NodeVersion = options.NodeVersion
+		if input.NodeVersion == "" {
+			input.NodeVersion = detectProjectNodeVersion(dm.workingDir)
+		}
+	}
+
+	return input, ctx.Err()
+}
+
+// directDependencyCount 直接依赖的数量，优先使用锁文件记录的根依赖
+func (dm *DependencyManager) directDependencyCount(lock *lockfile.Lockfile) int {
+	if lock != nil && lock.Root != nil {
+		return len(lock.Root.Dependencies) + len(lock.Root.DevDependencies) + len(lock.Root.OptionalDependencies)
+	}
+	if err := dm.packageJSON.Load(); err != nil {
+		return 0
+	}
+	return len(dm.packageJSON.GetDependencies()) + len(dm.packageJSON.GetDevDependencies()) +
+		len(dm.packageJSON.GetOptionalDependencies())
+}
+
+// findDeprecated 查询锁文件中直接依赖的已解析版本是否被弃用
+func findDeprecated(ctx context.Context, registryClient *registry.Client, lock *lockfile.Lockfile) ([]DeprecatedDependency, error) {
+	var names []string
+	if lock.Root != nil {
+		for _, deps := range []map[string]string{lock.Root.Dependencies, lock.Root.DevDependencies, lock.Root.OptionalDependencies} {
+			for name := range deps {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	resolved := lock.ResolvedSet()
+	var deprecated []DeprecatedDependency
+	for _, name := range names {
+		versions := resolved[name]
+		if len(versions) == 0 {
+			continue
+		}
+		packument, err := registryClient.GetPackumentWithOptions(ctx, name, registry.PackumentOptions{
+			Versions: registry.VersionsIn(versions...),
+		})
+		if err != nil {
+			return deprecated, fmt.Errorf("failed to get %s: %w", name, err)
+		}
+		for _, version := range versions {
+			if manifest, ok := packument.Versions[version]; ok && manifest.Deprecated != "" {
+				deprecated = append(deprecated, DeprecatedDependency{Name: name, Version: version, Message: string(manifest.Deprecated)})
+			}
+		}
+	}
+	return deprecated, nil
+}
+
+// detectProjectNodeVersion 从.nvmrc、.node-version或engines.node读取项目使用的Node.js版本
+//
+// engines.node是范围时返回满足范围的最低主版本，即项目声明仍然支持的最旧版本。
+// lts/*等别名无法确定版本，返回空字符串。
+func detectProjectNodeVersion(dir string) string {
+	for _, name := range []string{".nvmrc", ".node-version"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if major := nodeMajor(strings.TrimSpace(string(data))); major > 0 {
+			return strconv.Itoa(major)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Engines map[string]string `json:"engines"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || pkg.Engines["node"] == "" {
+		return ""
+	}
+	for _, major := range slices.Sorted(maps.Keys(nodeEndOfLife)) {
+		if semver.Satisfies(fmt.Sprintf("%d.999.999", major), pkg.Engines["node"]) {
+			return strconv.Itoa(major)
+		}
+	}
+	return ""
+}
+
+// nodeMajor 解析v20.11.0、20.11、20等形式的主版本号，无法解析时返回0
+func nodeMajor(version string) int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// ScoreHealth 根据已收集的数据计算健康度
+//
+// 各类别的得分为0-100：
+//   - security：每个受影响的包按严重程度扣分，critical 40、high 20、moderate 8、low 2
+//   - outdated：落后一个主版本计1、次版本计0.5、补丁版本计0.25，按直接依赖数量折算
+//   - deprecated：每个弃用的直接依赖扣20
+//   - license：每个使用被禁止许可证的包扣25，缺少许可证或为UNLICENSED的包扣5
+//   - node：已停止维护为0，180天内停止维护为50
+//
+// 总分是可用类别按权重的加权平均，input.Errors中的类别和权重为0的类别不计入。
+func ScoreHealth(input HealthInput, options HealthOptions) *ProjectHealth {
+	weights := options.Weights
+	if weights == nil {
+		weights = DefaultHealthWeights()
+	}
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	health := &ProjectHealth{}
+	var total float64
+	for _, category := range healthCategories {
+		if weights[category] <= 0 {
+			continue
+		}
+		result := CategoryHealth{Category: category, Weight: weights[category]}
+		var err error
+		switch category {
+		case HealthSecurity:
+			result.Score, result.Issues = scoreSecurity(input.Audit)
+		case HealthOutdated:
+			result.Score, result.Issues = scoreOutdated(input.Outdated, input.Dependencies)
+		case HealthDeprecated:
+			result.Score, result.Issues = scoreDeprecated(input.Deprecated)
+		case HealthLicense:
+			result.Score, result.Issues = scoreLicenses(input.Licenses, options.DeniedLicenses)
+		case HealthNode:
+			result.Score, result.Issues, err = scoreNode(input.NodeVersion, now)
+		}
+		if inputErr := input.Errors[category]; inputErr != nil {
+			err = inputErr
+		}
+		if err != nil {
+			result = CategoryHealth{Category: category, Error: err.Error()}
+		} else {
+			total += result.Weight
+		}
+		health.Categories = append(health.Categories, result)
+	}
+
+	for i := range health.Categories {
+		category := &health.Categories[i]
+		if category.Error != "" || total == 0 {
+			continue
+		}
+		category.Weight /= total
+		health.Score += category.Score * category.Weight
+	}
+	if total == 0 {
+		health.Score = 0
+	}
+	health.Score = math.Round(health.Score*10) / 10
+	health.Grade = healthGrade(health.Score)
+	return health
+}
+
+// scoreSecurity 按受影响包的严重程度扣分
+func scoreSecurity(report *AuditReport) (float64, []string) {
+	if report == nil {
+		return 100, nil
+	}
+	penalties := map[Severity]float64{
+		SeverityCritical: 40,
+		SeverityHigh:     20,
+		SeverityModerate: 8,
+		SeverityLow:      2,
+	}
+	findings := slices.Clone(report.Findings)
+	slices.SortStableFunc(findings, func(a, b AuditFinding) int {
+		return b.Severity.Rank() - a.Severity.Rank()
+	})
+
+	score := 100.0
+	var issues []string
+	for _, finding := range findings {
+		score -= penalties[finding.Severity]
+		issues = append(issues, fmt.Sprintf("%s: %s vulnerability", finding.Name, finding.Severity))
+	}
+	return math.Max(score, 0), issues
+}
+
+// scoreOutdated 按落后的版本级别计算过期比例
+func scoreOutdated(outdated []OutdatedPackage, dependencies int) (float64, []string) {
+	if len(outdated) == 0 {
+		return 100, nil
+	}
+	var penalty float64
+	var issues []string
+	for _, pkg := range outdated {
+		level, weight := outdatedLevel(pkg.Current, pkg.Latest)
+		penalty += weight
+		current := pkg.Current
+		if current == "" {
+			current = "missing"
+		}
+		issues = append(issues, fmt.Sprintf("%s: %s -> %s (%s)", pkg.Name, current, pkg.Latest, level))
+	}
+	dependencies = max(dependencies, len(outdated))
+	return math.Max(100*(1-penalty/float64(dependencies)), 0), issues
+}
+
+// outdatedLevel 当前版本落后于latest的级别及其权重
+func outdatedLevel(current, latest string) (string, float64) {
+	from, err := semver.Parse(current)
+	if err != nil {
+		return "unknown", 0.5
+	}
+	to, err := semver.Parse(latest)
+	if err != nil {
+		return "unknown", 0.5
+	}
+	switch {
+	case to.Major != from.Major:
+		return "major", 1
+	case to.Minor != from.Minor:
+		return "minor", 0.5
+	default:
+		return "patch", 0.25
+	}
+}
+
+// scoreDeprecated 每个弃用的直接依赖扣20
+func scoreDeprecated(deprecated []DeprecatedDependency) (float64, []string) {
+	var issues []string
+	for _, dep := range deprecated {
+		issues = append(issues, fmt.Sprintf("%s@%s: %s", dep.Name, dep.Version, dep.Message))
+	}
+	return math.Max(100-20*float64(len(deprecated)), 0), issues
+}
+
+// scoreLicenses 检查被禁止的许可证和缺少许可证的包
+func scoreLicenses(licenses map[string]string, denied []string) (float64, []string) {
+	deniedSet := make(map[string]bool, len(denied))
+	for _, license := range denied {
+		deniedSet[strings.ToLower(license)] = true
+	}
+
+	score := 100.0
+	var issues []string
+	for _, key := range slices.Sorted(maps.Keys(licenses)) {
+		license := strings.TrimSpace(licenses[key])
+		switch {
+		case license == "" || strings.EqualFold(license, "UNLICENSED"):
+			score -= 5
+			issues = append(issues, fmt.Sprintf("%s: no license", key))
+		case licenseDenied(license, deniedSet):
+			score -= 25
+			issues = append(issues, fmt.Sprintf("%s: denied license %s", key, license))
+		}
+	}
+	return math.Max(score, 0), issues
+}
+
+// licenseDenied 判断许可证表达式是否使用了被禁止的许可证
+//
+// OR表达式中有一个允许的许可证即可使用，AND表达式中任意一个被禁止即不可使用。
+func licenseDenied(expression string, denied map[string]bool) bool {
+	if len(denied) == 0 {
+		return false
+	}
+	expression = strings.Trim(expression, "() ")
+	for _, alternative := range strings.Split(expression, " OR ") {
+		allowed := true
+		for _, license := range strings.Split(alternative, " AND ") {
+			if denied[strings.ToLower(strings.Trim(license, "() "))] {
+				allowed = false
+				break
+			}
+		}
+		if allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// scoreNode 按Node.js主版本的维护状态计分
+func scoreNode(version string, now time.Time) (float64, []string, error) {
+	if version == "" {
+		return 0, nil, fmt.Errorf("node version not found in .nvmrc, .node-version or engines.node")
+	}
+	major := nodeMajor(version)
+	if major == 0 {
+		return 0, nil, fmt.Errorf("invalid node version %q", version)
+	}
+
+	eol, known := nodeEndOfLife[major]
+	if !known {
+		oldest := slices.Min(slices.Collect(maps.Keys(nodeEndOfLife)))
+		if major < oldest {
+			return 0, []string{fmt.Sprintf("node %d is end-of-life", major)}, nil
+		}
+		// 比已知版本更新
+		return 100, nil, nil
+	}
+
+	date, _ := time.Parse(time.DateOnly, eol)
+	switch {
+	case !now.Before(date):
+		return 0, []string{fmt.Sprintf("node %d reached end-of-life on %s", major, eol)}, nil
+	case date.Sub(now) < nodeEndOfLifeWarning:
+		return 50, []string{fmt.Sprintf("node %d reaches end-of-life on %s", major, eol)}, nil
+	default:
+		return 100, nil, nil
+	}
+}
+
+// healthGrade 分数对应的等级
+func healthGrade(score float64) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
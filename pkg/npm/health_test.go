@@ -0,0 +1,224 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+func TestScoreHealth(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := HealthInput{
+		Dependencies: 4,
+		Outdated: []OutdatedPackage{
+			{Name: "react", Current: "17.0.2", Latest: "18.2.0"},
+			{Name: "lodash", Current: "4.17.20", Latest: "4.17.21"},
+		},
+		Audit: &AuditReport{Findings: []AuditFinding{
+			{Name: "minimist", Severity: SeverityLow},
+			{Name: "semver", Severity: SeverityHigh},
+		}},
+		Deprecated: []DeprecatedDependency{{Name: "request", Version: "2.88.2", Message: "request has been deprecated"}},
+		Licenses: map[string]string{
+			"a@1.0.0": "MIT",
+			"b@1.0.0": "GPL-3.0",
+			"c@1.0.0": "",
+			"d@1.0.0": "(MIT OR GPL-3.0)",
+		},
+		NodeVersion: "20.11.0",
+	}
+
+	health := ScoreHealth(input, HealthOptions{DeniedLicenses: []string{"gpl-3.0"}, Now: now})
+
+	expected := map[HealthCategory]float64{
+		HealthSecurity:   78,
+		HealthOutdated:   68.75,
+		HealthDeprecated: 80,
+		HealthLicense:    70,
+		HealthNode:       50,
+	}
+	if len(health.Categories) != len(expected) {
+		t.Fatalf("Expected %d categories, got %+v", len(expected), health.Categories)
+	}
+	for category, score := range expected {
+		if got := health.Category(category); got == nil || got.Score != score {
+			t.Errorf("Expected %s score %v, got %+v", category, score, got)
+		}
+	}
+	// 0.35*78 + 0.2*68.75 + 0.15*80 + 0.15*70 + 0.15*50 = 71.05
+	if health.Score != 71.1 || health.Grade != "C" {
+		t.Errorf("Expected score 71.1 (C), got %v (%s)", health.Score, health.Grade)
+	}
+
+	security := health.Category(HealthSecurity)
+	if !reflect.DeepEqual(security.Issues, []string{"semver: high vulnerability", "minimist: low vulnerability"}) {
+		t.Errorf("Unexpected security issues: %v", security.Issues)
+	}
+	license := health.Category(HealthLicense)
+	if !reflect.DeepEqual(license.Issues, []string{"b@1.0.0: denied license GPL-3.0", "c@1.0.0: no license"}) {
+		t.Errorf("Unexpected license issues: %v", license.Issues)
+	}
+}
+
+func TestScoreHealthExcludesUnavailableCategories(t *testing.T) {
+	health := ScoreHealth(HealthInput{
+		Audit:  &AuditReport{Findings: []AuditFinding{{Name: "x", Severity: SeverityCritical}}},
+		Errors: map[HealthCategory]error{HealthSecurity: errors.New("audit failed")},
+	}, HealthOptions{
+		Weights: map[HealthCategory]float64{HealthSecurity: 1, HealthOutdated: 1, HealthNode: 1},
+	})
+
+	if len(health.Categories) != 3 {
+		t.Fatalf("Expected 3 categories, got %+v", health.Categories)
+	}
+	if security := health.Category(HealthSecurity); security.Error != "audit failed" || security.Weight != 0 {
+		t.Errorf("Expected security to be unavailable, got %+v", security)
+	}
+	if node := health.Category(HealthNode); node.Error == "" {
+		t.Errorf("Expected node to be unavailable without a version, got %+v", node)
+	}
+	if outdated := health.Category(HealthOutdated); outdated.Weight != 1 || outdated.Score != 100 {
+		t.Errorf("Expected outdated to carry the whole weight, got %+v", outdated)
+	}
+	if health.Score != 100 || health.Grade != "A" {
+		t.Errorf("Expected score 100 (A), got %v (%s)", health.Score, health.Grade)
+	}
+	if health.Category(HealthLicense) != nil {
+		t.Error("Expected categories without weight to be skipped")
+	}
+}
+
+func TestScoreNode(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		version string
+		score   float64
+	}{
+		{"v16.20.2", 0},
+		{"20", 50},
+		{"22.1", 100},
+		{"6", 0},
+		{"30", 100},
+	}
+	for _, tt := range tests {
+		score, _, err := scoreNode(tt.version, now)
+		if err != nil || score != tt.score {
+			t.Errorf("scoreNode(%q) = %v, %v, expected %v", tt.version, score, err, tt.score)
+		}
+	}
+	if _, _, err := scoreNode("lts/*", now); err == nil {
+		t.Error("Expected error for alias")
+	}
+}
+
+func TestDetectProjectNodeVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"engines":{"node":">=18.17 <23"}}`)
+	if version := detectProjectNodeVersion(dir); version != "18" {
+		t.Errorf("Expected 18 from engines, got %q", version)
+	}
+
+	writeTestFile(t, filepath.Join(dir, ".nvmrc"), "lts/*\n")
+	writeTestFile(t, filepath.Join(dir, ".node-version"), "v22.3.0\n")
+	if version := detectProjectNodeVersion(dir); version != "22" {
+		t.Errorf("Expected 22 from .node-version, got %q", version)
+	}
+
+	if version := detectProjectNodeVersion(t.TempDir()); version != "" {
+		t.Errorf("Expected no version, got %q", version)
+	}
+}
+
+func TestDependencyManagerHealth(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name":"app","version":"1.0.0"}`)
+	writeTestFile(t, filepath.Join(dir, ".nvmrc"), "24\n")
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), `{
+  "name": "app",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0", "dependencies": {"left-pad": "^1.3.0", "lodash": "^4.17.21"}},
+    "node_modules/left-pad": {"version": "1.3.0", "license": "WTFPL"},
+    "node_modules/lodash": {"version": "4.17.21", "license": "MIT"}
+  }
+}`)
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch strings.TrimPrefix(r.URL.Path, "/") {
+		case "left-pad":
+			w.Write([]byte(`{"name":"left-pad","versions":{"1.3.0":{"version":"1.3.0","deprecated":"use String.prototype.padStart()"}}}`))
+		default:
+			w.Write([]byte(`{"name":"lodash","versions":{"4.17.21":{"version":"4.17.21"}}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewMockClient()
+	client.audits = []*AuditReport{{Findings: []AuditFinding{{Name: "lodash", Severity: SeverityModerate}}}}
+	client.outdated = []OutdatedPackage{{Name: "lodash", Current: "4.17.21", Wanted: "4.17.21", Latest: "5.0.0"}}
+	dm, _ := NewDependencyManager(client, dir)
+
+	health, err := dm.Health(context.Background(), HealthOptions{
+		Registry:       registry.NewClient(server.URL),
+		DeniedLicenses: []string{"WTFPL"},
+		Now:            time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Health() failed: %v", err)
+	}
+
+	for _, category := range health.Categories {
+		if category.Error != "" {
+			t.Errorf("Unexpected error in %s: %s", category.Category, category.Error)
+		}
+	}
+	if deprecated := health.Category(HealthDeprecated); deprecated.Score != 80 ||
+		!reflect.DeepEqual(deprecated.Issues, []string{"left-pad@1.3.0: use String.prototype.padStart()"}) {
+		t.Errorf("Unexpected deprecated category: %+v", deprecated)
+	}
+	if outdated := health.Category(HealthOutdated); outdated.Score != 50 {
+		t.Errorf("Expected one of two dependencies a major behind, got %+v", outdated)
+	}
+	if security := health.Category(HealthSecurity); security.Score != 92 {
+		t.Errorf("Expected moderate finding penalty, got %+v", security)
+	}
+	if license := health.Category(HealthLicense); license.Score != 75 {
+		t.Errorf("Expected denied license penalty, got %+v", license)
+	}
+	if node := health.Category(HealthNode); node.Score != 100 {
+		t.Errorf("Expected node 24 to be supported, got %+v", node)
+	}
+	if len(requested) != 2 {
+		t.Errorf("Expected one registry request per direct dependency, got %v", requested)
+	}
+}
+
+func TestDependencyManagerHealthWithoutLockfile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name":"app","version":"1.0.0","dependencies":{"a":"1.0.0"}}`)
+	dm, _ := NewDependencyManager(NewMockClient(), dir)
+
+	health, err := dm.Health(context.Background(), HealthOptions{NodeVersion: "22"})
+	if err != nil {
+		t.Fatalf("Health() failed: %v", err)
+	}
+	for _, category := range []HealthCategory{HealthDeprecated, HealthLicense} {
+		if health.Category(category).Error == "" {
+			t.Errorf("Expected %s to be unavailable without a lockfile", category)
+		}
+	}
+	if health.Score != 100 {
+		t.Errorf("Expected remaining categories to score 100, got %v", health.Score)
+	}
+}
@@ -0,0 +1,141 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// Operation 客户端执行的一条npm命令，传给BeforeOperation和AfterOperation钩子
+type Operation struct {
+	Name       string            `json:"name"` // npm子命令，例如install、publish；npm --version为version
+	Args       []string          `json:"args"` // 完整参数，BeforeOperation钩子可以修改
+	WorkingDir string            `json:"working_dir"`
+	Env        map[string]string `json:"env"` // 额外的环境变量，BeforeOperation钩子可以修改，不为nil
+	Start      time.Time         `json:"start"`
+}
+
+// OperationResult 命令的执行结果
+type OperationResult struct {
+	Result   *utils.ExecuteResult `json:"result"` // 命令被拒绝时为nil
+	Err      error                `json:"-"`
+	Duration time.Duration        `json:"duration"`
+}
+
+// BeforeOperationHook 命令执行前调用，返回错误时拒绝执行，客户端方法返回*OperationVetoedError
+type BeforeOperationHook func(ctx context.Context, op *Operation) error
+
+// AfterOperationHook 命令结束后调用，被拒绝的命令也会调用
+type AfterOperationHook func(ctx context.Context, op *Operation, result *OperationResult)
+
+// OperationVetoedError BeforeOperation钩子拒绝执行命令
+type OperationVetoedError struct {
+	Operation string
+	Err       error
+}
+
+// Error 实现error接口
+func (e *OperationVetoedError) Error() string {
+	return fmt.Sprintf("npm %s vetoed: %v", e.Operation, e.Err)
+}
+
+// Unwrap 返回钩子返回的错误
+func (e *OperationVetoedError) Unwrap() error {
+	return e.Err
+}
+
+// IsOperationVetoed 检查是否为钩子拒绝执行的错误
+func IsOperationVetoed(err error) bool {
+	var vetoedErr *OperationVetoedError
+	return errors.As(err, &vetoedErr)
+}
+
+// WithBeforeOperation 在客户端执行每条npm命令之前调用hook，可以多次设置，按设置顺序调用
+//
+// 钩子可以修改参数和环境变量，例如为特定的包设置镜像，或按策略拒绝命令。
+// 与包装CommandExecutor相比不需要处理执行细节，只作用于客户端自身执行的命令。
+// 无论选项的顺序如何，钩子收到的Env都包含WithMirror的环境变量，拒绝的命令不计入WithBudget的预算。
+func WithBeforeOperation(hook BeforeOperationHook) ClientOption {
+	return func(c *client) {
+		c.beforeHooks = append(c.beforeHooks, hook)
+	}
+}
+
+// WithAfterOperation 在客户端执行的每条npm命令结束后调用hook，可以多次设置，按设置顺序调用
+//
+// 适合记录耗时和结果，钩子收到的Operation是实际执行的命令。
+func WithAfterOperation(hook AfterOperationHook) ClientOption {
+	return func(c *client) {
+		c.afterHooks = append(c.afterHooks, hook)
+	}
+}
+
+// hookExecutor 在命令前后调用钩子的执行器
+type hookExecutor struct {
+	executor utils.CommandExecutor
+	before   []BeforeOperationHook
+	after    []AfterOperationHook
+}
+
+// Execute 调用BeforeOperation钩子，执行命令，再调用AfterOperation钩子
+func (e *hookExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	op := &Operation{
+		Name:       operationName(options.Args),
+		Args:       slices.Clone(options.Args),
+		WorkingDir: options.WorkingDir,
+		Env:        maps.Clone(options.Env),
+		Start:      time.Now(),
+	}
+	if op.Env == nil {
+		op.Env = make(map[string]string)
+	}
+
+	for _, hook := range e.before {
+		if err := hook(ctx, op); err != nil {
+			err = &OperationVetoedError{Operation: op.Name, Err: err}
+			e.runAfter(ctx, op, &OperationResult{Err: err, Duration: time.Since(op.Start)})
+			return &utils.ExecuteResult{ExitCode: -1, Error: err}, err
+		}
+	}
+
+	options.Args = op.Args
+	options.WorkingDir = op.WorkingDir
+	options.Env = op.Env
+	result, err := e.executor.Execute(ctx, options)
+	e.runAfter(ctx, op, &OperationResult{Result: result, Err: err, Duration: time.Since(op.Start)})
+	return result, err
+}
+
+// runAfter 调用AfterOperation钩子
+func (e *hookExecutor) runAfter(ctx context.Context, op *Operation, result *OperationResult) {
+	for _, hook := range e.after {
+		hook(ctx, op, result)
+	}
+}
+
+// SetLogger 设置被包装执行器的日志记录器
+func (e *hookExecutor) SetLogger(logger *slog.Logger) {
+	if executor, ok := e.executor.(interface{ SetLogger(*slog.Logger) }); ok {
+		executor.SetLogger(logger)
+	}
+}
+
+// operationName 参数中的npm子命令，只有选项时取第一个选项名，例如--version为version
+func operationName(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	if len(args) > 0 {
+		return strings.TrimLeft(args[0], "-")
+	}
+	return ""
+}
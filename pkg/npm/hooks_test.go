@@ -0,0 +1,138 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOperationHooks(t *testing.T) {
+	executor := &recordingExecutor{}
+	var before, after []string
+	var last *OperationResult
+	policy := errors.New("installing from git is not allowed")
+	client, err := NewClientWithExecutor("npm", executor,
+		WithBeforeOperation(func(ctx context.Context, op *Operation) error {
+			before = append(before, "first:"+op.Name)
+			for _, arg := range op.Args {
+				if arg == "left-pad" {
+					op.Env["npm_config_registry"] = "https://mirror.example.com/"
+				}
+				if arg == "github:user/repo" {
+					return policy
+				}
+			}
+			return nil
+		}),
+		WithBeforeOperation(func(ctx context.Context, op *Operation) error {
+			before = append(before, "second:"+op.Name)
+			return nil
+		}),
+		WithAfterOperation(func(ctx context.Context, op *Operation, result *OperationResult) {
+			after = append(after, op.Name)
+			last = result
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	env := map[string]string{"CI": "true"}
+	if err := client.InstallPackage(ctx, "left-pad", InstallOptions{Env: env}); err != nil {
+		t.Fatalf("InstallPackage() failed: %v", err)
+	}
+	if executor.options.Env["npm_config_registry"] != "https://mirror.example.com/" || executor.options.Env["CI"] != "true" {
+		t.Errorf("Expected hook to set env, got %v", executor.options.Env)
+	}
+	if len(env) != 1 {
+		t.Errorf("Hook modified the caller's env: %v", env)
+	}
+	if len(before) != 2 || before[0] != "first:install" || before[1] != "second:install" {
+		t.Errorf("Unexpected before hooks: %v", before)
+	}
+	if len(after) != 1 || last.Result == nil || !last.Result.Success || last.Err != nil {
+		t.Errorf("Unexpected after hooks: %v %+v", after, last)
+	}
+
+	if _, err := client.Version(ctx); err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	if after[len(after)-1] != "version" {
+		t.Errorf("Expected version operation, got %v", after)
+	}
+
+	// 钩子拒绝时命令不执行，错误可以用errors.Is匹配钩子返回的错误
+	executor.options.Args = nil
+	before = nil
+	err = client.InstallPackage(ctx, "github:user/repo", InstallOptions{})
+	if !IsOperationVetoed(err) || !errors.Is(err, policy) {
+		t.Fatalf("Expected vetoed error, got %v", err)
+	}
+	if executor.options.Args != nil {
+		t.Errorf("Vetoed command was executed: %v", executor.options.Args)
+	}
+	if len(before) != 1 {
+		t.Errorf("Expected later hooks to be skipped, got %v", before)
+	}
+	if last.Result != nil || !IsOperationVetoed(last.Err) {
+		t.Errorf("Expected after hook to see the veto, got %+v", last)
+	}
+}
+
+func TestOperationHooksOptionOrder(t *testing.T) {
+	mirror := Mirror{Registry: "https://mirror.example.com/"}
+	for name, hooksFirst := range map[string]bool{"hooks first": true, "hooks last": false} {
+		t.Run(name, func(t *testing.T) {
+			budget := NewBudget(BudgetLimits{CacheDir: t.TempDir()})
+			var registries []string
+			hook := WithBeforeOperation(func(ctx context.Context, op *Operation) error {
+				registries = append(registries, op.Env["npm_config_registry"])
+				if op.Name == "install" {
+					return errors.New("installing is not allowed")
+				}
+				return nil
+			})
+			opts := []ClientOption{WithMirror(mirror), WithBudget(budget)}
+			if hooksFirst {
+				opts = append([]ClientOption{hook}, opts...)
+			} else {
+				opts = append(opts, hook)
+			}
+			client, err := NewClientWithExecutor("npm", &recordingExecutor{}, opts...)
+			if err != nil {
+				t.Fatalf("NewClientWithExecutor() failed: %v", err)
+			}
+
+			ctx := context.Background()
+			if _, err := client.Version(ctx); err != nil {
+				t.Fatalf("Version() failed: %v", err)
+			}
+			if err := client.InstallPackage(ctx, "lodash", InstallOptions{}); !IsOperationVetoed(err) {
+				t.Fatalf("Expected vetoed error, got %v", err)
+			}
+
+			// 钩子总能看到镜像的环境变量，被拒绝的命令不计入预算
+			if len(registries) != 2 || registries[0] != mirror.Registry || registries[1] != mirror.Registry {
+				t.Errorf("Expected hooks to see the mirror registry, got %v", registries)
+			}
+			if usage := budget.Usage(); usage.Processes != 1 {
+				t.Errorf("Expected 1 process in the budget, got %d", usage.Processes)
+			}
+		})
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	tests := map[string][]string{
+		"install": {"install", "lodash"},
+		"version": {"--version"},
+		"run":     {"run", "build"},
+		"":        nil,
+	}
+	for expected, args := range tests {
+		if got := operationName(args); got != expected {
+			t.Errorf("operationName(%v) = %q, expected %q", args, got, expected)
+		}
+	}
+}
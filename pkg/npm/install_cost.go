@@ -0,0 +1,242 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// InstallCostEntry 依赖树中的一个包版本
+type InstallCostEntry struct {
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	Size         int64  `json:"size"`          // tarball的字节数，registry没有返回Content-Length时为-1
+	UnpackedSize int64  `json:"unpacked_size"` // registry记录的解压后大小，旧版本可能为0
+	Files        int    `json:"files"`
+	Optional     bool   `json:"optional,omitempty"` // 只通过optionalDependencies引入
+}
+
+// InstallCost 安装一个包的估算成本
+type InstallCost struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Packages int    `json:"packages"` // 包括包本身在内的包版本数
+
+	// Size 所有tarball的下载大小，不包括UnknownSize个大小未知的包
+	Size         int64 `json:"size"`
+	UnpackedSize int64 `json:"unpacked_size"`
+	Files        int   `json:"files"`
+	UnknownSize  int   `json:"unknown_size,omitempty"`
+
+	Entries    []InstallCostEntry `json:"entries"`              // 按UnpackedSize从大到小排序
+	Unresolved []string           `json:"unresolved,omitempty"` // 无法从registry解析的依赖name@spec，例如git依赖
+}
+
+// InstallCostEstimator 根据registry元数据估算安装成本，不下载也不安装任何包
+type InstallCostEstimator struct {
+	registry *registry.Client
+}
+
+// NewInstallCostEstimator 创建安装成本估算器
+func NewInstallCostEstimator(registryClient *registry.Client) *InstallCostEstimator {
+	return &InstallCostEstimator{registry: registryClient}
+}
+
+// costRequest 依赖树中对某个包的一次请求
+type costRequest struct {
+	name     string
+	spec     string
+	optional bool
+}
+
+// EstimateInstallCost 解析pkg（name或name@spec）的完整传递依赖，统计包数、下载大小和解压后大小
+//
+// 按npm的规则跟随dependencies、optionalDependencies和非可选的peerDependencies，
+// 同一个包版本只计算一次。与npm的实际安装相比不考虑已安装的包、平台限制和锁文件。
+func (e *InstallCostEstimator) EstimateInstallCost(ctx context.Context, pkg string) (*InstallCost, error) {
+	name, spec := splitPackageSpec(pkg)
+	if spec == "" {
+		spec = "latest"
+	}
+	if name == "" {
+		return nil, NewValidationError("package", pkg, "package name cannot be empty")
+	}
+	if !isRegistrySpec(spec) && !strings.HasPrefix(spec, "npm:") {
+		return nil, NewValidationError("package", pkg, "only registry packages can be estimated")
+	}
+
+	packuments := make(map[string]*registry.Packument)
+	entries := make(map[string]*InstallCostEntry)
+	manifests := make(map[string]*registry.Manifest)
+	unresolved := make(map[string]bool)
+	var root *InstallCostEntry
+
+	level := []costRequest{{name: name, spec: spec}}
+	for len(level) > 0 {
+		// 先获取本层新出现的包的版本列表
+		var names []string
+		for i, req := range level {
+			if target, ok := strings.CutPrefix(req.spec, "npm:"); ok {
+				level[i].name, level[i].spec = splitPackageSpec(target)
+			}
+			if level[i].spec == "" {
+				level[i].spec = "*"
+			}
+			if _, ok := packuments[level[i].name]; !ok && isRegistrySpec(level[i].spec) {
+				packuments[level[i].name] = nil
+				names = append(names, level[i].name)
+			}
+		}
+		fetched := make([]*registry.Packument, len(names))
+		tasks := make([]utils.Task, len(names))
+		for i, pkgName := range names {
+			tasks[i] = utils.Task{
+				Name: pkgName,
+				Run: func(ctx context.Context) error {
+					packument, err := e.registry.GetPackumentWithOptions(ctx, pkgName, registry.PackumentOptions{})
+					if registry.IsNotFound(err) && root != nil {
+						return nil
+					}
+					fetched[i] = packument
+					return err
+				},
+			}
+		}
+		for i, result := range utils.NewWorkerPool(DefaultPackagesInfoConcurrency).Run(ctx, tasks) {
+			if result.Err != nil {
+				return nil, fmt.Errorf("failed to get %s: %w", names[i], result.Err)
+			}
+			packuments[names[i]] = fetched[i]
+		}
+
+		// 解析版本，新的包版本需要获取清单和tarball大小
+		var next []costRequest
+		var pending []*InstallCostEntry
+		for _, req := range level {
+			packument := packuments[req.name]
+			version := ""
+			if packument != nil {
+				versions := make([]string, 0, len(packument.Versions))
+				for v := range packument.Versions {
+					versions = append(versions, v)
+				}
+				version = resolveSpec(req.spec, packument.DistTags, versions)
+			}
+			if version == "" {
+				if root == nil {
+					return nil, NewValidationError("package", pkg, "no matching version found")
+				}
+				unresolved[req.name+"@"+req.spec] = true
+				continue
+			}
+
+			key := req.name + "@" + version
+			if entry, ok := entries[key]; ok {
+				// 可选依赖被必需的路径引用时改为必需，已获取清单的包重新展开依赖
+				if entry.Optional && !req.optional {
+					entry.Optional = false
+					if manifest := manifests[key]; manifest != nil {
+						next = append(next, manifestDependencies(manifest, false)...)
+					}
+				}
+				continue
+			}
+			entry := &InstallCostEntry{Name: req.name, Version: version, Optional: req.optional}
+			entries[key] = entry
+			pending = append(pending, entry)
+			if root == nil {
+				root = entry
+			}
+		}
+
+		fetchedManifests := make([]*registry.Manifest, len(pending))
+		tasks = make([]utils.Task, len(pending))
+		for i, entry := range pending {
+			tasks[i] = utils.Task{
+				Name: entry.Name + "@" + entry.Version,
+				Run: func(ctx context.Context) error {
+					manifest, err := e.registry.GetManifest(ctx, entry.Name, entry.Version)
+					if err != nil {
+						return err
+					}
+					entry.UnpackedSize = manifest.Dist.UnpackedSize
+					entry.Files = manifest.Dist.FileCount
+					entry.Size = -1
+					if manifest.Dist.Tarball != "" {
+						size, err := e.registry.TarballSize(ctx, manifest.Dist.Tarball)
+						if ctx.Err() != nil {
+							return ctx.Err()
+						}
+						if err == nil {
+							entry.Size = size
+						}
+					}
+					fetchedManifests[i] = manifest
+					return nil
+				},
+			}
+		}
+		for i, result := range utils.NewWorkerPool(DefaultPackagesInfoConcurrency).Run(ctx, tasks) {
+			if result.Err != nil {
+				return nil, fmt.Errorf("failed to get %s: %w", result.Name, result.Err)
+			}
+			manifests[result.Name] = fetchedManifests[i]
+			next = append(next, manifestDependencies(fetchedManifests[i], pending[i].Optional)...)
+		}
+		level = next
+	}
+
+	cost := &InstallCost{Name: root.Name, Version: root.Version, Entries: make([]InstallCostEntry, 0, len(entries))}
+	for _, entry := range entries {
+		cost.Entries = append(cost.Entries, *entry)
+		cost.Packages++
+		cost.UnpackedSize += entry.UnpackedSize
+		cost.Files += entry.Files
+		if entry.Size < 0 {
+			cost.UnknownSize++
+		} else {
+			cost.Size += entry.Size
+		}
+	}
+	sort.Slice(cost.Entries, func(i, j int) bool {
+		if cost.Entries[i].UnpackedSize != cost.Entries[j].UnpackedSize {
+			return cost.Entries[i].UnpackedSize > cost.Entries[j].UnpackedSize
+		}
+		if cost.Entries[i].Name != cost.Entries[j].Name {
+			return cost.Entries[i].Name < cost.Entries[j].Name
+		}
+		return cost.Entries[i].Version < cost.Entries[j].Version
+	})
+	for spec := range unresolved {
+		cost.Unresolved = append(cost.Unresolved, spec)
+	}
+	sort.Strings(cost.Unresolved)
+	return cost, nil
+}
+
+// manifestDependencies 清单中npm会安装的依赖，optionalDependencies覆盖dependencies中的同名依赖
+func manifestDependencies(manifest *registry.Manifest, optional bool) []costRequest {
+	deps := make(map[string]costRequest)
+	for name, spec := range manifest.PeerDependencies {
+		if !manifest.PeerDependenciesMeta[name].Optional {
+			deps[name] = costRequest{name: name, spec: spec, optional: optional}
+		}
+	}
+	for name, spec := range manifest.Dependencies {
+		deps[name] = costRequest{name: name, spec: spec, optional: optional}
+	}
+	for name, spec := range manifest.OptionalDependencies {
+		deps[name] = costRequest{name: name, spec: spec, optional: true}
+	}
+
+	requests := make([]costRequest, 0, len(deps))
+	for _, req := range deps {
+		requests = append(requests, req)
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].name < requests[j].name })
+	return requests
+}
@@ -0,0 +1,147 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+// newCostRegistry 模拟registry，versions为包名到版本清单（不含dist）的JSON
+func newCostRegistry(t *testing.T, versions map[string]map[string]string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		if tarball, ok := strings.CutSuffix(path, ".tgz"); ok {
+			if r.Method != http.MethodHead {
+				t.Errorf("Expected HEAD for tarball, got %s", r.Method)
+			}
+			if tarball != "unknown-1.0.0" {
+				w.Header().Set("Content-Length", fmt.Sprint(len(tarball)*10))
+			} else {
+				w.Header().Set("Transfer-Encoding", "chunked")
+			}
+			return
+		}
+
+		name, version, _ := strings.Cut(path, "/")
+		list, ok := versions[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if version == "" {
+			var latest string
+			var entries []string
+			for v := range list {
+				entries = append(entries, fmt.Sprintf("%q:{}", v))
+				latest = max(latest, v)
+			}
+			fmt.Fprintf(w, `{"name":%q,"dist-tags":{"latest":%q},"versions":{%s}}`, name, latest, strings.Join(entries, ","))
+			return
+		}
+		fmt.Fprintf(w, `{"name":%q,"version":%q,"dist":{"tarball":"%s/%s-%s.tgz","fileCount":2,"unpackedSize":%d}%s}`,
+			name, version, server.URL, name, version, len(name)*100, list[version])
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEstimateInstallCost(t *testing.T) {
+	server := newCostRegistry(t, map[string]map[string]string{
+		"app": {
+			"1.0.0": `,"dependencies":{"a":"^1.0.0","b":"^1.0.0","aliased":"npm:c@^2.0.0","gitdep":"github:user/repo","gone":"^1.0.0"},"optionalDependencies":{"unknown":"^1.0.0"},"peerDependencies":{"react":"*","peer":"^1.0.0"},"peerDependenciesMeta":{"react":{"optional":true}}}`,
+			"2.0.0": `,"dependencies":{}`,
+		},
+		"a":       {"1.0.0": `,"dependencies":{"c":"^1.0.0"}`, "1.1.0": `,"dependencies":{"c":"^1.0.0"}`},
+		"b":       {"1.0.0": `,"dependencies":{"c":"^1.0.0"}`},
+		"c":       {"1.0.0": ``, "2.0.0": ``},
+		"peer":    {"1.0.0": ``},
+		"unknown": {"1.0.0": ``},
+	})
+
+	cost, err := NewInstallCostEstimator(registry.NewClient(server.URL)).EstimateInstallCost(context.Background(), "app@^1.0.0")
+	if err != nil {
+		t.Fatalf("EstimateInstallCost() failed: %v", err)
+	}
+	if cost.Name != "app" || cost.Version != "1.0.0" {
+		t.Errorf("Expected app@1.0.0, got %s@%s", cost.Name, cost.Version)
+	}
+
+	var got []string
+	for _, entry := range cost.Entries {
+		got = append(got, fmt.Sprintf("%s@%s optional=%v", entry.Name, entry.Version, entry.Optional))
+	}
+	expected := []string{
+		"unknown@1.0.0 optional=true",
+		"peer@1.0.0 optional=false",
+		"app@1.0.0 optional=false",
+		"a@1.1.0 optional=false",
+		"b@1.0.0 optional=false",
+		"c@1.0.0 optional=false",
+		"c@2.0.0 optional=false",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected entries:\n%v\nexpected:\n%v", got, expected)
+	}
+	if !reflect.DeepEqual(cost.Unresolved, []string{"gitdep@github:user/repo", "gone@^1.0.0"}) {
+		t.Errorf("Unexpected unresolved: %v", cost.Unresolved)
+	}
+
+	// tarball大小为模拟服务器返回的len(name-version)*10，unknown没有Content-Length
+	if cost.Packages != 7 || cost.UnknownSize != 1 || cost.Files != 14 {
+		t.Errorf("Unexpected totals: %+v", cost)
+	}
+	if cost.Size != int64(len("peer-1.0.0")+len("app-1.0.0")+len("a-1.1.0")+len("b-1.0.0")+2*len("c-1.0.0"))*10 {
+		t.Errorf("Unexpected download size: %d", cost.Size)
+	}
+	if cost.UnpackedSize != int64(len("unknown")+len("peer")+len("app")+len("a")+len("b")+2*len("c"))*100 {
+		t.Errorf("Unexpected unpacked size: %d", cost.UnpackedSize)
+	}
+}
+
+func TestEstimateInstallCostOptionalBecomesRequired(t *testing.T) {
+	server := newCostRegistry(t, map[string]map[string]string{
+		"app":  {"1.0.0": `,"dependencies":{"b":"1.0.0"},"optionalDependencies":{"opt":"1.0.0"}`},
+		"opt":  {"1.0.0": `,"dependencies":{"leaf":"1.0.0"}`},
+		"b":    {"1.0.0": `,"dependencies":{"mid":"1.0.0"}`},
+		"mid":  {"1.0.0": `,"dependencies":{"opt":"1.0.0"}`},
+		"leaf": {"1.0.0": ``},
+	})
+
+	cost, err := NewInstallCostEstimator(registry.NewClient(server.URL)).EstimateInstallCost(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("EstimateInstallCost() failed: %v", err)
+	}
+	for _, entry := range cost.Entries {
+		if entry.Optional {
+			t.Errorf("Expected %s to be required through b -> mid -> opt", entry.Name)
+		}
+	}
+	if cost.Packages != 5 {
+		t.Errorf("Expected 5 packages, got %d", cost.Packages)
+	}
+}
+
+func TestEstimateInstallCostErrors(t *testing.T) {
+	server := newCostRegistry(t, map[string]map[string]string{"app": {"1.0.0": ``}})
+	estimator := NewInstallCostEstimator(registry.NewClient(server.URL))
+
+	if _, err := estimator.EstimateInstallCost(context.Background(), "missing"); !registry.IsNotFound(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	var validationErr *ValidationError
+	if _, err := estimator.EstimateInstallCost(context.Background(), "app@^2.0.0"); !errors.As(err, &validationErr) {
+		t.Errorf("Expected validation error for unmatched range, got %v", err)
+	}
+	if _, err := estimator.EstimateInstallCost(context.Background(), "app@github:user/repo"); !errors.As(err, &validationErr) {
+		t.Errorf("Expected validation error for git spec, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package npm
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/scagogogo/go-npm-sdk/pkg/platform"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
 )
 
 // InstallMethod 安装方法
@@ -25,12 +27,44 @@ const (
 
 // NpmInstallOptions npm安装选项
 type NpmInstallOptions struct {
-	Method      InstallMethod `json:"method"`
-	Version     string        `json:"version"`      // 指定版本，空表示最新版
-	InstallPath string        `json:"install_path"` // 安装路径（便携版使用）
-	Force       bool          `json:"force"`        // 强制安装
-	Global      bool          `json:"global"`       // 全局安装
-	Progress    func(string)  `json:"-"`            // 进度回调
+	Method      InstallMethod         `json:"method"`
+	Version     string                `json:"version"`      // 指定版本，空表示最新版
+	InstallPath string                `json:"install_path"` // 安装路径（便携版使用）
+	Force       bool                  `json:"force"`        // 强制安装
+	Global      bool                  `json:"global"`       // 全局安装
+	Events      platform.EventHandler `json:"-"`            // 进度事件处理器
+
+	// Progress 进度回调，只接收文字描述，与Events同时设置时两者都会收到进度
+	//
+	// Deprecated: 使用Events接收结构化的进度事件。
+	Progress func(string) `json:"-"`
+}
+
+// events 合并Events和旧的Progress回调，都没有设置时返回nil
+func (o NpmInstallOptions) events() platform.EventHandler {
+	if o.Progress == nil {
+		return o.Events
+	}
+	messages := progressMessages(o.Progress)
+	return platform.EventHandlerFunc(func(event platform.ProgressEvent) {
+		platform.EmitProgress(o.Events, event)
+		messages.HandleProgress(event)
+	})
+}
+
+// progressMessages 把进度事件转换为文字进度回调，没有描述的下载事件转换为下载百分比，progress为nil时返回nil
+func progressMessages(progress func(string)) platform.EventHandler {
+	if progress == nil {
+		return nil
+	}
+	return platform.EventHandlerFunc(func(event platform.ProgressEvent) {
+		switch {
+		case event.Message != "":
+			progress(event.Message)
+		case event.Stage == platform.StageDownloading && event.Percent >= 0:
+			progress(fmt.Sprintf("下载进度: %.1f%%", event.Percent))
+		}
+	})
 }
 
 // InstallResult 安装结果
@@ -48,6 +82,9 @@ type Installer struct {
 	detector     *Detector
 	downloader   *platform.NodeJSDownloader
 	platformInfo *platform.Info
+	logger       *slog.Logger
+
+	pureGoExtraction bool // 实验性功能，见Experiments.EnablePureGoExtraction
 }
 
 // NewInstaller 创建npm安装器
@@ -65,6 +102,16 @@ func NewInstaller() (*Installer, error) {
 	}, nil
 }
 
+// SetLogger 设置日志记录器，安装过程中执行的命令以debug级别记录，nil表示不记录
+func (i *Installer) SetLogger(logger *slog.Logger) {
+	i.logger = logger
+}
+
+// SetSignatureVerifier 设置下载时SHASUMS256.txt的签名校验，nil表示只校验哈希
+func (i *Installer) SetSignatureVerifier(verifier platform.SignatureVerifier) {
+	i.downloader.SetSignatureVerifier(verifier)
+}
+
 // Install 安装npm
 func (i *Installer) Install(ctx context.Context, options NpmInstallOptions) (*InstallResult, error) {
 	startTime := time.Now()
@@ -124,9 +171,12 @@ func (i *Installer) installAuto(ctx context.Context, options NpmInstallOptions)
 
 // installViaPackageManager 通过包管理器安装
 func (i *Installer) installViaPackageManager(ctx context.Context, options NpmInstallOptions) (*InstallResult, error) {
-	if options.Progress != nil {
-		options.Progress("正在通过包管理器安装Node.js/npm...")
-	}
+	events := options.events()
+	platform.EmitProgress(events, platform.ProgressEvent{
+		Stage:   platform.StageInstalling,
+		Percent: -1,
+		Message: "正在通过包管理器安装Node.js/npm...",
+	})
 
 	var cmd *exec.Cmd
 	var packageName string
@@ -161,7 +211,7 @@ func (i *Installer) installViaPackageManager(ctx context.Context, options NpmIns
 		switch i.platformInfo.Distribution {
 		case platform.Ubuntu, platform.Debian:
 			cmd = exec.CommandContext(ctx, "sudo", "apt-get", "update")
-			if err := cmd.Run(); err != nil {
+			if _, err := i.runCommand(ctx, cmd); err != nil {
 				return nil, fmt.Errorf("failed to update package list: %w", err)
 			}
 			cmd = exec.CommandContext(ctx, "sudo", "apt-get", "install", "-y", "nodejs", "npm")
@@ -185,11 +235,13 @@ func (i *Installer) installViaPackageManager(ctx context.Context, options NpmIns
 		return nil, NewPlatformError(string(i.platformInfo.Platform), "unsupported platform for package manager installation", nil)
 	}
 
-	if options.Progress != nil {
-		options.Progress(fmt.Sprintf("执行安装命令: %s", cmd.String()))
-	}
+	platform.EmitProgress(events, platform.ProgressEvent{
+		Stage:   platform.StageInstalling,
+		Percent: -1,
+		Message: fmt.Sprintf("执行安装命令: %s", cmd.String()),
+	})
 
-	output, err := cmd.CombinedOutput()
+	output, err := i.runCommand(ctx, cmd)
 	if err != nil {
 		return &InstallResult{
 			Success: false,
@@ -218,9 +270,11 @@ func (i *Installer) installViaPackageManager(ctx context.Context, options NpmIns
 
 // installViaOfficialInstaller 通过官方安装程序安装
 func (i *Installer) installViaOfficialInstaller(ctx context.Context, options NpmInstallOptions) (*InstallResult, error) {
-	if options.Progress != nil {
-		options.Progress("正在下载官方安装程序...")
-	}
+	events := options.events()
+	platform.EmitProgress(events, platform.ProgressEvent{
+		Stage:   platform.StageStarting,
+		Message: "正在下载官方安装程序...",
+	})
 
 	// 获取版本
 	version := options.Version
@@ -240,14 +294,7 @@ func (i *Installer) installViaOfficialInstaller(ctx context.Context, options Npm
 	defer os.RemoveAll(tempDir)
 
 	// 下载安装程序
-	progress := func(downloaded, total int64) {
-		if options.Progress != nil {
-			percent := float64(downloaded) / float64(total) * 100
-			options.Progress(fmt.Sprintf("下载进度: %.1f%%", percent))
-		}
-	}
-
-	result, err := i.downloader.DownloadNodeJS(ctx, version, i.platformInfo, tempDir, progress)
+	result, err := i.downloader.DownloadNodeJSWithEvents(ctx, version, i.platformInfo, tempDir, events)
 	if err != nil {
 		return &InstallResult{
 			Success: false,
@@ -256,10 +303,26 @@ func (i *Installer) installViaOfficialInstaller(ctx context.Context, options Npm
 		}, err
 	}
 
-	if options.Progress != nil {
-		options.Progress("正在安装Node.js...")
+	platform.EmitProgress(events, platform.ProgressEvent{
+		Stage:   platform.StageVerifying,
+		Percent: -1,
+		Message: "正在校验...",
+	})
+
+	if err := i.downloader.VerifyNodeJS(ctx, version, result.FilePath); err != nil {
+		return &InstallResult{
+			Success: false,
+			Method:  OfficialInstaller,
+			Error:   fmt.Errorf("failed to verify Node.js download: %w", err),
+		}, err
 	}
 
+	platform.EmitProgress(events, platform.ProgressEvent{
+		Stage:   platform.StageInstalling,
+		Percent: -1,
+		Message: "正在安装Node.js...",
+	})
+
 	// 执行安装
 	if err := i.executeInstaller(ctx, result.FilePath); err != nil {
 		return &InstallResult{
@@ -292,10 +355,12 @@ func (i *Installer) installPortable(ctx context.Context, options NpmInstallOptio
 	if options.InstallPath == "" {
 		return nil, fmt.Errorf("install path is required for portable installation")
 	}
+	events := options.events()
 
-	if options.Progress != nil {
-		options.Progress("正在下载便携版Node.js...")
-	}
+	platform.EmitProgress(events, platform.ProgressEvent{
+		Stage:   platform.StageStarting,
+		Message: "正在下载便携版Node.js...",
+	})
 
 	// 获取版本
 	version := options.Version
@@ -308,20 +373,13 @@ func (i *Installer) installPortable(ctx context.Context, options NpmInstallOptio
 	}
 
 	// 下载便携版
-	progress := func(downloaded, total int64) {
-		if options.Progress != nil {
-			percent := float64(downloaded) / float64(total) * 100
-			options.Progress(fmt.Sprintf("下载进度: %.1f%%", percent))
-		}
-	}
-
 	tempDir, err := os.MkdirTemp("", "nodejs-portable-")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
 	defer os.RemoveAll(tempDir)
 
-	result, err := i.downloader.DownloadNodeJS(ctx, version, i.platformInfo, tempDir, progress)
+	result, err := i.downloader.DownloadNodeJSWithEvents(ctx, version, i.platformInfo, tempDir, events)
 	if err != nil {
 		return &InstallResult{
 			Success: false,
@@ -330,10 +388,26 @@ func (i *Installer) installPortable(ctx context.Context, options NpmInstallOptio
 		}, err
 	}
 
-	if options.Progress != nil {
-		options.Progress("正在解压便携版...")
+	platform.EmitProgress(events, platform.ProgressEvent{
+		Stage:   platform.StageVerifying,
+		Percent: -1,
+		Message: "正在校验...",
+	})
+
+	if err := i.downloader.VerifyNodeJS(ctx, version, result.FilePath); err != nil {
+		return &InstallResult{
+			Success: false,
+			Method:  Portable,
+			Error:   fmt.Errorf("failed to verify Node.js download: %w", err),
+		}, err
 	}
 
+	platform.EmitProgress(events, platform.ProgressEvent{
+		Stage:   platform.StageExtracting,
+		Percent: -1,
+		Message: "正在解压便携版...",
+	})
+
 	// 解压到目标目录
 	if err := i.extractPortable(result.FilePath, options.InstallPath); err != nil {
 		return &InstallResult{
@@ -395,7 +469,8 @@ func (i *Installer) executeInstaller(ctx context.Context, installerPath string)
 		return fmt.Errorf("official installer not supported on %s", i.platformInfo.Platform)
 	}
 
-	return cmd.Run()
+	_, err := i.runCommand(ctx, cmd)
+	return err
 }
 
 // extractPortable 解压便携版
@@ -405,6 +480,15 @@ func (i *Installer) extractPortable(archivePath, destPath string) error {
 		return err
 	}
 
+	if i.pureGoExtraction {
+		switch {
+		case strings.HasSuffix(archivePath, ".zip"):
+			return extractZip(archivePath, destPath)
+		case strings.HasSuffix(archivePath, ".tar.gz"):
+			return extractTarGz(archivePath, destPath)
+		}
+	}
+
 	var cmd *exec.Cmd
 	switch {
 	case strings.HasSuffix(archivePath, ".zip"):
@@ -421,7 +505,16 @@ func (i *Installer) extractPortable(archivePath, destPath string) error {
 		return fmt.Errorf("unsupported archive format: %s", archivePath)
 	}
 
-	return cmd.Run()
+	_, err := i.runCommand(context.Background(), cmd)
+	return err
+}
+
+// runCommand 运行命令并记录日志，返回合并的stdout和stderr
+func (i *Installer) runCommand(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	utils.LogCommand(ctx, i.logger, cmd, start, err)
+	return output, err
 }
 
 // getPortableNpmPath 获取便携版npm路径
@@ -83,6 +83,30 @@ func TestNpmInstallOptions(t *testing.T) {
 	}
 }
 
+func TestNpmInstallOptionsEvents(t *testing.T) {
+	if (NpmInstallOptions{}).events() != nil {
+		t.Error("Expected nil handler without Events and Progress")
+	}
+
+	var events []platform.ProgressEvent
+	var messages []string
+	options := NpmInstallOptions{
+		Events:   platform.EventHandlerFunc(func(event platform.ProgressEvent) { events = append(events, event) }),
+		Progress: func(message string) { messages = append(messages, message) },
+	}
+	handler := options.events()
+	handler.HandleProgress(platform.ProgressEvent{Stage: platform.StageStarting, Message: "正在下载便携版Node.js..."})
+	handler.HandleProgress(platform.DownloadProgress(50, 200))
+	handler.HandleProgress(platform.DownloadProgress(50, 0))
+
+	if len(events) != 3 {
+		t.Errorf("Expected 3 events, got %d", len(events))
+	}
+	if strings.Join(messages, "|") != "正在下载便携版Node.js...|下载进度: 25.0%" {
+		t.Errorf("Unexpected progress messages: %q", messages)
+	}
+}
+
 func TestInstallResult(t *testing.T) {
 	result := &InstallResult{
 		Success:  true,
@@ -0,0 +1,271 @@
+package npm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/lockfile"
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// IntegrityStatus 已安装包的校验结果
+type IntegrityStatus string
+
+const (
+	IntegrityOK         IntegrityStatus = "ok"
+	IntegrityModified   IntegrityStatus = "modified"   // 安装的文件与tarball的内容不同
+	IntegrityMismatch   IntegrityStatus = "mismatch"   // 安装的版本或获取到的tarball与锁文件不符
+	IntegrityMissing    IntegrityStatus = "missing"    // 锁文件中的包没有安装
+	IntegrityUnverified IntegrityStatus = "unverified" // 锁文件没有sha512校验值，或无法获取tarball
+)
+
+// IntegrityResult 一个安装位置的校验结果
+type IntegrityResult struct {
+	Name      string          `json:"name"`
+	Version   string          `json:"version"`
+	Path      string          `json:"path"` // 相对项目目录的安装位置，例如node_modules/a/node_modules/b
+	Integrity string          `json:"integrity,omitempty"`
+	Status    IntegrityStatus `json:"status"`
+	Reason    string          `json:"reason,omitempty"`
+
+	ModifiedFiles []string `json:"modified_files,omitempty"` // 内容与tarball不同的文件，相对包目录
+	MissingFiles  []string `json:"missing_files,omitempty"`  // tarball中有但没有安装的文件
+}
+
+// IntegrityReport 项目中所有已安装包的校验结果
+type IntegrityReport struct {
+	WorkingDir string                  `json:"working_dir"`
+	Results    []IntegrityResult       `json:"results"` // 按安装位置排序
+	Counts     map[IntegrityStatus]int `json:"counts"`
+}
+
+// Failed 返回被修改、不符或缺失的结果
+func (r *IntegrityReport) Failed() []IntegrityResult {
+	var failed []IntegrityResult
+	for _, result := range r.Results {
+		switch result.Status {
+		case IntegrityModified, IntegrityMismatch, IntegrityMissing:
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// IntegrityVerifier 校验node_modules中的包与package-lock.json记录的integrity是否一致
+//
+// 锁文件中的integrity是tarball的SHA-512，已安装的文件无法还原出原来的tarball，
+// 所以先从npm缓存（按integrity寻址）或resolved地址取得tarball，确认其SHA-512与锁文件一致，
+// 再逐个比较tarball中的文件与安装的文件。安装脚本新生成的文件不算修改。
+type IntegrityVerifier struct {
+	registry    *registry.Client
+	cacheDir    string
+	concurrency int
+}
+
+// NewIntegrityVerifier 创建校验器，registryClient用于下载缓存中没有的tarball，为nil时只使用缓存
+func NewIntegrityVerifier(registryClient *registry.Client) *IntegrityVerifier {
+	return &IntegrityVerifier{
+		registry:    registryClient,
+		cacheDir:    defaultNpmCacheDir(),
+		concurrency: DefaultPackagesInfoConcurrency,
+	}
+}
+
+// SetCacheDir 设置npm缓存目录，默认为npm_config_cache或npm的默认目录
+func (v *IntegrityVerifier) SetCacheDir(dir string) {
+	v.cacheDir = dir
+}
+
+// VerifyIntegrity 重新计算workingDir中已安装包的完整性并与package-lock.json比较
+//
+// 缓存中没有的tarball从锁文件的resolved地址下载，registry由npm_config_registry环境变量决定。
+func VerifyIntegrity(ctx context.Context, workingDir string) (*IntegrityReport, error) {
+	return NewIntegrityVerifier(defaultFallback()).Verify(ctx, workingDir)
+}
+
+// Verify 校验workingDir中锁文件记录的每个安装位置
+func (v *IntegrityVerifier) Verify(ctx context.Context, workingDir string) (*IntegrityReport, error) {
+	path, err := lockfile.Locate(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := lockfile.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if lock.Format != lockfile.FormatNpm && lock.Format != lockfile.FormatShrinkwrap {
+		return nil, NewValidationError("lockfile", string(lock.Format), "integrity verification requires package-lock.json or npm-shrinkwrap.json")
+	}
+
+	packages := make([]*lockfile.Package, 0, len(lock.Packages))
+	for _, pkg := range lock.Packages {
+		if len(pkg.Paths) > 0 {
+			packages = append(packages, pkg)
+		}
+	}
+	results := make([][]IntegrityResult, len(packages))
+	tasks := make([]utils.Task, len(packages))
+	for i, pkg := range packages {
+		tasks[i] = utils.Task{
+			Name: pkg.Key(),
+			Run: func(ctx context.Context) error {
+				results[i] = v.verifyPackage(ctx, workingDir, pkg)
+				return nil
+			},
+		}
+	}
+	for _, result := range utils.NewWorkerPool(v.concurrency).Run(ctx, tasks) {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+	}
+
+	report := &IntegrityReport{WorkingDir: workingDir, Results: []IntegrityResult{}, Counts: make(map[IntegrityStatus]int)}
+	for _, list := range results {
+		for _, result := range list {
+			report.Results = append(report.Results, result)
+			report.Counts[result.Status]++
+		}
+	}
+	sort.Slice(report.Results, func(i, j int) bool { return report.Results[i].Path < report.Results[j].Path })
+	return report, nil
+}
+
+// verifyPackage 校验一个包版本的所有安装位置，tarball只获取一次
+func (v *IntegrityVerifier) verifyPackage(ctx context.Context, workingDir string, pkg *lockfile.Package) []IntegrityResult {
+	results := make([]IntegrityResult, len(pkg.Paths))
+	var pending []int
+	for i, path := range pkg.Paths {
+		results[i] = IntegrityResult{Name: pkg.Name, Version: pkg.Version, Path: path, Integrity: pkg.Integrity}
+		idx := strings.LastIndex(path, "node_modules/")
+		version := installedVersion(filepath.Join(workingDir, filepath.FromSlash(path[:idx])), path[idx+len("node_modules/"):])
+		switch {
+		case version == "":
+			results[i].Status, results[i].Reason = IntegrityMissing, "package is not installed"
+		case version != pkg.Version:
+			results[i].Status, results[i].Reason = IntegrityMismatch, fmt.Sprintf("installed version %s differs from lockfile", version)
+		default:
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return results
+	}
+
+	tarball, status, reason := v.fetchTarball(ctx, pkg)
+	for _, i := range pending {
+		if tarball == nil {
+			results[i].Status, results[i].Reason = status, reason
+			continue
+		}
+		compareInstalledFiles(&results[i], tarball, filepath.Join(workingDir, filepath.FromSlash(results[i].Path)))
+	}
+	return results
+}
+
+// fetchTarball 从缓存或resolved地址取得与锁文件integrity一致的tarball
+func (v *IntegrityVerifier) fetchTarball(ctx context.Context, pkg *lockfile.Package) (*Tarball, IntegrityStatus, string) {
+	expected := sha512Integrity(pkg.Integrity)
+	if expected == nil {
+		return nil, IntegrityUnverified, "lockfile has no sha512 integrity"
+	}
+
+	data, err := os.ReadFile(cacacheContentPath(v.cacheDir, expected))
+	if err != nil || !bytes.Equal(sha512Sum(data), expected) {
+		// 缓存中没有或缓存已损坏时下载
+		if v.registry == nil || pkg.Resolved == "" || !strings.Contains(pkg.Resolved, "://") {
+			return nil, IntegrityUnverified, "tarball is not in the npm cache"
+		}
+		data, err = v.registry.Download(ctx, pkg.Resolved)
+		if err != nil {
+			return nil, IntegrityUnverified, fmt.Sprintf("failed to download tarball: %v", err)
+		}
+		if !bytes.Equal(sha512Sum(data), expected) {
+			return nil, IntegrityMismatch, "tarball from " + pkg.Resolved + " does not match lockfile integrity"
+		}
+	}
+
+	tarball, err := NewTarballInspector(nil).Open(data)
+	if err != nil {
+		return nil, IntegrityUnverified, err.Error()
+	}
+	return tarball, IntegrityOK, ""
+}
+
+// compareInstalledFiles 比较tarball中的文件与dir中安装的文件
+func compareInstalledFiles(result *IntegrityResult, tarball *Tarball, dir string) {
+	for _, file := range tarball.Files {
+		expected, _ := tarball.ReadFile(file.Path)
+		actual, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			result.MissingFiles = append(result.MissingFiles, file.Path)
+		case err != nil, !sameInstalledContent(file.Path, expected, actual):
+			result.ModifiedFiles = append(result.ModifiedFiles, file.Path)
+		}
+	}
+	switch {
+	case len(result.ModifiedFiles) > 0 || len(result.MissingFiles) > 0:
+		result.Status = IntegrityModified
+		result.Reason = fmt.Sprintf("%d files modified, %d missing", len(result.ModifiedFiles), len(result.MissingFiles))
+	default:
+		result.Status = IntegrityOK
+	}
+}
+
+// sameInstalledContent 比较文件内容，旧版npm在安装的package.json中加入_resolved等以下划线开头的字段，比较时忽略
+func sameInstalledContent(path string, expected, actual []byte) bool {
+	if bytes.Equal(expected, actual) {
+		return true
+	}
+	if path != "package.json" {
+		return false
+	}
+	var want, got map[string]any
+	if json.Unmarshal(expected, &want) != nil || json.Unmarshal(actual, &got) != nil {
+		return false
+	}
+	for key := range got {
+		if strings.HasPrefix(key, "_") {
+			delete(got, key)
+		}
+	}
+	return reflect.DeepEqual(want, got)
+}
+
+// sha512Integrity 取出SRI中的sha512摘要，可能有多个以空格分隔的值
+func sha512Integrity(integrity string) []byte {
+	for _, value := range strings.Fields(integrity) {
+		if encoded, ok := strings.CutPrefix(value, "sha512-"); ok {
+			if digest, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(digest) == sha512.Size {
+				return digest
+			}
+		}
+	}
+	return nil
+}
+
+// sha512Sum 计算SHA-512摘要
+func sha512Sum(data []byte) []byte {
+	sum := sha512.Sum512(data)
+	return sum[:]
+}
+
+// cacacheContentPath npm缓存（cacache）中按摘要保存的内容路径
+func cacacheContentPath(cacheDir string, digest []byte) string {
+	hexDigest := hex.EncodeToString(digest)
+	return filepath.Join(cacheDir, "_cacache", "content-v2", "sha512", hexDigest[:2], hexDigest[2:4], hexDigest[4:])
+}
@@ -0,0 +1,118 @@
+package npm
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+func TestIntegrityVerifierVerify(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := t.TempDir()
+
+	// 各包的tarball都包含package.json和index.js
+	tarballs := make(map[string][]byte)
+	integrity := make(map[string]string)
+	for _, name := range []string{"a", "b", "c", "d", "e", "nested"} {
+		data := buildTestTarball(t, []testTarballFile{
+			{"package/package.json", 0644, fmt.Sprintf(`{"name":%q,"version":"1.0.0"}`, name)},
+			{"package/index.js", 0644, "module.exports = '" + name + "'\n"},
+		}, time.Now(), 255)
+		sum := sha512.Sum512(data)
+		tarballs[name] = data
+		integrity[name] = "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+		// e只能从registry下载
+		if name != "e" {
+			path := cacacheContentPath(cacheDir, sum[:])
+			os.MkdirAll(filepath.Dir(path), 0755)
+			os.WriteFile(path, data, 0644)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tarballs[r.URL.Path[1:]])
+	}))
+	defer server.Close()
+
+	install := func(path, name, version, index string) {
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		os.MkdirAll(target, 0755)
+		os.WriteFile(filepath.Join(target, "package.json"), []byte(fmt.Sprintf(`{"name":%q,"version":%q,"_resolved":"x"}`, name, version)), 0644)
+		if index != "" {
+			os.WriteFile(filepath.Join(target, "index.js"), []byte(index), 0644)
+		}
+	}
+	install("node_modules/a", "a", "1.0.0", "module.exports = 'a'\n")
+	install("node_modules/b", "b", "1.0.0", "module.exports = 'tampered'\n")
+	install("node_modules/d", "d", "2.0.0", "module.exports = 'd'\n")
+	install("node_modules/e", "e", "1.0.0", "module.exports = 'e'\n")
+	install("node_modules/a/node_modules/nested", "nested", "1.0.0", "")
+	install("node_modules/f", "f", "1.0.0", "")
+	// 安装脚本生成的文件不算修改
+	os.WriteFile(filepath.Join(dir, "node_modules/a/build.log"), []byte("built"), 0644)
+
+	lock := `{"name":"app","lockfileVersion":3,"packages":{"":{"name":"app"},`
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		lock += fmt.Sprintf(`"node_modules/%s":{"version":"1.0.0","resolved":"%s/%s","integrity":%q},`, name, server.URL, name, integrity[name])
+	}
+	lock += fmt.Sprintf(`"node_modules/a/node_modules/nested":{"version":"1.0.0","integrity":%q},`, integrity["nested"])
+	lock += `"node_modules/f":{"version":"1.0.0"}}}`
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), lock)
+
+	verifier := NewIntegrityVerifier(registry.NewClient(server.URL))
+	verifier.SetCacheDir(cacheDir)
+	report, err := verifier.Verify(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+
+	var got []string
+	for _, result := range report.Results {
+		got = append(got, fmt.Sprintf("%s %s %v %v", result.Path, result.Status, result.ModifiedFiles, result.MissingFiles))
+	}
+	expected := []string{
+		"node_modules/a ok [] []",
+		"node_modules/a/node_modules/nested modified [] [index.js]",
+		"node_modules/b modified [index.js] []",
+		"node_modules/c missing [] []",
+		"node_modules/d mismatch [] []",
+		"node_modules/e ok [] []",
+		"node_modules/f unverified [] []",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected results:\n%v", got)
+	}
+	if len(report.Failed()) != 4 || report.Counts[IntegrityOK] != 2 {
+		t.Errorf("Unexpected counts: %v", report.Counts)
+	}
+
+	// registry返回的tarball与锁文件不符
+	tarballs["e"] = tarballs["a"]
+	report, err = verifier.Verify(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range report.Results {
+		if result.Name == "e" && result.Status != IntegrityMismatch {
+			t.Errorf("Expected tarball mismatch for e, got %+v", result)
+		}
+	}
+}
+
+func TestIntegrityVerifierRequiresNpmLockfile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "yarn.lock"), "# yarn lockfile v1\n")
+	if _, err := NewIntegrityVerifier(nil).Verify(context.Background(), dir); err == nil {
+		t.Error("Expected error for yarn.lock")
+	}
+}
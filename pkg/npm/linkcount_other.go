@@ -0,0 +1,10 @@
+//go:build !unix
+
+package npm
+
+import "os"
+
+// linkCount 当前平台无法通过FileInfo获取硬链接数
+func linkCount(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,188 @@
+package npm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 以下函数直接在package.json文本上修改，保留原有的格式和键顺序
+
+// findJSONObjectKey 在从start处开始的JSON对象中查找直接成员key，返回值的起始位置
+func findJSONObjectKey(content string, start int, key string) (int, bool) {
+	if start < 0 || start >= len(content) || content[start] != '{' {
+		return 0, false
+	}
+
+	depth := 0
+	for i := start; i < len(content); i++ {
+		switch content[i] {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return 0, false
+			}
+		case '"':
+			end := i + 1
+			for end < len(content) && content[end] != '"' {
+				if content[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(content) {
+				return 0, false
+			}
+
+			if depth == 1 {
+				rest := strings.TrimLeft(content[end+1:], " \t\r\n")
+				if strings.HasPrefix(rest, ":") {
+					var name string
+					if json.Unmarshal([]byte(content[i:end+1]), &name) == nil && name == key {
+						valueStart := len(content) - len(rest) + 1
+						for valueStart < len(content) && strings.ContainsRune(" \t\r\n", rune(content[valueStart])) {
+							valueStart++
+						}
+						return valueStart, true
+					}
+				}
+			}
+			i = end
+		}
+	}
+	return 0, false
+}
+
+// setJSONStringMember 在嵌套对象path中设置字符串成员key，路径上缺少的对象会被创建
+func setJSONStringMember(content string, path []string, key, value string) (string, error) {
+	indent := detectJSONIndent(content)
+	object := strings.Index(content, "{")
+	if object < 0 {
+		return content, fmt.Errorf("content is not a JSON object")
+	}
+
+	for depth, name := range path {
+		start, ok := findJSONObjectKey(content, object, name)
+		if !ok {
+			var err error
+			if content, err = insertJSONMember(content, object, depth, indent, name, "{}"); err != nil {
+				return content, err
+			}
+			start, _ = findJSONObjectKey(content, object, name)
+		}
+		if content[start] != '{' {
+			return content, fmt.Errorf("%s is not an object", strings.Join(path[:depth+1], "."))
+		}
+		object = start
+	}
+
+	literal, err := encodeJSONString(value)
+	if err != nil {
+		return content, err
+	}
+
+	start, ok := findJSONObjectKey(content, object, key)
+	if !ok {
+		return insertJSONMember(content, object, len(path), indent, key, literal)
+	}
+	if content[start] != '"' {
+		return content, fmt.Errorf("%s is not a string", strings.Join(append(path, key), "."))
+	}
+	end := jsonValueEnd(content, start)
+	if end < 0 {
+		return content, fmt.Errorf("unterminated string for %s", key)
+	}
+	return content[:start] + literal + content[end:], nil
+}
+
+// setJSONMember 在顶层对象中把成员key设置为rawValue（已编码的JSON值），不存在时追加到末尾
+func setJSONMember(content, key, rawValue string) (string, error) {
+	object := strings.Index(content, "{")
+	if object < 0 {
+		return content, fmt.Errorf("content is not a JSON object")
+	}
+	start, ok := findJSONObjectKey(content, object, key)
+	if !ok {
+		return insertJSONMember(content, object, 0, detectJSONIndent(content), key, rawValue)
+	}
+
+	end := start
+	if strings.ContainsRune("\"{[", rune(content[start])) {
+		end = jsonValueEnd(content, start)
+	} else {
+		// 数字、布尔值和null到下一个分隔符为止
+		for end < len(content) && !strings.ContainsRune(",}] \t\r\n", rune(content[end])) {
+			end++
+		}
+	}
+	if end < 0 {
+		return content, fmt.Errorf("unterminated value for %s", key)
+	}
+	return content[:start] + rawValue + content[end:], nil
+}
+
+// insertJSONMember 在object处开始的对象末尾追加成员，depth为对象的嵌套层级
+func insertJSONMember(content string, object, depth int, indent, key, rawValue string) (string, error) {
+	end := jsonValueEnd(content, object)
+	if end < 0 {
+		return content, fmt.Errorf("unterminated object")
+	}
+	closing := end - 1
+
+	name, err := encodeJSONString(key)
+	if err != nil {
+		return content, err
+	}
+	member := strings.Repeat(indent, depth+1) + name + ": " + rawValue
+
+	inner := content[object+1 : closing]
+	if strings.TrimSpace(inner) == "" {
+		return content[:object+1] + "\n" + member + "\n" + strings.Repeat(indent, depth) + content[closing:], nil
+	}
+	last := object + 1 + len(strings.TrimRight(inner, " \t\r\n"))
+	return content[:last] + ",\n" + member + content[last:], nil
+}
+
+// jsonValueEnd 返回从start处开始的字符串、对象或数组之后的位置，未闭合时返回-1
+func jsonValueEnd(content string, start int) int {
+	depth := 0
+	for i := start; i < len(content); i++ {
+		switch content[i] {
+		case '"':
+			i++
+			for i < len(content) && content[i] != '"' {
+				if content[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(content) {
+				return -1
+			}
+			if depth == 0 {
+				return i + 1
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// detectJSONIndent 检测JSON文本使用的缩进，无法判断时使用两个空格
+func detectJSONIndent(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != line && strings.HasPrefix(trimmed, "\"") {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
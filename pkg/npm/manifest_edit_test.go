@@ -0,0 +1,55 @@
+package npm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindJSONObjectKey(t *testing.T) {
+	content := `{"a": {"dependencies": {"x": "1"}}, "dependencies" : {"x\"y": "2", "x": "3"}}`
+
+	section, ok := findJSONObjectKey(content, 0, "dependencies")
+	if !ok || content[section] != '{' || section < strings.Index(content, `"dependencies" :`) {
+		t.Fatalf("Expected top-level dependencies, got %d, %v", section, ok)
+	}
+	value, ok := findJSONObjectKey(content, section, "x")
+	if !ok || !strings.HasPrefix(content[value:], `"3"`) {
+		t.Errorf("Expected value of x, got %q", content[value:])
+	}
+	if _, ok := findJSONObjectKey(content, section, "missing"); ok {
+		t.Error("Expected missing key not to be found")
+	}
+}
+
+func TestSetJSONStringMember(t *testing.T) {
+	content := "{\n\t\"name\": \"app\",\n\t\"overrides\": {\n\t\t\"minimist\": \"1.2.5\"\n\t}\n}\n"
+
+	updated, err := setJSONStringMember(content, []string{"overrides"}, "minimist", "^1.2.6")
+	if err != nil {
+		t.Fatalf("setJSONStringMember() failed: %v", err)
+	}
+	updated, err = setJSONStringMember(updated, []string{"overrides"}, "lodash", "^4.17.21")
+	if err != nil {
+		t.Fatalf("setJSONStringMember() failed: %v", err)
+	}
+	expected := "{\n\t\"name\": \"app\",\n\t\"overrides\": {\n\t\t\"minimist\": \"^1.2.6\",\n\t\t\"lodash\": \"^4.17.21\"\n\t}\n}\n"
+	if updated != expected {
+		t.Errorf("Unexpected content:\n%s", updated)
+	}
+
+	updated, err = setJSONStringMember("{\n  \"name\": \"app\"\n}\n", []string{"pnpm", "overrides"}, "qs", "6.11.0")
+	if err != nil {
+		t.Fatalf("setJSONStringMember() failed: %v", err)
+	}
+	expected = "{\n  \"name\": \"app\",\n  \"pnpm\": {\n    \"overrides\": {\n      \"qs\": \"6.11.0\"\n    }\n  }\n}\n"
+	if updated != expected {
+		t.Errorf("Unexpected content:\n%s", updated)
+	}
+
+	if _, err := setJSONStringMember(`{"overrides": []}`, []string{"overrides"}, "qs", "1.0.0"); err == nil {
+		t.Error("Expected error when overrides is not an object")
+	}
+	if _, err := setJSONStringMember(`{"overrides": {"qs": {".": "1.0.0"}}}`, []string{"overrides"}, "qs", "1.0.0"); err == nil {
+		t.Error("Expected error when member is not a string")
+	}
+}
@@ -0,0 +1,261 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// Mirror registry及常用二进制包下载地址的镜像配置
+//
+// 可以通过WithMirror以npm_config_*环境变量传给客户端运行的每条npm命令，
+// 也可以通过DependencyManager.ConfigureMirror写入项目的.npmrc。
+type Mirror struct {
+	// Registry npm registry地址，对应registry配置项
+	Registry string `json:"registry,omitempty"`
+
+	// Disturl Node.js头文件的下载地址，node-gyp编译原生模块时使用，对应disturl配置项
+	Disturl string `json:"disturl,omitempty"`
+
+	// BinaryMirrors 安装脚本下载预编译二进制时读取的npm配置项，例如electron_mirror、sass_binary_site，
+	// npm以npm_config_<配置项>环境变量传给安装脚本
+	BinaryMirrors map[string]string `json:"binary_mirrors,omitempty"`
+}
+
+// BinaryMirrorTarget 安装时从网络下载预编译二进制的常用包
+type BinaryMirrorTarget struct {
+	Package   string `json:"package"`    // npm包名
+	Key       string `json:"key"`        // 下载地址的npm配置项
+	Env       string `json:"env"`        // 安装脚本同样读取的环境变量，设置时优先于配置项
+	NpmMirror string `json:"npm_mirror"` // npmmirror上的镜像地址
+}
+
+// BinaryMirrorTargets 返回支持通过npm配置项切换下载地址的常用包，按包名排序
+func BinaryMirrorTargets() []BinaryMirrorTarget {
+	return []BinaryMirrorTarget{
+		{Package: "chromedriver", Key: "chromedriver_cdnurl", Env: "CHROMEDRIVER_CDNURL", NpmMirror: "https://npmmirror.com/mirrors/chromedriver"},
+		{Package: "electron", Key: "electron_mirror", Env: "ELECTRON_MIRROR", NpmMirror: "https://npmmirror.com/mirrors/electron/"},
+		{Package: "electron-builder", Key: "electron_builder_binaries_mirror", Env: "ELECTRON_BUILDER_BINARIES_MIRROR", NpmMirror: "https://npmmirror.com/mirrors/electron-builder-binaries/"},
+		{Package: "node-sass", Key: "sass_binary_site", Env: "SASS_BINARY_SITE", NpmMirror: "https://npmmirror.com/mirrors/node-sass/"},
+		{Package: "playwright", Key: "playwright_download_host", Env: "PLAYWRIGHT_DOWNLOAD_HOST", NpmMirror: "https://npmmirror.com/mirrors/playwright"},
+		{Package: "puppeteer", Key: "puppeteer_download_base_url", Env: "PUPPETEER_DOWNLOAD_BASE_URL", NpmMirror: "https://cdn.npmmirror.com/binaries/chrome-for-testing"},
+		{Package: "sharp", Key: "sharp_binary_host", Env: "npm_config_sharp_binary_host", NpmMirror: "https://npmmirror.com/mirrors/sharp"},
+		{Package: "sharp", Key: "sharp_libvips_binary_host", Env: "npm_config_sharp_libvips_binary_host", NpmMirror: "https://npmmirror.com/mirrors/sharp-libvips"},
+	}
+}
+
+// NpmMirror 返回npmmirror（原淘宝npm镜像，cnpm）的配置
+//
+// 除registry和disturl外还包括BinaryMirrorTargets中所有包的二进制镜像。
+func NpmMirror() Mirror {
+	mirror := Mirror{
+		Registry:      "https://registry.npmmirror.com/",
+		Disturl:       "https://npmmirror.com/mirrors/node/",
+		BinaryMirrors: make(map[string]string),
+	}
+	for _, target := range BinaryMirrorTargets() {
+		mirror.BinaryMirrors[target.Key] = target.NpmMirror
+	}
+	return mirror
+}
+
+// Config 返回镜像对应的npm配置项，即写入.npmrc的内容
+func (m Mirror) Config() map[string]string {
+	config := make(map[string]string, len(m.BinaryMirrors)+2)
+	maps.Copy(config, m.BinaryMirrors)
+	if m.Registry != "" {
+		config["registry"] = m.Registry
+	}
+	if m.Disturl != "" {
+		config["disturl"] = m.Disturl
+	}
+	return config
+}
+
+// Env 返回镜像对应的npm_config_*环境变量
+func (m Mirror) Env() map[string]string {
+	config := m.Config()
+	env := make(map[string]string, len(config))
+	for key, value := range config {
+		env["npm_config_"+key] = value
+	}
+	return env
+}
+
+// WithMirror 所有操作使用镜像，包括npm命令和找不到npm时的registry回退
+//
+// 镜像的npm_config_*环境变量合并到每条命令的环境变量中，BuildCommand返回的命令同样包含这些变量。
+// 设置了Registry时回退的registry客户端也改为镜像，需要私有registry时在其后使用WithRegistryFallback覆盖。
+func WithMirror(mirror Mirror) ClientOption {
+	return func(c *client) {
+		c.setDefaultEnv(mirror.Env())
+		if mirror.Registry != "" && c.fallback != nil {
+			c.fallback = registry.NewClient(mirror.Registry)
+		}
+	}
+}
+
+// setDefaultEnv 设置客户端每条命令默认使用的环境变量
+func (c *client) setDefaultEnv(env map[string]string) {
+	if c.defaultEnv == nil {
+		c.defaultEnv = make(map[string]string, len(env))
+	}
+	maps.Copy(c.defaultEnv, env)
+}
+
+// mergeEnv 在默认环境变量上合并命令的环境变量，两者都为空时返回env本身
+func mergeEnv(defaults, env map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return env
+	}
+	merged := maps.Clone(defaults)
+	maps.Copy(merged, env)
+	return merged
+}
+
+// envExecutor 为每条命令加上默认环境变量的执行器
+type envExecutor struct {
+	executor utils.CommandExecutor
+	env      map[string]string
+}
+
+// Execute 实现utils.CommandExecutor接口
+func (e *envExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	options.Env = mergeEnv(e.env, options.Env)
+	return e.executor.Execute(ctx, options)
+}
+
+// SetLogger 设置被包装执行器的日志记录器
+func (e *envExecutor) SetLogger(logger *slog.Logger) {
+	if executor, ok := e.executor.(interface{ SetLogger(*slog.Logger) }); ok {
+		executor.SetLogger(logger)
+	}
+}
+
+// MirrorCheck 一个镜像配置项的检查结果
+type MirrorCheck struct {
+	Key      string `json:"key"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"` // npm实际使用的值，为空表示未设置
+	OK       bool   `json:"ok"`
+}
+
+// MirrorReport 镜像配置的检查结果，按配置项排序
+type MirrorReport struct {
+	Checks []MirrorCheck `json:"checks"`
+}
+
+// OK 所有配置项是否都已生效
+func (r *MirrorReport) OK() bool {
+	for _, check := range r.Checks {
+		if !check.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed 未生效的配置项
+func (r *MirrorReport) Failed() []MirrorCheck {
+	var failed []MirrorCheck
+	for _, check := range r.Checks {
+		if !check.OK {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// ConfigureMirror 把镜像配置写入项目的.npmrc，返回文件路径
+//
+// 已有的同名配置项被替换，其他内容和注释保持不变。npm 10会对registry以外的未知配置项
+// 输出Unknown project config警告，但仍然以npm_config_*环境变量传给安装脚本。
+func (dm *DependencyManager) ConfigureMirror(mirror Mirror) (string, error) {
+	path := filepath.Join(dm.workingDir, ".npmrc")
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read .npmrc: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(setNpmrcValues(string(content), mirror.Config())), 0644); err != nil {
+		return "", fmt.Errorf("failed to write .npmrc: %w", err)
+	}
+	return path, nil
+}
+
+// VerifyMirror 检查镜像配置是否对项目中运行的npm生效
+//
+// 以npm config list的结果为准，同时覆盖.npmrc、环境变量和WithMirror设置的配置，
+// 可以发现被用户级.npmrc或其他环境变量覆盖的配置项。地址比较时忽略末尾的/。
+func (dm *DependencyManager) VerifyMirror(ctx context.Context, mirror Mirror) (*MirrorReport, error) {
+	config, err := dm.client.ConfigList(ctx, dm.workingDir)
+	if err != nil {
+		return nil, err
+	}
+	actual := make(map[string]string, len(config))
+	for key, value := range config {
+		actual[npmrcKey(key)] = value
+	}
+
+	expected := mirror.Config()
+	keys := slices.Sorted(maps.Keys(expected))
+	report := &MirrorReport{Checks: make([]MirrorCheck, 0, len(keys))}
+	for _, key := range keys {
+		check := MirrorCheck{Key: key, Expected: expected[key], Actual: actual[npmrcKey(key)]}
+		check.OK = strings.TrimSuffix(check.Actual, "/") == strings.TrimSuffix(check.Expected, "/")
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+// npmrcKey 规范化npm配置项的键，npm不区分其中的下划线和连字符
+func npmrcKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+}
+
+// setNpmrcValues 在.npmrc内容中设置配置项，替换已有的同名配置项，新配置项按键排序追加到末尾
+func setNpmrcValues(content string, values map[string]string) string {
+	pending := make(map[string]string, len(values))
+	for key, value := range values {
+		pending[npmrcKey(key)] = key + "=" + value
+	}
+
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	}
+	result := make([]string, 0, len(lines)+len(pending))
+	replaced := make(map[string]bool)
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		key, _, found := strings.Cut(trimmed, "=")
+		if !found || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			result = append(result, line)
+			continue
+		}
+
+		key = npmrcKey(key)
+		switch {
+		case replaced[key]:
+			// 重复的配置项以最后一个为准，替换第一个后删除其余的
+		case pending[key] != "":
+			result = append(result, pending[key])
+			replaced[key] = true
+			delete(pending, key)
+		default:
+			result = append(result, line)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(pending)) {
+		result = append(result, pending[key])
+	}
+	return strings.Join(result, "\n") + "\n"
+}
@@ -0,0 +1,186 @@
+package npm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+func TestMirrorEnv(t *testing.T) {
+	env := NpmMirror().Env()
+	expected := map[string]string{
+		"npm_config_registry":         "https://registry.npmmirror.com/",
+		"npm_config_disturl":          "https://npmmirror.com/mirrors/node/",
+		"npm_config_sass_binary_site": "https://npmmirror.com/mirrors/node-sass/",
+		"npm_config_electron_mirror":  "https://npmmirror.com/mirrors/electron/",
+	}
+	for key, value := range expected {
+		if env[key] != value {
+			t.Errorf("Env()[%s] = %q, expected %q", key, env[key], value)
+		}
+	}
+
+	if env := (Mirror{}).Env(); len(env) != 0 {
+		t.Errorf("Expected empty env for empty mirror, got %v", env)
+	}
+
+	for _, target := range BinaryMirrorTargets() {
+		if NpmMirror().BinaryMirrors[target.Key] != target.NpmMirror {
+			t.Errorf("NpmMirror() is missing %s for %s", target.Key, target.Package)
+		}
+	}
+}
+
+func TestSetNpmrcValues(t *testing.T) {
+	content := "# project settings\nregistry=https://old.example.com/\nsave-exact=true\nelectron-mirror=https://a.example.com/\nELECTRON_MIRROR=https://b.example.com/\n"
+	got := setNpmrcValues(content, map[string]string{
+		"registry":         "https://registry.npmmirror.com/",
+		"electron_mirror":  "https://npmmirror.com/mirrors/electron/",
+		"sass_binary_site": "https://npmmirror.com/mirrors/node-sass/",
+		"disturl":          "https://npmmirror.com/mirrors/node/",
+	})
+	expected := "# project settings\nregistry=https://registry.npmmirror.com/\nsave-exact=true\nelectron_mirror=https://npmmirror.com/mirrors/electron/\n" +
+		"disturl=https://npmmirror.com/mirrors/node/\nsass_binary_site=https://npmmirror.com/mirrors/node-sass/\n"
+	if got != expected {
+		t.Errorf("setNpmrcValues() = %q, expected %q", got, expected)
+	}
+
+	if got := setNpmrcValues("", map[string]string{"registry": "https://r.example.com/"}); got != "registry=https://r.example.com/\n" {
+		t.Errorf("Unexpected content for empty .npmrc: %q", got)
+	}
+}
+
+func TestDependencyManagerMirror(t *testing.T) {
+	dir := t.TempDir()
+	executor := &listExecutor{result: &utils.ExecuteResult{Success: true, Stdout: `{
+		"registry": "https://registry.npmmirror.com",
+		"electron_mirror": "https://npmmirror.com/mirrors/electron/",
+		"sass-binary-site": "https://npmmirror.com/mirrors/node-sass/",
+		"save-exact": true,
+		"cafile": null
+	}`}}
+	npmClient, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	dm, err := NewDependencyManager(npmClient, dir)
+	if err != nil {
+		t.Fatalf("NewDependencyManager() failed: %v", err)
+	}
+
+	mirror := Mirror{
+		Registry: "https://registry.npmmirror.com/",
+		Disturl:  "https://npmmirror.com/mirrors/node/",
+		BinaryMirrors: map[string]string{
+			"electron_mirror":  "https://npmmirror.com/mirrors/electron/",
+			"sass_binary_site": "https://npmmirror.com/mirrors/node-sass/",
+		},
+	}
+	path, err := dm.ConfigureMirror(mirror)
+	if err != nil {
+		t.Fatalf("ConfigureMirror() failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "electron_mirror=https://npmmirror.com/mirrors/electron/\n") {
+		t.Errorf("Unexpected .npmrc: %s, %v", data, err)
+	}
+
+	report, err := dm.VerifyMirror(context.Background(), mirror)
+	if err != nil {
+		t.Fatalf("VerifyMirror() failed: %v", err)
+	}
+	if strings.Join(executor.args, " ") != "config list --json" {
+		t.Errorf("Unexpected args: %v", executor.args)
+	}
+	failed := report.Failed()
+	if report.OK() || len(report.Checks) != 4 || len(failed) != 1 || failed[0].Key != "disturl" || failed[0].Actual != "" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+func TestVerifyMirrorWithNpm(t *testing.T) {
+	npmClient, err := NewClient(WithMirror(Mirror{BinaryMirrors: map[string]string{"sass_binary_site": "https://env.example.com/"}}))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	if !npmClient.IsAvailable(context.Background()) {
+		t.Skip("npm not available")
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name": "app", "version": "1.0.0"}`)
+	dm, err := NewDependencyManager(npmClient, dir)
+	if err != nil {
+		t.Fatalf("NewDependencyManager() failed: %v", err)
+	}
+	if _, err := dm.ConfigureMirror(Mirror{BinaryMirrors: map[string]string{"electron_mirror": "https://npmrc.example.com/"}}); err != nil {
+		t.Fatalf("ConfigureMirror() failed: %v", err)
+	}
+
+	// .npmrc和WithMirror设置的环境变量都应该生效
+	report, err := dm.VerifyMirror(context.Background(), Mirror{BinaryMirrors: map[string]string{
+		"electron_mirror":  "https://npmrc.example.com/",
+		"sass_binary_site": "https://env.example.com/",
+	}})
+	if err != nil {
+		t.Fatalf("VerifyMirror() failed: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Expected mirror to take effect: %+v", report.Failed())
+	}
+}
+
+func TestClientWithMirror(t *testing.T) {
+	executor := &recordingExecutor{}
+	npmClient, err := NewClientWithExecutor("npm", executor, WithMirror(NpmMirror()))
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	c := npmClient.(*client)
+
+	if c.fallback == nil || c.fallback.URL() != "https://registry.npmmirror.com/" {
+		t.Errorf("Expected fallback to use the mirror, got %v", c.fallback)
+	}
+
+	err = npmClient.InstallPackage(context.Background(), "node-sass", InstallOptions{Env: map[string]string{"npm_config_sass_binary_site": "https://example.com/"}})
+	if err != nil {
+		t.Fatalf("InstallPackage() failed: %v", err)
+	}
+	env := executor.options.Env
+	if env["npm_config_registry"] != "https://registry.npmmirror.com/" || env["npm_config_electron_mirror"] == "" {
+		t.Errorf("Expected mirror env, got %v", env)
+	}
+	if env["npm_config_sass_binary_site"] != "https://example.com/" {
+		t.Errorf("Expected call env to take precedence, got %s", env["npm_config_sass_binary_site"])
+	}
+
+	command, err := npmClient.BuildCommand("InstallPackage", nil, "node-sass")
+	if err != nil {
+		t.Fatalf("BuildCommand() failed: %v", err)
+	}
+	if command.Env["npm_config_disturl"] != "https://npmmirror.com/mirrors/node/" {
+		t.Errorf("Expected BuildCommand to include mirror env, got %v", command.Env)
+	}
+
+	npmClient, err = NewClientWithExecutor("npm", executor, WithRegistryFallback(nil), WithMirror(Mirror{Registry: "https://r.example.com/"}))
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	if npmClient.(*client).fallback != nil {
+		t.Error("Expected disabled fallback to stay disabled")
+	}
+}
+
+// recordingExecutor 记录最后一次执行选项
+type recordingExecutor struct {
+	options utils.ExecuteOptions
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	e.options = options
+	return &utils.ExecuteResult{Success: true}, nil
+}
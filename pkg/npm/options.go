@@ -0,0 +1,848 @@
+package npm
+
+import "time"
+
+// 函数式选项
+//
+// 每个操作的选项结构体都有对应的XxxWith函数，用函数式选项构造选项结构体，例如
+//
+//	client.InstallPackage(ctx, "lodash", npm.InstallWith(npm.WithSaveDev(), npm.WithWorkingDir(dir)))
+//
+// 选项函数命名为With<字段名>，同名字段共用一个选项函数，只能传给有该字段的XxxWith函数，传错时编译失败。
+// 选项结构体新增字段时只需要增加选项函数，已有的结构体字面量和函数式写法都不受影响。
+
+// InitOpt 可以传给InitWith的选项
+type InitOpt interface {
+	applyInit(opts *InitOptions)
+}
+
+// InitWith 用函数式选项构造InitOptions，用于Init
+func InitWith(opts ...InitOpt) InitOptions {
+	var options InitOptions
+	for _, opt := range opts {
+		opt.applyInit(&options)
+	}
+	return options
+}
+
+// InstallOpt 可以传给InstallWith的选项
+type InstallOpt interface {
+	applyInstall(opts *InstallOptions)
+}
+
+// InstallWith 用函数式选项构造InstallOptions，用于InstallPackage和InstallPackages
+func InstallWith(opts ...InstallOpt) InstallOptions {
+	var options InstallOptions
+	for _, opt := range opts {
+		opt.applyInstall(&options)
+	}
+	return options
+}
+
+// RunScriptOpt 可以传给RunScriptWith的选项
+type RunScriptOpt interface {
+	applyRunScript(opts *RunScriptOptions)
+}
+
+// RunScriptWith 用函数式选项构造RunScriptOptions，用于RunScriptWithOptions
+func RunScriptWith(opts ...RunScriptOpt) RunScriptOptions {
+	var options RunScriptOptions
+	for _, opt := range opts {
+		opt.applyRunScript(&options)
+	}
+	return options
+}
+
+// UninstallOpt 可以传给UninstallWith的选项
+type UninstallOpt interface {
+	applyUninstall(opts *UninstallOptions)
+}
+
+// UninstallWith 用函数式选项构造UninstallOptions，用于UninstallPackage
+func UninstallWith(opts ...UninstallOpt) UninstallOptions {
+	var options UninstallOptions
+	for _, opt := range opts {
+		opt.applyUninstall(&options)
+	}
+	return options
+}
+
+// UpdateOpt 可以传给UpdateWith的选项
+type UpdateOpt interface {
+	applyUpdate(opts *UpdateOptions)
+}
+
+// UpdateWith 用函数式选项构造UpdateOptions，用于UpdatePackageWithOptions和UpdateAll
+func UpdateWith(opts ...UpdateOpt) UpdateOptions {
+	var options UpdateOptions
+	for _, opt := range opts {
+		opt.applyUpdate(&options)
+	}
+	return options
+}
+
+// ListOpt 可以传给ListWith的选项
+type ListOpt interface {
+	applyList(opts *ListOptions)
+}
+
+// ListWith 用函数式选项构造ListOptions，用于ListPackages
+func ListWith(opts ...ListOpt) ListOptions {
+	var options ListOptions
+	for _, opt := range opts {
+		opt.applyList(&options)
+	}
+	return options
+}
+
+// OutdatedOpt 可以传给OutdatedWith的选项
+type OutdatedOpt interface {
+	applyOutdated(opts *OutdatedOptions)
+}
+
+// OutdatedWith 用函数式选项构造OutdatedOptions，用于Outdated
+func OutdatedWith(opts ...OutdatedOpt) OutdatedOptions {
+	var options OutdatedOptions
+	for _, opt := range opts {
+		opt.applyOutdated(&options)
+	}
+	return options
+}
+
+// PruneOpt 可以传给PruneWith的选项
+type PruneOpt interface {
+	applyPrune(opts *PruneOptions)
+}
+
+// PruneWith 用函数式选项构造PruneOptions，用于Prune
+func PruneWith(opts ...PruneOpt) PruneOptions {
+	var options PruneOptions
+	for _, opt := range opts {
+		opt.applyPrune(&options)
+	}
+	return options
+}
+
+// DedupeOpt 可以传给DedupeWith的选项
+type DedupeOpt interface {
+	applyDedupe(opts *DedupeOptions)
+}
+
+// DedupeWith 用函数式选项构造DedupeOptions，用于Dedupe
+func DedupeWith(opts ...DedupeOpt) DedupeOptions {
+	var options DedupeOptions
+	for _, opt := range opts {
+		opt.applyDedupe(&options)
+	}
+	return options
+}
+
+// ShrinkwrapOpt 可以传给ShrinkwrapWith的选项
+type ShrinkwrapOpt interface {
+	applyShrinkwrap(opts *ShrinkwrapOptions)
+}
+
+// ShrinkwrapWith 用函数式选项构造ShrinkwrapOptions，用于ShrinkwrapWithOptions
+func ShrinkwrapWith(opts ...ShrinkwrapOpt) ShrinkwrapOptions {
+	var options ShrinkwrapOptions
+	for _, opt := range opts {
+		opt.applyShrinkwrap(&options)
+	}
+	return options
+}
+
+// ConfigListOpt 可以传给ConfigListWith的选项
+type ConfigListOpt interface {
+	applyConfigList(opts *ConfigListOptions)
+}
+
+// ConfigListWith 用函数式选项构造ConfigListOptions，用于ConfigListWithOptions
+func ConfigListWith(opts ...ConfigListOpt) ConfigListOptions {
+	var options ConfigListOptions
+	for _, opt := range opts {
+		opt.applyConfigList(&options)
+	}
+	return options
+}
+
+// PublishOpt 可以传给PublishWith的选项
+type PublishOpt interface {
+	applyPublish(opts *PublishOptions)
+}
+
+// PublishWith 用函数式选项构造PublishOptions，用于Publish
+func PublishWith(opts ...PublishOpt) PublishOptions {
+	var options PublishOptions
+	for _, opt := range opts {
+		opt.applyPublish(&options)
+	}
+	return options
+}
+
+// UnpublishOpt 可以传给UnpublishWith的选项
+type UnpublishOpt interface {
+	applyUnpublish(opts *UnpublishOptions)
+}
+
+// UnpublishWith 用函数式选项构造UnpublishOptions，用于Unpublish
+func UnpublishWith(opts ...UnpublishOpt) UnpublishOptions {
+	var options UnpublishOptions
+	for _, opt := range opts {
+		opt.applyUnpublish(&options)
+	}
+	return options
+}
+
+// DistTagOpt 可以传给DistTagWith的选项
+type DistTagOpt interface {
+	applyDistTag(opts *DistTagOptions)
+}
+
+// DistTagWith 用函数式选项构造DistTagOptions，用于DistTagList、DistTagAdd和DistTagRemove
+func DistTagWith(opts ...DistTagOpt) DistTagOptions {
+	var options DistTagOptions
+	for _, opt := range opts {
+		opt.applyDistTag(&options)
+	}
+	return options
+}
+
+// OwnerOpt 可以传给OwnerWith的选项
+type OwnerOpt interface {
+	applyOwner(opts *OwnerOptions)
+}
+
+// OwnerWith 用函数式选项构造OwnerOptions，用于AddOwner、RemoveOwner和ListOwners
+func OwnerWith(opts ...OwnerOpt) OwnerOptions {
+	var options OwnerOptions
+	for _, opt := range opts {
+		opt.applyOwner(&options)
+	}
+	return options
+}
+
+// AccessOpt 可以传给AccessWith的选项
+type AccessOpt interface {
+	applyAccess(opts *AccessOptions)
+}
+
+// AccessWith 用函数式选项构造AccessOptions，用于GetAccess、SetAccess等包访问管理方法
+func AccessWith(opts ...AccessOpt) AccessOptions {
+	var options AccessOptions
+	for _, opt := range opts {
+		opt.applyAccess(&options)
+	}
+	return options
+}
+
+// TokenOpt 可以传给TokenWith的选项
+type TokenOpt interface {
+	applyToken(opts *TokenOptions)
+}
+
+// TokenWith 用函数式选项构造TokenOptions，用于TokenList和TokenRevoke
+func TokenWith(opts ...TokenOpt) TokenOptions {
+	var options TokenOptions
+	for _, opt := range opts {
+		opt.applyToken(&options)
+	}
+	return options
+}
+
+// TokenCreateOpt 可以传给TokenCreateWith的选项
+type TokenCreateOpt interface {
+	applyTokenCreate(opts *TokenCreateOptions)
+}
+
+// TokenCreateWith 用函数式选项构造TokenCreateOptions，用于TokenCreate
+func TokenCreateWith(opts ...TokenCreateOpt) TokenCreateOptions {
+	var options TokenCreateOptions
+	for _, opt := range opts {
+		opt.applyTokenCreate(&options)
+	}
+	return options
+}
+
+// PackOpt 可以传给PackWith的选项
+type PackOpt interface {
+	applyPack(opts *PackOptions)
+}
+
+// PackWith 用函数式选项构造PackOptions，用于Pack
+func PackWith(opts ...PackOpt) PackOptions {
+	var options PackOptions
+	for _, opt := range opts {
+		opt.applyPack(&options)
+	}
+	return options
+}
+
+// AuditOpt 可以传给AuditWith的选项
+type AuditOpt interface {
+	applyAudit(opts *AuditOptions)
+}
+
+// AuditWith 用函数式选项构造AuditOptions，用于Audit
+func AuditWith(opts ...AuditOpt) AuditOptions {
+	var options AuditOptions
+	for _, opt := range opts {
+		opt.applyAudit(&options)
+	}
+	return options
+}
+
+// FundOpt 可以传给FundWith的选项
+type FundOpt interface {
+	applyFund(opts *FundOptions)
+}
+
+// FundWith 用函数式选项构造FundOptions，用于Fund
+func FundWith(opts ...FundOpt) FundOptions {
+	var options FundOptions
+	for _, opt := range opts {
+		opt.applyFund(&options)
+	}
+	return options
+}
+
+// nameOpt WithName的选项值
+type nameOpt string
+
+// WithName 包名，用于InitWith
+func WithName(name string) nameOpt {
+	return nameOpt(name)
+}
+
+func (o nameOpt) applyInit(opts *InitOptions) { opts.Name = string(o) }
+
+// versionOpt WithVersion的选项值
+type versionOpt string
+
+// WithVersion 版本号，用于InitWith
+func WithVersion(version string) versionOpt {
+	return versionOpt(version)
+}
+
+func (o versionOpt) applyInit(opts *InitOptions) { opts.Version = string(o) }
+
+// descriptionOpt WithDescription的选项值
+type descriptionOpt string
+
+// WithDescription 描述，用于InitWith
+func WithDescription(description string) descriptionOpt {
+	return descriptionOpt(description)
+}
+
+func (o descriptionOpt) applyInit(opts *InitOptions) { opts.Description = string(o) }
+
+// authorOpt WithAuthor的选项值
+type authorOpt string
+
+// WithAuthor 作者，用于InitWith
+func WithAuthor(author string) authorOpt {
+	return authorOpt(author)
+}
+
+func (o authorOpt) applyInit(opts *InitOptions) { opts.Author = string(o) }
+
+// licenseOpt WithLicense的选项值
+type licenseOpt string
+
+// WithLicense 许可证，用于InitWith
+func WithLicense(license string) licenseOpt {
+	return licenseOpt(license)
+}
+
+func (o licenseOpt) applyInit(opts *InitOptions) { opts.License = string(o) }
+
+// privateOpt WithPrivate的选项值
+type privateOpt struct{}
+
+// WithPrivate 标记为私有包，用于InitWith
+func WithPrivate() privateOpt {
+	return privateOpt{}
+}
+
+func (o privateOpt) applyInit(opts *InitOptions) { opts.Private = true }
+
+// initializerOpt WithInitializer的选项值
+type initializerOpt string
+
+// WithInitializer 运行npm init <initializer>，例如react-app或@scope/foo，用于InitWith
+func WithInitializer(initializer string) initializerOpt {
+	return initializerOpt(initializer)
+}
+
+func (o initializerOpt) applyInit(opts *InitOptions) { opts.Initializer = string(o) }
+
+// workingDirOpt WithWorkingDir的选项值
+type workingDirOpt string
+
+// WithWorkingDir 工作目录，用于InitWith、InstallWith、RunScriptWith、UninstallWith、UpdateWith、ListWith、OutdatedWith、PruneWith、DedupeWith、ShrinkwrapWith、ConfigListWith、PublishWith、PackWith、AuditWith、FundWith
+func WithWorkingDir(dir string) workingDirOpt {
+	return workingDirOpt(dir)
+}
+
+func (o workingDirOpt) applyInit(opts *InitOptions)             { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyInstall(opts *InstallOptions)       { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyRunScript(opts *RunScriptOptions)   { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyUninstall(opts *UninstallOptions)   { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyUpdate(opts *UpdateOptions)         { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyList(opts *ListOptions)             { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyOutdated(opts *OutdatedOptions)     { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyPrune(opts *PruneOptions)           { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyDedupe(opts *DedupeOptions)         { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyShrinkwrap(opts *ShrinkwrapOptions) { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyConfigList(opts *ConfigListOptions) { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyPublish(opts *PublishOptions)       { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyPack(opts *PackOptions)             { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyAudit(opts *AuditOptions)           { opts.WorkingDir = string(o) }
+func (o workingDirOpt) applyFund(opts *FundOptions)             { opts.WorkingDir = string(o) }
+
+// forceOpt WithForce的选项值
+type forceOpt struct{}
+
+// WithForce --force，用于InitWith、InstallWith、UnpublishWith
+func WithForce() forceOpt {
+	return forceOpt{}
+}
+
+func (o forceOpt) applyInit(opts *InitOptions)           { opts.Force = true }
+func (o forceOpt) applyInstall(opts *InstallOptions)     { opts.Force = true }
+func (o forceOpt) applyUnpublish(opts *UnpublishOptions) { opts.Force = true }
+
+// envOpt WithEnv的选项值
+type envOpt [2]string
+
+// WithEnv 添加一个环境变量，可以多次使用，用于所有XxxWith函数
+func WithEnv(key, value string) envOpt {
+	return envOpt{key, value}
+}
+
+// set 把环境变量加入env，env为nil时新建
+func (o envOpt) set(env map[string]string) map[string]string {
+	if env == nil {
+		env = make(map[string]string)
+	}
+	env[o[0]] = o[1]
+	return env
+}
+
+func (o envOpt) applyInit(opts *InitOptions)               { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyInstall(opts *InstallOptions)         { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyRunScript(opts *RunScriptOptions)     { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyUninstall(opts *UninstallOptions)     { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyUpdate(opts *UpdateOptions)           { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyList(opts *ListOptions)               { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyOutdated(opts *OutdatedOptions)       { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyPrune(opts *PruneOptions)             { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyDedupe(opts *DedupeOptions)           { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyShrinkwrap(opts *ShrinkwrapOptions)   { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyConfigList(opts *ConfigListOptions)   { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyPublish(opts *PublishOptions)         { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyUnpublish(opts *UnpublishOptions)     { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyDistTag(opts *DistTagOptions)         { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyOwner(opts *OwnerOptions)             { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyAccess(opts *AccessOptions)           { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyToken(opts *TokenOptions)             { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyTokenCreate(opts *TokenCreateOptions) { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyPack(opts *PackOptions)               { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyAudit(opts *AuditOptions)             { opts.Env = o.set(opts.Env) }
+func (o envOpt) applyFund(opts *FundOptions)               { opts.Env = o.set(opts.Env) }
+
+// userConfigOpt WithUserConfig的选项值
+type userConfigOpt string
+
+// WithUserConfig 替代的.npmrc路径，用于所有XxxWith函数
+func WithUserConfig(path string) userConfigOpt {
+	return userConfigOpt(path)
+}
+
+func (o userConfigOpt) applyInit(opts *InitOptions)               { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyInstall(opts *InstallOptions)         { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyRunScript(opts *RunScriptOptions)     { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyUninstall(opts *UninstallOptions)     { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyUpdate(opts *UpdateOptions)           { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyList(opts *ListOptions)               { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyOutdated(opts *OutdatedOptions)       { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyPrune(opts *PruneOptions)             { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyDedupe(opts *DedupeOptions)           { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyShrinkwrap(opts *ShrinkwrapOptions)   { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyConfigList(opts *ConfigListOptions)   { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyPublish(opts *PublishOptions)         { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyUnpublish(opts *UnpublishOptions)     { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyDistTag(opts *DistTagOptions)         { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyOwner(opts *OwnerOptions)             { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyAccess(opts *AccessOptions)           { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyToken(opts *TokenOptions)             { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyTokenCreate(opts *TokenCreateOptions) { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyPack(opts *PackOptions)               { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyAudit(opts *AuditOptions)             { opts.UserConfig = string(o) }
+func (o userConfigOpt) applyFund(opts *FundOptions)               { opts.UserConfig = string(o) }
+
+// saveOpt WithSave的选项值
+type saveOpt struct{}
+
+// WithSave --save，用于UpdateWith
+func WithSave() saveOpt {
+	return saveOpt{}
+}
+
+func (o saveOpt) applyUpdate(opts *UpdateOptions) { opts.Save = true }
+
+// saveDevOpt WithSaveDev的选项值
+type saveDevOpt struct{}
+
+// WithSaveDev --save-dev，用于InstallWith、UninstallWith
+func WithSaveDev() saveDevOpt {
+	return saveDevOpt{}
+}
+
+func (o saveDevOpt) applyInstall(opts *InstallOptions)     { opts.SaveDev = true }
+func (o saveDevOpt) applyUninstall(opts *UninstallOptions) { opts.SaveDev = true }
+
+// saveOptionalOpt WithSaveOptional的选项值
+type saveOptionalOpt struct{}
+
+// WithSaveOptional --save-optional，用于InstallWith
+func WithSaveOptional() saveOptionalOpt {
+	return saveOptionalOpt{}
+}
+
+func (o saveOptionalOpt) applyInstall(opts *InstallOptions) { opts.SaveOptional = true }
+
+// saveExactOpt WithSaveExact的选项值
+type saveExactOpt struct{}
+
+// WithSaveExact --save-exact，用于InstallWith
+func WithSaveExact() saveExactOpt {
+	return saveExactOpt{}
+}
+
+func (o saveExactOpt) applyInstall(opts *InstallOptions) { opts.SaveExact = true }
+
+// globalOpt WithGlobal的选项值
+type globalOpt struct{}
+
+// WithGlobal --global，用于InstallWith、UninstallWith、UpdateWith、ListWith
+func WithGlobal() globalOpt {
+	return globalOpt{}
+}
+
+func (o globalOpt) applyInstall(opts *InstallOptions)     { opts.Global = true }
+func (o globalOpt) applyUninstall(opts *UninstallOptions) { opts.Global = true }
+func (o globalOpt) applyUpdate(opts *UpdateOptions)       { opts.Global = true }
+func (o globalOpt) applyList(opts *ListOptions)           { opts.Global = true }
+
+// productionOpt WithProduction的选项值
+type productionOpt struct{}
+
+// WithProduction 只处理生产依赖，安装和列表使用--production，审计使用--omit=dev，用于InstallWith、ListWith、PruneWith、AuditWith
+func WithProduction() productionOpt {
+	return productionOpt{}
+}
+
+func (o productionOpt) applyInstall(opts *InstallOptions) { opts.Production = true }
+func (o productionOpt) applyList(opts *ListOptions)       { opts.Production = true }
+func (o productionOpt) applyPrune(opts *PruneOptions)     { opts.Production = true }
+func (o productionOpt) applyAudit(opts *AuditOptions)     { opts.Production = true }
+
+// registryOpt WithRegistry的选项值
+type registryOpt string
+
+// WithRegistry 自定义registry，用于InstallWith、UpdateWith、PublishWith、UnpublishWith、DistTagWith、OwnerWith、AccessWith、TokenWith、TokenCreateWith、AuditWith
+func WithRegistry(url string) registryOpt {
+	return registryOpt(url)
+}
+
+func (o registryOpt) applyInstall(opts *InstallOptions)         { opts.Registry = string(o) }
+func (o registryOpt) applyUpdate(opts *UpdateOptions)           { opts.Registry = string(o) }
+func (o registryOpt) applyPublish(opts *PublishOptions)         { opts.Registry = string(o) }
+func (o registryOpt) applyUnpublish(opts *UnpublishOptions)     { opts.Registry = string(o) }
+func (o registryOpt) applyDistTag(opts *DistTagOptions)         { opts.Registry = string(o) }
+func (o registryOpt) applyOwner(opts *OwnerOptions)             { opts.Registry = string(o) }
+func (o registryOpt) applyAccess(opts *AccessOptions)           { opts.Registry = string(o) }
+func (o registryOpt) applyToken(opts *TokenOptions)             { opts.Registry = string(o) }
+func (o registryOpt) applyTokenCreate(opts *TokenCreateOptions) { opts.Registry = string(o) }
+func (o registryOpt) applyAudit(opts *AuditOptions)             { opts.Registry = string(o) }
+
+// ignoreScriptsOpt WithIgnoreScripts的选项值
+type ignoreScriptsOpt struct{}
+
+// WithIgnoreScripts --ignore-scripts，用于InstallWith
+func WithIgnoreScripts() ignoreScriptsOpt {
+	return ignoreScriptsOpt{}
+}
+
+func (o ignoreScriptsOpt) applyInstall(opts *InstallOptions) { opts.IgnoreScripts = true }
+
+// legacyPeerDepsOpt WithLegacyPeerDeps的选项值
+type legacyPeerDepsOpt struct{}
+
+// WithLegacyPeerDeps --legacy-peer-deps，用于InstallWith
+func WithLegacyPeerDeps() legacyPeerDepsOpt {
+	return legacyPeerDepsOpt{}
+}
+
+func (o legacyPeerDepsOpt) applyInstall(opts *InstallOptions) { opts.LegacyPeerDeps = true }
+
+// strictPeerDepsOpt WithStrictPeerDeps的选项值
+type strictPeerDepsOpt struct{}
+
+// WithStrictPeerDeps --strict-peer-deps，用于InstallWith
+func WithStrictPeerDeps() strictPeerDepsOpt {
+	return strictPeerDepsOpt{}
+}
+
+func (o strictPeerDepsOpt) applyInstall(opts *InstallOptions) { opts.StrictPeerDeps = true }
+
+// noPackageLockOpt WithNoPackageLock的选项值
+type noPackageLockOpt struct{}
+
+// WithNoPackageLock --no-package-lock，用于InstallWith
+func WithNoPackageLock() noPackageLockOpt {
+	return noPackageLockOpt{}
+}
+
+func (o noPackageLockOpt) applyInstall(opts *InstallOptions) { opts.NoPackageLock = true }
+
+// packageLockOnlyOpt WithPackageLockOnly的选项值
+type packageLockOnlyOpt struct{}
+
+// WithPackageLockOnly --package-lock-only，用于InstallWith
+func WithPackageLockOnly() packageLockOnlyOpt {
+	return packageLockOnlyOpt{}
+}
+
+func (o packageLockOnlyOpt) applyInstall(opts *InstallOptions) { opts.PackageLockOnly = true }
+
+// saveBundleOpt WithSaveBundle的选项值
+type saveBundleOpt struct{}
+
+// WithSaveBundle --save-bundle，用于InstallWith
+func WithSaveBundle() saveBundleOpt {
+	return saveBundleOpt{}
+}
+
+func (o saveBundleOpt) applyInstall(opts *InstallOptions) { opts.SaveBundle = true }
+
+// savePeerOpt WithSavePeer的选项值
+type savePeerOpt struct{}
+
+// WithSavePeer --save-peer，用于InstallWith
+func WithSavePeer() savePeerOpt {
+	return savePeerOpt{}
+}
+
+func (o savePeerOpt) applyInstall(opts *InstallOptions) { opts.SavePeer = true }
+
+// omitOpt WithOmit的选项值
+type omitOpt []string
+
+// WithOmit --omit，可选dev、optional、peer，可以多次使用，用于InstallWith
+func WithOmit(types ...string) omitOpt {
+	return omitOpt(types)
+}
+
+func (o omitOpt) applyInstall(opts *InstallOptions) { opts.Omit = append(opts.Omit, o...) }
+
+// onOutputOpt WithOnOutput的选项值
+type onOutputOpt func(OutputLine)
+
+// WithOnOutput 逐行接收命令的输出，用于InitWith、InstallWith、RunScriptWith
+func WithOnOutput(fn func(OutputLine)) onOutputOpt {
+	return onOutputOpt(fn)
+}
+
+func (o onOutputOpt) applyInit(opts *InitOptions)           { opts.OnOutput = o }
+func (o onOutputOpt) applyInstall(opts *InstallOptions)     { opts.OnOutput = o }
+func (o onOutputOpt) applyRunScript(opts *RunScriptOptions) { opts.OnOutput = o }
+
+// argsOpt WithArgs的选项值
+type argsOpt []string
+
+// WithArgs 传给脚本或初始化器的参数，可以多次使用，用于RunScriptWith、InitWith
+func WithArgs(args ...string) argsOpt {
+	return argsOpt(args)
+}
+
+func (o argsOpt) applyInit(opts *InitOptions)           { opts.Args = append(opts.Args, o...) }
+func (o argsOpt) applyRunScript(opts *RunScriptOptions) { opts.Args = append(opts.Args, o...) }
+
+// processGroupOpt WithProcessGroup的选项值
+type processGroupOpt struct{}
+
+// WithProcessGroup 在新的进程组中运行脚本，取消时同时终止子进程，用于RunScriptWith
+func WithProcessGroup() processGroupOpt {
+	return processGroupOpt{}
+}
+
+func (o processGroupOpt) applyRunScript(opts *RunScriptOptions) { opts.ProcessGroup = true }
+
+// timeoutOpt WithTimeout的选项值
+type timeoutOpt time.Duration
+
+// WithTimeout 超时时间，小于0表示不限制，用于RunScriptWith
+func WithTimeout(timeout time.Duration) timeoutOpt {
+	return timeoutOpt(timeout)
+}
+
+func (o timeoutOpt) applyRunScript(opts *RunScriptOptions) { opts.Timeout = time.Duration(o) }
+
+// depthOpt WithDepth的选项值
+type depthOpt int
+
+// WithDepth --depth，用于ListWith
+func WithDepth(depth int) depthOpt {
+	return depthOpt(depth)
+}
+
+func (o depthOpt) applyList(opts *ListOptions) { opts.Depth = int(o) }
+
+// jsonOpt WithJSON的选项值
+type jsonOpt struct{}
+
+// WithJSON --json，用于ListWith
+func WithJSON() jsonOpt {
+	return jsonOpt{}
+}
+
+func (o jsonOpt) applyList(opts *ListOptions) { opts.JSON = true }
+
+// dryRunOpt WithDryRun的选项值
+type dryRunOpt struct{}
+
+// WithDryRun --dry-run，用于PruneWith、PublishWith、PackWith
+func WithDryRun() dryRunOpt {
+	return dryRunOpt{}
+}
+
+func (o dryRunOpt) applyPrune(opts *PruneOptions)     { opts.DryRun = true }
+func (o dryRunOpt) applyPublish(opts *PublishOptions) { opts.DryRun = true }
+func (o dryRunOpt) applyPack(opts *PackOptions)       { opts.DryRun = true }
+
+// tagOpt WithTag的选项值
+type tagOpt string
+
+// WithTag --tag，用于PublishWith
+func WithTag(tag string) tagOpt {
+	return tagOpt(tag)
+}
+
+func (o tagOpt) applyPublish(opts *PublishOptions) { opts.Tag = string(o) }
+
+// publishAccessOpt WithAccess的选项值
+type publishAccessOpt string
+
+// WithAccess --access，public或restricted，用于PublishWith
+func WithAccess(access string) publishAccessOpt {
+	return publishAccessOpt(access)
+}
+
+func (o publishAccessOpt) applyPublish(opts *PublishOptions) { opts.Access = string(o) }
+
+// otpOpt WithOTP的选项值
+type otpOpt string
+
+// WithOTP --otp，用于PublishWith、UnpublishWith、DistTagWith、OwnerWith、AccessWith、TokenWith、TokenCreateWith
+func WithOTP(otp string) otpOpt {
+	return otpOpt(otp)
+}
+
+func (o otpOpt) applyPublish(opts *PublishOptions)         { opts.OTP = string(o) }
+func (o otpOpt) applyUnpublish(opts *UnpublishOptions)     { opts.OTP = string(o) }
+func (o otpOpt) applyDistTag(opts *DistTagOptions)         { opts.OTP = string(o) }
+func (o otpOpt) applyOwner(opts *OwnerOptions)             { opts.OTP = string(o) }
+func (o otpOpt) applyAccess(opts *AccessOptions)           { opts.OTP = string(o) }
+func (o otpOpt) applyToken(opts *TokenOptions)             { opts.OTP = string(o) }
+func (o otpOpt) applyTokenCreate(opts *TokenCreateOptions) { opts.OTP = string(o) }
+
+// provenanceFileOpt WithProvenanceFile的选项值
+type provenanceFileOpt string
+
+// WithProvenanceFile --provenance-file，用于PublishWith
+func WithProvenanceFile(path string) provenanceFileOpt {
+	return provenanceFileOpt(path)
+}
+
+func (o provenanceFileOpt) applyPublish(opts *PublishOptions) { opts.ProvenanceFile = string(o) }
+
+// readOnlyOpt WithReadOnly的选项值
+type readOnlyOpt struct{}
+
+// WithReadOnly --read-only，用于TokenCreateWith
+func WithReadOnly() readOnlyOpt {
+	return readOnlyOpt{}
+}
+
+func (o readOnlyOpt) applyTokenCreate(opts *TokenCreateOptions) { opts.ReadOnly = true }
+
+// cidrOpt WithCIDR的选项值
+type cidrOpt []string
+
+// WithCIDR --cidr，可以多次使用，用于TokenCreateWith
+func WithCIDR(ranges ...string) cidrOpt {
+	return cidrOpt(ranges)
+}
+
+func (o cidrOpt) applyTokenCreate(opts *TokenCreateOptions) { opts.CIDR = append(opts.CIDR, o...) }
+
+// passwordOpt WithPassword的选项值
+type passwordOpt string
+
+// WithPassword 账户密码，用于TokenCreateWith
+func WithPassword(password string) passwordOpt {
+	return passwordOpt(password)
+}
+
+func (o passwordOpt) applyTokenCreate(opts *TokenCreateOptions) { opts.Password = string(o) }
+
+// specOpt WithSpec的选项值
+type specOpt string
+
+// WithSpec 要打包的包，用于PackWith
+func WithSpec(spec string) specOpt {
+	return specOpt(spec)
+}
+
+func (o specOpt) applyPack(opts *PackOptions) { opts.Spec = string(o) }
+
+// packDestinationOpt WithPackDestination的选项值
+type packDestinationOpt string
+
+// WithPackDestination --pack-destination，用于PackWith
+func WithPackDestination(dir string) packDestinationOpt {
+	return packDestinationOpt(dir)
+}
+
+func (o packDestinationOpt) applyPack(opts *PackOptions) { opts.PackDestination = string(o) }
+
+// normalizeOpt WithNormalize的选项值
+type normalizeOpt struct{}
+
+// WithNormalize 打包后重写tarball，使哈希与机器无关，用于PackWith
+func WithNormalize() normalizeOpt {
+	return normalizeOpt{}
+}
+
+func (o normalizeOpt) applyPack(opts *PackOptions) { opts.Normalize = true }
+
+// ignoreFileOpt WithIgnoreFile的选项值
+type ignoreFileOpt string
+
+// WithIgnoreFile 审计忽略文件，用于AuditWith
+func WithIgnoreFile(path string) ignoreFileOpt {
+	return ignoreFileOpt(path)
+}
+
+func (o ignoreFileOpt) applyAudit(opts *AuditOptions) { opts.IgnoreFile = string(o) }
+
+// workspacesOpt WithWorkspaces的选项值
+type workspacesOpt []string
+
+// WithWorkspaces --workspace，可以多次使用，用于FundWith
+func WithWorkspaces(names ...string) workspacesOpt {
+	return workspacesOpt(names)
+}
+
+func (o workspacesOpt) applyFund(opts *FundOptions) { opts.Workspaces = append(opts.Workspaces, o...) }
@@ -0,0 +1,72 @@
+package npm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// allOptions 每个函数式选项各一个，值均不为零
+func allOptions() []interface{} {
+	return []interface{}{
+		WithName("app"), WithVersion("1.0.0"), WithDescription("desc"), WithAuthor("me"), WithLicense("MIT"), WithPrivate(), WithInitializer("react-app"),
+		WithWorkingDir("/app"), WithForce(), WithEnv("CI", "true"), WithUserConfig("/app/.npmrc"),
+		WithSave(), WithSaveDev(), WithSaveOptional(), WithSaveExact(), WithGlobal(), WithProduction(), WithRegistry("https://registry.example.com/"),
+		WithIgnoreScripts(), WithLegacyPeerDeps(), WithStrictPeerDeps(), WithNoPackageLock(), WithPackageLockOnly(), WithSaveBundle(), WithSavePeer(),
+		WithOmit("dev"), WithOnOutput(func(OutputLine) {}), WithArgs("--watch"), WithProcessGroup(), WithTimeout(time.Minute),
+		WithDepth(2), WithJSON(), WithDryRun(), WithTag("next"), WithAccess("public"), WithOTP("123456"), WithProvenanceFile("bundle.json"),
+		WithReadOnly(), WithCIDR("10.0.0.0/8"), WithPassword("secret"), WithSpec("lodash"), WithPackDestination("/tmp"), WithNormalize(),
+		WithIgnoreFile(".npm-audit-ignore.json"), WithWorkspaces("a"),
+	}
+}
+
+// applyAll 把所有适用的选项传给with
+func applyAll[T, O any](with func(...O) T) T {
+	var opts []O
+	for _, option := range allOptions() {
+		if opt, ok := option.(O); ok {
+			opts = append(opts, opt)
+		}
+	}
+	return with(opts...)
+}
+
+func TestOptionsCoverAllFields(t *testing.T) {
+	// 选项结构体新增字段时需要增加对应的选项函数
+	results := []interface{}{
+		applyAll(InitWith), applyAll(InstallWith), applyAll(RunScriptWith), applyAll(UninstallWith), applyAll(UpdateWith),
+		applyAll(ListWith), applyAll(OutdatedWith), applyAll(PruneWith), applyAll(DedupeWith), applyAll(ShrinkwrapWith), applyAll(ConfigListWith), applyAll(PublishWith),
+		applyAll(UnpublishWith), applyAll(DistTagWith), applyAll(OwnerWith), applyAll(AccessWith),
+		applyAll(TokenWith), applyAll(TokenCreateWith), applyAll(PackWith), applyAll(AuditWith), applyAll(FundWith),
+	}
+	for _, result := range results {
+		value := reflect.ValueOf(result)
+		for i := 0; i < value.NumField(); i++ {
+			if value.Field(i).IsZero() {
+				t.Errorf("%s.%s has no functional option", value.Type().Name(), value.Type().Field(i).Name)
+			}
+		}
+	}
+}
+
+func TestInstallWith(t *testing.T) {
+	options := InstallWith(WithSaveDev(), WithWorkingDir("/app"), WithEnv("CI", "true"), WithEnv("NODE_ENV", "test"), WithOmit("optional"), WithOmit("peer"))
+	expected := InstallOptions{
+		SaveDev:    true,
+		WorkingDir: "/app",
+		Env:        map[string]string{"CI": "true", "NODE_ENV": "test"},
+		Omit:       []string{"optional", "peer"},
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("InstallWith() = %+v, expected %+v", options, expected)
+	}
+
+	if options := InstallWith(); !reflect.DeepEqual(options, InstallOptions{}) {
+		t.Errorf("Expected zero options, got %+v", options)
+	}
+
+	script := RunScriptWith(WithArgs("--port", "3000"), WithArgs("--open"), WithTimeout(-1))
+	if !reflect.DeepEqual(script.Args, []string{"--port", "3000", "--open"}) || script.Timeout != -1 {
+		t.Errorf("Unexpected run script options: %+v", script)
+	}
+}
@@ -0,0 +1,268 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/osv"
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// DefaultOSVConcurrency OSVScanner获取漏洞详情的默认并发数
+const DefaultOSVConcurrency = 8
+
+// OSVScanner 通过OSV.dev查询锁文件中每个包版本的漏洞
+//
+// 不需要npm，也不依赖registry的审计接口，适用于不支持npm audit的私有registry。
+// 结果与npm audit的格式相同，修复版本来自OSV记录的fixed事件，修复方式是升级受影响的包本身。
+type OSVScanner struct {
+	client      *osv.Client
+	concurrency int
+}
+
+// NewOSVScanner 创建OSV扫描器，client为nil时使用OSV.dev官方地址
+func NewOSVScanner(client *osv.Client) *OSVScanner {
+	if client == nil {
+		client = osv.NewClient("")
+	}
+	return &OSVScanner{client: client, concurrency: DefaultOSVConcurrency}
+}
+
+// SetConcurrency 设置获取漏洞详情的并发数，小于等于0时使用DefaultOSVConcurrency
+func (s *OSVScanner) SetConcurrency(concurrency int) {
+	if concurrency <= 0 {
+		concurrency = DefaultOSVConcurrency
+	}
+	s.concurrency = concurrency
+}
+
+// WithOSVAudit npm audit失败或找不到npm时使用OSV扫描锁文件
+//
+// 私有registry不支持审计接口时npm audit会失败，设置后Audit改为返回OSV的扫描结果。
+func WithOSVAudit(scanner *OSVScanner) ClientOption {
+	return func(c *client) {
+		c.auditScanner = scanner
+	}
+}
+
+// Scan 扫描options.WorkingDir中锁文件的所有包版本，options.Production时跳过开发依赖
+//
+// 只使用options的WorkingDir、Production和IgnoreFile，IgnoreFile中的规则按公告编号匹配。
+func (s *OSVScanner) Scan(ctx context.Context, options AuditOptions) (*AuditReport, error) {
+	targets, err := loadAuditTargets(options)
+	if err != nil {
+		return nil, fmt.Errorf("OSV scan requires a lockfile: %w", err)
+	}
+
+	names := make([]string, 0, len(targets.versions))
+	for name := range targets.versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type target struct{ name, version string }
+	var queried []target
+	var queries []osv.Query
+	for _, name := range names {
+		for _, version := range targets.versions[name] {
+			queried = append(queried, target{name, version})
+			queries = append(queries, osv.Query{
+				Package: osv.Package{Name: name, Ecosystem: osv.EcosystemNpm},
+				Version: version,
+			})
+		}
+	}
+
+	ids, err := s.client.QueryBatch(ctx, queries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OSV: %w", err)
+	}
+
+	vulns, err := s.fetch(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一个包的多个版本合并为一个问题，修复版本需要修复所有版本的所有漏洞，任意一个没有修复版本时不可修复
+	findings := make(map[string]*AuditFinding)
+	fixes := make(map[string]*semver.Version)
+	unfixable := make(map[string]bool)
+	for i, target := range queried {
+		for _, id := range ids[i] {
+			vuln := vulns[id]
+			finding, exists := findings[target.name]
+			if !exists {
+				finding = &AuditFinding{Name: target.name, IsDirect: targets.direct[target.name]}
+				findings[target.name] = finding
+			}
+			addOSVAdvisory(finding, target.name, vuln)
+
+			fix := osvFixVersion(target.name, target.version, vuln)
+			switch {
+			case fix == nil:
+				unfixable[target.name] = true
+			case fixes[target.name] == nil || fixes[target.name].LessThan(fix):
+				fixes[target.name] = fix
+			}
+		}
+	}
+
+	report := &AuditReport{Counts: make(map[Severity]int), Dependencies: targets.dependencies}
+	for _, name := range names {
+		finding, exists := findings[name]
+		if !exists {
+			continue
+		}
+		ranges := make([]string, 0, len(finding.Advisories))
+		for _, advisory := range finding.Advisories {
+			ranges = append(ranges, advisory.Range)
+		}
+		finding.Range = strings.Join(ranges, " || ")
+		if fix := fixes[name]; fix != nil && !unfixable[name] {
+			finding.Fix = AuditFix{
+				Available:     true,
+				Name:          name,
+				Version:       fix.String(),
+				IsSemVerMajor: osvMajorUpgrade(targets.versions[name], fix),
+			}
+		}
+		report.Findings = append(report.Findings, *finding)
+		report.Counts[finding.Severity]++
+	}
+	report.Total = len(report.Findings)
+
+	if options.IgnoreFile != "" {
+		ignores, err := LoadAuditIgnoreFile(options.IgnoreFile)
+		if err != nil {
+			return nil, err
+		}
+		report = report.ApplyIgnores(ignores, time.Now())
+	}
+	return report, nil
+}
+
+// fetch 并发获取所有漏洞的详情
+func (s *OSVScanner) fetch(ctx context.Context, ids [][]string) (map[string]*osv.Vulnerability, error) {
+	var unique []string
+	for _, list := range ids {
+		for _, id := range list {
+			if !slices.Contains(unique, id) {
+				unique = append(unique, id)
+			}
+		}
+	}
+
+	vulns := make([]*osv.Vulnerability, len(unique))
+	tasks := make([]utils.Task, len(unique))
+	for i, id := range unique {
+		tasks[i] = utils.Task{
+			Name: id,
+			Run: func(ctx context.Context) error {
+				vuln, err := s.client.GetVulnerability(ctx, id)
+				if err != nil {
+					return fmt.Errorf("failed to get %s: %w", id, err)
+				}
+				vulns[i] = vuln
+				return nil
+			},
+		}
+	}
+
+	result := make(map[string]*osv.Vulnerability, len(unique))
+	for i, taskResult := range utils.NewWorkerPool(s.concurrency).Run(ctx, tasks) {
+		if taskResult.Err != nil {
+			return nil, taskResult.Err
+		}
+		result[unique[i]] = vulns[i]
+	}
+	return result, nil
+}
+
+// addOSVAdvisory 把漏洞加入问题的公告列表，严重程度取最高值
+func addOSVAdvisory(finding *AuditFinding, name string, vuln *osv.Vulnerability) {
+	url := osvAdvisoryURL(vuln)
+	for _, advisory := range finding.Advisories {
+		if advisory.URL == url {
+			return
+		}
+	}
+
+	severity := osvSeverity(vuln)
+	title := vuln.Summary
+	if title == "" {
+		title = vuln.ID
+	}
+	finding.Advisories = append(finding.Advisories, Advisory{
+		Name:     name,
+		Title:    title,
+		URL:      url,
+		Severity: severity,
+		CWE:      vuln.DatabaseSpecific.CWEIDs,
+		Range:    strings.Join(vuln.AffectedRanges(osv.EcosystemNpm, name), " || "),
+	})
+	if severity.Rank() > finding.Severity.Rank() {
+		finding.Severity = severity
+	}
+}
+
+// osvFixVersion 修复version中vuln的最低版本，没有修复版本时返回nil
+func osvFixVersion(name, version string, vuln *osv.Vulnerability) *semver.Version {
+	current, err := semver.Parse(version)
+	if err != nil {
+		return nil
+	}
+	var fix *semver.Version
+	for _, fixed := range vuln.FixedVersions(osv.EcosystemNpm, name) {
+		candidate, err := semver.Parse(fixed)
+		if err != nil || !current.LessThan(candidate) {
+			continue
+		}
+		if fix == nil || candidate.LessThan(fix) {
+			fix = candidate
+		}
+	}
+	return fix
+}
+
+// osvMajorUpgrade 升级到fix是否跨越某个已安装版本的主版本
+func osvMajorUpgrade(versions []string, fix *semver.Version) bool {
+	for _, version := range versions {
+		if current, err := semver.Parse(version); err == nil && current.Major != fix.Major {
+			return true
+		}
+	}
+	return false
+}
+
+// osvAdvisoryURL GitHub公告使用与npm audit相同的地址，其他来源使用osv.dev的页面
+func osvAdvisoryURL(vuln *osv.Vulnerability) string {
+	if strings.HasPrefix(vuln.ID, "GHSA-") {
+		return "https://github.com/advisories/" + vuln.ID
+	}
+	return vuln.URL()
+}
+
+// osvSeverity 漏洞的严重程度
+//
+// GitHub公告在database_specific中给出严重程度；恶意包（MAL-）按critical处理；其他情况按moderate处理。
+func osvSeverity(vuln *osv.Vulnerability) Severity {
+	switch strings.ToLower(vuln.DatabaseSpecific.Severity) {
+	case "critical":
+		return SeverityCritical
+	case "high":
+		return SeverityHigh
+	case "moderate", "medium":
+		return SeverityModerate
+	case "low":
+		return SeverityLow
+	}
+	if strings.HasPrefix(vuln.ID, "MAL-") {
+		return SeverityCritical
+	}
+	return SeverityModerate
+}
@@ -0,0 +1,160 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/osv"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// newOSVTestServer 模拟OSV API，vulns为漏洞ID到详情的映射，affected为name@version到漏洞ID的映射
+func newOSVTestServer(t *testing.T, vulns map[string]string, affected map[string][]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, found := strings.CutPrefix(r.URL.Path, "/v1/vulns/"); found {
+			if vuln, ok := vulns[id]; ok {
+				w.Write([]byte(vuln))
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+
+		var body struct {
+			Queries []osv.Query `json:"queries"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		type vuln struct {
+			ID string `json:"id"`
+		}
+		results := make([]map[string][]vuln, len(body.Queries))
+		for i, query := range body.Queries {
+			results[i] = map[string][]vuln{}
+			for _, id := range affected[query.Package.Name+"@"+query.Version] {
+				results[i]["vulns"] = append(results[i]["vulns"], vuln{id})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// osvTestLockfile 包含直接依赖lodash和两个版本的传递依赖minimist
+const osvTestLockfile = `{
+  "name": "app",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0", "dependencies": {"lodash": "^4.17.0", "mkdirp": "^0.5.0"}, "devDependencies": {"evil": "^1.0.0"}},
+    "node_modules/lodash": {"version": "4.17.20"},
+    "node_modules/mkdirp": {"version": "0.5.5", "dependencies": {"minimist": "^1.2.5"}},
+    "node_modules/minimist": {"version": "1.2.5"},
+    "node_modules/other/node_modules/minimist": {"version": "0.0.8"},
+    "node_modules/evil": {"version": "1.0.0", "dev": true}
+  }
+}`
+
+var osvTestVulns = map[string]string{
+	"GHSA-35jh-r3h4-6jhm": `{"id":"GHSA-35jh-r3h4-6jhm","summary":"Command Injection in lodash",
+		"affected":[{"package":{"ecosystem":"npm","name":"lodash"},"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"4.17.21"}]}]}],
+		"database_specific":{"severity":"HIGH","cwe_ids":["CWE-77"]}}`,
+	"GHSA-xvch-5gv4-984h": `{"id":"GHSA-xvch-5gv4-984h","summary":"Prototype Pollution in minimist",
+		"affected":[{"package":{"ecosystem":"npm","name":"minimist"},"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"0.2.4"},{"introduced":"1.0.0"},{"fixed":"1.2.6"}]}]}],
+		"database_specific":{"severity":"CRITICAL"}}`,
+	"MAL-2024-1": `{"id":"MAL-2024-1","summary":"Malicious code in evil",
+		"affected":[{"package":{"ecosystem":"npm","name":"evil"},"versions":["1.0.0"]}]}`,
+}
+
+var osvTestAffected = map[string][]string{
+	"lodash@4.17.20": {"GHSA-35jh-r3h4-6jhm"},
+	"minimist@1.2.5": {"GHSA-xvch-5gv4-984h"},
+	"minimist@0.0.8": {"GHSA-xvch-5gv4-984h"},
+	"evil@1.0.0":     {"MAL-2024-1"},
+}
+
+func TestOSVScannerScan(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), osvTestLockfile)
+	server := newOSVTestServer(t, osvTestVulns, osvTestAffected)
+
+	report, err := NewOSVScanner(osv.NewClient(server.URL)).Scan(context.Background(), AuditOptions{WorkingDir: dir})
+	if err != nil {
+		t.Fatalf("Scan() failed: %v", err)
+	}
+	if report.Total != 3 || report.Dependencies != 5 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if report.Counts[SeverityCritical] != 2 || report.Counts[SeverityHigh] != 1 {
+		t.Errorf("Unexpected counts: %v", report.Counts)
+	}
+
+	lodash := report.Finding("lodash")
+	if lodash == nil || !lodash.IsDirect || lodash.Severity != SeverityHigh || lodash.Range != "<4.17.21" {
+		t.Fatalf("Unexpected lodash finding: %+v", lodash)
+	}
+	if lodash.Fix != (AuditFix{Available: true, Name: "lodash", Version: "4.17.21"}) {
+		t.Errorf("Unexpected lodash fix: %+v", lodash.Fix)
+	}
+	advisory := lodash.Advisories[0]
+	if advisory.ID() != "GHSA-35jh-r3h4-6jhm" || advisory.Title != "Command Injection in lodash" || advisory.CWE[0] != "CWE-77" {
+		t.Errorf("Unexpected advisory: %+v", advisory)
+	}
+
+	// 两个版本的修复版本取较高的1.2.6，跨越0.x的主版本
+	minimist := report.Finding("minimist")
+	if minimist == nil || minimist.IsDirect || len(minimist.Advisories) != 1 ||
+		minimist.Fix != (AuditFix{Available: true, Name: "minimist", Version: "1.2.6", IsSemVerMajor: true}) {
+		t.Errorf("Unexpected minimist finding: %+v", minimist)
+	}
+
+	evil := report.Finding("evil")
+	if evil == nil || evil.Severity != SeverityCritical || evil.Fix.Available || evil.Advisories[0].ID() != "MAL-2024-1" {
+		t.Errorf("Unexpected evil finding: %+v", evil)
+	}
+
+	production, err := NewOSVScanner(osv.NewClient(server.URL)).Scan(context.Background(), AuditOptions{WorkingDir: dir, Production: true})
+	if err != nil {
+		t.Fatalf("Scan() failed: %v", err)
+	}
+	if production.Finding("evil") != nil || production.Total != 2 {
+		t.Errorf("Expected dev dependencies to be skipped, got %+v", production.Findings)
+	}
+}
+
+func TestOSVScannerRequiresLockfile(t *testing.T) {
+	if _, err := NewOSVScanner(nil).Scan(context.Background(), AuditOptions{WorkingDir: t.TempDir()}); err == nil {
+		t.Error("Expected error without lockfile")
+	}
+}
+
+func TestClientAuditWithOSV(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), osvTestLockfile)
+	server := newOSVTestServer(t, osvTestVulns, osvTestAffected)
+
+	// registry不支持审计接口时npm audit输出错误而不是报告
+	executor := &listExecutor{
+		result: &utils.ExecuteResult{ExitCode: 1, Stdout: `{"error":{"code":"ENOAUDIT","summary":"Your configured registry does not support audit requests."}}`},
+		err:    errors.New("exit status 1"),
+	}
+	client, err := NewClientWithExecutor("npm", executor, WithOSVAudit(NewOSVScanner(osv.NewClient(server.URL))))
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+
+	report, err := client.Audit(context.Background(), AuditOptions{WorkingDir: dir})
+	if err != nil {
+		t.Fatalf("Audit() failed: %v", err)
+	}
+	if report.Total != 3 || report.Finding("lodash") == nil {
+		t.Errorf("Expected OSV results, got %+v", report)
+	}
+}
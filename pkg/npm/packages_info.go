@@ -0,0 +1,97 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// DefaultPackagesInfoConcurrency GetPackagesInfo的默认并发数
+const DefaultPackagesInfoConcurrency = 8
+
+// PackagesInfoError GetPackagesInfo中部分包查询失败
+type PackagesInfoError struct {
+	Errors map[string]error // 包名到错误
+}
+
+// Error 实现error接口
+func (e *PackagesInfoError) Error() string {
+	names := e.Packages()
+	if len(names) == 1 {
+		return fmt.Sprintf("failed to get info for %s: %v", names[0], e.Errors[names[0]])
+	}
+	return fmt.Sprintf("failed to get info for %d packages: %s", len(names), strings.Join(names, ", "))
+}
+
+// Unwrap 返回各个包的错误，errors.Is可以判断是否包含网络错误等
+func (e *PackagesInfoError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, name := range e.Packages() {
+		errs = append(errs, e.Errors[name])
+	}
+	return errs
+}
+
+// Packages 返回查询失败的包名，按字典序排列
+func (e *PackagesInfoError) Packages() []string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetPackagesInfo 并发查询多个包的信息
+//
+// 最多同时运行concurrency个查询，小于等于0时使用DefaultPackagesInfoConcurrency。
+// 重复的包名只查询一次。每个查询与GetPackageInfo相同，同样使用客户端的缓存和重试策略。
+// 返回成功查询的结果，部分包失败时同时返回*PackagesInfoError；ctx取消后未开始的查询以ctx的错误失败。
+func (c *client) GetPackagesInfo(ctx context.Context, pkgs []string, concurrency int) (map[string]*PackageInfo, error) {
+	for _, pkg := range pkgs {
+		if pkg == "" {
+			return nil, NewValidationError("packages", strings.Join(pkgs, " "), "package name cannot be empty")
+		}
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultPackagesInfoConcurrency
+	}
+
+	var unique []string
+	seen := make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		if !seen[pkg] {
+			seen[pkg] = true
+			unique = append(unique, pkg)
+		}
+	}
+	results := make(map[string]*PackageInfo, len(unique))
+	infos := make([]*PackageInfo, len(unique))
+	tasks := make([]utils.Task, len(unique))
+	for i, pkg := range unique {
+		tasks[i] = utils.Task{
+			Name: pkg,
+			Run: func(ctx context.Context) (err error) {
+				infos[i], err = c.GetPackageInfo(ctx, pkg)
+				return err
+			},
+		}
+	}
+
+	failed := make(map[string]error)
+	for i, result := range utils.NewWorkerPool(concurrency).Run(ctx, tasks) {
+		if result.Err != nil {
+			failed[result.Name] = result.Err
+		} else {
+			results[result.Name] = infos[i]
+		}
+	}
+
+	if len(failed) > 0 {
+		return results, &PackagesInfoError{Errors: failed}
+	}
+	return results, nil
+}
@@ -0,0 +1,116 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// viewExecutor 模拟npm view，记录同时运行的查询数
+type viewExecutor struct {
+	delay   time.Duration
+	running int32
+	peak    int32
+	mu      sync.Mutex
+	calls   map[string]int
+}
+
+func (e *viewExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	pkg := options.Args[1]
+	e.mu.Lock()
+	e.calls[pkg]++
+	e.mu.Unlock()
+
+	running := atomic.AddInt32(&e.running, 1)
+	defer atomic.AddInt32(&e.running, -1)
+	for {
+		peak := atomic.LoadInt32(&e.peak)
+		if running <= peak || atomic.CompareAndSwapInt32(&e.peak, peak, running) {
+			break
+		}
+	}
+	time.Sleep(e.delay)
+
+	if strings.HasPrefix(pkg, "missing") {
+		return &utils.ExecuteResult{ExitCode: 1, Stderr: "npm error code E404\n"}, fmt.Errorf("command failed with exit code 1")
+	}
+	return &utils.ExecuteResult{Success: true, Stdout: fmt.Sprintf(`{"name": %q, "version": "1.0.0"}`, pkg)}, nil
+}
+
+func TestClientGetPackagesInfo(t *testing.T) {
+	executor := &viewExecutor{delay: 5 * time.Millisecond, calls: make(map[string]int)}
+	client, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+
+	var pkgs []string
+	for i := 0; i < 20; i++ {
+		pkgs = append(pkgs, fmt.Sprintf("pkg-%d", i))
+	}
+	pkgs = append(pkgs, "pkg-0", "missing-a", "missing-b")
+
+	results, err := client.GetPackagesInfo(context.Background(), pkgs, 3)
+	if len(results) != 20 || results["pkg-7"] == nil || results["pkg-7"].Name != "pkg-7" {
+		t.Errorf("Expected 20 successful results, got %d", len(results))
+	}
+	infoErr, ok := err.(*PackagesInfoError)
+	if !ok {
+		t.Fatalf("Expected *PackagesInfoError, got %v", err)
+	}
+	if names := infoErr.Packages(); len(names) != 2 || names[0] != "missing-a" || names[1] != "missing-b" {
+		t.Errorf("Unexpected failed packages: %v", names)
+	}
+	if !IsPackageNotFound(err) {
+		t.Error("Expected errors.Is to see the per-package errors")
+	}
+	if executor.peak > 3 {
+		t.Errorf("Expected at most 3 concurrent lookups, got %d", executor.peak)
+	}
+	if executor.calls["pkg-0"] != 1 {
+		t.Errorf("Expected duplicate package to be looked up once, got %d", executor.calls["pkg-0"])
+	}
+
+	if _, err := client.GetPackagesInfo(context.Background(), []string{"a", ""}, 2); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for empty package name, got %v", err)
+	}
+	if results, err := client.GetPackagesInfo(context.Background(), nil, 0); err != nil || len(results) != 0 {
+		t.Errorf("GetPackagesInfo(nil) = %v, %v", results, err)
+	}
+}
+
+func TestClientGetPackagesInfoCancelled(t *testing.T) {
+	executor := &viewExecutor{delay: 20 * time.Millisecond, calls: make(map[string]int)}
+	client, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+
+	var pkgs []string
+	for i := 0; i < 50; i++ {
+		pkgs = append(pkgs, fmt.Sprintf("pkg-%d", i))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	results, err := client.GetPackagesInfo(ctx, pkgs, 2)
+	infoErr, ok := err.(*PackagesInfoError)
+	if !ok {
+		t.Fatalf("Expected *PackagesInfoError, got %v", err)
+	}
+	if len(results)+len(infoErr.Errors) != len(pkgs) {
+		t.Errorf("Expected every package to have a result or an error, got %d + %d", len(results), len(infoErr.Errors))
+	}
+	if infoErr.Errors["pkg-49"] != context.DeadlineExceeded {
+		t.Errorf("Expected unscheduled packages to fail with the context error, got %v", infoErr.Errors["pkg-49"])
+	}
+	if len(executor.calls) >= len(pkgs) {
+		t.Error("Expected lookups to stop after cancellation")
+	}
+}
@@ -0,0 +1,330 @@
+package npm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// ParseListJSON 解析npm list --json的输出，返回按名称排序的顶层依赖
+//
+// 依赖缺失或无效时npm仍会输出依赖树并附带error字段，这里照常返回已解析的依赖，
+// 缺失的依赖Version为空、Missing为true。每个依赖的Children是下一层依赖，
+// 层数取决于npm list的--depth或--all。
+func ParseListJSON(data []byte) ([]Package, error) {
+	root, err := ParseListTree(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.Children == nil {
+		return []Package{}, nil
+	}
+	return root.Children, nil
+}
+
+// listNode npm list --json输出中的一个节点
+type listNode struct {
+	Name             string              `json:"name"`
+	Version          string              `json:"version"`
+	Resolved         string              `json:"resolved"`
+	Path             string              `json:"path"`
+	Extraneous       bool                `json:"extraneous"`
+	Missing          bool                `json:"missing"`
+	Invalid          json.RawMessage     `json:"invalid"`  // npm 7及以上是原因字符串，npm 6是true
+	Required         json.RawMessage     `json:"required"` // 缺失依赖的版本范围，npm 6的peer依赖是对象
+	PeerMissing      bool                `json:"peerMissing"`
+	PeerInvalid      bool                `json:"peerInvalid"`
+	PeerDependencies map[string]string   `json:"peerDependencies"`
+	Problems         []string            `json:"problems"`
+	Dependencies     map[string]listNode `json:"dependencies"`
+}
+
+// ParseListTree 解析npm list --json的输出，返回根项目及完整的依赖树
+//
+// 根项目的Problems汇总了整棵树的问题。依赖缺失或无效时npm以ELSPROBLEMS结束，
+// 这里照常返回依赖树；其他错误（例如package.json无法解析）返回error。
+func ParseListTree(data []byte) (*Package, error) {
+	var raw struct {
+		listNode
+		Error json.RawMessage `json:"error"`
+	}
+	if err := utils.DecodeJSON("npm list output", data, &raw); err != nil {
+		return nil, err
+	}
+	if output := parseJSONError(raw.Error); output != nil && output.Code != "ELSPROBLEMS" {
+		return nil, fmt.Errorf("npm list failed: %s: %s", output.Code, output.Summary)
+	}
+
+	root := Package{Name: raw.Name, Version: raw.Version, Path: raw.Path, Problems: raw.Problems}
+	root.Children = listChildren(raw.listNode)
+	return &root, nil
+}
+
+// listChildren 把节点的dependencies转换为按名称排序的Package
+func listChildren(node listNode) []Package {
+	if len(node.Dependencies) == 0 {
+		return nil
+	}
+
+	packages := make([]Package, 0, len(node.Dependencies))
+	for name, dep := range node.Dependencies {
+		pkg := Package{
+			Name:        name,
+			Version:     dep.Version,
+			Resolved:    dep.Resolved,
+			Path:        dep.Path,
+			Extraneous:  dep.Extraneous,
+			Missing:     dep.Missing || dep.PeerMissing,
+			InvalidPeer: dep.PeerMissing || dep.PeerInvalid,
+			Problems:    dep.Problems,
+			Children:    listChildren(dep),
+		}
+		if json.Unmarshal(dep.Invalid, &pkg.InvalidReason) != nil {
+			json.Unmarshal(dep.Invalid, &pkg.Invalid)
+		}
+		pkg.Invalid = pkg.Invalid || pkg.InvalidReason != "" || dep.PeerInvalid
+		json.Unmarshal(dep.Required, &pkg.Required)
+		// npm list --long输出父包的peerDependencies，可以区分不满足的是否为peer依赖
+		if _, ok := node.PeerDependencies[name]; ok && pkg.Invalid {
+			pkg.InvalidPeer = true
+		}
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages
+}
+
+// ParseOutdatedJSON 解析npm outdated --json的输出，返回按名称排序的过期依赖
+//
+// 同一个包在多个位置安装（例如workspaces）时npm输出数组，这里展开为多项并保持npm的顺序。
+func ParseOutdatedJSON(data []byte) ([]OutdatedPackage, error) {
+	var raw map[string]json.RawMessage
+	if err := utils.DecodeJSON("npm outdated output", data, &raw); err != nil {
+		return nil, err
+	}
+	if output := parseJSONError(raw["error"]); output != nil {
+		return nil, fmt.Errorf("npm outdated failed: %s: %s", output.Code, output.Summary)
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var packages []OutdatedPackage
+	for _, name := range names {
+		value := bytes.TrimSpace(raw[name])
+		var entries []OutdatedPackage
+		if len(value) > 0 && value[0] == '[' {
+			if err := json.Unmarshal(value, &entries); err != nil {
+				return nil, fmt.Errorf("failed to parse outdated entry %s: %w", name, err)
+			}
+		} else {
+			var entry OutdatedPackage
+			if err := json.Unmarshal(value, &entry); err != nil {
+				return nil, fmt.Errorf("failed to parse outdated entry %s: %w", name, err)
+			}
+			entries = []OutdatedPackage{entry}
+		}
+		for _, entry := range entries {
+			entry.Name = name
+			packages = append(packages, entry)
+		}
+	}
+	return packages, nil
+}
+
+// ParseSearchJSON 解析npm search --json的输出
+//
+// npm 7及以上输出包信息数组，更早的版本和registry搜索接口输出{package, score, searchScore}，
+// 两种格式都转换为SearchResult，前者没有评分信息。
+func ParseSearchJSON(data []byte) ([]SearchResult, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var raw struct {
+			Objects []SearchResult  `json:"objects"`
+			Error   json.RawMessage `json:"error"`
+		}
+		if err := utils.DecodeJSON("npm search output", data, &raw); err != nil {
+			return nil, err
+		}
+		if output := parseJSONError(raw.Error); output != nil {
+			return nil, fmt.Errorf("npm search failed: %s: %s", output.Code, output.Summary)
+		}
+		return raw.Objects, nil
+	}
+
+	var items []json.RawMessage
+	if err := utils.DecodeJSON("npm search output", data, &items); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(items))
+	for _, item := range items {
+		var probe struct {
+			Package json.RawMessage `json:"package"`
+		}
+		if err := json.Unmarshal(item, &probe); err != nil {
+			return nil, fmt.Errorf("failed to parse search results: %w", err)
+		}
+
+		var result SearchResult
+		if probe.Package != nil {
+			if err := json.Unmarshal(item, &result); err != nil {
+				return nil, fmt.Errorf("failed to parse search results: %w", err)
+			}
+		} else if err := json.Unmarshal(item, &result.Package); err != nil {
+			return nil, fmt.Errorf("failed to parse search results: %w", err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// errorLinePrefixes npm错误行的前缀，npm 10开始使用"npm error"
+var errorLinePrefixes = []string{"npm error", "npm ERR!"}
+
+// errorFieldPattern 匹配错误输出中的"code E404"等字段行
+var errorFieldPattern = regexp.MustCompile(`^(code|errno|syscall|path) (\S+)$`)
+
+// logFileMessage npm在错误输出末尾给出调试日志路径的说明
+const logFileMessage = "A complete log of this run can be found in:"
+
+// ParseErrorOutput 解析失败的npm命令的输出，没有可识别的错误信息时返回nil
+//
+// stderr中的"npm error"（npm 10及以上）和"npm ERR!"行都会被解析；使用--json时npm还会在
+// stdout输出error对象，此时摘要和详情以error对象为准，stderr补充其它字段。
+func ParseErrorOutput(stdout, stderr string) *ErrorOutput {
+	output := parseErrorLines(stderr)
+
+	var raw map[string]json.RawMessage
+	if json.Unmarshal([]byte(stdout), &raw) == nil {
+		if jsonOutput := parseJSONError(raw["error"]); jsonOutput != nil {
+			if output == nil {
+				output = &ErrorOutput{}
+			}
+			if jsonOutput.Code != "" {
+				output.Code = jsonOutput.Code
+			}
+			output.Summary = jsonOutput.Summary
+			output.Detail = jsonOutput.Detail
+		}
+	}
+
+	return output
+}
+
+// parseJSONError 解析--json输出中的error对象，不是错误对象时返回nil
+func parseJSONError(data json.RawMessage) *ErrorOutput {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var raw struct {
+		Code    string `json:"code"`
+		Summary string `json:"summary"`
+		Detail  string `json:"detail"`
+	}
+	if json.Unmarshal(data, &raw) != nil || (raw.Code == "" && raw.Summary == "" && raw.Detail == "") {
+		return nil
+	}
+	return &ErrorOutput{
+		Code:    raw.Code,
+		Summary: raw.Summary,
+		Detail:  raw.Detail,
+	}
+}
+
+// parseErrorLines 解析stderr中带npm错误前缀的行
+func parseErrorLines(stderr string) *ErrorOutput {
+	output := &ErrorOutput{}
+	var messages []string
+	found := false
+	expectLogFile := false
+
+	for _, line := range strings.Split(stderr, "\n") {
+		message, ok := trimErrorPrefix(strings.TrimRight(line, " \r"))
+		if !ok {
+			continue
+		}
+		found = true
+
+		if expectLogFile {
+			output.LogFile = strings.TrimSpace(message)
+			expectLogFile = false
+			continue
+		}
+		if rest, ok := strings.CutPrefix(message, logFileMessage); ok {
+			// npm 6在下一行给出路径
+			if output.LogFile = strings.TrimSpace(rest); output.LogFile == "" {
+				expectLogFile = true
+			}
+			continue
+		}
+
+		if match := errorFieldPattern.FindStringSubmatch(message); match != nil && output.setField(match[1], match[2]) {
+			continue
+		}
+
+		messages = append(messages, message)
+	}
+	if !found {
+		return nil
+	}
+
+	// 第一条非空消息作为摘要，其余作为详情
+	for len(messages) > 0 && strings.TrimSpace(messages[0]) == "" {
+		messages = messages[1:]
+	}
+	if len(messages) > 0 {
+		output.Summary = messages[0]
+		output.Detail = strings.Trim(strings.Join(messages[1:], "\n"), "\n")
+	}
+	return output
+}
+
+// setField 设置尚未设置的字段，字段已有值时返回false，
+// 这样详情中再次出现的同名行会作为普通消息保留
+func (o *ErrorOutput) setField(name, value string) bool {
+	var field *string
+	switch name {
+	case "code":
+		field = &o.Code
+	case "errno":
+		field = &o.Errno
+	case "syscall":
+		field = &o.Syscall
+	case "path":
+		field = &o.Path
+	default:
+		return false
+	}
+	if *field != "" {
+		return false
+	}
+	*field = value
+	return true
+}
+
+// trimErrorPrefix 去掉npm错误行前缀，不是错误行时返回false
+func trimErrorPrefix(line string) (string, bool) {
+	for _, prefix := range errorLinePrefixes {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			if rest == "" {
+				return "", true
+			}
+			if rest[0] == ' ' {
+				return rest[1:], true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,339 @@
+package npm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/fixtures"
+	"github.com/scagogogo/go-npm-sdk/pkg/parse"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// corpusResult 解析结果的golden格式
+type corpusResult struct {
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// parseSample 用样本类型对应的解析器解析样本
+func parseSample(t *testing.T, sample fixtures.Sample) corpusResult {
+	t.Helper()
+
+	var result interface{}
+	var err error
+	stdout := []byte(sample.Stdout)
+	switch sample.Kind {
+	case fixtures.KindList:
+		result, err = ParseListJSON(stdout)
+	case fixtures.KindAudit:
+		result, err = ParseAuditJSON(stdout)
+	case fixtures.KindOutdated:
+		result, err = ParseOutdatedJSON(stdout)
+	case fixtures.KindSearch:
+		result, err = ParseSearchJSON(stdout)
+	case fixtures.KindError:
+		output := ParseErrorOutput(sample.Stdout, sample.Stderr)
+		if output == nil {
+			t.Fatalf("Expected error output to be recognized")
+		}
+		result = output
+	default:
+		t.Fatalf("Unknown sample kind %q", sample.Kind)
+	}
+
+	if err != nil {
+		return corpusResult{Error: err.Error()}
+	}
+	return corpusResult{Result: result}
+}
+
+// TestParserCorpus 用fixtures语料库检查各解析器的输出，设置GO_NPM_SDK_UPDATE_GOLDEN=1时重新生成golden文件
+func TestParserCorpus(t *testing.T) {
+	samples, err := fixtures.Samples()
+	if err != nil {
+		t.Fatalf("Samples() failed: %v", err)
+	}
+	if len(samples) == 0 {
+		t.Fatal("Expected corpus samples")
+	}
+
+	update := os.Getenv("GO_NPM_SDK_UPDATE_GOLDEN") != ""
+	for _, sample := range samples {
+		t.Run(sample.ID(), func(t *testing.T) {
+			got, err := json.MarshalIndent(parseSample(t, sample), "", "  ")
+			if err != nil {
+				t.Fatalf("Failed to marshal result: %v", err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", "golden", filepath.FromSlash(sample.ID())+".json")
+			if update {
+				writeTestFile(t, golden, string(got))
+				return
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with GO_NPM_SDK_UPDATE_GOLDEN=1 to create it): %v", err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Errorf("Result differs from %s:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestParseErrorOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdout   string
+		stderr   string
+		expected *ErrorOutput
+	}{
+		{
+			name:     "no error lines",
+			stderr:   "npm warn deprecated left-pad@1.3.0\n",
+			expected: nil,
+		},
+		{
+			name:   "npm 10",
+			stderr: "npm notice\nnpm error code ENOENT\nnpm error syscall open\nnpm error path /tmp/package.json\nnpm error errno -2\nnpm error enoent Could not read package.json\nnpm error enoent This is related to npm not being able to find a file.\nnpm error A complete log of this run can be found in: /tmp/debug.log\n",
+			expected: &ErrorOutput{
+				Code:    "ENOENT",
+				Errno:   "-2",
+				Syscall: "open",
+				Path:    "/tmp/package.json",
+				Summary: "enoent Could not read package.json",
+				Detail:  "enoent This is related to npm not being able to find a file.",
+				LogFile: "/tmp/debug.log",
+			},
+		},
+		{
+			name:   "npm 6 log file on next line",
+			stderr: "npm ERR! code E401\nnpm ERR! Unable to authenticate\n\nnpm ERR! A complete log of this run can be found in:\nnpm ERR!     /home/user/.npm/_logs/debug.log\n",
+			expected: &ErrorOutput{
+				Code:    "E401",
+				Summary: "Unable to authenticate",
+				LogFile: "/home/user/.npm/_logs/debug.log",
+			},
+		},
+		{
+			name:   "json error object",
+			stdout: `{"error": {"code": "E404", "summary": "Not Found", "detail": "not in this registry"}}`,
+			stderr: "npm error code E404\nnpm error 404 Not Found\n",
+			expected: &ErrorOutput{
+				Code:    "E404",
+				Summary: "Not Found",
+				Detail:  "not in this registry",
+			},
+		},
+		{
+			name:     "json error object only",
+			stdout:   `{"error": {"code": "ENOLOCK", "summary": "This command requires an existing lockfile.", "detail": ""}}`,
+			expected: &ErrorOutput{Code: "ENOLOCK", Summary: "This command requires an existing lockfile."},
+		},
+		{
+			name:     "repeated field line is kept as message",
+			stderr:   "npm error code E1\nnpm error code E2\n",
+			expected: &ErrorOutput{Code: "E1", Summary: "code E2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseErrorOutput(tt.stdout, tt.stderr)
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("ParseErrorOutput() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseOutdatedJSON(t *testing.T) {
+	// 名为error的包不是错误对象
+	packages, err := ParseOutdatedJSON([]byte(`{"error": {"current": "1.0.0", "wanted": "1.0.1", "latest": "2.0.0"}}`))
+	if err != nil {
+		t.Fatalf("ParseOutdatedJSON() failed: %v", err)
+	}
+	if len(packages) != 1 || packages[0].Name != "error" || packages[0].Latest != "2.0.0" {
+		t.Errorf("Unexpected packages: %+v", packages)
+	}
+
+	_, err = ParseOutdatedJSON([]byte(`{"error": {"code": "ENOTCACHED", "summary": "request failed", "detail": ""}}`))
+	if err == nil || !strings.Contains(err.Error(), "ENOTCACHED") {
+		t.Errorf("Expected ENOTCACHED error, got %v", err)
+	}
+
+	if _, err := ParseOutdatedJSON([]byte(`{"left-pad": 1}`)); err == nil {
+		t.Error("Expected error for invalid entry")
+	}
+	if _, err := ParseOutdatedJSON([]byte(`not json`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestParseSearchJSON(t *testing.T) {
+	// registry搜索接口格式
+	results, err := ParseSearchJSON([]byte(`{"objects": [{"package": {"name": "left-pad", "version": "1.3.0"}, "score": {"final": 0.5}, "searchScore": 1.5}], "total": 1}`))
+	if err != nil {
+		t.Fatalf("ParseSearchJSON() failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Package.Name != "left-pad" || results[0].Score.Final != 0.5 || results[0].SearchScore != 1.5 {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	// npm 6的数组格式
+	results, err = ParseSearchJSON([]byte(`[{"package": {"name": "left-pad"}, "searchScore": 2}]`))
+	if err != nil || len(results) != 1 || results[0].Package.Name != "left-pad" || results[0].SearchScore != 2 {
+		t.Errorf("Unexpected results: %+v, %v", results, err)
+	}
+
+	if _, err := ParseSearchJSON([]byte(`{"error": {"code": "ENOTCACHED", "summary": "request failed"}}`)); err == nil {
+		t.Error("Expected error for error object")
+	}
+	if _, err := ParseSearchJSON([]byte(`[1]`)); err == nil {
+		t.Error("Expected error for invalid item")
+	}
+}
+
+// addCorpusSeeds 把语料库中指定类型样本的stdout加入fuzz种子
+func addCorpusSeeds(f *testing.F, kind fixtures.Kind) {
+	samples, err := fixtures.Samples(kind)
+	if err != nil {
+		f.Fatalf("Samples() failed: %v", err)
+	}
+	for _, sample := range samples {
+		f.Add([]byte(sample.Stdout))
+	}
+}
+
+// checkParseError 检查解析错误的位置信息是否在输入范围内
+func checkParseError(t *testing.T, data []byte, err error) {
+	t.Helper()
+
+	var parseErr *utils.ParseError
+	if errors.As(err, &parseErr) && (parseErr.Offset < 0 || parseErr.Offset > int64(len(data)) || parseErr.Line < 1 || parseErr.Column < 1) {
+		t.Errorf("Invalid error position for %q: %+v", data, parseErr)
+	}
+}
+
+func FuzzParseListJSON(f *testing.F) {
+	addCorpusSeeds(f, fixtures.KindList)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if _, err := ParseListJSON(data); err != nil {
+			checkParseError(t, data, err)
+		}
+	})
+}
+
+func FuzzParseAuditJSON(f *testing.F) {
+	addCorpusSeeds(f, fixtures.KindAudit)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if _, err := ParseAuditJSON(data); err != nil {
+			checkParseError(t, data, err)
+		}
+	})
+}
+
+func FuzzParseOutdatedJSON(f *testing.F) {
+	addCorpusSeeds(f, fixtures.KindOutdated)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if _, err := ParseOutdatedJSON(data); err != nil {
+			checkParseError(t, data, err)
+		}
+	})
+}
+
+func FuzzParseSearchJSON(f *testing.F) {
+	addCorpusSeeds(f, fixtures.KindSearch)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if _, err := ParseSearchJSON(data); err != nil {
+			checkParseError(t, data, err)
+		}
+	})
+}
+
+func FuzzParseErrorOutput(f *testing.F) {
+	samples, err := fixtures.Samples(fixtures.KindError)
+	if err != nil {
+		f.Fatalf("Samples() failed: %v", err)
+	}
+	for _, sample := range samples {
+		f.Add(sample.Stdout, sample.Stderr)
+	}
+	f.Fuzz(func(t *testing.T, stdout, stderr string) {
+		output := ParseErrorOutput(stdout, stderr)
+		if output != nil && len(output.Summary)+len(output.Detail) > len(stdout)+len(stderr) {
+			t.Errorf("Output is longer than the input: %+v", output)
+		}
+	})
+}
+
+func TestParseListTree(t *testing.T) {
+	// npm 6的peer依赖和npm list --long输出的peerDependencies都标记为InvalidPeer
+	root, err := ParseListTree([]byte(`{
+  "name": "app",
+  "version": "1.0.0",
+  "path": "/app",
+  "dependencies": {
+    "react-dom": {
+      "version": "18.2.0",
+      "path": "/app/node_modules/react-dom",
+      "peerDependencies": {"react": "^18.2.0"},
+      "dependencies": {
+        "react": {"version": "17.0.2", "invalid": "\"^18.2.0\" from node_modules/react-dom"}
+      }
+    },
+    "old-plugin": {"required": {"version": "^2.0.0"}, "peerMissing": true},
+    "legacy": {"version": "1.0.0", "invalid": true}
+  }
+}`))
+	if err != nil {
+		t.Fatalf("ParseListTree() failed: %v", err)
+	}
+	if root.Name != "app" || root.Path != "/app" || len(root.Children) != 3 {
+		t.Fatalf("Unexpected root: %+v", root)
+	}
+
+	legacy, plugin, dom := root.Children[0], root.Children[1], root.Children[2]
+	if !legacy.Invalid || legacy.InvalidPeer || legacy.InvalidReason != "" {
+		t.Errorf("Unexpected legacy: %+v", legacy)
+	}
+	if !plugin.Missing || !plugin.InvalidPeer || plugin.Required != "" {
+		t.Errorf("Unexpected old-plugin: %+v", plugin)
+	}
+	if dom.Path != "/app/node_modules/react-dom" || len(dom.Children) != 1 {
+		t.Fatalf("Unexpected react-dom: %+v", dom)
+	}
+	if react := dom.Children[0]; !react.Invalid || !react.InvalidPeer || react.InvalidReason != `"^18.2.0" from node_modules/react-dom` {
+		t.Errorf("Unexpected react: %+v", react)
+	}
+}
+
+func TestParsersReportPosition(t *testing.T) {
+	_, err := ParseListJSON([]byte("{\n  \"dependencies\": {\n    \"a\": {\"version\": 1}\n  }\n}"))
+	var parseErr *utils.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *utils.ParseError, got %v", err)
+	}
+	if parseErr.Source != "npm list output" || parseErr.Line != 3 || parseErr.Column != 22 {
+		t.Errorf("Unexpected error position: %+v", parseErr)
+	}
+
+	limits := parse.DefaultLimits
+	defer func() { parse.DefaultLimits = limits }()
+	parse.DefaultLimits = parse.Limits{MaxSize: 1024, MaxDepth: 4}
+
+	deep := []byte(`{"vulnerabilities": {"a": {"via": [[{}]]}}}`)
+	if _, err := ParseAuditJSON(deep); !errors.Is(err, utils.ErrNestingTooDeep) {
+		t.Errorf("Expected ErrNestingTooDeep, got %v", err)
+	}
+	if _, err := ParseSearchJSON(bytes.Repeat([]byte(" "), 1025)); !errors.Is(err, utils.ErrInputTooLarge) {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// Pnpm pnpm命令封装
+//
+// pnpm把所有包保存在一个内容寻址的共享store中，项目node_modules/.pnpm中的文件
+// 是指向store的硬链接。CI节点需要定期清理store并确认链接没有退化为复制。
+type Pnpm struct {
+	pnpmPath   string
+	workingDir string
+	executor   *utils.Executor
+}
+
+// PnpmStorePruneResult store清理结果
+type PnpmStorePruneResult struct {
+	StorePath string `json:"store_path"`
+	Output    string `json:"output"`
+}
+
+// HardlinkReport 硬链接检查结果
+type HardlinkReport struct {
+	Supported  bool     `json:"supported"`             // 当前平台是否支持读取链接数
+	Checked    int      `json:"checked"`               // 检查的文件数
+	Linked     int      `json:"linked"`                // 链接数大于1的文件数
+	NotLinked  []string `json:"not_linked,omitempty"`  // 未链接的文件（最多记录100个）
+	MaxReached bool     `json:"max_reached,omitempty"` // 未链接文件超过记录上限
+}
+
+// maxReportedFiles 硬链接检查最多记录的未链接文件数
+const maxReportedFiles = 100
+
+// NewPnpm 创建pnpm命令封装
+func NewPnpm(workingDir string) *Pnpm {
+	return &Pnpm{
+		pnpmPath:   "pnpm",
+		workingDir: workingDir,
+		executor:   utils.NewExecutor(),
+	}
+}
+
+// SetPnpmPath 设置pnpm可执行文件路径
+func (p *Pnpm) SetPnpmPath(path string) {
+	p.pnpmPath = path
+}
+
+// IsAvailable 检查pnpm是否可用
+func (p *Pnpm) IsAvailable(ctx context.Context) bool {
+	_, err := p.Version(ctx)
+	return err == nil
+}
+
+// Version 获取pnpm版本
+func (p *Pnpm) Version(ctx context.Context) (string, error) {
+	result, err := p.run(ctx, 30*time.Second, "--version")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+// StorePath 获取当前项目使用的store路径
+func (p *Pnpm) StorePath(ctx context.Context) (string, error) {
+	result, err := p.run(ctx, 30*time.Second, "store", "path")
+	if err != nil {
+		return "", err
+	}
+
+	path := strings.TrimSpace(result.Stdout)
+	if path == "" {
+		return "", fmt.Errorf("pnpm store path returned empty output")
+	}
+	return path, nil
+}
+
+// StorePrune 从store中删除未被任何项目引用的包
+func (p *Pnpm) StorePrune(ctx context.Context) (*PnpmStorePruneResult, error) {
+	storePath, err := p.StorePath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := p.run(ctx, 30*time.Minute, "store", "prune")
+	if err != nil {
+		return nil, err
+	}
+
+	return &PnpmStorePruneResult{
+		StorePath: storePath,
+		Output:    strings.TrimSpace(result.Stdout),
+	}, nil
+}
+
+// StoreStatus 检查store中被修改过的包，store完好时返回nil
+func (p *Pnpm) StoreStatus(ctx context.Context) error {
+	_, err := p.run(ctx, 10*time.Minute, "store", "status")
+	return err
+}
+
+// Import 根据package-lock.json等其他锁文件生成pnpm-lock.yaml
+func (p *Pnpm) Import(ctx context.Context) error {
+	_, err := p.run(ctx, 10*time.Minute, "import")
+	return err
+}
+
+// VerifyHardlinks 检查node_modules/.pnpm中的文件是否为指向store的硬链接
+//
+// 使用copy导入方式或store与项目不在同一文件系统时，文件会被复制，
+// 既浪费磁盘空间也说明store配置有问题。
+func (p *Pnpm) VerifyHardlinks() (*HardlinkReport, error) {
+	return VerifyPnpmHardlinks(p.workingDir)
+}
+
+// VerifyPnpmHardlinks 检查指定项目node_modules/.pnpm中文件的硬链接情况
+func VerifyPnpmHardlinks(projectDir string) (*HardlinkReport, error) {
+	virtualStore := filepath.Join(projectDir, "node_modules", ".pnpm")
+	if stat, err := os.Stat(virtualStore); err != nil || !stat.IsDir() {
+		return nil, fmt.Errorf("pnpm virtual store not found: %s", virtualStore)
+	}
+
+	report := &HardlinkReport{Supported: true}
+	err := filepath.WalkDir(virtualStore, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// 跳过符号链接和目录，只检查普通文件
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		count, ok := linkCount(info)
+		if !ok {
+			report.Supported = false
+			return filepath.SkipAll
+		}
+
+		report.Checked++
+		if count > 1 {
+			report.Linked++
+		} else if len(report.NotLinked) < maxReportedFiles {
+			rel, _ := filepath.Rel(projectDir, path)
+			report.NotLinked = append(report.NotLinked, rel)
+		} else {
+			report.MaxReached = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk pnpm virtual store: %w", err)
+	}
+
+	return report, nil
+}
+
+// run 执行pnpm命令
+func (p *Pnpm) run(ctx context.Context, timeout time.Duration, args ...string) (*utils.ExecuteResult, error) {
+	executeOptions := utils.ExecuteOptions{
+		Command:       p.pnpmPath,
+		Args:          args,
+		WorkingDir:    p.workingDir,
+		CaptureOutput: true,
+		Timeout:       timeout,
+	}
+
+	result, err := p.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("pnpm %s failed: %w", strings.Join(args, " "), err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package npm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestNewPnpm(t *testing.T) {
+	pnpm := NewPnpm("/tmp/project")
+	if pnpm == nil {
+		t.Fatal("NewPnpm() returned nil")
+	}
+
+	if pnpm.pnpmPath != "pnpm" {
+		t.Errorf("Expected default pnpm path 'pnpm', got '%s'", pnpm.pnpmPath)
+	}
+
+	pnpm.SetPnpmPath("/usr/local/bin/pnpm")
+	if pnpm.pnpmPath != "/usr/local/bin/pnpm" {
+		t.Errorf("Expected pnpm path to be updated, got '%s'", pnpm.pnpmPath)
+	}
+}
+
+func TestPnpmUnavailable(t *testing.T) {
+	pnpm := NewPnpm(t.TempDir())
+	pnpm.SetPnpmPath("non-existent-pnpm-binary")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if pnpm.IsAvailable(ctx) {
+		t.Error("Expected pnpm to be unavailable")
+	}
+	if _, err := pnpm.StorePath(ctx); err == nil {
+		t.Error("Expected StorePath() to fail when pnpm is missing")
+	}
+	if _, err := pnpm.StorePrune(ctx); err == nil {
+		t.Error("Expected StorePrune() to fail when pnpm is missing")
+	}
+	if err := pnpm.StoreStatus(ctx); err == nil {
+		t.Error("Expected StoreStatus() to fail when pnpm is missing")
+	}
+}
+
+func TestVerifyPnpmHardlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("link counts are not available on Windows")
+	}
+
+	project := t.TempDir()
+	store := t.TempDir()
+	pkgDir := filepath.Join(project, "node_modules", ".pnpm", "lodash@4.17.21", "node_modules", "lodash")
+
+	storeFile := filepath.Join(store, "lodash-index.js")
+	writeTestFile(t, storeFile, "module.exports = {};")
+	writeTestFile(t, filepath.Join(pkgDir, "package.json"), `{"name":"lodash"}`)
+	if err := os.Link(storeFile, filepath.Join(pkgDir, "index.js")); err != nil {
+		t.Skipf("hardlinks not supported in temp dir: %v", err)
+	}
+	// 符号链接不应计入检查
+	os.Symlink(pkgDir, filepath.Join(project, "node_modules", "lodash"))
+
+	report, err := NewPnpm(project).VerifyHardlinks()
+	if err != nil {
+		t.Fatalf("VerifyHardlinks() failed: %v", err)
+	}
+
+	if !report.Supported {
+		t.Fatal("Expected link counts to be supported")
+	}
+	if report.Checked != 2 {
+		t.Errorf("Expected 2 checked files, got %d", report.Checked)
+	}
+	if report.Linked != 1 {
+		t.Errorf("Expected 1 linked file, got %d", report.Linked)
+	}
+	if len(report.NotLinked) != 1 || filepath.Base(report.NotLinked[0]) != "package.json" {
+		t.Errorf("Expected package.json to be reported as not linked, got %v", report.NotLinked)
+	}
+}
+
+func TestVerifyPnpmHardlinksMissingStore(t *testing.T) {
+	if _, err := VerifyPnpmHardlinks(t.TempDir()); err == nil {
+		t.Error("Expected error when node_modules/.pnpm does not exist")
+	}
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/scagogogo/go-npm-sdk/pkg/platform"
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
 )
 
 // PortableManager 便携版管理器
@@ -18,6 +23,7 @@ type PortableManager struct {
 	downloader   *platform.NodeJSDownloader
 	platformInfo *platform.Info
 	baseDir      string
+	logger       *slog.Logger
 }
 
 // PortableConfig 便携版配置
@@ -29,6 +35,9 @@ type PortableConfig struct {
 	InstallDate string `json:"install_date"`
 }
 
+// NodeRelease Node.js发布索引中的一个版本，参见platform.NodeRelease
+type NodeRelease = platform.NodeRelease
+
 // NewPortableManager 创建便携版管理器
 func NewPortableManager(baseDir string) (*PortableManager, error) {
 	detector := platform.NewDetector()
@@ -39,11 +48,9 @@ func NewPortableManager(baseDir string) (*PortableManager, error) {
 
 	if baseDir == "" {
 		// 使用默认目录
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		if baseDir, err = defaultPortableDir(); err != nil {
+			return nil, err
 		}
-		baseDir = filepath.Join(homeDir, ".go-npm-sdk", "portable")
 	}
 
 	return &PortableManager{
@@ -53,11 +60,38 @@ func NewPortableManager(baseDir string) (*PortableManager, error) {
 	}, nil
 }
 
-// Install 安装便携版Node.js/npm
-func (pm *PortableManager) Install(ctx context.Context, version string, progress func(string)) (*PortableConfig, error) {
-	if progress != nil {
-		progress("开始安装便携版Node.js...")
+// defaultPortableDir 便携版的默认安装目录
+func defaultPortableDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
 	}
+	return filepath.Join(homeDir, ".go-npm-sdk", "portable"), nil
+}
+
+// SetLogger 设置日志记录器，解压等命令以debug级别记录，创建的客户端也使用该记录器，nil表示不记录
+func (pm *PortableManager) SetLogger(logger *slog.Logger) {
+	pm.logger = logger
+}
+
+// SetSignatureVerifier 设置安装时SHASUMS256.txt的签名校验，nil表示只校验哈希
+func (pm *PortableManager) SetSignatureVerifier(verifier platform.SignatureVerifier) {
+	pm.downloader.SetSignatureVerifier(verifier)
+}
+
+// Install 安装便携版Node.js/npm，progress接收文字描述的进度，可以为nil
+//
+// 需要结构化的进度事件时使用InstallWithEvents。
+func (pm *PortableManager) Install(ctx context.Context, version string, progress func(string)) (*PortableConfig, error) {
+	return pm.InstallWithEvents(ctx, version, progressMessages(progress))
+}
+
+// InstallWithEvents 安装便携版Node.js/npm，安装过程中向handler发送进度事件，handler可以为nil
+func (pm *PortableManager) InstallWithEvents(ctx context.Context, version string, handler platform.EventHandler) (*PortableConfig, error) {
+	platform.EmitProgress(handler, platform.ProgressEvent{
+		Stage:   platform.StageStarting,
+		Message: "开始安装便携版Node.js...",
+	})
 
 	// 获取版本
 	if version == "" {
@@ -71,9 +105,11 @@ func (pm *PortableManager) Install(ctx context.Context, version string, progress
 	// 检查是否已安装
 	installPath := filepath.Join(pm.baseDir, fmt.Sprintf("node-v%s", version))
 	if config, err := pm.LoadConfig(installPath); err == nil {
-		if progress != nil {
-			progress(fmt.Sprintf("版本 %s 已安装", version))
-		}
+		platform.EmitProgress(handler, platform.ProgressEvent{
+			Stage:   platform.StageCompleted,
+			Percent: 100,
+			Message: fmt.Sprintf("版本 %s 已安装", version),
+		})
 		return config, nil
 	}
 
@@ -83,16 +119,11 @@ func (pm *PortableManager) Install(ctx context.Context, version string, progress
 	}
 
 	// 下载Node.js
-	if progress != nil {
-		progress("正在下载Node.js...")
-	}
-
-	downloadProgress := func(downloaded, total int64) {
-		if progress != nil {
-			percent := float64(downloaded) / float64(total) * 100
-			progress(fmt.Sprintf("下载进度: %.1f%%", percent))
-		}
-	}
+	platform.EmitProgress(handler, platform.ProgressEvent{
+		Stage:   platform.StageDownloading,
+		Percent: -1,
+		Message: "正在下载Node.js...",
+	})
 
 	tempDir, err := os.MkdirTemp("", "nodejs-portable-")
 	if err != nil {
@@ -100,16 +131,29 @@ func (pm *PortableManager) Install(ctx context.Context, version string, progress
 	}
 	defer os.RemoveAll(tempDir)
 
-	result, err := pm.downloader.DownloadNodeJS(ctx, version, pm.platformInfo, tempDir, downloadProgress)
+	result, err := pm.downloader.DownloadNodeJSWithEvents(ctx, version, pm.platformInfo, tempDir, handler)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download Node.js: %w", err)
 	}
 
-	// 解压
-	if progress != nil {
-		progress("正在解压...")
+	// 解压前用SHASUMS256.txt校验，损坏或被篡改的压缩包返回*ChecksumError
+	platform.EmitProgress(handler, platform.ProgressEvent{
+		Stage:   platform.StageVerifying,
+		Percent: -1,
+		Message: "正在校验...",
+	})
+
+	if err := pm.downloader.VerifyNodeJS(ctx, version, result.FilePath); err != nil {
+		return nil, fmt.Errorf("failed to verify Node.js download: %w", err)
 	}
 
+	// 解压
+	platform.EmitProgress(handler, platform.ProgressEvent{
+		Stage:   platform.StageExtracting,
+		Percent: -1,
+		Message: "正在解压...",
+	})
+
 	if err := pm.extractArchive(result.FilePath, installPath); err != nil {
 		return nil, fmt.Errorf("failed to extract archive: %w", err)
 	}
@@ -128,9 +172,11 @@ func (pm *PortableManager) Install(ctx context.Context, version string, progress
 		return nil, fmt.Errorf("failed to save config: %w", err)
 	}
 
-	if progress != nil {
-		progress(fmt.Sprintf("便携版Node.js v%s 安装完成", version))
-	}
+	platform.EmitProgress(handler, platform.ProgressEvent{
+		Stage:   platform.StageCompleted,
+		Percent: 100,
+		Message: fmt.Sprintf("便携版Node.js v%s 安装完成", version),
+	})
 
 	return config, nil
 }
@@ -249,7 +295,10 @@ func (pm *PortableManager) extractTarXz(archivePath, destPath string) error {
 // runCommand 运行命令
 func (pm *PortableManager) runCommand(name string, args ...string) error {
 	cmd := exec.Command(name, args...)
-	return cmd.Run()
+	start := time.Now()
+	err := cmd.Run()
+	utils.LogCommand(context.Background(), pm.logger, cmd, start, err)
+	return err
 }
 
 // getNodePath 获取Node.js可执行文件路径
@@ -262,6 +311,11 @@ func (pm *PortableManager) getNodePath(installPath string) string {
 
 // getNpmPath 获取npm可执行文件路径
 func (pm *PortableManager) getNpmPath(installPath string) string {
+	return portableNpmPath(installPath)
+}
+
+// portableNpmPath 便携版安装目录中的npm可执行文件路径
+func portableNpmPath(installPath string) string {
 	if runtime.GOOS == "windows" {
 		return filepath.Join(installPath, "npm.cmd")
 	}
@@ -276,7 +330,14 @@ func (pm *PortableManager) CreateClient(version string) (Client, error) {
 	}
 
 	// 创建使用便携版npm的客户端
-	return NewClientWithPath(config.NpmPath)
+	client, err := NewClientWithPath(config.NpmPath)
+	if err != nil {
+		return nil, err
+	}
+	if pm.logger != nil {
+		client.SetLogger(pm.logger)
+	}
+	return client, nil
 }
 
 // SetAsDefault 将指定版本设置为默认版本
@@ -313,3 +374,89 @@ func (pm *PortableManager) IsVersionInstalled(version string) bool {
 	_, err := os.Stat(installPath)
 	return err == nil
 }
+
+// ListRemote 列出Node.js发布索引（index.json）中可以安装的版本，按版本从新到旧排列
+//
+// filter由逗号分隔的条件组成，版本需要满足所有条件，为空时返回所有版本：
+//   - "lts"：LTS版本；"lts/<代号>"：指定代号的LTS版本，例如lts/hydrogen
+//   - "latest"：每个主版本只保留最新的一个，在其他条件之后应用
+//   - 其他值按版本范围匹配，例如">=18"或"20.x"
+//
+// 例如"lts,>=18,latest"返回18及以上每个LTS主版本的最新版本。
+func (pm *PortableManager) ListRemote(ctx context.Context, filter string) ([]NodeRelease, error) {
+	releases, err := pm.downloader.GetReleases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Node.js releases: %w", err)
+	}
+	return filterReleases(releases, filter)
+}
+
+// filterReleases 按ListRemote的filter筛选版本，无法解析的版本号被忽略
+func filterReleases(releases []NodeRelease, filter string) ([]NodeRelease, error) {
+	var predicates []func(release *NodeRelease, version *semver.Version) bool
+	latest := false
+	for _, term := range strings.Split(filter, ",") {
+		term = strings.TrimSpace(term)
+		lower := strings.ToLower(term)
+		switch {
+		case lower == "" || lower == "all":
+		case lower == "latest":
+			latest = true
+		case lower == "lts":
+			predicates = append(predicates, func(release *NodeRelease, _ *semver.Version) bool {
+				return release.IsLTS()
+			})
+		case strings.HasPrefix(lower, "lts/"):
+			codename := term[len("lts/"):]
+			predicates = append(predicates, func(release *NodeRelease, _ *semver.Version) bool {
+				return strings.EqualFold(release.LTS, codename)
+			})
+		default:
+			rng, err := semver.ParseRange(term)
+			if err != nil {
+				return nil, NewValidationError("filter", term, "invalid version range")
+			}
+			predicates = append(predicates, func(_ *NodeRelease, version *semver.Version) bool {
+				return rng.Contains(version)
+			})
+		}
+	}
+
+	type parsedRelease struct {
+		release NodeRelease
+		version *semver.Version
+	}
+	var matched []parsedRelease
+	for _, release := range releases {
+		version, err := semver.Parse(release.Version)
+		if err != nil {
+			continue
+		}
+		ok := true
+		for _, predicate := range predicates {
+			if !predicate(&release, version) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, parsedRelease{release: release, version: version})
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[j].version.LessThan(matched[i].version)
+	})
+
+	result := make([]NodeRelease, 0, len(matched))
+	seen := make(map[int]bool)
+	for _, item := range matched {
+		if latest {
+			if seen[item.version.Major] {
+				continue
+			}
+			seen[item.version.Major] = true
+		}
+		result = append(result, item.release)
+	}
+	return result, nil
+}
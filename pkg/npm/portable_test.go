@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/platform"
 )
 
 func TestNewPortableManager(t *testing.T) {
@@ -413,6 +415,9 @@ func (m *MockNpmClient) UninstallPackage(ctx context.Context, pkg string, option
 	return nil
 }
 func (m *MockNpmClient) UpdatePackage(ctx context.Context, pkg string) error { return nil }
+func (m *MockNpmClient) UpdatePackageWithOptions(ctx context.Context, pkg string, options UpdateOptions) error {
+	return nil
+}
 func (m *MockNpmClient) ListPackages(ctx context.Context, options ListOptions) ([]Package, error) {
 	return []Package{}, nil
 }
@@ -472,3 +477,101 @@ func TestPortableManagerCreateClient(t *testing.T) {
 		t.Error("Expected non-nil client")
 	}
 }
+
+func TestPortableManagerInstallProgressEvents(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewPortableManager(tempDir)
+	if err != nil {
+		t.Fatalf("NewPortableManager() failed: %v", err)
+	}
+
+	installPath := filepath.Join(tempDir, "node-v18.17.0")
+	if err := os.MkdirAll(installPath, 0755); err != nil {
+		t.Fatalf("Failed to create install directory: %v", err)
+	}
+	if err := manager.SaveConfig(&PortableConfig{Version: "18.17.0", InstallPath: installPath}); err != nil {
+		t.Fatalf("SaveConfig() failed: %v", err)
+	}
+
+	var stages []platform.ProgressStage
+	handler := platform.EventHandlerFunc(func(event platform.ProgressEvent) {
+		stages = append(stages, event.Stage)
+		if event.Message == "" {
+			t.Errorf("Expected message for stage %s", event.Stage)
+		}
+	})
+
+	// 已安装的版本直接完成，不会下载
+	config, err := manager.InstallWithEvents(context.Background(), "18.17.0", handler)
+	if err != nil {
+		t.Fatalf("InstallWithEvents() failed: %v", err)
+	}
+	if config.Version != "18.17.0" {
+		t.Errorf("Expected version 18.17.0, got %s", config.Version)
+	}
+
+	expected := []platform.ProgressStage{platform.StageStarting, platform.StageCompleted}
+	if fmt.Sprint(stages) != fmt.Sprint(expected) {
+		t.Errorf("Expected stages %v, got %v", expected, stages)
+	}
+
+	// handler可以为nil
+	if _, err := manager.InstallWithEvents(context.Background(), "18.17.0", nil); err != nil {
+		t.Fatalf("InstallWithEvents() with nil handler failed: %v", err)
+	}
+
+	// 文字进度回调收到各阶段的描述
+	var messages []string
+	if _, err := manager.Install(context.Background(), "18.17.0", func(message string) { messages = append(messages, message) }); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if strings.Join(messages, "|") != "开始安装便携版Node.js...|版本 18.17.0 已安装" {
+		t.Errorf("Unexpected progress messages: %q", messages)
+	}
+	if _, err := manager.Install(context.Background(), "18.17.0", nil); err != nil {
+		t.Fatalf("Install() with nil progress failed: %v", err)
+	}
+}
+
+func TestFilterReleases(t *testing.T) {
+	releases := []NodeRelease{
+		{Version: "22.9.0"},
+		{Version: "20.17.0", LTS: "Iron"},
+		{Version: "21.7.3"},
+		{Version: "20.16.0", LTS: "Iron"},
+		{Version: "18.20.4", LTS: "Hydrogen"},
+		{Version: "18.20.3", LTS: "Hydrogen"},
+		{Version: "16.20.2", LTS: "Gallium"},
+		{Version: "not-a-version"},
+	}
+	tests := []struct {
+		filter   string
+		expected []string
+	}{
+		{"", []string{"22.9.0", "21.7.3", "20.17.0", "20.16.0", "18.20.4", "18.20.3", "16.20.2"}},
+		{"lts", []string{"20.17.0", "20.16.0", "18.20.4", "18.20.3", "16.20.2"}},
+		{"lts/hydrogen", []string{"18.20.4", "18.20.3"}},
+		{">=20", []string{"22.9.0", "21.7.3", "20.17.0", "20.16.0"}},
+		{"latest", []string{"22.9.0", "21.7.3", "20.17.0", "18.20.4", "16.20.2"}},
+		{"lts, >=18, latest", []string{"20.17.0", "18.20.4"}},
+		{"lts/argon", []string{}},
+	}
+	for _, tt := range tests {
+		result, err := filterReleases(releases, tt.filter)
+		if err != nil {
+			t.Fatalf("filterReleases(%q) failed: %v", tt.filter, err)
+		}
+		versions := make([]string, 0, len(result))
+		for _, release := range result {
+			versions = append(versions, release.Version)
+		}
+		if strings.Join(versions, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("filterReleases(%q) = %v, expected %v", tt.filter, versions, tt.expected)
+		}
+	}
+
+	var validationErr *ValidationError
+	if _, err := filterReleases(releases, "lts,>=>18"); !IsValidationError(err, &validationErr) {
+		t.Errorf("Expected ValidationError for invalid range, got %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package npm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// ProjectManager 项目使用的包管理器
+type ProjectManager string
+
+const (
+	ManagerNpm     ProjectManager = "npm"
+	ManagerYarn    ProjectManager = "yarn"
+	ManagerPnpm    ProjectManager = "pnpm"
+	ManagerBun     ProjectManager = "bun"
+	ManagerUnknown ProjectManager = "unknown"
+)
+
+// ProjectManagerInfo 项目包管理器检测结果
+type ProjectManagerInfo struct {
+	Manager    ProjectManager `json:"manager"`
+	Version    string         `json:"version,omitempty"`     // packageManager字段中声明的版本
+	Root       string         `json:"root"`                  // 找到依据的目录
+	Confidence float64        `json:"confidence"`            // 置信度，0到1之间
+	Evidence   []string       `json:"evidence,omitempty"`    // 检测依据
+	NodeLinker string         `json:"node_linker,omitempty"` // Yarn Berry的nodeLinker配置
+	PnP        bool           `json:"pnp"`                   // 是否使用Yarn Plug'n'Play
+}
+
+// UsesNodeModules 项目依赖是否安装在node_modules中
+func (i *ProjectManagerInfo) UsesNodeModules() bool {
+	return !i.PnP
+}
+
+// managerSignal 单条检测依据
+type managerSignal struct {
+	manager ProjectManager
+	weight  float64
+	reason  string
+}
+
+// managerFiles 文件与包管理器的对应关系及权重
+var managerFiles = []struct {
+	name    string
+	manager ProjectManager
+	weight  float64
+}{
+	{"package-lock.json", ManagerNpm, 0.9},
+	{"npm-shrinkwrap.json", ManagerNpm, 0.9},
+	{"yarn.lock", ManagerYarn, 0.9},
+	{"pnpm-lock.yaml", ManagerPnpm, 0.9},
+	{"bun.lockb", ManagerBun, 0.9},
+	{"bun.lock", ManagerBun, 0.9},
+	{"pnpm-workspace.yaml", ManagerPnpm, 0.5},
+	{".pnpmfile.cjs", ManagerPnpm, 0.5},
+	{".yarnrc.yml", ManagerYarn, 0.4},
+	{".yarnrc", ManagerYarn, 0.4},
+	{"bunfig.toml", ManagerBun, 0.4},
+}
+
+// DetectProjectManager 检测项目使用的包管理器
+//
+// 依次检查packageManager字段、锁文件和配置文件，当前目录没有任何依据时
+// 向上查找，直到遇到.git目录或文件系统根目录。没有找到依据时返回npm，置信度为0。
+func DetectProjectManager(dir string) (*ProjectManagerInfo, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	if stat, err := os.Stat(absDir); err != nil {
+		return nil, fmt.Errorf("failed to access directory: %w", err)
+	} else if !stat.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidWorkingDirectory, absDir)
+	}
+
+	current := absDir
+	for {
+		signals, declared, err := collectManagerSignals(current)
+		if err != nil {
+			return nil, err
+		}
+		if len(signals) > 0 {
+			info := scoreManagerSignals(signals)
+			info.Root = current
+			if declared != nil && info.Manager == declared.manager {
+				info.Version = declared.version
+			}
+			if info.Manager == ManagerYarn {
+				detectYarnLinker(current, info)
+			}
+			return info, nil
+		}
+
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	return &ProjectManagerInfo{
+		Manager:    ManagerNpm,
+		Root:       absDir,
+		Confidence: 0,
+		Evidence:   []string{"no lockfile, packageManager field or config file found, defaulting to npm"},
+	}, nil
+}
+
+// declaredManager packageManager字段声明的包管理器
+type declaredManager struct {
+	manager ProjectManager
+	version string
+}
+
+// collectManagerSignals 收集目录中的检测依据
+func collectManagerSignals(dir string) ([]managerSignal, *declaredManager, error) {
+	var signals []managerSignal
+	var declared *declaredManager
+
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err == nil {
+		var manifest struct {
+			PackageManager string `json:"packageManager"`
+		}
+		if err := utils.DecodeJSON(filepath.Join(dir, "package.json"), data, &manifest); err != nil {
+			return nil, nil, err
+		}
+		if manifest.PackageManager != "" {
+			manager, version := ParsePackageManagerField(manifest.PackageManager)
+			if manager != ManagerUnknown {
+				declared = &declaredManager{manager: manager, version: version}
+				signals = append(signals, managerSignal{
+					manager: manager,
+					weight:  1.0,
+					reason:  fmt.Sprintf("package.json packageManager field is %q", manifest.PackageManager),
+				})
+			}
+		}
+	}
+
+	for _, file := range managerFiles {
+		if _, err := os.Stat(filepath.Join(dir, file.name)); err == nil {
+			signals = append(signals, managerSignal{
+				manager: file.manager,
+				weight:  file.weight,
+				reason:  fmt.Sprintf("found %s", file.name),
+			})
+		}
+	}
+
+	return signals, declared, nil
+}
+
+// scoreManagerSignals 根据检测依据计算结果
+func scoreManagerSignals(signals []managerSignal) *ProjectManagerInfo {
+	scores := make(map[ProjectManager]float64)
+	var total float64
+	for _, signal := range signals {
+		scores[signal.manager] += signal.weight
+		total += signal.weight
+	}
+
+	managers := make([]ProjectManager, 0, len(scores))
+	for manager := range scores {
+		managers = append(managers, manager)
+	}
+	sort.Slice(managers, func(i, j int) bool {
+		if scores[managers[i]] != scores[managers[j]] {
+			return scores[managers[i]] > scores[managers[j]]
+		}
+		return managers[i] < managers[j]
+	})
+
+	winner := managers[0]
+	strength := scores[winner]
+	if strength > 1 {
+		strength = 1
+	}
+
+	info := &ProjectManagerInfo{
+		Manager:    winner,
+		Confidence: scores[winner] / total * strength,
+	}
+	for _, signal := range signals {
+		info.Evidence = append(info.Evidence, signal.reason)
+	}
+	if len(managers) > 1 {
+		info.Evidence = append(info.Evidence, fmt.Sprintf("conflicting signals for %s", joinManagers(managers[1:])))
+	}
+
+	return info
+}
+
+// joinManagers 拼接包管理器名称
+func joinManagers(managers []ProjectManager) string {
+	names := make([]string, len(managers))
+	for i, manager := range managers {
+		names[i] = string(manager)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParsePackageManagerField 解析package.json中的packageManager字段
+//
+// 字段格式为"<name>@<version>[+<hash>]"，例如"pnpm@8.15.0"。
+func ParsePackageManagerField(value string) (ProjectManager, string) {
+	value = strings.TrimSpace(value)
+	name, version, _ := strings.Cut(value, "@")
+	if idx := strings.Index(version, "+"); idx >= 0 {
+		version = version[:idx]
+	}
+
+	switch ProjectManager(strings.ToLower(name)) {
+	case ManagerNpm:
+		return ManagerNpm, version
+	case ManagerYarn:
+		return ManagerYarn, version
+	case ManagerPnpm:
+		return ManagerPnpm, version
+	case ManagerBun:
+		return ManagerBun, version
+	default:
+		return ManagerUnknown, version
+	}
+}
+
+// detectYarnLinker 检测Yarn项目的依赖链接方式
+//
+// Yarn 2及以上版本默认使用Plug'n'Play，除非.yarnrc.yml中将nodeLinker设置为
+// node-modules或pnpm。
+func detectYarnLinker(dir string, info *ProjectManagerInfo) {
+	berry := false
+	if major, ok := majorVersion(info.Version); ok && major >= 2 {
+		berry = true
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, ".yarnrc.yml")); err == nil {
+		berry = true
+		info.NodeLinker = parseYarnrcValue(string(data), "nodeLinker")
+	}
+
+	for _, name := range []string{".pnp.cjs", ".pnp.js"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			info.PnP = true
+			info.Evidence = append(info.Evidence, fmt.Sprintf("found %s", name))
+			if info.NodeLinker == "" {
+				info.NodeLinker = "pnp"
+			}
+			return
+		}
+	}
+
+	if !berry {
+		return
+	}
+	if info.NodeLinker == "" {
+		info.NodeLinker = "pnp"
+	}
+	info.PnP = info.NodeLinker == "pnp"
+	if info.PnP {
+		info.Evidence = append(info.Evidence, "yarn berry uses Plug'n'Play")
+	}
+}
+
+// parseYarnrcValue 读取.yarnrc.yml中的顶层配置项
+func parseYarnrcValue(content, key string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(name) != key {
+			continue
+		}
+		if idx := strings.Index(value, "#"); idx >= 0 {
+			value = value[:idx]
+		}
+		return strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return ""
+}
+
+// majorVersion 获取版本号的主版本
+func majorVersion(version string) (int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	major, _, _ := strings.Cut(version, ".")
+	value, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
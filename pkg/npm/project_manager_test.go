@@ -0,0 +1,213 @@
+package npm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestDetectProjectManagerLockfiles(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected ProjectManager
+	}{
+		{"package-lock.json", ManagerNpm},
+		{"yarn.lock", ManagerYarn},
+		{"pnpm-lock.yaml", ManagerPnpm},
+		{"bun.lockb", ManagerBun},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			dir := t.TempDir()
+			os.Mkdir(filepath.Join(dir, ".git"), 0755)
+			writeTestFile(t, filepath.Join(dir, "package.json"), `{"name":"test"}`)
+			writeTestFile(t, filepath.Join(dir, tt.file), "")
+
+			info, err := DetectProjectManager(dir)
+			if err != nil {
+				t.Fatalf("DetectProjectManager() failed: %v", err)
+			}
+			if info.Manager != tt.expected {
+				t.Errorf("Expected manager %s, got %s", tt.expected, info.Manager)
+			}
+			if info.Confidence < 0.8 {
+				t.Errorf("Expected high confidence, got %f", info.Confidence)
+			}
+			if len(info.Evidence) == 0 {
+				t.Error("Expected evidence to be recorded")
+			}
+		})
+	}
+}
+
+func TestDetectProjectManagerPackageManagerField(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, ".git"), 0755)
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name":"test","packageManager":"pnpm@8.15.0+sha256.abc"}`)
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), "{}")
+
+	info, err := DetectProjectManager(dir)
+	if err != nil {
+		t.Fatalf("DetectProjectManager() failed: %v", err)
+	}
+
+	if info.Manager != ManagerPnpm {
+		t.Errorf("Expected packageManager field to win, got %s", info.Manager)
+	}
+	if info.Version != "8.15.0" {
+		t.Errorf("Expected version '8.15.0', got '%s'", info.Version)
+	}
+	if info.Confidence >= 0.9 {
+		t.Errorf("Expected reduced confidence for conflicting signals, got %f", info.Confidence)
+	}
+}
+
+func TestDetectProjectManagerWalksUp(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	writeTestFile(t, filepath.Join(root, "yarn.lock"), "")
+	sub := filepath.Join(root, "packages", "app")
+	writeTestFile(t, filepath.Join(sub, "package.json"), `{"name":"app"}`)
+
+	info, err := DetectProjectManager(sub)
+	if err != nil {
+		t.Fatalf("DetectProjectManager() failed: %v", err)
+	}
+
+	if info.Manager != ManagerYarn {
+		t.Errorf("Expected yarn from parent lockfile, got %s", info.Manager)
+	}
+	if info.Root != root {
+		t.Errorf("Expected root '%s', got '%s'", root, info.Root)
+	}
+}
+
+func TestDetectProjectManagerDefault(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, ".git"), 0755)
+
+	info, err := DetectProjectManager(dir)
+	if err != nil {
+		t.Fatalf("DetectProjectManager() failed: %v", err)
+	}
+
+	if info.Manager != ManagerNpm {
+		t.Errorf("Expected default npm, got %s", info.Manager)
+	}
+	if info.Confidence != 0 {
+		t.Errorf("Expected zero confidence, got %f", info.Confidence)
+	}
+}
+
+func TestDetectProjectManagerErrors(t *testing.T) {
+	if _, err := DetectProjectManager(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, ".git"), 0755)
+	writeTestFile(t, filepath.Join(dir, "package.json"), "{invalid")
+	if _, err := DetectProjectManager(dir); err == nil {
+		t.Error("Expected error for invalid package.json")
+	}
+}
+
+func TestDetectProjectManagerYarnPnP(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		pnp        bool
+		nodeLinker string
+	}{
+		{
+			name:       "yarn classic",
+			files:      map[string]string{"yarn.lock": ""},
+			pnp:        false,
+			nodeLinker: "",
+		},
+		{
+			name:       "berry default linker",
+			files:      map[string]string{"yarn.lock": "", ".yarnrc.yml": "yarnPath: .yarn/releases/yarn-4.1.0.cjs\n"},
+			pnp:        true,
+			nodeLinker: "pnp",
+		},
+		{
+			name:       "berry node-modules linker",
+			files:      map[string]string{"yarn.lock": "", ".yarnrc.yml": "nodeLinker: node-modules # compat\n"},
+			pnp:        false,
+			nodeLinker: "node-modules",
+		},
+		{
+			name:       "pnp runtime present",
+			files:      map[string]string{"yarn.lock": "", ".pnp.cjs": ""},
+			pnp:        true,
+			nodeLinker: "pnp",
+		},
+		{
+			name:       "packageManager declares berry",
+			files:      map[string]string{"package.json": `{"packageManager":"yarn@4.1.0"}`},
+			pnp:        true,
+			nodeLinker: "pnp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.Mkdir(filepath.Join(dir, ".git"), 0755)
+			for name, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, name), content)
+			}
+
+			info, err := DetectProjectManager(dir)
+			if err != nil {
+				t.Fatalf("DetectProjectManager() failed: %v", err)
+			}
+			if info.Manager != ManagerYarn {
+				t.Fatalf("Expected yarn, got %s", info.Manager)
+			}
+			if info.PnP != tt.pnp {
+				t.Errorf("Expected PnP %v, got %v", tt.pnp, info.PnP)
+			}
+			if info.UsesNodeModules() == tt.pnp {
+				t.Errorf("Expected UsesNodeModules() to be %v", !tt.pnp)
+			}
+			if info.NodeLinker != tt.nodeLinker {
+				t.Errorf("Expected nodeLinker '%s', got '%s'", tt.nodeLinker, info.NodeLinker)
+			}
+		})
+	}
+}
+
+func TestParsePackageManagerField(t *testing.T) {
+	tests := []struct {
+		value   string
+		manager ProjectManager
+		version string
+	}{
+		{"npm@10.2.0", ManagerNpm, "10.2.0"},
+		{"yarn@4.1.0", ManagerYarn, "4.1.0"},
+		{"pnpm@8.15.0+sha512.deadbeef", ManagerPnpm, "8.15.0"},
+		{"bun@1.0.0", ManagerBun, "1.0.0"},
+		{"deno@1.0.0", ManagerUnknown, "1.0.0"},
+		{"", ManagerUnknown, ""},
+	}
+
+	for _, tt := range tests {
+		manager, version := ParsePackageManagerField(tt.value)
+		if manager != tt.manager || version != tt.version {
+			t.Errorf("ParsePackageManagerField(%q) = (%s, %s), expected (%s, %s)", tt.value, manager, version, tt.manager, tt.version)
+		}
+	}
+}
@@ -0,0 +1,619 @@
+package npm
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+// ErrNoProvenance 包版本没有来源证明
+var ErrNoProvenance = errors.New("package version has no provenance attestation")
+
+// ErrNoTrustRoot 没有设置验证来源证明所需的Fulcio根证书或Rekor公钥
+var ErrNoTrustRoot = errors.New("no sigstore trust root configured")
+
+// ProvenanceError 来源证明缺失或验证失败
+type ProvenanceError struct {
+	Name    string
+	Version string
+	Reason  string
+	Err     error
+}
+
+// Error 实现error接口
+func (e *ProvenanceError) Error() string {
+	return fmt.Sprintf("provenance verification failed for %s@%s: %s", e.Name, e.Version, e.Reason)
+}
+
+// Unwrap 返回底层错误
+func (e *ProvenanceError) Unwrap() error {
+	return e.Err
+}
+
+// Provenance 验证通过的来源证明，说明包版本由哪个仓库的哪次构建发布
+type Provenance struct {
+	Name          string `json:"name"`
+	Version       string `json:"version"`
+	PredicateType string `json:"predicate_type"`
+
+	Repository    string `json:"repository,omitempty"` // 源码仓库，例如https://github.com/owner/repo
+	Commit        string `json:"commit,omitempty"`
+	Ref           string `json:"ref,omitempty"`      // 例如refs/tags/v1.0.0
+	Workflow      string `json:"workflow,omitempty"` // 构建工作流文件在仓库中的路径
+	BuilderID     string `json:"builder_id,omitempty"`
+	InvocationURL string `json:"invocation_url,omitempty"` // 构建运行的地址
+
+	Identity string `json:"identity"`         // 签名证书的主体，通常是工作流的URI
+	Issuer   string `json:"issuer,omitempty"` // 签发身份令牌的OIDC issuer
+
+	LogIndex       int64     `json:"log_index"`
+	IntegratedTime time.Time `json:"integrated_time"` // 写入透明日志的时间
+
+	CertificateTrusted bool `json:"certificate_trusted"` // 证书链已由SetFulcioRoots设置的根证书验证，验证通过时总为true
+	LogEntryVerified   bool `json:"log_entry_verified"`  // 透明日志的签名时间戳已由SetRekorKeys设置的公钥验证，验证通过时总为true
+	PublishAttestation bool `json:"publish_attestation"` // registry签署的发布证明也已验证
+}
+
+// ProvenanceVerifier 获取并验证包版本的sigstore来源证明
+//
+// 验证的内容：DSSE签名、证书链由Fulcio根证书签发且在写入透明日志时有效、
+// 透明日志条目对应该签名且签名时间戳由Rekor公钥签署、证明的主体是registry上该版本tarball的SHA-512、
+// 证书中的源码仓库与证明一致，以及registry签署的发布证明（如果有）。
+// Fulcio根证书和Rekor公钥需要调用方从sigstore的TUF仓库取得后通过SetFulcioRoots和
+// SetRekorKeys设置，未设置时验证失败，错误满足errors.Is(err, ErrNoTrustRoot)。
+type ProvenanceVerifier struct {
+	registry    *registry.Client
+	fulcioRoots *x509.CertPool
+	rekorKeys   map[string]crypto.PublicKey // 日志ID（公钥DER的SHA-256） -> 公钥
+}
+
+// NewProvenanceVerifier 创建来源证明验证器
+func NewProvenanceVerifier(registryClient *registry.Client) *ProvenanceVerifier {
+	return &ProvenanceVerifier{
+		registry:  registryClient,
+		rekorKeys: make(map[string]crypto.PublicKey),
+	}
+}
+
+// SetFulcioRoots 设置验证签名证书链的Fulcio根证书
+func (v *ProvenanceVerifier) SetFulcioRoots(roots *x509.CertPool) {
+	v.fulcioRoots = roots
+}
+
+// SetRekorKeys 设置验证透明日志签名时间戳的Rekor公钥，按公钥计算日志ID
+func (v *ProvenanceVerifier) SetRekorKeys(keys ...crypto.PublicKey) error {
+	for _, key := range keys {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			return fmt.Errorf("failed to encode rekor key: %w", err)
+		}
+		sum := sha256.Sum256(der)
+		v.rekorKeys[hex.EncodeToString(sum[:])] = key
+	}
+	return nil
+}
+
+// VerifyProvenance 验证包版本的来源证明，version可以是版本号或dist-tag
+//
+// 使用npm_config_registry环境变量指定的registry，未设置时使用官方registry。
+// 没有设置信任根，有来源证明时总是返回满足errors.Is(err, ErrNoTrustRoot)的错误，
+// 只能用来判断是否有来源证明；需要验证时使用ProvenanceVerifier并设置Fulcio根证书和Rekor公钥。
+func VerifyProvenance(ctx context.Context, pkg, version string) (*Provenance, error) {
+	return NewProvenanceVerifier(defaultFallback()).Verify(ctx, pkg, version)
+}
+
+// Verify 获取并验证包版本的来源证明，没有证明时错误满足errors.Is(err, ErrNoProvenance)
+func (v *ProvenanceVerifier) Verify(ctx context.Context, name, version string) (*Provenance, error) {
+	manifest, err := v.registry.GetManifest(ctx, name, version)
+	if err != nil {
+		if registry.IsNotFound(err) {
+			err = fmt.Errorf("%w: %w", ErrPackageNotFound, err)
+		}
+		return nil, NewNpmError("view", name+"@"+version, -1, "", "", err)
+	}
+	version = manifest.Version
+	fail := func(err error) error {
+		return &ProvenanceError{Name: name, Version: version, Reason: err.Error(), Err: err}
+	}
+
+	digest := sha512Integrity(manifest.Dist.Integrity)
+	if digest == nil {
+		return nil, fail(errors.New("registry has no sha512 integrity for the tarball"))
+	}
+	attestations, err := v.registry.Attestations(ctx, name, version)
+	if registry.IsNotFound(err) {
+		return nil, fail(ErrNoProvenance)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var provenance *Provenance
+	for _, attestation := range attestations {
+		if attestation.PredicateType != registry.PredicateSLSAProvenanceV1 && attestation.PredicateType != registry.PredicateSLSAProvenanceV02 {
+			continue
+		}
+		provenance, err = v.verifyProvenance(name, version, digest, attestation)
+		if err != nil {
+			return nil, fail(err)
+		}
+		break
+	}
+	if provenance == nil {
+		return nil, fail(ErrNoProvenance)
+	}
+
+	var keys []registry.SigningKey
+	for _, attestation := range attestations {
+		if attestation.PredicateType != registry.PredicateNpmPublish {
+			continue
+		}
+		if keys == nil {
+			if keys, err = v.registry.SigningKeys(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if err := v.verifyPublishAttestation(name, version, digest, attestation, keys); err != nil {
+			return nil, fail(fmt.Errorf("publish attestation: %w", err))
+		}
+		provenance.PublishAttestation = true
+	}
+	return provenance, nil
+}
+
+// verifyProvenance 验证SLSA来源证明并取出构建信息
+func (v *ProvenanceVerifier) verifyProvenance(name, version string, digest []byte, attestation registry.Attestation) (*Provenance, error) {
+	bundle, err := v.verifyBundle(attestation.Bundle, nil)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.certificate == nil {
+		return nil, errors.New("provenance bundle is not signed with a certificate")
+	}
+	if err := checkStatement(bundle.statement, attestation.PredicateType, name, version, digest); err != nil {
+		return nil, err
+	}
+
+	provenance := &Provenance{
+		Name:               name,
+		Version:            version,
+		PredicateType:      attestation.PredicateType,
+		Issuer:             fulcioExtension(bundle.certificate, oidFulcioIssuerV2, oidFulcioIssuer),
+		LogIndex:           bundle.logIndex,
+		IntegratedTime:     bundle.integratedTime,
+		CertificateTrusted: bundle.trusted,
+		LogEntryVerified:   bundle.logVerified,
+	}
+	if uris := bundle.certificate.URIs; len(uris) > 0 {
+		provenance.Identity = uris[0].String()
+	} else if len(bundle.certificate.EmailAddresses) > 0 {
+		provenance.Identity = bundle.certificate.EmailAddresses[0]
+	}
+	if err := readSLSAPredicate(provenance, bundle.statement.Predicate); err != nil {
+		return nil, err
+	}
+
+	// 证书由Fulcio根据构建的身份令牌签发，其中的仓库和提交必须与证明中的一致
+	if repository := fulcioExtension(bundle.certificate, oidFulcioSourceRepository); repository != "" && provenance.Repository != "" && repository != provenance.Repository {
+		return nil, fmt.Errorf("certificate source repository %s differs from provenance repository %s", repository, provenance.Repository)
+	}
+	if commit := fulcioExtension(bundle.certificate, oidFulcioSourceDigest); commit != "" && provenance.Commit != "" && commit != provenance.Commit {
+		return nil, fmt.Errorf("certificate source commit %s differs from provenance commit %s", commit, provenance.Commit)
+	}
+	return provenance, nil
+}
+
+// verifyPublishAttestation 验证registry用自己的密钥签署的发布证明
+func (v *ProvenanceVerifier) verifyPublishAttestation(name, version string, digest []byte, attestation registry.Attestation, keys []registry.SigningKey) error {
+	bundle, err := v.verifyBundle(attestation.Bundle, func(hint string, signedAt time.Time) (crypto.PublicKey, error) {
+		for _, key := range keys {
+			if key.KeyID != hint {
+				continue
+			}
+			if key.Expires != nil && !signedAt.IsZero() && signedAt.After(*key.Expires) {
+				return nil, fmt.Errorf("signing key %s expired at %s", hint, key.Expires.Format(time.RFC3339))
+			}
+			der, err := base64.StdEncoding.DecodeString(key.Key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode signing key %s: %w", hint, err)
+			}
+			return x509.ParsePKIXPublicKey(der)
+		}
+		return nil, fmt.Errorf("unknown registry signing key %s", hint)
+	})
+	if err != nil {
+		return err
+	}
+	return checkStatement(bundle.statement, attestation.PredicateType, name, version, digest)
+}
+
+// sigstoreBundle sigstore bundle中用到的字段，兼容0.1到0.3版本
+type sigstoreBundle struct {
+	VerificationMaterial struct {
+		X509CertificateChain *struct {
+			Certificates []struct {
+				RawBytes []byte `json:"rawBytes"`
+			} `json:"certificates"`
+		} `json:"x509CertificateChain"`
+		Certificate *struct {
+			RawBytes []byte `json:"rawBytes"`
+		} `json:"certificate"`
+		PublicKey *struct {
+			Hint string `json:"hint"`
+		} `json:"publicKey"`
+		TlogEntries []tlogEntry `json:"tlogEntries"`
+	} `json:"verificationMaterial"`
+	DSSEEnvelope *struct {
+		Payload     []byte `json:"payload"`
+		PayloadType string `json:"payloadType"`
+		Signatures  []struct {
+			Sig   []byte `json:"sig"`
+			KeyID string `json:"keyid"`
+		} `json:"signatures"`
+	} `json:"dsseEnvelope"`
+}
+
+// tlogEntry Rekor透明日志条目，整数按protobuf的JSON格式以字符串表示
+type tlogEntry struct {
+	LogIndex json.Number `json:"logIndex"`
+	LogID    struct {
+		KeyID []byte `json:"keyId"`
+	} `json:"logId"`
+	IntegratedTime   json.Number `json:"integratedTime"`
+	InclusionPromise *struct {
+		SignedEntryTimestamp []byte `json:"signedEntryTimestamp"`
+	} `json:"inclusionPromise"`
+	CanonicalizedBody []byte `json:"canonicalizedBody"`
+}
+
+// inTotoStatement DSSE载荷中的in-toto声明
+type inTotoStatement struct {
+	Subject []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// verifiedBundle 签名验证通过的bundle
+type verifiedBundle struct {
+	statement      inTotoStatement
+	certificate    *x509.Certificate // 使用公钥签名时为nil
+	logIndex       int64
+	integratedTime time.Time // 没有透明日志条目时为零值
+	trusted        bool
+	logVerified    bool
+}
+
+// verifyBundle 验证bundle的DSSE签名和透明日志条目
+//
+// 证书签名的bundle必须有透明日志条目，证书只在签发后的几分钟内有效。
+// publicKey为nil时不接受公钥签名的bundle。
+func (v *ProvenanceVerifier) verifyBundle(data json.RawMessage, publicKey func(hint string, signedAt time.Time) (crypto.PublicKey, error)) (*verifiedBundle, error) {
+	var bundle sigstoreBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse sigstore bundle: %w", err)
+	}
+	envelope := bundle.DSSEEnvelope
+	if envelope == nil {
+		return nil, errors.New("bundle has no DSSE envelope")
+	}
+	if envelope.PayloadType != "application/vnd.in-toto+json" {
+		return nil, fmt.Errorf("unexpected payload type %q", envelope.PayloadType)
+	}
+
+	result := &verifiedBundle{}
+	material := bundle.VerificationMaterial
+	var entry *tlogEntry
+	if len(material.TlogEntries) > 0 {
+		entry = &material.TlogEntries[0]
+		integratedTime, err := entry.IntegratedTime.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid integrated time %q", entry.IntegratedTime)
+		}
+		if result.logIndex, err = entry.LogIndex.Int64(); err != nil {
+			return nil, fmt.Errorf("invalid log index %q", entry.LogIndex)
+		}
+		result.integratedTime = time.Unix(integratedTime, 0).UTC()
+	}
+
+	var chain [][]byte
+	if material.Certificate != nil {
+		chain = [][]byte{material.Certificate.RawBytes}
+	} else if material.X509CertificateChain != nil {
+		for _, cert := range material.X509CertificateChain.Certificates {
+			chain = append(chain, cert.RawBytes)
+		}
+	}
+
+	var key crypto.PublicKey
+	switch {
+	case len(chain) > 0:
+		if entry == nil {
+			return nil, errors.New("bundle has no transparency log entry")
+		}
+		cert, err := v.verifyCertificate(chain, result.integratedTime)
+		if err != nil {
+			return nil, err
+		}
+		result.certificate, result.trusted, key = cert, true, cert.PublicKey
+	case material.PublicKey != nil && publicKey != nil:
+		var err error
+		if key, err = publicKey(material.PublicKey.Hint, result.integratedTime); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("bundle has no usable verification material")
+	}
+
+	pae := dssePAE(envelope.PayloadType, envelope.Payload)
+	verified := false
+	for _, signature := range envelope.Signatures {
+		if verifySignature(key, pae, signature.Sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("DSSE signature does not match")
+	}
+
+	if entry != nil {
+		if err := checkLogEntryBody(entry.CanonicalizedBody, envelope.Payload); err != nil {
+			return nil, err
+		}
+		logVerified, err := v.verifyInclusionPromise(entry)
+		if err != nil {
+			return nil, err
+		}
+		result.logVerified = logVerified
+	}
+
+	if err := json.Unmarshal(envelope.Payload, &result.statement); err != nil {
+		return nil, fmt.Errorf("failed to parse in-toto statement: %w", err)
+	}
+	return result, nil
+}
+
+// verifyCertificate 检查签名证书在signedAt时有效，并用Fulcio根证书验证证书链
+func (v *ProvenanceVerifier) verifyCertificate(chain [][]byte, signedAt time.Time) (*x509.Certificate, error) {
+	cert, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+	}
+	if signedAt.Before(cert.NotBefore) || signedAt.After(cert.NotAfter) {
+		return nil, fmt.Errorf("signing certificate was not valid at %s", signedAt.Format(time.RFC3339))
+	}
+	if v.fulcioRoots == nil {
+		return nil, fmt.Errorf("%w: Fulcio root certificates are not set", ErrNoTrustRoot)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, der := range chain[1:] {
+		intermediate, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate chain: %w", err)
+		}
+		intermediates.AddCert(intermediate)
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         v.fulcioRoots,
+		Intermediates: intermediates,
+		CurrentTime:   signedAt,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("signing certificate is not trusted: %w", err)
+	}
+	return cert, nil
+}
+
+// verifyInclusionPromise 用Rekor公钥验证透明日志条目的签名时间戳
+func (v *ProvenanceVerifier) verifyInclusionPromise(entry *tlogEntry) (bool, error) {
+	if len(v.rekorKeys) == 0 {
+		return false, fmt.Errorf("%w: Rekor public keys are not set", ErrNoTrustRoot)
+	}
+	logID := hex.EncodeToString(entry.LogID.KeyID)
+	key, ok := v.rekorKeys[logID]
+	if !ok {
+		return false, fmt.Errorf("transparency log %s is not trusted", logID)
+	}
+	if entry.InclusionPromise == nil {
+		return false, errors.New("transparency log entry has no signed entry timestamp")
+	}
+
+	// 签名的内容是按键排序的紧凑JSON
+	integratedTime, _ := entry.IntegratedTime.Int64()
+	logIndex, _ := entry.LogIndex.Int64()
+	payload, err := json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{base64.StdEncoding.EncodeToString(entry.CanonicalizedBody), integratedTime, logID, logIndex})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode log entry: %w", err)
+	}
+	if !verifySignature(key, payload, entry.InclusionPromise.SignedEntryTimestamp) {
+		return false, errors.New("transparency log signed entry timestamp does not match")
+	}
+	return true, nil
+}
+
+// checkLogEntryBody 确认透明日志条目记录的是这个DSSE载荷，兼容intoto和dsse两种条目
+func checkLogEntryBody(body, payload []byte) error {
+	var entry struct {
+		Spec struct {
+			Content struct {
+				PayloadHash struct {
+					Value string `json:"value"`
+				} `json:"payloadHash"`
+			} `json:"content"`
+			PayloadHash struct {
+				Value string `json:"value"`
+			} `json:"payloadHash"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(body, &entry); err != nil {
+		return fmt.Errorf("failed to parse transparency log entry: %w", err)
+	}
+	recorded := entry.Spec.Content.PayloadHash.Value
+	if recorded == "" {
+		recorded = entry.Spec.PayloadHash.Value
+	}
+	sum := sha256.Sum256(payload)
+	if recorded != hex.EncodeToString(sum[:]) {
+		return errors.New("transparency log entry does not match the DSSE payload")
+	}
+	return nil
+}
+
+// checkStatement 确认声明的谓词类型正确，并且主体是该包版本的tarball
+func checkStatement(statement inTotoStatement, predicateType, name, version string, digest []byte) error {
+	if statement.PredicateType != predicateType {
+		return fmt.Errorf("statement predicate type %q does not match attestation %q", statement.PredicateType, predicateType)
+	}
+	purls := []string{"pkg:npm/" + strings.Replace(name, "@", "%40", 1) + "@" + version, "pkg:npm/" + name + "@" + version}
+	for _, subject := range statement.Subject {
+		if subject.Name != purls[0] && subject.Name != purls[1] {
+			continue
+		}
+		if subject.Digest["sha512"] != hex.EncodeToString(digest) {
+			return fmt.Errorf("attested digest of %s does not match the registry tarball", subject.Name)
+		}
+		return nil
+	}
+	return fmt.Errorf("statement does not attest %s", purls[0])
+}
+
+// readSLSAPredicate 从SLSA v1或v0.2谓词中取出仓库、提交、工作流和构建信息
+func readSLSAPredicate(provenance *Provenance, data json.RawMessage) error {
+	var predicate struct {
+		BuildDefinition struct {
+			ExternalParameters struct {
+				Workflow struct {
+					Ref        string `json:"ref"`
+					Repository string `json:"repository"`
+					Path       string `json:"path"`
+				} `json:"workflow"`
+			} `json:"externalParameters"`
+			ResolvedDependencies []struct {
+				Digest map[string]string `json:"digest"`
+			} `json:"resolvedDependencies"`
+		} `json:"buildDefinition"`
+		RunDetails struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+			Metadata struct {
+				InvocationID string `json:"invocationId"`
+			} `json:"metadata"`
+		} `json:"runDetails"`
+
+		// v0.2
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+		Invocation struct {
+			ConfigSource struct {
+				URI        string            `json:"uri"`
+				Digest     map[string]string `json:"digest"`
+				EntryPoint string            `json:"entryPoint"`
+			} `json:"configSource"`
+		} `json:"invocation"`
+		Metadata struct {
+			BuildInvocationID string `json:"buildInvocationId"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &predicate); err != nil {
+		return fmt.Errorf("failed to parse provenance predicate: %w", err)
+	}
+
+	if provenance.PredicateType == registry.PredicateSLSAProvenanceV02 {
+		source := predicate.Invocation.ConfigSource
+		// uri形如git+https://github.com/owner/repo@refs/heads/main
+		uri := strings.TrimPrefix(source.URI, "git+")
+		if idx := strings.LastIndex(uri, "@"); idx > 0 {
+			uri, provenance.Ref = uri[:idx], uri[idx+1:]
+		}
+		provenance.Repository = uri
+		provenance.Commit = source.Digest["sha1"]
+		provenance.Workflow = source.EntryPoint
+		provenance.BuilderID = predicate.Builder.ID
+		provenance.InvocationURL = predicate.Metadata.BuildInvocationID
+		return nil
+	}
+
+	workflow := predicate.BuildDefinition.ExternalParameters.Workflow
+	provenance.Repository = workflow.Repository
+	provenance.Ref = workflow.Ref
+	provenance.Workflow = workflow.Path
+	if deps := predicate.BuildDefinition.ResolvedDependencies; len(deps) > 0 {
+		provenance.Commit = deps[0].Digest["gitCommit"]
+	}
+	provenance.BuilderID = predicate.RunDetails.Builder.ID
+	provenance.InvocationURL = predicate.RunDetails.Metadata.InvocationID
+	return nil
+}
+
+// Fulcio证书扩展
+var (
+	oidFulcioIssuer           = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}  // 旧格式，值为原始字符串
+	oidFulcioIssuerV2         = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}  // 以下的值为DER编码的UTF8String
+	oidFulcioSourceRepository = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 12} // 源码仓库URI
+	oidFulcioSourceDigest     = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 13} // 源码提交
+)
+
+// fulcioExtension 按顺序返回证书中第一个存在的扩展的值
+func fulcioExtension(cert *x509.Certificate, oids ...asn1.ObjectIdentifier) string {
+	for _, oid := range oids {
+		for _, ext := range cert.Extensions {
+			if !ext.Id.Equal(oid) {
+				continue
+			}
+			if oid.Equal(oidFulcioIssuer) {
+				return string(ext.Value)
+			}
+			var value string
+			if _, err := asn1.UnmarshalWithParams(ext.Value, &value, "utf8"); err == nil {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// dssePAE DSSE的预认证编码，签名的实际内容
+func dssePAE(payloadType string, payload []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// verifySignature 验证ECDSA（SHA-256）或Ed25519签名
+func verifySignature(key crypto.PublicKey, data, signature []byte) bool {
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, sum[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, signature)
+	}
+	return false
+}
@@ -0,0 +1,327 @@
+package npm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+// testSigstore 测试用的Fulcio、Rekor和registry签名密钥
+type testSigstore struct {
+	t           *testing.T
+	root        *x509.Certificate
+	rootKey     *ecdsa.PrivateKey
+	rekorKey    *ecdsa.PrivateKey
+	registryKey *ecdsa.PrivateKey
+	signedAt    time.Time
+}
+
+func newTestSigstore(t *testing.T) *testSigstore {
+	t.Helper()
+	s := &testSigstore{t: t, rootKey: testECDSAKey(t), rekorKey: testECDSAKey(t), registryKey: testECDSAKey(t), signedAt: time.Now().Add(-time.Hour).Truncate(time.Second)}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test fulcio"},
+		NotBefore:             s.signedAt.Add(-24 * time.Hour),
+		NotAfter:              s.signedAt.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &s.rootKey.PublicKey, s.rootKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() failed: %v", err)
+	}
+	s.root, _ = x509.ParseCertificate(der)
+	return s
+}
+
+func testECDSAKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	return key
+}
+
+func testUTF8Extension(t *testing.T, oid asn1.ObjectIdentifier, value string) pkix.Extension {
+	data, err := asn1.MarshalWithParams(value, "utf8")
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	return pkix.Extension{Id: oid, Value: data}
+}
+
+// bundle 构造DSSE签名的bundle，certificate为false时使用registry密钥签名
+func (s *testSigstore) bundle(statement string, certificate bool) json.RawMessage {
+	t := s.t
+	payload := []byte(statement)
+	signer := s.registryKey
+	material := map[string]any{}
+	if certificate {
+		signer = testECDSAKey(t)
+		workflow, _ := url.Parse("https://github.com/owner/repo/.github/workflows/release.yml@refs/tags/v1.0.0")
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			NotBefore:    s.signedAt.Add(-5 * time.Minute),
+			NotAfter:     s.signedAt.Add(5 * time.Minute),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			URIs:         []*url.URL{workflow},
+			ExtraExtensions: []pkix.Extension{
+				testUTF8Extension(t, oidFulcioIssuerV2, "https://token.actions.githubusercontent.com"),
+				testUTF8Extension(t, oidFulcioSourceRepository, "https://github.com/owner/repo"),
+				testUTF8Extension(t, oidFulcioSourceDigest, "0123abcd"),
+			},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, s.root, &signer.PublicKey, s.rootKey)
+		if err != nil {
+			t.Fatalf("CreateCertificate() failed: %v", err)
+		}
+		material["certificate"] = map[string]any{"rawBytes": der}
+	} else {
+		material["publicKey"] = map[string]any{"hint": "SHA256:test"}
+	}
+
+	signature, err := ecdsa.SignASN1(rand.Reader, signer, testSHA256(dssePAE("application/vnd.in-toto+json", payload)))
+	if err != nil {
+		t.Fatalf("SignASN1() failed: %v", err)
+	}
+
+	payloadHash := sha256.Sum256(payload)
+	body, _ := json.Marshal(map[string]any{"kind": "intoto", "spec": map[string]any{"content": map[string]any{"payloadHash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(payloadHash[:])}}}})
+	rekorDER, _ := x509.MarshalPKIXPublicKey(&s.rekorKey.PublicKey)
+	logID := sha256.Sum256(rekorDER)
+	set, _ := json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{base64.StdEncoding.EncodeToString(body), s.signedAt.Unix(), hex.EncodeToString(logID[:]), 42})
+	setSignature, err := ecdsa.SignASN1(rand.Reader, s.rekorKey, testSHA256(set))
+	if err != nil {
+		t.Fatalf("SignASN1() failed: %v", err)
+	}
+	material["tlogEntries"] = []any{map[string]any{
+		"logIndex":          "42",
+		"logId":             map[string]any{"keyId": logID[:]},
+		"integratedTime":    strconv.FormatInt(s.signedAt.Unix(), 10),
+		"inclusionPromise":  map[string]any{"signedEntryTimestamp": setSignature},
+		"canonicalizedBody": body,
+	}}
+
+	data, _ := json.Marshal(map[string]any{
+		"mediaType":            "application/vnd.dev.sigstore.bundle.v0.3+json",
+		"verificationMaterial": material,
+		"dsseEnvelope": map[string]any{
+			"payload":     payload,
+			"payloadType": "application/vnd.in-toto+json",
+			"signatures":  []any{map[string]any{"sig": signature, "keyid": ""}},
+		},
+	})
+	return data
+}
+
+func testSHA256(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// statement 构造主体为@scope/pkg@1.0.0的in-toto声明
+func testStatement(predicateType string, digest []byte, predicate string) string {
+	return `{"_type":"https://in-toto.io/Statement/v1","subject":[{"name":"pkg:npm/%40scope/pkg@1.0.0","digest":{"sha512":"` + hex.EncodeToString(digest) + `"}}],"predicateType":"` + predicateType + `","predicate":` + predicate + `}`
+}
+
+func TestProvenanceVerifier(t *testing.T) {
+	sigstore := newTestSigstore(t)
+	tarball := sha512.Sum512([]byte("tarball"))
+	integrity := "sha512-" + base64.StdEncoding.EncodeToString(tarball[:])
+
+	provenancePredicate := `{
+		"buildDefinition": {
+			"externalParameters": {"workflow": {"ref": "refs/tags/v1.0.0", "repository": "https://github.com/owner/repo", "path": ".github/workflows/release.yml"}},
+			"resolvedDependencies": [{"uri": "git+https://github.com/owner/repo@refs/tags/v1.0.0", "digest": {"gitCommit": "0123abcd"}}]
+		},
+		"runDetails": {"builder": {"id": "https://github.com/actions/runner"}, "metadata": {"invocationId": "https://github.com/owner/repo/actions/runs/1/attempts/1"}}
+	}`
+	attestations := []registry.Attestation{
+		{PredicateType: registry.PredicateSLSAProvenanceV1, Bundle: sigstore.bundle(testStatement(registry.PredicateSLSAProvenanceV1, tarball[:], provenancePredicate), true)},
+		{PredicateType: registry.PredicateNpmPublish, Bundle: sigstore.bundle(testStatement(registry.PredicateNpmPublish, tarball[:], `{"name":"@scope/pkg","version":"1.0.0"}`), false)},
+	}
+
+	registryDER, _ := x509.MarshalPKIXPublicKey(&sigstore.registryKey.PublicKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/@scope/pkg/latest", "/@scope/pkg/1.0.0":
+			w.Write([]byte(`{"name":"@scope/pkg","version":"1.0.0","dist":{"tarball":"https://example.com/pkg.tgz","integrity":"` + integrity + `"}}`))
+		case "/-/npm/v1/attestations/@scope/pkg@1.0.0":
+			json.NewEncoder(w).Encode(map[string]any{"attestations": attestations})
+		case "/-/npm/v1/keys":
+			json.NewEncoder(w).Encode(map[string]any{"keys": []any{map[string]any{"keyid": "SHA256:test", "keytype": "ecdsa-sha2-nistp256", "scheme": "ecdsa-sha2-nistp256", "key": base64.StdEncoding.EncodeToString(registryDER), "expires": nil}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	verifier := NewProvenanceVerifier(registry.NewClient(server.URL))
+
+	// 没有信任根时验证失败
+	if _, err := verifier.Verify(ctx, "@scope/pkg", "latest"); !errors.Is(err, ErrNoTrustRoot) {
+		t.Errorf("Expected ErrNoTrustRoot without trust roots, got %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(sigstore.root)
+	verifier.SetFulcioRoots(roots)
+	if _, err := verifier.Verify(ctx, "@scope/pkg", "latest"); !errors.Is(err, ErrNoTrustRoot) {
+		t.Errorf("Expected ErrNoTrustRoot without Rekor keys, got %v", err)
+	}
+	if err := verifier.SetRekorKeys(&sigstore.rekorKey.PublicKey); err != nil {
+		t.Fatalf("SetRekorKeys() failed: %v", err)
+	}
+	provenance, err := verifier.Verify(ctx, "@scope/pkg", "latest")
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	if provenance.Version != "1.0.0" || provenance.Repository != "https://github.com/owner/repo" || provenance.Commit != "0123abcd" ||
+		provenance.Ref != "refs/tags/v1.0.0" || provenance.Workflow != ".github/workflows/release.yml" {
+		t.Errorf("Unexpected source: %+v", provenance)
+	}
+	if provenance.Issuer != "https://token.actions.githubusercontent.com" || provenance.Identity != "https://github.com/owner/repo/.github/workflows/release.yml@refs/tags/v1.0.0" {
+		t.Errorf("Unexpected identity: %+v", provenance)
+	}
+	if provenance.LogIndex != 42 || !provenance.IntegratedTime.Equal(sigstore.signedAt) || !provenance.PublishAttestation {
+		t.Errorf("Unexpected log entry: %+v", provenance)
+	}
+	if !provenance.CertificateTrusted || !provenance.LogEntryVerified {
+		t.Errorf("Expected trusted certificate and log entry: %+v", provenance)
+	}
+
+	// 其他根证书签发的证书和其他日志的条目不被信任
+	untrusted := NewProvenanceVerifier(registry.NewClient(server.URL))
+	untrusted.SetFulcioRoots(x509.NewCertPool())
+	var provenanceErr *ProvenanceError
+	if _, err := untrusted.Verify(ctx, "@scope/pkg", "1.0.0"); !errors.As(err, &provenanceErr) {
+		t.Errorf("Expected ProvenanceError for untrusted root, got %v", err)
+	}
+	untrusted = NewProvenanceVerifier(registry.NewClient(server.URL))
+	untrusted.SetFulcioRoots(roots)
+	untrusted.SetRekorKeys(&testECDSAKey(t).PublicKey)
+	if _, err := untrusted.Verify(ctx, "@scope/pkg", "1.0.0"); !errors.As(err, &provenanceErr) {
+		t.Errorf("Expected ProvenanceError for unknown log, got %v", err)
+	}
+
+	// 证明的摘要与registry上的tarball不符
+	other := sha512.Sum512([]byte("other"))
+	attestations[0].Bundle = sigstore.bundle(testStatement(registry.PredicateSLSAProvenanceV1, other[:], provenancePredicate), true)
+	if _, err := verifier.Verify(ctx, "@scope/pkg", "1.0.0"); !errors.As(err, &provenanceErr) {
+		t.Errorf("Expected ProvenanceError for digest mismatch, got %v", err)
+	}
+
+	// 签名被篡改
+	var bundle map[string]any
+	json.Unmarshal(sigstore.bundle(testStatement(registry.PredicateSLSAProvenanceV1, tarball[:], provenancePredicate), true), &bundle)
+	bundle["dsseEnvelope"].(map[string]any)["payload"] = base64.StdEncoding.EncodeToString([]byte(testStatement(registry.PredicateSLSAProvenanceV1, tarball[:], `{}`)))
+	attestations[0].Bundle, _ = json.Marshal(bundle)
+	if _, err := verifier.Verify(ctx, "@scope/pkg", "1.0.0"); !errors.As(err, &provenanceErr) {
+		t.Errorf("Expected ProvenanceError for tampered payload, got %v", err)
+	}
+
+	if _, err := verifier.Verify(ctx, "@scope/missing", "1.0.0"); !IsPackageNotFound(err) {
+		t.Errorf("Expected package not found, got %v", err)
+	}
+}
+
+func TestProvenanceVerifierRejectsSelfSignedBundle(t *testing.T) {
+	tarball := sha512.Sum512([]byte("tarball"))
+	integrity := "sha512-" + base64.StdEncoding.EncodeToString(tarball[:])
+	predicate := `{"buildDefinition": {"externalParameters": {"workflow": {"repository": "https://github.com/owner/repo"}}}}`
+
+	// 攻击者用自己生成的根证书和日志密钥签名
+	attacker := newTestSigstore(t)
+	bundle := attacker.bundle(testStatement(registry.PredicateSLSAProvenanceV1, tarball[:], predicate), true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/@scope/pkg/1.0.0":
+			w.Write([]byte(`{"name":"@scope/pkg","version":"1.0.0","dist":{"integrity":"` + integrity + `"}}`))
+		case "/-/npm/v1/attestations/@scope/pkg@1.0.0":
+			json.NewEncoder(w).Encode(map[string]any{"attestations": []registry.Attestation{{PredicateType: registry.PredicateSLSAProvenanceV1, Bundle: bundle}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// 默认不信任任何根证书
+	t.Setenv("npm_config_registry", server.URL)
+	if provenance, err := VerifyProvenance(ctx, "@scope/pkg", "1.0.0"); !errors.Is(err, ErrNoTrustRoot) {
+		t.Errorf("Expected ErrNoTrustRoot for self-signed bundle, got %+v, %v", provenance, err)
+	}
+
+	// 配置了真正的信任根时攻击者的证书和日志条目都不被信任
+	trusted := newTestSigstore(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(trusted.root)
+	verifier := NewProvenanceVerifier(registry.NewClient(server.URL))
+	verifier.SetFulcioRoots(roots)
+	verifier.SetRekorKeys(&trusted.rekorKey.PublicKey)
+	var provenanceErr *ProvenanceError
+	if provenance, err := verifier.Verify(ctx, "@scope/pkg", "1.0.0"); !errors.As(err, &provenanceErr) {
+		t.Errorf("Expected ProvenanceError for self-signed bundle, got %+v, %v", provenance, err)
+	}
+}
+
+func TestVerifyProvenanceMissing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/pkg/1.0.0" {
+			w.Write([]byte(`{"name":"pkg","version":"1.0.0","dist":{"integrity":"sha512-` + base64.StdEncoding.EncodeToString(make([]byte, sha512.Size)) + `"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	t.Setenv("npm_config_registry", server.URL)
+	_, err := VerifyProvenance(context.Background(), "pkg", "1.0.0")
+	if !errors.Is(err, ErrNoProvenance) {
+		t.Errorf("Expected ErrNoProvenance, got %v", err)
+	}
+}
+
+func TestReadSLSAPredicateV02(t *testing.T) {
+	provenance := &Provenance{PredicateType: registry.PredicateSLSAProvenanceV02}
+	err := readSLSAPredicate(provenance, json.RawMessage(`{
+		"builder": {"id": "https://github.com/actions/runner"},
+		"invocation": {"configSource": {"uri": "git+https://github.com/owner/repo@refs/heads/main", "digest": {"sha1": "abc"}, "entryPoint": ".github/workflows/publish.yml"}},
+		"metadata": {"buildInvocationId": "1-1"}
+	}`))
+	if err != nil {
+		t.Fatalf("readSLSAPredicate() failed: %v", err)
+	}
+	if provenance.Repository != "https://github.com/owner/repo" || provenance.Ref != "refs/heads/main" || provenance.Commit != "abc" ||
+		provenance.Workflow != ".github/workflows/publish.yml" || provenance.InvocationURL != "1-1" {
+		t.Errorf("Unexpected provenance: %+v", provenance)
+	}
+}
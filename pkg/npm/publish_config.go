@@ -0,0 +1,54 @@
+package npm
+
+import (
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+)
+
+// PublishConfig package.json的publishConfig字段
+type PublishConfig = npmiface.PublishConfig
+
+// PublishConfigConflict PublishOptions与publishConfig不一致的项
+type PublishConfigConflict = npmiface.PublishConfigConflict
+
+// MergePublishConfig 用package.json的publishConfig补全options中没有设置的registry、access和tag
+//
+// 两边都设置且不同时以options为准，与npm 9以后命令行参数优先于publishConfig的行为一致，
+// 不一致的项作为冲突返回。scope包的registry优先取publishConfig中的"@scope:registry"。
+func MergePublishConfig(pkg *PackageJSON, options PublishOptions) (PublishOptions, []PublishConfigConflict) {
+	data := pkg.GetData()
+	if data == nil || data.PublishConfig == nil {
+		return options, nil
+	}
+	config := data.PublishConfig
+
+	var conflicts []PublishConfigConflict
+	merge := func(field string, option *string, value string, same func(a, b string) bool) {
+		switch {
+		case value == "":
+		case *option == "":
+			*option = value
+		case !same(*option, value):
+			conflicts = append(conflicts, PublishConfigConflict{Field: field, Option: *option, PublishConfig: value})
+		}
+	}
+	equal := func(a, b string) bool { return a == b }
+	merge("registry", &options.Registry, config.RegistryFor(data.Name), sameRegistry)
+	merge("access", &options.Access, config.Access, equal)
+	merge("tag", &options.Tag, config.Tag, equal)
+	return options, conflicts
+}
+
+// sameRegistry 比较registry地址，忽略末尾的/
+func sameRegistry(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+// packageScope 返回@scope/name中的@scope，不是scope包时返回空字符串
+func packageScope(name string) string {
+	if scope, _, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		return scope
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+func TestPublishConfigJSON(t *testing.T) {
+	var config PublishConfig
+	data := `{"registry":"https://registry.example.com/","access":"restricted","@org:registry":"https://npm.org.example.com/"}`
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if config.Access != "restricted" || config.ScopeRegistries["@org"] != "https://npm.org.example.com/" {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+	if got := config.RegistryFor("@org/pkg"); got != "https://npm.org.example.com/" {
+		t.Errorf("RegistryFor(@org/pkg) = %q", got)
+	}
+	if got := config.RegistryFor("@other/pkg"); got != "https://registry.example.com/" {
+		t.Errorf("RegistryFor(@other/pkg) = %q", got)
+	}
+
+	encoded, err := json.Marshal(&config)
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	var decoded PublishConfig
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded.ScopeRegistries["@org"] != "https://npm.org.example.com/" {
+		t.Errorf("Round trip lost scope registry: %s", encoded)
+	}
+}
+
+func TestMergePublishConfig(t *testing.T) {
+	load := func(content string) *PackageJSON {
+		path := filepath.Join(t.TempDir(), "package.json")
+		writeTestFile(t, path, content)
+		pkg := NewPackageJSON(path)
+		if err := pkg.Load(); err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		return pkg
+	}
+
+	pkg := load(`{"name":"@org/pkg","version":"1.0.0","publishConfig":{"registry":"https://registry.example.com/","@org:registry":"https://npm.org.example.com/","access":"restricted","tag":"next"}}`)
+	options, conflicts := MergePublishConfig(pkg, PublishOptions{})
+	if options.Registry != "https://npm.org.example.com/" || options.Access != "restricted" || options.Tag != "next" || len(conflicts) != 0 {
+		t.Errorf("Unexpected merge: %+v %v", options, conflicts)
+	}
+
+	// 末尾的/不算冲突，其他不一致以options为准
+	options, conflicts = MergePublishConfig(pkg, PublishOptions{Registry: "https://npm.org.example.com", Access: "public"})
+	if options.Registry != "https://npm.org.example.com" || options.Access != "public" || options.Tag != "next" {
+		t.Errorf("Expected options to take precedence, got %+v", options)
+	}
+	if len(conflicts) != 1 || conflicts[0].Field != "access" || conflicts[0].Option != "public" || conflicts[0].PublishConfig != "restricted" {
+		t.Errorf("Unexpected conflicts: %+v", conflicts)
+	}
+
+	options, conflicts = MergePublishConfig(load(`{"name":"pkg","version":"1.0.0"}`), PublishOptions{Tag: "beta"})
+	if options.Tag != "beta" || options.Registry != "" || conflicts != nil {
+		t.Errorf("Expected options unchanged without publishConfig, got %+v %v", options, conflicts)
+	}
+	if options, _ := MergePublishConfig(NewPackageJSON(""), PublishOptions{Tag: "beta"}); options.Tag != "beta" {
+		t.Errorf("Expected options unchanged for unloaded package.json, got %+v", options)
+	}
+}
+
+// publishExecutor 模拟npm pack和npm publish，记录publish的参数
+type publishExecutor struct {
+	t       *testing.T
+	name    string
+	publish []string
+}
+
+func (e *publishExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	if options.Args[0] != "pack" {
+		e.publish = options.Args
+		return &utils.ExecuteResult{Success: true}, nil
+	}
+
+	dir := options.Args[slices.Index(options.Args, "--pack-destination")+1]
+	filename := strings.NewReplacer("@", "", "/", "-").Replace(e.name) + "-1.0.0.tgz"
+	data := buildTestTarball(e.t, []testTarballFile{
+		{name: "package/package.json", mode: 0644, data: fmt.Sprintf(`{"name":%q,"version":"1.0.0"}`, e.name)},
+	}, time.Now(), 3)
+	if err := os.WriteFile(filepath.Join(dir, filename), data, 0644); err != nil {
+		return nil, err
+	}
+	stdout := fmt.Sprintf(`[{"name":%q,"version":"1.0.0","filename":%q,"size":%d,"files":[]}]`, e.name, filename, len(data))
+	return &utils.ExecuteResult{Success: true, Stdout: stdout}, nil
+}
+
+func TestClientPublishUsesPublishConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{
+  "name": "@org/pkg",
+  "version": "1.0.0",
+  "publishConfig": {"@org:registry": "https://npm.org.example.com/", "access": "restricted"}
+}`)
+
+	executor := &publishExecutor{t: t, name: "@org/pkg"}
+	c, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	var events []PublishEvent
+	c.Events().Subscribe(func(event Event) {
+		if publish, ok := event.(PublishEvent); ok {
+			events = append(events, publish)
+		}
+	})
+
+	if err := c.Publish(context.Background(), PublishOptions{WorkingDir: dir, Tag: "next", Access: "public"}); err != nil {
+		t.Fatalf("Publish() failed: %v", err)
+	}
+
+	args := strings.Join(executor.publish, " ")
+	for _, expected := range []string{"--tag next", "--access public", "--registry https://npm.org.example.com/", "--@org:registry=https://npm.org.example.com/"} {
+		if !strings.Contains(args, expected) {
+			t.Errorf("Expected %q in publish args: %s", expected, args)
+		}
+	}
+	if len(events) == 0 {
+		t.Fatal("Expected publish events")
+	}
+	last := events[len(events)-1]
+	if last.Stage != PublishStageDone || last.Registry != "https://npm.org.example.com/" {
+		t.Errorf("Unexpected final event: %+v", last)
+	}
+	if len(last.Conflicts) != 1 || last.Conflicts[0].Field != "access" {
+		t.Errorf("Expected access conflict, got %+v", last.Conflicts)
+	}
+}
+
+func TestRegistryPublisherRejectsPublishConfigRegistry(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{
+  "name": "@org/pkg",
+  "version": "1.0.0",
+  "publishConfig": {"registry": "https://npm.org.example.com/"}
+}`)
+
+	publisher := NewRegistryPublisher(&MockClient{}, registry.NewClient("https://registry.example.com/"))
+	_, err := publisher.Publish(context.Background(), PublishOptions{WorkingDir: dir})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "https://npm.org.example.com/") {
+		t.Errorf("Expected validation error for publishConfig registry, got %v", err)
+	}
+}
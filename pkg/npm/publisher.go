@@ -0,0 +1,33 @@
+package npm
+
+import (
+	"context"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+// Publisher 发布包的常用流程，普通发布由Client.Publish完成，canary发布由CanaryPublisher完成
+type Publisher struct {
+	client   Client
+	registry *registry.Client
+}
+
+// NewPublisher 创建发布器，registryClient只在清理旧的canary版本时使用，可以为nil
+func NewPublisher(npmClient Client, registryClient *registry.Client) *Publisher {
+	return &Publisher{
+		client:   npmClient,
+		registry: registryClient,
+	}
+}
+
+// Publish 发布包，合并publishConfig的规则见Client.Publish
+func (p *Publisher) Publish(ctx context.Context, options PublishOptions) error {
+	return p.client.Publish(ctx, options)
+}
+
+// PublishCanary 以<version>-canary.<sha>.<timestamp>格式的唯一预发布版本发布，返回的Spec可以直接用于npm install
+//
+// 版本以options.Tag（默认与PreID相同）发布，不会成为latest；版本号的计算见CanaryVersion。
+func (p *Publisher) PublishCanary(ctx context.Context, options CanaryOptions) (*CanaryResult, error) {
+	return NewCanaryPublisher(p.client, p.registry).Publish(ctx, options)
+}
@@ -0,0 +1,91 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// QueryResult npm query匹配到的包
+type QueryResult = npmiface.QueryResult
+
+// Query 运行npm query，返回与依赖选择器匹配的包
+//
+// 选择器语法见npm文档，例如 ":attr(scripts, [postinstall])" 查找定义了postinstall脚本的包。
+// 与Explain一样在当前目录运行。
+func (c *client) Query(ctx context.Context, selector string) ([]QueryResult, error) {
+	executeOptions, err := c.queryCommand(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return nil, NewNpmError("query", selector, -1, "", "", err)
+		}
+		return nil, NewNpmError("query", selector, result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	return parseQueryJSON([]byte(result.Stdout))
+}
+
+// queryCommand 构造npm query的执行选项
+func (c *client) queryCommand(selector string) (utils.ExecuteOptions, error) {
+	if selector == "" {
+		return utils.ExecuteOptions{}, NewValidationError("selector", selector, "selector cannot be empty")
+	}
+
+	return utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          []string{"query", selector},
+		CaptureOutput: true,
+		Timeout:       time.Minute,
+	}, nil
+}
+
+// parseQueryJSON 解析npm query的输出
+func parseQueryJSON(data []byte) ([]QueryResult, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse query output: %w", err)
+	}
+
+	results := make([]QueryResult, 0, len(raw))
+	for _, item := range raw {
+		var result QueryResult
+		if err := json.Unmarshal(item, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse query output: %w", err)
+		}
+		result.License = parseLicenseField(item)
+		result.Raw = item
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// parseLicenseField 读取license字段，兼容字符串和{"type": ...}两种形式
+func parseLicenseField(data []byte) string {
+	var manifest struct {
+		License json.RawMessage `json:"license"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil || len(manifest.License) == 0 {
+		return ""
+	}
+
+	var license string
+	if err := json.Unmarshal(manifest.License, &license); err == nil {
+		return license
+	}
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(manifest.License, &object); err == nil {
+		return object.Type
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+const queryFixture = `[
+  {
+    "name": "app",
+    "version": "1.0.0",
+    "scripts": {"postinstall": "node setup.js"},
+    "dependencies": {"esbuild": "^0.20.0"},
+    "_id": "app@1.0.0",
+    "pkgid": "app@1.0.0",
+    "location": "",
+    "path": "/tmp/app",
+    "realpath": "/tmp/app",
+    "resolved": null,
+    "from": [],
+    "to": ["node_modules/esbuild"],
+    "dev": false,
+    "inBundle": false,
+    "deduped": false,
+    "overridden": false,
+    "queryContext": {}
+  },
+  {
+    "name": "esbuild",
+    "version": "0.20.2",
+    "license": {"type": "MIT", "url": "https://opensource.org/licenses/MIT"},
+    "scripts": {"postinstall": "node install.js"},
+    "bin": {"esbuild": "bin/esbuild"},
+    "_id": "esbuild@0.20.2",
+    "pkgid": "esbuild@0.20.2",
+    "location": "node_modules/esbuild",
+    "path": "/tmp/app/node_modules/esbuild",
+    "resolved": "https://registry.npmjs.org/esbuild/-/esbuild-0.20.2.tgz",
+    "integrity": "sha512-abc",
+    "from": [""],
+    "to": [],
+    "dev": true,
+    "inBundle": false,
+    "deduped": false,
+    "overridden": false,
+    "queryContext": {}
+  }
+]`
+
+func TestParseQueryJSON(t *testing.T) {
+	results, err := parseQueryJSON([]byte(queryFixture))
+	if err != nil {
+		t.Fatalf("parseQueryJSON() failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	root := results[0]
+	if !root.IsRoot() || root.Name != "app" || root.Scripts["postinstall"] != "node setup.js" || root.Resolved != "" {
+		t.Errorf("Unexpected root result: %+v", root)
+	}
+
+	esbuild := results[1]
+	if esbuild.IsRoot() || !esbuild.Dev || esbuild.License != "MIT" || esbuild.Integrity != "sha512-abc" {
+		t.Errorf("Unexpected esbuild result: %+v", esbuild)
+	}
+	if len(esbuild.From) != 1 || esbuild.From[0] != "" {
+		t.Errorf("Expected esbuild to be required by the root, got %v", esbuild.From)
+	}
+
+	// 未解析的字段可以从Raw中读取
+	var manifest struct {
+		Bin map[string]string `json:"bin"`
+	}
+	if err := json.Unmarshal(esbuild.Raw, &manifest); err != nil || manifest.Bin["esbuild"] != "bin/esbuild" {
+		t.Errorf("Expected bin in raw result, got %v, %v", manifest.Bin, err)
+	}
+
+	if results, err := parseQueryJSON([]byte("[]")); err != nil || len(results) != 0 {
+		t.Errorf("parseQueryJSON([]) = %v, %v", results, err)
+	}
+	if _, err := parseQueryJSON([]byte("npm error")); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+func TestClientQuery(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.Query(ctx, ""); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for empty selector, got %v", err)
+	}
+
+	if !client.IsAvailable(ctx) {
+		t.Skip("npm not available")
+	}
+	results, err := client.Query(ctx, ":root")
+	if err != nil {
+		t.Fatalf("Query() failed: %v", err)
+	}
+	if len(results) != 1 || !results[0].IsRoot() {
+		t.Errorf("Expected the root project, got %+v", results)
+	}
+}
@@ -0,0 +1,298 @@
+package npm
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecommendationKind 建议操作的类型
+type RecommendationKind string
+
+const (
+	RecommendSecurityFix       RecommendationKind = "security-fix"       // 升级包以修复安全公告
+	RecommendNodeUpgrade       RecommendationKind = "node-upgrade"       // 升级已经或即将停止维护的Node.js
+	RecommendReplaceDeprecated RecommendationKind = "replace-deprecated" // 替换或升级弃用的依赖
+	RecommendLicenseReview     RecommendationKind = "license-review"     // 检查使用被禁止许可证的包
+	RecommendMajorUpgrade      RecommendationKind = "major-upgrade"      // 直接依赖落后一个或多个主版本
+)
+
+// recommendationOrder 同一严重程度内各类型的顺序
+var recommendationOrder = []RecommendationKind{
+	RecommendSecurityFix, RecommendNodeUpgrade, RecommendReplaceDeprecated, RecommendLicenseReview, RecommendMajorUpgrade,
+}
+
+// Recommendation 一条建议操作
+type Recommendation struct {
+	Kind     RecommendationKind `json:"kind"`
+	Severity Severity           `json:"severity"` // 优先级，沿用审计的严重程度
+	Package  string             `json:"package,omitempty"`
+	Current  string             `json:"current,omitempty"`
+	Target   string             `json:"target,omitempty"` // 建议的版本、替代包或Node.js主版本，为空表示没有可用的修复
+	Title    string             `json:"title"`
+	IDs      []string           `json:"ids,omitempty"` // 修复的安全公告编号
+	Links    []string           `json:"links,omitempty"`
+}
+
+// RecommendationFeed 项目的建议操作列表，按优先级从高到低排序
+type RecommendationFeed struct {
+	Project         string           `json:"project"`
+	GeneratedAt     time.Time        `json:"generated_at"`
+	Score           float64          `json:"score"` // 同一批数据计算的健康度
+	Grade           string           `json:"grade"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+// nodeReleasesURL Node.js版本维护计划
+const nodeReleasesURL = "https://nodejs.org/en/about/previous-releases"
+
+// replacementPattern 从弃用说明中提取替代包，例如"use node-fetch instead"、"renamed to @scope/pkg"
+var replacementPattern = regexp.MustCompile("(?i:use|switch to|replaced by|moved to|renamed to|migrate to)\\s+[`'\"]?(@?[a-z0-9][a-z0-9._-]*(?:/[a-z0-9][a-z0-9._-]*)?)")
+
+// replacementStopWords 跟在use等词后但不是包名的词
+var replacementStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "it": true, "this": true, "that": true, "version": true,
+	"versions": true, "native": true, "node": true, "instead": true, "latest": true, "v": true,
+}
+
+// Recommendations 收集与Health相同的数据，生成按优先级排序的建议操作
+//
+// 通知机器人可以直接把结果序列化为JSON。权重为0的类别不收集数据也不生成建议，
+// 数据获取失败的类别同样跳过。
+func (dm *DependencyManager) Recommendations(ctx context.Context, options HealthOptions) (*RecommendationFeed, error) {
+	input, err := dm.collectHealth(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	project := filepath.Base(dm.workingDir)
+	if err := dm.packageJSON.Load(); err == nil && dm.packageJSON.GetName() != "" {
+		project = dm.packageJSON.GetName()
+	}
+	health := ScoreHealth(input, options)
+	return &RecommendationFeed{
+		Project:         project,
+		GeneratedAt:     time.Now().UTC(),
+		Score:           health.Score,
+		Grade:           health.Grade,
+		Recommendations: BuildRecommendations(input, options),
+	}, nil
+}
+
+// BuildRecommendations 根据已收集的数据生成建议操作
+//
+// 安全修复按npm给出的修复方式合并，优先级为其中最高的严重程度；停止维护的Node.js和被禁止的许可证为high，
+// 即将停止维护的Node.js和弃用的依赖为moderate，落后主版本的依赖为low。次版本和补丁版本的更新不生成建议。
+func BuildRecommendations(input HealthInput, options HealthOptions) []Recommendation {
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	available := func(category HealthCategory) bool {
+		return input.Errors[category] == nil
+	}
+
+	var recommendations []Recommendation
+	if available(HealthSecurity) {
+		recommendations = append(recommendations, securityRecommendations(input.Audit)...)
+	}
+	if available(HealthNode) {
+		if recommendation, ok := nodeRecommendation(input.NodeVersion, now); ok {
+			recommendations = append(recommendations, recommendation)
+		}
+	}
+	if available(HealthDeprecated) {
+		for _, dep := range input.Deprecated {
+			recommendations = append(recommendations, deprecatedRecommendation(dep))
+		}
+	}
+	if available(HealthLicense) {
+		recommendations = append(recommendations, licenseRecommendations(input.Licenses, options.DeniedLicenses)...)
+	}
+	if available(HealthOutdated) {
+		for _, pkg := range input.Outdated {
+			if level, _ := outdatedLevel(pkg.Current, pkg.Latest); level == "major" {
+				recommendations = append(recommendations, Recommendation{
+					Kind:     RecommendMajorUpgrade,
+					Severity: SeverityLow,
+					Package:  pkg.Name,
+					Current:  pkg.Current,
+					Target:   pkg.Latest,
+					Title:    fmt.Sprintf("Upgrade %s from %s to %s", pkg.Name, pkg.Current, pkg.Latest),
+					Links:    []string{packagePageURL(pkg.Name)},
+				})
+			}
+		}
+	}
+
+	slices.SortStableFunc(recommendations, func(a, b Recommendation) int {
+		return cmp.Or(
+			b.Severity.Rank()-a.Severity.Rank(),
+			slices.Index(recommendationOrder, a.Kind)-slices.Index(recommendationOrder, b.Kind),
+			strings.Compare(a.Package, b.Package),
+		)
+	})
+	return recommendations
+}
+
+// securityRecommendations 按修复方式合并审计发现的问题
+func securityRecommendations(report *AuditReport) []Recommendation {
+	if report == nil {
+		return nil
+	}
+
+	byFix := make(map[string]*Recommendation)
+	var keys []string
+	for _, finding := range report.Findings {
+		if len(finding.Advisories) == 0 {
+			// 只通过其他受影响的包间接受影响，由那些包的建议覆盖
+			continue
+		}
+
+		key := finding.Name
+		if finding.Fix.Available {
+			key = finding.Fix.Name + "@" + finding.Fix.Version
+		}
+		recommendation, exists := byFix[key]
+		if !exists {
+			recommendation = &Recommendation{Kind: RecommendSecurityFix, Severity: finding.Severity, Package: finding.Name}
+			if finding.Fix.Available {
+				recommendation.Package = finding.Fix.Name
+				recommendation.Target = finding.Fix.Version
+			}
+			byFix[key] = recommendation
+			keys = append(keys, key)
+		}
+		if finding.Severity.Rank() > recommendation.Severity.Rank() {
+			recommendation.Severity = finding.Severity
+		}
+		for _, advisory := range finding.Advisories {
+			if id := advisory.ID(); !slices.Contains(recommendation.IDs, id) {
+				recommendation.IDs = append(recommendation.IDs, id)
+				if advisory.URL != "" {
+					recommendation.Links = append(recommendation.Links, advisory.URL)
+				}
+			}
+		}
+	}
+
+	recommendations := make([]Recommendation, 0, len(keys))
+	for _, key := range keys {
+		recommendation := byFix[key]
+		ids := strings.Join(recommendation.IDs, ", ")
+		if recommendation.Target == "" {
+			recommendation.Title = fmt.Sprintf("No fix available for %s (%s)", recommendation.Package, ids)
+		} else {
+			recommendation.Title = fmt.Sprintf("Upgrade %s to %s to fix %s", recommendation.Package, recommendation.Target, ids)
+		}
+		recommendations = append(recommendations, *recommendation)
+	}
+	return recommendations
+}
+
+// nodeRecommendation 已经或即将停止维护的Node.js版本建议升级到最旧的仍在维护的LTS版本
+func nodeRecommendation(version string, now time.Time) (Recommendation, bool) {
+	score, issues, err := scoreNode(version, now)
+	if err != nil || score == 100 {
+		return Recommendation{}, false
+	}
+
+	recommendation := Recommendation{
+		Kind:     RecommendNodeUpgrade,
+		Severity: SeverityHigh,
+		Package:  "node",
+		Current:  strconv.Itoa(nodeMajor(version)),
+		Links:    []string{nodeReleasesURL},
+	}
+	if score > 0 {
+		recommendation.Severity = SeverityModerate
+	}
+	if target := supportedNodeLTS(now); target > 0 {
+		recommendation.Target = strconv.Itoa(target)
+		recommendation.Title = fmt.Sprintf("Upgrade Node.js %s to %d: %s", recommendation.Current, target, issues[0])
+	} else {
+		recommendation.Title = fmt.Sprintf("Upgrade Node.js %s: %s", recommendation.Current, issues[0])
+	}
+	return recommendation, true
+}
+
+// supportedNodeLTS 维护时间还剩超过nodeEndOfLifeWarning的最旧LTS主版本，没有时返回0
+func supportedNodeLTS(now time.Time) int {
+	for _, major := range slices.Sorted(maps.Keys(nodeEndOfLife)) {
+		if major%2 != 0 {
+			continue
+		}
+		date, _ := time.Parse(time.DateOnly, nodeEndOfLife[major])
+		if date.Sub(now) >= nodeEndOfLifeWarning {
+			return major
+		}
+	}
+	return 0
+}
+
+// deprecatedRecommendation 弃用说明中提到其他包时建议替换，否则建议升级或替换
+func deprecatedRecommendation(dep DeprecatedDependency) Recommendation {
+	recommendation := Recommendation{
+		Kind:     RecommendReplaceDeprecated,
+		Severity: SeverityModerate,
+		Package:  dep.Name,
+		Current:  dep.Version,
+		Title:    fmt.Sprintf("Replace deprecated %s@%s: %s", dep.Name, dep.Version, dep.Message),
+		Links:    []string{packagePageURL(dep.Name)},
+	}
+	if replacement := deprecationReplacement(dep.Message); replacement != "" && replacement != dep.Name {
+		recommendation.Target = replacement
+		recommendation.Title = fmt.Sprintf("Replace deprecated %s@%s with %s", dep.Name, dep.Version, replacement)
+		recommendation.Links = append(recommendation.Links, packagePageURL(replacement))
+	}
+	return recommendation
+}
+
+// deprecationReplacement 从弃用说明中提取替代包，没有时返回空字符串
+func deprecationReplacement(message string) string {
+	for _, match := range replacementPattern.FindAllStringSubmatch(message, -1) {
+		name := strings.TrimRight(match[1], ".")
+		if !replacementStopWords[name] {
+			return name
+		}
+	}
+	return ""
+}
+
+// licenseRecommendations 使用被禁止许可证的包，按name@version排序
+func licenseRecommendations(licenses map[string]string, denied []string) []Recommendation {
+	deniedSet := make(map[string]bool, len(denied))
+	for _, license := range denied {
+		deniedSet[strings.ToLower(license)] = true
+	}
+
+	var recommendations []Recommendation
+	for _, key := range slices.Sorted(maps.Keys(licenses)) {
+		license := strings.TrimSpace(licenses[key])
+		if license == "" || !licenseDenied(license, deniedSet) {
+			continue
+		}
+		name, version := splitPackageSpec(key)
+		recommendations = append(recommendations, Recommendation{
+			Kind:     RecommendLicenseReview,
+			Severity: SeverityHigh,
+			Package:  name,
+			Current:  version,
+			Title:    fmt.Sprintf("Review %s: denied license %s", key, license),
+			Links:    []string{packagePageURL(name)},
+		})
+	}
+	return recommendations
+}
+
+// packagePageURL 包在npmjs.com上的页面
+func packagePageURL(name string) string {
+	return "https://www.npmjs.com/package/" + name
+}
@@ -0,0 +1,131 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildRecommendations(t *testing.T) {
+	input := HealthInput{
+		Outdated: []OutdatedPackage{
+			{Name: "react", Current: "17.0.2", Latest: "18.2.0"},
+			{Name: "lodash", Current: "4.17.20", Latest: "4.17.21"},
+		},
+		Audit: &AuditReport{Findings: []AuditFinding{
+			{
+				Name:       "minimist",
+				Severity:   SeverityModerate,
+				Advisories: []Advisory{{URL: "https://github.com/advisories/GHSA-xvch-5gv4-984h", Severity: SeverityModerate}},
+				Fix:        AuditFix{Available: true, Name: "mkdirp", Version: "1.0.4", IsSemVerMajor: true},
+			},
+			{
+				Name:       "mkdirp",
+				Severity:   SeverityHigh,
+				Advisories: []Advisory{{URL: "https://github.com/advisories/GHSA-aaaa-bbbb-cccc", Severity: SeverityHigh}},
+				Fix:        AuditFix{Available: true, Name: "mkdirp", Version: "1.0.4", IsSemVerMajor: true},
+			},
+			{Name: "parent", Severity: SeverityHigh, Via: []string{"mkdirp"}},
+			{
+				Name:       "ip",
+				Severity:   SeverityLow,
+				Advisories: []Advisory{{URL: "https://github.com/advisories/GHSA-2p57-rm9w-gvfp", Severity: SeverityLow}},
+			},
+		}},
+		Deprecated: []DeprecatedDependency{
+			{Name: "request", Version: "2.88.2", Message: "request has been deprecated, see https://github.com/request/request/issues/3142"},
+			{Name: "node-uuid", Version: "1.4.8", Message: "Use uuid module instead"},
+		},
+		Licenses:    map[string]string{"@scope/gpl@1.0.0": "GPL-3.0", "mit@1.0.0": "MIT"},
+		NodeVersion: "16",
+	}
+
+	recommendations := BuildRecommendations(input, HealthOptions{
+		DeniedLicenses: []string{"GPL-3.0"},
+		Now:            time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	type summary struct {
+		Kind     RecommendationKind
+		Severity Severity
+		Package  string
+		Target   string
+	}
+	var got []summary
+	for _, r := range recommendations {
+		got = append(got, summary{r.Kind, r.Severity, r.Package, r.Target})
+	}
+	expected := []summary{
+		{RecommendSecurityFix, SeverityHigh, "mkdirp", "1.0.4"},
+		{RecommendNodeUpgrade, SeverityHigh, "node", "22"},
+		{RecommendLicenseReview, SeverityHigh, "@scope/gpl", ""},
+		{RecommendReplaceDeprecated, SeverityModerate, "node-uuid", "uuid"},
+		{RecommendReplaceDeprecated, SeverityModerate, "request", ""},
+		{RecommendSecurityFix, SeverityLow, "ip", ""},
+		{RecommendMajorUpgrade, SeverityLow, "react", "18.2.0"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Unexpected recommendations:\ngot      %+v\nexpected %+v", got, expected)
+	}
+
+	fix := recommendations[0]
+	if fix.Title != "Upgrade mkdirp to 1.0.4 to fix GHSA-xvch-5gv4-984h, GHSA-aaaa-bbbb-cccc" || len(fix.Links) != 2 {
+		t.Errorf("Unexpected security fix: %+v", fix)
+	}
+	if title := recommendations[5].Title; title != "No fix available for ip (GHSA-2p57-rm9w-gvfp)" {
+		t.Errorf("Unexpected title for unfixable finding: %s", title)
+	}
+	if node := recommendations[1]; node.Current != "16" || node.Links[0] != nodeReleasesURL {
+		t.Errorf("Unexpected node recommendation: %+v", node)
+	}
+}
+
+func TestBuildRecommendationsSkipsUnavailableCategories(t *testing.T) {
+	input := HealthInput{
+		Audit:  &AuditReport{Findings: []AuditFinding{{Name: "x", Severity: SeverityCritical, Advisories: []Advisory{{Source: 1}}}}},
+		Errors: map[HealthCategory]error{HealthSecurity: errors.New("audit failed")},
+	}
+	if recommendations := BuildRecommendations(input, HealthOptions{}); len(recommendations) != 0 {
+		t.Errorf("Expected no recommendations, got %+v", recommendations)
+	}
+}
+
+func TestDeprecationReplacement(t *testing.T) {
+	tests := map[string]string{
+		"Use uuid module instead":                               "uuid",
+		"Package renamed to @babel/plugin-transform-class.":     "@babel/plugin-transform-class",
+		"this package has been replaced by `node-fetch`":        "node-fetch",
+		"Please use the native fetch API":                       "",
+		"use String.prototype.padStart()":                       "",
+		"request has been deprecated, see https://example.com/": "",
+	}
+	for message, expected := range tests {
+		if got := deprecationReplacement(message); got != expected {
+			t.Errorf("deprecationReplacement(%q) = %q, expected %q", message, got, expected)
+		}
+	}
+}
+
+func TestDependencyManagerRecommendations(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name":"app","version":"1.0.0","engines":{"node":">=14"}}`)
+
+	client := NewMockClient()
+	client.outdated = []OutdatedPackage{{Name: "react", Current: "17.0.2", Latest: "18.2.0"}}
+	dm, _ := NewDependencyManager(client, dir)
+
+	feed, err := dm.Recommendations(context.Background(), HealthOptions{Now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Recommendations() failed: %v", err)
+	}
+	if feed.Project != "app" || feed.GeneratedAt.IsZero() || feed.Grade == "" {
+		t.Errorf("Unexpected feed: %+v", feed)
+	}
+	if len(feed.Recommendations) != 2 || feed.Recommendations[0].Kind != RecommendNodeUpgrade ||
+		feed.Recommendations[1].Kind != RecommendMajorUpgrade {
+		t.Errorf("Unexpected recommendations: %+v", feed.Recommendations)
+	}
+}
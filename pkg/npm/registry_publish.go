@@ -0,0 +1,176 @@
+package npm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+// RegistryPublisher 打包后直接调用registry API发布，不经过npm publish
+//
+// 与Client.Publish相比可以报告实际的上传字节进度和registry处理阶段。
+// 打包由Client.Pack完成，registry地址和认证令牌由registry.Client决定，
+// PublishOptions.Registry不生效；package.json的publishConfig指定了其他registry时返回错误。
+type RegistryPublisher struct {
+	client    Client
+	registry  *registry.Client
+	runScript func(ctx context.Context, workingDir, script string, env map[string]string) error
+}
+
+// NewRegistryPublisher 创建直接发布器
+//
+// npmClient为NewClient创建的客户端时，prepublishOnly、publish和postpublish脚本
+// 按npm的顺序运行；其他Client实现不运行这些脚本。
+func NewRegistryPublisher(npmClient Client, registryClient *registry.Client) *RegistryPublisher {
+	publisher := &RegistryPublisher{
+		client:   npmClient,
+		registry: registryClient,
+	}
+	if c, ok := npmClient.(*client); ok {
+		publisher.runScript = c.runLifecycleScript
+	}
+	return publisher
+}
+
+// Publish 打包并发布，过程中在客户端的事件总线上发送PublishEvent
+func (p *RegistryPublisher) Publish(ctx context.Context, options PublishOptions) (*registry.PublishResponse, error) {
+	events := p.client.Events()
+	event := PublishEvent{DryRun: options.DryRun}
+	emit := func(stage PublishStage) {
+		event.Stage = stage
+		event.Time = time.Now()
+		events.Emit(event)
+	}
+	fail := func(err error) (*registry.PublishResponse, error) {
+		event.Err = err
+		emit(PublishStageFailed)
+		return nil, err
+	}
+
+	pkg := NewPackageJSON(filepath.Join(options.WorkingDir, "package.json"))
+	if err := pkg.Load(); err != nil {
+		pkg = NewPackageJSON("")
+	}
+	options, event.Conflicts = MergePublishConfig(pkg, options)
+	event.Registry = p.registry.URL()
+
+	emit(PublishStagePacking)
+
+	// 包指定了其他registry时拒绝发布，避免私有包被发布到registry客户端指向的公共registry
+	if data := pkg.GetData(); data != nil && data.PublishConfig != nil {
+		if target := data.PublishConfig.RegistryFor(data.Name); target != "" && !sameRegistry(target, p.registry.URL()) {
+			return fail(NewValidationError("registry", target, "publishConfig registry differs from the registry client "+p.registry.URL()))
+		}
+	}
+	if err := p.runLifecycle(ctx, pkg, options, "prepublishOnly"); err != nil {
+		return fail(err)
+	}
+
+	tempDir, err := os.MkdirTemp("", "npm-publish-")
+	if err != nil {
+		return fail(fmt.Errorf("failed to create temp directory: %w", err))
+	}
+	defer os.RemoveAll(tempDir)
+
+	packed, err := p.client.Pack(ctx, PackOptions{
+		WorkingDir:      options.WorkingDir,
+		PackDestination: tempDir,
+		Normalize:       true,
+		Env:             options.Env,
+		UserConfig:      options.UserConfig,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	tarball, err := os.ReadFile(packed.Path)
+	if err != nil {
+		return fail(fmt.Errorf("failed to read tarball: %w", err))
+	}
+	manifest, err := readTarballManifest(tarball)
+	if err != nil {
+		return fail(err)
+	}
+	event.Package = packed.Name
+	event.Version = packed.Version
+	event.Tarball = packed.Filename
+
+	request := registry.PublishRequest{
+		Manifest: manifest,
+		Tarball:  tarball,
+		Tag:      options.Tag,
+		Access:   options.Access,
+		OTP:      options.OTP,
+		DryRun:   options.DryRun,
+	}
+	if options.ProvenanceFile != "" {
+		request.ProvenanceBundle, err = os.ReadFile(options.ProvenanceFile)
+		if err != nil {
+			return fail(fmt.Errorf("failed to read provenance bundle: %w", err))
+		}
+	}
+
+	// 进度按请求体计算，其中tarball经过base64编码
+	request.Progress = func(sent, total int64) {
+		event.BytesSent = sent
+		event.BytesTotal = total
+		emit(PublishStageUploading)
+		if sent == total {
+			emit(PublishStageProcessing)
+		}
+	}
+
+	response, err := p.registry.Publish(ctx, request)
+	if err != nil {
+		return fail(err)
+	}
+	event.BytesSent = response.BodySize
+	event.BytesTotal = response.BodySize
+
+	for _, script := range []string{"publish", "postpublish"} {
+		if err := p.runLifecycle(ctx, pkg, options, script); err != nil {
+			return fail(err)
+		}
+	}
+
+	emit(PublishStageDone)
+	return response, nil
+}
+
+// runLifecycle 脚本存在时运行
+func (p *RegistryPublisher) runLifecycle(ctx context.Context, pkg *PackageJSON, options PublishOptions, script string) error {
+	if p.runScript == nil || !pkg.HasScript(script) {
+		return nil
+	}
+	return p.runScript(ctx, options.WorkingDir, script, commandEnv(options.Env, options.UserConfig))
+}
+
+// readTarballManifest 读取tarball中的package/package.json
+func readTarballManifest(tarball []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tarball: %w", err)
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("package.json not found in tarball")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tarball: %w", err)
+		}
+		if header.Name == "package/package.json" {
+			return io.ReadAll(reader)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+func TestRegistryPublisherPublish(t *testing.T) {
+	c, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if !c.IsAvailable(ctx) {
+		t.Skip("npm not available")
+	}
+
+	var received struct {
+		Name     string                     `json:"name"`
+		Versions map[string]json.RawMessage `json:"versions"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("npm-otp") != "654321" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"otp required"}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	writeTestFile(t, filepath.Join(tempDir, "package.json"), `{
+  "name": "registry-publish-test",
+  "version": "2.0.0",
+  "scripts": {
+    "postpublish": "node -e \"require('fs').writeFileSync('postpublish.txt', '')\""
+  }
+}`)
+	writeTestFile(t, filepath.Join(tempDir, "index.js"), "module.exports = 1\n")
+
+	var stages []PublishStage
+	var last PublishEvent
+	c.Events().Subscribe(func(event Event) {
+		if publish, ok := event.(PublishEvent); ok {
+			if len(stages) == 0 || stages[len(stages)-1] != publish.Stage {
+				stages = append(stages, publish.Stage)
+			}
+			last = publish
+		}
+	})
+
+	publisher := NewRegistryPublisher(c, registry.NewClient(server.URL))
+	response, err := publisher.Publish(ctx, PublishOptions{WorkingDir: tempDir, OTP: "654321"})
+	if err != nil {
+		t.Fatalf("Publish() failed: %v", err)
+	}
+
+	if response.ID != "registry-publish-test@2.0.0" || response.StatusCode != http.StatusCreated {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	if received.Name != "registry-publish-test" || received.Versions["2.0.0"] == nil {
+		t.Errorf("Unexpected document received: %+v", received)
+	}
+
+	expected := []PublishStage{PublishStagePacking, PublishStageUploading, PublishStageProcessing, PublishStageDone}
+	if len(stages) != len(expected) {
+		t.Fatalf("Stages = %v, expected %v", stages, expected)
+	}
+	for i := range expected {
+		if stages[i] != expected[i] {
+			t.Fatalf("Stages = %v, expected %v", stages, expected)
+		}
+	}
+	if last.BytesSent != response.BodySize || last.Percent() != 100 {
+		t.Errorf("Unexpected final progress: %+v", last)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "postpublish.txt")); err != nil {
+		t.Errorf("Expected postpublish script to run: %v", err)
+	}
+
+	// 缺少一次性密码时返回registry错误并发送failed事件
+	stages = nil
+	_, err = publisher.Publish(ctx, PublishOptions{WorkingDir: tempDir})
+	var registryErr *registry.Error
+	if !errors.As(err, &registryErr) || registryErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected registry error, got %v", err)
+	}
+	if len(stages) == 0 || stages[len(stages)-1] != PublishStageFailed {
+		t.Errorf("Expected failed stage, got %v", stages)
+	}
+}
+
+func TestReadTarballManifest(t *testing.T) {
+	if _, err := readTarballManifest([]byte("not a tarball")); err == nil {
+		t.Error("Expected error for invalid tarball")
+	}
+}
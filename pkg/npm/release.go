@@ -0,0 +1,486 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/vcs"
+)
+
+// ErrNothingToRelease 上次发布之后没有需要发布的提交
+var ErrNothingToRelease = errors.New("no releasable commits since last release")
+
+// ReleaseBump 版本提升级别
+type ReleaseBump string
+
+const (
+	BumpNone  ReleaseBump = "none"
+	BumpPatch ReleaseBump = "patch"
+	BumpMinor ReleaseBump = "minor"
+	BumpMajor ReleaseBump = "major"
+)
+
+// ConventionalCommit 按Conventional Commits规范解析的提交
+type ConventionalCommit struct {
+	Hash         string `json:"hash,omitempty"`
+	Type         string `json:"type"`
+	Scope        string `json:"scope,omitempty"`
+	Description  string `json:"description"`
+	Body         string `json:"body,omitempty"`
+	Breaking     bool   `json:"breaking,omitempty"`
+	BreakingNote string `json:"breaking_note,omitempty"` // BREAKING CHANGE脚注的内容，只用!标记时为Description
+}
+
+// Bump 提交对应的版本提升级别：不兼容修改为major，feat为minor，fix和perf为patch
+func (c ConventionalCommit) Bump() ReleaseBump {
+	switch {
+	case c.Breaking:
+		return BumpMajor
+	case c.Type == "feat":
+		return BumpMinor
+	case c.Type == "fix" || c.Type == "perf":
+		return BumpPatch
+	}
+	return BumpNone
+}
+
+var conventionalHeader = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: (.+)$`)
+
+// ParseConventionalCommit 解析提交标题和正文，不符合Conventional Commits规范时返回false
+func ParseConventionalCommit(subject, body string) (ConventionalCommit, bool) {
+	match := conventionalHeader.FindStringSubmatch(strings.TrimSpace(subject))
+	if match == nil {
+		return ConventionalCommit{}, false
+	}
+	commit := ConventionalCommit{
+		Type:        strings.ToLower(match[1]),
+		Scope:       match[2],
+		Description: strings.TrimSpace(match[4]),
+		Body:        strings.TrimSpace(body),
+		Breaking:    match[3] == "!",
+	}
+
+	// BREAKING CHANGE脚注到段落结束为止
+	for _, paragraph := range strings.Split(commit.Body, "\n\n") {
+		for _, prefix := range []string{"BREAKING CHANGE:", "BREAKING-CHANGE:"} {
+			if index := strings.Index(paragraph, prefix); index >= 0 && (index == 0 || paragraph[index-1] == '\n') {
+				commit.Breaking = true
+				commit.BreakingNote = strings.TrimSpace(paragraph[index+len(prefix):])
+			}
+		}
+	}
+	if commit.Breaking && commit.BreakingNote == "" {
+		commit.BreakingNote = commit.Description
+	}
+	return commit, true
+}
+
+// NextVersion 根据提升级别计算下一个版本
+//
+// 1.0.0之前的版本中不兼容修改只提升minor，新功能只提升patch。当前为预发布版本时与semver inc一致，
+// 只有提升级别高于预发布版本已经包含的提升时才提升基础版本：1.3.0-beta.0的minor和patch提升
+// 仍然是1.3.0，major提升为2.0.0。preid不为空时发布预发布版本：基础版本不变且已经是相同标识的
+// 预发布版本时递增序号，否则为基础版本加上<preid>.0；preid为空时发布正式版本。
+// bump为BumpNone时返回当前版本。
+func NextVersion(current string, bump ReleaseBump, preid string) (string, error) {
+	v, err := semver.Parse(current)
+	if err != nil {
+		return "", NewValidationError("version", current, err.Error())
+	}
+	if preid != "" && !isPrereleaseIdentifier(preid) {
+		return "", NewValidationError("pre_id", preid, "must contain only alphanumerics, hyphens and dots")
+	}
+	if bump == BumpNone {
+		return v.String(), nil
+	}
+
+	if v.Major == 0 {
+		switch bump {
+		case BumpMajor:
+			bump = BumpMinor
+		case BumpMinor:
+			bump = BumpPatch
+		}
+	}
+
+	if len(v.Prerelease) > 0 && prereleaseImplies(v, bump) {
+		if preid == "" {
+			return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch), nil
+		}
+		prefix := strings.Split(preid, ".")
+		if len(v.Prerelease) == len(prefix)+1 && strings.Join(v.Prerelease[:len(prefix)], ".") == preid {
+			if n, err := strconv.Atoi(v.Prerelease[len(prefix)]); err == nil {
+				return fmt.Sprintf("%d.%d.%d-%s.%d", v.Major, v.Minor, v.Patch, preid, n+1), nil
+			}
+		}
+		return fmt.Sprintf("%d.%d.%d-%s.0", v.Major, v.Minor, v.Patch, preid), nil
+	}
+
+	switch bump {
+	case BumpMajor:
+		v.Major, v.Minor, v.Patch = v.Major+1, 0, 0
+	case BumpMinor:
+		v.Minor, v.Patch = v.Minor+1, 0
+	case BumpPatch:
+		v.Patch++
+	default:
+		return "", NewValidationError("bump", string(bump), "must be none, patch, minor or major")
+	}
+	version := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if preid != "" {
+		version += "-" + preid + ".0"
+	}
+	return version, nil
+}
+
+// prereleaseImplies 预发布版本的基础版本是否已经包含bump级别的提升
+//
+// 例如2.0.0-beta.0已经是major提升，1.3.0-beta.0已经是minor提升，任何预发布版本都已经是patch提升。
+func prereleaseImplies(v *semver.Version, bump ReleaseBump) bool {
+	switch bump {
+	case BumpMajor:
+		return v.Minor == 0 && v.Patch == 0
+	case BumpMinor:
+		return v.Patch == 0
+	case BumpPatch:
+		return true
+	}
+	return false
+}
+
+// ReleaseOptions 发布流程选项
+type ReleaseOptions struct {
+	WorkingDir    string          `json:"working_dir,omitempty"`
+	TagPrefix     string          `json:"tag_prefix,omitempty"`     // 标签前缀，默认v
+	PreID         string          `json:"pre_id,omitempty"`         // 预发布标识，例如beta，为空时发布正式版本
+	Version       string          `json:"version,omitempty"`        // 指定发布的版本，不根据提交计算
+	ChangelogFile string          `json:"changelog_file,omitempty"` // 更新日志文件，默认CHANGELOG.md
+	SkipChangelog bool            `json:"skip_changelog,omitempty"` // 不写入更新日志文件
+	SkipTag       bool            `json:"skip_tag,omitempty"`       // 不创建标签
+	Push          bool            `json:"push,omitempty"`           // 推送当前分支和标签
+	Remote        string          `json:"remote,omitempty"`         // 推送的远程仓库，默认origin
+	Publish       *PublishOptions `json:"publish,omitempty"`        // 不为nil时提交后用客户端发布
+	DryRun        bool            `json:"dry_run,omitempty"`        // 只计算版本和更新日志，不修改仓库
+}
+
+// ReleaseResult 发布结果
+type ReleaseResult struct {
+	Name            string               `json:"name"`
+	PreviousVersion string               `json:"previous_version"`
+	Version         string               `json:"version"`
+	PreviousTag     string               `json:"previous_tag,omitempty"` // 上次发布的标签，没有时从第一个提交开始
+	Tag             string               `json:"tag,omitempty"`
+	Bump            ReleaseBump          `json:"bump"`
+	Commits         []ConventionalCommit `json:"commits"`           // 上次发布之后符合规范的提交，从新到旧
+	Ignored         int                  `json:"ignored,omitempty"` // 不符合规范的提交数量
+	Changelog       string               `json:"changelog"`         // 本次发布的更新日志片段
+	Files           []string             `json:"files,omitempty"`   // 修改的文件
+	Commit          string               `json:"commit,omitempty"`
+	Pushed          bool                 `json:"pushed,omitempty"`
+	Published       bool                 `json:"published,omitempty"`
+}
+
+// ReleaseRepository 发布流程需要的仓库操作
+type ReleaseRepository interface {
+	vcs.VCS
+	vcs.History
+}
+
+// Releaser 基于Conventional Commits的发布流程
+//
+// 读取上次发布标签之后的提交，计算下一个版本，更新package.json和锁文件中的版本，
+// 在更新日志开头加入本次发布的内容，提交并打标签，然后按选项推送和发布。
+// package.json以文本方式修改，保留原有格式和键顺序；发布通过客户端执行。
+type Releaser struct {
+	client Client
+	repo   ReleaseRepository
+	now    func() time.Time
+}
+
+// NewReleaser 创建发布器，repo通常为vcs.NewGit(workingDir)
+func NewReleaser(npmClient Client, repo ReleaseRepository) *Releaser {
+	return &Releaser{
+		client: npmClient,
+		repo:   repo,
+		now:    time.Now,
+	}
+}
+
+// Plan 计算下一个版本和更新日志，不修改仓库
+func (r *Releaser) Plan(ctx context.Context, options ReleaseOptions) (*ReleaseResult, error) {
+	if options.TagPrefix == "" {
+		options.TagPrefix = "v"
+	}
+
+	pkg := NewPackageJSON(filepath.Join(options.WorkingDir, "package.json"))
+	if err := pkg.Load(); err != nil {
+		return nil, err
+	}
+	result := &ReleaseResult{
+		Name:            pkg.GetName(),
+		PreviousVersion: pkg.GetVersion(),
+		Bump:            BumpNone,
+		Commits:         []ConventionalCommit{},
+	}
+	if result.PreviousVersion == "" {
+		result.PreviousVersion = "0.0.0"
+	}
+
+	previousTag, err := r.repo.LatestTag(ctx, options.TagPrefix+"*")
+	if err != nil && !errors.Is(err, vcs.ErrNoTag) {
+		return nil, err
+	}
+	result.PreviousTag = previousTag
+
+	history, err := r.repo.Log(ctx, previousTag, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range history {
+		commit, ok := ParseConventionalCommit(entry.Subject, entry.Body)
+		if !ok {
+			result.Ignored++
+			continue
+		}
+		commit.Hash = entry.Hash
+		result.Commits = append(result.Commits, commit)
+		result.Bump = higherBump(result.Bump, commit.Bump())
+	}
+
+	if options.Version != "" {
+		v, err := semver.Parse(options.Version)
+		if err != nil {
+			return nil, NewValidationError("version", options.Version, err.Error())
+		}
+		result.Version = v.String()
+	} else if result.Version, err = NextVersion(result.PreviousVersion, result.Bump, options.PreID); err != nil {
+		return nil, err
+	}
+	if !options.SkipTag {
+		result.Tag = options.TagPrefix + result.Version
+	}
+	result.Changelog = releaseChangelog(result.Version, r.now(), result.Commits)
+	return result, nil
+}
+
+// Release 执行发布流程，没有需要发布的提交且未指定Version时返回ErrNothingToRelease
+func (r *Releaser) Release(ctx context.Context, options ReleaseOptions) (*ReleaseResult, error) {
+	if options.ChangelogFile == "" {
+		options.ChangelogFile = "CHANGELOG.md"
+	}
+
+	result, err := r.Plan(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	if options.Version == "" && result.Bump == BumpNone {
+		return result, ErrNothingToRelease
+	}
+	if result.Version == result.PreviousVersion {
+		return result, NewValidationError("version", result.Version, "version is already released")
+	}
+	if options.DryRun {
+		return result, nil
+	}
+
+	dirty, err := vcs.HasChanges(ctx, r.repo)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("working tree has uncommitted changes")
+	}
+
+	if err := setManifestVersion(filepath.Join(options.WorkingDir, "package.json"), result.Version, false); err != nil {
+		return nil, err
+	}
+	result.Files = append(result.Files, "package.json")
+	for _, name := range []string{"package-lock.json", "npm-shrinkwrap.json"} {
+		path := filepath.Join(options.WorkingDir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := setManifestVersion(path, result.Version, true); err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, name)
+	}
+	if !options.SkipChangelog {
+		if err := prependChangelog(filepath.Join(options.WorkingDir, options.ChangelogFile), result.Changelog); err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, filepath.ToSlash(options.ChangelogFile))
+	}
+
+	if err := r.repo.Add(ctx, result.Files...); err != nil {
+		return nil, err
+	}
+	message := "chore(release): " + result.Version
+	if result.Commit, err = r.repo.Commit(ctx, message); err != nil {
+		return nil, err
+	}
+	if result.Tag != "" {
+		if err := r.repo.Tag(ctx, result.Tag, message+"\n\n"+result.Changelog); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Push {
+		branch, err := r.repo.CurrentBranch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		refs := []string{branch}
+		if result.Tag != "" {
+			refs = append(refs, result.Tag)
+		}
+		if err := r.repo.Push(ctx, options.Remote, refs...); err != nil {
+			return result, err
+		}
+		result.Pushed = true
+	}
+
+	if options.Publish != nil {
+		publish := *options.Publish
+		if publish.WorkingDir == "" {
+			publish.WorkingDir = options.WorkingDir
+		}
+		// 预发布版本不能占用latest标签
+		if publish.Tag == "" && options.PreID != "" {
+			publish.Tag = options.PreID
+		}
+		if err := r.client.Publish(ctx, publish); err != nil {
+			return result, err
+		}
+		result.Published = !publish.DryRun
+	}
+	return result, nil
+}
+
+// higherBump 返回较高的提升级别
+func higherBump(a, b ReleaseBump) ReleaseBump {
+	rank := map[ReleaseBump]int{BumpNone: 0, BumpPatch: 1, BumpMinor: 2, BumpMajor: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// releaseChangelogSections 更新日志中列出的提交类型及标题
+var releaseChangelogSections = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"revert", "Reverts"},
+}
+
+// releaseChangelog 生成一个版本的更新日志片段
+func releaseChangelog(version string, date time.Time, commits []ConventionalCommit) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s (%s)\n", version, date.Format("2006-01-02"))
+
+	entry := func(commit ConventionalCommit, text string) string {
+		line := "* "
+		if commit.Scope != "" {
+			line += "**" + commit.Scope + ":** "
+		}
+		line += text
+		if commit.Hash != "" {
+			line += " (" + shortHash(commit.Hash) + ")"
+		}
+		return line + "\n"
+	}
+
+	var breaking []string
+	for _, commit := range commits {
+		if commit.Breaking {
+			breaking = append(breaking, entry(commit, commit.BreakingNote))
+		}
+	}
+	if len(breaking) > 0 {
+		b.WriteString("\n### ⚠ BREAKING CHANGES\n\n" + strings.Join(breaking, ""))
+	}
+
+	for _, section := range releaseChangelogSections {
+		var lines []string
+		for _, commit := range commits {
+			if commit.Type == section.Type {
+				lines = append(lines, entry(commit, commit.Description))
+			}
+		}
+		if len(lines) > 0 {
+			b.WriteString("\n### " + section.Title + "\n\n" + strings.Join(lines, ""))
+		}
+	}
+	return b.String()
+}
+
+// shortHash 提交ID的前7位
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// prependChangelog 把section插入更新日志开头的标题之后，文件不存在时创建
+func prependChangelog(path, section string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read changelog: %w", err)
+	}
+
+	existing := string(content)
+	header := "# Changelog\n\n"
+	if strings.HasPrefix(existing, "# ") {
+		end := strings.Index(existing, "\n")
+		if end < 0 {
+			end = len(existing)
+		}
+		header = existing[:end] + "\n\n"
+		existing = strings.TrimLeft(existing[end:], "\r\n")
+	}
+	updated := header + section
+	if existing != "" {
+		updated += "\n" + existing
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return nil
+}
+
+// setManifestVersion 以文本方式修改package.json或锁文件中的version，锁文件同时修改packages[""]中的版本
+func setManifestVersion(path, version string, lockfile bool) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	updated, err := setJSONStringMember(string(content), nil, "version", version)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", filepath.Base(path), err)
+	}
+	if lockfile {
+		var lock struct {
+			Packages map[string]json.RawMessage `json:"packages"`
+		}
+		if json.Unmarshal(content, &lock) == nil && lock.Packages[""] != nil {
+			if updated, err = setJSONStringMember(updated, []string{"packages", ""}, "version", version); err != nil {
+				return fmt.Errorf("failed to update %s: %w", filepath.Base(path), err)
+			}
+		}
+	}
+	return os.WriteFile(path, []byte(updated), 0644)
+}
@@ -0,0 +1,182 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/vcs"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		subject, body string
+		ok            bool
+		expected      ConventionalCommit
+		bump          ReleaseBump
+	}{
+		{"feat: add search", "", true, ConventionalCommit{Type: "feat", Description: "add search"}, BumpMinor},
+		{"fix(cli): handle empty input", "", true, ConventionalCommit{Type: "fix", Scope: "cli", Description: "handle empty input"}, BumpPatch},
+		{"refactor!: drop node 16", "", true, ConventionalCommit{Type: "refactor", Description: "drop node 16", Breaking: true, BreakingNote: "drop node 16"}, BumpMajor},
+		{"perf: cache lookups", "Details.\n\nBREAKING CHANGE: cache directory moved\nto ~/.cache", true,
+			ConventionalCommit{Type: "perf", Description: "cache lookups", Body: "Details.\n\nBREAKING CHANGE: cache directory moved\nto ~/.cache", Breaking: true, BreakingNote: "cache directory moved\nto ~/.cache"}, BumpMajor},
+		{"docs: update readme", "", true, ConventionalCommit{Type: "docs", Description: "update readme"}, BumpNone},
+		{"Update readme", "", false, ConventionalCommit{}, BumpNone},
+		{"feat:missing space", "", false, ConventionalCommit{}, BumpNone},
+	}
+	for _, tt := range tests {
+		commit, ok := ParseConventionalCommit(tt.subject, tt.body)
+		if ok != tt.ok || commit != tt.expected {
+			t.Errorf("ParseConventionalCommit(%q) = %+v, %v, expected %+v", tt.subject, commit, ok, tt.expected)
+		}
+		if commit.Bump() != tt.bump {
+			t.Errorf("Bump() for %q = %s, expected %s", tt.subject, commit.Bump(), tt.bump)
+		}
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		current  string
+		bump     ReleaseBump
+		preid    string
+		expected string
+	}{
+		{"1.2.3", BumpPatch, "", "1.2.4"},
+		{"1.2.3", BumpMinor, "", "1.3.0"},
+		{"1.2.3", BumpMajor, "", "2.0.0"},
+		{"1.2.3", BumpNone, "", "1.2.3"},
+		{"0.4.1", BumpMajor, "", "0.5.0"},
+		{"0.4.1", BumpMinor, "", "0.4.2"},
+		{"1.2.3", BumpMinor, "beta", "1.3.0-beta.0"},
+		{"1.3.0-beta.0", BumpPatch, "beta", "1.3.0-beta.1"},
+		{"1.3.0-rc.1", BumpPatch, "", "1.3.0"},
+
+		// 从预发布版本提升，级别高于预发布版本已经包含的提升时提升基础版本
+		{"1.3.0-beta.4", BumpMajor, "rc", "2.0.0-rc.0"},
+		{"1.3.0-beta.4", BumpMajor, "", "2.0.0"},
+		{"2.0.0-beta.1", BumpMajor, "beta", "2.0.0-beta.2"},
+		{"2.0.0-beta.1", BumpMajor, "", "2.0.0"},
+		{"1.2.3-beta.0", BumpMinor, "beta", "1.3.0-beta.0"},
+		{"1.2.3-beta.0", BumpMinor, "", "1.3.0"},
+		{"1.3.0-beta.0", BumpMinor, "beta", "1.3.0-beta.1"},
+		{"1.3.0-beta.0", BumpMinor, "", "1.3.0"},
+		{"1.2.3-beta.0", BumpPatch, "beta", "1.2.3-beta.1"},
+		{"1.2.3-beta.0", BumpPatch, "", "1.2.3"},
+		{"0.5.0-beta.0", BumpMajor, "beta", "0.5.0-beta.1"},
+		{"0.4.1-beta.0", BumpMajor, "", "0.5.0"},
+	}
+	for _, tt := range tests {
+		got, err := NextVersion(tt.current, tt.bump, tt.preid)
+		if err != nil || got != tt.expected {
+			t.Errorf("NextVersion(%s, %s, %q) = %s, %v, expected %s", tt.current, tt.bump, tt.preid, got, err, tt.expected)
+		}
+	}
+
+	if _, err := NextVersion("latest", BumpPatch, ""); err == nil {
+		t.Error("Expected error for invalid version")
+	}
+	if _, err := NextVersion("1.0.0", BumpPatch, "beta!"); err == nil {
+		t.Error("Expected error for invalid preid")
+	}
+}
+
+func TestReleaserRelease(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	repo := vcs.NewGit(dir)
+	repo.SetAuthor("Test User", "test@example.com")
+	if output, err := exec.Command("git", "init", "-b", "main", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, output)
+	}
+
+	commit := func(file, content, message string) {
+		t.Helper()
+		writeTestFile(t, filepath.Join(dir, file), content)
+		if err := repo.Add(ctx, file); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+		if _, err := repo.Commit(ctx, message); err != nil {
+			t.Fatalf("Commit() failed: %v", err)
+		}
+	}
+	commit("package.json", "{\n    \"name\": \"app\",\n    \"version\": \"1.4.2\",\n    \"private\": false\n}\n", "chore: initial commit")
+	commit("package-lock.json", `{"name":"app","version":"1.4.2","lockfileVersion":3,"packages":{"":{"name":"app","version":"1.4.2"}}}`, "chore: add lockfile")
+	commit("CHANGELOG.md", "# Changelog\n\n## 1.4.2 (2026-01-01)\n", "docs: changelog")
+	if err := repo.Tag(ctx, "v1.4.2", ""); err != nil {
+		t.Fatalf("Tag() failed: %v", err)
+	}
+	commit("search.js", "search", "feat(search): add fuzzy matching")
+	commit("cli.js", "cli", "fix: handle empty input\n\nBREAKING CHANGE: exit code is now 2")
+	commit("notes.txt", "notes", "Update notes")
+
+	executor := &publishExecutor{t: t, name: "app"}
+	client, err := NewClientWithExecutor("npm", executor)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	releaser := NewReleaser(client, repo)
+	releaser.now = func() time.Time { return time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC) }
+
+	plan, err := releaser.Release(ctx, ReleaseOptions{WorkingDir: dir, DryRun: true})
+	if err != nil {
+		t.Fatalf("Release() dry run failed: %v", err)
+	}
+	if plan.Version != "2.0.0" || plan.Bump != BumpMajor || plan.PreviousTag != "v1.4.2" || len(plan.Commits) != 2 || plan.Ignored != 1 {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+	if dirty, _ := repo.HasChanges(ctx); dirty {
+		t.Fatal("Dry run modified the working tree")
+	}
+
+	result, err := releaser.Release(ctx, ReleaseOptions{WorkingDir: dir, Publish: &PublishOptions{Access: "public"}})
+	if err != nil {
+		t.Fatalf("Release() failed: %v", err)
+	}
+	if result.Tag != "v2.0.0" || result.Commit == "" || !result.Published {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	expectedChangelog := "## 2.0.0 (2026-10-18)\n\n### ⚠ BREAKING CHANGES\n\n* exit code is now 2 (" +
+		shortHash(result.Commits[0].Hash) + ")\n\n### Features\n\n* **search:** add fuzzy matching (" +
+		shortHash(result.Commits[1].Hash) + ")\n\n### Bug Fixes\n\n* handle empty input (" + shortHash(result.Commits[0].Hash) + ")\n"
+	if result.Changelog != expectedChangelog {
+		t.Errorf("Unexpected changelog:\n%s", result.Changelog)
+	}
+
+	manifest, _ := os.ReadFile(filepath.Join(dir, "package.json"))
+	if string(manifest) != "{\n    \"name\": \"app\",\n    \"version\": \"2.0.0\",\n    \"private\": false\n}\n" {
+		t.Errorf("Unexpected package.json:\n%s", manifest)
+	}
+	lock, _ := os.ReadFile(filepath.Join(dir, "package-lock.json"))
+	if strings.Count(string(lock), `"version":"2.0.0"`) != 2 {
+		t.Errorf("Unexpected package-lock.json: %s", lock)
+	}
+	changelog, _ := os.ReadFile(filepath.Join(dir, "CHANGELOG.md"))
+	if string(changelog) != "# Changelog\n\n"+expectedChangelog+"\n## 1.4.2 (2026-01-01)\n" {
+		t.Errorf("Unexpected CHANGELOG.md:\n%s", changelog)
+	}
+	if dirty, _ := repo.HasChanges(ctx); dirty {
+		t.Error("Expected release changes to be committed")
+	}
+	if tag, _ := repo.LatestTag(ctx, "v*"); tag != "v2.0.0" {
+		t.Errorf("Expected tag v2.0.0, got %s", tag)
+	}
+	if !slices.Contains(executor.publish, "publish") || !slices.Contains(executor.publish, "--access") {
+		t.Errorf("Unexpected publish command: %v", executor.publish)
+	}
+
+	// 发布之后没有新的提交
+	if _, err := releaser.Release(ctx, ReleaseOptions{WorkingDir: dir}); !errors.Is(err, ErrNothingToRelease) {
+		t.Errorf("Expected ErrNothingToRelease, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package npm
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// NpmSource npm可执行文件的来源
+type NpmSource string
+
+const (
+	SourceExplicit NpmSource = "explicit" // NpmResolver.ExplicitPath指定的路径
+	SourcePortable NpmSource = "portable" // PortableManager.SetAsDefault设置的默认版本
+	SourceCorepack NpmSource = "corepack" // corepack enable npm创建的shim
+	SourcePath     NpmSource = "path"     // PATH中的npm
+)
+
+// DefaultResolutionOrder 默认的npm查找顺序
+var DefaultResolutionOrder = []NpmSource{SourceExplicit, SourcePortable, SourceCorepack, SourcePath}
+
+// NpmCandidate 查找npm时检查的一个来源
+type NpmCandidate struct {
+	Source   NpmSource `json:"source"`
+	Path     string    `json:"path,omitempty"`
+	Target   string    `json:"target,omitempty"` // 符号链接指向的实际文件，与Path相同时为空
+	Found    bool      `json:"found"`
+	Selected bool      `json:"selected"`
+	Reason   string    `json:"reason"`
+}
+
+// NpmResolution npm的查找结果
+type NpmResolution struct {
+	Path       string         `json:"path"`             // 选中的npm，没有找到时为空
+	Source     NpmSource      `json:"source,omitempty"` // 选中的来源
+	Candidates []NpmCandidate `json:"candidates"`       // 按查找顺序排列，选中之后的来源也会检查，便于发现被遮蔽的安装
+}
+
+// Explain 返回每个来源的检查结果，一行一个
+func (r *NpmResolution) Explain() string {
+	var b strings.Builder
+	if r.Path == "" {
+		b.WriteString("no npm binary found\n")
+	} else {
+		fmt.Fprintf(&b, "using %s (%s)\n", r.Path, r.Source)
+	}
+	for _, candidate := range r.Candidates {
+		mark := " "
+		if candidate.Selected {
+			mark = "*"
+		}
+		fmt.Fprintf(&b, "%s %-8s ", mark, candidate.Source)
+		if candidate.Path != "" {
+			b.WriteString(candidate.Path)
+			if candidate.Target != "" {
+				b.WriteString(" -> " + candidate.Target)
+			}
+			b.WriteString(": ")
+		}
+		b.WriteString(candidate.Reason + "\n")
+	}
+	return b.String()
+}
+
+// NpmResolver 按配置的顺序查找npm可执行文件
+//
+// 机器上同时有系统安装、便携版和corepack时，PATH中排在前面的npm不一定是想要的那个。
+// NpmResolver按Order检查每个来源，选中第一个存在的，并报告每个来源的检查结果。
+type NpmResolver struct {
+	Order        []NpmSource // 为空时使用DefaultResolutionOrder
+	ExplicitPath string      // SourceExplicit的路径，为空时跳过该来源
+	PortableDir  string      // PortableManager的基础目录，为空时使用默认目录
+
+	lookPath func(string) (string, error)
+}
+
+// NewNpmResolver 创建使用默认顺序的查找器
+func NewNpmResolver() *NpmResolver {
+	return &NpmResolver{Order: DefaultResolutionOrder}
+}
+
+// Resolve 按顺序查找npm，没有找到时返回ErrNpmNotFound，结果中仍包含每个来源的检查结果
+func (r *NpmResolver) Resolve() (*NpmResolution, error) {
+	order := r.Order
+	if len(order) == 0 {
+		order = DefaultResolutionOrder
+	}
+
+	resolution := &NpmResolution{Candidates: []NpmCandidate{}}
+	for _, source := range order {
+		candidate := r.check(source)
+		if candidate.Found && resolution.Path == "" {
+			candidate.Selected = true
+			resolution.Path, resolution.Source = candidate.Path, source
+		} else if candidate.Found {
+			candidate.Reason += ", shadowed by " + string(resolution.Source)
+		}
+		resolution.Candidates = append(resolution.Candidates, candidate)
+	}
+	if resolution.Path == "" {
+		return resolution, ErrNpmNotFound
+	}
+	return resolution, nil
+}
+
+// check 检查一个来源
+func (r *NpmResolver) check(source NpmSource) NpmCandidate {
+	candidate := NpmCandidate{Source: source}
+	switch source {
+	case SourceExplicit:
+		if r.ExplicitPath == "" {
+			candidate.Reason = "no explicit path configured"
+			return candidate
+		}
+		candidate.Path = r.ExplicitPath
+
+	case SourcePortable:
+		dir := r.PortableDir
+		if dir == "" {
+			var err error
+			if dir, err = defaultPortableDir(); err != nil {
+				candidate.Reason = err.Error()
+				return candidate
+			}
+		}
+		candidate.Path = portableNpmPath(filepath.Join(dir, "default"))
+
+	case SourceCorepack:
+		corepack, err := r.look("corepack")
+		if err != nil {
+			candidate.Reason = "corepack not found in PATH"
+			return candidate
+		}
+		// corepack enable默认把shim安装在corepack所在的目录
+		candidate.Path = filepath.Join(filepath.Dir(corepack), npmExecutableName())
+		if !isExecutableFile(candidate.Path) {
+			candidate.Reason = "corepack is installed but npm is not enabled, run corepack enable npm"
+			return candidate
+		}
+		target, _ := filepath.EvalSymlinks(candidate.Path)
+		content, _ := os.ReadFile(candidate.Path)
+		if !strings.Contains(strings.ToLower(target), "corepack") && !strings.Contains(string(content), "corepack") {
+			candidate.Reason = "npm next to corepack is not a corepack shim"
+			return candidate
+		}
+
+	case SourcePath:
+		path, err := r.look(npmExecutableName())
+		if err != nil {
+			candidate.Reason = "npm not found in PATH"
+			return candidate
+		}
+		candidate.Path = path
+
+	default:
+		candidate.Reason = "unknown source"
+		return candidate
+	}
+
+	if !isExecutableFile(candidate.Path) {
+		candidate.Reason = "not found or not executable"
+		return candidate
+	}
+	if target, err := filepath.EvalSymlinks(candidate.Path); err == nil && target != candidate.Path {
+		candidate.Target = target
+	}
+	candidate.Found = true
+	candidate.Reason = "found"
+	return candidate
+}
+
+// look 在PATH中查找命令
+func (r *NpmResolver) look(name string) (string, error) {
+	if r.lookPath != nil {
+		return r.lookPath(name)
+	}
+	return exec.LookPath(name)
+}
+
+// npmExecutableName 当前平台的npm可执行文件名
+func npmExecutableName() string {
+	if runtime.GOOS == "windows" {
+		return "npm.cmd"
+	}
+	return "npm"
+}
+
+// isExecutableFile 判断path是否为可执行的普通文件，Windows上只检查文件是否存在
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// WithNpmResolver 创建客户端时用resolver选择npm，选择结果可以通过ResolvedNpm查看
+//
+// resolver没有设置ExplicitPath时，NewClientWithPath等传入的路径作为SourceExplicit。
+// 没有找到npm时保留客户端原来的路径。
+func WithNpmResolver(resolver *NpmResolver) ClientOption {
+	return func(c *client) {
+		if resolver.ExplicitPath == "" && c.npmPath != "npm" {
+			copied := *resolver
+			copied.ExplicitPath = c.npmPath
+			resolver = &copied
+		}
+		c.resolution, _ = resolver.Resolve()
+		if c.resolution.Path != "" {
+			c.npmPath = c.resolution.Path
+		}
+	}
+}
+
+// ResolvedNpm 返回客户端通过WithNpmResolver选择npm的结果，没有使用WithNpmResolver时返回nil
+func ResolvedNpm(npmClient Client) *NpmResolution {
+	if c, ok := npmClient.(*client); ok {
+		return c.resolution
+	}
+	return nil
+}
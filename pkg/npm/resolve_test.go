@@ -0,0 +1,150 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeTestExecutable 创建可执行文件
+func writeTestExecutable(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNpmResolver(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("resolver test uses unix executables")
+	}
+
+	root := t.TempDir()
+	explicit := filepath.Join(root, "explicit", "npm")
+	portableDir := filepath.Join(root, "portable")
+	corepackDir := filepath.Join(root, "node-bin")
+	systemNpm := filepath.Join(root, "usr", "bin", "npm")
+	writeTestExecutable(t, explicit, "#!/bin/sh\n")
+	writeTestExecutable(t, portableNpmPath(filepath.Join(portableDir, "default")), "#!/bin/sh\n")
+	writeTestExecutable(t, filepath.Join(corepackDir, "corepack"), "#!/bin/sh\n")
+	writeTestExecutable(t, filepath.Join(corepackDir, "npm"), "#!/bin/sh\nexec corepack npm \"$@\"\n")
+	writeTestExecutable(t, systemNpm, "#!/bin/sh\n")
+
+	lookPath := func(name string) (string, error) {
+		switch name {
+		case "corepack":
+			return filepath.Join(corepackDir, "corepack"), nil
+		case "npm":
+			return systemNpm, nil
+		}
+		return "", errors.New("not found")
+	}
+
+	resolver := &NpmResolver{ExplicitPath: explicit, PortableDir: portableDir, lookPath: lookPath}
+	resolution, err := resolver.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() failed: %v", err)
+	}
+	if resolution.Path != explicit || resolution.Source != SourceExplicit {
+		t.Errorf("Expected explicit npm, got %s (%s)", resolution.Path, resolution.Source)
+	}
+	if len(resolution.Candidates) != len(DefaultResolutionOrder) {
+		t.Fatalf("Expected every source to be checked, got %+v", resolution.Candidates)
+	}
+	for _, candidate := range resolution.Candidates[1:] {
+		if !candidate.Found || candidate.Selected || !strings.Contains(candidate.Reason, "shadowed by explicit") {
+			t.Errorf("Expected %s to be shadowed, got %+v", candidate.Source, candidate)
+		}
+	}
+	explanation := resolution.Explain()
+	if !strings.Contains(explanation, "using "+explicit) || !strings.Contains(explanation, "* explicit") {
+		t.Errorf("Unexpected explanation:\n%s", explanation)
+	}
+
+	// 显式路径不存在时使用下一个来源
+	resolver.ExplicitPath = filepath.Join(root, "missing", "npm")
+	resolution, err = resolver.Resolve()
+	if err != nil || resolution.Source != SourcePortable {
+		t.Fatalf("Expected portable npm, got %+v, %v", resolution, err)
+	}
+	if resolution.Candidates[0].Found || resolution.Candidates[0].Reason != "not found or not executable" {
+		t.Errorf("Unexpected explicit candidate: %+v", resolution.Candidates[0])
+	}
+
+	// 自定义顺序
+	resolver.Order = []NpmSource{SourcePath, SourcePortable}
+	resolution, err = resolver.Resolve()
+	if err != nil || resolution.Path != systemNpm || len(resolution.Candidates) != 2 {
+		t.Fatalf("Expected PATH npm, got %+v, %v", resolution, err)
+	}
+
+	// corepack旁边的npm不是shim时不算corepack来源
+	writeTestExecutable(t, filepath.Join(corepackDir, "npm"), "#!/bin/sh\n")
+	resolver.Order = []NpmSource{SourceCorepack}
+	resolution, err = resolver.Resolve()
+	if !errors.Is(err, ErrNpmNotFound) || resolution.Path != "" {
+		t.Fatalf("Expected ErrNpmNotFound, got %+v, %v", resolution, err)
+	}
+	if !strings.Contains(resolution.Candidates[0].Reason, "not a corepack shim") {
+		t.Errorf("Unexpected corepack candidate: %+v", resolution.Candidates[0])
+	}
+	if !strings.HasPrefix(resolution.Explain(), "no npm binary found") {
+		t.Errorf("Unexpected explanation:\n%s", resolution.Explain())
+	}
+}
+
+func TestWithNpmResolver(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("resolver test uses unix executables")
+	}
+
+	portableDir := t.TempDir()
+	portableNpm := portableNpmPath(filepath.Join(portableDir, "default"))
+	writeTestExecutable(t, portableNpm, "#!/bin/sh\n")
+	resolver := &NpmResolver{
+		PortableDir: portableDir,
+		lookPath:    func(string) (string, error) { return "", errors.New("not found") },
+	}
+
+	executor := &recordingExecutor{}
+	client, err := NewClientWithExecutor("npm", executor, WithNpmResolver(resolver))
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	resolution := ResolvedNpm(client)
+	if resolution == nil || resolution.Path != portableNpm {
+		t.Fatalf("Expected portable npm, got %+v", resolution)
+	}
+	if _, err := client.Version(context.Background()); err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	if executor.options.Command != portableNpm {
+		t.Errorf("Expected command %s, got %s", portableNpm, executor.options.Command)
+	}
+
+	// 客户端传入的路径作为显式路径
+	explicit := filepath.Join(t.TempDir(), "npm")
+	writeTestExecutable(t, explicit, "#!/bin/sh\n")
+	client, err = NewClientWithExecutor(explicit, &recordingExecutor{}, WithNpmResolver(resolver))
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	if resolution := ResolvedNpm(client); resolution.Source != SourceExplicit || resolution.Path != explicit {
+		t.Errorf("Expected explicit npm, got %+v", resolution)
+	}
+	if resolver.ExplicitPath != "" {
+		t.Errorf("WithNpmResolver modified the resolver: %+v", resolver)
+	}
+
+	client, _ = NewClientWithExecutor("npm", &recordingExecutor{})
+	if ResolvedNpm(client) != nil {
+		t.Error("Expected nil resolution without WithNpmResolver")
+	}
+}
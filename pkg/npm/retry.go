@@ -0,0 +1,93 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// RetryPolicy 访问registry的操作失败时的重试策略
+type RetryPolicy = npmiface.RetryPolicy
+
+// DefaultRetryableErrors 默认可重试的错误
+//
+// RetryPolicy.Retryable为空时实际使用的是npmiface.DefaultRetryableErrors，修改默认值需要修改后者。
+var DefaultRetryableErrors = npmiface.DefaultRetryableErrors
+
+// DefaultRetryPolicy 返回默认的重试策略：最多3次，等待1s、2s
+func DefaultRetryPolicy() *RetryPolicy {
+	return npmiface.DefaultRetryPolicy()
+}
+
+// RetryEvent 操作失败并即将重试时在事件总线上发送的事件
+type RetryEvent struct {
+	Op      string        `json:"op"`
+	Package string        `json:"package,omitempty"`
+	Attempt int           `json:"attempt"` // 失败的尝试次数，从1开始
+	Delay   time.Duration `json:"delay"`   // 下一次尝试前的等待时间
+	Err     error         `json:"-"`
+}
+
+// EventType 实现Event接口
+func (e RetryEvent) EventType() string {
+	return "retry"
+}
+
+// retry 按重试策略执行fn，policy为nil时只执行一次
+//
+// 等待期间ctx被取消时返回最后一次的错误。
+func retry(ctx context.Context, policy *RetryPolicy, events *EventBus, op, pkg string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || policy == nil || attempt >= policy.MaxAttempts || !policy.ShouldRetry(err) {
+			return err
+		}
+
+		delay := policy.Backoff(attempt)
+		events.Emit(RetryEvent{Op: op, Package: pkg, Attempt: attempt, Delay: delay, Err: err})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// SetRetryPolicy 设置install、view和search失败时的重试策略，nil表示不重试
+func (c *client) SetRetryPolicy(policy *RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+// withRetry 按客户端的重试策略执行fn
+func (c *client) withRetry(ctx context.Context, op, pkg string, fn func() error) error {
+	return retry(ctx, c.retryPolicy, c.events, op, pkg, fn)
+}
+
+// executeWithRetry 按重试策略执行npm命令，失败时返回NpmError
+func (c *client) executeWithRetry(ctx context.Context, op, pkg string, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	var result *utils.ExecuteResult
+	err := c.withRetry(ctx, op, pkg, func() error {
+		var err error
+		result, err = c.executor.Execute(ctx, options)
+		if err != nil {
+			if result == nil {
+				return NewNpmError(op, pkg, -1, "", "", err)
+			}
+			return NewNpmError(op, pkg, result.ExitCode, result.Stdout, result.Stderr, err)
+		}
+		if !result.Success {
+			return NewNpmError(op, pkg, result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm %s failed", op))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,201 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 3}
+	expected := []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 900 * time.Millisecond, time.Second, time.Second}
+	for i, want := range expected {
+		if got := policy.Backoff(i + 1); got != want {
+			t.Errorf("Backoff(%d) = %v, expected %v", i+1, got, want)
+		}
+	}
+
+	// 倍数小于1时按固定间隔等待
+	constant := &RetryPolicy{InitialBackoff: 50 * time.Millisecond}
+	if got := constant.Backoff(4); got != 50*time.Millisecond {
+		t.Errorf("Expected constant backoff, got %v", got)
+	}
+
+	capped := &RetryPolicy{InitialBackoff: time.Minute, MaxBackoff: time.Second, Multiplier: 2}
+	if got := capped.Backoff(1); got != time.Second {
+		t.Errorf("Expected initial backoff to be capped, got %v", got)
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	tests := []struct {
+		name   string
+		err    error
+		expect bool
+	}{
+		{"nil", nil, false},
+		{"network", NewNpmError("view", "lodash", 1, "", "npm error code ECONNRESET\n", errors.New("failed")), true},
+		{"registry 5xx", NewNpmError("view", "lodash", 1, "", "npm error code E503\n", errors.New("failed")), true},
+		{"wrapped", NewInstallError("lodash", "npm install failed", NewNpmError("install", "lodash", 1, "", "npm error code EAI_AGAIN\n", errors.New("failed"))), true},
+		{"not found", NewNpmError("view", "missing", 1, "", "npm error code E404\n", errors.New("failed")), false},
+		{"auth", NewNpmError("view", "private", 1, "", "npm error code E401\n", errors.New("failed")), false},
+		{"cancelled", NewNpmError("view", "lodash", -1, "", "", context.Canceled), false},
+		{"timeout", NewNpmError("view", "lodash", -1, "", "", utils.ErrCommandTimeout), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.ShouldRetry(tt.err); got != tt.expect {
+				t.Errorf("ShouldRetry(%v) = %v, expected %v", tt.err, got, tt.expect)
+			}
+		})
+	}
+
+	// 自定义可重试的错误
+	policy.Retryable = []error{utils.ErrCommandTimeout}
+	if !policy.ShouldRetry(NewNpmError("view", "lodash", -1, "", "", utils.ErrCommandTimeout)) {
+		t.Error("Expected timeout to be retried with custom retryable errors")
+	}
+	if policy.ShouldRetry(NewNpmError("view", "lodash", 1, "", "npm error code ECONNRESET\n", errors.New("failed"))) {
+		t.Error("Expected custom retryable errors to replace the defaults")
+	}
+}
+
+func TestClassifyRegistryErrorCode(t *testing.T) {
+	for _, code := range []string{"E500", "E502", "E503", "E504"} {
+		if ClassifyErrorCode(code) != ErrRegistryError {
+			t.Errorf("Expected %s to be classified as registry error", code)
+		}
+	}
+	for _, code := range []string{"E5", "E5XX", "E4040", "E400"} {
+		if ClassifyErrorCode(code) == ErrRegistryError {
+			t.Errorf("Expected %s not to be classified as registry error", code)
+		}
+	}
+	if !IsRegistryError(NewNpmError("view", "lodash", 1, "", "npm error code E502\n", errors.New("failed"))) {
+		t.Error("Expected IsRegistryError to match E502")
+	}
+}
+
+// newRetryTestClient 创建使用回放器和极短退避时间的客户端
+func newRetryTestClient(t *testing.T, interactions ...utils.Interaction) (*client, *utils.Replayer) {
+	t.Helper()
+	replayer := utils.NewReplayer(&utils.Fixture{Interactions: interactions})
+	c, err := NewClientWithExecutor("npm", replayer)
+	if err != nil {
+		t.Fatalf("NewClientWithExecutor() failed: %v", err)
+	}
+	c.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2})
+	return c.(*client), replayer
+}
+
+func TestClientRetry(t *testing.T) {
+	ctx := context.Background()
+	unavailable := "npm error code E503\nnpm error 503 Service Unavailable - GET https://registry.npmjs.org/lodash\n"
+	reset := "npm error code ECONNRESET\nnpm error network aborted\n"
+
+	t.Run("install", func(t *testing.T) {
+		c, replayer := newRetryTestClient(t,
+			utils.Interaction{Command: "npm", Args: []string{"install", "lodash"}, ExitCode: 1, Stderr: reset},
+			utils.Interaction{Command: "npm", Args: []string{"install", "lodash"}, ExitCode: 1, Stderr: unavailable},
+			utils.Interaction{Command: "npm", Args: []string{"install", "lodash"}, Stdout: "added 1 package\n"},
+		)
+		var events []RetryEvent
+		c.Events().Subscribe(func(event Event) {
+			if retryEvent, ok := event.(RetryEvent); ok {
+				events = append(events, retryEvent)
+			}
+		})
+
+		if err := c.InstallPackage(ctx, "lodash", InstallOptions{}); err != nil {
+			t.Fatalf("Expected install to succeed after retries, got %v", err)
+		}
+		if len(replayer.Remaining()) != 0 {
+			t.Errorf("Expected all attempts to be used, %d left", len(replayer.Remaining()))
+		}
+		if len(events) != 2 || events[0].Op != "install" || events[0].Attempt != 1 || events[1].Attempt != 2 {
+			t.Fatalf("Unexpected retry events: %+v", events)
+		}
+		if events[1].Delay != 2*time.Millisecond || !IsRegistryError(events[1].Err) {
+			t.Errorf("Unexpected second retry event: %+v", events[1])
+		}
+	})
+
+	t.Run("view", func(t *testing.T) {
+		c, _ := newRetryTestClient(t,
+			utils.Interaction{Command: "npm", Args: []string{"view", "lodash", "--json"}, ExitCode: 1, Stderr: unavailable},
+			utils.Interaction{Command: "npm", Args: []string{"view", "lodash", "--json"}, Stdout: `{"name": "lodash", "version": "4.17.21"}`},
+		)
+		info, err := c.GetPackageInfo(ctx, "lodash")
+		if err != nil || info.Name != "lodash" {
+			t.Fatalf("GetPackageInfo() = %+v, %v", info, err)
+		}
+	})
+
+	t.Run("search gives up", func(t *testing.T) {
+		c, replayer := newRetryTestClient(t,
+			utils.Interaction{Command: "npm", Args: []string{"search", "react", "--json"}, ExitCode: 1, Stderr: reset},
+			utils.Interaction{Command: "npm", Args: []string{"search", "react", "--json"}, ExitCode: 1, Stderr: reset},
+			utils.Interaction{Command: "npm", Args: []string{"search", "react", "--json"}, ExitCode: 1, Stderr: reset},
+			utils.Interaction{Command: "npm", Args: []string{"search", "react", "--json"}, Stdout: "[]"},
+		)
+		_, err := c.Search(ctx, "react")
+		if !IsNetworkError(err) {
+			t.Fatalf("Expected network error after the last attempt, got %v", err)
+		}
+		if len(replayer.Remaining()) != 1 {
+			t.Errorf("Expected exactly MaxAttempts attempts, %d interactions left", len(replayer.Remaining()))
+		}
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		c, replayer := newRetryTestClient(t,
+			utils.Interaction{Command: "npm", Args: []string{"view", "missing", "--json"}, ExitCode: 1, Stderr: "npm error code E404\nnpm error 404 Not Found - GET https://registry.npmjs.org/missing\n"},
+			utils.Interaction{Command: "npm", Args: []string{"view", "missing", "--json"}, Stdout: "{}"},
+		)
+		if _, err := c.GetPackageInfo(ctx, "missing"); !IsPackageNotFound(err) {
+			t.Fatalf("Expected package not found, got %v", err)
+		}
+		if len(replayer.Remaining()) != 1 {
+			t.Error("Expected E404 not to be retried")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c, replayer := newRetryTestClient(t,
+			utils.Interaction{Command: "npm", Args: []string{"search", "react", "--json"}, ExitCode: 1, Stderr: reset},
+			utils.Interaction{Command: "npm", Args: []string{"search", "react", "--json"}, Stdout: "[]"},
+		)
+		c.SetRetryPolicy(nil)
+		if _, err := c.Search(ctx, "react"); !IsNetworkError(err) {
+			t.Fatalf("Expected network error without retry policy, got %v", err)
+		}
+		if len(replayer.Remaining()) != 1 {
+			t.Error("Expected a single attempt without retry policy")
+		}
+	})
+
+	t.Run("cancelled during backoff", func(t *testing.T) {
+		c, replayer := newRetryTestClient(t,
+			utils.Interaction{Command: "npm", Args: []string{"search", "react", "--json"}, ExitCode: 1, Stderr: reset},
+			utils.Interaction{Command: "npm", Args: []string{"search", "react", "--json"}, Stdout: "[]"},
+		)
+		c.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour})
+		ctx, cancel := context.WithCancel(context.Background())
+		c.Events().Subscribe(func(event Event) {
+			if _, ok := event.(RetryEvent); ok {
+				cancel()
+			}
+		})
+
+		if _, err := c.Search(ctx, "react"); !IsNetworkError(err) {
+			t.Fatalf("Expected the last error to be returned, got %v", err)
+		}
+		if len(replayer.Remaining()) != 1 {
+			t.Error("Expected no attempt after cancellation")
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+// Implementation 执行操作的方式
+type Implementation string
+
+const (
+	ImplementationCLI      Implementation = "cli"      // 运行npm命令
+	ImplementationRegistry Implementation = "registry" // 直接调用registry的HTTP接口
+)
+
+// ImplementationPolicy 选择实现的策略
+type ImplementationPolicy string
+
+const (
+	PolicyAuto     ImplementationPolicy = "auto"     // 按npm是否可用、是否离线和registry是否支持接口自动选择
+	PolicyCLI      ImplementationPolicy = "cli"      // 只使用npm命令
+	PolicyRegistry ImplementationPolicy = "registry" // 只使用registry的HTTP接口
+)
+
+// RoutedOperation Router可以选择实现的操作
+type RoutedOperation string
+
+const (
+	RouteView    RoutedOperation = "view"
+	RouteSearch  RoutedOperation = "search"
+	RouteAudit   RoutedOperation = "audit"
+	RoutePublish RoutedOperation = "publish"
+)
+
+// Routed 操作的结果和实际使用的实现
+type Routed[T any] struct {
+	Value          T              `json:"value"`
+	Implementation Implementation `json:"implementation"`
+	Reason         string         `json:"reason"` // 选择该实现的原因
+}
+
+// Router 为同时有npm命令和registry HTTP接口两种实现的操作（view、search、audit、publish）
+// 在每次调用时选择可用的实现，并在结果中返回实际使用的实现
+//
+// 默认策略PolicyAuto：
+//   - 离线时只使用npm命令，npm可能从缓存中返回结果
+//   - 找不到npm命令时使用registry接口
+//   - view和search优先使用registry接口，一次HTTP请求比启动npm快得多
+//   - audit和publish优先使用npm命令，npm audit给出依赖路径和修复方案，
+//     npm publish读取.npmrc中的认证信息
+//   - registry不支持search或audit的接口时（404、405、501）改用npm命令，之后的调用直接使用npm命令
+type Router struct {
+	client   Client
+	registry *registry.Client
+
+	mu          sync.Mutex
+	policy      ImplementationPolicy
+	overrides   map[RoutedOperation]ImplementationPolicy
+	offline     bool
+	cli         *bool // npm命令是否可用，第一次需要时检测
+	unsupported map[RoutedOperation]bool
+}
+
+// NewRouter 创建实现选择器
+//
+// registryClient为nil时与WithRegistryFallback的默认值相同，使用npm_config_registry
+// 环境变量指定的registry，未设置时使用官方registry。
+func NewRouter(npmClient Client, registryClient *registry.Client) *Router {
+	if registryClient == nil {
+		registryClient = defaultFallback()
+	}
+	return &Router{
+		client:      npmClient,
+		registry:    registryClient,
+		policy:      PolicyAuto,
+		overrides:   make(map[RoutedOperation]ImplementationPolicy),
+		unsupported: make(map[RoutedOperation]bool),
+	}
+}
+
+// SetPolicy 设置所有操作的默认策略
+func (r *Router) SetPolicy(policy ImplementationPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy = policy
+}
+
+// SetOperationPolicy 设置单个操作的策略，优先于SetPolicy；PolicyAuto以外的策略不会回退到另一种实现
+func (r *Router) SetOperationPolicy(operation RoutedOperation, policy ImplementationPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides[operation] = policy
+}
+
+// SetOffline 设置是否离线，离线时不使用registry接口
+func (r *Router) SetOffline(offline bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.offline = offline
+}
+
+// View 获取包信息，pkg可以带版本号、版本范围或dist-tag
+func (r *Router) View(ctx context.Context, pkg string) (*Routed[*PackageInfo], error) {
+	return route(ctx, r, RouteView,
+		func() (*PackageInfo, error) { return r.client.GetPackageInfo(ctx, pkg) },
+		func() (*PackageInfo, error) { return registryPackageInfo(ctx, r.registry, pkg) },
+	)
+}
+
+// Search 搜索包
+func (r *Router) Search(ctx context.Context, query string) (*Routed[[]SearchResult], error) {
+	if query == "" {
+		return nil, NewValidationError("query", query, "search query cannot be empty")
+	}
+	return route(ctx, r, RouteSearch,
+		func() ([]SearchResult, error) { return r.client.Search(ctx, query) },
+		func() ([]SearchResult, error) { return registrySearch(ctx, r.registry, query) },
+	)
+}
+
+// Audit 安全审计，registry接口需要项目中有锁文件
+func (r *Router) Audit(ctx context.Context, options AuditOptions) (*Routed[*AuditReport], error) {
+	return route(ctx, r, RouteAudit,
+		func() (*AuditReport, error) { return r.client.Audit(ctx, options) },
+		func() (*AuditReport, error) { return registryAudit(ctx, r.registry, options) },
+	)
+}
+
+// Publish 发布包，使用npm命令时Value为nil
+//
+// 使用registry接口时通过RegistryPublisher发布，打包仍然需要npm命令。
+func (r *Router) Publish(ctx context.Context, options PublishOptions) (*Routed[*registry.PublishResponse], error) {
+	return route(ctx, r, RoutePublish,
+		func() (*registry.PublishResponse, error) { return nil, r.client.Publish(ctx, options) },
+		func() (*registry.PublishResponse, error) {
+			return NewRegistryPublisher(r.client, r.registry).Publish(ctx, options)
+		},
+	)
+}
+
+// Select 返回下一次调用operation时会使用的实现和原因，不执行操作
+func (r *Router) Select(ctx context.Context, operation RoutedOperation) (Implementation, string, error) {
+	return r.choose(ctx, operation)
+}
+
+// route 选择实现并执行，自动策略下registry不支持接口时改用npm命令
+func route[T any](ctx context.Context, r *Router, operation RoutedOperation, cli, api func() (T, error)) (*Routed[T], error) {
+	impl, reason, err := r.choose(ctx, operation)
+	if err != nil {
+		return nil, err
+	}
+	if impl == ImplementationCLI {
+		value, err := cli()
+		if err != nil {
+			return nil, err
+		}
+		return &Routed[T]{Value: value, Implementation: ImplementationCLI, Reason: reason}, nil
+	}
+
+	value, err := api()
+	if err != nil && operation != RouteView && isUnsupportedEndpoint(err) && r.canRetryWithCLI(ctx, operation) {
+		value, err = cli()
+		impl, reason = ImplementationCLI, "registry does not support the endpoint"
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Routed[T]{Value: value, Implementation: impl, Reason: reason}, nil
+}
+
+// choose 按策略选择实现
+func (r *Router) choose(ctx context.Context, operation RoutedOperation) (Implementation, string, error) {
+	r.mu.Lock()
+	policy, ok := r.overrides[operation]
+	if !ok {
+		policy = r.policy
+	}
+	offline := r.offline
+	unsupported := r.unsupported[operation]
+	r.mu.Unlock()
+
+	switch policy {
+	case PolicyCLI:
+		if !r.cliAvailable(ctx) {
+			return "", "", fmt.Errorf("%s: %w", operation, ErrNpmNotFound)
+		}
+		return ImplementationCLI, "selected by policy", nil
+	case PolicyRegistry:
+		if offline {
+			return "", "", fmt.Errorf("%s: registry is not reachable offline", operation)
+		}
+		return ImplementationRegistry, "selected by policy", nil
+	case PolicyAuto, "":
+	default:
+		return "", "", NewValidationError("policy", string(policy), "unknown implementation policy")
+	}
+
+	cli := r.cliAvailable(ctx)
+	switch {
+	case offline:
+		if !cli {
+			return "", "", fmt.Errorf("%s: offline and %w", operation, ErrNpmNotFound)
+		}
+		return ImplementationCLI, "offline, npm may answer from its cache", nil
+	case !cli:
+		return ImplementationRegistry, "npm not found", nil
+	case unsupported:
+		return ImplementationCLI, "registry does not support the endpoint", nil
+	case operation == RouteView || operation == RouteSearch:
+		return ImplementationRegistry, "registry API avoids starting npm", nil
+	case operation == RouteAudit:
+		return ImplementationCLI, "npm audit reports dependency paths and fixes", nil
+	}
+	return ImplementationCLI, "npm publish reads credentials from .npmrc", nil
+}
+
+// canRetryWithCLI 自动策略下记录registry不支持接口，npm命令可用时返回true
+func (r *Router) canRetryWithCLI(ctx context.Context, operation RoutedOperation) bool {
+	r.mu.Lock()
+	policy, ok := r.overrides[operation]
+	if !ok {
+		policy = r.policy
+	}
+	if policy != PolicyAuto && policy != "" {
+		r.mu.Unlock()
+		return false
+	}
+	r.unsupported[operation] = true
+	r.mu.Unlock()
+	return r.cliAvailable(ctx)
+}
+
+// cliAvailable 检测npm命令是否可用，结果被缓存
+func (r *Router) cliAvailable(ctx context.Context) bool {
+	r.mu.Lock()
+	if r.cli != nil {
+		defer r.mu.Unlock()
+		return *r.cli
+	}
+	r.mu.Unlock()
+
+	available := r.client.IsAvailable(ctx)
+	if ctx.Err() != nil {
+		// 检测被取消时不缓存结果
+		return available
+	}
+	r.mu.Lock()
+	r.cli = &available
+	r.mu.Unlock()
+	return available
+}
+
+// isUnsupportedEndpoint 判断registry是否不支持请求的接口
+func isUnsupportedEndpoint(err error) bool {
+	var registryErr *registry.Error
+	if !errors.As(err, &registryErr) {
+		return false
+	}
+	switch registryErr.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
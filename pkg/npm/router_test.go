@@ -0,0 +1,135 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+// routerClient npm命令可用性可配置的客户端，Search返回固定结果
+type routerClient struct {
+	*MockClient
+	available bool
+	checks    int
+}
+
+func (c *routerClient) IsAvailable(ctx context.Context) bool {
+	c.checks++
+	return c.available
+}
+
+func (c *routerClient) Search(ctx context.Context, query string) ([]SearchResult, error) {
+	return []SearchResult{{Package: SearchPackage{Name: "from-cli"}}}, nil
+}
+
+func newRouterRegistry(t *testing.T, searchStatus int) *registry.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/lodash":
+			fmt.Fprint(w, `{"name":"lodash","dist-tags":{"latest":"4.17.21"},"versions":{"4.17.21":{"name":"lodash","version":"4.17.21"}}}`)
+		case "/-/v1/search":
+			if searchStatus != http.StatusOK {
+				w.WriteHeader(searchStatus)
+				return
+			}
+			fmt.Fprint(w, `{"objects":[{"package":{"name":"from-registry","version":"1.0.0"}}],"total":1}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return registry.NewClient(server.URL)
+}
+
+func TestRouterAuto(t *testing.T) {
+	ctx := context.Background()
+	client := &routerClient{MockClient: NewMockClient(), available: true}
+	router := NewRouter(client, newRouterRegistry(t, http.StatusOK))
+
+	view, err := router.View(ctx, "lodash")
+	if err != nil {
+		t.Fatalf("View() failed: %v", err)
+	}
+	if view.Implementation != ImplementationRegistry || view.Value.Version != "4.17.21" {
+		t.Errorf("Unexpected view result: %+v", view)
+	}
+
+	search, err := router.Search(ctx, "lodash")
+	if err != nil || search.Implementation != ImplementationRegistry || search.Value[0].Package.Name != "from-registry" {
+		t.Errorf("Unexpected search result: %+v, %v", search, err)
+	}
+
+	audit, err := router.Audit(ctx, AuditOptions{})
+	if err != nil || audit.Implementation != ImplementationCLI {
+		t.Errorf("Expected audit through npm, got %+v, %v", audit, err)
+	}
+	publish, err := router.Publish(ctx, PublishOptions{})
+	if err != nil || publish.Implementation != ImplementationCLI || publish.Value != nil {
+		t.Errorf("Expected publish through npm, got %+v, %v", publish, err)
+	}
+
+	// npm可用性只检测一次
+	if client.checks != 1 {
+		t.Errorf("Expected one availability check, got %d", client.checks)
+	}
+
+	// 离线时只使用npm命令
+	router.SetOffline(true)
+	if impl, _, err := router.Select(ctx, RouteView); err != nil || impl != ImplementationCLI {
+		t.Errorf("Expected npm offline, got %s, %v", impl, err)
+	}
+	router.SetOperationPolicy(RouteView, PolicyRegistry)
+	if _, err := router.View(ctx, "lodash"); err == nil {
+		t.Error("Expected registry policy to fail offline")
+	}
+}
+
+func TestRouterWithoutCLI(t *testing.T) {
+	ctx := context.Background()
+	router := NewRouter(&routerClient{MockClient: NewMockClient()}, newRouterRegistry(t, http.StatusOK))
+
+	audit, _, err := router.Select(ctx, RouteAudit)
+	if err != nil || audit != ImplementationRegistry {
+		t.Errorf("Expected registry audit without npm, got %s, %v", audit, err)
+	}
+
+	router.SetPolicy(PolicyCLI)
+	if _, err := router.Search(ctx, "lodash"); !errors.Is(err, ErrNpmNotFound) {
+		t.Errorf("Expected ErrNpmNotFound, got %v", err)
+	}
+
+	router.SetOffline(true)
+	router.SetPolicy(PolicyAuto)
+	if _, err := router.View(ctx, "lodash"); !errors.Is(err, ErrNpmNotFound) {
+		t.Errorf("Expected ErrNpmNotFound offline without npm, got %v", err)
+	}
+}
+
+func TestRouterUnsupportedEndpoint(t *testing.T) {
+	ctx := context.Background()
+	router := NewRouter(&routerClient{MockClient: NewMockClient(), available: true}, newRouterRegistry(t, http.StatusNotFound))
+
+	search, err := router.Search(ctx, "lodash")
+	if err != nil {
+		t.Fatalf("Search() failed: %v", err)
+	}
+	if search.Implementation != ImplementationCLI || search.Value[0].Package.Name != "from-cli" {
+		t.Errorf("Expected fallback to npm, got %+v", search)
+	}
+	// 之后的调用直接使用npm命令
+	if impl, reason, _ := router.Select(ctx, RouteSearch); impl != ImplementationCLI || reason != "registry does not support the endpoint" {
+		t.Errorf("Unexpected selection: %s (%s)", impl, reason)
+	}
+
+	// 强制使用registry时不回退
+	router.SetOperationPolicy(RouteSearch, PolicyRegistry)
+	if _, err := router.Search(ctx, "lodash"); !registry.IsNotFound(err) {
+		t.Errorf("Expected registry error, got %v", err)
+	}
+}
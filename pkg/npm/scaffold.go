@@ -0,0 +1,272 @@
+package npm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// TemplateManifestFile 模板描述文件名，不会被复制到生成的项目中
+const TemplateManifestFile = "scaffold.json"
+
+// templateVariablePattern 模板变量，例如{{name}}或{{ author }}
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// Template 项目模板
+//
+// 模板可以来自磁盘目录或embed.FS等任意fs.FS。文件内容和路径中的{{变量}}会在生成时替换。
+type Template struct {
+	Name string
+	fsys fs.FS
+}
+
+// TemplateManifest 模板描述
+//
+// Files中的键为模板内的文件或目录路径，值为条件名称，以"!"开头表示条件为假时才生成，例如
+// {"files": {"tsconfig.json": "typescript", "src/index.js": "!typescript"}}。
+type TemplateManifest struct {
+	Description string            `json:"description,omitempty"`
+	Variables   map[string]string `json:"variables,omitempty"` // 变量默认值
+	Files       map[string]string `json:"files,omitempty"`     // 条件文件
+}
+
+// ScaffoldHook 项目生成后执行的钩子
+type ScaffoldHook func(ctx context.Context, result *ScaffoldResult) error
+
+// ScaffoldOptions 项目生成选项
+type ScaffoldOptions struct {
+	TargetDir  string            `json:"target_dir"`
+	Variables  map[string]string `json:"variables,omitempty"`  // 模板变量，name默认为目标目录名
+	Conditions map[string]bool   `json:"conditions,omitempty"` // 条件文件的开关
+	Overwrite  bool              `json:"overwrite,omitempty"`  // 覆盖已存在的文件
+	Hooks      []ScaffoldHook    `json:"-"`                    // 生成后按顺序执行
+}
+
+// ScaffoldResult 项目生成结果
+type ScaffoldResult struct {
+	TargetDir  string            `json:"target_dir"`
+	Variables  map[string]string `json:"variables"`
+	Files      []string          `json:"files"`                // 已生成的文件，相对于目标目录
+	Skipped    []string          `json:"skipped,omitempty"`    // 因条件不满足而跳过的文件
+	Unresolved []string          `json:"unresolved,omitempty"` // 未提供值的变量，保留原样
+}
+
+// Scaffolder 项目脚手架
+type Scaffolder struct {
+	template *Template
+}
+
+// NewTemplate 从fs.FS创建模板，可用于embed.FS
+func NewTemplate(name string, fsys fs.FS) *Template {
+	return &Template{Name: name, fsys: fsys}
+}
+
+// LoadTemplateDir 从目录加载模板
+func LoadTemplateDir(dir string) (*Template, error) {
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access template directory: %w", err)
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("template path is not a directory: %s", dir)
+	}
+
+	return NewTemplate(filepath.Base(dir), os.DirFS(dir)), nil
+}
+
+// Manifest 读取模板描述，模板没有描述文件时返回空描述
+func (t *Template) Manifest() (*TemplateManifest, error) {
+	manifest := &TemplateManifest{}
+	data, err := fs.ReadFile(t.fsys, TemplateManifestFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+		return nil, fmt.Errorf("failed to read template manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse template manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// NewScaffolder 创建项目脚手架
+func NewScaffolder(template *Template) *Scaffolder {
+	return &Scaffolder{template: template}
+}
+
+// Generate 根据模板生成项目，然后依次执行钩子
+func (s *Scaffolder) Generate(ctx context.Context, options ScaffoldOptions) (*ScaffoldResult, error) {
+	if s.template == nil || s.template.fsys == nil {
+		return nil, fmt.Errorf("scaffolder has no template")
+	}
+	if options.TargetDir == "" {
+		return nil, NewValidationError("target_dir", options.TargetDir, "target directory cannot be empty")
+	}
+
+	targetDir, err := filepath.Abs(options.TargetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve target directory: %w", err)
+	}
+
+	manifest, err := s.template.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	variables := map[string]string{"name": filepath.Base(targetDir)}
+	for key, value := range manifest.Variables {
+		variables[key] = value
+	}
+	for key, value := range options.Variables {
+		variables[key] = value
+	}
+
+	result := &ScaffoldResult{
+		TargetDir: targetDir,
+		Variables: variables,
+	}
+	unresolved := make(map[string]bool)
+
+	err = fs.WalkDir(s.template.fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." || name == TemplateManifestFile {
+			return nil
+		}
+
+		if !templateConditionMet(manifest.Files, name, options.Conditions) {
+			result.Skipped = append(result.Skipped, name)
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		relPath := substituteTemplateVariables(name, variables, unresolved)
+		target := filepath.Join(targetDir, filepath.FromSlash(relPath))
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		if !options.Overwrite {
+			if _, err := os.Stat(target); err == nil {
+				return fmt.Errorf("file already exists: %s", target)
+			}
+		}
+
+		content, err := fs.ReadFile(s.template.fsys, name)
+		if err != nil {
+			return err
+		}
+		// 二进制文件原样复制
+		if !bytes.Contains(content, []byte{0}) {
+			content = []byte(substituteTemplateVariables(string(content), variables, unresolved))
+		}
+
+		mode := os.FileMode(0644)
+		if info, err := entry.Info(); err == nil && info.Mode()&0111 != 0 {
+			mode = 0755
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, mode); err != nil {
+			return err
+		}
+
+		result.Files = append(result.Files, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate project from template %s: %w", s.template.Name, err)
+	}
+
+	for name := range unresolved {
+		result.Unresolved = append(result.Unresolved, name)
+	}
+	sort.Strings(result.Unresolved)
+
+	for i, hook := range options.Hooks {
+		if err := hook(ctx, result); err != nil {
+			return result, fmt.Errorf("post-generate hook %d failed: %w", i+1, err)
+		}
+	}
+
+	return result, nil
+}
+
+// templateConditionMet 检查文件或其所在目录的条件是否满足
+func templateConditionMet(files map[string]string, name string, conditions map[string]bool) bool {
+	for current := name; current != "." && current != "/"; current = path.Dir(current) {
+		condition, ok := files[current]
+		if !ok {
+			continue
+		}
+
+		negate := strings.HasPrefix(condition, "!")
+		enabled := conditions[strings.TrimPrefix(condition, "!")]
+		if enabled == negate {
+			return false
+		}
+	}
+	return true
+}
+
+// substituteTemplateVariables 替换模板变量，未定义的变量保留原样并记录
+func substituteTemplateVariables(content string, variables map[string]string, unresolved map[string]bool) string {
+	return templateVariablePattern.ReplaceAllStringFunc(content, func(match string) string {
+		name := templateVariablePattern.FindStringSubmatch(match)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		unresolved[name] = true
+		return match
+	})
+}
+
+// InstallDependenciesHook 在生成的项目中安装依赖
+func InstallDependenciesHook(manager ProjectManager) ScaffoldHook {
+	return CommandHook(string(manager), "install")
+}
+
+// GitInitHook 在生成的项目中初始化git仓库
+func GitInitHook() ScaffoldHook {
+	return CommandHook("git", "init")
+}
+
+// CommandHook 在生成的项目目录中执行命令
+func CommandHook(command string, args ...string) ScaffoldHook {
+	return func(ctx context.Context, result *ScaffoldResult) error {
+		executeOptions := utils.ExecuteOptions{
+			Command:       command,
+			Args:          args,
+			WorkingDir:    result.TargetDir,
+			CaptureOutput: true,
+			Timeout:       10 * time.Minute,
+		}
+
+		execResult, err := utils.NewExecutor().Execute(ctx, executeOptions)
+		if err != nil {
+			return fmt.Errorf("%s %s failed: %w", command, strings.Join(args, " "), err)
+		}
+		if !execResult.Success {
+			return fmt.Errorf("%s %s failed: %s", command, strings.Join(args, " "), strings.TrimSpace(execResult.Stderr))
+		}
+		return nil
+	}
+}
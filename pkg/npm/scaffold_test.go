@@ -0,0 +1,177 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testTemplateFS() fstest.MapFS {
+	return fstest.MapFS{
+		"scaffold.json": {Data: []byte(`{
+			"variables": {"license": "MIT"},
+			"files": {"tsconfig.json": "typescript", "src/index.js": "!typescript", "ts": "typescript"}
+		}`)},
+		"package.json":    {Data: []byte(`{"name": "{{name}}", "author": "{{ author }}", "license": "{{license}}"}`)},
+		"README.md":       {Data: []byte("# {{name}}\n\n{{unknown}}\n")},
+		"tsconfig.json":   {Data: []byte(`{}`)},
+		"src/index.js":    {Data: []byte("console.log('{{name}}')\n")},
+		"ts/index.ts":     {Data: []byte("export {}\n")},
+		"bin/{{name}}.sh": {Data: []byte("#!/bin/sh\n"), Mode: 0755},
+		"assets/logo.bin": {Data: []byte{0, 1, '{', '{', 'n', 'a', 'm', 'e', '}', '}'}},
+	}
+}
+
+func TestScaffolderGenerate(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "my-app")
+	scaffolder := NewScaffolder(NewTemplate("test", testTemplateFS()))
+
+	result, err := scaffolder.Generate(context.Background(), ScaffoldOptions{
+		TargetDir: target,
+		Variables: map[string]string{"author": "Jane"},
+	})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(target, "package.json"))
+	if err != nil {
+		t.Fatalf("Failed to read package.json: %v", err)
+	}
+	if string(data) != `{"name": "my-app", "author": "Jane", "license": "MIT"}` {
+		t.Errorf("Unexpected package.json: %s", data)
+	}
+
+	if _, err := os.Stat(filepath.Join(target, "src", "index.js")); err != nil {
+		t.Error("Expected negated conditional file to be generated")
+	}
+	if _, err := os.Stat(filepath.Join(target, "tsconfig.json")); err == nil {
+		t.Error("Expected conditional file to be skipped")
+	}
+	if _, err := os.Stat(filepath.Join(target, "ts")); err == nil {
+		t.Error("Expected conditional directory to be skipped")
+	}
+	if _, err := os.Stat(filepath.Join(target, TemplateManifestFile)); err == nil {
+		t.Error("Expected template manifest not to be copied")
+	}
+
+	info, err := os.Stat(filepath.Join(target, "bin", "my-app.sh"))
+	if err != nil {
+		t.Fatalf("Expected variables in paths to be substituted: %v", err)
+	}
+	if info.Mode()&0111 == 0 {
+		t.Error("Expected executable bit to be preserved")
+	}
+
+	binary, _ := os.ReadFile(filepath.Join(target, "assets", "logo.bin"))
+	if !strings.Contains(string(binary), "{{name}}") {
+		t.Error("Expected binary files to be copied verbatim")
+	}
+
+	if len(result.Unresolved) != 1 || result.Unresolved[0] != "unknown" {
+		t.Errorf("Expected unresolved variable 'unknown', got %v", result.Unresolved)
+	}
+	if len(result.Skipped) != 2 {
+		t.Errorf("Expected 2 skipped entries, got %v", result.Skipped)
+	}
+}
+
+func TestScaffolderConditionsAndOverwrite(t *testing.T) {
+	target := t.TempDir()
+	scaffolder := NewScaffolder(NewTemplate("test", testTemplateFS()))
+	options := ScaffoldOptions{
+		TargetDir:  target,
+		Conditions: map[string]bool{"typescript": true},
+	}
+
+	if _, err := scaffolder.Generate(context.Background(), options); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "ts", "index.ts")); err != nil {
+		t.Error("Expected conditional directory to be generated")
+	}
+	if _, err := os.Stat(filepath.Join(target, "src", "index.js")); err == nil {
+		t.Error("Expected negated conditional file to be skipped")
+	}
+
+	if _, err := scaffolder.Generate(context.Background(), options); err == nil {
+		t.Error("Expected error when files already exist")
+	}
+
+	options.Overwrite = true
+	if _, err := scaffolder.Generate(context.Background(), options); err != nil {
+		t.Errorf("Expected overwrite to succeed, got %v", err)
+	}
+}
+
+func TestScaffolderHooks(t *testing.T) {
+	var calls []string
+	hook := func(name string, err error) ScaffoldHook {
+		return func(ctx context.Context, result *ScaffoldResult) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	scaffolder := NewScaffolder(NewTemplate("test", testTemplateFS()))
+	_, err := scaffolder.Generate(context.Background(), ScaffoldOptions{
+		TargetDir: t.TempDir(),
+		Hooks:     []ScaffoldHook{hook("first", nil), hook("second", errors.New("boom")), hook("third", nil)},
+	})
+	if err == nil || !strings.Contains(err.Error(), "hook 2") {
+		t.Errorf("Expected second hook failure, got %v", err)
+	}
+	if strings.Join(calls, ",") != "first,second" {
+		t.Errorf("Expected hooks to stop after failure, got %v", calls)
+	}
+}
+
+func TestGitInitHook(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	target := t.TempDir()
+	scaffolder := NewScaffolder(NewTemplate("test", testTemplateFS()))
+	if _, err := scaffolder.Generate(context.Background(), ScaffoldOptions{
+		TargetDir: target,
+		Hooks:     []ScaffoldHook{GitInitHook()},
+	}); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(target, ".git")); err != nil {
+		t.Error("Expected git repository to be initialized")
+	}
+}
+
+func TestLoadTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "index.js"), "// {{name}}\n")
+
+	template, err := LoadTemplateDir(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplateDir() failed: %v", err)
+	}
+
+	target := filepath.Join(t.TempDir(), "demo")
+	if _, err := NewScaffolder(template).Generate(context.Background(), ScaffoldOptions{TargetDir: target}); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(target, "index.js"))
+	if string(data) != "// demo\n" {
+		t.Errorf("Unexpected content: %s", data)
+	}
+
+	if _, err := LoadTemplateDir(filepath.Join(dir, "index.js")); err == nil {
+		t.Error("Expected error for non-directory template path")
+	}
+	if _, err := NewScaffolder(template).Generate(context.Background(), ScaffoldOptions{}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for empty target, got %v", err)
+	}
+}
@@ -0,0 +1,447 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// DefaultServiceCacheTTL Service缓存包信息和搜索结果的默认时长
+const DefaultServiceCacheTTL = 5 * time.Minute
+
+// ServiceOptions 服务选项
+type ServiceOptions struct {
+	// Root 项目目录必须位于该目录之下，相对路径的项目目录相对于Root，为空时不限制
+	Root string
+
+	// MaxConcurrency 同时执行的npm命令数，小于等于0时使用CPU核数
+	MaxConcurrency int
+
+	// RateLimit 每秒最多启动的npm命令数，0表示不限制
+	RateLimit float64
+
+	// RateBurst 允许的突发命令数，小于等于0时为1
+	RateBurst int
+
+	// CacheTTL GetPackageInfo和Search结果的缓存时长，0时使用DefaultServiceCacheTTL，小于0时不缓存
+	CacheTTL time.Duration
+
+	// Executor 命令执行器，为nil时使用utils.NewExecutor()
+	Executor utils.CommandExecutor
+
+	// Detector 启动时检测npm使用的检测器，为nil时使用NewDetector()
+	Detector *Detector
+
+	// ClientOptions 创建客户端时附加的选项，例如WithMirror
+	ClientOptions []ClientOption
+}
+
+// ServiceStats 服务的运行统计
+type ServiceStats struct {
+	Uptime           time.Duration `json:"uptime"`
+	CommandsStarted  int64         `json:"commands_started"`
+	CommandsFailed   int64         `json:"commands_failed"`   // 启动失败或退出码不为0的命令
+	CommandsRejected int64         `json:"commands_rejected"` // 等待执行时ctx被取消的命令
+	CommandSeconds   float64       `json:"command_seconds"`   // 所有命令的总耗时
+	InFlight         int64         `json:"in_flight"`
+	Queued           int64         `json:"queued"`
+	CacheHits        int64         `json:"cache_hits"`
+	CacheMisses      int64         `json:"cache_misses"`
+	CacheEntries     int           `json:"cache_entries"`
+}
+
+// ServiceHealth 服务的健康状态，npm可用时Ready为true
+type ServiceHealth struct {
+	Ready    bool          `json:"ready"`
+	Npm      *NpmInfo      `json:"npm,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Uptime   time.Duration `json:"uptime"`
+	InFlight int64         `json:"in_flight"`
+	Queued   int64         `json:"queued"`
+}
+
+// Service 供服务端长期运行使用的SDK入口
+//
+// 启动时检测一次npm并复用结果，所有项目共享一个客户端、包信息缓存以及并发数和速率限制，
+// 请求处理函数通过Project获取限定在某个项目目录中的句柄。Health和Stats可以通过HealthHandler
+// 和MetricsHandler挂载到HTTP服务上。
+type Service struct {
+	root     string
+	client   Client
+	cache    *MemoryCache
+	executor *limitedExecutor
+	npm      *NpmInfo
+	npmErr   error
+	started  time.Time
+
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewService 创建服务并检测npm
+//
+// 检测失败不会返回错误，Health报告未就绪，只读操作仍然可以回退到registry；只有选项无效时返回错误。
+func NewService(ctx context.Context, options ServiceOptions) (*Service, error) {
+	s := &Service{started: time.Now(), stop: make(chan struct{}), done: make(chan struct{})}
+
+	if options.Root != "" {
+		root, err := filepath.Abs(options.Root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve root directory: %w", err)
+		}
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			return nil, NewValidationError("root", options.Root, "root must be an existing directory")
+		}
+		s.root = root
+	}
+
+	detector := options.Detector
+	if detector == nil {
+		detector = NewDetector()
+	}
+	s.npm, s.npmErr = detector.Detect(ctx)
+	npmPath := "npm"
+	if s.npmErr == nil && s.npm.Path != "" {
+		npmPath = s.npm.Path
+	}
+
+	executor := options.Executor
+	if executor == nil {
+		executor = utils.NewExecutor()
+	}
+	s.executor = newLimitedExecutor(executor, options.MaxConcurrency, options.RateLimit, options.RateBurst)
+
+	clientOptions := options.ClientOptions
+	ttl := options.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultServiceCacheTTL
+	}
+	if ttl > 0 {
+		s.cache = NewMemoryCache(ttl)
+		clientOptions = append([]ClientOption{WithCache(&countingCache{cache: s.cache, hits: &s.cacheHits, misses: &s.cacheMisses})}, clientOptions...)
+	}
+
+	client, err := NewClientWithExecutor(npmPath, s.executor, clientOptions...)
+	if err != nil {
+		return nil, err
+	}
+	s.client = client
+
+	go s.purgeCache(ttl)
+	return s, nil
+}
+
+// purgeCache 定期清理过期的缓存条目，直到Close
+func (s *Service) purgeCache(ttl time.Duration) {
+	defer close(s.done)
+	if s.cache == nil {
+		<-s.stop
+		return
+	}
+
+	ticker := time.NewTicker(ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.cache.Purge()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close 停止后台清理，不影响进行中的命令
+func (s *Service) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+	return nil
+}
+
+// Client 返回共享的客户端，命令受服务的并发数和速率限制
+func (s *Service) Client() Client {
+	return s.client
+}
+
+// SetLogger 设置日志记录器，nil表示不记录
+func (s *Service) SetLogger(logger *slog.Logger) {
+	s.client.SetLogger(logger)
+}
+
+// Project 返回dir中项目的句柄
+//
+// 设置了Root时相对路径相对于Root，解析后不在Root之下的目录返回*ValidationError，
+// 可以直接使用请求中的项目路径。句柄很轻量，每个请求创建一个即可。
+func (s *Service) Project(dir string) (*Project, error) {
+	if dir == "" {
+		return nil, NewValidationError("dir", dir, "project directory cannot be empty")
+	}
+	if s.root != "" && !filepath.IsAbs(dir) {
+		dir = filepath.Join(s.root, dir)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+	if s.root != "" {
+		rel, err := filepath.Rel(s.root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, NewValidationError("dir", dir, "project directory must be inside the service root")
+		}
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return nil, NewValidationError("dir", dir, "project directory does not exist")
+	}
+
+	manager, err := NewDependencyManager(s.client, abs)
+	if err != nil {
+		return nil, err
+	}
+	return &Project{DependencyManager: manager, dir: abs, client: s.client}, nil
+}
+
+// Health 返回服务的健康状态
+func (s *Service) Health() ServiceHealth {
+	health := ServiceHealth{
+		Ready:    s.npmErr == nil,
+		Npm:      s.npm,
+		Uptime:   time.Since(s.started),
+		InFlight: s.executor.inFlight.Load(),
+		Queued:   s.executor.queued.Load(),
+	}
+	if s.npmErr != nil {
+		health.Error = s.npmErr.Error()
+	}
+	return health
+}
+
+// Stats 返回服务的运行统计
+func (s *Service) Stats() ServiceStats {
+	stats := ServiceStats{
+		Uptime:           time.Since(s.started),
+		CommandsStarted:  s.executor.started.Load(),
+		CommandsFailed:   s.executor.failed.Load(),
+		CommandsRejected: s.executor.rejected.Load(),
+		CommandSeconds:   time.Duration(s.executor.nanos.Load()).Seconds(),
+		InFlight:         s.executor.inFlight.Load(),
+		Queued:           s.executor.queued.Load(),
+		CacheHits:        s.cacheHits.Load(),
+		CacheMisses:      s.cacheMisses.Load(),
+	}
+	if s.cache != nil {
+		stats.CacheEntries = s.cache.Len()
+	}
+	return stats
+}
+
+// HealthHandler 以JSON返回Health，就绪时状态码为200，否则为503
+func (s *Service) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := s.Health()
+		w.Header().Set("Content-Type", "application/json")
+		if !health.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(health)
+	})
+}
+
+// MetricsHandler 以Prometheus文本格式返回Stats，指标名以go_npm_sdk_开头
+func (s *Service) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats := s.Stats()
+		metrics := []struct {
+			name, kind, help string
+			value            float64
+		}{
+			{"go_npm_sdk_uptime_seconds", "gauge", "Time since the service was created.", stats.Uptime.Seconds()},
+			{"go_npm_sdk_commands_started_total", "counter", "npm commands started.", float64(stats.CommandsStarted)},
+			{"go_npm_sdk_commands_failed_total", "counter", "npm commands that failed to start or exited with a non-zero code.", float64(stats.CommandsFailed)},
+			{"go_npm_sdk_commands_rejected_total", "counter", "npm commands cancelled while waiting for a slot.", float64(stats.CommandsRejected)},
+			{"go_npm_sdk_command_seconds_total", "counter", "Total time spent running npm commands.", stats.CommandSeconds},
+			{"go_npm_sdk_commands_in_flight", "gauge", "npm commands currently running.", float64(stats.InFlight)},
+			{"go_npm_sdk_commands_queued", "gauge", "npm commands waiting for a slot.", float64(stats.Queued)},
+			{"go_npm_sdk_cache_hits_total", "counter", "Package info and search cache hits.", float64(stats.CacheHits)},
+			{"go_npm_sdk_cache_misses_total", "counter", "Package info and search cache misses.", float64(stats.CacheMisses)},
+			{"go_npm_sdk_cache_entries", "gauge", "Entries in the package info and search cache.", float64(stats.CacheEntries)},
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		for _, metric := range metrics {
+			fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value)
+		}
+	})
+}
+
+// Project 限定在某个项目目录中的句柄，由Service.Project创建
+//
+// 嵌入的DependencyManager的所有操作都在该目录中执行。
+type Project struct {
+	*DependencyManager
+	dir    string
+	client Client
+}
+
+// Dir 项目目录的绝对路径
+func (p *Project) Dir() string {
+	return p.dir
+}
+
+// RunScript 在项目目录中运行package.json中的脚本
+func (p *Project) RunScript(ctx context.Context, script string, args ...string) error {
+	return p.client.RunScriptWithOptions(ctx, script, RunScriptOptions{Args: args, WorkingDir: p.dir})
+}
+
+// limitedExecutor 限制并发数和启动速率并统计命令的执行器
+type limitedExecutor struct {
+	executor utils.CommandExecutor
+	slots    chan struct{}
+	limiter  *rateLimiter
+
+	started  atomic.Int64
+	failed   atomic.Int64
+	rejected atomic.Int64
+	nanos    atomic.Int64
+	inFlight atomic.Int64
+	queued   atomic.Int64
+}
+
+// newLimitedExecutor 创建限制执行器，concurrency小于等于0时使用CPU核数，rate为0时不限制速率
+func newLimitedExecutor(executor utils.CommandExecutor, concurrency int, rate float64, burst int) *limitedExecutor {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	e := &limitedExecutor{executor: executor, slots: make(chan struct{}, concurrency)}
+	if rate > 0 {
+		e.limiter = newRateLimiter(rate, burst)
+	}
+	return e
+}
+
+// Execute 实现utils.CommandExecutor接口，等待速率限制和空闲的执行槽，等待时ctx取消返回ctx.Err()
+func (e *limitedExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	e.queued.Add(1)
+	err := e.acquire(ctx)
+	e.queued.Add(-1)
+	if err != nil {
+		// 与utils.Executor一致，取消时也返回结果
+		e.rejected.Add(1)
+		return &utils.ExecuteResult{Cancelled: true, Error: err}, err
+	}
+	defer func() { <-e.slots }()
+
+	e.started.Add(1)
+	e.inFlight.Add(1)
+	start := time.Now()
+	result, err := e.executor.Execute(ctx, options)
+	e.nanos.Add(int64(time.Since(start)))
+	e.inFlight.Add(-1)
+	if err != nil || result == nil || !result.Success {
+		e.failed.Add(1)
+	}
+	return result, err
+}
+
+// acquire 先等待速率限制再占用执行槽，ctx取消时返回错误
+func (e *limitedExecutor) acquire(ctx context.Context) error {
+	if e.limiter != nil {
+		if err := e.limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+	select {
+	case e.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetLogger 设置被包装执行器的日志记录器
+func (e *limitedExecutor) SetLogger(logger *slog.Logger) {
+	if executor, ok := e.executor.(interface{ SetLogger(*slog.Logger) }); ok {
+		executor.SetLogger(logger)
+	}
+}
+
+// rateLimiter 令牌桶速率限制
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter 创建令牌桶，初始时令牌是满的
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait 取得一个令牌，没有令牌时等待到下一个令牌补充或ctx取消
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// countingCache 统计命中率的缓存包装
+type countingCache struct {
+	cache  Cache
+	hits   *atomic.Int64
+	misses *atomic.Int64
+}
+
+// Get 实现Cache接口
+func (c *countingCache) Get(key string) ([]byte, bool) {
+	value, ok := c.cache.Get(key)
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return value, ok
+}
+
+// Set 实现Cache接口
+func (c *countingCache) Set(key string, value []byte) {
+	c.cache.Set(key, value)
+}
@@ -0,0 +1,191 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// serviceExecutor 按参数返回结果，release关闭前阻塞所有命令
+type serviceExecutor struct {
+	release chan struct{}
+	running atomic.Int64
+	peak    atomic.Int64
+}
+
+func (e *serviceExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	running := e.running.Add(1)
+	defer e.running.Add(-1)
+	for {
+		peak := e.peak.Load()
+		if running <= peak || e.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+	if e.release != nil {
+		<-e.release
+	}
+
+	switch strings.Join(options.Args, " ") {
+	case "config get registry":
+		return &utils.ExecuteResult{Success: true, Stdout: "https://registry.npmjs.org/\n"}, nil
+	case "view lodash --json":
+		return &utils.ExecuteResult{Success: true, Stdout: `{"name":"lodash","version":"4.17.21"}`}, nil
+	case "--version":
+		return &utils.ExecuteResult{Success: true, Stdout: "10.2.0\n"}, nil
+	}
+	return &utils.ExecuteResult{ExitCode: 1}, errors.New("exit status 1")
+}
+
+func newTestService(t *testing.T, options ServiceOptions) *Service {
+	t.Helper()
+	service, err := NewService(context.Background(), options)
+	if err != nil {
+		t.Fatalf("NewService() failed: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+	return service
+}
+
+func TestServiceConcurrencyLimit(t *testing.T) {
+	executor := &serviceExecutor{release: make(chan struct{})}
+	service := newTestService(t, ServiceOptions{Executor: executor, MaxConcurrency: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service.Client().Version(context.Background())
+		}()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for service.Stats().Queued != 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if stats := service.Stats(); stats.InFlight != 2 || stats.Queued != 3 {
+		t.Errorf("Expected 2 running and 3 queued commands, got %+v", stats)
+	}
+
+	// 所有执行槽都被占用时等待的命令随ctx取消
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := service.Client().Version(ctx); err == nil {
+		t.Error("Expected cancelled command to fail")
+	}
+
+	close(executor.release)
+	wg.Wait()
+	if executor.peak.Load() != 2 {
+		t.Errorf("Expected at most 2 concurrent commands, got %d", executor.peak.Load())
+	}
+	stats := service.Stats()
+	if stats.CommandsStarted != 5 || stats.CommandsRejected != 1 || stats.CommandsFailed != 0 || stats.InFlight != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestServiceRateLimit(t *testing.T) {
+	service := newTestService(t, ServiceOptions{Executor: &serviceExecutor{}, RateLimit: 0.001, RateBurst: 1})
+
+	if _, err := service.Client().Version(context.Background()); err != nil {
+		t.Fatalf("Expected first command within burst to run: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := service.Client().Version(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected rate limited command to time out, got %v", err)
+	}
+	if stats := service.Stats(); stats.CommandsStarted != 1 || stats.CommandsRejected != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestServiceCache(t *testing.T) {
+	service := newTestService(t, ServiceOptions{Executor: &serviceExecutor{}})
+
+	for i := 0; i < 3; i++ {
+		info, err := service.Client().GetPackageInfo(context.Background(), "lodash")
+		if err != nil || info.Version != "4.17.21" {
+			t.Fatalf("GetPackageInfo() = %+v, %v", info, err)
+		}
+	}
+	if stats := service.Stats(); stats.CacheHits != 2 || stats.CacheMisses != 1 || stats.CacheEntries != 1 {
+		t.Errorf("Unexpected cache stats: %+v", stats)
+	}
+
+	uncached := newTestService(t, ServiceOptions{Executor: &serviceExecutor{}, CacheTTL: -1})
+	uncached.Client().GetPackageInfo(context.Background(), "lodash")
+	uncached.Client().GetPackageInfo(context.Background(), "lodash")
+	if stats := uncached.Stats(); stats.CacheHits != 0 || stats.CacheMisses != 0 {
+		t.Errorf("Expected no cache, got %+v", stats)
+	}
+}
+
+func TestServiceProject(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "apps", "web"), 0755)
+	outside := t.TempDir()
+
+	service := newTestService(t, ServiceOptions{Root: root, Executor: &serviceExecutor{}})
+
+	project, err := service.Project("apps/web")
+	if err != nil {
+		t.Fatalf("Project() failed: %v", err)
+	}
+	if project.Dir() != filepath.Join(root, "apps", "web") || project.workingDir != project.Dir() {
+		t.Errorf("Unexpected project dir %s", project.Dir())
+	}
+	if _, err := service.Project(filepath.Join(root, "apps")); err != nil {
+		t.Errorf("Expected absolute path inside root to be allowed: %v", err)
+	}
+
+	for _, dir := range []string{"", "../" + filepath.Base(outside), outside, "apps/missing", "apps/web/../../.."} {
+		var validationErr *ValidationError
+		if _, err := service.Project(dir); !errors.As(err, &validationErr) {
+			t.Errorf("Project(%q) expected ValidationError, got %v", dir, err)
+		}
+	}
+
+	if _, err := NewService(context.Background(), ServiceOptions{Root: filepath.Join(root, "missing")}); err == nil {
+		t.Error("Expected error for missing root")
+	}
+}
+
+func TestServiceHandlers(t *testing.T) {
+	service := newTestService(t, ServiceOptions{Executor: &serviceExecutor{}})
+	service.Client().Version(context.Background())
+
+	recorder := httptest.NewRecorder()
+	service.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range []string{
+		"# TYPE go_npm_sdk_commands_started_total counter\n",
+		"go_npm_sdk_commands_started_total 1\n",
+		"go_npm_sdk_commands_in_flight 0\n",
+	} {
+		if !strings.Contains(recorder.Body.String(), line) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, recorder.Body.String())
+		}
+	}
+
+	health := service.Health()
+	if health.Ready != (health.Error == "") {
+		t.Errorf("Inconsistent health: %+v", health)
+	}
+	recorder = httptest.NewRecorder()
+	service.HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if want := map[bool]int{true: http.StatusOK, false: http.StatusServiceUnavailable}[health.Ready]; recorder.Code != want {
+		t.Errorf("Expected status %d, got %d", want, recorder.Code)
+	}
+}
@@ -0,0 +1,240 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+// SizeThresholds 包体积检查的阈值，为0的项不检查
+type SizeThresholds struct {
+	MaxSizeIncrease         float64 `json:"max_size_increase,omitempty"`          // tarball大小相对基线的最大增幅，0.2表示20%
+	MaxUnpackedSizeIncrease float64 `json:"max_unpacked_size_increase,omitempty"` // 解压后大小相对基线的最大增幅
+	MaxSize                 int64   `json:"max_size,omitempty"`                   // tarball大小上限（字节）
+	MaxUnpackedSize         int64   `json:"max_unpacked_size,omitempty"`          // 解压后大小上限（字节）
+	MaxAddedFiles           int     `json:"max_added_files,omitempty"`            // 相对基线最多新增的文件数
+	MaxFileSize             int64   `json:"max_file_size,omitempty"`              // 新增或变大的单个文件的大小上限（字节）
+}
+
+// DefaultSizeThresholds 默认阈值：体积增长不超过25%，新增文件不超过50个
+func DefaultSizeThresholds() SizeThresholds {
+	return SizeThresholds{
+		MaxSizeIncrease:         0.25,
+		MaxUnpackedSizeIncrease: 0.25,
+		MaxAddedFiles:           50,
+	}
+}
+
+// SizeCheckOptions 包体积检查选项
+type SizeCheckOptions struct {
+	WorkingDir string            `json:"working_dir,omitempty"`
+	Baseline   string            `json:"baseline,omitempty"`    // 对比的已发布版本或dist-tag，默认latest
+	Thresholds *SizeThresholds   `json:"thresholds,omitempty"`  // 为nil时使用DefaultSizeThresholds
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// FileSizeChange 文件大小变化
+type FileSizeChange struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Baseline int64  `json:"baseline"`
+}
+
+// SizeViolation 超出阈值的项
+type SizeViolation struct {
+	Metric  string `json:"metric"` // 对应SizeThresholds的字段名
+	Message string `json:"message"`
+}
+
+// SizeReport 包体积检查结果
+type SizeReport struct {
+	Package              string           `json:"package"`
+	Version              string           `json:"version"`
+	BaselineVersion      string           `json:"baseline_version,omitempty"`
+	FirstPublish         bool             `json:"first_publish,omitempty"` // registry中没有基线版本
+	Size                 int64            `json:"size"`
+	BaselineSize         int64            `json:"baseline_size"`
+	UnpackedSize         int64            `json:"unpacked_size"`
+	BaselineUnpackedSize int64            `json:"baseline_unpacked_size"`
+	FileCount            int              `json:"file_count"`
+	BaselineFileCount    int              `json:"baseline_file_count"`
+	AddedFiles           []PackFile       `json:"added_files,omitempty"`
+	RemovedFiles         []string         `json:"removed_files,omitempty"`
+	GrownFiles           []FileSizeChange `json:"grown_files,omitempty"`
+	Violations           []SizeViolation  `json:"violations,omitempty"`
+}
+
+// Passed 没有超出阈值
+func (r *SizeReport) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+// SizeChecker 把即将发布的包与registry中已发布的版本比较体积
+type SizeChecker struct {
+	client   Client
+	registry *registry.Client
+}
+
+// NewSizeChecker 创建包体积检查器
+func NewSizeChecker(npmClient Client, registryClient *registry.Client) *SizeChecker {
+	return &SizeChecker{
+		client:   npmClient,
+		registry: registryClient,
+	}
+}
+
+// Check 以dry-run方式打包，下载基线版本的tarball并比较
+func (s *SizeChecker) Check(ctx context.Context, options SizeCheckOptions) (*SizeReport, error) {
+	current, err := s.client.Pack(ctx, PackOptions{
+		WorkingDir: options.WorkingDir,
+		DryRun:     true,
+		Env:        options.Env,
+		UserConfig: options.UserConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	thresholds := DefaultSizeThresholds()
+	if options.Thresholds != nil {
+		thresholds = *options.Thresholds
+	}
+
+	baseline, err := s.fetchBaseline(ctx, current.Name, options.Baseline)
+	if err != nil {
+		return nil, err
+	}
+	return CompareSize(current, baseline, thresholds), nil
+}
+
+// fetchBaseline 下载基线版本并统计文件，版本不存在时返回nil
+func (s *SizeChecker) fetchBaseline(ctx context.Context, name, spec string) (*PackResult, error) {
+	manifest, err := s.registry.GetManifest(ctx, name, spec)
+	if registry.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch baseline manifest: %w", err)
+	}
+
+	tarball, err := s.registry.Download(ctx, manifest.Dist.Tarball)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download baseline tarball: %w", err)
+	}
+	entries, err := readTarballEntries(tarball)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := &PackResult{
+		Name:    manifest.Name,
+		Version: manifest.Version,
+		Size:    int64(len(tarball)),
+	}
+	for _, entry := range entries {
+		size := int64(len(entry.data))
+		baseline.Files = append(baseline.Files, PackFile{
+			Path: trimPackagePrefix(entry.name),
+			Size: size,
+			Mode: int(entry.mode),
+		})
+		baseline.UnpackedSize += size
+	}
+	baseline.EntryCount = len(baseline.Files)
+	return baseline, nil
+}
+
+// trimPackagePrefix 去掉tarball路径的第一级目录，与npm pack --json的文件路径一致
+func trimPackagePrefix(name string) string {
+	if i := strings.Index(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// CompareSize 比较两次打包结果，baseline为nil表示首次发布，只检查绝对上限
+func CompareSize(current, baseline *PackResult, thresholds SizeThresholds) *SizeReport {
+	report := &SizeReport{
+		Package:      current.Name,
+		Version:      current.Version,
+		FirstPublish: baseline == nil,
+		Size:         current.Size,
+		UnpackedSize: current.UnpackedSize,
+		FileCount:    len(current.Files),
+	}
+	violate := func(metric, format string, args ...interface{}) {
+		report.Violations = append(report.Violations, SizeViolation{Metric: metric, Message: fmt.Sprintf(format, args...)})
+	}
+
+	baselineFiles := make(map[string]int64)
+	if baseline != nil {
+		report.BaselineVersion = baseline.Version
+		report.BaselineSize = baseline.Size
+		report.BaselineUnpackedSize = baseline.UnpackedSize
+		report.BaselineFileCount = len(baseline.Files)
+		for _, file := range baseline.Files {
+			baselineFiles[file.Path] = file.Size
+		}
+	}
+
+	currentFiles := make(map[string]bool)
+	for _, file := range current.Files {
+		currentFiles[file.Path] = true
+		// 首次发布时所有文件都视为新增，但不列入AddedFiles
+		changed := baseline == nil
+		if previous, existed := baselineFiles[file.Path]; baseline != nil && !existed {
+			report.AddedFiles = append(report.AddedFiles, file)
+			changed = true
+		} else if existed && file.Size > previous {
+			report.GrownFiles = append(report.GrownFiles, FileSizeChange{Path: file.Path, Size: file.Size, Baseline: previous})
+			changed = true
+		}
+		if changed && thresholds.MaxFileSize > 0 && file.Size > thresholds.MaxFileSize {
+			violate("MaxFileSize", "%s is %d bytes, limit is %d", file.Path, file.Size, thresholds.MaxFileSize)
+		}
+	}
+	for path := range baselineFiles {
+		if !currentFiles[path] {
+			report.RemovedFiles = append(report.RemovedFiles, path)
+		}
+	}
+	sort.Strings(report.RemovedFiles)
+	sort.Slice(report.AddedFiles, func(i, j int) bool { return report.AddedFiles[i].Path < report.AddedFiles[j].Path })
+	sort.Slice(report.GrownFiles, func(i, j int) bool { return report.GrownFiles[i].Path < report.GrownFiles[j].Path })
+
+	if thresholds.MaxSize > 0 && current.Size > thresholds.MaxSize {
+		violate("MaxSize", "tarball is %d bytes, limit is %d", current.Size, thresholds.MaxSize)
+	}
+	if thresholds.MaxUnpackedSize > 0 && current.UnpackedSize > thresholds.MaxUnpackedSize {
+		violate("MaxUnpackedSize", "unpacked size is %d bytes, limit is %d", current.UnpackedSize, thresholds.MaxUnpackedSize)
+	}
+	if baseline == nil {
+		return report
+	}
+
+	if increase, ok := sizeIncrease(current.Size, baseline.Size); ok && thresholds.MaxSizeIncrease > 0 && increase > thresholds.MaxSizeIncrease {
+		violate("MaxSizeIncrease", "tarball grew %.1f%% (%d -> %d bytes), limit is %.1f%%",
+			increase*100, baseline.Size, current.Size, thresholds.MaxSizeIncrease*100)
+	}
+	if increase, ok := sizeIncrease(current.UnpackedSize, baseline.UnpackedSize); ok && thresholds.MaxUnpackedSizeIncrease > 0 && increase > thresholds.MaxUnpackedSizeIncrease {
+		violate("MaxUnpackedSizeIncrease", "unpacked size grew %.1f%% (%d -> %d bytes), limit is %.1f%%",
+			increase*100, baseline.UnpackedSize, current.UnpackedSize, thresholds.MaxUnpackedSizeIncrease*100)
+	}
+	if thresholds.MaxAddedFiles > 0 && len(report.AddedFiles) > thresholds.MaxAddedFiles {
+		violate("MaxAddedFiles", "%d files added, limit is %d", len(report.AddedFiles), thresholds.MaxAddedFiles)
+	}
+
+	return report
+}
+
+// sizeIncrease 计算相对增幅，基线为0时无法计算
+func sizeIncrease(current, baseline int64) (float64, bool) {
+	if baseline <= 0 {
+		return 0, false
+	}
+	return float64(current-baseline) / float64(baseline), true
+}
@@ -0,0 +1,144 @@
+package npm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+// packStubClient Pack返回固定结果的客户端
+type packStubClient struct {
+	*MockClient
+	packed *PackResult
+}
+
+func (c *packStubClient) Pack(ctx context.Context, options PackOptions) (*PackResult, error) {
+	return c.packed, nil
+}
+
+func TestCompareSize(t *testing.T) {
+	baseline := &PackResult{
+		Version:      "1.0.0",
+		Size:         1000,
+		UnpackedSize: 3000,
+		Files: []PackFile{
+			{Path: "package.json", Size: 100},
+			{Path: "index.js", Size: 900},
+			{Path: "old.js", Size: 2000},
+		},
+	}
+	current := &PackResult{
+		Name:         "pkg",
+		Version:      "1.1.0",
+		Size:         1500,
+		UnpackedSize: 3400,
+		Files: []PackFile{
+			{Path: "package.json", Size: 100},
+			{Path: "index.js", Size: 1200},
+			{Path: "dist/bundle.js", Size: 2100},
+		},
+	}
+
+	report := CompareSize(current, baseline, SizeThresholds{
+		MaxSizeIncrease:         0.25,
+		MaxUnpackedSizeIncrease: 0.25,
+		MaxFileSize:             2000,
+	})
+	if report.Passed() {
+		t.Fatal("Expected violations")
+	}
+	metrics := make(map[string]bool)
+	for _, violation := range report.Violations {
+		metrics[violation.Metric] = true
+	}
+	// tarball增长50%，解压后增长约13%，bundle.js超过单文件上限
+	if !metrics["MaxSizeIncrease"] || metrics["MaxUnpackedSizeIncrease"] || !metrics["MaxFileSize"] || len(report.Violations) != 2 {
+		t.Errorf("Unexpected violations: %+v", report.Violations)
+	}
+	if len(report.AddedFiles) != 1 || report.AddedFiles[0].Path != "dist/bundle.js" {
+		t.Errorf("Unexpected added files: %+v", report.AddedFiles)
+	}
+	if len(report.RemovedFiles) != 1 || report.RemovedFiles[0] != "old.js" {
+		t.Errorf("Unexpected removed files: %v", report.RemovedFiles)
+	}
+	if len(report.GrownFiles) != 1 || report.GrownFiles[0] != (FileSizeChange{Path: "index.js", Size: 1200, Baseline: 900}) {
+		t.Errorf("Unexpected grown files: %+v", report.GrownFiles)
+	}
+	if report.BaselineVersion != "1.0.0" || report.BaselineFileCount != 3 || report.FileCount != 3 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	// 阈值为0时不检查
+	if report := CompareSize(current, baseline, SizeThresholds{}); !report.Passed() {
+		t.Errorf("Expected no violations without thresholds, got %+v", report.Violations)
+	}
+
+	// 首次发布只检查绝对上限
+	report = CompareSize(current, nil, SizeThresholds{MaxSizeIncrease: 0.01, MaxSize: 1000, MaxAddedFiles: 1})
+	if !report.FirstPublish || len(report.AddedFiles) != 0 {
+		t.Errorf("Unexpected first publish report: %+v", report)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Metric != "MaxSize" {
+		t.Errorf("Unexpected first publish violations: %+v", report.Violations)
+	}
+}
+
+func TestSizeCheckerCheck(t *testing.T) {
+	tarball := buildTestTarball(t, []testTarballFile{
+		{name: "package/package.json", mode: 0644, data: `{"name":"pkg","version":"1.0.0"}`},
+		{name: "package/index.js", mode: 0644, data: "module.exports = 1;\n"},
+	}, time.Now(), 3)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pkg/latest":
+			w.Write([]byte(`{"name":"pkg","version":"1.0.0","dist":{"tarball":"` + server.URL + `/pkg/-/pkg-1.0.0.tgz"}}`))
+		case "/pkg/-/pkg-1.0.0.tgz":
+			w.Write(tarball)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	current := &PackResult{
+		Name:         "pkg",
+		Version:      "1.1.0",
+		Size:         int64(len(tarball)) * 3,
+		UnpackedSize: 52,
+		Files: []PackFile{
+			{Path: "package.json", Size: 32},
+			{Path: "index.js", Size: 20},
+		},
+	}
+	checker := NewSizeChecker(&packStubClient{MockClient: NewMockClient(), packed: current}, registry.NewClient(server.URL))
+
+	report, err := checker.Check(context.Background(), SizeCheckOptions{})
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if report.BaselineVersion != "1.0.0" || report.BaselineSize != int64(len(tarball)) || report.BaselineUnpackedSize != 52 {
+		t.Errorf("Unexpected baseline: %+v", report)
+	}
+	if len(report.AddedFiles) != 0 || len(report.RemovedFiles) != 0 || len(report.GrownFiles) != 0 {
+		t.Errorf("Expected identical file lists: %+v", report)
+	}
+	if report.Passed() || report.Violations[0].Metric != "MaxSizeIncrease" {
+		t.Errorf("Expected size increase violation with default thresholds, got %+v", report.Violations)
+	}
+
+	// registry中没有基线版本
+	report, err = checker.Check(context.Background(), SizeCheckOptions{Baseline: "next"})
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if !report.FirstPublish || !report.Passed() {
+		t.Errorf("Expected first publish without violations, got %+v", report)
+	}
+}
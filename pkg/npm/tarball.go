@@ -0,0 +1,168 @@
+package npm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// tarballModTime npm pack写入的固定修改时间
+var tarballModTime = time.Date(1985, time.October, 26, 8, 15, 0, 0, time.UTC)
+
+// tarballEntry tarball中的一个文件
+type tarballEntry struct {
+	name string
+	mode int64
+	data []byte
+}
+
+// NormalizeTarball 重写tarball，使相同内容在任何机器上都得到相同的字节
+//
+// 文件按路径排序，修改时间固定为npm使用的1985-10-26T08:15:00Z，权限统一为0644
+// （可执行文件为0755），属主信息清空，gzip头不包含时间和操作系统信息。
+// tarball中只保留普通文件，npm pack本身也不会打包目录项和符号链接。
+func NormalizeTarball(data []byte) ([]byte, error) {
+	entries, err := readTarballEntries(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip writer: %w", err)
+	}
+	writer := tar.NewWriter(gz)
+
+	for _, entry := range entries {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     entry.name,
+			Mode:     entry.mode,
+			Size:     int64(len(entry.data)),
+			ModTime:  tarballModTime,
+			Format:   tar.FormatPAX,
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to write tarball: %w", err)
+		}
+		if _, err := writer.Write(entry.data); err != nil {
+			return nil, fmt.Errorf("failed to write tarball: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write tarball: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write tarball: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// TarballContentHash 计算tarball内容的哈希，格式为sha256-<base64>
+//
+// 哈希只取决于文件路径、可执行权限和内容，与压缩方式、修改时间和文件顺序无关，
+// 可以用来判断两次打包的内容是否相同。
+func TarballContentHash(data []byte) (string, error) {
+	entries, err := readTarballEntries(data)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	var size [8]byte
+	for _, entry := range entries {
+		// 以长度前缀分隔各字段，避免不同的路径和内容拼接出相同的输入
+		binary.BigEndian.PutUint64(size[:], uint64(len(entry.name)))
+		hash.Write(size[:])
+		io.WriteString(hash, entry.name)
+		binary.BigEndian.PutUint64(size[:], uint64(entry.mode))
+		hash.Write(size[:])
+		binary.BigEndian.PutUint64(size[:], uint64(len(entry.data)))
+		hash.Write(size[:])
+		hash.Write(entry.data)
+	}
+	return "sha256-" + base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
+// finishPackedTarball 计算内容哈希，需要时规范化tarball并更新大小和校验和
+func finishPackedTarball(result *PackResult, normalize bool) error {
+	data, err := os.ReadFile(result.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read tarball: %w", err)
+	}
+
+	if normalize {
+		data, err = NormalizeTarball(data)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(result.Path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write tarball: %w", err)
+		}
+		sha1Sum := sha1.Sum(data)
+		sha512Sum := sha512.Sum512(data)
+		result.Size = int64(len(data))
+		result.Shasum = hex.EncodeToString(sha1Sum[:])
+		result.Integrity = "sha512-" + base64.StdEncoding.EncodeToString(sha512Sum[:])
+	}
+
+	result.ContentHash, err = TarballContentHash(data)
+	return err
+}
+
+// readTarballEntries 读取tarball中的普通文件，按路径排序，权限规范化
+func readTarballEntries(data []byte) ([]tarballEntry, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tarball: %w", err)
+	}
+	defer gz.Close()
+
+	byName := make(map[string]tarballEntry)
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tarball: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tarball: %w", err)
+		}
+		mode := int64(0644)
+		if header.Mode&0111 != 0 {
+			mode = 0755
+		}
+		// 同名文件以最后一个为准，与解压结果一致
+		byName[header.Name] = tarballEntry{name: header.Name, mode: mode, data: content}
+	}
+
+	entries := make([]tarballEntry, 0, len(byName))
+	for _, entry := range byName {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	return entries, nil
+}
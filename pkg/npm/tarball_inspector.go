@@ -0,0 +1,378 @@
+package npm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// maxInspectUnpackedSize 检查时tarball解压后的大小上限，防止压缩炸弹
+const maxInspectUnpackedSize = 1 << 30
+
+// installScriptNames npm install时自动运行的生命周期脚本
+var installScriptNames = []string{"preinstall", "install", "postinstall"}
+
+// TarballFile tarball中的一个普通文件，路径不含顶层目录（通常是package/）
+type TarballFile struct {
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	Executable bool   `json:"executable,omitempty"`
+	Binary     bool   `json:"binary,omitempty"` // 内容不是文本
+	Native     bool   `json:"native,omitempty"` // ELF、Mach-O、PE等可执行格式或.node模块
+}
+
+// TarballManifest tarball中package.json与审查相关的字段
+type TarballManifest struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Scripts              map[string]string `json:"scripts,omitempty"`
+	Dependencies         map[string]string `json:"dependencies,omitempty"`
+	OptionalDependencies map[string]string `json:"optionalDependencies,omitempty"`
+	Gypfile              *bool             `json:"gypfile,omitempty"` // 为false时不隐式运行node-gyp
+}
+
+// Tarball 已读入内存的包tarball
+type Tarball struct {
+	Files        []TarballFile `json:"files"` // 按路径排序
+	UnpackedSize int64         `json:"unpacked_size"`
+
+	contents map[string][]byte
+}
+
+// ReadFile 读取tarball中的文件，path不含顶层目录
+func (t *Tarball) ReadFile(path string) ([]byte, error) {
+	content, ok := t.contents[strings.TrimPrefix(path, "./")]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in tarball: %w", path, os.ErrNotExist)
+	}
+	return content, nil
+}
+
+// Manifest 解析tarball中的package.json
+func (t *Tarball) Manifest() (*TarballManifest, error) {
+	data, err := t.ReadFile("package.json")
+	if err != nil {
+		return nil, err
+	}
+	var manifest TarballManifest
+	if err := utils.DecodeJSON("package.json", data, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// InstallScripts 返回安装时会运行的脚本
+//
+// 包含binding.gyp且没有install和preinstall脚本时，除非gypfile为false，npm会隐式运行
+// node-gyp rebuild，同样计入结果。
+func (t *Tarball) InstallScripts() (map[string]string, error) {
+	manifest, err := t.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	scripts := make(map[string]string)
+	for _, name := range installScriptNames {
+		if script := manifest.Scripts[name]; script != "" {
+			scripts[name] = script
+		}
+	}
+	_, hasGyp := t.contents["binding.gyp"]
+	if hasGyp && (manifest.Gypfile == nil || *manifest.Gypfile) && scripts["install"] == "" && scripts["preinstall"] == "" {
+		scripts["install"] = "node-gyp rebuild"
+	}
+	return scripts, nil
+}
+
+// SuspiciousPattern 检查文件内容时查找的可疑模式
+type SuspiciousPattern struct {
+	Name        string
+	Description string
+	Regexp      *regexp.Regexp
+}
+
+// DefaultSuspiciousPatterns 默认查找的可疑模式，常见于窃取凭据、下载执行载荷和混淆代码的恶意包
+//
+// 正常的包也可能匹配这些模式，结果只用于提示人工审查。
+var DefaultSuspiciousPatterns = []SuspiciousPattern{
+	{"eval", "dynamic code evaluation", regexp.MustCompile(`\beval\s*\(|\bnew\s+Function\s*\(`)},
+	{"child_process", "spawns processes", regexp.MustCompile(`(?:require\s*\(\s*|from\s+|import\s*\(\s*)['"](?:node:)?child_process['"]`)},
+	{"base64", "decodes base64 data", regexp.MustCompile(`Buffer\.from\s*\([^)]*['"]base64['"]|\batob\s*\(`)},
+	{"download", "downloads and runs remote content", regexp.MustCompile(`\b(?:curl|wget)\s[^|;&]*\|\s*(?:ba|z)?sh\b|\bInvoke-WebRequest\b|\biwr\s`)},
+	{"credentials", "reads credential files", regexp.MustCompile(`\.npmrc\b|\.ssh[/\\]|\bid_rsa\b|\.aws[/\\]credentials|\.docker[/\\]config\.json`)},
+	{"obfuscation", "long escaped or hex-encoded strings", regexp.MustCompile(`(?:\\x[0-9a-fA-F]{2}){16,}|\b_0x[0-9a-f]{4,}\b`)},
+}
+
+// SuspiciousMatch 文件中的一处可疑内容
+type SuspiciousMatch struct {
+	Path    string `json:"path"` // 匹配package.json中的脚本时为package.json#scripts.<name>
+	Line    int    `json:"line"`
+	Pattern string `json:"pattern"`
+	Match   string `json:"match"` // 匹配的文本，过长时截断
+}
+
+// TarballReport 检查tarball的结果
+type TarballReport struct {
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	Files        int    `json:"files"`
+	UnpackedSize int64  `json:"unpacked_size"`
+
+	InstallScripts map[string]string `json:"install_scripts,omitempty"`
+	BinaryFiles    []TarballFile     `json:"binary_files,omitempty"`
+	Suspicious     []SuspiciousMatch `json:"suspicious,omitempty"` // 按路径和行号排序
+}
+
+// HasFindings 是否有安装脚本、原生可执行文件或可疑内容
+func (r *TarballReport) HasFindings() bool {
+	if len(r.InstallScripts) > 0 || len(r.Suspicious) > 0 {
+		return true
+	}
+	for _, file := range r.BinaryFiles {
+		if file.Native {
+			return true
+		}
+	}
+	return false
+}
+
+// TarballInspector 打开本地或registry上的包tarball，检查安装脚本、二进制文件和可疑内容，
+// 用于构建供应链审查工具。检查只读取内容，不解压到磁盘也不运行任何脚本。
+type TarballInspector struct {
+	registry *registry.Client
+	patterns []SuspiciousPattern
+}
+
+// NewTarballInspector 创建tarball检查器，registryClient只在Fetch时使用，可以为nil
+func NewTarballInspector(registryClient *registry.Client) *TarballInspector {
+	return &TarballInspector{registry: registryClient, patterns: DefaultSuspiciousPatterns}
+}
+
+// SetPatterns 设置查找的可疑模式，替换DefaultSuspiciousPatterns
+func (i *TarballInspector) SetPatterns(patterns []SuspiciousPattern) {
+	i.patterns = patterns
+}
+
+// OpenFile 读取本地的.tgz文件，例如npm pack的结果
+func (i *TarballInspector) OpenFile(path string) (*Tarball, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tarball: %w", err)
+	}
+	return i.Open(data)
+}
+
+// Open 读取内存中的tarball
+func (i *TarballInspector) Open(data []byte) (*Tarball, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tarball: %w", err)
+	}
+	defer gz.Close()
+
+	tarball := &Tarball{contents: make(map[string][]byte)}
+	files := make(map[string]TarballFile)
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tarball: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+		_, path, ok := strings.Cut(strings.TrimPrefix(header.Name, "./"), "/")
+		if !ok || path == "" {
+			continue
+		}
+
+		content, err := io.ReadAll(io.LimitReader(reader, maxInspectUnpackedSize-tarball.UnpackedSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tarball: %w", err)
+		}
+		if previous, ok := files[path]; ok {
+			tarball.UnpackedSize -= previous.Size
+		}
+		tarball.UnpackedSize += int64(len(content))
+		if tarball.UnpackedSize > maxInspectUnpackedSize {
+			return nil, fmt.Errorf("unpacked size exceeds %d bytes", maxInspectUnpackedSize)
+		}
+
+		// 同名文件以最后一个为准，与解压结果一致
+		tarball.contents[path] = content
+		files[path] = TarballFile{
+			Path:       path,
+			Size:       int64(len(content)),
+			Executable: header.Mode&0111 != 0,
+			Binary:     isBinaryContent(content),
+			Native:     isNativeFile(path, content),
+		}
+	}
+
+	tarball.Files = make([]TarballFile, 0, len(files))
+	for _, file := range files {
+		tarball.Files = append(tarball.Files, file)
+	}
+	sort.Slice(tarball.Files, func(a, b int) bool { return tarball.Files[a].Path < tarball.Files[b].Path })
+	return tarball, nil
+}
+
+// Fetch 从registry下载pkg（name或name@spec）的tarball，按dist.integrity或dist.shasum校验后读取
+func (i *TarballInspector) Fetch(ctx context.Context, pkg string) (*Tarball, error) {
+	if i.registry == nil {
+		return nil, fmt.Errorf("no registry client configured")
+	}
+	name, spec := splitPackageSpec(pkg)
+	if name == "" {
+		return nil, NewValidationError("package", pkg, "package name cannot be empty")
+	}
+	if spec != "" && !isRegistrySpec(spec) {
+		return nil, NewValidationError("package", pkg, "only registry packages can be fetched")
+	}
+
+	packument, err := i.registry.GetPackumentWithOptions(ctx, name, registry.PackumentOptions{})
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0, len(packument.Versions))
+	for version := range packument.Versions {
+		versions = append(versions, version)
+	}
+	version := resolveSpec(spec, packument.DistTags, versions)
+	if version == "" {
+		return nil, NewValidationError("package", pkg, "no matching version found")
+	}
+
+	manifest, err := i.registry.GetManifest(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Dist.Tarball == "" {
+		return nil, fmt.Errorf("%s@%s has no tarball", name, version)
+	}
+	data, err := i.registry.Download(ctx, manifest.Dist.Tarball)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTarballDist(data, manifest.Dist); err != nil {
+		return nil, fmt.Errorf("%s@%s: %w", name, version, err)
+	}
+	return i.Open(data)
+}
+
+// Inspect 检查tarball：安装脚本、二进制文件以及文本文件和安装脚本中的可疑模式
+func (i *TarballInspector) Inspect(tarball *Tarball) (*TarballReport, error) {
+	manifest, err := tarball.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	scripts, err := tarball.InstallScripts()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TarballReport{
+		Name:         manifest.Name,
+		Version:      manifest.Version,
+		Files:        len(tarball.Files),
+		UnpackedSize: tarball.UnpackedSize,
+	}
+	if len(scripts) > 0 {
+		report.InstallScripts = scripts
+	}
+	for _, file := range tarball.Files {
+		if file.Binary {
+			report.BinaryFiles = append(report.BinaryFiles, file)
+			continue
+		}
+		// package.json中只有脚本会被执行，单独检查
+		if file.Path != "package.json" {
+			report.Suspicious = append(report.Suspicious, i.scan(file.Path, tarball.contents[file.Path])...)
+		}
+	}
+	for _, name := range installScriptNames {
+		if script := scripts[name]; script != "" {
+			report.Suspicious = append(report.Suspicious, i.scan("package.json#scripts."+name, []byte(script))...)
+		}
+	}
+	sort.SliceStable(report.Suspicious, func(a, b int) bool {
+		if report.Suspicious[a].Path != report.Suspicious[b].Path {
+			return report.Suspicious[a].Path < report.Suspicious[b].Path
+		}
+		return report.Suspicious[a].Line < report.Suspicious[b].Line
+	})
+	return report, nil
+}
+
+// scan 在内容中查找可疑模式，每个模式每行只报告一次
+func (i *TarballInspector) scan(path string, content []byte) []SuspiciousMatch {
+	var matches []SuspiciousMatch
+	for lineNo, line := range bytes.Split(content, []byte("\n")) {
+		for _, pattern := range i.patterns {
+			match := pattern.Regexp.Find(line)
+			if match == nil {
+				continue
+			}
+			text := string(match)
+			if len(text) > 80 {
+				text = text[:80] + "..."
+			}
+			matches = append(matches, SuspiciousMatch{Path: path, Line: lineNo + 1, Pattern: pattern.Name, Match: text})
+		}
+	}
+	return matches
+}
+
+// checkTarballDist 按registry记录的integrity校验tarball，没有integrity时使用shasum
+func checkTarballDist(data []byte, dist registry.Dist) error {
+	if expected, ok := strings.CutPrefix(dist.Integrity, "sha512-"); ok {
+		sum := sha512.Sum512(data)
+		if base64.StdEncoding.EncodeToString(sum[:]) != expected {
+			return fmt.Errorf("tarball integrity mismatch: expected %s", dist.Integrity)
+		}
+		return nil
+	}
+	if dist.Shasum != "" {
+		sum := sha1.Sum(data)
+		if hex.EncodeToString(sum[:]) != strings.ToLower(dist.Shasum) {
+			return fmt.Errorf("tarball shasum mismatch: expected %s", dist.Shasum)
+		}
+	}
+	return nil
+}
+
+// isBinaryContent 内容开头8000字节中有NUL字节时视为二进制，与git的判断方式相同
+func isBinaryContent(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
+
+// isNativeFile 是否为原生可执行文件或共享库
+func isNativeFile(path string, content []byte) bool {
+	switch {
+	case strings.HasSuffix(path, ".node"),
+		bytes.HasPrefix(content, []byte("\x7fELF")),
+		bytes.HasPrefix(content, []byte("MZ")) && isBinaryContent(content),
+		bytes.HasPrefix(content, []byte{0xcf, 0xfa, 0xed, 0xfe}), // Mach-O 64位
+		bytes.HasPrefix(content, []byte{0xce, 0xfa, 0xed, 0xfe}), // Mach-O 32位
+		bytes.HasPrefix(content, []byte{0xca, 0xfe, 0xba, 0xbe}): // Mach-O通用格式
+		return true
+	}
+	return false
+}
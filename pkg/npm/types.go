@@ -1,186 +1,155 @@
 package npm
 
 import (
-	"context"
 	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
 )
 
 // Client 定义npm客户端的核心接口
-type Client interface {
-	// 检查npm是否可用
-	IsAvailable(ctx context.Context) bool
+type Client = npmiface.Client
 
-	// 安装npm
-	Install(ctx context.Context) error
+// InitOptions 项目初始化选项
+type InitOptions = npmiface.InitOptions
 
-	// 获取npm版本
-	Version(ctx context.Context) (string, error)
+// InstallOptions 安装选项
+type InstallOptions = npmiface.InstallOptions
 
-	// 项目初始化
-	Init(ctx context.Context, options InitOptions) error
+// RunScriptOptions 运行脚本选项
+type RunScriptOptions = npmiface.RunScriptOptions
 
-	// 安装包
-	InstallPackage(ctx context.Context, pkg string, options InstallOptions) error
+// OutputLine 命令输出的一行
+type OutputLine = npmiface.OutputLine
 
-	// 卸载包
-	UninstallPackage(ctx context.Context, pkg string, options UninstallOptions) error
+// UninstallOptions 卸载选项
+type UninstallOptions = npmiface.UninstallOptions
 
-	// 更新包
-	UpdatePackage(ctx context.Context, pkg string) error
+// UpdateOptions 更新选项
+type UpdateOptions = npmiface.UpdateOptions
 
-	// 列出已安装的包
-	ListPackages(ctx context.Context, options ListOptions) ([]Package, error)
+// UpdateChange 更新前后锁文件中一个包的版本变化
+type UpdateChange = npmiface.UpdateChange
 
-	// 运行脚本
-	RunScript(ctx context.Context, script string, args ...string) error
+// UpdateResult UpdateAll的结果
+type UpdateResult = npmiface.UpdateResult
 
-	// 发布包
-	Publish(ctx context.Context, options PublishOptions) error
+// ListOptions 列表选项
+type ListOptions = npmiface.ListOptions
 
-	// 获取包信息
-	GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error)
+// OutdatedOptions 检查过期依赖选项
+type OutdatedOptions = npmiface.OutdatedOptions
 
-	// 搜索包
-	Search(ctx context.Context, query string) ([]SearchResult, error)
-}
+// PruneOptions 清理多余包选项
+type PruneOptions = npmiface.PruneOptions
 
-// InitOptions 项目初始化选项
-type InitOptions struct {
-	Name        string `json:"name,omitempty"`
-	Version     string `json:"version,omitempty"`
-	Description string `json:"description,omitempty"`
-	Author      string `json:"author,omitempty"`
-	License     string `json:"license,omitempty"`
-	Private     bool   `json:"private,omitempty"`
-	WorkingDir  string `json:"-"` // 工作目录，不序列化到package.json
-	Force       bool   `json:"-"` // 强制覆盖，不序列化到package.json
-}
+// DedupeOptions 合并重复包选项
+type DedupeOptions = npmiface.DedupeOptions
 
-// InstallOptions 安装选项
-type InstallOptions struct {
-	SaveDev       bool   `json:"save_dev,omitempty"`       // --save-dev
-	SaveOptional  bool   `json:"save_optional,omitempty"`  // --save-optional
-	SaveExact     bool   `json:"save_exact,omitempty"`     // --save-exact
-	Global        bool   `json:"global,omitempty"`         // --global
-	Production    bool   `json:"production,omitempty"`     // --production
-	WorkingDir    string `json:"working_dir,omitempty"`    // 工作目录
-	Registry      string `json:"registry,omitempty"`       // 自定义registry
-	Force         bool   `json:"force,omitempty"`          // --force
-	IgnoreScripts bool   `json:"ignore_scripts,omitempty"` // --ignore-scripts
-}
+// ShrinkwrapOptions 生成npm-shrinkwrap.json选项
+type ShrinkwrapOptions = npmiface.ShrinkwrapOptions
 
-// UninstallOptions 卸载选项
-type UninstallOptions struct {
-	SaveDev    bool   `json:"save_dev,omitempty"`    // --save-dev
-	Global     bool   `json:"global,omitempty"`      // --global
-	WorkingDir string `json:"working_dir,omitempty"` // 工作目录
-}
-
-// ListOptions 列表选项
-type ListOptions struct {
-	Global     bool   `json:"global,omitempty"`      // --global
-	Depth      int    `json:"depth,omitempty"`       // --depth
-	Production bool   `json:"production,omitempty"`  // --production
-	WorkingDir string `json:"working_dir,omitempty"` // 工作目录
-	JSON       bool   `json:"json,omitempty"`        // --json
-}
+// ConfigListOptions 列出npm配置选项
+type ConfigListOptions = npmiface.ConfigListOptions
 
 // PublishOptions 发布选项
-type PublishOptions struct {
-	Tag        string `json:"tag,omitempty"`         // --tag
-	Access     string `json:"access,omitempty"`      // --access (public/restricted)
-	Registry   string `json:"registry,omitempty"`    // 自定义registry
-	WorkingDir string `json:"working_dir,omitempty"` // 工作目录
-	DryRun     bool   `json:"dry_run,omitempty"`     // --dry-run
-}
+type PublishOptions = npmiface.PublishOptions
+
+// UnpublishOptions 撤销发布选项
+type UnpublishOptions = npmiface.UnpublishOptions
+
+// DistTagOptions dist-tag管理选项
+type DistTagOptions = npmiface.DistTagOptions
+
+// OwnerOptions 包所有者管理选项
+type OwnerOptions = npmiface.OwnerOptions
+
+// Owner 包所有者
+type Owner = npmiface.Owner
+
+// AccessLevel 包访问级别
+type AccessLevel = npmiface.AccessLevel
+
+const (
+	AccessPublic     = npmiface.AccessPublic
+	AccessRestricted = npmiface.AccessRestricted
+)
+
+// Permission 团队或协作者的权限
+type Permission = npmiface.Permission
+
+const (
+	PermissionReadOnly  = npmiface.PermissionReadOnly
+	PermissionReadWrite = npmiface.PermissionReadWrite
+)
+
+// AccessOptions 包访问管理选项
+type AccessOptions = npmiface.AccessOptions
+
+// Token 访问令牌信息
+type Token = npmiface.Token
+
+// TokenOptions 令牌管理选项
+type TokenOptions = npmiface.TokenOptions
+
+// TokenCreateOptions 创建令牌选项
+type TokenCreateOptions = npmiface.TokenCreateOptions
+
+// PackOptions 打包选项
+type PackOptions = npmiface.PackOptions
+
+// PackResult 打包结果
+type PackResult = npmiface.PackResult
+
+// PackFile tarball中的文件
+type PackFile = npmiface.PackFile
 
 // Package 表示一个npm包
-type Package struct {
-	Name         string            `json:"name"`
-	Version      string            `json:"version"`
-	Description  string            `json:"description,omitempty"`
-	Dependencies map[string]string `json:"dependencies,omitempty"`
-	DevDeps      map[string]string `json:"devDependencies,omitempty"`
-	OptionalDeps map[string]string `json:"optionalDependencies,omitempty"`
-	PeerDeps     map[string]string `json:"peerDependencies,omitempty"`
-	Scripts      map[string]string `json:"scripts,omitempty"`
-	Keywords     []string          `json:"keywords,omitempty"`
-	Author       string            `json:"author,omitempty"`
-	License      string            `json:"license,omitempty"`
-	Homepage     string            `json:"homepage,omitempty"`
-	Repository   *Repository       `json:"repository,omitempty"`
-	Bugs         *Bugs             `json:"bugs,omitempty"`
-	Main         string            `json:"main,omitempty"`
-	Private      bool              `json:"private,omitempty"`
-}
+type Package = npmiface.Package
 
 // Repository 仓库信息
-type Repository struct {
-	Type string `json:"type"`
-	URL  string `json:"url"`
-}
+type Repository = npmiface.Repository
 
 // Bugs bug报告信息
-type Bugs struct {
-	URL   string `json:"url,omitempty"`
-	Email string `json:"email,omitempty"`
-}
+type Bugs = npmiface.Bugs
 
 // PackageInfo 包详细信息
-type PackageInfo struct {
-	Name         string                 `json:"name"`
-	Version      string                 `json:"version"`
-	Description  string                 `json:"description"`
-	Keywords     []string               `json:"keywords"`
-	Homepage     string                 `json:"homepage"`
-	Repository   *Repository            `json:"repository"`
-	Author       *Person                `json:"author"`
-	License      string                 `json:"license"`
-	Dependencies map[string]string      `json:"dependencies"`
-	DevDeps      map[string]string      `json:"devDependencies"`
-	Versions     map[string]interface{} `json:"versions"`
-	Time         map[string]time.Time   `json:"time"`
-	DistTags     map[string]string      `json:"dist-tags"`
-}
+type PackageInfo = npmiface.PackageInfo
 
 // Person 人员信息
-type Person struct {
-	Name  string `json:"name"`
-	Email string `json:"email,omitempty"`
-	URL   string `json:"url,omitempty"`
+type Person = npmiface.Person
+
+// PackagePerson package.json中字符串或对象形式的人员信息
+type PackagePerson = npmiface.PackagePerson
+
+// ParsePerson 解析"Name <email> (url)"格式的人员字符串
+func ParsePerson(value string) Person {
+	return npmiface.ParsePerson(value)
 }
 
 // SearchResult 搜索结果
-type SearchResult struct {
-	Package     SearchPackage `json:"package"`
-	Score       SearchScore   `json:"score"`
-	SearchScore float64       `json:"searchScore"`
-}
+type SearchResult = npmiface.SearchResult
 
 // SearchPackage 搜索包信息
-type SearchPackage struct {
-	Name        string            `json:"name"`
-	Version     string            `json:"version"`
-	Description string            `json:"description"`
-	Keywords    []string          `json:"keywords"`
-	Date        time.Time         `json:"date"`
-	Links       map[string]string `json:"links"`
-	Author      *Person           `json:"author"`
-	Publisher   *Person           `json:"publisher"`
-	Maintainers []*Person         `json:"maintainers"`
-}
+type SearchPackage = npmiface.SearchPackage
 
 // SearchScore 搜索评分
-type SearchScore struct {
-	Final  float64     `json:"final"`
-	Detail ScoreDetail `json:"detail"`
-}
+type SearchScore = npmiface.SearchScore
 
 // ScoreDetail 评分详情
-type ScoreDetail struct {
-	Quality     float64 `json:"quality"`
-	Popularity  float64 `json:"popularity"`
-	Maintenance float64 `json:"maintenance"`
+type ScoreDetail = npmiface.ScoreDetail
+
+// OutdatedPackage npm outdated输出中的一项
+type OutdatedPackage = npmiface.OutdatedPackage
+
+// ErrorOutput 从失败的npm命令输出中解析出的错误信息
+type ErrorOutput struct {
+	Code    string `json:"code,omitempty"` // 错误码，例如E404、ERESOLVE
+	Errno   string `json:"errno,omitempty"`
+	Syscall string `json:"syscall,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Summary string `json:"summary,omitempty"`  // 第一行错误说明
+	Detail  string `json:"detail,omitempty"`   // 其余说明
+	LogFile string `json:"log_file,omitempty"` // npm调试日志路径
 }
 
 // CommandResult 命令执行结果
@@ -547,6 +547,38 @@ func TestPerson(t *testing.T) {
 	}
 }
 
+func TestPackageAuthor(t *testing.T) {
+	var pkg Package
+	if err := json.Unmarshal([]byte(`{"name": "app", "author": {"name": "Barney Rubble", "email": "b@rubble.com"}}`), &pkg); err != nil {
+		t.Fatalf("JSON unmarshal failed: %v", err)
+	}
+	if pkg.Author != "Barney Rubble <b@rubble.com>" || !pkg.AuthorObject {
+		t.Errorf("Unexpected author: %q", pkg.Author)
+	}
+	if author := pkg.AuthorPerson(); author == nil || author.Name != "Barney Rubble" || author.Email != "b@rubble.com" {
+		t.Errorf("Unexpected author person: %+v", author)
+	}
+
+	data, err := json.Marshal(pkg)
+	if err != nil {
+		t.Fatalf("JSON marshal failed: %v", err)
+	}
+	var decoded map[string]json.RawMessage
+	json.Unmarshal(data, &decoded)
+	if string(decoded["author"]) != `{"name":"Barney Rubble","email":"b@rubble.com"}` {
+		t.Errorf("Expected object author to be preserved, got %s", decoded["author"])
+	}
+
+	// 字符串形式原样写出
+	pkg = Package{Name: "app", Author: "Test Author"}
+	if data, _ := json.Marshal(pkg); string(data) != `{"name":"app","version":"","author":"Test Author"}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+	if pkg := (Package{}); pkg.AuthorPerson() != nil {
+		t.Error("Expected nil author person")
+	}
+}
+
 func TestSearchResult(t *testing.T) {
 	author := &Person{
 		Name:  "Test Author",
@@ -0,0 +1,446 @@
+package npm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// DefaultRegistry npm官方registry地址
+const DefaultRegistry = "https://registry.npmjs.org/"
+
+// RangeStrategy 依赖更新时版本范围的处理策略
+type RangeStrategy string
+
+const (
+	RangeStrategyAuto         RangeStrategy = "auto"            // 由工具决定
+	RangeStrategyPin          RangeStrategy = "pin"             // 固定为精确版本
+	RangeStrategyBump         RangeStrategy = "bump"            // 提升范围下限
+	RangeStrategyReplace      RangeStrategy = "replace"         // 新版本超出范围时替换范围
+	RangeStrategyWiden        RangeStrategy = "widen"           // 扩大范围以包含新版本
+	RangeStrategyLockfileOnly RangeStrategy = "update-lockfile" // 只更新锁文件
+)
+
+// UpdatePolicy 依赖自动更新策略
+type UpdatePolicy struct {
+	RangeStrategy         RangeStrategy `json:"range_strategy,omitempty"`
+	Schedule              string        `json:"schedule,omitempty"`                 // daily、weekly或monthly，默认weekly
+	OpenPullRequestsLimit int           `json:"open_pull_requests_limit,omitempty"` // 同时打开的PR数量上限
+	Automerge             bool          `json:"automerge,omitempty"`                // 自动合并次版本和补丁更新，仅Renovate支持
+	Labels                []string      `json:"labels,omitempty"`
+}
+
+// UpdateProjectInfo 生成更新配置所需的项目信息
+type UpdateProjectInfo struct {
+	Registry         string            `json:"registry"`                    // 默认registry
+	ScopedRegistries map[string]string `json:"scoped_registries,omitempty"` // scope -> registry
+	Workspaces       []string          `json:"workspaces,omitempty"`        // 工作区匹配模式
+}
+
+// CustomRegistries 返回所有非官方registry地址，已去重并排序
+func (i *UpdateProjectInfo) CustomRegistries() []string {
+	seen := make(map[string]bool)
+	var registries []string
+	add := func(registry string) {
+		registry = normalizeRegistryURL(registry)
+		if registry == "" || registry == DefaultRegistry || seen[registry] {
+			return
+		}
+		seen[registry] = true
+		registries = append(registries, registry)
+	}
+
+	add(i.Registry)
+	for _, registry := range i.ScopedRegistries {
+		add(registry)
+	}
+	sort.Strings(registries)
+	return registries
+}
+
+// UpdateConfigGenerator Renovate和Dependabot配置生成器
+type UpdateConfigGenerator struct {
+	workingDir string
+}
+
+// NewUpdateConfigGenerator 创建更新配置生成器
+func NewUpdateConfigGenerator(workingDir string) *UpdateConfigGenerator {
+	return &UpdateConfigGenerator{workingDir: workingDir}
+}
+
+// Detect 从.npmrc、package.json和pnpm-workspace.yaml中检测registry和工作区
+func (g *UpdateConfigGenerator) Detect() (*UpdateProjectInfo, error) {
+	info := &UpdateProjectInfo{
+		Registry:         DefaultRegistry,
+		ScopedRegistries: make(map[string]string),
+	}
+
+	data, err := os.ReadFile(filepath.Join(g.workingDir, ".npmrc"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read .npmrc: %w", err)
+	}
+	for key, value := range parseNpmrc(string(data)) {
+		switch {
+		case key == "registry":
+			info.Registry = normalizeRegistryURL(value)
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			info.ScopedRegistries[strings.TrimSuffix(key, ":registry")] = normalizeRegistryURL(value)
+		}
+	}
+
+	workspaces, err := detectWorkspacePatterns(g.workingDir)
+	if err != nil {
+		return nil, err
+	}
+	info.Workspaces = workspaces
+
+	return info, nil
+}
+
+// Renovate 生成renovate.json内容
+func (g *UpdateConfigGenerator) Renovate(policy UpdatePolicy) ([]byte, error) {
+	if _, err := dependabotVersioningStrategy(policy.RangeStrategy); err != nil {
+		return nil, err
+	}
+
+	info, err := g.Detect()
+	if err != nil {
+		return nil, err
+	}
+
+	type packageRule struct {
+		MatchPackageNames []string `json:"matchPackageNames,omitempty"`
+		MatchFileNames    []string `json:"matchFileNames,omitempty"`
+		MatchUpdateTypes  []string `json:"matchUpdateTypes,omitempty"`
+		RegistryURLs      []string `json:"registryUrls,omitempty"`
+		GroupName         string   `json:"groupName,omitempty"`
+		Automerge         bool     `json:"automerge,omitempty"`
+	}
+	type hostRule struct {
+		MatchHost string `json:"matchHost"`
+		HostType  string `json:"hostType"`
+	}
+	config := struct {
+		Schema        string        `json:"$schema"`
+		Extends       []string      `json:"extends"`
+		RangeStrategy RangeStrategy `json:"rangeStrategy,omitempty"`
+		Schedule      []string      `json:"schedule,omitempty"`
+		PRConcurrent  int           `json:"prConcurrentLimit,omitempty"`
+		Labels        []string      `json:"labels,omitempty"`
+		RegistryURLs  []string      `json:"registryUrls,omitempty"`
+		HostRules     []hostRule    `json:"hostRules,omitempty"`
+		PackageRules  []packageRule `json:"packageRules,omitempty"`
+	}{
+		Schema:        "https://docs.renovatebot.com/renovate-schema.json",
+		Extends:       []string{"config:recommended"},
+		RangeStrategy: policy.RangeStrategy,
+		PRConcurrent:  policy.OpenPullRequestsLimit,
+		Labels:        policy.Labels,
+	}
+
+	switch policy.Schedule {
+	case "daily":
+		config.Schedule = []string{"before 6am"}
+	case "monthly":
+		config.Schedule = []string{"before 6am on the first day of the month"}
+	case "", "weekly":
+		config.Schedule = []string{"before 6am on monday"}
+	default:
+		return nil, NewValidationError("schedule", policy.Schedule, "schedule must be daily, weekly or monthly")
+	}
+
+	if info.Registry != DefaultRegistry {
+		config.RegistryURLs = []string{info.Registry}
+	}
+	for _, registry := range info.CustomRegistries() {
+		if u, err := url.Parse(registry); err == nil && u.Host != "" {
+			config.HostRules = append(config.HostRules, hostRule{MatchHost: u.Host, HostType: "npm"})
+		}
+	}
+
+	scopes := make([]string, 0, len(info.ScopedRegistries))
+	for scope := range info.ScopedRegistries {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	for _, scope := range scopes {
+		config.PackageRules = append(config.PackageRules, packageRule{
+			MatchPackageNames: []string{scope + "/**"},
+			RegistryURLs:      []string{info.ScopedRegistries[scope]},
+		})
+	}
+
+	if len(info.Workspaces) > 0 {
+		var files []string
+		for _, pattern := range info.Workspaces {
+			files = append(files, strings.TrimSuffix(pattern, "/")+"/package.json")
+		}
+		config.PackageRules = append(config.PackageRules, packageRule{
+			MatchFileNames: files,
+			GroupName:      "workspace dependencies",
+		})
+	}
+
+	if policy.Automerge {
+		config.PackageRules = append(config.PackageRules, packageRule{
+			MatchUpdateTypes: []string{"minor", "patch"},
+			Automerge:        true,
+		})
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode renovate config: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Dependabot 生成.github/dependabot.yml内容
+func (g *UpdateConfigGenerator) Dependabot(policy UpdatePolicy) ([]byte, error) {
+	info, err := g.Detect()
+	if err != nil {
+		return nil, err
+	}
+
+	interval := policy.Schedule
+	if interval == "" {
+		interval = "weekly"
+	}
+	if interval != "daily" && interval != "weekly" && interval != "monthly" {
+		return nil, NewValidationError("schedule", policy.Schedule, "schedule must be daily, weekly or monthly")
+	}
+
+	strategy, err := dependabotVersioningStrategy(policy.RangeStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("version: 2\n")
+
+	registries := info.CustomRegistries()
+	var registryNames []string
+	if len(registries) > 0 {
+		builder.WriteString("registries:\n")
+		for _, registry := range registries {
+			name, secret := dependabotRegistryName(registry)
+			registryNames = append(registryNames, name)
+			builder.WriteString("  " + name + ":\n")
+			builder.WriteString("    type: npm-registry\n")
+			builder.WriteString("    url: " + strings.TrimSuffix(registry, "/") + "\n")
+			builder.WriteString("    token: ${{secrets." + secret + "}}\n")
+		}
+	}
+
+	builder.WriteString("updates:\n")
+	builder.WriteString("  - package-ecosystem: \"npm\"\n")
+	if len(info.Workspaces) > 0 {
+		builder.WriteString("    directories:\n")
+		builder.WriteString("      - \"/\"\n")
+		for _, pattern := range info.Workspaces {
+			builder.WriteString("      - \"/" + strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/") + "\"\n")
+		}
+	} else {
+		builder.WriteString("    directory: \"/\"\n")
+	}
+	builder.WriteString("    schedule:\n")
+	builder.WriteString("      interval: \"" + interval + "\"\n")
+	if strategy != "" {
+		builder.WriteString("    versioning-strategy: " + strategy + "\n")
+	}
+	if policy.OpenPullRequestsLimit > 0 {
+		builder.WriteString(fmt.Sprintf("    open-pull-requests-limit: %d\n", policy.OpenPullRequestsLimit))
+	}
+	if len(policy.Labels) > 0 {
+		builder.WriteString("    labels:\n")
+		for _, label := range policy.Labels {
+			builder.WriteString("      - \"" + label + "\"\n")
+		}
+	}
+	if len(registryNames) > 0 {
+		builder.WriteString("    registries:\n")
+		for _, name := range registryNames {
+			builder.WriteString("      - " + name + "\n")
+		}
+	}
+
+	return []byte(builder.String()), nil
+}
+
+// WriteRenovate 生成并写入renovate.json，返回文件路径
+func (g *UpdateConfigGenerator) WriteRenovate(policy UpdatePolicy) (string, error) {
+	data, err := g.Renovate(policy)
+	if err != nil {
+		return "", err
+	}
+	return g.writeConfig("renovate.json", data)
+}
+
+// WriteDependabot 生成并写入.github/dependabot.yml，返回文件路径
+func (g *UpdateConfigGenerator) WriteDependabot(policy UpdatePolicy) (string, error) {
+	data, err := g.Dependabot(policy)
+	if err != nil {
+		return "", err
+	}
+	return g.writeConfig(filepath.Join(".github", "dependabot.yml"), data)
+}
+
+// writeConfig 写入配置文件
+func (g *UpdateConfigGenerator) writeConfig(name string, data []byte) (string, error) {
+	path := filepath.Join(g.workingDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return path, nil
+}
+
+// dependabotVersioningStrategy 将RangeStrategy映射为Dependabot的versioning-strategy
+func dependabotVersioningStrategy(strategy RangeStrategy) (string, error) {
+	switch strategy {
+	case "", RangeStrategyAuto:
+		return "", nil
+	case RangeStrategyBump, RangeStrategyPin:
+		// Dependabot没有固定版本的策略，increase最接近
+		return "increase", nil
+	case RangeStrategyReplace:
+		return "increase-if-necessary", nil
+	case RangeStrategyWiden:
+		return "widen", nil
+	case RangeStrategyLockfileOnly:
+		return "lockfile-only", nil
+	default:
+		return "", NewValidationError("range_strategy", string(strategy), "unknown range strategy")
+	}
+}
+
+// dependabotRegistryName 根据registry地址生成Dependabot中的registry名称和token密钥名
+func dependabotRegistryName(registry string) (string, string) {
+	host := registry
+	if u, err := url.Parse(registry); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	var name strings.Builder
+	for _, r := range strings.ToLower(host) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			name.WriteRune(r)
+		} else {
+			name.WriteRune('-')
+		}
+	}
+
+	registryName := "npm-" + strings.Trim(name.String(), "-")
+	secret := strings.ToUpper(strings.ReplaceAll(registryName, "-", "_")) + "_TOKEN"
+	return registryName, secret
+}
+
+// normalizeRegistryURL 规范化registry地址，保证以/结尾
+func normalizeRegistryURL(registry string) string {
+	registry = strings.TrimSpace(registry)
+	if registry == "" {
+		return ""
+	}
+	if !strings.HasSuffix(registry, "/") {
+		registry += "/"
+	}
+	return registry
+}
+
+// parseNpmrc 解析.npmrc内容，忽略注释并展开引号
+func parseNpmrc(content string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return values
+}
+
+// detectWorkspacePatterns 读取package.json的workspaces字段或pnpm-workspace.yaml
+func detectWorkspacePatterns(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err == nil {
+		var manifest struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if err := utils.DecodeJSON(filepath.Join(dir, "package.json"), data, &manifest); err != nil {
+			return nil, err
+		}
+		if patterns := parseWorkspacesField(manifest.Workspaces); len(patterns) > 0 {
+			return patterns, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "pnpm-workspace.yaml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read pnpm-workspace.yaml: %w", err)
+	}
+	return parsePnpmWorkspacePackages(string(data)), nil
+}
+
+// parseWorkspacesField 解析workspaces字段，支持数组和{"packages": [...]}两种形式
+func parseWorkspacesField(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(raw, &patterns); err == nil {
+		return patterns
+	}
+
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		return object.Packages
+	}
+	return nil
+}
+
+// parsePnpmWorkspacePackages 读取pnpm-workspace.yaml中packages列表，跳过排除模式
+func parsePnpmWorkspacePackages(content string) []string {
+	var patterns []string
+	inPackages := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(trimmed, "packages:")
+			continue
+		}
+		if !inPackages || !strings.HasPrefix(trimmed, "- ") {
+			continue
+		}
+
+		pattern := strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "- ")), `"'`)
+		if pattern != "" && !strings.HasPrefix(pattern, "!") {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
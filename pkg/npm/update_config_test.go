@@ -0,0 +1,143 @@
+package npm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupUpdateConfigProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name":"mono","workspaces":["packages/*","apps/web/"]}`)
+	writeTestFile(t, filepath.Join(dir, ".npmrc"), `# company registry
+registry=https://npm.example.com
+@acme:registry=https://npm.pkg.github.com/
+//npm.pkg.github.com/:_authToken=${GITHUB_TOKEN}
+`)
+	return dir
+}
+
+func TestUpdateConfigGeneratorDetect(t *testing.T) {
+	info, err := NewUpdateConfigGenerator(setupUpdateConfigProject(t)).Detect()
+	if err != nil {
+		t.Fatalf("Detect() failed: %v", err)
+	}
+
+	if info.Registry != "https://npm.example.com/" {
+		t.Errorf("Expected normalized registry, got '%s'", info.Registry)
+	}
+	if info.ScopedRegistries["@acme"] != "https://npm.pkg.github.com/" {
+		t.Errorf("Expected scoped registry, got %v", info.ScopedRegistries)
+	}
+	if len(info.Workspaces) != 2 {
+		t.Errorf("Expected 2 workspace patterns, got %v", info.Workspaces)
+	}
+	if registries := info.CustomRegistries(); len(registries) != 2 {
+		t.Errorf("Expected 2 custom registries, got %v", registries)
+	}
+}
+
+func TestUpdateConfigGeneratorDetectPnpmWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), `{"name":"mono"}`)
+	writeTestFile(t, filepath.Join(dir, "pnpm-workspace.yaml"), "packages:\n  - 'packages/*'\n  - \"!**/test/**\"\ncatalog:\n  - ignored\n")
+
+	info, err := NewUpdateConfigGenerator(dir).Detect()
+	if err != nil {
+		t.Fatalf("Detect() failed: %v", err)
+	}
+	if len(info.Workspaces) != 1 || info.Workspaces[0] != "packages/*" {
+		t.Errorf("Expected pnpm workspace patterns, got %v", info.Workspaces)
+	}
+	if info.Registry != DefaultRegistry || len(info.CustomRegistries()) != 0 {
+		t.Errorf("Expected default registry, got %+v", info)
+	}
+}
+
+func TestUpdateConfigGeneratorRenovate(t *testing.T) {
+	gen := NewUpdateConfigGenerator(setupUpdateConfigProject(t))
+	data, err := gen.Renovate(UpdatePolicy{RangeStrategy: RangeStrategyPin, Automerge: true, OpenPullRequestsLimit: 5})
+	if err != nil {
+		t.Fatalf("Renovate() failed: %v", err)
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Generated invalid JSON: %v", err)
+	}
+	if config["rangeStrategy"] != "pin" {
+		t.Errorf("Expected rangeStrategy pin, got %v", config["rangeStrategy"])
+	}
+	if config["prConcurrentLimit"] != float64(5) {
+		t.Errorf("Expected prConcurrentLimit 5, got %v", config["prConcurrentLimit"])
+	}
+
+	content := string(data)
+	for _, expected := range []string{
+		`"matchHost": "npm.pkg.github.com"`,
+		`"@acme/**"`,
+		`"packages/*/package.json"`,
+		`"apps/web/package.json"`,
+		`"automerge": true`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected renovate.json to contain %s, got:\n%s", expected, content)
+		}
+	}
+
+	if _, err := gen.Renovate(UpdatePolicy{RangeStrategy: "sideways"}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for unknown strategy, got %v", err)
+	}
+	if _, err := gen.Renovate(UpdatePolicy{Schedule: "hourly"}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for unknown schedule, got %v", err)
+	}
+}
+
+func TestUpdateConfigGeneratorDependabot(t *testing.T) {
+	gen := NewUpdateConfigGenerator(setupUpdateConfigProject(t))
+	data, err := gen.Dependabot(UpdatePolicy{RangeStrategy: RangeStrategyWiden, Schedule: "daily", Labels: []string{"deps"}})
+	if err != nil {
+		t.Fatalf("Dependabot() failed: %v", err)
+	}
+
+	content := string(data)
+	for _, expected := range []string{
+		"version: 2\n",
+		"  npm-npm-pkg-github-com:\n    type: npm-registry\n    url: https://npm.pkg.github.com\n    token: ${{secrets.NPM_NPM_PKG_GITHUB_COM_TOKEN}}\n",
+		"    directories:\n      - \"/\"\n      - \"/packages/*\"\n      - \"/apps/web\"\n",
+		"      interval: \"daily\"\n",
+		"    versioning-strategy: widen\n",
+		"    registries:\n      - npm-npm-example-com\n      - npm-npm-pkg-github-com\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected dependabot.yml to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
+
+func TestUpdateConfigGeneratorWrite(t *testing.T) {
+	dir := t.TempDir()
+	gen := NewUpdateConfigGenerator(dir)
+
+	path, err := gen.WriteDependabot(UpdatePolicy{})
+	if err != nil {
+		t.Fatalf("WriteDependabot() failed: %v", err)
+	}
+	if path != filepath.Join(dir, ".github", "dependabot.yml") {
+		t.Errorf("Unexpected path: %s", path)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "directory: \"/\"") || strings.Contains(string(data), "versioning-strategy") {
+		t.Errorf("Unexpected default dependabot config:\n%s", data)
+	}
+
+	if path, err = gen.WriteRenovate(UpdatePolicy{}); err != nil {
+		t.Fatalf("WriteRenovate() failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected renovate.json to be written: %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+	"github.com/scagogogo/go-npm-sdk/pkg/vcs"
+)
+
+// DependencyUpdate 单个依赖更新
+type DependencyUpdate struct {
+	Name string         `json:"name"`
+	From string         `json:"from,omitempty"` // 当前版本范围，为空时从package.json读取
+	To   string         `json:"to"`             // 新版本范围
+	Type DependencyType `json:"type,omitempty"` // 依赖类型，为空时自动查找
+}
+
+// UpdatePlan 依赖更新计划
+type UpdatePlan struct {
+	Branch  string             `json:"branch,omitempty"` // 分支名，为空时根据更新内容生成
+	Updates []DependencyUpdate `json:"updates"`
+}
+
+// UpdaterOptions 更新执行选项
+type UpdaterOptions struct {
+	TestScript        string        `json:"test_script,omitempty"`         // 测试脚本名，默认为test
+	SkipTests         bool          `json:"skip_tests,omitempty"`          // 不运行测试
+	SkipLockfile      bool          `json:"skip_lockfile,omitempty"`       // 不重新生成锁文件
+	AllowFailingTests bool          `json:"allow_failing_tests,omitempty"` // 测试失败时仍然提交
+	Timeout           time.Duration `json:"timeout,omitempty"`             // 安装和测试的超时时间
+}
+
+// UpdateRunResult 更新执行结果
+type UpdateRunResult struct {
+	BaseBranch  string             `json:"base_branch"`
+	Branch      string             `json:"branch"`
+	Commit      string             `json:"commit"`
+	Applied     []DependencyUpdate `json:"applied"`
+	Files       []string           `json:"files"`
+	TestsRun    bool               `json:"tests_run"`
+	TestsPassed bool               `json:"tests_passed"`
+	TestOutput  string             `json:"test_output,omitempty"`
+	Title       string             `json:"title"`
+	Summary     string             `json:"summary"` // 提交信息正文，可直接用作PR描述
+}
+
+// Updater 在工作区中应用依赖更新并生成提交
+//
+// 更新流程：创建分支，修改package.json中的版本范围，用项目的包管理器重新生成锁文件，
+// 运行测试脚本，最后通过VCS提交。package.json以文本方式修改，保留原有格式和键顺序。
+type Updater struct {
+	workingDir string
+	repo       vcs.VCS
+	executor   *utils.Executor
+}
+
+// NewUpdater 创建依赖更新器
+func NewUpdater(workingDir string, repo vcs.VCS) *Updater {
+	return &Updater{
+		workingDir: workingDir,
+		repo:       repo,
+		executor:   utils.NewExecutor(),
+	}
+}
+
+// Apply 执行更新计划并提交
+func (u *Updater) Apply(ctx context.Context, plan UpdatePlan, options UpdaterOptions) (*UpdateRunResult, error) {
+	if len(plan.Updates) == 0 {
+		return nil, NewValidationError("updates", "", "update plan is empty")
+	}
+	for _, update := range plan.Updates {
+		if update.Name == "" {
+			return nil, NewValidationError("name", "", "dependency name cannot be empty")
+		}
+		if update.To == "" {
+			return nil, NewValidationError("to", update.Name, "target version cannot be empty")
+		}
+	}
+	if options.TestScript == "" {
+		options.TestScript = "test"
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 10 * time.Minute
+	}
+
+	dirty, err := vcs.HasChanges(ctx, u.repo)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("working tree has uncommitted changes")
+	}
+
+	base, err := u.repo.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := plan.Branch
+	if branch == "" {
+		branch = updateBranchName(plan.Updates)
+	}
+	if err := u.repo.CreateBranch(ctx, branch); err != nil {
+		return nil, err
+	}
+
+	result := &UpdateRunResult{
+		BaseBranch: base,
+		Branch:     branch,
+	}
+
+	applied, err := updateManifestRanges(filepath.Join(u.workingDir, "package.json"), plan.Updates)
+	if err != nil {
+		return nil, err
+	}
+	result.Applied = applied
+	result.Files = []string{"package.json"}
+
+	info, err := DetectProjectManager(u.workingDir)
+	if err != nil {
+		return nil, err
+	}
+	manager := info.Manager
+	if manager == ManagerUnknown {
+		manager = ManagerNpm
+	}
+
+	if !options.SkipLockfile {
+		if _, err := u.runManager(ctx, manager, options.Timeout, "install"); err != nil {
+			return nil, fmt.Errorf("failed to regenerate lockfile: %w", err)
+		}
+	}
+	for _, name := range []string{"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lock"} {
+		if _, err := os.Stat(filepath.Join(u.workingDir, name)); err == nil {
+			result.Files = append(result.Files, name)
+		}
+	}
+
+	if !options.SkipTests {
+		if err := u.runTests(ctx, manager, options, result); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Title, result.Summary = updateSummary(result)
+
+	if err := u.repo.Add(ctx, result.Files...); err != nil {
+		return nil, err
+	}
+	commit, err := u.repo.Commit(ctx, result.Title+"\n\n"+result.Summary)
+	if err != nil {
+		return nil, err
+	}
+	result.Commit = commit
+
+	return result, nil
+}
+
+// updateManifestRanges 以文本方式修改package.json中的依赖版本范围，返回实际修改的更新
+func updateManifestRanges(path string, updates []DependencyUpdate) ([]DependencyUpdate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+
+	var manifest map[string]json.RawMessage
+	if err := utils.DecodeJSON(path, data, &manifest); err != nil {
+		return nil, err
+	}
+	sections := make(map[DependencyType]map[string]string)
+	for _, depType := range []DependencyType{Production, Development, Optional, Peer} {
+		deps := make(map[string]string)
+		if raw, ok := manifest[string(depType)]; ok {
+			if err := json.Unmarshal(raw, &deps); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", depType, err)
+			}
+		}
+		sections[depType] = deps
+	}
+
+	content := string(data)
+	applied := make([]DependencyUpdate, 0, len(updates))
+	for _, update := range updates {
+		if update.Type == "" {
+			for _, depType := range []DependencyType{Production, Development, Optional, Peer} {
+				if _, ok := sections[depType][update.Name]; ok {
+					update.Type = depType
+					break
+				}
+			}
+		}
+
+		current, ok := sections[update.Type][update.Name]
+		if !ok {
+			return nil, fmt.Errorf("dependency %s not found in package.json", update.Name)
+		}
+		if update.From != "" && update.From != current {
+			return nil, fmt.Errorf("dependency %s is at %s, expected %s", update.Name, current, update.From)
+		}
+		update.From = current
+		if current == update.To {
+			continue
+		}
+
+		sectionStart, ok := findJSONObjectKey(content, strings.Index(content, "{"), string(update.Type))
+		if !ok {
+			return nil, fmt.Errorf("failed to locate %s in package.json", update.Type)
+		}
+		valueStart, ok := findJSONObjectKey(content, sectionStart, update.Name)
+		if !ok {
+			return nil, fmt.Errorf("failed to locate %s in package.json", update.Name)
+		}
+		content, ok = replaceJSONString(content, valueStart, current, update.To)
+		if !ok {
+			return nil, fmt.Errorf("failed to update %s in package.json", update.Name)
+		}
+
+		sections[update.Type][update.Name] = update.To
+		applied = append(applied, update)
+	}
+
+	if len(applied) == 0 {
+		return nil, fmt.Errorf("all dependencies are already up to date")
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write package.json: %w", err)
+	}
+	return applied, nil
+}
+
+// runTests 运行测试脚本
+func (u *Updater) runTests(ctx context.Context, manager ProjectManager, options UpdaterOptions, result *UpdateRunResult) error {
+	pkg := NewPackageJSON(filepath.Join(u.workingDir, "package.json"))
+	if err := pkg.Load(); err != nil {
+		return err
+	}
+	if !pkg.HasScript(options.TestScript) {
+		return nil
+	}
+
+	result.TestsRun = true
+	output, err := u.runManager(ctx, manager, options.Timeout, "run", options.TestScript)
+	result.TestOutput = output
+	if err != nil {
+		if !options.AllowFailingTests {
+			return fmt.Errorf("test script %s failed: %w", options.TestScript, err)
+		}
+		return nil
+	}
+	result.TestsPassed = true
+	return nil
+}
+
+// runManager 使用项目的包管理器执行命令，返回合并后的输出
+func (u *Updater) runManager(ctx context.Context, manager ProjectManager, timeout time.Duration, args ...string) (string, error) {
+	executeOptions := utils.ExecuteOptions{
+		Command:       string(manager),
+		Args:          args,
+		WorkingDir:    u.workingDir,
+		CaptureOutput: true,
+		Timeout:       timeout,
+	}
+
+	result, err := u.executor.Execute(ctx, executeOptions)
+	output := ""
+	if result != nil {
+		output = strings.TrimSpace(result.Stdout + "\n" + result.Stderr)
+	}
+	if err != nil {
+		return output, err
+	}
+	return output, nil
+}
+
+// updateBranchName 根据更新内容生成分支名
+func updateBranchName(updates []DependencyUpdate) string {
+	if len(updates) == 1 {
+		name := strings.NewReplacer("@", "", "/", "-").Replace(updates[0].Name)
+		version := strings.TrimLeft(updates[0].To, "^~>=<v ")
+		return "deps/" + name + "-" + version
+	}
+	return fmt.Sprintf("deps/update-%d-dependencies", len(updates))
+}
+
+// updateSummary 生成提交标题和正文
+func updateSummary(result *UpdateRunResult) (string, string) {
+	var title string
+	if len(result.Applied) == 1 {
+		title = fmt.Sprintf("Update %s to %s", result.Applied[0].Name, result.Applied[0].To)
+	} else {
+		title = fmt.Sprintf("Update %d dependencies", len(result.Applied))
+	}
+
+	var body strings.Builder
+	body.WriteString("| Package | Type | From | To |\n")
+	body.WriteString("|---|---|---|---|\n")
+	for _, update := range result.Applied {
+		fmt.Fprintf(&body, "| %s | %s | %s | %s |\n", update.Name, update.Type, update.From, update.To)
+	}
+	body.WriteString("\n")
+
+	switch {
+	case !result.TestsRun:
+		body.WriteString("Tests: not run\n")
+	case result.TestsPassed:
+		body.WriteString("Tests: passed\n")
+	default:
+		body.WriteString("Tests: failed\n")
+	}
+
+	return title, body.String()
+}
@@ -0,0 +1,198 @@
+package npm
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/vcs"
+)
+
+// recordingVCS 记录调用的VCS实现
+type recordingVCS struct {
+	branch   string
+	branches []string
+	added    []string
+	messages []string
+	dirty    bool
+}
+
+func (r *recordingVCS) CurrentBranch(ctx context.Context) (string, error) {
+	return r.branch, nil
+}
+
+func (r *recordingVCS) CreateBranch(ctx context.Context, name string) error {
+	r.branches = append(r.branches, name)
+	r.branch = name
+	return nil
+}
+
+func (r *recordingVCS) Checkout(ctx context.Context, name string) error {
+	r.branch = name
+	return nil
+}
+
+func (r *recordingVCS) Add(ctx context.Context, paths ...string) error {
+	r.added = append(r.added, paths...)
+	return nil
+}
+
+func (r *recordingVCS) Commit(ctx context.Context, message string) (string, error) {
+	r.messages = append(r.messages, message)
+	return "abc123", nil
+}
+
+func (r *recordingVCS) Status(ctx context.Context) ([]vcs.FileStatus, error) {
+	if r.dirty {
+		return []vcs.FileStatus{{Path: "package.json", Staging: vcs.Unmodified, Worktree: vcs.Modified}}, nil
+	}
+	return nil, nil
+}
+
+func (r *recordingVCS) Diff(ctx context.Context, from, to string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *recordingVCS) Tag(ctx context.Context, name, message string) error {
+	return nil
+}
+
+func (r *recordingVCS) Push(ctx context.Context, remote string, refs ...string) error {
+	return nil
+}
+
+const updaterManifest = `{
+  "name": "app",
+  "version": "1.0.0",
+  "scripts": {
+    "test": "node -e \"process.exit(0)\""
+  },
+  "dependencies": {
+    "lodash": "^4.17.20",
+    "@scope/pkg": "~1.0.0"
+  },
+  "devDependencies": {
+    "lodash": "^3.0.0",
+    "jest": "^29.0.0"
+  }
+}
+`
+
+func TestUpdaterApply(t *testing.T) {
+	if _, err := exec.LookPath("npm"); err != nil {
+		t.Skip("npm not available")
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), updaterManifest)
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), `{"lockfileVersion":3}`)
+
+	repo := &recordingVCS{branch: "main"}
+	updater := NewUpdater(dir, repo)
+	result, err := updater.Apply(context.Background(), UpdatePlan{
+		Updates: []DependencyUpdate{
+			{Name: "lodash", To: "^4.17.21"},
+			{Name: "jest", To: "^30.0.0"},
+			{Name: "@scope/pkg", To: "~1.0.0"},
+		},
+	}, UpdaterOptions{SkipLockfile: true})
+	if err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "package.json"))
+	expected := strings.Replace(updaterManifest, `"lodash": "^4.17.20"`, `"lodash": "^4.17.21"`, 1)
+	expected = strings.Replace(expected, `"jest": "^29.0.0"`, `"jest": "^30.0.0"`, 1)
+	if string(data) != expected {
+		t.Errorf("Unexpected package.json:\n%s", data)
+	}
+
+	if len(result.Applied) != 2 {
+		t.Fatalf("Expected 2 applied updates, got %+v", result.Applied)
+	}
+	if result.Applied[0].Type != Production || result.Applied[0].From != "^4.17.20" {
+		t.Errorf("Unexpected first update: %+v", result.Applied[0])
+	}
+	if result.Applied[1].Type != Development {
+		t.Errorf("Expected jest to be a dev dependency, got %+v", result.Applied[1])
+	}
+
+	if result.BaseBranch != "main" || result.Branch != "deps/update-3-dependencies" || result.Commit != "abc123" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if !result.TestsRun || !result.TestsPassed {
+		t.Errorf("Expected tests to pass, got %+v", result)
+	}
+	if strings.Join(repo.added, ",") != "package.json,package-lock.json" {
+		t.Errorf("Unexpected staged files: %v", repo.added)
+	}
+
+	message := repo.messages[0]
+	for _, line := range []string{
+		"Update 2 dependencies\n\n",
+		"| lodash | dependencies | ^4.17.20 | ^4.17.21 |",
+		"| jest | devDependencies | ^29.0.0 | ^30.0.0 |",
+		"Tests: passed",
+	} {
+		if !strings.Contains(message, line) {
+			t.Errorf("Expected commit message to contain %q, got:\n%s", line, message)
+		}
+	}
+}
+
+func TestUpdaterApplyFailingTests(t *testing.T) {
+	if _, err := exec.LookPath("npm"); err != nil {
+		t.Skip("npm not available")
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"),
+		`{"name":"app","scripts":{"test":"node -e \"process.exit(1)\""},"dependencies":{"left-pad":"1.0.0"}}`)
+
+	plan := UpdatePlan{Updates: []DependencyUpdate{{Name: "left-pad", To: "1.3.0", Type: Production}}}
+	if _, err := NewUpdater(dir, &recordingVCS{branch: "main"}).Apply(context.Background(), plan, UpdaterOptions{SkipLockfile: true}); err == nil {
+		t.Fatal("Expected error when tests fail")
+	}
+
+	writeTestFile(t, filepath.Join(dir, "package.json"),
+		`{"name":"app","scripts":{"test":"node -e \"process.exit(1)\""},"dependencies":{"left-pad":"1.0.0"}}`)
+	repo := &recordingVCS{branch: "main"}
+	result, err := NewUpdater(dir, repo).Apply(context.Background(), plan, UpdaterOptions{SkipLockfile: true, AllowFailingTests: true})
+	if err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+	if result.TestsPassed || result.Branch != "deps/left-pad-1.3.0" || result.Title != "Update left-pad to 1.3.0" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if !strings.Contains(repo.messages[0], "Tests: failed") {
+		t.Errorf("Expected failed tests in summary, got:\n%s", repo.messages[0])
+	}
+}
+
+func TestUpdaterApplyValidation(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), updaterManifest)
+	ctx := context.Background()
+
+	if _, err := NewUpdater(dir, &recordingVCS{}).Apply(ctx, UpdatePlan{}, UpdaterOptions{}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for empty plan, got %v", err)
+	}
+	if _, err := NewUpdater(dir, &recordingVCS{dirty: true}).Apply(ctx, UpdatePlan{Updates: []DependencyUpdate{{Name: "jest", To: "1"}}}, UpdaterOptions{}); err == nil {
+		t.Error("Expected error for dirty working tree")
+	}
+
+	tests := []DependencyUpdate{
+		{Name: "missing", To: "1.0.0"},
+		{Name: "lodash", From: "^1.0.0", To: "^4.17.21"},
+		{Name: "lodash", To: "^4.17.20"},
+	}
+	for _, update := range tests {
+		_, err := NewUpdater(dir, &recordingVCS{}).Apply(ctx, UpdatePlan{Updates: []DependencyUpdate{update}}, UpdaterOptions{SkipLockfile: true, SkipTests: true})
+		if err == nil {
+			t.Errorf("Expected error for %+v", update)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package npm
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultWatchExclude WatchOptions.Exclude为nil时排除的文件
+var DefaultWatchExclude = []string{"**/node_modules/**", ".git/**"}
+
+// WatchOptions 监视模式选项
+type WatchOptions struct {
+	WorkingDir string            `json:"working_dir,omitempty"` // 项目目录，同时也是监视的根目录
+	Args       []string          `json:"args,omitempty"`        // 传给脚本的参数
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+
+	// 监视的文件，相对于WorkingDir的glob，**匹配任意层目录，为空时监视所有文件
+	Include []string `json:"include,omitempty"`
+	// 忽略的文件和目录，nil时使用DefaultWatchExclude
+	Exclude []string `json:"exclude,omitempty"`
+
+	Debounce     time.Duration `json:"debounce,omitempty"`      // 最后一次变化后等待多久再重启，默认200ms
+	PollInterval time.Duration `json:"poll_interval,omitempty"` // 检查文件变化的间隔，默认500ms
+
+	OnOutput func(OutputLine) `json:"-"` // 逐行接收脚本的输出
+}
+
+// WatchStage 监视模式的阶段
+type WatchStage string
+
+const (
+	WatchStageStart   WatchStage = "start"   // 第一次启动脚本
+	WatchStageRestart WatchStage = "restart" // 文件变化后重新启动脚本
+	WatchStageExit    WatchStage = "exit"    // 脚本自行退出，继续等待文件变化
+	WatchStageStop    WatchStage = "stop"    // ctx取消，停止监视
+)
+
+// WatchEvent 监视模式在事件总线上发送的事件
+type WatchEvent struct {
+	Stage   WatchStage `json:"stage"`
+	Script  string     `json:"script"`
+	Run     int        `json:"run"`               // 第几次运行，从1开始
+	Changed []string   `json:"changed,omitempty"` // restart阶段触发重启的文件，相对于WorkingDir
+	Time    time.Time  `json:"time"`
+	Err     error      `json:"-"` // exit阶段脚本失败时的错误
+}
+
+// EventType 实现Event接口
+func (e WatchEvent) EventType() string {
+	return "watch." + string(e.Stage)
+}
+
+// ScriptRunner 在客户端之上提供更高层的脚本运行方式
+type ScriptRunner struct {
+	client Client
+}
+
+// NewScriptRunner 创建脚本运行器
+func NewScriptRunner(client Client) *ScriptRunner {
+	return &ScriptRunner{client: client}
+}
+
+// Watch 运行脚本并监视项目文件，文件变化后重新运行，直到ctx取消
+//
+// 连续的变化在Debounce内合并为一次重启。重启时先终止正在运行的脚本及其子进程，
+// 适合开发服务器；脚本自行退出后（例如构建脚本）继续监视，下次变化时再次运行。
+// 每次启动、重启和退出都在客户端的事件总线上发送WatchEvent。
+// 文件变化通过定期扫描检测，不依赖平台的文件通知接口。ctx取消后返回ctx.Err()。
+func (r *ScriptRunner) Watch(ctx context.Context, script string, options WatchOptions) error {
+	if script == "" {
+		return NewValidationError("script", script, "script name cannot be empty")
+	}
+
+	root := options.WorkingDir
+	if root == "" {
+		root = "."
+	}
+	exclude := options.Exclude
+	if exclude == nil {
+		exclude = DefaultWatchExclude
+	}
+	for _, pattern := range append(append([]string(nil), options.Include...), exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return NewValidationError("pattern", pattern, "invalid glob pattern")
+		}
+	}
+	debounce := options.Debounce
+	if debounce <= 0 {
+		debounce = 200 * time.Millisecond
+	}
+	pollInterval := options.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = 500 * time.Millisecond
+	}
+
+	files, err := scanWatchedFiles(root, options.Include, exclude)
+	if err != nil {
+		return err
+	}
+
+	runOptions := RunScriptOptions{
+		Args:         options.Args,
+		WorkingDir:   options.WorkingDir,
+		Env:          options.Env,
+		UserConfig:   options.UserConfig,
+		OnOutput:     options.OnOutput,
+		ProcessGroup: true,
+		Timeout:      -1,
+	}
+	events := r.client.Events()
+
+	var (
+		run    int
+		cancel context.CancelFunc
+		done   chan error
+	)
+	start := func(stage WatchStage, changed []string) {
+		run++
+		var runCtx context.Context
+		runCtx, cancel = context.WithCancel(ctx)
+		done = make(chan error, 1)
+		go func(done chan<- error) {
+			done <- r.client.RunScriptWithOptions(runCtx, script, runOptions)
+		}(done)
+		events.Emit(WatchEvent{Stage: stage, Script: script, Run: run, Changed: changed, Time: time.Now()})
+	}
+	stop := func() {
+		if done != nil {
+			cancel()
+			<-done
+			done = nil
+		}
+	}
+
+	start(WatchStageStart, nil)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	debounceTimer := time.NewTimer(debounce)
+	debounceTimer.Stop()
+	var pending []string
+
+	for {
+		select {
+		case <-ctx.Done():
+			stop()
+			events.Emit(WatchEvent{Stage: WatchStageStop, Script: script, Run: run, Time: time.Now()})
+			return ctx.Err()
+
+		case err := <-done:
+			done = nil
+			cancel()
+			events.Emit(WatchEvent{Stage: WatchStageExit, Script: script, Run: run, Time: time.Now(), Err: err})
+
+		case <-ticker.C:
+			current, err := scanWatchedFiles(root, options.Include, exclude)
+			if err != nil {
+				// 监视的目录暂时不可读时跳过这一轮
+				continue
+			}
+			if changed := changedFiles(files, current); len(changed) > 0 {
+				pending = mergeChanged(pending, changed)
+				debounceTimer.Reset(debounce)
+			}
+			files = current
+
+		case <-debounceTimer.C:
+			stop()
+			start(WatchStageRestart, pending)
+			pending = nil
+		}
+	}
+}
+
+// watchedFile 监视的文件状态
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+}
+
+// scanWatchedFiles 扫描root下匹配include且不匹配exclude的文件
+func scanWatchedFiles(root string, include, exclude []string) (map[string]watchedFile, error) {
+	files := make(map[string]watchedFile)
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			// 扫描期间被删除或无法读取的文件忽略
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+
+		for _, pattern := range exclude {
+			if matchGlob(pattern, rel) {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		if len(include) > 0 {
+			matched := false
+			for _, pattern := range include {
+				if matchGlob(pattern, rel) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil
+			}
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		files[rel] = watchedFile{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return files, err
+}
+
+// changedFiles 返回新增、删除或修改过的文件，按路径排序
+func changedFiles(before, after map[string]watchedFile) []string {
+	var changed []string
+	for name, file := range after {
+		if old, ok := before[name]; !ok || !old.modTime.Equal(file.modTime) || old.size != file.size {
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// mergeChanged 合并两次扫描之间的变化，结果按路径排序且不重复
+func mergeChanged(pending, changed []string) []string {
+	for _, name := range changed {
+		if idx := sort.SearchStrings(pending, name); idx == len(pending) || pending[idx] != name {
+			pending = append(pending, "")
+			copy(pending[idx+1:], pending[idx:])
+			pending[idx] = name
+		}
+	}
+	return pending
+}
+
+// matchGlob 检查斜杠分隔的相对路径是否匹配glob，**匹配零个或多个目录层级，
+// 其余部分按path.Match匹配
+func matchGlob(pattern, name string) bool {
+	return matchGlobParts(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchGlobParts 按路径段匹配
+func matchGlobParts(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlobParts(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
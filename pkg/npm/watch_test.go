@@ -0,0 +1,164 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface/mock"
+)
+
+// watchEvents 订阅事件总线上的WatchEvent
+func watchEvents(bus *EventBus) <-chan WatchEvent {
+	ch := make(chan WatchEvent, 16)
+	bus.Subscribe(func(event Event) {
+		if e, ok := event.(WatchEvent); ok {
+			ch <- e
+		}
+	})
+	return ch
+}
+
+func nextWatchEvent(t *testing.T, ch <-chan WatchEvent) WatchEvent {
+	t.Helper()
+	select {
+	case e := <-ch:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for watch event")
+		return WatchEvent{}
+	}
+}
+
+func TestScriptRunnerWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "src", "index.js"), "console.log(1)\n")
+	writeTestFile(t, filepath.Join(dir, "README.md"), "# app\n")
+	writeTestFile(t, filepath.Join(dir, "src", "node_modules", "dep", "index.js"), "module.exports = 1\n")
+
+	bus := NewEventBus()
+	events := watchEvents(bus)
+	runOptions := make(chan RunScriptOptions, 4)
+	client := &mock.Client{
+		EventsFunc: func() *EventBus { return bus },
+		RunScriptWithOptionsFunc: func(ctx context.Context, script string, options RunScriptOptions) error {
+			runOptions <- options
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result := make(chan error, 1)
+	go func() {
+		result <- NewScriptRunner(client).Watch(ctx, "dev", WatchOptions{
+			WorkingDir:   dir,
+			Args:         []string{"--port", "3000"},
+			Include:      []string{"src/**/*.js"},
+			Debounce:     20 * time.Millisecond,
+			PollInterval: 10 * time.Millisecond,
+		})
+	}()
+
+	if e := nextWatchEvent(t, events); e.Stage != WatchStageStart || e.Run != 1 || e.EventType() != "watch.start" {
+		t.Fatalf("Unexpected event: %+v", e)
+	}
+	options := <-runOptions
+	if !options.ProcessGroup || options.Timeout >= 0 || options.WorkingDir != dir || !reflect.DeepEqual(options.Args, []string{"--port", "3000"}) {
+		t.Errorf("Unexpected run options: %+v", options)
+	}
+
+	// 不匹配Include和被排除的文件不触发重启
+	time.Sleep(50 * time.Millisecond)
+	writeTestFile(t, filepath.Join(dir, "README.md"), "# app\n\nchanged\n")
+	writeTestFile(t, filepath.Join(dir, "src", "node_modules", "dep", "index.js"), "module.exports = 2\n")
+	writeTestFile(t, filepath.Join(dir, "src", "index.js"), "console.log(2)\n")
+	writeTestFile(t, filepath.Join(dir, "src", "lib", "util.js"), "exports.x = 1\n")
+
+	e := nextWatchEvent(t, events)
+	if e.Stage != WatchStageRestart || e.Run != 2 || !reflect.DeepEqual(e.Changed, []string{"src/index.js", "src/lib/util.js"}) {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+	<-runOptions
+
+	cancel()
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if e := nextWatchEvent(t, events); e.Stage != WatchStageStop || e.Run != 2 {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+}
+
+func TestScriptRunnerWatchExit(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "index.js"), "1\n")
+
+	bus := NewEventBus()
+	events := watchEvents(bus)
+	failure := errors.New("build failed")
+	client := &mock.Client{
+		EventsFunc: func() *EventBus { return bus },
+		RunScriptWithOptionsFunc: func(ctx context.Context, script string, options RunScriptOptions) error {
+			return failure
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go NewScriptRunner(client).Watch(ctx, "build", WatchOptions{WorkingDir: dir, Debounce: 10 * time.Millisecond, PollInterval: 10 * time.Millisecond})
+
+	nextWatchEvent(t, events)
+	if e := nextWatchEvent(t, events); e.Stage != WatchStageExit || e.Err != failure {
+		t.Fatalf("Unexpected event: %+v", e)
+	}
+
+	// 脚本退出后继续监视，文件变化时再次运行
+	time.Sleep(50 * time.Millisecond)
+	writeTestFile(t, filepath.Join(dir, "index.js"), "22\n")
+	if e := nextWatchEvent(t, events); e.Stage != WatchStageRestart || e.Run != 2 {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+}
+
+func TestScriptRunnerWatchValidation(t *testing.T) {
+	runner := NewScriptRunner(&mock.Client{})
+	if err := runner.Watch(context.Background(), "", WatchOptions{}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for empty script, got %v", err)
+	}
+	if err := runner.Watch(context.Background(), "dev", WatchOptions{Include: []string{"src/["}}); !IsValidationError(err, nil) {
+		t.Errorf("Expected validation error for invalid pattern, got %v", err)
+	}
+	if err := runner.Watch(context.Background(), "dev", WatchOptions{WorkingDir: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		matched bool
+	}{
+		{"src/**", "src/index.js", true},
+		{"src/**", "src/a/b/c.ts", true},
+		{"src/**", "lib/index.js", false},
+		{"**/*.js", "index.js", true},
+		{"**/*.js", "src/a/index.js", true},
+		{"**/*.js", "src/a/index.ts", false},
+		{"**/node_modules/**", "node_modules", true},
+		{"**/node_modules/**", "packages/a/node_modules/b/index.js", true},
+		{"src/*.js", "src/a/index.js", false},
+		{"src/**/test/*.js", "src/test/a.js", true},
+		{"package.json", "package.json", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.matched {
+			t.Errorf("matchGlob(%q, %q) = %v, expected %v", tt.pattern, tt.name, got, tt.matched)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package npm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// workspaceProtocol pnpm和yarn使用的工作区依赖前缀
+const workspaceProtocol = "workspace:"
+
+// Workspace monorepo中的一个工作区包
+type Workspace struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Dir     string `json:"dir"`  // 绝对路径
+	Path    string `json:"path"` // 相对根目录的路径，使用/分隔
+	Private bool   `json:"private,omitempty"`
+
+	manifest     string
+	dependencies map[DependencyType]map[string]string
+	scripts      map[string]string
+}
+
+// WorkspaceReference 工作区包之间的依赖引用
+type WorkspaceReference struct {
+	Package    string         `json:"package"`    // 声明依赖的工作区包
+	Dependency string         `json:"dependency"` // 被依赖的包名
+	Type       DependencyType `json:"type"`
+	Spec       string         `json:"spec"`               // package.json中的原始写法
+	Resolved   string         `json:"resolved,omitempty"` // 替换后的具体版本范围
+	Problem    string         `json:"problem,omitempty"`  // 无法解析的原因
+}
+
+// WorkspaceManager 处理monorepo中工作区包之间的依赖
+//
+// 工作区来自根目录package.json的workspaces字段或pnpm-workspace.yaml，
+// 模式按filepath.Glob展开，不支持**。
+type WorkspaceManager struct {
+	rootDir string
+}
+
+// NewWorkspaceManager 创建工作区管理器
+func NewWorkspaceManager(rootDir string) *WorkspaceManager {
+	return &WorkspaceManager{rootDir: rootDir}
+}
+
+// Workspaces 列出所有工作区包，按路径排序
+func (m *WorkspaceManager) Workspaces() ([]Workspace, error) {
+	root, err := filepath.Abs(m.rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve root directory: %w", err)
+	}
+	patterns, err := detectWorkspacePatterns(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no workspaces configured in %s", root)
+	}
+
+	seen := make(map[string]bool)
+	var workspaces []Workspace
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(strings.TrimSuffix(pattern, "/"))))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace pattern %q: %w", pattern, err)
+		}
+		for _, dir := range matches {
+			if seen[dir] || strings.Contains(dir, string(filepath.Separator)+"node_modules"+string(filepath.Separator)) {
+				continue
+			}
+			seen[dir] = true
+
+			workspace, err := loadWorkspace(root, dir)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			workspaces = append(workspaces, *workspace)
+		}
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].Path < workspaces[j].Path
+	})
+	return workspaces, nil
+}
+
+// loadWorkspace 读取工作区目录中的package.json，不存在时返回os.ErrNotExist
+func loadWorkspace(root, dir string) (*Workspace, error) {
+	manifest := filepath.Join(dir, "package.json")
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", manifest, err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := utils.DecodeJSON(manifest, data, &raw); err != nil {
+		return nil, err
+	}
+
+	rel, _ := filepath.Rel(root, dir)
+	workspace := &Workspace{
+		Dir:          dir,
+		Path:         filepath.ToSlash(rel),
+		manifest:     manifest,
+		dependencies: make(map[DependencyType]map[string]string),
+	}
+	json.Unmarshal(raw["name"], &workspace.Name)
+	json.Unmarshal(raw["version"], &workspace.Version)
+	json.Unmarshal(raw["private"], &workspace.Private)
+	json.Unmarshal(raw["scripts"], &workspace.scripts)
+	for _, depType := range []DependencyType{Production, Development, Optional, Peer} {
+		deps := make(map[string]string)
+		if section, ok := raw[string(depType)]; ok {
+			if err := json.Unmarshal(section, &deps); err != nil {
+				return nil, fmt.Errorf("failed to parse %s in %s: %w", depType, manifest, err)
+			}
+		}
+		workspace.dependencies[depType] = deps
+	}
+	return workspace, nil
+}
+
+// References 列出所有workspace:、file:和link:形式的引用，并计算发布时应替换成的版本范围
+//
+// workspace:*替换为精确版本，workspace:^和workspace:~加上对应前缀，
+// workspace:<range>保留范围本身；指向工作区的file:和link:替换为^<version>。
+func (m *WorkspaceManager) References() ([]WorkspaceReference, error) {
+	workspaces, err := m.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+
+	return workspaceReferences(workspaces), nil
+}
+
+// workspaceReferences 收集工作区引用并解析
+func workspaceReferences(workspaces []Workspace) []WorkspaceReference {
+	var references []WorkspaceReference
+	forEachDependency(workspaces, func(workspace *Workspace, depType DependencyType, name, spec string) {
+		if !strings.HasPrefix(spec, workspaceProtocol) && !strings.HasPrefix(spec, "file:") && !strings.HasPrefix(spec, "link:") {
+			return
+		}
+		reference := WorkspaceReference{Package: workspace.Name, Dependency: name, Type: depType, Spec: spec}
+		reference.Resolved, reference.Problem = resolveWorkspaceSpec(workspaces, workspace, name, spec)
+		references = append(references, reference)
+	})
+	return references
+}
+
+// Validate 检查所有工作区引用都能解析为具体版本
+func (m *WorkspaceManager) Validate() error {
+	references, err := m.References()
+	if err != nil {
+		return err
+	}
+	return workspaceReferenceError(references)
+}
+
+// ResolveForPublish 在指定工作区包（为空时为全部）的package.json中把工作区引用替换为具体版本
+//
+// 用于发布前处理，修改后的文件可在发布完成后通过版本控制恢复。任一引用无法解析时不修改任何文件。
+func (m *WorkspaceManager) ResolveForPublish(packages ...string) ([]WorkspaceReference, error) {
+	workspaces, err := m.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool)
+	for _, name := range packages {
+		selected[name] = true
+	}
+	var rewrites []WorkspaceReference
+	for _, reference := range workspaceReferences(workspaces) {
+		if len(selected) == 0 || selected[reference.Package] {
+			rewrites = append(rewrites, reference)
+		}
+	}
+	if err := workspaceReferenceError(rewrites); err != nil {
+		return nil, err
+	}
+
+	if err := applyWorkspaceReferences(workspaces, rewrites); err != nil {
+		return nil, err
+	}
+	return rewrites, nil
+}
+
+// WorkspaceRangeStyle 同步时工作区包之间依赖范围的写法
+type WorkspaceRangeStyle string
+
+const (
+	RangeStylePreserve  WorkspaceRangeStyle = ""          // 保留原有的前缀和workspace:协议
+	RangeStyleExact     WorkspaceRangeStyle = "exact"     // 1.2.3
+	RangeStyleCaret     WorkspaceRangeStyle = "caret"     // ^1.2.3
+	RangeStyleWorkspace WorkspaceRangeStyle = "workspace" // workspace:*，只有pnpm和yarn支持
+)
+
+// WorkspaceSyncOptions 同步工作区依赖版本的选项
+type WorkspaceSyncOptions struct {
+	Style  WorkspaceRangeStyle `json:"style,omitempty"`
+	DryRun bool                `json:"dry_run,omitempty"` // 只检查需要修改的引用，不写入文件
+}
+
+// SyncVersions 版本号变更后，把工作区包之间的依赖范围同步到被依赖包的当前版本
+//
+// 只处理<version>、^<version>、~<version>及其workspace:形式，保留原有前缀；
+// *、复杂范围和路径引用保持不变。返回实际修改的引用。
+func (m *WorkspaceManager) SyncVersions() ([]WorkspaceReference, error) {
+	return m.SyncVersionsWithOptions(WorkspaceSyncOptions{})
+}
+
+// SyncVersionsWithOptions 按options.Style统一工作区包之间的依赖范围，返回需要修改的引用
+//
+// 除SyncVersions处理的写法外，指定Style时workspace:*、workspace:^和workspace:~也会改写。
+// RangeStyleExact和RangeStyleCaret生成不带workspace:协议的范围，适合发布前使用。
+func (m *WorkspaceManager) SyncVersionsWithOptions(options WorkspaceSyncOptions) ([]WorkspaceReference, error) {
+	switch options.Style {
+	case RangeStylePreserve, RangeStyleExact, RangeStyleCaret, RangeStyleWorkspace:
+	default:
+		return nil, NewValidationError("style", string(options.Style), "must be exact, caret, workspace or empty")
+	}
+
+	workspaces, err := m.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*Workspace)
+	for i := range workspaces {
+		byName[workspaces[i].Name] = &workspaces[i]
+	}
+
+	var updates []WorkspaceReference
+	forEachDependency(workspaces, func(workspace *Workspace, depType DependencyType, name, spec string) {
+		target, ok := byName[name]
+		if !ok || target.Version == "" {
+			return
+		}
+
+		protocol := ""
+		rng := spec
+		if strings.HasPrefix(spec, workspaceProtocol) {
+			protocol = workspaceProtocol
+			rng = strings.TrimPrefix(spec, workspaceProtocol)
+		}
+		prefix := ""
+		if strings.HasPrefix(rng, "^") || strings.HasPrefix(rng, "~") {
+			prefix = rng[:1]
+		}
+		dynamic := protocol != "" && (rng == "*" || rng == "^" || rng == "~")
+		if !semver.Valid(strings.TrimPrefix(rng, prefix)) && !(dynamic && options.Style != RangeStylePreserve) {
+			return
+		}
+
+		var synced string
+		switch options.Style {
+		case RangeStyleExact:
+			synced = target.Version
+		case RangeStyleCaret:
+			synced = "^" + target.Version
+		case RangeStyleWorkspace:
+			synced = workspaceProtocol + "*"
+		default:
+			synced = protocol + prefix + target.Version
+		}
+		if synced != spec {
+			updates = append(updates, WorkspaceReference{Package: workspace.Name, Dependency: name, Type: depType, Spec: spec, Resolved: synced})
+		}
+	})
+
+	if options.DryRun {
+		return updates, nil
+	}
+	if err := applyWorkspaceReferences(workspaces, updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// forEachDependency 按工作区路径、依赖类型和包名的顺序遍历依赖
+func forEachDependency(workspaces []Workspace, fn func(workspace *Workspace, depType DependencyType, name, spec string)) {
+	for i := range workspaces {
+		workspace := &workspaces[i]
+		for _, depType := range []DependencyType{Production, Development, Optional, Peer} {
+			deps := workspace.dependencies[depType]
+			names := make([]string, 0, len(deps))
+			for name := range deps {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fn(workspace, depType, name, deps[name])
+			}
+		}
+	}
+}
+
+// resolveWorkspaceSpec 解析工作区引用，返回替换后的范围或无法解析的原因
+func resolveWorkspaceSpec(workspaces []Workspace, from *Workspace, name, spec string) (string, string) {
+	var target *Workspace
+	rng := ""
+
+	if strings.HasPrefix(spec, workspaceProtocol) {
+		rest := strings.TrimPrefix(spec, workspaceProtocol)
+		if strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/") {
+			target = findWorkspaceByDir(workspaces, from.Dir, rest)
+		} else {
+			target = findWorkspaceByName(workspaces, name)
+			rng = rest
+		}
+	} else {
+		_, path, _ := strings.Cut(spec, ":")
+		target = findWorkspaceByDir(workspaces, from.Dir, path)
+		rng = "^"
+	}
+
+	if target == nil {
+		return "", "not a workspace package"
+	}
+	if target.Version == "" {
+		return "", fmt.Sprintf("workspace package %s has no version", target.Name)
+	}
+
+	var resolved string
+	switch rng {
+	case "", "*":
+		resolved = target.Version
+	case "^", "~":
+		resolved = rng + target.Version
+	default:
+		if !semver.ValidRange(rng) {
+			return "", fmt.Sprintf("invalid range %q", rng)
+		}
+		if !semver.Satisfies(target.Version, rng) {
+			return "", fmt.Sprintf("%s@%s does not satisfy %s", target.Name, target.Version, rng)
+		}
+		resolved = rng
+	}
+
+	// 依赖名与工作区包名不同时使用npm别名
+	if target.Name != name {
+		resolved = "npm:" + target.Name + "@" + resolved
+	}
+	return resolved, ""
+}
+
+// findWorkspaceByName 按包名查找工作区
+func findWorkspaceByName(workspaces []Workspace, name string) *Workspace {
+	for i := range workspaces {
+		if workspaces[i].Name == name {
+			return &workspaces[i]
+		}
+	}
+	return nil
+}
+
+// findWorkspaceByDir 按相对from的路径查找工作区
+func findWorkspaceByDir(workspaces []Workspace, from, path string) *Workspace {
+	dir := filepath.FromSlash(path)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(from, dir)
+	}
+	dir = filepath.Clean(dir)
+	for i := range workspaces {
+		if workspaces[i].Dir == dir {
+			return &workspaces[i]
+		}
+	}
+	return nil
+}
+
+// workspaceReferenceError 汇总无法解析的引用
+func workspaceReferenceError(references []WorkspaceReference) error {
+	var problems []string
+	for _, reference := range references {
+		if reference.Problem != "" {
+			problems = append(problems, fmt.Sprintf("%s -> %s (%s): %s", reference.Package, reference.Dependency, reference.Spec, reference.Problem))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid workspace references: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// applyWorkspaceReferences 把引用的Resolved写回各自的package.json，保留原有格式
+func applyWorkspaceReferences(workspaces []Workspace, references []WorkspaceReference) error {
+	for i := range workspaces {
+		var updates []DependencyUpdate
+		for _, reference := range references {
+			if reference.Package == workspaces[i].Name && reference.Resolved != reference.Spec {
+				updates = append(updates, DependencyUpdate{
+					Name: reference.Dependency,
+					From: reference.Spec,
+					To:   reference.Resolved,
+					Type: reference.Type,
+				})
+			}
+		}
+		if len(updates) == 0 {
+			continue
+		}
+		if _, err := updateManifestRanges(workspaces[i].manifest, updates); err != nil {
+			return fmt.Errorf("failed to update %s: %w", workspaces[i].Path, err)
+		}
+	}
+	return nil
+}
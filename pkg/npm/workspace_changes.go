@@ -0,0 +1,185 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/vcs"
+)
+
+// ChangedWorkspace 两个版本之间有变化的工作区包
+type ChangedWorkspace struct {
+	Workspace
+	Direct bool     `json:"direct"`          // 包目录中有文件修改
+	Files  []string `json:"files,omitempty"` // 包目录中修改的文件，相对仓库根目录
+	Via    []string `json:"via,omitempty"`   // 依赖的有变化的工作区包，只列出直接依赖
+}
+
+// TopologicalOrder 按依赖顺序列出工作区包，被依赖的包排在前面，没有先后关系的包按路径排序
+//
+// 工作区之间的依赖包括所有依赖类型中的workspace:、file:、link:引用，
+// 以及版本范围满足工作区包版本的同名依赖。依赖存在环时返回错误。
+func (m *WorkspaceManager) TopologicalOrder() ([]Workspace, error) {
+	workspaces, err := m.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	order, err := topologicalWorkspaces(workspaces, workspaceDependencyGraph(workspaces))
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]Workspace, len(order))
+	for i, index := range order {
+		sorted[i] = workspaces[index]
+	}
+	return sorted, nil
+}
+
+// Changed 找出from和to之间有变化的工作区包，按依赖顺序返回，用于只构建或发布受影响的包
+//
+// 包目录中有文件修改的包为直接变化，依赖了有变化的工作区包的包（包括间接依赖）也视为有变化。
+// from和to的含义与vcs.VCS.Diff相同：from为空时使用HEAD，to为空时与工作区比较。
+// Diff返回的路径应相对monorepo根目录，即根目录就是仓库根目录；
+// 不属于任何工作区包的文件（例如根目录的锁文件）不影响结果。
+func (m *WorkspaceManager) Changed(ctx context.Context, repo vcs.VCS, from, to string) ([]ChangedWorkspace, error) {
+	workspaces, err := m.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	graph := workspaceDependencyGraph(workspaces)
+	order, err := topologicalWorkspaces(workspaces, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := repo.Diff(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[int][]string)
+	for _, path := range paths {
+		if index := owningWorkspace(workspaces, path); index >= 0 {
+			files[index] = append(files[index], path)
+		}
+	}
+
+	// 按依赖顺序处理，依赖总是先于依赖它的包确定是否有变化
+	changed := make(map[int]bool)
+	var result []ChangedWorkspace
+	for _, index := range order {
+		entry := ChangedWorkspace{Workspace: workspaces[index], Files: files[index], Direct: len(files[index]) > 0}
+		for _, dependency := range graph[index] {
+			if changed[dependency] {
+				entry.Via = append(entry.Via, workspaces[dependency].Name)
+			}
+		}
+		if entry.Direct || len(entry.Via) > 0 {
+			sort.Strings(entry.Via)
+			changed[index] = true
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// owningWorkspace 返回包含path的工作区下标，嵌套时取最深的工作区，不属于任何工作区时返回-1
+func owningWorkspace(workspaces []Workspace, path string) int {
+	owner := -1
+	for i := range workspaces {
+		if strings.HasPrefix(path, workspaces[i].Path+"/") && (owner < 0 || len(workspaces[i].Path) > len(workspaces[owner].Path)) {
+			owner = i
+		}
+	}
+	return owner
+}
+
+// workspaceDependencyGraph 计算每个工作区依赖的工作区下标，按下标排序并去重
+func workspaceDependencyGraph(workspaces []Workspace) [][]int {
+	index := make(map[*Workspace]int)
+	for i := range workspaces {
+		index[&workspaces[i]] = i
+	}
+
+	graph := make([][]int, len(workspaces))
+	seen := make(map[[2]int]bool)
+	forEachDependency(workspaces, func(workspace *Workspace, depType DependencyType, name, spec string) {
+		target := workspaceDependencyTarget(workspaces, workspace, name, spec)
+		if target == nil || target == workspace {
+			return
+		}
+		edge := [2]int{index[workspace], index[target]}
+		if !seen[edge] {
+			seen[edge] = true
+			graph[edge[0]] = append(graph[edge[0]], edge[1])
+		}
+	})
+	for _, dependencies := range graph {
+		sort.Ints(dependencies)
+	}
+	return graph
+}
+
+// workspaceDependencyTarget 返回依赖指向的工作区包，指向registry等其他来源时返回nil
+func workspaceDependencyTarget(workspaces []Workspace, from *Workspace, name, spec string) *Workspace {
+	switch {
+	case strings.HasPrefix(spec, workspaceProtocol):
+		rest := strings.TrimPrefix(spec, workspaceProtocol)
+		if strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/") {
+			return findWorkspaceByDir(workspaces, from.Dir, rest)
+		}
+		return findWorkspaceByName(workspaces, name)
+	case strings.HasPrefix(spec, "file:") || strings.HasPrefix(spec, "link:"):
+		_, path, _ := strings.Cut(spec, ":")
+		return findWorkspaceByDir(workspaces, from.Dir, path)
+	}
+
+	// npm只在版本满足范围时链接同名的工作区包
+	target := findWorkspaceByName(workspaces, name)
+	if target == nil || !semver.ValidRange(spec) || (target.Version != "" && !semver.Satisfies(target.Version, spec)) {
+		return nil
+	}
+	return target
+}
+
+// topologicalWorkspaces 按依赖顺序返回工作区下标，没有先后关系时按路径排序，存在环时返回错误
+func topologicalWorkspaces(workspaces []Workspace, graph [][]int) ([]int, error) {
+	pending := make([]int, len(workspaces))
+	dependents := make([][]int, len(workspaces))
+	for i, dependencies := range graph {
+		pending[i] = len(dependencies)
+		for _, dependency := range dependencies {
+			dependents[dependency] = append(dependents[dependency], i)
+		}
+	}
+
+	// workspaces已按路径排序，每次取下标最小的就绪节点
+	var order []int
+	done := make([]bool, len(workspaces))
+	for len(order) < len(workspaces) {
+		next := -1
+		for i := range workspaces {
+			if !done[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var cycle []string
+			for i := range workspaces {
+				if !done[i] {
+					cycle = append(cycle, workspaces[i].Name)
+				}
+			}
+			return nil, fmt.Errorf("workspace dependency cycle, cannot order %s", strings.Join(cycle, ", "))
+		}
+		done[next] = true
+		order = append(order, next)
+		for _, dependent := range dependents[next] {
+			pending[dependent]--
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,95 @@
+package npm
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// diffVCS 返回固定修改文件列表的VCS
+type diffVCS struct {
+	recordingVCS
+	paths []string
+	from  string
+}
+
+func (d *diffVCS) Diff(ctx context.Context, from, to string) ([]string, error) {
+	d.from = from
+	return d.paths, nil
+}
+
+func TestWorkspaceManagerTopologicalOrder(t *testing.T) {
+	root := writeTestMonorepo(t)
+	manager := NewWorkspaceManager(root)
+
+	workspaces, err := manager.TopologicalOrder()
+	if err != nil {
+		t.Fatalf("TopologicalOrder() failed: %v", err)
+	}
+	var names []string
+	for _, workspace := range workspaces {
+		names = append(names, workspace.Name)
+	}
+	if expected := []string{"@repo/core", "@repo/utils", "@repo/app"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+
+	// 版本范围满足时同名依赖也算工作区依赖，形成环
+	writeTestFile(t, filepath.Join(root, "packages", "core", "package.json"), `{"name":"@repo/core","version":"1.2.0","devDependencies":{"@repo/app":"^2.0.0"}}`)
+	if _, err := manager.TopologicalOrder(); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected cycle error, got %v", err)
+	}
+
+	// 不满足范围的依赖从registry安装，不算工作区依赖
+	writeTestFile(t, filepath.Join(root, "packages", "core", "package.json"), `{"name":"@repo/core","version":"1.2.0","devDependencies":{"@repo/app":"^1.0.0"}}`)
+	if _, err := manager.TopologicalOrder(); err != nil {
+		t.Errorf("TopologicalOrder() failed: %v", err)
+	}
+}
+
+func TestWorkspaceManagerChanged(t *testing.T) {
+	root := writeTestMonorepo(t)
+	manager := NewWorkspaceManager(root)
+	ctx := context.Background()
+
+	repo := &diffVCS{paths: []string{"README.md", "packages/utils/index.js"}}
+	changed, err := manager.Changed(ctx, repo, "origin/main", "")
+	if err != nil {
+		t.Fatalf("Changed() failed: %v", err)
+	}
+	if repo.from != "origin/main" {
+		t.Errorf("Expected diff from origin/main, got %q", repo.from)
+	}
+	if len(changed) != 2 {
+		t.Fatalf("Expected 2 changed workspaces, got %+v", changed)
+	}
+	if changed[0].Name != "@repo/utils" || !changed[0].Direct || !reflect.DeepEqual(changed[0].Files, []string{"packages/utils/index.js"}) {
+		t.Errorf("Unexpected utils entry: %+v", changed[0])
+	}
+	if changed[1].Name != "@repo/app" || changed[1].Direct || !reflect.DeepEqual(changed[1].Via, []string{"@repo/utils"}) {
+		t.Errorf("Unexpected app entry: %+v", changed[1])
+	}
+
+	repo.paths = []string{"packages/core/src/index.js"}
+	changed, err = manager.Changed(ctx, repo, "", "")
+	if err != nil {
+		t.Fatalf("Changed() failed: %v", err)
+	}
+	var names []string
+	for _, workspace := range changed {
+		names = append(names, workspace.Name)
+	}
+	if expected := []string{"@repo/core", "@repo/utils", "@repo/app"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+	if !reflect.DeepEqual(changed[2].Via, []string{"@repo/core", "@repo/utils"}) {
+		t.Errorf("Unexpected app entry: %+v", changed[2])
+	}
+
+	repo.paths = []string{"package-lock.json"}
+	if changed, err := manager.Changed(ctx, repo, "", ""); err != nil || len(changed) != 0 {
+		t.Errorf("Expected no changed workspaces, got %+v, %v", changed, err)
+	}
+}
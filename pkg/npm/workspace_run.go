@@ -0,0 +1,232 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WorkspaceRunStatus 工作区包中脚本的运行状态
+type WorkspaceRunStatus string
+
+const (
+	WorkspaceRunSucceeded WorkspaceRunStatus = "succeeded"
+	WorkspaceRunFailed    WorkspaceRunStatus = "failed"
+	WorkspaceRunSkipped   WorkspaceRunStatus = "skipped"   // 包中没有该脚本
+	WorkspaceRunBlocked   WorkspaceRunStatus = "blocked"   // 依赖的工作区包运行失败
+	WorkspaceRunCancelled WorkspaceRunStatus = "cancelled" // 快速失败或ctx取消时未完成
+)
+
+// WorkspaceRunOptions 在工作区中运行脚本的选项
+type WorkspaceRunOptions struct {
+	Client      Client            `json:"-"`                     // 运行脚本的客户端，为nil时使用NewClient创建
+	Packages    []string          `json:"packages,omitempty"`    // 只在这些包中运行，为空时为全部，例如Changed的结果
+	Concurrency int               `json:"concurrency,omitempty"` // 同时运行的包数量，默认为1
+	FailFast    bool              `json:"fail_fast,omitempty"`   // 一个包失败后取消正在运行的包，不再启动新的包
+	Args        []string          `json:"args,omitempty"`        // 传给脚本的参数
+	Env         map[string]string `json:"env,omitempty"`
+	UserConfig  string            `json:"user_config,omitempty"`
+	Timeout     time.Duration     `json:"timeout,omitempty"` // 每个包的超时时间，含义与RunScriptOptions.Timeout相同
+
+	// 逐行接收脚本的输出，并发运行时不同包的回调可能同时发生
+	OnOutput func(workspace string, line OutputLine) `json:"-"`
+}
+
+// WorkspaceRunResult 一个工作区包中脚本的运行结果
+type WorkspaceRunResult struct {
+	Name     string             `json:"name"`
+	Path     string             `json:"path"`
+	Status   WorkspaceRunStatus `json:"status"`
+	Err      error              `json:"-"`
+	Error    string             `json:"error,omitempty"`
+	Duration time.Duration      `json:"duration,omitempty"`
+}
+
+// RunScriptTopo 按依赖顺序在工作区包中运行脚本，类似npm run -ws，但包总是在它依赖的工作区包之后运行
+//
+// 依赖关系与TopologicalOrder相同，没有依赖关系的包可以并发运行。没有该脚本的包跳过，
+// 依赖失败的包不会运行。结果按依赖顺序排列，任一包失败时同时返回汇总的错误。
+func (m *WorkspaceManager) RunScriptTopo(ctx context.Context, script string, options WorkspaceRunOptions) ([]WorkspaceRunResult, error) {
+	if script == "" {
+		return nil, NewValidationError("script", "", "script name cannot be empty")
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	npmClient := options.Client
+	if npmClient == nil {
+		var err error
+		if npmClient, err = NewClient(); err != nil {
+			return nil, err
+		}
+	}
+
+	workspaces, err := m.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	graph := workspaceDependencyGraph(workspaces)
+	order, err := topologicalWorkspaces(workspaces, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[int]bool)
+	for _, name := range options.Packages {
+		workspace := findWorkspaceByName(workspaces, name)
+		if workspace == nil {
+			return nil, NewValidationError("packages", name, "not a workspace package")
+		}
+		for i := range workspaces {
+			if &workspaces[i] == workspace {
+				selected[i] = true
+			}
+		}
+	}
+
+	position := make([]int, len(workspaces))
+	pending := make([]int, len(workspaces))
+	dependents := make([][]int, len(workspaces))
+	for rank, index := range order {
+		position[index] = rank
+	}
+	for i, dependencies := range graph {
+		pending[i] = len(dependencies)
+		for _, dependency := range dependencies {
+			dependents[dependency] = append(dependents[dependency], i)
+		}
+	}
+
+	results := make([]*WorkspaceRunResult, len(workspaces))
+	for i := range workspaces {
+		results[i] = &WorkspaceRunResult{Name: workspaces[i].Name, Path: workspaces[i].Path}
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type finished struct {
+		index int
+		err   error
+	}
+	done := make(chan finished)
+	var ready []int
+	running := 0
+	stopped := false
+	cancelled := ctx.Done()
+
+	// complete记录结果并释放依赖它的包，失败时依赖它的包全部标记为blocked
+	var complete func(index int, status WorkspaceRunStatus)
+	complete = func(index int, status WorkspaceRunStatus) {
+		results[index].Status = status
+		for _, dependent := range dependents[index] {
+			if results[dependent].Status != "" {
+				continue
+			}
+			if status == WorkspaceRunFailed || status == WorkspaceRunBlocked || status == WorkspaceRunCancelled {
+				complete(dependent, WorkspaceRunBlocked)
+				continue
+			}
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	for _, index := range order {
+		if pending[index] == 0 {
+			ready = append(ready, index)
+		}
+	}
+
+	for {
+		for !stopped && running < options.Concurrency && len(ready) > 0 {
+			// 按依赖顺序启动，结果稳定
+			next := 0
+			for i := range ready {
+				if position[ready[i]] < position[ready[next]] {
+					next = i
+				}
+			}
+			index := ready[next]
+			ready = append(ready[:next], ready[next+1:]...)
+
+			workspace := workspaces[index]
+			if (len(selected) > 0 && !selected[index]) || workspace.scripts[script] == "" {
+				complete(index, WorkspaceRunSkipped)
+				continue
+			}
+
+			running++
+			go func() {
+				start := time.Now()
+				runOptions := RunScriptOptions{
+					Args:       options.Args,
+					WorkingDir: workspace.Dir,
+					Env:        options.Env,
+					UserConfig: options.UserConfig,
+					Timeout:    options.Timeout,
+				}
+				if options.OnOutput != nil {
+					runOptions.OnOutput = func(line OutputLine) {
+						options.OnOutput(workspace.Name, line)
+					}
+				}
+				err := npmClient.RunScriptWithOptions(runCtx, script, runOptions)
+				results[index].Duration = time.Since(start)
+				done <- finished{index: index, err: err}
+			}()
+		}
+		if running == 0 && (stopped || len(ready) == 0) {
+			break
+		}
+
+		select {
+		case <-cancelled:
+			// 不再启动新的包，继续等待正在运行的脚本退出
+			stopped = true
+			cancelled = nil
+			cancel()
+		case result := <-done:
+			running--
+			status := WorkspaceRunSucceeded
+			if result.err != nil {
+				status = WorkspaceRunFailed
+				if stopped {
+					status = WorkspaceRunCancelled
+				}
+				results[result.index].Err = result.err
+				results[result.index].Error = result.err.Error()
+			}
+			complete(result.index, status)
+			if status == WorkspaceRunFailed && options.FailFast {
+				stopped = true
+				cancel()
+			}
+		}
+	}
+
+	var ordered []WorkspaceRunResult
+	var errs []error
+	for _, index := range order {
+		if len(selected) > 0 && !selected[index] {
+			continue
+		}
+		result := results[index]
+		if result.Status == "" {
+			result.Status = WorkspaceRunCancelled
+		}
+		if result.Status == WorkspaceRunFailed {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Name, result.Err))
+		}
+		ordered = append(ordered, *result)
+	}
+	if len(errs) > 0 {
+		return ordered, fmt.Errorf("script %s failed: %w", script, errors.Join(errs...))
+	}
+	if err := ctx.Err(); err != nil {
+		return ordered, err
+	}
+	return ordered, nil
+}
@@ -0,0 +1,129 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// scriptClient 记录脚本运行顺序的客户端，failing中的目录运行失败
+type scriptClient struct {
+	MockClient
+	mu      sync.Mutex
+	events  []string
+	failing map[string]bool
+}
+
+func (c *scriptClient) RunScriptWithOptions(ctx context.Context, script string, options RunScriptOptions) error {
+	name := filepath.Base(options.WorkingDir)
+	c.mu.Lock()
+	c.events = append(c.events, "start "+name)
+	c.mu.Unlock()
+
+	var err error
+	if c.failing[name] {
+		err = errors.New("exit status 1")
+	}
+	c.mu.Lock()
+	c.events = append(c.events, "end "+name)
+	c.mu.Unlock()
+	return err
+}
+
+func writeScriptMonorepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "package.json"), `{"name":"root","private":true,"workspaces":["packages/*"]}`)
+	writeTestFile(t, filepath.Join(root, "packages", "core", "package.json"), `{"name":"@repo/core","version":"1.0.0","scripts":{"build":"tsc"}}`)
+	writeTestFile(t, filepath.Join(root, "packages", "utils", "package.json"), `{"name":"@repo/utils","version":"1.0.0","dependencies":{"@repo/core":"workspace:*"},"scripts":{"build":"tsc"}}`)
+	writeTestFile(t, filepath.Join(root, "packages", "app", "package.json"), `{"name":"@repo/app","version":"1.0.0","dependencies":{"@repo/utils":"^1.0.0"},"scripts":{"build":"vite build"}}`)
+	writeTestFile(t, filepath.Join(root, "packages", "docs", "package.json"), `{"name":"@repo/docs","version":"1.0.0","scripts":{"build":"vitepress build"}}`)
+	writeTestFile(t, filepath.Join(root, "packages", "types", "package.json"), `{"name":"@repo/types","version":"1.0.0"}`)
+	return root
+}
+
+func workspaceRunStatuses(results []WorkspaceRunResult) map[string]WorkspaceRunStatus {
+	statuses := make(map[string]WorkspaceRunStatus)
+	for _, result := range results {
+		statuses[result.Name] = result.Status
+	}
+	return statuses
+}
+
+func TestWorkspaceManagerRunScriptTopo(t *testing.T) {
+	manager := NewWorkspaceManager(writeScriptMonorepo(t))
+	ctx := context.Background()
+
+	client := &scriptClient{}
+	results, err := manager.RunScriptTopo(ctx, "build", WorkspaceRunOptions{Client: client, Concurrency: 3})
+	if err != nil {
+		t.Fatalf("RunScriptTopo() failed: %v", err)
+	}
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	if expected := []string{"@repo/core", "@repo/docs", "@repo/types", "@repo/utils", "@repo/app"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected results in dependency order %v, got %v", expected, names)
+	}
+	expected := map[string]WorkspaceRunStatus{
+		"@repo/core": WorkspaceRunSucceeded, "@repo/docs": WorkspaceRunSucceeded, "@repo/types": WorkspaceRunSkipped,
+		"@repo/utils": WorkspaceRunSucceeded, "@repo/app": WorkspaceRunSucceeded,
+	}
+	if statuses := workspaceRunStatuses(results); !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+	events := strings.Join(client.events, ",")
+	if !(strings.Index(events, "end core") < strings.Index(events, "start utils") && strings.Index(events, "end utils") < strings.Index(events, "start app")) {
+		t.Errorf("Dependencies did not run first: %s", events)
+	}
+
+	// 依赖失败时依赖它的包不运行，独立的包继续运行
+	client = &scriptClient{failing: map[string]bool{"utils": true}}
+	results, err = manager.RunScriptTopo(ctx, "build", WorkspaceRunOptions{Client: client})
+	if err == nil || !strings.Contains(err.Error(), "@repo/utils") {
+		t.Errorf("Expected error for utils, got %v", err)
+	}
+	statuses := workspaceRunStatuses(results)
+	if statuses["@repo/utils"] != WorkspaceRunFailed || statuses["@repo/app"] != WorkspaceRunBlocked || statuses["@repo/docs"] != WorkspaceRunSucceeded {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+
+	// 快速失败时不再启动新的包
+	client = &scriptClient{failing: map[string]bool{"core": true}}
+	results, err = manager.RunScriptTopo(ctx, "build", WorkspaceRunOptions{Client: client, FailFast: true})
+	if err == nil {
+		t.Error("Expected error")
+	}
+	statuses = workspaceRunStatuses(results)
+	if statuses["@repo/core"] != WorkspaceRunFailed || statuses["@repo/docs"] != WorkspaceRunCancelled || statuses["@repo/utils"] != WorkspaceRunBlocked {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+	if len(client.events) != 2 {
+		t.Errorf("Expected only core to run, got %v", client.events)
+	}
+
+	// 只运行指定的包
+	client = &scriptClient{}
+	results, err = manager.RunScriptTopo(ctx, "build", WorkspaceRunOptions{Client: client, Packages: []string{"@repo/app", "@repo/core"}})
+	if err != nil {
+		t.Fatalf("RunScriptTopo() failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "@repo/core" || results[1].Name != "@repo/app" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+	if strings.Join(client.events, ",") != "start core,end core,start app,end app" {
+		t.Errorf("Unexpected runs: %v", client.events)
+	}
+
+	if _, err := manager.RunScriptTopo(ctx, "build", WorkspaceRunOptions{Client: client, Packages: []string{"missing"}}); err == nil {
+		t.Error("Expected error for unknown package")
+	}
+	if _, err := manager.RunScriptTopo(ctx, "", WorkspaceRunOptions{Client: client}); err == nil {
+		t.Error("Expected error for empty script")
+	}
+}
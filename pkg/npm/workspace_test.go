@@ -0,0 +1,205 @@
+package npm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestMonorepo 创建包含三个工作区包的monorepo
+func writeTestMonorepo(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "package.json"), `{"name":"root","private":true,"workspaces":["packages/*"]}`)
+	writeTestFile(t, filepath.Join(root, "packages", "core", "package.json"), `{"name":"@repo/core","version":"1.2.0"}`)
+	writeTestFile(t, filepath.Join(root, "packages", "utils", "package.json"), `{
+  "name": "@repo/utils",
+  "version": "0.3.1",
+  "dependencies": {
+    "@repo/core": "workspace:^"
+  }
+}
+`)
+	writeTestFile(t, filepath.Join(root, "packages", "app", "package.json"), `{
+  "name": "@repo/app",
+  "version": "2.0.0",
+  "dependencies": {
+    "@repo/core": "workspace:*",
+    "@repo/utils": "file:../utils",
+    "lodash": "^4.17.21"
+  },
+  "devDependencies": {
+    "core-alias": "workspace:../core"
+  },
+  "peerDependencies": {
+    "@repo/core": "workspace:>=1.0.0"
+  }
+}
+`)
+	// 没有package.json的目录会被跳过
+	os.MkdirAll(filepath.Join(root, "packages", "empty"), 0755)
+	return root
+}
+
+func TestWorkspaceManagerWorkspaces(t *testing.T) {
+	manager := NewWorkspaceManager(writeTestMonorepo(t))
+	workspaces, err := manager.Workspaces()
+	if err != nil {
+		t.Fatalf("Workspaces() failed: %v", err)
+	}
+
+	var paths []string
+	for _, workspace := range workspaces {
+		paths = append(paths, workspace.Path+"="+workspace.Name+"@"+workspace.Version)
+	}
+	expected := "packages/app=@repo/app@2.0.0 packages/core=@repo/core@1.2.0 packages/utils=@repo/utils@0.3.1"
+	if strings.Join(paths, " ") != expected {
+		t.Errorf("Workspaces() = %v, expected %s", paths, expected)
+	}
+
+	if _, err := NewWorkspaceManager(t.TempDir()).Workspaces(); err == nil {
+		t.Error("Expected error without workspaces")
+	}
+}
+
+func TestWorkspaceManagerResolveForPublish(t *testing.T) {
+	root := writeTestMonorepo(t)
+	manager := NewWorkspaceManager(root)
+
+	references, err := manager.References()
+	if err != nil {
+		t.Fatalf("References() failed: %v", err)
+	}
+	resolved := make(map[string]string)
+	for _, reference := range references {
+		if reference.Problem != "" {
+			t.Errorf("Unexpected problem: %+v", reference)
+		}
+		resolved[reference.Package+" "+string(reference.Type)+" "+reference.Dependency] = reference.Resolved
+	}
+	expected := map[string]string{
+		"@repo/app dependencies @repo/core":     "1.2.0",
+		"@repo/app dependencies @repo/utils":    "^0.3.1",
+		"@repo/app devDependencies core-alias":  "npm:@repo/core@1.2.0",
+		"@repo/app peerDependencies @repo/core": ">=1.0.0",
+		"@repo/utils dependencies @repo/core":   "^1.2.0",
+	}
+	if len(resolved) != len(expected) {
+		t.Errorf("References() = %v", resolved)
+	}
+	for key, value := range expected {
+		if resolved[key] != value {
+			t.Errorf("%s resolved to %q, expected %q", key, resolved[key], value)
+		}
+	}
+
+	if _, err := manager.ResolveForPublish("@repo/utils"); err != nil {
+		t.Fatalf("ResolveForPublish() failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "packages", "utils", "package.json"))
+	if !strings.Contains(string(data), "\"@repo/core\": \"^1.2.0\"\n") {
+		t.Errorf("Expected utils to be rewritten preserving format, got:\n%s", data)
+	}
+	data, _ = os.ReadFile(filepath.Join(root, "packages", "app", "package.json"))
+	if !strings.Contains(string(data), "workspace:*") {
+		t.Error("Expected app to be left untouched")
+	}
+
+	// 引用无法解析时不修改任何文件
+	writeTestFile(t, filepath.Join(root, "packages", "core", "package.json"), `{"name":"@repo/core","version":"0.9.0"}`)
+	writeTestFile(t, filepath.Join(root, "packages", "extra", "package.json"), `{"name":"@repo/extra","version":"1.0.0","dependencies":{"missing":"workspace:*"}}`)
+	err = manager.Validate()
+	if err == nil || !strings.Contains(err.Error(), "@repo/core@0.9.0 does not satisfy >=1.0.0") || !strings.Contains(err.Error(), "missing (workspace:*): not a workspace package") {
+		t.Errorf("Unexpected Validate() error: %v", err)
+	}
+	if _, err := manager.ResolveForPublish(); err == nil {
+		t.Error("Expected ResolveForPublish() to fail")
+	}
+	data, _ = os.ReadFile(filepath.Join(root, "packages", "app", "package.json"))
+	if !strings.Contains(string(data), "workspace:*") {
+		t.Error("Expected app to be left untouched after failure")
+	}
+}
+
+func TestWorkspaceManagerSyncVersions(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "package.json"), `{"private":true,"workspaces":{"packages":["libs/*"]}}`)
+	writeTestFile(t, filepath.Join(root, "libs", "a", "package.json"), `{"name":"a","version":"2.0.0"}`)
+	writeTestFile(t, filepath.Join(root, "libs", "b", "package.json"), `{"name":"b","version":"1.0.0","dependencies":{"a":"^1.0.0"},"devDependencies":{"c":"workspace:~0.1.0"}}`)
+	writeTestFile(t, filepath.Join(root, "libs", "c", "package.json"), `{"name":"c","version":"0.2.0","dependencies":{"a":"*","b":"1.0.0"},"peerDependencies":{"a":">=1 <3"}}`)
+
+	manager := NewWorkspaceManager(root)
+	updates, err := manager.SyncVersions()
+	if err != nil {
+		t.Fatalf("SyncVersions() failed: %v", err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("Expected 2 updates, got %+v", updates)
+	}
+	if updates[0].Package != "b" || updates[0].Dependency != "a" || updates[0].Resolved != "^2.0.0" {
+		t.Errorf("Unexpected update: %+v", updates[0])
+	}
+	if updates[1].Dependency != "c" || updates[1].Type != Development || updates[1].Resolved != "workspace:~0.2.0" {
+		t.Errorf("Unexpected update: %+v", updates[1])
+	}
+
+	data, _ := os.ReadFile(filepath.Join(root, "libs", "b", "package.json"))
+	if string(data) != `{"name":"b","version":"1.0.0","dependencies":{"a":"^2.0.0"},"devDependencies":{"c":"workspace:~0.2.0"}}` {
+		t.Errorf("Unexpected manifest: %s", data)
+	}
+
+	// 已同步时不再修改
+	if updates, err := manager.SyncVersions(); err != nil || len(updates) != 0 {
+		t.Errorf("Expected no updates, got %+v, %v", updates, err)
+	}
+}
+
+func TestWorkspaceManagerSyncVersionsWithOptions(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "package.json"), `{"private":true,"workspaces":["libs/*"]}`)
+	writeTestFile(t, filepath.Join(root, "libs", "a", "package.json"), `{"name":"a","version":"2.0.0"}`)
+	writeTestFile(t, filepath.Join(root, "libs", "b", "package.json"), `{"name":"b","version":"1.0.0","dependencies":{"a":"workspace:^"},"devDependencies":{"c":"workspace:~0.1.0"}}`)
+	writeTestFile(t, filepath.Join(root, "libs", "c", "package.json"), `{"name":"c","version":"0.2.0","dependencies":{"a":"*","b":"1.0.0"},"peerDependencies":{"a":">=1 <3"}}`)
+	manager := NewWorkspaceManager(root)
+
+	// 只检查时不修改文件
+	updates, err := manager.SyncVersionsWithOptions(WorkspaceSyncOptions{Style: RangeStyleCaret, DryRun: true})
+	if err != nil {
+		t.Fatalf("SyncVersionsWithOptions() failed: %v", err)
+	}
+	var resolved []string
+	for _, update := range updates {
+		resolved = append(resolved, update.Package+">"+update.Dependency+"@"+update.Resolved)
+	}
+	if strings.Join(resolved, " ") != "b>a@^2.0.0 b>c@^0.2.0 c>b@^1.0.0" {
+		t.Errorf("Unexpected updates: %v", resolved)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "libs", "b", "package.json"))
+	if !strings.Contains(string(data), `"a":"workspace:^"`) {
+		t.Errorf("Dry run modified manifest: %s", data)
+	}
+
+	updates, err = manager.SyncVersionsWithOptions(WorkspaceSyncOptions{Style: RangeStyleWorkspace})
+	if err != nil || len(updates) != 3 {
+		t.Fatalf("Expected 3 updates, got %+v, %v", updates, err)
+	}
+	data, _ = os.ReadFile(filepath.Join(root, "libs", "c", "package.json"))
+	if string(data) != `{"name":"c","version":"0.2.0","dependencies":{"a":"*","b":"workspace:*"},"peerDependencies":{"a":">=1 <3"}}` {
+		t.Errorf("Unexpected manifest: %s", data)
+	}
+
+	// 默认写法不改写workspace:*
+	if updates, err := manager.SyncVersions(); err != nil || len(updates) != 0 {
+		t.Errorf("Expected no updates, got %+v, %v", updates, err)
+	}
+	updates, err = manager.SyncVersionsWithOptions(WorkspaceSyncOptions{Style: RangeStyleExact})
+	if err != nil || len(updates) != 3 || updates[0].Resolved != "2.0.0" {
+		t.Errorf("Unexpected exact updates: %+v, %v", updates, err)
+	}
+
+	if _, err := manager.SyncVersionsWithOptions(WorkspaceSyncOptions{Style: "loose"}); err == nil {
+		t.Error("Expected error for unknown style")
+	}
+}
@@ -0,0 +1,205 @@
+package npm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// Yarn yarn命令封装
+//
+// 主要用于Yarn Berry(2+)项目。这类项目默认使用Plug'n'Play，没有node_modules目录，
+// 依赖信息需要通过yarn info等命令获取，而不能通过npm ls或扫描node_modules得到。
+type Yarn struct {
+	yarnPath   string
+	workingDir string
+	executor   utils.CommandExecutor
+}
+
+// YarnOption Yarn选项
+type YarnOption func(*Yarn)
+
+// WithYarnExecutor 使用指定的命令执行器，例如utils.Recorder或utils.Replayer，nil时忽略
+func WithYarnExecutor(executor utils.CommandExecutor) YarnOption {
+	return func(y *Yarn) {
+		if executor != nil {
+			y.executor = executor
+		}
+	}
+}
+
+// YarnPackageInfo yarn info输出的包信息
+type YarnPackageInfo struct {
+	Locator      string   `json:"locator"` // 例如 lodash@npm:4.17.21
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`
+	Instances    int      `json:"instances,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// YarnConstraintsResult yarn constraints检查结果
+type YarnConstraintsResult struct {
+	Passed bool   `json:"passed"`
+	Output string `json:"output"`
+}
+
+// NewYarn 创建yarn命令封装
+func NewYarn(workingDir string, opts ...YarnOption) *Yarn {
+	y := &Yarn{
+		yarnPath:   "yarn",
+		workingDir: workingDir,
+		executor:   utils.NewExecutor(),
+	}
+	for _, opt := range opts {
+		opt(y)
+	}
+	return y
+}
+
+// SetYarnPath 设置yarn可执行文件路径
+func (y *Yarn) SetYarnPath(path string) {
+	y.yarnPath = path
+}
+
+// IsAvailable 检查yarn是否可用
+func (y *Yarn) IsAvailable(ctx context.Context) bool {
+	_, err := y.Version(ctx)
+	return err == nil
+}
+
+// Version 获取yarn版本
+func (y *Yarn) Version(ctx context.Context) (string, error) {
+	result, err := y.run(ctx, 30*time.Second, "--version")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+// Info 获取包信息，pkg为空时返回所有工作区的直接依赖
+func (y *Yarn) Info(ctx context.Context, pkg string) ([]YarnPackageInfo, error) {
+	args := []string{"info", "--json"}
+	if pkg != "" {
+		args = append(args, pkg)
+	}
+
+	result, err := y.run(ctx, 2*time.Minute, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseYarnInfoJSON(result.Stdout)
+}
+
+// Import 根据package-lock.json生成yarn.lock（仅Yarn 1支持）
+func (y *Yarn) Import(ctx context.Context) error {
+	_, err := y.run(ctx, 10*time.Minute, "import")
+	return err
+}
+
+// Constraints 运行yarn constraints检查工作区约束
+func (y *Yarn) Constraints(ctx context.Context) (*YarnConstraintsResult, error) {
+	executeOptions := utils.ExecuteOptions{
+		Command:       y.yarnPath,
+		Args:          []string{"constraints"},
+		WorkingDir:    y.workingDir,
+		CaptureOutput: true,
+		Timeout:       5 * time.Minute,
+	}
+
+	result, err := y.executor.Execute(ctx, executeOptions)
+	if err != nil && (result == nil || result.Cancelled || result.ExitCode == 0) {
+		return nil, fmt.Errorf("yarn constraints failed: %w", err)
+	}
+
+	// 存在约束违规时yarn以非零状态码退出
+	return &YarnConstraintsResult{
+		Passed: result.Success,
+		Output: strings.TrimSpace(result.Stdout + result.Stderr),
+	}, nil
+}
+
+// run 执行yarn命令
+func (y *Yarn) run(ctx context.Context, timeout time.Duration, args ...string) (*utils.ExecuteResult, error) {
+	executeOptions := utils.ExecuteOptions{
+		Command:       y.yarnPath,
+		Args:          args,
+		WorkingDir:    y.workingDir,
+		CaptureOutput: true,
+		Timeout:       timeout,
+	}
+
+	result, err := y.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("yarn %s failed: %w", args[0], err)
+	}
+
+	return result, nil
+}
+
+// parseYarnInfoJSON 解析yarn info --json的输出
+//
+// Yarn Berry每行输出一个JSON对象：
+// {"value":"lodash@npm:4.17.21","children":{"Version":"4.17.21","Dependencies":[...]}}
+func parseYarnInfoJSON(output string) ([]YarnPackageInfo, error) {
+	var packages []YarnPackageInfo
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var entry struct {
+			Value    string `json:"value"`
+			Children struct {
+				Version      string `json:"Version"`
+				Instances    int    `json:"Instances"`
+				Dependencies []struct {
+					Descriptor string `json:"descriptor"`
+				} `json:"Dependencies"`
+			} `json:"children"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse yarn info output: %w", err)
+		}
+
+		info := YarnPackageInfo{
+			Locator:   entry.Value,
+			Name:      yarnLocatorName(entry.Value),
+			Version:   entry.Children.Version,
+			Instances: entry.Children.Instances,
+		}
+		for _, dep := range entry.Children.Dependencies {
+			info.Dependencies = append(info.Dependencies, dep.Descriptor)
+		}
+		packages = append(packages, info)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read yarn info output: %w", err)
+	}
+
+	return packages, nil
+}
+
+// yarnLocatorName 从locator中提取包名，正确处理scope
+func yarnLocatorName(locator string) string {
+	if strings.HasPrefix(locator, "@") {
+		if idx := strings.Index(locator[1:], "@"); idx >= 0 {
+			return locator[:idx+1]
+		}
+		return locator
+	}
+	if idx := strings.Index(locator, "@"); idx >= 0 {
+		return locator[:idx]
+	}
+	return locator
+}
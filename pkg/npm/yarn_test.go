@@ -0,0 +1,130 @@
+package npm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+func TestNewYarn(t *testing.T) {
+	yarn := NewYarn("/tmp/project")
+	if yarn == nil {
+		t.Fatal("NewYarn() returned nil")
+	}
+
+	if yarn.yarnPath != "yarn" {
+		t.Errorf("Expected default yarn path 'yarn', got '%s'", yarn.yarnPath)
+	}
+
+	yarn.SetYarnPath("/opt/yarn/bin/yarn")
+	if yarn.yarnPath != "/opt/yarn/bin/yarn" {
+		t.Errorf("Expected yarn path to be updated, got '%s'", yarn.yarnPath)
+	}
+}
+
+func TestYarnUnavailable(t *testing.T) {
+	yarn := NewYarn(t.TempDir())
+	yarn.SetYarnPath("non-existent-yarn-binary")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if yarn.IsAvailable(ctx) {
+		t.Error("Expected yarn to be unavailable")
+	}
+	if _, err := yarn.Info(ctx, "lodash"); err == nil {
+		t.Error("Expected Info() to fail when yarn is missing")
+	}
+	if _, err := yarn.Constraints(ctx); err == nil {
+		t.Error("Expected Constraints() to fail when yarn is missing")
+	}
+}
+
+func TestYarnWithExecutor(t *testing.T) {
+	ctx := context.Background()
+	replayer := utils.NewReplayer(&utils.Fixture{Interactions: []utils.Interaction{
+		{Command: "yarn", Args: []string{"--version"}, Stdout: "4.1.0\n"},
+		{Command: "yarn", Args: []string{"info", "--json", "lodash"}, Stdout: `{"value":"lodash@npm:4.17.21","children":{"Version":"4.17.21"}}` + "\n"},
+		{Command: "yarn", Args: []string{"constraints"}, Stdout: "✓ No constraint violations"},
+		{Command: "yarn", Args: []string{"constraints"}, Stderr: "✗ lodash must be pinned", ExitCode: 1},
+	}})
+	yarn := NewYarn(t.TempDir(), WithYarnExecutor(replayer))
+
+	if version, err := yarn.Version(ctx); err != nil || version != "4.1.0" {
+		t.Errorf("Version() = %q, %v", version, err)
+	}
+	packages, err := yarn.Info(ctx, "lodash")
+	if err != nil || len(packages) != 1 || packages[0].Name != "lodash" || packages[0].Version != "4.17.21" {
+		t.Errorf("Info() = %+v, %v", packages, err)
+	}
+	if result, err := yarn.Constraints(ctx); err != nil || !result.Passed {
+		t.Errorf("Constraints() = %+v, %v", result, err)
+	}
+	if result, err := yarn.Constraints(ctx); err != nil || result.Passed || result.Output != "✗ lodash must be pinned" {
+		t.Errorf("Constraints() with violations = %+v, %v", result, err)
+	}
+	if remaining := replayer.Remaining(); len(remaining) != 0 {
+		t.Errorf("Expected all interactions to be used, remaining: %+v", remaining)
+	}
+
+	// 命令在工作目录中执行
+	executor := &recordingExecutor{}
+	NewYarn("/tmp/project", WithYarnExecutor(executor)).Import(ctx)
+	if executor.options.WorkingDir != "/tmp/project" || executor.options.Args[0] != "import" {
+		t.Errorf("Unexpected execute options: %+v", executor.options)
+	}
+
+	if NewYarn("/tmp/project", WithYarnExecutor(nil)).executor == nil {
+		t.Error("Expected nil executor to keep the default executor")
+	}
+}
+
+func TestParseYarnInfoJSON(t *testing.T) {
+	output := `{"value":"lodash@npm:4.17.21","children":{"Instances":1,"Version":"4.17.21"}}
+{"value":"@babel/core@npm:7.23.0","children":{"Version":"7.23.0","Dependencies":[{"descriptor":"@babel/types@npm:^7.23.0","locator":"@babel/types@npm:7.23.0"}]}}
+
+➤ YN0000: Done
+`
+
+	packages, err := parseYarnInfoJSON(output)
+	if err != nil {
+		t.Fatalf("parseYarnInfoJSON() failed: %v", err)
+	}
+
+	if len(packages) != 2 {
+		t.Fatalf("Expected 2 packages, got %d", len(packages))
+	}
+
+	if packages[0].Name != "lodash" || packages[0].Version != "4.17.21" || packages[0].Instances != 1 {
+		t.Errorf("Unexpected first package: %+v", packages[0])
+	}
+
+	if packages[1].Name != "@babel/core" {
+		t.Errorf("Expected scoped name '@babel/core', got '%s'", packages[1].Name)
+	}
+	if len(packages[1].Dependencies) != 1 || packages[1].Dependencies[0] != "@babel/types@npm:^7.23.0" {
+		t.Errorf("Unexpected dependencies: %v", packages[1].Dependencies)
+	}
+
+	if _, err := parseYarnInfoJSON("{invalid"); err == nil {
+		t.Error("Expected error for invalid JSON line")
+	}
+}
+
+func TestYarnLocatorName(t *testing.T) {
+	tests := map[string]string{
+		"lodash@npm:4.17.21":     "lodash",
+		"@types/node@npm:20.0.0": "@types/node",
+		"my-app@workspace:.":     "my-app",
+		"@scope/pkg":             "@scope/pkg",
+		"plain":                  "plain",
+	}
+
+	for locator, expected := range tests {
+		if name := yarnLocatorName(locator); name != expected {
+			t.Errorf("yarnLocatorName(%q) = %q, expected %q", locator, name, expected)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package npmiface
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity 漏洞严重程度
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityLow      Severity = "low"
+	SeverityModerate Severity = "moderate"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// severityOrder 严重程度从低到高排列
+var severityOrder = []Severity{SeverityInfo, SeverityLow, SeverityModerate, SeverityHigh, SeverityCritical}
+
+// Rank 严重程度的排序值，未知的严重程度返回-1
+func (s Severity) Rank() int {
+	for i, severity := range severityOrder {
+		if severity == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// AuditOptions 安全审计选项
+type AuditOptions struct {
+	Production bool              `json:"production,omitempty"`  // --omit=dev
+	Registry   string            `json:"registry,omitempty"`    // --registry
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	IgnoreFile string            `json:"ignore_file,omitempty"` // 审计忽略文件，设置后在结果中应用忽略规则
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// Advisory 安全公告
+type Advisory struct {
+	Source    int      `json:"source"`
+	Name      string   `json:"name"`
+	Title     string   `json:"title"`
+	URL       string   `json:"url"`
+	Severity  Severity `json:"severity"`
+	CWE       []string `json:"cwe,omitempty"`
+	CVSSScore float64  `json:"cvss_score,omitempty"`
+	Range     string   `json:"range"` // 受影响的版本范围
+}
+
+// ID 公告标识，优先使用GHSA编号和OSV编号，否则使用npm公告编号
+func (a Advisory) ID() string {
+	if idx := strings.LastIndex(a.URL, "/GHSA-"); idx >= 0 {
+		return a.URL[idx+1:]
+	}
+	if id, found := strings.CutPrefix(a.URL, "https://osv.dev/vulnerability/"); found {
+		return id
+	}
+	return strconv.Itoa(a.Source)
+}
+
+// AuditFix npm给出的修复方式
+type AuditFix struct {
+	Available     bool   `json:"available"`
+	Name          string `json:"name,omitempty"`    // 需要更新的包，可能是受影响包的上层依赖
+	Version       string `json:"version,omitempty"` // 更新到的版本
+	IsSemVerMajor bool   `json:"is_semver_major,omitempty"`
+}
+
+// AuditFinding 单个受影响的包
+type AuditFinding struct {
+	Name       string     `json:"name"`
+	Severity   Severity   `json:"severity"`
+	IsDirect   bool       `json:"is_direct"`
+	Advisories []Advisory `json:"advisories,omitempty"` // 直接影响该包的公告
+	Via        []string   `json:"via,omitempty"`        // 通过这些受影响的依赖间接受影响
+	Effects    []string   `json:"effects,omitempty"`    // 因该包而受影响的上层包
+	Range      string     `json:"range"`
+	Nodes      []string   `json:"nodes,omitempty"`
+	Fix        AuditFix   `json:"fix"`
+}
+
+// AuditReport 安全审计报告
+type AuditReport struct {
+	Findings       []AuditFinding    `json:"findings"`
+	Counts         map[Severity]int  `json:"counts"`
+	Total          int               `json:"total"`
+	Dependencies   int               `json:"dependencies"`
+	Ignored        []IgnoredAdvisory `json:"ignored,omitempty"`         // 被忽略规则排除的公告
+	ExpiredIgnores []AuditIgnore     `json:"expired_ignores,omitempty"` // 已过期、不再生效的忽略规则
+	UnusedIgnores  []AuditIgnore     `json:"unused_ignores,omitempty"`  // 没有匹配任何公告的忽略规则
+}
+
+// Finding 按包名查找受影响的包
+func (r *AuditReport) Finding(name string) *AuditFinding {
+	for i := range r.Findings {
+		if r.Findings[i].Name == name {
+			return &r.Findings[i]
+		}
+	}
+	return nil
+}
+
+// AuditGate 审计门禁规则，返回违反规则的问题和原因，没有违反时返回nil
+type AuditGate func(report *AuditReport) ([]AuditFinding, string)
+
+// GateResult 门禁检查结果
+type GateResult struct {
+	Passed     bool           `json:"passed"`
+	Violations []AuditFinding `json:"violations,omitempty"`
+	Reasons    []string       `json:"reasons,omitempty"`
+	Report     *AuditReport   `json:"report"`
+}
+
+// Err 检查未通过时返回描述原因的错误，通过时返回nil
+func (r *GateResult) Err() error {
+	if r.Passed {
+		return nil
+	}
+
+	names := make([]string, 0, len(r.Violations))
+	for _, finding := range r.Violations {
+		names = append(names, fmt.Sprintf("%s (%s)", finding.Name, finding.Severity))
+	}
+	return fmt.Errorf("audit gate failed: %s: %s", strings.Join(r.Reasons, "; "), strings.Join(names, ", "))
+}
+
+// FailOn 存在指定严重程度及以上的问题时失败
+func FailOn(severity Severity) AuditGate {
+	return func(report *AuditReport) ([]AuditFinding, string) {
+		var violations []AuditFinding
+		for _, finding := range report.Findings {
+			if finding.Severity.Rank() >= severity.Rank() {
+				violations = append(violations, finding)
+			}
+		}
+		if len(violations) == 0 {
+			return nil, ""
+		}
+		return violations, fmt.Sprintf("%d finding(s) at or above %s", len(violations), severity)
+	}
+}
+
+// MaxAllowed 某个严重程度的问题数量超过上限时失败，未列出的严重程度不受限制
+func MaxAllowed(limits map[Severity]int) AuditGate {
+	return func(report *AuditReport) ([]AuditFinding, string) {
+		var violations []AuditFinding
+		var reasons []string
+		for _, severity := range severityOrder {
+			limit, ok := limits[severity]
+			if !ok {
+				continue
+			}
+
+			var matched []AuditFinding
+			for _, finding := range report.Findings {
+				if finding.Severity == severity {
+					matched = append(matched, finding)
+				}
+			}
+			if len(matched) > limit {
+				violations = append(violations, matched...)
+				reasons = append(reasons, fmt.Sprintf("%d %s finding(s), at most %d allowed", len(matched), severity, limit))
+			}
+		}
+		return violations, strings.Join(reasons, ", ")
+	}
+}
+
+// Gate 用一组门禁规则检查审计报告
+func (r *AuditReport) Gate(gates ...AuditGate) *GateResult {
+	result := &GateResult{
+		Passed: true,
+		Report: r,
+	}
+
+	seen := make(map[string]bool)
+	for _, gate := range gates {
+		violations, reason := gate(r)
+		if len(violations) == 0 {
+			continue
+		}
+		result.Passed = false
+		result.Reasons = append(result.Reasons, reason)
+		for _, finding := range violations {
+			if !seen[finding.Name] {
+				seen[finding.Name] = true
+				result.Violations = append(result.Violations, finding)
+			}
+		}
+	}
+
+	return result
+}
+
+// AuditIgnore 单条忽略规则
+type AuditIgnore struct {
+	ID      string `json:"id"`                // GHSA编号或npm公告编号
+	Package string `json:"package,omitempty"` // 只对该包生效，为空时对所有包生效
+	Expires string `json:"expires"`           // 到期日期，格式为2006-01-02或RFC3339
+	Reason  string `json:"reason"`            // 接受该风险的理由
+}
+
+// ExpiresAt 解析到期时间，只有日期时到期时间为当天结束
+func (i AuditIgnore) ExpiresAt() (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", i.Expires); err == nil {
+		return t.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return time.Parse(time.RFC3339, i.Expires)
+}
+
+// matches 规则是否匹配公告
+func (i AuditIgnore) matches(pkg string, advisory Advisory) bool {
+	if i.Package != "" && i.Package != pkg {
+		return false
+	}
+	return strings.EqualFold(i.ID, advisory.ID()) || i.ID == strconv.Itoa(advisory.Source)
+}
+
+// AuditIgnoreList 审计忽略文件内容
+type AuditIgnoreList struct {
+	Ignores []AuditIgnore `json:"ignores"`
+}
+
+// IgnoredAdvisory 被忽略的公告
+type IgnoredAdvisory struct {
+	ID       string    `json:"id"`
+	Package  string    `json:"package"`
+	Severity Severity  `json:"severity"`
+	Reason   string    `json:"reason"`
+	Expires  time.Time `json:"expires"`
+}
+
+// ApplyIgnores 返回应用忽略规则后的新报告
+//
+// 被未过期规则匹配的公告从结果中移除并记录在Ignored中；公告全部被忽略的包，以及只因
+// 这些包而受影响的上层包不再计入统计。已过期的规则不再生效，记录在ExpiredIgnores中，
+// 没有匹配任何公告的规则记录在UnusedIgnores中，便于清理。
+func (r *AuditReport) ApplyIgnores(list *AuditIgnoreList, now time.Time) *AuditReport {
+	result := &AuditReport{
+		Counts:         make(map[Severity]int),
+		Dependencies:   r.Dependencies,
+		Ignored:        append([]IgnoredAdvisory(nil), r.Ignored...),
+		ExpiredIgnores: append([]AuditIgnore(nil), r.ExpiredIgnores...),
+		UnusedIgnores:  append([]AuditIgnore(nil), r.UnusedIgnores...),
+	}
+
+	var active []AuditIgnore
+	for _, ignore := range list.Ignores {
+		expires, err := ignore.ExpiresAt()
+		if err != nil || now.After(expires) {
+			result.ExpiredIgnores = append(result.ExpiredIgnores, ignore)
+			continue
+		}
+		active = append(active, ignore)
+	}
+
+	used := make([]bool, len(active))
+	findings := make([]AuditFinding, 0, len(r.Findings))
+	for _, finding := range r.Findings {
+		var advisories []Advisory
+		for _, advisory := range finding.Advisories {
+			ignored := false
+			for i, ignore := range active {
+				if ignore.matches(finding.Name, advisory) {
+					expires, _ := ignore.ExpiresAt()
+					result.Ignored = append(result.Ignored, IgnoredAdvisory{
+						ID:       advisory.ID(),
+						Package:  finding.Name,
+						Severity: advisory.Severity,
+						Reason:   ignore.Reason,
+						Expires:  expires,
+					})
+					used[i] = true
+					ignored = true
+					break
+				}
+			}
+			if !ignored {
+				advisories = append(advisories, advisory)
+			}
+		}
+		finding.Advisories = advisories
+		findings = append(findings, finding)
+	}
+	for i, ignore := range active {
+		if !used[i] {
+			result.UnusedIgnores = append(result.UnusedIgnores, ignore)
+		}
+	}
+
+	// 仍然受影响的包：有未被忽略的公告，或者依赖了仍然受影响的包
+	affected := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, finding := range findings {
+			if affected[finding.Name] {
+				continue
+			}
+			if len(finding.Advisories) > 0 {
+				affected[finding.Name] = true
+				changed = true
+				continue
+			}
+			for _, via := range finding.Via {
+				if affected[via] {
+					affected[finding.Name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, finding := range findings {
+		if !affected[finding.Name] {
+			continue
+		}
+		finding.Severity = effectiveSeverity(finding, findings, affected)
+		result.Findings = append(result.Findings, finding)
+		result.Counts[finding.Severity]++
+	}
+	result.Total = len(result.Findings)
+
+	return result
+}
+
+// effectiveSeverity 根据剩余公告和受影响的依赖重新计算严重程度
+func effectiveSeverity(finding AuditFinding, findings []AuditFinding, affected map[string]bool) Severity {
+	severity := Severity("")
+	for _, advisory := range finding.Advisories {
+		if advisory.Severity.Rank() > severity.Rank() {
+			severity = advisory.Severity
+		}
+	}
+	for _, via := range finding.Via {
+		if !affected[via] {
+			continue
+		}
+		for _, other := range findings {
+			if other.Name == via && other.Severity.Rank() > severity.Rank() {
+				severity = other.Severity
+			}
+		}
+	}
+	if severity == "" {
+		return finding.Severity
+	}
+	return severity
+}
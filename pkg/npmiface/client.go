@@ -0,0 +1,172 @@
+package npmiface
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Client 定义npm客户端的核心接口
+type Client interface {
+	// 检查npm是否可用
+	IsAvailable(ctx context.Context) bool
+
+	// 安装npm
+	Install(ctx context.Context) error
+
+	// 获取npm版本
+	Version(ctx context.Context) (string, error)
+
+	// 项目初始化
+	Init(ctx context.Context, options InitOptions) error
+
+	// 安装包
+	InstallPackage(ctx context.Context, pkg string, options InstallOptions) error
+
+	// 在一次npm install中安装多个包，pkgs为空时安装所有依赖
+	InstallPackages(ctx context.Context, pkgs []string, options InstallOptions) error
+
+	// 卸载包
+	UninstallPackage(ctx context.Context, pkg string, options UninstallOptions) error
+
+	// 更新包
+	UpdatePackage(ctx context.Context, pkg string) error
+
+	// 按选项更新包
+	UpdatePackageWithOptions(ctx context.Context, pkg string, options UpdateOptions) error
+
+	// 更新所有依赖，返回对比更新前后锁文件得到的版本变化
+	UpdateAll(ctx context.Context, options UpdateOptions) (*UpdateResult, error)
+
+	// 列出已安装的包
+	ListPackages(ctx context.Context, options ListOptions) ([]Package, error)
+
+	// 列出全局安装的包
+	ListGlobal(ctx context.Context) ([]Package, error)
+
+	// 全局安装包
+	InstallGlobal(ctx context.Context, pkg string, options InstallOptions) error
+
+	// 卸载全局安装的包
+	UninstallGlobal(ctx context.Context, pkg string, options UninstallOptions) error
+
+	// 返回全局node_modules目录
+	GlobalRoot(ctx context.Context) (string, error)
+
+	// 列出过期的依赖
+	Outdated(ctx context.Context, options OutdatedOptions) ([]OutdatedPackage, error)
+
+	// 移除多余的包
+	Prune(ctx context.Context, options PruneOptions) error
+
+	// 合并重复的包
+	Dedupe(ctx context.Context, options DedupeOptions) error
+
+	// 生成npm-shrinkwrap.json
+	Shrinkwrap(ctx context.Context, workingDir string) error
+
+	// 按选项生成npm-shrinkwrap.json
+	ShrinkwrapWithOptions(ctx context.Context, options ShrinkwrapOptions) error
+
+	// 列出workingDir中生效的npm配置，包括环境变量和各级.npmrc
+	ConfigList(ctx context.Context, workingDir string) (map[string]string, error)
+
+	// 按选项列出生效的npm配置，Env和UserConfig同样影响结果
+	ConfigListWithOptions(ctx context.Context, options ConfigListOptions) (map[string]string, error)
+
+	// 列出依赖的资助信息
+	Fund(ctx context.Context, options FundOptions) (*FundResult, error)
+
+	// 安全审计
+	Audit(ctx context.Context, options AuditOptions) (*AuditReport, error)
+
+	// 检查npm环境
+	Doctor(ctx context.Context) (*DoctorResult, error)
+
+	// 检查registry连通性，返回延迟
+	Ping(ctx context.Context, registry string) (time.Duration, error)
+
+	// 解释包为什么被安装
+	Explain(ctx context.Context, pkg string) ([]ExplainNode, error)
+
+	// 使用依赖选择器查询已安装的包
+	Query(ctx context.Context, selector string) ([]QueryResult, error)
+
+	// 运行脚本
+	RunScript(ctx context.Context, script string, args ...string) error
+
+	// 按选项运行脚本，可以指定工作目录并实时接收输出
+	RunScriptWithOptions(ctx context.Context, script string, options RunScriptOptions) error
+
+	// 发布包，发布过程中在事件总线上发送PublishEvent
+	Publish(ctx context.Context, options PublishOptions) error
+
+	// 返回客户端的事件总线
+	Events() *EventBus
+
+	// 设置日志记录器，执行的命令、参数、耗时和退出码以debug级别记录
+	SetLogger(logger *slog.Logger)
+
+	// 设置install、view和search遇到网络错误或registry 5xx错误时的重试策略，nil表示不重试
+	SetRetryPolicy(policy *RetryPolicy)
+
+	// 撤销发布
+	Unpublish(ctx context.Context, spec string, options UnpublishOptions) error
+
+	// 打包
+	Pack(ctx context.Context, options PackOptions) (*PackResult, error)
+
+	// 获取包信息
+	GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error)
+
+	// 并发获取多个包的信息，部分失败时返回成功的结果和*PackagesInfoError
+	GetPackagesInfo(ctx context.Context, pkgs []string, concurrency int) (map[string]*PackageInfo, error)
+
+	// 搜索包
+	Search(ctx context.Context, query string) ([]SearchResult, error)
+
+	// 列出包的dist-tag
+	DistTagList(ctx context.Context, pkg string, options DistTagOptions) (map[string]string, error)
+
+	// 将dist-tag指向指定版本
+	DistTagAdd(ctx context.Context, spec, tag string, options DistTagOptions) error
+
+	// 删除dist-tag
+	DistTagRemove(ctx context.Context, pkg, tag string, options DistTagOptions) error
+
+	// 添加包所有者
+	AddOwner(ctx context.Context, user, pkg string, options OwnerOptions) error
+
+	// 移除包所有者
+	RemoveOwner(ctx context.Context, user, pkg string, options OwnerOptions) error
+
+	// 列出包所有者
+	ListOwners(ctx context.Context, pkg string, options OwnerOptions) ([]Owner, error)
+
+	// 获取包访问级别
+	GetAccess(ctx context.Context, pkg string, options AccessOptions) (AccessLevel, error)
+
+	// 设置包访问级别
+	SetAccess(ctx context.Context, pkg string, level AccessLevel, options AccessOptions) error
+
+	// 授予团队权限
+	GrantAccess(ctx context.Context, pkg, team string, permission Permission, options AccessOptions) error
+
+	// 撤销团队权限
+	RevokeAccess(ctx context.Context, pkg, team string, options AccessOptions) error
+
+	// 列出包协作者及其权限
+	ListCollaborators(ctx context.Context, pkg string, options AccessOptions) (map[string]Permission, error)
+
+	// 创建访问令牌
+	TokenCreate(ctx context.Context, options TokenCreateOptions) (*Token, error)
+
+	// 列出访问令牌
+	TokenList(ctx context.Context, options TokenOptions) ([]Token, error)
+
+	// 撤销访问令牌
+	TokenRevoke(ctx context.Context, id string, options TokenOptions) error
+
+	// 返回操作将要运行的npm命令而不执行，op为Client的方法名，args为方法除ctx和选项以外的参数
+	BuildCommand(op string, options interface{}, args ...string) (*Command, error)
+}
@@ -0,0 +1,44 @@
+package npmiface
+
+import (
+	"strings"
+	"time"
+)
+
+// Command 客户端为一次操作构造的npm命令，由BuildCommand返回
+type Command struct {
+	Path       string            `json:"path"`                  // npm可执行文件
+	Args       []string          `json:"args"`                  // 不含可执行文件的参数
+	Env        map[string]string `json:"env,omitempty"`         // 在当前进程的环境变量之上增加的变量
+	WorkingDir string            `json:"working_dir,omitempty"` // 为空时在当前目录运行
+	Input      string            `json:"input,omitempty"`       // 写入标准输入的内容，例如npm token create的密码
+	Timeout    time.Duration     `json:"timeout"`
+}
+
+// Argv 返回以可执行文件开头的完整参数列表
+func (c Command) Argv() []string {
+	return append([]string{c.Path}, c.Args...)
+}
+
+// String 返回可以粘贴到shell中的命令行，包含空格或特殊字符的参数用单引号括起
+func (c Command) String() string {
+	argv := c.Argv()
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote 按POSIX shell规则引用参数
+func shellQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if strings.IndexFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-^~", r))
+	}) < 0 {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,16 @@
+// Package npmiface 定义npm客户端的公共接口及其选项和结果类型
+//
+// 该包只依赖标准库，下游项目可以只依赖接口编写代码和测试，不引入命令执行器、
+// Node.js下载器等实现细节。pkg/npm中的同名类型都是这里的类型别名，npm.NewClient
+// 返回的客户端实现了Client接口，两个包的类型可以混用。
+//
+// 兼容性约定：
+//   - 选项和结果结构体只会增加字段，不会删除或修改已有字段的含义
+//   - Client接口在次版本中可能增加方法，自行实现接口的代码应嵌入npmiface.Client
+//     或mock.Client，避免因新方法编译失败
+//   - 预定义错误保持不变，可以放心用errors.Is判断
+//
+// mock子包由go generate根据Client接口生成，修改接口后需要重新生成。
+package npmiface
+
+//go:generate go run ./internal/mockgen
@@ -0,0 +1,25 @@
+package npmiface
+
+// DoctorCheck npm doctor的单项检查
+type DoctorCheck struct {
+	Title  string `json:"title"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"` // 检查结果或修复建议
+}
+
+// DoctorResult npm doctor检查结果
+type DoctorResult struct {
+	OK     bool          `json:"ok"`
+	Checks []DoctorCheck `json:"checks"`
+}
+
+// Failed 未通过的检查
+func (r *DoctorResult) Failed() []DoctorCheck {
+	var failed []DoctorCheck
+	for _, check := range r.Checks {
+		if !check.OK {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,83 @@
+package npmiface
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 预定义错误
+var (
+	// ErrNpmNotFound npm未找到
+	ErrNpmNotFound = errors.New("npm not found")
+
+	// ErrNpmNotInstalled npm未安装
+	ErrNpmNotInstalled = errors.New("npm is not installed")
+
+	// ErrInvalidPackageName 无效的包名
+	ErrInvalidPackageName = errors.New("invalid package name")
+
+	// ErrPackageNotFound 包未找到
+	ErrPackageNotFound = errors.New("package not found")
+
+	// ErrPackageAlreadyExists 包已存在
+	ErrPackageAlreadyExists = errors.New("package already exists")
+
+	// ErrInvalidVersion 无效版本
+	ErrInvalidVersion = errors.New("invalid version")
+
+	// ErrNetworkError 网络错误
+	ErrNetworkError = errors.New("network error")
+
+	// ErrPermissionDenied 权限被拒绝
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrInvalidWorkingDirectory 无效的工作目录
+	ErrInvalidWorkingDirectory = errors.New("invalid working directory")
+
+	// ErrCommandTimeout 命令超时
+	ErrCommandTimeout = errors.New("command timeout")
+
+	// ErrInvalidPackageJSON 无效的package.json
+	ErrInvalidPackageJSON = errors.New("invalid package.json")
+
+	// ErrRegistryError registry错误
+	ErrRegistryError = errors.New("registry error")
+
+	// ErrAuthenticationFailed 认证失败
+	ErrAuthenticationFailed = errors.New("authentication failed")
+
+	// ErrUnsupportedPlatform 不支持的平台
+	ErrUnsupportedPlatform = errors.New("unsupported platform")
+
+	// ErrPeerDepConflict 依赖树无法解析，通常是peer依赖冲突（ERESOLVE）
+	ErrPeerDepConflict = errors.New("unable to resolve dependency tree")
+
+	// ErrNoMatchingVersion 没有满足版本范围的版本（ETARGET）
+	ErrNoMatchingVersion = errors.New("no matching version")
+
+	// ErrOTPRequired 操作需要一次性密码（EOTP）
+	ErrOTPRequired = errors.New("one-time password required")
+
+	// ErrDiskFull 磁盘空间不足（ENOSPC）
+	ErrDiskFull = errors.New("no space left on device")
+)
+
+// ValidationError 验证错误
+type ValidationError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed for field '%s' with value '%s': %s", e.Field, e.Value, e.Reason)
+}
+
+// NewValidationError 创建验证错误
+func NewValidationError(field, value, reason string) *ValidationError {
+	return &ValidationError{
+		Field:  field,
+		Value:  value,
+		Reason: reason,
+	}
+}
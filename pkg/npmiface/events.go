@@ -0,0 +1,111 @@
+package npmiface
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Event 事件总线上传递的事件，订阅者按具体类型区分
+type Event interface {
+	// 事件类型，例如publish.uploading
+	EventType() string
+}
+
+// EventListener 事件订阅函数
+type EventListener func(event Event)
+
+// EventBus 进程内事件总线
+//
+// Emit同步调用所有订阅者，订阅者应尽快返回，耗时的处理需要自行转到其他goroutine。
+// nil的EventBus可以安全调用Emit。
+type EventBus struct {
+	mu        sync.RWMutex
+	listeners map[int]EventListener
+	next      int
+}
+
+// NewEventBus 创建事件总线
+func NewEventBus() *EventBus {
+	return &EventBus{listeners: make(map[int]EventListener)}
+}
+
+// Subscribe 订阅所有事件，返回取消订阅的函数
+func (b *EventBus) Subscribe(listener EventListener) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.listeners[id] = listener
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.listeners, id)
+	}
+}
+
+// Emit 将事件发送给所有订阅者，按订阅顺序调用
+func (b *EventBus) Emit(event Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	ids := make([]int, 0, len(b.listeners))
+	for id := range b.listeners {
+		ids = append(ids, id)
+	}
+	listeners := make([]EventListener, 0, len(ids))
+	sort.Ints(ids)
+	for _, id := range ids {
+		listeners = append(listeners, b.listeners[id])
+	}
+	b.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// PublishStage 发布阶段
+type PublishStage string
+
+const (
+	PublishStagePacking    PublishStage = "packing"    // 生成tarball
+	PublishStageUploading  PublishStage = "uploading"  // 上传tarball
+	PublishStageProcessing PublishStage = "processing" // 上传完成，等待registry处理
+	PublishStageDone       PublishStage = "done"
+	PublishStageFailed     PublishStage = "failed"
+)
+
+// PublishEvent 发布过程中的阶段事件
+type PublishEvent struct {
+	Stage      PublishStage `json:"stage"`
+	Package    string       `json:"package,omitempty"`
+	Version    string       `json:"version,omitempty"`
+	Tarball    string       `json:"tarball,omitempty"`
+	BytesSent  int64        `json:"bytes_sent"`
+	BytesTotal int64        `json:"bytes_total"` // 打包完成前为0
+	DryRun     bool         `json:"dry_run,omitempty"`
+	Time       time.Time    `json:"time"`
+	Err        error        `json:"-"` // 仅在failed阶段设置
+
+	// Registry 按PublishOptions和package.json的publishConfig确定的registry，为空时由npm配置决定
+	Registry  string                  `json:"registry,omitempty"`
+	Conflicts []PublishConfigConflict `json:"conflicts,omitempty"` // PublishOptions与publishConfig不一致的项
+}
+
+// EventType 实现Event接口
+func (e PublishEvent) EventType() string {
+	return "publish." + string(e.Stage)
+}
+
+// Percent 上传进度百分比，总大小未知时返回0
+func (e PublishEvent) Percent() float64 {
+	if e.BytesTotal <= 0 {
+		return 0
+	}
+	return float64(e.BytesSent) * 100 / float64(e.BytesTotal)
+}
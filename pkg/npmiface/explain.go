@@ -0,0 +1,64 @@
+package npmiface
+
+// ExplainNode npm explain输出中的一个已安装包
+type ExplainNode struct {
+	Name        string             `json:"name,omitempty"`
+	Version     string             `json:"version,omitempty"`
+	Location    string             `json:"location"`
+	IsWorkspace bool               `json:"isWorkspace,omitempty"`
+	Dev         bool               `json:"dev,omitempty"`
+	Optional    bool               `json:"optional,omitempty"`
+	Peer        bool               `json:"peer,omitempty"`
+	Bundled     bool               `json:"bundled,omitempty"`
+	Overridden  bool               `json:"overridden,omitempty"`
+	Dependents  []ExplainDependent `json:"dependents,omitempty"`
+}
+
+// ExplainDependent 依赖某个包的上层包
+type ExplainDependent struct {
+	Type string       `json:"type"` // prod、dev、optional、peer等
+	Name string       `json:"name"`
+	Spec string       `json:"spec"` // 上层包声明的版本范围
+	From *ExplainNode `json:"from,omitempty"`
+}
+
+// IsRoot 是否为项目根目录
+func (n *ExplainNode) IsRoot() bool {
+	return n.Name == "" && len(n.Dependents) == 0
+}
+
+// String 返回name@version格式
+func (n *ExplainNode) String() string {
+	if n.IsRoot() {
+		return n.Location
+	}
+	return n.Name + "@" + n.Version
+}
+
+// Chains 返回从该包到项目根目录的所有依赖链，每条链以该包开始，不包含根目录
+func (n *ExplainNode) Chains() [][]string {
+	var chains [][]string
+	var walk func(node *ExplainNode, chain []string, seen map[string]bool)
+	walk = func(node *ExplainNode, chain []string, seen map[string]bool) {
+		chain = append(chain, node.String())
+		if len(node.Dependents) == 0 {
+			chains = append(chains, append([]string(nil), chain...))
+			return
+		}
+		seen[node.Location] = true
+		for _, dependent := range node.Dependents {
+			switch {
+			case dependent.From == nil || dependent.From.IsRoot():
+				chains = append(chains, append([]string(nil), chain...))
+			case seen[dependent.From.Location]:
+				// 循环依赖，链在此截断
+				chains = append(chains, append(append([]string(nil), chain...), dependent.From.String()))
+			default:
+				walk(dependent.From, chain, seen)
+			}
+		}
+		delete(seen, node.Location)
+	}
+	walk(n, nil, make(map[string]bool))
+	return chains
+}
@@ -0,0 +1,123 @@
+package npmiface
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// FundingSource 一个资助渠道
+type FundingSource struct {
+	Type string `json:"type,omitempty"` // github、opencollective、patreon等
+	URL  string `json:"url"`
+}
+
+// Funding package.json的funding字段
+//
+// 字段可以是URL字符串、{type, url}对象或两者组成的数组，解析后统一为列表；
+// 序列化时只有一个渠道的写回字符串或对象形式。
+type Funding []FundingSource
+
+// UnmarshalJSON 解析字符串、对象或数组形式的funding字段
+func (f *Funding) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		items = []json.RawMessage{data}
+	}
+
+	sources := make(Funding, 0, len(items))
+	for _, item := range items {
+		source, err := parseFundingSource(item)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source)
+	}
+	*f = sources
+	return nil
+}
+
+// MarshalJSON 按npm习惯的最简形式输出
+func (f Funding) MarshalJSON() ([]byte, error) {
+	if len(f) == 1 {
+		if f[0].Type == "" {
+			return json.Marshal(f[0].URL)
+		}
+		return json.Marshal(f[0])
+	}
+	return json.Marshal([]FundingSource(f))
+}
+
+// URLs 返回所有资助链接
+func (f Funding) URLs() []string {
+	urls := make([]string, 0, len(f))
+	for _, source := range f {
+		urls = append(urls, source.URL)
+	}
+	return urls
+}
+
+// parseFundingSource 解析单个资助渠道
+func parseFundingSource(data []byte) (FundingSource, error) {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		return FundingSource{URL: url}, nil
+	}
+
+	var source FundingSource
+	if err := json.Unmarshal(data, &source); err != nil {
+		return source, fmt.Errorf("invalid funding entry: %s", data)
+	}
+	return source, nil
+}
+
+// FundOptions npm fund选项
+type FundOptions struct {
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	Workspaces []string          `json:"workspaces,omitempty"`  // --workspace
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// FundingNode 资助树中的一个包
+type FundingNode struct {
+	Name         string        `json:"name"`
+	Version      string        `json:"version,omitempty"`
+	Funding      Funding       `json:"funding,omitempty"`
+	Dependencies []FundingNode `json:"dependencies,omitempty"` // 按名称排序
+}
+
+// FundResult npm fund的结果，根节点为项目本身
+type FundResult struct {
+	FundingNode
+	Length int `json:"length"` // 有资助信息的包数量
+}
+
+// Packages 按深度优先顺序返回所有有资助信息的包，不含子节点
+func (r *FundResult) Packages() []FundingNode {
+	var packages []FundingNode
+	var walk func(node FundingNode)
+	walk = func(node FundingNode) {
+		if len(node.Funding) > 0 {
+			packages = append(packages, FundingNode{Name: node.Name, Version: node.Version, Funding: node.Funding})
+		}
+		for _, dependency := range node.Dependencies {
+			walk(dependency)
+		}
+	}
+	walk(r.FundingNode)
+	return packages
+}
+
+// ByURL 按资助链接分组，返回链接到name@version列表的映射
+func (r *FundResult) ByURL() map[string][]string {
+	groups := make(map[string][]string)
+	for _, pkg := range r.Packages() {
+		for _, url := range pkg.Funding.URLs() {
+			if id := pkg.Name + "@" + pkg.Version; !slices.Contains(groups[url], id) {
+				groups[url] = append(groups[url], id)
+			}
+		}
+	}
+	return groups
+}
@@ -0,0 +1,207 @@
+// mockgen 根据npmiface.Client生成mock包
+//
+// 在pkg/npmiface目录下运行go generate，读取client.go并写入mock/mock.go。
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	src, err := os.ReadFile("client.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := generate(src)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("mock/mock.go", out, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// method Client接口中的一个方法
+type method struct {
+	name    string
+	params  []string // 参数类型
+	results []string // 返回值类型
+}
+
+// generate 解析client.go中的Client接口，返回mock.go的内容
+func generate(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "client.go", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var iface *ast.InterfaceType
+	ast.Inspect(file, func(node ast.Node) bool {
+		if spec, ok := node.(*ast.TypeSpec); ok && spec.Name.Name == "Client" {
+			iface, _ = spec.Type.(*ast.InterfaceType)
+		}
+		return iface == nil
+	})
+	if iface == nil {
+		return nil, fmt.Errorf("interface Client not found")
+	}
+
+	var methods []method
+	for _, field := range iface.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("embedded interfaces are not supported")
+		}
+		m := method{name: field.Names[0].Name}
+		m.params = fieldTypes(fset, fn.Params)
+		if fn.Results != nil {
+			m.results = fieldTypes(fset, fn.Results)
+		}
+		methods = append(methods, m)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString("type Client struct {\n")
+	for _, m := range methods {
+		fmt.Fprintf(&buf, "\t%sFunc func(%s)%s\n", m.name, strings.Join(m.params, ", "), resultList(m.results))
+	}
+	buf.WriteString("\n\tmu    sync.Mutex\n\tcalls []Call\n}\n\n")
+	buf.WriteString("var _ npmiface.Client = (*Client)(nil)\n")
+	buf.WriteString(helpers)
+
+	for _, m := range methods {
+		names := make([]string, len(m.params))
+		params := make([]string, len(m.params))
+		for i, typ := range m.params {
+			names[i] = fmt.Sprintf("p%d", i)
+			params[i] = names[i] + " " + typ
+		}
+		args := strings.Join(names, ", ")
+		if n := len(m.params); n > 0 && strings.HasPrefix(m.params[n-1], "...") {
+			args += "..."
+		}
+
+		fmt.Fprintf(&buf, "\n// %s 调用%sFunc，未设置时返回零值\n", m.name, m.name)
+		fmt.Fprintf(&buf, "func (m *Client) %s(%s)%s {\n", m.name, strings.Join(params, ", "), resultList(m.results))
+		fmt.Fprintf(&buf, "\tm.record(%q, %s)\n", m.name, strings.Join(names, ", "))
+		fmt.Fprintf(&buf, "\tif m.%sFunc != nil {\n", m.name)
+		if len(m.results) == 0 {
+			fmt.Fprintf(&buf, "\t\tm.%sFunc(%s)\n\t}\n}\n", m.name, args)
+			continue
+		}
+		fmt.Fprintf(&buf, "\t\treturn m.%sFunc(%s)\n\t}\n", m.name, args)
+		zero := make([]string, len(m.results))
+		for i, typ := range m.results {
+			zero[i] = fmt.Sprintf("r%d", i)
+			fmt.Fprintf(&buf, "\tvar r%d %s\n", i, typ)
+		}
+		fmt.Fprintf(&buf, "\treturn %s\n}\n", strings.Join(zero, ", "))
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// fieldTypes 返回参数列表中每个参数的类型，npmiface中的类型加上包名
+func fieldTypes(fset *token.FileSet, list *ast.FieldList) []string {
+	var types []string
+	for _, field := range list.List {
+		qualify(field.Type)
+		var buf bytes.Buffer
+		printer.Fprint(&buf, fset, field.Type)
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, buf.String())
+		}
+	}
+	return types
+}
+
+// qualify 把未限定包名的导出类型改为npmiface.X
+func qualify(expr ast.Expr) {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.SelectorExpr:
+			return false
+		case *ast.Ident:
+			if ast.IsExported(n.Name) {
+				n.Name = "npmiface." + n.Name
+			}
+		}
+		return true
+	})
+}
+
+// resultList 返回方法声明中的返回值部分
+func resultList(results []string) string {
+	switch len(results) {
+	case 0:
+		return ""
+	case 1:
+		return " " + results[0]
+	default:
+		return " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+const header = `// Code generated by go generate in pkg/npmiface; DO NOT EDIT.
+
+// Package mock 提供npmiface.Client的模拟实现，用于下游项目的单元测试
+//
+// 每个方法对应一个XxxFunc字段，设置后调用该函数，未设置时返回零值。所有调用按顺序记录，
+// 可以通过Calls检查。
+package mock
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+)
+
+// Client npmiface.Client的模拟实现，零值可以直接使用
+`
+
+const helpers = `
+// Call 一次方法调用
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Calls 返回按顺序记录的所有调用
+func (m *Client) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// Reset 清空调用记录
+func (m *Client) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+// record 记录一次调用
+func (m *Client) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+`
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGeneratedMockUpToDate(t *testing.T) {
+	src, err := os.ReadFile("../../client.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := generate(src)
+	if err != nil {
+		t.Fatalf("generate() failed: %v", err)
+	}
+	actual, err := os.ReadFile("../../mock/mock.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Error("mock/mock.go is out of date, run go generate in pkg/npmiface")
+	}
+}
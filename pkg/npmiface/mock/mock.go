@@ -0,0 +1,667 @@
+// Code generated by go generate in pkg/npmiface; DO NOT EDIT.
+
+// Package mock 提供npmiface.Client的模拟实现，用于下游项目的单元测试
+//
+// 每个方法对应一个XxxFunc字段，设置后调用该函数，未设置时返回零值。所有调用按顺序记录，
+// 可以通过Calls检查。
+package mock
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+)
+
+// Client npmiface.Client的模拟实现，零值可以直接使用
+type Client struct {
+	IsAvailableFunc              func(context.Context) bool
+	InstallFunc                  func(context.Context) error
+	VersionFunc                  func(context.Context) (string, error)
+	InitFunc                     func(context.Context, npmiface.InitOptions) error
+	InstallPackageFunc           func(context.Context, string, npmiface.InstallOptions) error
+	InstallPackagesFunc          func(context.Context, []string, npmiface.InstallOptions) error
+	UninstallPackageFunc         func(context.Context, string, npmiface.UninstallOptions) error
+	UpdatePackageFunc            func(context.Context, string) error
+	UpdatePackageWithOptionsFunc func(context.Context, string, npmiface.UpdateOptions) error
+	UpdateAllFunc                func(context.Context, npmiface.UpdateOptions) (*npmiface.UpdateResult, error)
+	ListPackagesFunc             func(context.Context, npmiface.ListOptions) ([]npmiface.Package, error)
+	ListGlobalFunc               func(context.Context) ([]npmiface.Package, error)
+	InstallGlobalFunc            func(context.Context, string, npmiface.InstallOptions) error
+	UninstallGlobalFunc          func(context.Context, string, npmiface.UninstallOptions) error
+	GlobalRootFunc               func(context.Context) (string, error)
+	OutdatedFunc                 func(context.Context, npmiface.OutdatedOptions) ([]npmiface.OutdatedPackage, error)
+	PruneFunc                    func(context.Context, npmiface.PruneOptions) error
+	DedupeFunc                   func(context.Context, npmiface.DedupeOptions) error
+	ShrinkwrapFunc               func(context.Context, string) error
+	ShrinkwrapWithOptionsFunc    func(context.Context, npmiface.ShrinkwrapOptions) error
+	ConfigListFunc               func(context.Context, string) (map[string]string, error)
+	ConfigListWithOptionsFunc    func(context.Context, npmiface.ConfigListOptions) (map[string]string, error)
+	FundFunc                     func(context.Context, npmiface.FundOptions) (*npmiface.FundResult, error)
+	AuditFunc                    func(context.Context, npmiface.AuditOptions) (*npmiface.AuditReport, error)
+	DoctorFunc                   func(context.Context) (*npmiface.DoctorResult, error)
+	PingFunc                     func(context.Context, string) (time.Duration, error)
+	ExplainFunc                  func(context.Context, string) ([]npmiface.ExplainNode, error)
+	QueryFunc                    func(context.Context, string) ([]npmiface.QueryResult, error)
+	RunScriptFunc                func(context.Context, string, ...string) error
+	RunScriptWithOptionsFunc     func(context.Context, string, npmiface.RunScriptOptions) error
+	PublishFunc                  func(context.Context, npmiface.PublishOptions) error
+	EventsFunc                   func() *npmiface.EventBus
+	SetLoggerFunc                func(*slog.Logger)
+	SetRetryPolicyFunc           func(*npmiface.RetryPolicy)
+	UnpublishFunc                func(context.Context, string, npmiface.UnpublishOptions) error
+	PackFunc                     func(context.Context, npmiface.PackOptions) (*npmiface.PackResult, error)
+	GetPackageInfoFunc           func(context.Context, string) (*npmiface.PackageInfo, error)
+	GetPackagesInfoFunc          func(context.Context, []string, int) (map[string]*npmiface.PackageInfo, error)
+	SearchFunc                   func(context.Context, string) ([]npmiface.SearchResult, error)
+	DistTagListFunc              func(context.Context, string, npmiface.DistTagOptions) (map[string]string, error)
+	DistTagAddFunc               func(context.Context, string, string, npmiface.DistTagOptions) error
+	DistTagRemoveFunc            func(context.Context, string, string, npmiface.DistTagOptions) error
+	AddOwnerFunc                 func(context.Context, string, string, npmiface.OwnerOptions) error
+	RemoveOwnerFunc              func(context.Context, string, string, npmiface.OwnerOptions) error
+	ListOwnersFunc               func(context.Context, string, npmiface.OwnerOptions) ([]npmiface.Owner, error)
+	GetAccessFunc                func(context.Context, string, npmiface.AccessOptions) (npmiface.AccessLevel, error)
+	SetAccessFunc                func(context.Context, string, npmiface.AccessLevel, npmiface.AccessOptions) error
+	GrantAccessFunc              func(context.Context, string, string, npmiface.Permission, npmiface.AccessOptions) error
+	RevokeAccessFunc             func(context.Context, string, string, npmiface.AccessOptions) error
+	ListCollaboratorsFunc        func(context.Context, string, npmiface.AccessOptions) (map[string]npmiface.Permission, error)
+	TokenCreateFunc              func(context.Context, npmiface.TokenCreateOptions) (*npmiface.Token, error)
+	TokenListFunc                func(context.Context, npmiface.TokenOptions) ([]npmiface.Token, error)
+	TokenRevokeFunc              func(context.Context, string, npmiface.TokenOptions) error
+	BuildCommandFunc             func(string, interface{}, ...string) (*npmiface.Command, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ npmiface.Client = (*Client)(nil)
+
+// Call 一次方法调用
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Calls 返回按顺序记录的所有调用
+func (m *Client) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// Reset 清空调用记录
+func (m *Client) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+// record 记录一次调用
+func (m *Client) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// IsAvailable 调用IsAvailableFunc，未设置时返回零值
+func (m *Client) IsAvailable(p0 context.Context) bool {
+	m.record("IsAvailable", p0)
+	if m.IsAvailableFunc != nil {
+		return m.IsAvailableFunc(p0)
+	}
+	var r0 bool
+	return r0
+}
+
+// Install 调用InstallFunc，未设置时返回零值
+func (m *Client) Install(p0 context.Context) error {
+	m.record("Install", p0)
+	if m.InstallFunc != nil {
+		return m.InstallFunc(p0)
+	}
+	var r0 error
+	return r0
+}
+
+// Version 调用VersionFunc，未设置时返回零值
+func (m *Client) Version(p0 context.Context) (string, error) {
+	m.record("Version", p0)
+	if m.VersionFunc != nil {
+		return m.VersionFunc(p0)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// Init 调用InitFunc，未设置时返回零值
+func (m *Client) Init(p0 context.Context, p1 npmiface.InitOptions) error {
+	m.record("Init", p0, p1)
+	if m.InitFunc != nil {
+		return m.InitFunc(p0, p1)
+	}
+	var r0 error
+	return r0
+}
+
+// InstallPackage 调用InstallPackageFunc，未设置时返回零值
+func (m *Client) InstallPackage(p0 context.Context, p1 string, p2 npmiface.InstallOptions) error {
+	m.record("InstallPackage", p0, p1, p2)
+	if m.InstallPackageFunc != nil {
+		return m.InstallPackageFunc(p0, p1, p2)
+	}
+	var r0 error
+	return r0
+}
+
+// InstallPackages 调用InstallPackagesFunc，未设置时返回零值
+func (m *Client) InstallPackages(p0 context.Context, p1 []string, p2 npmiface.InstallOptions) error {
+	m.record("InstallPackages", p0, p1, p2)
+	if m.InstallPackagesFunc != nil {
+		return m.InstallPackagesFunc(p0, p1, p2)
+	}
+	var r0 error
+	return r0
+}
+
+// UninstallPackage 调用UninstallPackageFunc，未设置时返回零值
+func (m *Client) UninstallPackage(p0 context.Context, p1 string, p2 npmiface.UninstallOptions) error {
+	m.record("UninstallPackage", p0, p1, p2)
+	if m.UninstallPackageFunc != nil {
+		return m.UninstallPackageFunc(p0, p1, p2)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdatePackage 调用UpdatePackageFunc，未设置时返回零值
+func (m *Client) UpdatePackage(p0 context.Context, p1 string) error {
+	m.record("UpdatePackage", p0, p1)
+	if m.UpdatePackageFunc != nil {
+		return m.UpdatePackageFunc(p0, p1)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdatePackageWithOptions 调用UpdatePackageWithOptionsFunc，未设置时返回零值
+func (m *Client) UpdatePackageWithOptions(p0 context.Context, p1 string, p2 npmiface.UpdateOptions) error {
+	m.record("UpdatePackageWithOptions", p0, p1, p2)
+	if m.UpdatePackageWithOptionsFunc != nil {
+		return m.UpdatePackageWithOptionsFunc(p0, p1, p2)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateAll 调用UpdateAllFunc，未设置时返回零值
+func (m *Client) UpdateAll(p0 context.Context, p1 npmiface.UpdateOptions) (*npmiface.UpdateResult, error) {
+	m.record("UpdateAll", p0, p1)
+	if m.UpdateAllFunc != nil {
+		return m.UpdateAllFunc(p0, p1)
+	}
+	var r0 *npmiface.UpdateResult
+	var r1 error
+	return r0, r1
+}
+
+// ListPackages 调用ListPackagesFunc，未设置时返回零值
+func (m *Client) ListPackages(p0 context.Context, p1 npmiface.ListOptions) ([]npmiface.Package, error) {
+	m.record("ListPackages", p0, p1)
+	if m.ListPackagesFunc != nil {
+		return m.ListPackagesFunc(p0, p1)
+	}
+	var r0 []npmiface.Package
+	var r1 error
+	return r0, r1
+}
+
+// ListGlobal 调用ListGlobalFunc，未设置时返回零值
+func (m *Client) ListGlobal(p0 context.Context) ([]npmiface.Package, error) {
+	m.record("ListGlobal", p0)
+	if m.ListGlobalFunc != nil {
+		return m.ListGlobalFunc(p0)
+	}
+	var r0 []npmiface.Package
+	var r1 error
+	return r0, r1
+}
+
+// InstallGlobal 调用InstallGlobalFunc，未设置时返回零值
+func (m *Client) InstallGlobal(p0 context.Context, p1 string, p2 npmiface.InstallOptions) error {
+	m.record("InstallGlobal", p0, p1, p2)
+	if m.InstallGlobalFunc != nil {
+		return m.InstallGlobalFunc(p0, p1, p2)
+	}
+	var r0 error
+	return r0
+}
+
+// UninstallGlobal 调用UninstallGlobalFunc，未设置时返回零值
+func (m *Client) UninstallGlobal(p0 context.Context, p1 string, p2 npmiface.UninstallOptions) error {
+	m.record("UninstallGlobal", p0, p1, p2)
+	if m.UninstallGlobalFunc != nil {
+		return m.UninstallGlobalFunc(p0, p1, p2)
+	}
+	var r0 error
+	return r0
+}
+
+// GlobalRoot 调用GlobalRootFunc，未设置时返回零值
+func (m *Client) GlobalRoot(p0 context.Context) (string, error) {
+	m.record("GlobalRoot", p0)
+	if m.GlobalRootFunc != nil {
+		return m.GlobalRootFunc(p0)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// Outdated 调用OutdatedFunc，未设置时返回零值
+func (m *Client) Outdated(p0 context.Context, p1 npmiface.OutdatedOptions) ([]npmiface.OutdatedPackage, error) {
+	m.record("Outdated", p0, p1)
+	if m.OutdatedFunc != nil {
+		return m.OutdatedFunc(p0, p1)
+	}
+	var r0 []npmiface.OutdatedPackage
+	var r1 error
+	return r0, r1
+}
+
+// Prune 调用PruneFunc，未设置时返回零值
+func (m *Client) Prune(p0 context.Context, p1 npmiface.PruneOptions) error {
+	m.record("Prune", p0, p1)
+	if m.PruneFunc != nil {
+		return m.PruneFunc(p0, p1)
+	}
+	var r0 error
+	return r0
+}
+
+// Dedupe 调用DedupeFunc，未设置时返回零值
+func (m *Client) Dedupe(p0 context.Context, p1 npmiface.DedupeOptions) error {
+	m.record("Dedupe", p0, p1)
+	if m.DedupeFunc != nil {
+		return m.DedupeFunc(p0, p1)
+	}
+	var r0 error
+	return r0
+}
+
+// Shrinkwrap 调用ShrinkwrapFunc，未设置时返回零值
+func (m *Client) Shrinkwrap(p0 context.Context, p1 string) error {
+	m.record("Shrinkwrap", p0, p1)
+	if m.ShrinkwrapFunc != nil {
+		return m.ShrinkwrapFunc(p0, p1)
+	}
+	var r0 error
+	return r0
+}
+
+// ShrinkwrapWithOptions 调用ShrinkwrapWithOptionsFunc，未设置时返回零值
+func (m *Client) ShrinkwrapWithOptions(p0 context.Context, p1 npmiface.ShrinkwrapOptions) error {
+	m.record("ShrinkwrapWithOptions", p0, p1)
+	if m.ShrinkwrapWithOptionsFunc != nil {
+		return m.ShrinkwrapWithOptionsFunc(p0, p1)
+	}
+	var r0 error
+	return r0
+}
+
+// ConfigList 调用ConfigListFunc，未设置时返回零值
+func (m *Client) ConfigList(p0 context.Context, p1 string) (map[string]string, error) {
+	m.record("ConfigList", p0, p1)
+	if m.ConfigListFunc != nil {
+		return m.ConfigListFunc(p0, p1)
+	}
+	var r0 map[string]string
+	var r1 error
+	return r0, r1
+}
+
+// ConfigListWithOptions 调用ConfigListWithOptionsFunc，未设置时返回零值
+func (m *Client) ConfigListWithOptions(p0 context.Context, p1 npmiface.ConfigListOptions) (map[string]string, error) {
+	m.record("ConfigListWithOptions", p0, p1)
+	if m.ConfigListWithOptionsFunc != nil {
+		return m.ConfigListWithOptionsFunc(p0, p1)
+	}
+	var r0 map[string]string
+	var r1 error
+	return r0, r1
+}
+
+// Fund 调用FundFunc，未设置时返回零值
+func (m *Client) Fund(p0 context.Context, p1 npmiface.FundOptions) (*npmiface.FundResult, error) {
+	m.record("Fund", p0, p1)
+	if m.FundFunc != nil {
+		return m.FundFunc(p0, p1)
+	}
+	var r0 *npmiface.FundResult
+	var r1 error
+	return r0, r1
+}
+
+// Audit 调用AuditFunc，未设置时返回零值
+func (m *Client) Audit(p0 context.Context, p1 npmiface.AuditOptions) (*npmiface.AuditReport, error) {
+	m.record("Audit", p0, p1)
+	if m.AuditFunc != nil {
+		return m.AuditFunc(p0, p1)
+	}
+	var r0 *npmiface.AuditReport
+	var r1 error
+	return r0, r1
+}
+
+// Doctor 调用DoctorFunc，未设置时返回零值
+func (m *Client) Doctor(p0 context.Context) (*npmiface.DoctorResult, error) {
+	m.record("Doctor", p0)
+	if m.DoctorFunc != nil {
+		return m.DoctorFunc(p0)
+	}
+	var r0 *npmiface.DoctorResult
+	var r1 error
+	return r0, r1
+}
+
+// Ping 调用PingFunc，未设置时返回零值
+func (m *Client) Ping(p0 context.Context, p1 string) (time.Duration, error) {
+	m.record("Ping", p0, p1)
+	if m.PingFunc != nil {
+		return m.PingFunc(p0, p1)
+	}
+	var r0 time.Duration
+	var r1 error
+	return r0, r1
+}
+
+// Explain 调用ExplainFunc，未设置时返回零值
+func (m *Client) Explain(p0 context.Context, p1 string) ([]npmiface.ExplainNode, error) {
+	m.record("Explain", p0, p1)
+	if m.ExplainFunc != nil {
+		return m.ExplainFunc(p0, p1)
+	}
+	var r0 []npmiface.ExplainNode
+	var r1 error
+	return r0, r1
+}
+
+// Query 调用QueryFunc，未设置时返回零值
+func (m *Client) Query(p0 context.Context, p1 string) ([]npmiface.QueryResult, error) {
+	m.record("Query", p0, p1)
+	if m.QueryFunc != nil {
+		return m.QueryFunc(p0, p1)
+	}
+	var r0 []npmiface.QueryResult
+	var r1 error
+	return r0, r1
+}
+
+// RunScript 调用RunScriptFunc，未设置时返回零值
+func (m *Client) RunScript(p0 context.Context, p1 string, p2 ...string) error {
+	m.record("RunScript", p0, p1, p2)
+	if m.RunScriptFunc != nil {
+		return m.RunScriptFunc(p0, p1, p2...)
+	}
+	var r0 error
+	return r0
+}
+
+// RunScriptWithOptions 调用RunScriptWithOptionsFunc，未设置时返回零值
+func (m *Client) RunScriptWithOptions(p0 context.Context, p1 string, p2 npmiface.RunScriptOptions) error {
+	m.record("RunScriptWithOptions", p0, p1, p2)
+	if m.RunScriptWithOptionsFunc != nil {
+		return m.RunScriptWithOptionsFunc(p0, p1, p2)
+	}
+	var r0 error
+	return r0
+}
+
+// Publish 调用PublishFunc，未设置时返回零值
+func (m *Client) Publish(p0 context.Context, p1 npmiface.PublishOptions) error {
+	m.record("Publish", p0, p1)
+	if m.PublishFunc != nil {
+		return m.PublishFunc(p0, p1)
+	}
+	var r0 error
+	return r0
+}
+
+// Events 调用EventsFunc，未设置时返回零值
+func (m *Client) Events() *npmiface.EventBus {
+	m.record("Events")
+	if m.EventsFunc != nil {
+		return m.EventsFunc()
+	}
+	var r0 *npmiface.EventBus
+	return r0
+}
+
+// SetLogger 调用SetLoggerFunc，未设置时返回零值
+func (m *Client) SetLogger(p0 *slog.Logger) {
+	m.record("SetLogger", p0)
+	if m.SetLoggerFunc != nil {
+		m.SetLoggerFunc(p0)
+	}
+}
+
+// SetRetryPolicy 调用SetRetryPolicyFunc，未设置时返回零值
+func (m *Client) SetRetryPolicy(p0 *npmiface.RetryPolicy) {
+	m.record("SetRetryPolicy", p0)
+	if m.SetRetryPolicyFunc != nil {
+		m.SetRetryPolicyFunc(p0)
+	}
+}
+
+// Unpublish 调用UnpublishFunc，未设置时返回零值
+func (m *Client) Unpublish(p0 context.Context, p1 string, p2 npmiface.UnpublishOptions) error {
+	m.record("Unpublish", p0, p1, p2)
+	if m.UnpublishFunc != nil {
+		return m.UnpublishFunc(p0, p1, p2)
+	}
+	var r0 error
+	return r0
+}
+
+// Pack 调用PackFunc，未设置时返回零值
+func (m *Client) Pack(p0 context.Context, p1 npmiface.PackOptions) (*npmiface.PackResult, error) {
+	m.record("Pack", p0, p1)
+	if m.PackFunc != nil {
+		return m.PackFunc(p0, p1)
+	}
+	var r0 *npmiface.PackResult
+	var r1 error
+	return r0, r1
+}
+
+// GetPackageInfo 调用GetPackageInfoFunc，未设置时返回零值
+func (m *Client) GetPackageInfo(p0 context.Context, p1 string) (*npmiface.PackageInfo, error) {
+	m.record("GetPackageInfo", p0, p1)
+	if m.GetPackageInfoFunc != nil {
+		return m.GetPackageInfoFunc(p0, p1)
+	}
+	var r0 *npmiface.PackageInfo
+	var r1 error
+	return r0, r1
+}
+
+// GetPackagesInfo 调用GetPackagesInfoFunc，未设置时返回零值
+func (m *Client) GetPackagesInfo(p0 context.Context, p1 []string, p2 int) (map[string]*npmiface.PackageInfo, error) {
+	m.record("GetPackagesInfo", p0, p1, p2)
+	if m.GetPackagesInfoFunc != nil {
+		return m.GetPackagesInfoFunc(p0, p1, p2)
+	}
+	var r0 map[string]*npmiface.PackageInfo
+	var r1 error
+	return r0, r1
+}
+
+// Search 调用SearchFunc，未设置时返回零值
+func (m *Client) Search(p0 context.Context, p1 string) ([]npmiface.SearchResult, error) {
+	m.record("Search", p0, p1)
+	if m.SearchFunc != nil {
+		return m.SearchFunc(p0, p1)
+	}
+	var r0 []npmiface.SearchResult
+	var r1 error
+	return r0, r1
+}
+
+// DistTagList 调用DistTagListFunc，未设置时返回零值
+func (m *Client) DistTagList(p0 context.Context, p1 string, p2 npmiface.DistTagOptions) (map[string]string, error) {
+	m.record("DistTagList", p0, p1, p2)
+	if m.DistTagListFunc != nil {
+		return m.DistTagListFunc(p0, p1, p2)
+	}
+	var r0 map[string]string
+	var r1 error
+	return r0, r1
+}
+
+// DistTagAdd 调用DistTagAddFunc，未设置时返回零值
+func (m *Client) DistTagAdd(p0 context.Context, p1 string, p2 string, p3 npmiface.DistTagOptions) error {
+	m.record("DistTagAdd", p0, p1, p2, p3)
+	if m.DistTagAddFunc != nil {
+		return m.DistTagAddFunc(p0, p1, p2, p3)
+	}
+	var r0 error
+	return r0
+}
+
+// DistTagRemove 调用DistTagRemoveFunc，未设置时返回零值
+func (m *Client) DistTagRemove(p0 context.Context, p1 string, p2 string, p3 npmiface.DistTagOptions) error {
+	m.record("DistTagRemove", p0, p1, p2, p3)
+	if m.DistTagRemoveFunc != nil {
+		return m.DistTagRemoveFunc(p0, p1, p2, p3)
+	}
+	var r0 error
+	return r0
+}
+
+// AddOwner 调用AddOwnerFunc，未设置时返回零值
+func (m *Client) AddOwner(p0 context.Context, p1 string, p2 string, p3 npmiface.OwnerOptions) error {
+	m.record("AddOwner", p0, p1, p2, p3)
+	if m.AddOwnerFunc != nil {
+		return m.AddOwnerFunc(p0, p1, p2, p3)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveOwner 调用RemoveOwnerFunc，未设置时返回零值
+func (m *Client) RemoveOwner(p0 context.Context, p1 string, p2 string, p3 npmiface.OwnerOptions) error {
+	m.record("RemoveOwner", p0, p1, p2, p3)
+	if m.RemoveOwnerFunc != nil {
+		return m.RemoveOwnerFunc(p0, p1, p2, p3)
+	}
+	var r0 error
+	return r0
+}
+
+// ListOwners 调用ListOwnersFunc，未设置时返回零值
+func (m *Client) ListOwners(p0 context.Context, p1 string, p2 npmiface.OwnerOptions) ([]npmiface.Owner, error) {
+	m.record("ListOwners", p0, p1, p2)
+	if m.ListOwnersFunc != nil {
+		return m.ListOwnersFunc(p0, p1, p2)
+	}
+	var r0 []npmiface.Owner
+	var r1 error
+	return r0, r1
+}
+
+// GetAccess 调用GetAccessFunc，未设置时返回零值
+func (m *Client) GetAccess(p0 context.Context, p1 string, p2 npmiface.AccessOptions) (npmiface.AccessLevel, error) {
+	m.record("GetAccess", p0, p1, p2)
+	if m.GetAccessFunc != nil {
+		return m.GetAccessFunc(p0, p1, p2)
+	}
+	var r0 npmiface.AccessLevel
+	var r1 error
+	return r0, r1
+}
+
+// SetAccess 调用SetAccessFunc，未设置时返回零值
+func (m *Client) SetAccess(p0 context.Context, p1 string, p2 npmiface.AccessLevel, p3 npmiface.AccessOptions) error {
+	m.record("SetAccess", p0, p1, p2, p3)
+	if m.SetAccessFunc != nil {
+		return m.SetAccessFunc(p0, p1, p2, p3)
+	}
+	var r0 error
+	return r0
+}
+
+// GrantAccess 调用GrantAccessFunc，未设置时返回零值
+func (m *Client) GrantAccess(p0 context.Context, p1 string, p2 string, p3 npmiface.Permission, p4 npmiface.AccessOptions) error {
+	m.record("GrantAccess", p0, p1, p2, p3, p4)
+	if m.GrantAccessFunc != nil {
+		return m.GrantAccessFunc(p0, p1, p2, p3, p4)
+	}
+	var r0 error
+	return r0
+}
+
+// RevokeAccess 调用RevokeAccessFunc，未设置时返回零值
+func (m *Client) RevokeAccess(p0 context.Context, p1 string, p2 string, p3 npmiface.AccessOptions) error {
+	m.record("RevokeAccess", p0, p1, p2, p3)
+	if m.RevokeAccessFunc != nil {
+		return m.RevokeAccessFunc(p0, p1, p2, p3)
+	}
+	var r0 error
+	return r0
+}
+
+// ListCollaborators 调用ListCollaboratorsFunc，未设置时返回零值
+func (m *Client) ListCollaborators(p0 context.Context, p1 string, p2 npmiface.AccessOptions) (map[string]npmiface.Permission, error) {
+	m.record("ListCollaborators", p0, p1, p2)
+	if m.ListCollaboratorsFunc != nil {
+		return m.ListCollaboratorsFunc(p0, p1, p2)
+	}
+	var r0 map[string]npmiface.Permission
+	var r1 error
+	return r0, r1
+}
+
+// TokenCreate 调用TokenCreateFunc，未设置时返回零值
+func (m *Client) TokenCreate(p0 context.Context, p1 npmiface.TokenCreateOptions) (*npmiface.Token, error) {
+	m.record("TokenCreate", p0, p1)
+	if m.TokenCreateFunc != nil {
+		return m.TokenCreateFunc(p0, p1)
+	}
+	var r0 *npmiface.Token
+	var r1 error
+	return r0, r1
+}
+
+// TokenList 调用TokenListFunc，未设置时返回零值
+func (m *Client) TokenList(p0 context.Context, p1 npmiface.TokenOptions) ([]npmiface.Token, error) {
+	m.record("TokenList", p0, p1)
+	if m.TokenListFunc != nil {
+		return m.TokenListFunc(p0, p1)
+	}
+	var r0 []npmiface.Token
+	var r1 error
+	return r0, r1
+}
+
+// TokenRevoke 调用TokenRevokeFunc，未设置时返回零值
+func (m *Client) TokenRevoke(p0 context.Context, p1 string, p2 npmiface.TokenOptions) error {
+	m.record("TokenRevoke", p0, p1, p2)
+	if m.TokenRevokeFunc != nil {
+		return m.TokenRevokeFunc(p0, p1, p2)
+	}
+	var r0 error
+	return r0
+}
+
+// BuildCommand 调用BuildCommandFunc，未设置时返回零值
+func (m *Client) BuildCommand(p0 string, p1 interface{}, p2 ...string) (*npmiface.Command, error) {
+	m.record("BuildCommand", p0, p1, p2)
+	if m.BuildCommandFunc != nil {
+		return m.BuildCommandFunc(p0, p1, p2...)
+	}
+	var r0 *npmiface.Command
+	var r1 error
+	return r0, r1
+}
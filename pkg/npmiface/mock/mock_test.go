@@ -0,0 +1,50 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npmiface"
+)
+
+func TestClient(t *testing.T) {
+	var client npmiface.Client = &Client{
+		GetPackageInfoFunc: func(ctx context.Context, pkg string) (*npmiface.PackageInfo, error) {
+			if pkg == "missing" {
+				return nil, npmiface.ErrPackageNotFound
+			}
+			return &npmiface.PackageInfo{Name: pkg, Version: "1.0.0"}, nil
+		},
+	}
+	ctx := context.Background()
+
+	info, err := client.GetPackageInfo(ctx, "lodash")
+	if err != nil || info.Name != "lodash" {
+		t.Errorf("GetPackageInfo() = %+v, %v", info, err)
+	}
+	if _, err := client.GetPackageInfo(ctx, "missing"); !errors.Is(err, npmiface.ErrPackageNotFound) {
+		t.Errorf("Expected package not found error, got %v", err)
+	}
+
+	// 未设置的方法返回零值
+	if client.IsAvailable(ctx) {
+		t.Error("Expected IsAvailable to return false")
+	}
+	if err := client.RunScript(ctx, "test", "--watch"); err != nil {
+		t.Errorf("RunScript() failed: %v", err)
+	}
+
+	mock := client.(*Client)
+	calls := mock.Calls()
+	if len(calls) != 4 || calls[0].Method != "GetPackageInfo" || calls[3].Method != "RunScript" {
+		t.Fatalf("Unexpected calls: %+v", calls)
+	}
+	if args, ok := calls[3].Args[2].([]string); !ok || len(args) != 1 || args[0] != "--watch" {
+		t.Errorf("Unexpected RunScript args: %+v", calls[3].Args)
+	}
+	mock.Reset()
+	if len(mock.Calls()) != 0 {
+		t.Error("Expected Reset to clear calls")
+	}
+}
@@ -0,0 +1,148 @@
+package npmiface
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// ParsePerson 解析npm的人员字符串，格式为"Name <email> (url)"，email和url都可以省略
+func ParsePerson(value string) Person {
+	var person Person
+	rest := value
+	if start := strings.Index(rest, "<"); start >= 0 {
+		if end := strings.Index(rest[start:], ">"); end >= 0 {
+			person.Email = strings.TrimSpace(rest[start+1 : start+end])
+			rest = rest[:start] + rest[start+end+1:]
+		}
+	}
+	if start := strings.Index(rest, "("); start >= 0 {
+		if end := strings.Index(rest[start:], ")"); end >= 0 {
+			person.URL = strings.TrimSpace(rest[start+1 : start+end])
+			rest = rest[:start] + rest[start+end+1:]
+		}
+	}
+	person.Name = strings.Join(strings.Fields(rest), " ")
+	return person
+}
+
+// String 按npm的人员字符串格式输出，例如"Barney Rubble <b@rubble.com> (http://barnyrubble.tumblr.com/)"
+func (p Person) String() string {
+	parts := make([]string, 0, 3)
+	if p.Name != "" {
+		parts = append(parts, p.Name)
+	}
+	if p.Email != "" {
+		parts = append(parts, "<"+p.Email+">")
+	}
+	if p.URL != "" {
+		parts = append(parts, "("+p.URL+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// UnmarshalJSON 解析对象或"Name <email> (url)"字符串形式的人员信息
+func (p *Person) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*p = ParsePerson(value)
+		return nil
+	}
+	type person Person
+	return json.Unmarshal(data, (*person)(p))
+}
+
+// PackagePerson package.json中author、contributors和maintainers的一项
+//
+// 字段可以是"Name <email> (url)"字符串或{name, email, url}对象，解析后统一为Person；
+// 序列化时保持解析时的形式，Object为false时写成字符串。
+type PackagePerson struct {
+	Person
+	Object bool `json:"-"` // 序列化为对象形式
+}
+
+// UnmarshalJSON 解析字符串或对象形式的人员信息，并记录原来的形式
+func (p *PackagePerson) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	p.Object = len(data) > 0 && data[0] == '{'
+	return p.Person.UnmarshalJSON(data)
+}
+
+// MarshalJSON 按Object选择对象或字符串形式输出
+func (p PackagePerson) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	// 字符串形式包含"<"和">"，不转义为\u003c
+	encoder.SetEscapeHTML(false)
+	var err error
+	if p.Object {
+		err = encoder.Encode(p.Person)
+	} else {
+		err = encoder.Encode(p.Person.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// AuthorPerson 解析Author，没有作者时返回nil
+func (p *Package) AuthorPerson() *Person {
+	if p.Author == "" {
+		return nil
+	}
+	person := ParsePerson(p.Author)
+	return &person
+}
+
+// packageFields Package的字段，不带JSON方法
+type packageFields Package
+
+// UnmarshalJSON 解析package.json，author可以是字符串或{name, email, url}对象
+//
+// 对象形式的作者转换为"Name <email> (url)"格式保存在Author中，并设置AuthorObject。
+func (p *Package) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		*packageFields
+		Author *PackagePerson `json:"author"`
+	}{packageFields: (*packageFields)(p)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Author != nil {
+		p.Author = raw.Author.String()
+		p.AuthorObject = raw.Author.Object
+	}
+	return nil
+}
+
+// MarshalJSON AuthorObject为true时把作者写成对象，否则原样写出Author字符串
+func (p Package) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		packageFields
+		Author any `json:"author,omitempty"`
+	}{packageFields: packageFields(p)}
+	switch {
+	case p.Author == "":
+	case p.AuthorObject:
+		raw.Author = ParsePerson(p.Author)
+	default:
+		raw.Author = p.Author
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	// 调用方可以关闭HTML转义，这里不提前转义
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(raw); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
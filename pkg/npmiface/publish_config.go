@@ -0,0 +1,78 @@
+package npmiface
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// PublishConfig package.json的publishConfig字段，发布时优先于.npmrc中的配置
+//
+// 除registry、access和tag外，私有scope包常用"@scope:registry"指定registry，
+// 解析后放在ScopeRegistries中。其他配置项不影响SDK的行为，解析时忽略。
+type PublishConfig struct {
+	Registry        string            `json:"registry,omitempty"`
+	Access          string            `json:"access,omitempty"` // public或restricted
+	Tag             string            `json:"tag,omitempty"`
+	ScopeRegistries map[string]string `json:"-"` // 键为带@的scope，例如@myorg
+}
+
+// UnmarshalJSON 解析publishConfig，收集@scope:registry形式的键
+func (c *PublishConfig) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	type plain PublishConfig
+	var config plain
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	for key, value := range raw {
+		scope, ok := strings.CutSuffix(key, ":registry")
+		if !ok || !strings.HasPrefix(scope, "@") {
+			continue
+		}
+		var registry string
+		if err := json.Unmarshal(value, &registry); err != nil {
+			return err
+		}
+		if config.ScopeRegistries == nil {
+			config.ScopeRegistries = make(map[string]string)
+		}
+		config.ScopeRegistries[scope] = registry
+	}
+	*c = PublishConfig(config)
+	return nil
+}
+
+// MarshalJSON 把ScopeRegistries写回@scope:registry形式的键
+func (c PublishConfig) MarshalJSON() ([]byte, error) {
+	out := make(map[string]string, len(c.ScopeRegistries)+3)
+	for scope, registry := range c.ScopeRegistries {
+		out[scope+":registry"] = registry
+	}
+	for key, value := range map[string]string{"registry": c.Registry, "access": c.Access, "tag": c.Tag} {
+		if value != "" {
+			out[key] = value
+		}
+	}
+	return json.Marshal(out)
+}
+
+// RegistryFor 返回发布包name时publishConfig指定的registry，包所在scope的配置优先
+func (c *PublishConfig) RegistryFor(name string) string {
+	if scope, _, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		if registry := c.ScopeRegistries[scope]; registry != "" {
+			return registry
+		}
+	}
+	return c.Registry
+}
+
+// PublishConfigConflict PublishOptions和publishConfig为同一项设置了不同的值，以PublishOptions为准
+type PublishConfigConflict struct {
+	Field         string `json:"field"` // registry、access或tag
+	Option        string `json:"option"`
+	PublishConfig string `json:"publish_config"`
+}
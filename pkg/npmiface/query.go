@@ -0,0 +1,38 @@
+package npmiface
+
+import "encoding/json"
+
+// QueryResult npm query匹配到的包
+//
+// 常用字段已解析为结构体字段，完整的package.json内容及npm附加的字段保存在Raw中。
+type QueryResult struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	ID                   string            `json:"_id,omitempty"`
+	PkgID                string            `json:"pkgid,omitempty"`
+	Location             string            `json:"location"` // 相对于项目根目录的路径，根目录为空
+	Path                 string            `json:"path"`
+	Realpath             string            `json:"realpath,omitempty"`
+	Resolved             string            `json:"resolved,omitempty"`
+	Integrity            string            `json:"integrity,omitempty"`
+	License              string            `json:"-"` // 旧版对象形式的license取其type
+	Scripts              map[string]string `json:"scripts,omitempty"`
+	Dependencies         map[string]string `json:"dependencies,omitempty"`
+	DevDependencies      map[string]string `json:"devDependencies,omitempty"`
+	PeerDependencies     map[string]string `json:"peerDependencies,omitempty"`
+	OptionalDependencies map[string]string `json:"optionalDependencies,omitempty"`
+	From                 []string          `json:"from,omitempty"` // 依赖该包的上层包位置
+	To                   []string          `json:"to,omitempty"`   // 该包依赖的包位置
+	Dev                  bool              `json:"dev,omitempty"`
+	Optional             bool              `json:"optional,omitempty"`
+	Peer                 bool              `json:"peer,omitempty"`
+	InBundle             bool              `json:"inBundle,omitempty"`
+	Deduped              bool              `json:"deduped,omitempty"`
+	Overridden           bool              `json:"overridden,omitempty"`
+	Raw                  json.RawMessage   `json:"-"`
+}
+
+// IsRoot 是否为项目根目录
+func (r *QueryResult) IsRoot() bool {
+	return r.Location == ""
+}
@@ -0,0 +1,74 @@
+package npmiface
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RetryPolicy 访问registry的操作失败时的重试策略
+//
+// 只有错误链中包含Retryable里的错误时才会重试，默认为网络错误和registry的5xx错误，
+// 包未找到、认证失败、依赖冲突等错误重试也不会成功，直接返回。
+type RetryPolicy struct {
+	MaxAttempts    int           // 最多尝试次数，包括第一次，小于等于1时不重试
+	InitialBackoff time.Duration // 第一次重试前的等待时间
+	MaxBackoff     time.Duration // 等待时间上限，0表示不限制
+	Multiplier     float64       // 每次重试后等待时间的倍数，小于1时按1处理
+	Retryable      []error       // 可重试的错误，按errors.Is匹配，为空时使用DefaultRetryableErrors
+}
+
+// DefaultRetryableErrors 默认可重试的错误
+var DefaultRetryableErrors = []error{ErrNetworkError, ErrRegistryError}
+
+// DefaultRetryPolicy 返回默认的重试策略：最多3次，等待1s、2s
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// ShouldRetry 检查错误是否属于可重试的错误
+func (p *RetryPolicy) ShouldRetry(err error) bool {
+	if err == nil {
+		return false
+	}
+	// 调用方取消的操作不重试
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	retryable := p.Retryable
+	if len(retryable) == 0 {
+		retryable = DefaultRetryableErrors
+	}
+	for _, target := range retryable {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff 返回第attempt次尝试失败后的等待时间，attempt从1开始
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		backoff *= multiplier
+		if p.MaxBackoff > 0 && backoff >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(backoff)
+}
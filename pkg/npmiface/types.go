@@ -0,0 +1,428 @@
+package npmiface
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// InitOptions 项目初始化选项
+type InitOptions struct {
+	Name        string            `json:"name,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Author      string            `json:"author,omitempty"`
+	License     string            `json:"license,omitempty"`
+	Private     bool              `json:"private,omitempty"`
+	WorkingDir  string            `json:"-"` // 工作目录，不序列化到package.json
+	Force       bool              `json:"-"` // 强制覆盖，不序列化到package.json
+	Env         map[string]string `json:"-"` // 额外的环境变量
+	UserConfig  string            `json:"-"` // 替代的.npmrc路径
+
+	// 初始化器，例如react-app、@scope/foo或vite@latest，设置时运行npm init <initializer>，
+	// 即npm exec create-<initializer>，由初始化器生成项目，不能同时设置上面的包字段
+	Initializer string           `json:"-"`
+	Args        []string         `json:"-"` // 传给初始化器的参数，例如项目目录和--template react
+	OnOutput    func(OutputLine) `json:"-"` // 逐行接收命令的输出，用于显示初始化器的实时日志
+}
+
+// InstallOptions 安装选项
+type InstallOptions struct {
+	SaveDev       bool   `json:"save_dev,omitempty"`       // --save-dev
+	SaveOptional  bool   `json:"save_optional,omitempty"`  // --save-optional
+	SaveExact     bool   `json:"save_exact,omitempty"`     // --save-exact
+	Global        bool   `json:"global,omitempty"`         // --global
+	Production    bool   `json:"production,omitempty"`     // --production
+	WorkingDir    string `json:"working_dir,omitempty"`    // 工作目录
+	Registry      string `json:"registry,omitempty"`       // 自定义registry
+	Force         bool   `json:"force,omitempty"`          // --force
+	IgnoreScripts bool   `json:"ignore_scripts,omitempty"` // --ignore-scripts
+
+	LegacyPeerDeps  bool              `json:"legacy_peer_deps,omitempty"`  // --legacy-peer-deps，不安装peer依赖，忽略冲突
+	StrictPeerDeps  bool              `json:"strict_peer_deps,omitempty"`  // --strict-peer-deps，peer依赖冲突时失败
+	NoPackageLock   bool              `json:"no_package_lock,omitempty"`   // --no-package-lock，不读写package-lock.json
+	PackageLockOnly bool              `json:"package_lock_only,omitempty"` // --package-lock-only，只更新package-lock.json
+	SaveBundle      bool              `json:"save_bundle,omitempty"`       // --save-bundle
+	SavePeer        bool              `json:"save_peer,omitempty"`         // --save-peer
+	Omit            []string          `json:"omit,omitempty"`              // --omit，可选dev、optional、peer
+	Env             map[string]string `json:"env,omitempty"`               // 额外的环境变量
+	UserConfig      string            `json:"user_config,omitempty"`       // 替代的.npmrc路径
+	OnOutput        func(OutputLine)  `json:"-"`                           // 逐行接收npm的输出，用于显示实时日志
+}
+
+// RunScriptOptions 运行脚本选项
+type RunScriptOptions struct {
+	Args       []string          `json:"args,omitempty"`        // 传给脚本的参数，放在--之后
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+	OnOutput   func(OutputLine)  `json:"-"`                     // 逐行接收脚本的输出
+
+	// 在新的进程组中运行，取消时同时终止脚本启动的子进程，例如开发服务器，仅Unix有效。
+	// 进程不再接收终端的Ctrl+C，调用方需要自行取消ctx。
+	ProcessGroup bool `json:"process_group,omitempty"`
+
+	// 超时时间，0表示30分钟，小于0表示不限制
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// OutputLine 命令输出的一行
+//
+// OnOutput在命令运行期间按输出顺序调用，stdout和stderr的回调不会同时发生。
+type OutputLine struct {
+	Stream string    `json:"stream"` // stdout或stderr
+	Time   time.Time `json:"time"`   // 读取到该行的时间
+	Text   string    `json:"text"`   // 不含换行符
+}
+
+// UninstallOptions 卸载选项
+type UninstallOptions struct {
+	SaveDev    bool              `json:"save_dev,omitempty"`    // --save-dev
+	Global     bool              `json:"global,omitempty"`      // --global
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// UpdateOptions 更新选项
+type UpdateOptions struct {
+	Global     bool              `json:"global,omitempty"`      // --global
+	Registry   string            `json:"registry,omitempty"`    // 自定义registry
+	Save       bool              `json:"save,omitempty"`        // --save，同时更新package.json中直接依赖的版本范围
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// UpdateChange 更新前后锁文件中一个包的版本变化
+type UpdateChange struct {
+	Name string   `json:"name"`
+	From []string `json:"from,omitempty"` // 更新前的版本，为空表示新增的包
+	To   []string `json:"to,omitempty"`   // 更新后的版本，为空表示移除的包
+}
+
+// UpdateResult UpdateAll的结果
+type UpdateResult struct {
+	Changes []UpdateChange `json:"changes"` // 按包名排序，没有锁文件（例如全局更新）时为空
+}
+
+// ListOptions 列表选项
+type ListOptions struct {
+	Global     bool              `json:"global,omitempty"`      // --global
+	Depth      int               `json:"depth,omitempty"`       // --depth，-1时使用--all列出所有层级
+	Production bool              `json:"production,omitempty"`  // --production
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	JSON       bool              `json:"json,omitempty"`        // --json
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// OutdatedOptions 检查过期依赖选项
+type OutdatedOptions struct {
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// PruneOptions 清理多余包选项
+type PruneOptions struct {
+	Production bool              `json:"production,omitempty"`  // --production
+	DryRun     bool              `json:"dry_run,omitempty"`     // --dry-run
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// DedupeOptions 合并重复包选项
+type DedupeOptions struct {
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// ShrinkwrapOptions 生成npm-shrinkwrap.json选项
+type ShrinkwrapOptions struct {
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// ConfigListOptions 列出npm配置选项
+type ConfigListOptions struct {
+	WorkingDir string            `json:"working_dir,omitempty"` // 工作目录
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// PublishOptions 发布选项
+type PublishOptions struct {
+	Tag            string            `json:"tag,omitempty"`             // --tag
+	Access         string            `json:"access,omitempty"`          // --access (public/restricted)
+	Registry       string            `json:"registry,omitempty"`        // 自定义registry
+	WorkingDir     string            `json:"working_dir,omitempty"`     // 工作目录
+	DryRun         bool              `json:"dry_run,omitempty"`         // --dry-run
+	OTP            string            `json:"otp,omitempty"`             // --otp
+	ProvenanceFile string            `json:"provenance_file,omitempty"` // --provenance-file，sigstore来源证明bundle
+	Env            map[string]string `json:"env,omitempty"`             // 额外的环境变量
+	UserConfig     string            `json:"user_config,omitempty"`     // 替代的.npmrc路径
+}
+
+// UnpublishOptions 撤销发布选项
+type UnpublishOptions struct {
+	Registry   string            `json:"registry,omitempty"`    // --registry
+	OTP        string            `json:"otp,omitempty"`         // --otp
+	Force      bool              `json:"force,omitempty"`       // --force，撤销整个包时需要
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// DistTagOptions dist-tag管理选项
+type DistTagOptions struct {
+	Registry   string            `json:"registry,omitempty"`    // --registry
+	OTP        string            `json:"otp,omitempty"`         // --otp
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// OwnerOptions 包所有者管理选项
+type OwnerOptions struct {
+	Registry   string            `json:"registry,omitempty"`    // --registry
+	OTP        string            `json:"otp,omitempty"`         // --otp
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// Owner 包所有者
+type Owner struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+// AccessLevel 包访问级别
+type AccessLevel string
+
+const (
+	AccessPublic     AccessLevel = "public"
+	AccessRestricted AccessLevel = "restricted"
+)
+
+// Permission 团队或协作者的权限
+type Permission string
+
+const (
+	PermissionReadOnly  Permission = "read-only"
+	PermissionReadWrite Permission = "read-write"
+)
+
+// AccessOptions 包访问管理选项
+type AccessOptions struct {
+	Registry   string            `json:"registry,omitempty"`    // --registry
+	OTP        string            `json:"otp,omitempty"`         // --otp
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// Token 访问令牌信息
+type Token struct {
+	Key           string   `json:"key"`            // 令牌的哈希值
+	Token         string   `json:"token"`          // 列出时为脱敏后的值，创建时为完整令牌
+	ReadOnly      bool     `json:"readonly"`       // 是否只读
+	Automation    bool     `json:"automation"`     // 是否为自动化令牌
+	CIDRWhitelist []string `json:"cidr_whitelist"` // 允许使用的IP范围
+	Created       string   `json:"created"`        // 创建时间
+	Updated       string   `json:"updated"`        // 更新时间
+}
+
+// ID 令牌的短ID，可用于撤销令牌
+func (t Token) ID() string {
+	if len(t.Key) > 6 {
+		return t.Key[:6]
+	}
+	return t.Key
+}
+
+// TokenOptions 令牌管理选项
+type TokenOptions struct {
+	Registry   string            `json:"registry,omitempty"`    // --registry
+	OTP        string            `json:"otp,omitempty"`         // --otp
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// TokenCreateOptions 创建令牌选项
+type TokenCreateOptions struct {
+	ReadOnly   bool              `json:"read_only,omitempty"`   // --read-only
+	CIDR       []string          `json:"cidr,omitempty"`        // --cidr
+	Password   string            `json:"-"`                     // 账户密码，npm会交互式读取
+	Registry   string            `json:"registry,omitempty"`    // --registry
+	OTP        string            `json:"otp,omitempty"`         // --otp
+	Env        map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	UserConfig string            `json:"user_config,omitempty"` // 替代的.npmrc路径
+}
+
+// PackOptions 打包选项
+type PackOptions struct {
+	Spec            string            `json:"spec,omitempty"`             // 要打包的包，空表示当前项目
+	WorkingDir      string            `json:"working_dir,omitempty"`      // 工作目录
+	PackDestination string            `json:"pack_destination,omitempty"` // --pack-destination
+	DryRun          bool              `json:"dry_run,omitempty"`          // --dry-run
+	Normalize       bool              `json:"normalize,omitempty"`        // 打包后用NormalizeTarball重写tarball，使哈希与机器无关
+	Env             map[string]string `json:"env,omitempty"`              // 额外的环境变量
+	UserConfig      string            `json:"user_config,omitempty"`      // 替代的.npmrc路径
+}
+
+// PackResult 打包结果
+type PackResult struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Version      string     `json:"version"`
+	Filename     string     `json:"filename"`
+	Path         string     `json:"path,omitempty"` // tarball的完整路径
+	Size         int64      `json:"size"`           // tarball大小
+	UnpackedSize int64      `json:"unpackedSize"`   // 解压后大小
+	Shasum       string     `json:"shasum"`
+	Integrity    string     `json:"integrity"`
+	ContentHash  string     `json:"contentHash,omitempty"` // TarballContentHash计算的内容哈希，dry-run时为空
+	EntryCount   int        `json:"entryCount"`
+	Files        []PackFile `json:"files"`
+	Bundled      []string   `json:"bundled,omitempty"`
+}
+
+// PackFile tarball中的文件
+type PackFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Mode int    `json:"mode"`
+}
+
+// Package 表示一个npm包
+type Package struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Description  string            `json:"description,omitempty"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+	DevDeps      map[string]string `json:"devDependencies,omitempty"`
+	OptionalDeps map[string]string `json:"optionalDependencies,omitempty"`
+	PeerDeps     map[string]string `json:"peerDependencies,omitempty"`
+	Scripts      map[string]string `json:"scripts,omitempty"`
+	Keywords     []string          `json:"keywords,omitempty"`
+	Author       string            `json:"author,omitempty"` // "Name <email> (url)"格式，对象形式的作者解析时转换为这种格式
+	AuthorObject bool              `json:"-"`                // author在package.json中为对象形式，序列化时保持
+	Contributors []PackagePerson   `json:"contributors,omitempty"`
+	Maintainers  []PackagePerson   `json:"maintainers,omitempty"`
+	License      string            `json:"license,omitempty"`
+	Homepage     string            `json:"homepage,omitempty"`
+	Repository   *Repository       `json:"repository,omitempty"`
+	Bugs         *Bugs             `json:"bugs,omitempty"`
+	Funding      Funding           `json:"funding,omitempty"`
+	Main         string            `json:"main,omitempty"`
+	Module       string            `json:"module,omitempty"`      // 打包工具使用的ES模块入口
+	Types        string            `json:"types,omitempty"`       // TypeScript类型声明入口
+	Browser      json.RawMessage   `json:"browser,omitempty"`     // 浏览器入口，字符串或文件替换表
+	Exports      json.RawMessage   `json:"exports,omitempty"`     // 条件导出，字符串、数组或对象
+	Imports      json.RawMessage   `json:"imports,omitempty"`     // 以#开头的包内导入映射
+	Bin          json.RawMessage   `json:"bin,omitempty"`         // 可执行文件，字符串或命令到路径的映射
+	Files        []string          `json:"files,omitempty"`       // 发布时包含的文件
+	SideEffects  json.RawMessage   `json:"sideEffects,omitempty"` // false或有副作用的文件列表
+	Directories  map[string]string `json:"directories,omitempty"` // 包的目录结构，例如bin、man
+	Private      bool              `json:"private,omitempty"`
+
+	Engines        map[string]string `json:"engines,omitempty"`        // 支持的运行时版本范围，例如node、npm
+	OS             []string          `json:"os,omitempty"`             // 支持的操作系统，"!"开头表示排除
+	CPU            []string          `json:"cpu,omitempty"`            // 支持的CPU架构，"!"开头表示排除
+	PackageManager string            `json:"packageManager,omitempty"` // Corepack使用的包管理器，例如pnpm@9.1.0
+	PublishConfig  *PublishConfig    `json:"publishConfig,omitempty"`
+
+	// 以下字段只在ListPackages返回的依赖树中出现
+	Resolved      string    `json:"resolved,omitempty"`      // 安装来源，例如tarball地址
+	Path          string    `json:"path,omitempty"`          // 安装目录，npm list --long时才有
+	Extraneous    bool      `json:"extraneous,omitempty"`    // 已安装但没有被任何包依赖
+	Missing       bool      `json:"missing,omitempty"`       // 被依赖但没有安装，Version为空
+	Invalid       bool      `json:"invalid,omitempty"`       // 已安装的版本不满足依赖要求的范围
+	InvalidPeer   bool      `json:"invalidPeer,omitempty"`   // 不满足的是peerDependencies
+	InvalidReason string    `json:"invalidReason,omitempty"` // npm给出的不满足原因，例如"^2.0.0" from the root project
+	Required      string    `json:"required,omitempty"`      // 缺失依赖要求的版本范围
+	Problems      []string  `json:"problems,omitempty"`      // npm报告的该包的问题
+	Children      []Package `json:"children,omitempty"`      // 子依赖，按名称排序
+}
+
+// Repository 仓库信息
+type Repository struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Bugs bug报告信息
+type Bugs struct {
+	URL   string `json:"url,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// PackageInfo 包详细信息
+type PackageInfo struct {
+	Name         string                 `json:"name"`
+	Version      string                 `json:"version"`
+	Description  string                 `json:"description"`
+	Keywords     []string               `json:"keywords"`
+	Homepage     string                 `json:"homepage"`
+	Repository   *Repository            `json:"repository"`
+	Author       *Person                `json:"author"`
+	License      string                 `json:"license"`
+	Dependencies map[string]string      `json:"dependencies"`
+	DevDeps      map[string]string      `json:"devDependencies"`
+	Versions     map[string]interface{} `json:"versions"`
+	Time         map[string]time.Time   `json:"time"`
+	DistTags     map[string]string      `json:"dist-tags"`
+}
+
+// Person 人员信息
+type Person struct {
+	Name     string `json:"name"`
+	Email    string `json:"email,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"` // npm用户名，搜索结果的publisher和maintainers使用
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Package     SearchPackage `json:"package"`
+	Score       SearchScore   `json:"score"`
+	SearchScore float64       `json:"searchScore"`
+}
+
+// SearchPackage 搜索包信息
+type SearchPackage struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Description string            `json:"description"`
+	Keywords    []string          `json:"keywords"`
+	Date        time.Time         `json:"date"`
+	Links       map[string]string `json:"links"`
+	Author      *Person           `json:"author"`
+	Publisher   *Person           `json:"publisher"`
+	Maintainers []*Person         `json:"maintainers"`
+}
+
+// SearchScore 搜索评分
+type SearchScore struct {
+	Final  float64     `json:"final"`
+	Detail ScoreDetail `json:"detail"`
+}
+
+// ScoreDetail 评分详情
+type ScoreDetail struct {
+	Quality     float64 `json:"quality"`
+	Popularity  float64 `json:"popularity"`
+	Maintenance float64 `json:"maintenance"`
+}
+
+// OutdatedPackage npm outdated输出中的一项
+type OutdatedPackage struct {
+	Name      string `json:"name"`
+	Current   string `json:"current,omitempty"` // 已安装的版本，未安装时为空
+	Wanted    string `json:"wanted"`            // 满足package.json范围的最高版本
+	Latest    string `json:"latest"`            // latest标签指向的版本
+	Dependent string `json:"dependent,omitempty"`
+	Location  string `json:"location,omitempty"`
+	Type      string `json:"type,omitempty"` // 使用--long时输出的依赖类型
+	Homepage  string `json:"homepage,omitempty"`
+}
@@ -0,0 +1,361 @@
+package npmserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npm"
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+)
+
+// maxRequestBytes 请求体的大小上限
+const maxRequestBytes = 1 << 20
+
+// ServiceName gRPC风格路由使用的服务名，例如POST /npm.v1.NpmService/Install
+const ServiceName = "npm.v1.NpmService"
+
+// InstallRequest 安装请求，Packages为空时安装package.json中的所有依赖
+type InstallRequest struct {
+	Project  string             `json:"project"` // 项目目录，相对于Service的Root
+	Packages []string           `json:"packages,omitempty"`
+	Options  npm.InstallOptions `json:"options"`
+}
+
+// InstallResponse 安装结果
+type InstallResponse struct {
+	Project  string   `json:"project"`
+	Packages []string `json:"packages,omitempty"`
+}
+
+// AuditRequest 审计请求，项目中存在审计忽略文件时自动应用
+type AuditRequest struct {
+	Project string           `json:"project"`
+	Options npm.AuditOptions `json:"options"`
+}
+
+// OutdatedRequest 过期依赖查询请求
+type OutdatedRequest struct {
+	Project string `json:"project"`
+}
+
+// OutdatedResponse 过期依赖，按名称排序
+type OutdatedResponse struct {
+	Packages []npm.OutdatedPackage `json:"packages"`
+}
+
+// PublishPreflightRequest 发布前检查请求
+type PublishPreflightRequest struct {
+	Project    string              `json:"project"`
+	Registry   string              `json:"registry,omitempty"` // 与Publish的选项相同，为空时取自publishConfig
+	Access     string              `json:"access,omitempty"`
+	Tag        string              `json:"tag,omitempty"`
+	SizeCheck  bool                `json:"size_check,omitempty"` // 与registry中已发布的版本比较体积，需要registry客户端
+	Thresholds *npm.SizeThresholds `json:"thresholds,omitempty"` // 为nil时使用npm.DefaultSizeThresholds
+}
+
+// PublishPreflightResponse 发布前检查结果，Passed为false时Problems说明原因
+type PublishPreflightResponse struct {
+	Package *npm.PackResult `json:"package"` // dry-run打包的结果
+	Size    *npm.SizeReport `json:"size,omitempty"`
+
+	// 合并请求和publishConfig后实际使用的发布配置，Registry为空时由npm配置决定
+	Registry  string                      `json:"registry,omitempty"`
+	Access    string                      `json:"access,omitempty"`
+	Tag       string                      `json:"tag,omitempty"`
+	Conflicts []npm.PublishConfigConflict `json:"conflicts,omitempty"`
+
+	Problems []string `json:"problems,omitempty"`
+	Passed   bool     `json:"passed"`
+}
+
+// ErrorResponse 请求失败时返回的JSON
+type ErrorResponse struct {
+	Code    string `json:"code"` // gRPC状态码名称，例如invalid_argument、not_found
+	Message string `json:"message"`
+	NpmCode string `json:"npm_code,omitempty"` // npm错误码，例如E404、ERESOLVE
+}
+
+// Server 以JSON over HTTP提供SDK的主要操作，供非Go服务调用
+//
+// 每个操作同时注册REST风格（POST /v1/install）和gRPC风格（POST /npm.v1.NpmService/Install）的路由，
+// 请求和响应的字段与SDK的类型一致。所有命令通过Service执行，共享它的并发限制和缓存；
+// 项目目录由Service.Project校验，请求中的working_dir、env和user_config会被忽略，
+// registry只能是New传入或AllowRegistry允许的地址。
+type Server struct {
+	service    *npm.Service
+	registry   *registry.Client
+	registries map[string]*registry.Client // 允许访问的registry，键为去掉末尾/的地址
+	mux        *http.ServeMux
+}
+
+// New 创建服务，registryClient为nil时发布前检查不检查版本是否已发布，也不支持体积检查
+func New(service *npm.Service, registryClient *registry.Client) *Server {
+	s := &Server{
+		service:    service,
+		registry:   registryClient,
+		registries: make(map[string]*registry.Client),
+		mux:        http.NewServeMux(),
+	}
+	if registryClient != nil {
+		s.AllowRegistry(registryClient)
+	}
+
+	handle(s, "install", "Install", s.install)
+	handle(s, "audit", "Audit", s.audit)
+	handle(s, "outdated", "Outdated", s.outdated)
+	handle(s, "publish/preflight", "PublishPreflight", s.publishPreflight)
+	s.mux.Handle("GET /healthz", service.HealthHandler())
+	s.mux.Handle("GET /metrics", service.MetricsHandler())
+	return s
+}
+
+// AllowRegistry 允许安装和审计使用client的registry，发布前检查在那里检查版本是否已发布
+//
+// 请求或publishConfig指定的registry只有与New传入的客户端或这里允许的客户端地址相同时才会被访问，
+// 服务不会请求调用方传入的任意地址。需要在开始处理请求前调用。
+func (s *Server) AllowRegistry(client *registry.Client) {
+	s.registries[strings.TrimSuffix(client.URL(), "/")] = client
+}
+
+// ServeHTTP 实现http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handle 注册一个操作的两种路由
+func handle[Req, Resp any](s *Server, path, method string, fn func(context.Context, *Req) (*Resp, error)) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request Req
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			var maxBytesErr *http.MaxBytesError
+			if !errors.As(err, &maxBytesErr) {
+				err = npm.NewValidationError("body", "", fmt.Sprintf("invalid request: %v", err))
+			}
+			writeError(w, err)
+			return
+		}
+
+		response, err := fn(r.Context(), &request)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+	s.mux.Handle("POST /v1/"+path, handler)
+	s.mux.Handle("POST /"+ServiceName+"/"+method, handler)
+}
+
+// install 在项目中安装依赖
+func (s *Server) install(ctx context.Context, request *InstallRequest) (*InstallResponse, error) {
+	project, err := s.service.Project(request.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	// 以"-"开头的包名会被npm当作参数，例如--prefix=/或--userconfig
+	for _, pkg := range request.Packages {
+		if strings.HasPrefix(pkg, "-") {
+			return nil, npm.NewValidationError("packages", pkg, "package spec cannot start with \"-\"")
+		}
+	}
+
+	if err := s.checkRegistry(request.Options.Registry); err != nil {
+		return nil, err
+	}
+
+	options := request.Options
+	options.WorkingDir = project.Dir()
+	options.Global = false
+	options.Env = nil
+	options.UserConfig = ""
+	if err := s.service.Client().InstallPackages(ctx, request.Packages, options); err != nil {
+		return nil, err
+	}
+	return &InstallResponse{Project: request.Project, Packages: request.Packages}, nil
+}
+
+// audit 运行安全审计
+func (s *Server) audit(ctx context.Context, request *AuditRequest) (*npm.AuditReport, error) {
+	project, err := s.service.Project(request.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRegistry(request.Options.Registry); err != nil {
+		return nil, err
+	}
+
+	options := npm.AuditOptions{
+		Production: request.Options.Production,
+		Registry:   request.Options.Registry,
+		WorkingDir: project.Dir(),
+	}
+	ignoreFile := filepath.Join(project.Dir(), npm.DefaultAuditIgnoreFile)
+	if _, err := os.Stat(ignoreFile); err == nil {
+		options.IgnoreFile = ignoreFile
+	}
+	return s.service.Client().Audit(ctx, options)
+}
+
+// outdated 查询过期依赖
+func (s *Server) outdated(ctx context.Context, request *OutdatedRequest) (*OutdatedResponse, error) {
+	project, err := s.service.Project(request.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	packages, err := s.service.Client().Outdated(ctx, npm.OutdatedOptions{WorkingDir: project.Dir()})
+	if err != nil {
+		return nil, err
+	}
+	if packages == nil {
+		packages = []npm.OutdatedPackage{}
+	}
+	return &OutdatedResponse{Packages: packages}, nil
+}
+
+// publishPreflight 以dry-run方式打包，检查包能否发布
+func (s *Server) publishPreflight(ctx context.Context, request *PublishPreflightRequest) (*PublishPreflightResponse, error) {
+	project, err := s.service.Project(request.Project)
+	if err != nil {
+		return nil, err
+	}
+	if request.SizeCheck && s.registry == nil {
+		return nil, npm.NewValidationError("size_check", "true", "size check requires a registry client")
+	}
+
+	response := &PublishPreflightResponse{}
+	pkg := npm.NewPackageJSON(filepath.Join(project.Dir(), "package.json"))
+	if err := pkg.Load(); err != nil {
+		return nil, err
+	}
+	if pkg.IsPrivate() {
+		response.Problems = append(response.Problems, "package is marked private")
+	}
+
+	options, conflicts := npm.MergePublishConfig(pkg, npm.PublishOptions{Registry: request.Registry, Access: request.Access, Tag: request.Tag})
+	response.Registry, response.Access, response.Tag, response.Conflicts = options.Registry, options.Access, options.Tag, conflicts
+	for _, conflict := range conflicts {
+		response.Problems = append(response.Problems, fmt.Sprintf("publishConfig.%s is %q but %q will be used", conflict.Field, conflict.PublishConfig, conflict.Option))
+	}
+	if strings.HasPrefix(pkg.GetName(), "@") && options.Access == "" && (options.Registry == "" || sameRegistry(options.Registry, registry.DefaultRegistry)) {
+		response.Problems = append(response.Problems, "scoped packages are published as restricted by default, set publishConfig.access")
+	}
+
+	response.Package, err = s.service.Client().Pack(ctx, npm.PackOptions{WorkingDir: project.Dir(), DryRun: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.registry != nil && !pkg.IsPrivate() {
+		// 包发布到其他registry时在那里检查版本是否已存在，只访问允许的registry
+		target := options.Registry
+		if target == "" {
+			target = s.registry.URL()
+		}
+		registryClient, ok := s.registries[strings.TrimSuffix(target, "/")]
+		if !ok {
+			response.Problems = append(response.Problems, fmt.Sprintf("cannot check whether %s@%s is published: registry %q is not allowed", response.Package.Name, response.Package.Version, target))
+		} else {
+			_, err := registryClient.GetManifest(ctx, response.Package.Name, response.Package.Version)
+			switch {
+			case err == nil:
+				response.Problems = append(response.Problems, fmt.Sprintf("%s@%s is already published", response.Package.Name, response.Package.Version))
+			case !registry.IsNotFound(err):
+				return nil, err
+			}
+		}
+	}
+
+	if request.SizeCheck {
+		thresholds := npm.DefaultSizeThresholds()
+		if request.Thresholds != nil {
+			thresholds = *request.Thresholds
+		}
+		report, err := npm.NewSizeChecker(s.service.Client(), s.registry).Check(ctx, npm.SizeCheckOptions{
+			WorkingDir: project.Dir(),
+			Thresholds: &thresholds,
+		})
+		if err != nil {
+			return nil, err
+		}
+		response.Size = report
+		for _, violation := range report.Violations {
+			response.Problems = append(response.Problems, violation.Message)
+		}
+	}
+
+	response.Passed = len(response.Problems) == 0
+	return response, nil
+}
+
+// checkRegistry 检查请求指定的registry是否被允许，为空时由npm配置决定
+func (s *Server) checkRegistry(target string) error {
+	if target == "" {
+		return nil
+	}
+	if _, ok := s.registries[strings.TrimSuffix(target, "/")]; !ok {
+		return npm.NewValidationError("registry", target, "registry is not allowed")
+	}
+	return nil
+}
+
+// sameRegistry 比较registry地址，忽略末尾的/
+func sameRegistry(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+// writeJSON 写入JSON响应
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError 把SDK的错误映射为gRPC状态码名称和HTTP状态码
+func writeError(w http.ResponseWriter, err error) {
+	code, status := errorStatus(err)
+	response := ErrorResponse{Code: code, Message: err.Error()}
+	var npmErr *npm.NpmError
+	if errors.As(err, &npmErr) {
+		response.NpmCode = npmErr.Code()
+	}
+	writeJSON(w, status, response)
+}
+
+// errorStatus 返回错误对应的gRPC状态码名称和HTTP状态码
+func errorStatus(err error) (string, int) {
+	var validationErr *npm.ValidationError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &validationErr):
+		return "invalid_argument", http.StatusBadRequest
+	case errors.As(err, &maxBytesErr):
+		return "resource_exhausted", http.StatusRequestEntityTooLarge
+	case npm.IsBudgetExceeded(err):
+		return "resource_exhausted", http.StatusTooManyRequests
+	case errors.Is(err, context.Canceled):
+		return "canceled", 499
+	case errors.Is(err, context.DeadlineExceeded), npm.IsTimeout(err):
+		return "deadline_exceeded", http.StatusGatewayTimeout
+	case npm.IsPackageNotFound(err), npm.IsNoMatchingVersion(err):
+		return "not_found", http.StatusNotFound
+	case npm.IsAuthError(err), npm.IsOTPRequired(err):
+		return "unauthenticated", http.StatusUnauthorized
+	case npm.IsPeerDepConflict(err):
+		return "failed_precondition", http.StatusPreconditionFailed
+	case npm.IsNpmNotFound(err), npm.IsNetworkError(err), npm.IsRegistryError(err):
+		return "unavailable", http.StatusServiceUnavailable
+	}
+	return "internal", http.StatusInternalServerError
+}
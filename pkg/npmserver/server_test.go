@@ -0,0 +1,320 @@
+package npmserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/npm"
+	"github.com/scagogogo/go-npm-sdk/pkg/registry"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// stubExecutor 按子命令返回固定输出并记录执行的命令
+type stubExecutor struct {
+	mu   sync.Mutex
+	runs []utils.ExecuteOptions
+}
+
+func (e *stubExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	e.mu.Lock()
+	e.runs = append(e.runs, options)
+	e.mu.Unlock()
+
+	switch options.Args[0] {
+	case "--version":
+		return &utils.ExecuteResult{Success: true, Stdout: "10.2.0\n"}, nil
+	case "install":
+		if len(options.Args) > 1 && options.Args[1] == "missing-pkg" {
+			stderr := "npm error code E404\nnpm error 404 Not Found - GET https://registry.npmjs.org/missing-pkg\n"
+			return &utils.ExecuteResult{ExitCode: 1, Stderr: stderr}, errors.New("exit status 1")
+		}
+		return &utils.ExecuteResult{Success: true}, nil
+	case "audit":
+		return &utils.ExecuteResult{ExitCode: 1, Stdout: `{"auditReportVersion": 2, "vulnerabilities": {
+  "lodash": {"name": "lodash", "severity": "critical", "isDirect": true, "via": [{"source": 1, "name": "lodash", "title": "Command Injection", "url": "https://npmjs.com/advisories/1", "severity": "critical", "range": "<4.17.21"}], "effects": [], "range": "<4.17.21", "nodes": ["node_modules/lodash"], "fixAvailable": false}
+}, "metadata": {"vulnerabilities": {"critical": 1, "total": 1}}}`}, errors.New("exit status 1")
+	case "outdated":
+		return &utils.ExecuteResult{ExitCode: 1, Stdout: `{"lodash": {"current": "4.17.20", "wanted": "4.17.21", "latest": "4.17.21"}}`}, errors.New("exit status 1")
+	case "pack":
+		return &utils.ExecuteResult{Success: true, Stdout: `[{"id": "app@1.0.0", "name": "app", "version": "1.0.0", "size": 100, "unpackedSize": 200, "filename": "app-1.0.0.tgz", "files": [{"path": "index.js", "size": 200}], "entryCount": 1}]`}, nil
+	}
+	return &utils.ExecuteResult{ExitCode: 1}, errors.New("exit status 1")
+}
+
+func newTestServer(t *testing.T, registryClient *registry.Client, allowed ...*registry.Client) (*httptest.Server, *stubExecutor, string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "app", "package.json"), []byte(`{"name":"app","version":"1.0.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := &stubExecutor{}
+	service, err := npm.NewService(context.Background(), npm.ServiceOptions{Root: root, Executor: executor})
+	if err != nil {
+		t.Fatalf("NewService() failed: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+
+	handler := New(service, registryClient)
+	for _, client := range allowed {
+		handler.AllowRegistry(client)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server, executor, root
+}
+
+func post(t *testing.T, url, body string, out any) int {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("Failed to decode response from %s: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+func TestServerInstall(t *testing.T) {
+	server, executor, root := newTestServer(t, nil)
+
+	var response InstallResponse
+	body := `{"project":"app","packages":["lodash"],"options":{"save_dev":true,"working_dir":"/etc","user_config":"/etc/npmrc"}}`
+	if status := post(t, server.URL+"/v1/install", body, &response); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if !reflect.DeepEqual(response, InstallResponse{Project: "app", Packages: []string{"lodash"}}) {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	run := executor.runs[len(executor.runs)-1]
+	if run.WorkingDir != filepath.Join(root, "app") || run.Env["npm_config_userconfig"] != "" {
+		t.Errorf("Expected install in project dir without user config, got %+v", run)
+	}
+	if !reflect.DeepEqual(run.Args, []string{"install", "lodash", "--save-dev"}) {
+		t.Errorf("Unexpected args: %v", run.Args)
+	}
+
+	// gRPC风格的路由指向同一个操作
+	if status := post(t, server.URL+"/"+ServiceName+"/Install", `{"project":"app"}`, &response); status != http.StatusOK {
+		t.Errorf("Expected 200 from gRPC-style route, got %d", status)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	server, executor, _ := newTestServer(t, nil)
+
+	tests := []struct {
+		path, body, code string
+		status           int
+	}{
+		{"/v1/install", `{"project":"../outside"}`, "invalid_argument", http.StatusBadRequest},
+		{"/v1/install", `{"project":"app","unknown":true}`, "invalid_argument", http.StatusBadRequest},
+		{"/v1/install", `{"project":"app","packages":["missing-pkg"]}`, "not_found", http.StatusNotFound},
+		{"/v1/install", `{"project":"app","packages":["lodash","--prefix=/"]}`, "invalid_argument", http.StatusBadRequest},
+		{"/v1/publish/preflight", `{"project":"app","size_check":true}`, "invalid_argument", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		var response ErrorResponse
+		if status := post(t, server.URL+tt.path, tt.body, &response); status != tt.status || response.Code != tt.code {
+			t.Errorf("POST %s %s: expected %d %s, got %d %+v", tt.path, tt.body, tt.status, tt.code, status, response)
+		}
+	}
+
+	for _, run := range executor.runs {
+		for _, arg := range run.Args {
+			if strings.HasPrefix(arg, "--prefix") {
+				t.Errorf("Unexpected npm run with %v", run.Args)
+			}
+		}
+	}
+
+	var response ErrorResponse
+	post(t, server.URL+"/v1/install", `{"project":"app","packages":["missing-pkg"]}`, &response)
+	if response.NpmCode != "E404" {
+		t.Errorf("Expected npm code E404, got %+v", response)
+	}
+
+	resp, err := http.Get(server.URL + "/v1/install")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerRegistryAllowlist(t *testing.T) {
+	server, executor, _ := newTestServer(t, registry.NewClient("https://registry.example.com/"))
+
+	// 未允许的registry在执行npm之前被拒绝
+	for _, path := range []string{"/v1/install", "/v1/audit"} {
+		var response ErrorResponse
+		body := `{"project":"app","options":{"registry":"https://attacker.example.com"}}`
+		if status := post(t, server.URL+path, body, &response); status != http.StatusBadRequest || response.Code != "invalid_argument" {
+			t.Errorf("POST %s: expected 400 invalid_argument, got %d %+v", path, status, response)
+		}
+	}
+	for _, run := range executor.runs {
+		if strings.Contains(strings.Join(run.Args, " "), "attacker.example.com") {
+			t.Errorf("Unexpected npm run with %v", run.Args)
+		}
+	}
+
+	// 允许的registry忽略末尾的/
+	var response InstallResponse
+	if status := post(t, server.URL+"/v1/install", `{"project":"app","options":{"registry":"https://registry.example.com"}}`, &response); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if args := strings.Join(executor.runs[len(executor.runs)-1].Args, " "); !strings.Contains(args, "https://registry.example.com") {
+		t.Errorf("Expected allowed registry in args, got %s", args)
+	}
+	var report npm.AuditReport
+	if status := post(t, server.URL+"/v1/audit", `{"project":"app","options":{"registry":"https://registry.example.com/"}}`, &report); status != http.StatusOK {
+		t.Errorf("Expected 200 from audit, got %d", status)
+	}
+}
+
+func TestServerAuditAndOutdated(t *testing.T) {
+	server, _, _ := newTestServer(t, nil)
+
+	var report npm.AuditReport
+	if status := post(t, server.URL+"/v1/audit", `{"project":"app","options":{"production":true}}`, &report); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Name != "lodash" {
+		t.Errorf("Unexpected audit report: %+v", report)
+	}
+
+	var outdated OutdatedResponse
+	if status := post(t, server.URL+"/v1/outdated", `{"project":"app"}`, &outdated); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if len(outdated.Packages) != 1 || outdated.Packages[0].Name != "lodash" || outdated.Packages[0].Latest != "4.17.21" {
+		t.Errorf("Unexpected outdated packages: %+v", outdated.Packages)
+	}
+}
+
+func TestServerPublishPreflight(t *testing.T) {
+	published := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app/1.0.0" {
+			w.Write([]byte(`{"name":"app","version":"1.0.0"}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer published.Close()
+
+	server, _, root := newTestServer(t, nil)
+	var response PublishPreflightResponse
+	if status := post(t, server.URL+"/v1/publish/preflight", `{"project":"app"}`, &response); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if !response.Passed || response.Package == nil || response.Package.Filename != "app-1.0.0.tgz" {
+		t.Errorf("Unexpected preflight: %+v", response)
+	}
+
+	server, _, root = newTestServer(t, registry.NewClient(published.URL))
+	os.WriteFile(filepath.Join(root, "app", "package.json"), []byte(`{"name":"app","version":"1.0.0"}`), 0644)
+	response = PublishPreflightResponse{}
+	post(t, server.URL+"/"+ServiceName+"/PublishPreflight", `{"project":"app"}`, &response)
+	if response.Passed || !reflect.DeepEqual(response.Problems, []string{"app@1.0.0 is already published"}) {
+		t.Errorf("Expected already published problem, got %+v", response)
+	}
+
+	os.WriteFile(filepath.Join(root, "app", "package.json"), []byte(`{"name":"app","version":"1.0.0","private":true}`), 0644)
+	response = PublishPreflightResponse{}
+	post(t, server.URL+"/v1/publish/preflight", `{"project":"app"}`, &response)
+	if response.Passed || !reflect.DeepEqual(response.Problems, []string{"package is marked private"}) {
+		t.Errorf("Expected private problem, got %+v", response)
+	}
+}
+
+func TestServerPublishPreflightPublishConfig(t *testing.T) {
+	published := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app/1.0.0" {
+			w.Write([]byte(`{"name":"app","version":"1.0.0"}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer published.Close()
+	private := httptest.NewServer(http.NotFoundHandler())
+	defer private.Close()
+
+	var requested atomic.Int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer other.Close()
+
+	server, _, root := newTestServer(t, registry.NewClient(published.URL), registry.NewClient(private.URL))
+	os.WriteFile(filepath.Join(root, "app", "package.json"), []byte(`{"name":"@org/app","version":"1.0.0","publishConfig":{"@org:registry":"`+private.URL+`/","access":"restricted"}}`), 0644)
+
+	// 版本是否已发布在publishConfig指定的registry上检查
+	var response PublishPreflightResponse
+	post(t, server.URL+"/v1/publish/preflight", `{"project":"app","tag":"next"}`, &response)
+	if !response.Passed || response.Registry != private.URL+"/" || response.Access != "restricted" || response.Tag != "next" {
+		t.Errorf("Unexpected preflight: %+v", response)
+	}
+
+	response = PublishPreflightResponse{}
+	post(t, server.URL+"/v1/publish/preflight", `{"project":"app","registry":"`+published.URL+`"}`, &response)
+	expected := []string{
+		fmt.Sprintf("publishConfig.registry is %q but %q will be used", private.URL+"/", published.URL),
+		"app@1.0.0 is already published",
+	}
+	if response.Passed || len(response.Conflicts) != 1 || !reflect.DeepEqual(response.Problems, expected) {
+		t.Errorf("Expected registry conflict, got %+v", response)
+	}
+
+	// 没有允许的registry不会被访问
+	response = PublishPreflightResponse{}
+	post(t, server.URL+"/v1/publish/preflight", `{"project":"app","registry":"`+other.URL+`"}`, &response)
+	if response.Passed || len(response.Problems) != 2 || !strings.Contains(response.Problems[1], "is not allowed") || requested.Load() != 0 {
+		t.Errorf("Expected registry to be rejected without requests, got %+v (%d requests)", response, requested.Load())
+	}
+
+	server, _, root = newTestServer(t, nil)
+	os.WriteFile(filepath.Join(root, "app", "package.json"), []byte(`{"name":"@org/app","version":"1.0.0"}`), 0644)
+	response = PublishPreflightResponse{}
+	post(t, server.URL+"/v1/publish/preflight", `{"project":"app"}`, &response)
+	if response.Passed || len(response.Problems) != 1 || !strings.Contains(response.Problems[0], "restricted by default") {
+		t.Errorf("Expected scoped access problem, got %+v", response)
+	}
+}
+
+func TestServerHealth(t *testing.T) {
+	server, _, _ := newTestServer(t, nil)
+
+	resp, err := http.Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var health npm.ServiceHealth
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || !health.Ready || health.Npm == nil {
+		t.Errorf("Unexpected health: %d %+v", resp.StatusCode, health)
+	}
+}
@@ -0,0 +1,287 @@
+package osv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL OSV.dev API地址
+const DefaultBaseURL = "https://api.osv.dev/"
+
+// EcosystemNpm npm包所属的生态
+const EcosystemNpm = "npm"
+
+// maxBatchQueries querybatch接口单次请求的最大查询数
+const maxBatchQueries = 1000
+
+// Client OSV.dev漏洞数据库API客户端
+//
+// OSV汇总了GitHub Advisory Database、恶意包报告等数据源，不依赖npm registry的审计接口。
+type Client struct {
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+}
+
+// NewClient 创建OSV客户端，baseURL为空时使用DefaultBaseURL
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &Client{
+		baseURL:   baseURL,
+		userAgent: "go-npm-sdk/1.0",
+		httpClient: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+	}
+}
+
+// SetHTTPClient 设置HTTP客户端
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// Package 包的标识
+type Package struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+// Query 查询某个包版本的漏洞
+type Query struct {
+	Package   Package `json:"package"`
+	Version   string  `json:"version"`
+	PageToken string  `json:"page_token,omitempty"`
+}
+
+// Event 受影响版本范围中的一个事件
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// Range 受影响的版本范围，npm生态使用SEMVER类型
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Affected 受影响的包及其版本
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+// Severity CVSS等评分
+type Severity struct {
+	Type  string `json:"type"`  // 例如CVSS_V3
+	Score string `json:"score"` // CVSS向量
+}
+
+// Reference 相关链接
+type Reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Vulnerability 漏洞详情
+type Vulnerability struct {
+	ID         string      `json:"id"`
+	Summary    string      `json:"summary,omitempty"`
+	Details    string      `json:"details,omitempty"`
+	Aliases    []string    `json:"aliases,omitempty"` // 例如CVE编号
+	Modified   string      `json:"modified,omitempty"`
+	Published  string      `json:"published,omitempty"`
+	Severity   []Severity  `json:"severity,omitempty"`
+	Affected   []Affected  `json:"affected,omitempty"`
+	References []Reference `json:"references,omitempty"`
+
+	// DatabaseSpecific 数据源特有的字段，GitHub Advisory在其中给出严重程度和CWE
+	DatabaseSpecific struct {
+		Severity string   `json:"severity,omitempty"` // LOW、MODERATE、HIGH、CRITICAL
+		CWEIDs   []string `json:"cwe_ids,omitempty"`
+	} `json:"database_specific"`
+}
+
+// URL 漏洞在osv.dev上的页面
+func (v *Vulnerability) URL() string {
+	return "https://osv.dev/vulnerability/" + v.ID
+}
+
+// FixedVersions 返回ecosystem中name包的修复版本，按出现顺序
+func (v *Vulnerability) FixedVersions(ecosystem, name string) []string {
+	var fixed []string
+	for _, affected := range v.Affected {
+		if affected.Package.Ecosystem != ecosystem || affected.Package.Name != name {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				if event.Fixed != "" {
+					fixed = append(fixed, event.Fixed)
+				}
+			}
+		}
+	}
+	return fixed
+}
+
+// AffectedRanges 返回ecosystem中name包受影响的范围，使用npm的范围语法，例如">=1.0.0 <1.2.3"
+func (v *Vulnerability) AffectedRanges(ecosystem, name string) []string {
+	var ranges []string
+	for _, affected := range v.Affected {
+		if affected.Package.Ecosystem != ecosystem || affected.Package.Name != name {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+				continue
+			}
+			var lower string
+			for _, event := range r.Events {
+				switch {
+				case event.Introduced != "":
+					lower = event.Introduced
+				case event.Fixed != "":
+					ranges = append(ranges, semverRange(lower, "<"+event.Fixed))
+					lower = ""
+				case event.LastAffected != "":
+					ranges = append(ranges, semverRange(lower, "<="+event.LastAffected))
+					lower = ""
+				}
+			}
+			if lower != "" {
+				ranges = append(ranges, semverRange(lower, ""))
+			}
+		}
+	}
+	return ranges
+}
+
+// semverRange 由下界和上界组成范围，下界为0时省略
+func semverRange(introduced, upper string) string {
+	if introduced == "" || introduced == "0" {
+		if upper == "" {
+			return "*"
+		}
+		return upper
+	}
+	if upper == "" {
+		return ">=" + introduced
+	}
+	return ">=" + introduced + " " + upper
+}
+
+// QueryBatch 批量查询漏洞，返回与queries一一对应的漏洞ID列表
+//
+// 超过1000个查询时分多次请求，单个查询的结果分页时自动获取后续页。
+func (c *Client) QueryBatch(ctx context.Context, queries []Query) ([][]string, error) {
+	results := make([][]string, len(queries))
+	pending := make([]int, len(queries))
+	for i := range queries {
+		pending[i] = i
+	}
+	tokens := make([]string, len(queries))
+
+	for len(pending) > 0 {
+		batch := pending[:min(len(pending), maxBatchQueries)]
+		pending = pending[len(batch):]
+
+		request := struct {
+			Queries []Query `json:"queries"`
+		}{Queries: make([]Query, len(batch))}
+		for i, index := range batch {
+			request.Queries[i] = queries[index]
+			request.Queries[i].PageToken = tokens[index]
+		}
+
+		var response struct {
+			Results []struct {
+				Vulns []struct {
+					ID string `json:"id"`
+				} `json:"vulns"`
+				NextPageToken string `json:"next_page_token"`
+			} `json:"results"`
+		}
+		if err := c.post(ctx, "v1/querybatch", request, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Results) != len(batch) {
+			return nil, fmt.Errorf("expected %d results from querybatch, got %d", len(batch), len(response.Results))
+		}
+
+		for i, result := range response.Results {
+			index := batch[i]
+			for _, vuln := range result.Vulns {
+				results[index] = append(results[index], vuln.ID)
+			}
+			if result.NextPageToken != "" {
+				tokens[index] = result.NextPageToken
+				pending = append(pending, index)
+			}
+		}
+	}
+	return results, nil
+}
+
+// GetVulnerability 获取漏洞详情
+func (c *Client) GetVulnerability(ctx context.Context, id string) (*Vulnerability, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"v1/vulns/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	var vuln Vulnerability
+	if err := c.do(req, &vuln); err != nil {
+		return nil, err
+	}
+	return &vuln, nil
+}
+
+// post 发送JSON请求并解码响应
+func (c *Client) post(ctx context.Context, path string, body, v interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, v)
+}
+
+// do 发送请求，非2xx响应返回错误
+func (c *Client) do(req *http.Request, v interface{}) error {
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return fmt.Errorf("%s %s: %d %s", req.Method, req.URL, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestQueryBatchPaging(t *testing.T) {
+	var requests []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/querybatch" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Queries []Query `json:"queries"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, len(body.Queries))
+
+		type vuln struct {
+			ID string `json:"id"`
+		}
+		type result struct {
+			Vulns         []vuln `json:"vulns,omitempty"`
+			NextPageToken string `json:"next_page_token,omitempty"`
+		}
+		results := make([]result, len(body.Queries))
+		for i, query := range body.Queries {
+			switch {
+			case query.Package.Name == "lodash" && query.PageToken == "":
+				results[i] = result{Vulns: []vuln{{"GHSA-1"}}, NextPageToken: "next"}
+			case query.Package.Name == "lodash" && query.PageToken == "next":
+				results[i] = result{Vulns: []vuln{{"GHSA-2"}}}
+			case query.Version == "0.0.999":
+				results[i] = result{Vulns: []vuln{{"MAL-1"}}}
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	}))
+	defer server.Close()
+
+	queries := []Query{{Package: Package{Name: "lodash", Ecosystem: EcosystemNpm}, Version: "4.17.20"}}
+	for i := 0; i < maxBatchQueries; i++ {
+		queries = append(queries, Query{Package: Package{Name: fmt.Sprintf("pkg-%d", i), Ecosystem: EcosystemNpm}, Version: fmt.Sprintf("0.0.%d", i)})
+	}
+
+	results, err := NewClient(server.URL).QueryBatch(context.Background(), queries)
+	if err != nil {
+		t.Fatalf("QueryBatch() failed: %v", err)
+	}
+	if !reflect.DeepEqual(results[0], []string{"GHSA-1", "GHSA-2"}) {
+		t.Errorf("Expected both pages for lodash, got %v", results[0])
+	}
+	if !reflect.DeepEqual(results[1000], []string{"MAL-1"}) || results[1] != nil {
+		t.Errorf("Unexpected results: %v %v", results[1], results[1000])
+	}
+	// 1001个查询分两批，lodash的第二页与剩余的查询一起请求
+	if !reflect.DeepEqual(requests, []int{1000, 2}) {
+		t.Errorf("Unexpected batches: %v", requests)
+	}
+}
+
+func TestGetVulnerability(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/vulns/GHSA-35jh-r3h4-6jhm" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{
+			"id": "GHSA-35jh-r3h4-6jhm",
+			"summary": "Command Injection in lodash",
+			"aliases": ["CVE-2021-23337"],
+			"affected": [{
+				"package": {"ecosystem": "npm", "name": "lodash"},
+				"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+			}, {
+				"package": {"ecosystem": "npm", "name": "lodash.template"},
+				"ranges": [{"type": "SEMVER", "events": [{"introduced": "1.0.0"}, {"last_affected": "4.5.0"}, {"introduced": "5.0.0"}]}]
+			}],
+			"database_specific": {"severity": "HIGH", "cwe_ids": ["CWE-77"]}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	vuln, err := client.GetVulnerability(context.Background(), "GHSA-35jh-r3h4-6jhm")
+	if err != nil {
+		t.Fatalf("GetVulnerability() failed: %v", err)
+	}
+	if vuln.DatabaseSpecific.Severity != "HIGH" || vuln.Aliases[0] != "CVE-2021-23337" {
+		t.Errorf("Unexpected vulnerability: %+v", vuln)
+	}
+	if fixed := vuln.FixedVersions(EcosystemNpm, "lodash"); !reflect.DeepEqual(fixed, []string{"4.17.21"}) {
+		t.Errorf("Unexpected fixed versions: %v", fixed)
+	}
+	if ranges := vuln.AffectedRanges(EcosystemNpm, "lodash"); !reflect.DeepEqual(ranges, []string{"<4.17.21"}) {
+		t.Errorf("Unexpected lodash ranges: %v", ranges)
+	}
+	if ranges := vuln.AffectedRanges(EcosystemNpm, "lodash.template"); !reflect.DeepEqual(ranges, []string{">=1.0.0 <=4.5.0", ">=5.0.0"}) {
+		t.Errorf("Unexpected lodash.template ranges: %v", ranges)
+	}
+
+	if _, err := client.GetVulnerability(context.Background(), "GHSA-missing"); err == nil {
+		t.Error("Expected error for missing vulnerability")
+	}
+}
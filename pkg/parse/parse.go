@@ -0,0 +1,139 @@
+// Package parse 在限制下解析不可信的JSON输入，并给出带行列号的错误
+//
+// 该包只依赖标准库且不执行命令，可以编译到WASM，供lockfile、manifest等纯数据包使用。
+package parse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInputTooLarge 输入超过Limits.MaxSize
+	ErrInputTooLarge = errors.New("input too large")
+
+	// ErrNestingTooDeep 嵌套深度超过Limits.MaxDepth
+	ErrNestingTooDeep = errors.New("nesting too deep")
+)
+
+// Limits 解析不可信输入（仓库中的package.json、锁文件，npm的输出）时的限制
+type Limits struct {
+	MaxSize  int // 输入的最大字节数
+	MaxDepth int // JSON对象/数组或YAML块的最大嵌套深度
+}
+
+// DefaultLimits 各解析器使用的限制，可以在程序启动时按需调整
+//
+// 大型monorepo的package-lock.json可能有几十MB，lockfileVersion 1的嵌套依赖
+// 每层node_modules占两层JSON嵌套，默认值对真实文件足够宽松。
+var DefaultLimits = Limits{
+	MaxSize:  256 << 20,
+	MaxDepth: 512,
+}
+
+// Error 解析失败的位置信息
+type Error struct {
+	Source string // 输入来源，例如文件路径或"npm audit output"
+	Offset int64  // 出错字节的偏移，从0开始
+	Line   int    // 行号，从1开始
+	Column int    // 列号（按字节），从1开始
+	Err    error  // 原始错误
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("failed to parse %s at line %d, column %d (offset %d): %v", e.Source, e.Line, e.Column, e.Offset, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError 创建data中offset处的解析错误，行列号根据offset计算
+func NewError(source string, data []byte, offset int64, err error) *Error {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return &Error{
+		Source: source,
+		Offset: offset,
+		Line:   bytes.Count(before, []byte{'\n'}) + 1,
+		Column: int(offset) - lineStart + 1,
+		Err:    err,
+	}
+}
+
+// CheckSize 检查输入大小是否超过DefaultLimits.MaxSize
+func CheckSize(source string, data []byte) error {
+	if limit := DefaultLimits.MaxSize; limit > 0 && len(data) > limit {
+		return NewError(source, data, int64(limit), fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrInputTooLarge, len(data), limit))
+	}
+	return nil
+}
+
+// DecodeJSON 在DefaultLimits限制下解析JSON，失败时返回*Error
+func DecodeJSON(source string, data []byte, v interface{}) error {
+	if err := CheckSize(source, data); err != nil {
+		return err
+	}
+	if err := checkJSONDepth(source, data, DefaultLimits.MaxDepth); err != nil {
+		return err
+	}
+
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+
+	// encoding/json的Offset是出错时已读取的字节数，出错字节在它之前
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return NewError(source, data, syntaxErr.Offset-1, err)
+	case errors.As(err, &typeErr):
+		return NewError(source, data, typeErr.Offset-1, err)
+	default:
+		return NewError(source, data, 0, err)
+	}
+}
+
+// checkJSONDepth 检查JSON的嵌套深度，只扫描括号和字符串，语法错误留给json.Unmarshal报告
+func checkJSONDepth(source string, data []byte, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	depth := 0
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			if depth++; depth > limit {
+				return NewError(source, data, int64(i), fmt.Errorf("%w: exceeds limit of %d", ErrNestingTooDeep, limit))
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
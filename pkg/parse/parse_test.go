@@ -0,0 +1,107 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewParseError(t *testing.T) {
+	data := []byte("line one\nline two\nthree")
+	tests := []struct {
+		offset int64
+		line   int
+		column int
+	}{
+		{0, 1, 1},
+		{4, 1, 5},
+		{9, 2, 1},
+		{14, 2, 6},
+		{int64(len(data)), 3, 6},
+		{-1, 1, 1},
+		{1000, 3, 6},
+	}
+
+	for _, tt := range tests {
+		err := NewError("test", data, tt.offset, errors.New("bad"))
+		if err.Line != tt.line || err.Column != tt.column {
+			t.Errorf("Offset %d: expected %d:%d, got %d:%d", tt.offset, tt.line, tt.column, err.Line, err.Column)
+		}
+		if err.Offset < 0 || err.Offset > int64(len(data)) {
+			t.Errorf("Offset %d: expected offset to be clamped, got %d", tt.offset, err.Offset)
+		}
+	}
+
+	err := NewError("package.json", data, 14, errors.New("bad value"))
+	if err.Error() != "failed to parse package.json at line 2, column 6 (offset 14): bad value" {
+		t.Errorf("Unexpected message: %s", err.Error())
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var value struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	if err := DecodeJSON("test", []byte(`{"name": "demo", "version": "1.0.0"}`), &value); err != nil || value.Name != "demo" {
+		t.Fatalf("DecodeJSON() = %v, %+v", err, value)
+	}
+
+	tests := []struct {
+		name   string
+		data   string
+		line   int
+		column int
+	}{
+		{"syntax error", "{\n  \"name\": demo\n}", 2, 11},
+		{"type error", "{\n  \"version\": 1\n}", 2, 14},
+		{"unexpected end", "{\"name\": \"demo\"", 1, 15},
+		{"empty input", "", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeJSON("test", []byte(tt.data), &value)
+			var parseErr *Error
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected *Error, got %v", err)
+			}
+			if parseErr.Line != tt.line || parseErr.Column != tt.column {
+				t.Errorf("Expected %d:%d, got %d:%d (%v)", tt.line, tt.column, parseErr.Line, parseErr.Column, err)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONLimits(t *testing.T) {
+	limits := DefaultLimits
+	defer func() { DefaultLimits = limits }()
+	DefaultLimits = Limits{MaxSize: 32, MaxDepth: 3}
+
+	var value interface{}
+	if err := DecodeJSON("test", []byte(`[[["]]]]]]"]]]`), &value); err != nil {
+		t.Errorf("Expected brackets in strings to be ignored, got %v", err)
+	}
+	if err := DecodeJSON("test", []byte(`[[["\"[[["]]]`), &value); err != nil {
+		t.Errorf("Expected escaped quotes to be handled, got %v", err)
+	}
+
+	err := DecodeJSON("test", []byte("{\"a\": [[\n{}]]}"), &value)
+	var parseErr *Error
+	if !errors.As(err, &parseErr) || !errors.Is(err, ErrNestingTooDeep) {
+		t.Fatalf("Expected ErrNestingTooDeep, got %v", err)
+	}
+	if parseErr.Line != 2 || parseErr.Column != 1 {
+		t.Errorf("Expected error at the fourth opening bracket, got %d:%d", parseErr.Line, parseErr.Column)
+	}
+
+	err = DecodeJSON("test", []byte(strings.Repeat(" ", 33)), &value)
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+
+	// 0表示不限制
+	DefaultLimits = Limits{}
+	if err := DecodeJSON("test", []byte(strings.Repeat("[", 100)+strings.Repeat("]", 100)), &value); err != nil {
+		t.Errorf("Expected no limits, got %v", err)
+	}
+}
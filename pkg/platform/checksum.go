@@ -0,0 +1,146 @@
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxChecksumFileSize SHASUMS256.txt及其签名的大小上限
+const maxChecksumFileSize = 1 << 20
+
+// ChecksumError 下载的文件与发布的SHA-256校验和不一致，文件可能损坏或被篡改
+type ChecksumError struct {
+	File     string `json:"file"`
+	Expected string `json:"expected"` // SHASUMS256.txt中的十六进制SHA-256
+	Actual   string `json:"actual"`
+}
+
+// Error 实现error接口
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s", e.File, e.Expected, e.Actual)
+}
+
+// SignatureVerifier 校验SHASUMS256.txt的分离签名（SHASUMS256.txt.sig）
+//
+// 标准库没有OpenPGP实现，需要GPG校验时由调用方用Node.js发布者的公钥实现，签名无效时返回错误。
+type SignatureVerifier func(shasums, signature []byte) error
+
+// SetSignatureVerifier 设置SHASUMS256.txt的签名校验，nil表示只校验哈希
+func (nd *NodeJSDownloader) SetSignatureVerifier(verifier SignatureVerifier) {
+	nd.verifySignature = verifier
+}
+
+// ParseSHASUMS 解析sha256sum格式的校验和文件，返回文件名到十六进制SHA-256的映射
+func ParseSHASUMS(data []byte) (map[string]string, error) {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		sum, name, ok := strings.Cut(text, " ")
+		// 二进制模式的文件名以"*"开头
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+		if decoded, err := hex.DecodeString(sum); !ok || name == "" || err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid checksum line %d: %q", line, text)
+		}
+		sums[name] = strings.ToLower(sum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// VerifySHA256 计算文件的SHA-256并与expected比较，不一致时返回*ChecksumError
+func VerifySHA256(filePath, expected string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	actual := hex.EncodeToString(hash.Sum(nil))
+	if !strings.EqualFold(actual, expected) {
+		return &ChecksumError{File: filepath.Base(filePath), Expected: strings.ToLower(expected), Actual: actual}
+	}
+	return nil
+}
+
+// GetChecksums 获取指定版本发布的SHASUMS256.txt并解析
+//
+// 设置了SignatureVerifier时同时获取SHASUMS256.txt.sig并校验签名。
+func (nd *NodeJSDownloader) GetChecksums(ctx context.Context, version string) (map[string]string, error) {
+	url := fmt.Sprintf("%s/v%s/SHASUMS256.txt", nd.baseURL, version)
+	data, err := nd.fetch(ctx, url, maxChecksumFileSize)
+	if err != nil {
+		return nil, err
+	}
+	if nd.verifySignature != nil {
+		signature, err := nd.fetch(ctx, url+".sig", maxChecksumFileSize)
+		if err != nil {
+			return nil, err
+		}
+		if err := nd.verifySignature(data, signature); err != nil {
+			return nil, fmt.Errorf("invalid signature for SHASUMS256.txt: %w", err)
+		}
+	}
+	return ParseSHASUMS(data)
+}
+
+// VerifyNodeJS 用版本发布的SHASUMS256.txt校验下载的Node.js压缩包
+//
+// 哈希不一致时返回*ChecksumError；SHASUMS256.txt中没有该文件时同样返回错误，不跳过校验。
+func (nd *NodeJSDownloader) VerifyNodeJS(ctx context.Context, version, filePath string) error {
+	sums, err := nd.GetChecksums(ctx, version)
+	if err != nil {
+		return fmt.Errorf("failed to get checksums: %w", err)
+	}
+	name := filepath.Base(filePath)
+	expected, ok := sums[name]
+	if !ok {
+		return fmt.Errorf("no checksum for %s in SHASUMS256.txt", name)
+	}
+	return VerifySHA256(filePath, expected)
+}
+
+// fetch 下载小文件的内容，超过limit字节时返回错误
+func (nd *NodeJSDownloader) fetch(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "go-npm-sdk/1.0")
+
+	resp, err := nd.downloader.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: status %d", url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,99 @@
+package platform
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSHASUMS(t *testing.T) {
+	sum := strings.Repeat("ab", sha256.Size)
+	sums, err := ParseSHASUMS([]byte(sum + "  node-v18.17.0-linux-x64.tar.xz\n" + strings.ToUpper(sum) + " *node-v18.17.0-win-x64.zip\n\n"))
+	if err != nil {
+		t.Fatalf("ParseSHASUMS() failed: %v", err)
+	}
+	if sums["node-v18.17.0-linux-x64.tar.xz"] != sum || sums["node-v18.17.0-win-x64.zip"] != sum {
+		t.Errorf("Unexpected checksums: %v", sums)
+	}
+
+	for _, data := range []string{"abc  file.txt", sum, "zz" + sum[2:] + "  file.txt"} {
+		if _, err := ParseSHASUMS([]byte(data)); err == nil {
+			t.Errorf("ParseSHASUMS(%q) expected error", data)
+		}
+	}
+}
+
+func TestVerifyNodeJS(t *testing.T) {
+	content := []byte("node archive")
+	sum := sha256.Sum256(content)
+	shasums := fmt.Sprintf("%s  node-v18.17.0-linux-x64.tar.xz\n", hex.EncodeToString(sum[:]))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v18.17.0/SHASUMS256.txt":
+			w.Write([]byte(shasums))
+		case "/v18.17.0/SHASUMS256.txt.sig":
+			w.Write([]byte("signature"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	downloader := NewNodeJSDownloader()
+	downloader.baseURL = server.URL
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "node-v18.17.0-linux-x64.tar.xz")
+	if err := os.WriteFile(archive, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := downloader.VerifyNodeJS(context.Background(), "18.17.0", archive); err != nil {
+		t.Fatalf("VerifyNodeJS() failed: %v", err)
+	}
+
+	// 内容被篡改
+	if err := os.WriteFile(archive, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var checksumErr *ChecksumError
+	err := downloader.VerifyNodeJS(context.Background(), "18.17.0", archive)
+	if !errors.As(err, &checksumErr) || checksumErr.File != "node-v18.17.0-linux-x64.tar.xz" || checksumErr.Expected != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected ChecksumError, got %v", err)
+	}
+
+	// SHASUMS256.txt中没有的文件
+	other := filepath.Join(dir, "node-v18.17.0-darwin-x64.tar.gz")
+	os.WriteFile(other, content, 0644)
+	if err := downloader.VerifyNodeJS(context.Background(), "18.17.0", other); err == nil || !strings.Contains(err.Error(), "no checksum") {
+		t.Errorf("Expected missing checksum error, got %v", err)
+	}
+
+	// 签名校验
+	var signed []byte
+	downloader.SetSignatureVerifier(func(shasums, signature []byte) error {
+		signed = shasums
+		if string(signature) != "signature" {
+			return errors.New("bad signature")
+		}
+		return nil
+	})
+	if _, err := downloader.GetChecksums(context.Background(), "18.17.0"); err != nil || string(signed) != shasums {
+		t.Errorf("GetChecksums() with signature failed: %v", err)
+	}
+	downloader.SetSignatureVerifier(func(shasums, signature []byte) error { return errors.New("bad signature") })
+	if _, err := downloader.GetChecksums(context.Background(), "18.17.0"); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("Expected signature error, got %v", err)
+	}
+
+	if _, err := downloader.GetChecksums(context.Background(), "0.0.0"); err == nil {
+		t.Error("Expected error for missing SHASUMS256.txt")
+	}
+}
@@ -199,8 +199,9 @@ func (pr *progressReader) Read(p []byte) (int, error) {
 
 // NodeJSDownloader Node.js下载器
 type NodeJSDownloader struct {
-	downloader *Downloader
-	baseURL    string
+	downloader      *Downloader
+	baseURL         string
+	verifySignature SignatureVerifier // SHASUMS256.txt的签名校验，为nil时只校验哈希
 }
 
 // NewNodeJSDownloader 创建Node.js下载器
@@ -263,6 +264,17 @@ func (nd *NodeJSDownloader) DownloadNodeJS(ctx context.Context, version string,
 	return nd.downloader.DownloadWithRetry(ctx, options, 3)
 }
 
+// DownloadNodeJSWithEvents 下载Node.js，下载过程中向handler发送下载阶段的进度事件，handler可以为nil
+func (nd *NodeJSDownloader) DownloadNodeJSWithEvents(ctx context.Context, version string, info *Info, destination string, handler EventHandler) (*DownloadResult, error) {
+	var progress ProgressCallback
+	if handler != nil {
+		progress = func(downloaded, total int64) {
+			handler.HandleProgress(DownloadProgress(downloaded, total))
+		}
+	}
+	return nd.DownloadNodeJS(ctx, version, info, destination, progress)
+}
+
 // GetLatestVersion 获取最新版本号
 func (nd *NodeJSDownloader) GetLatestVersion(ctx context.Context) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", "https://nodejs.org/dist/latest/", nil)
@@ -402,3 +402,77 @@ func TestGetLatestVersion(t *testing.T) {
 
 	t.Logf("Latest Node.js version: %s", version)
 }
+
+func TestDownloadNodeJSProgressEvents(t *testing.T) {
+	content := strings.Repeat("x", 64*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v18.17.0/node-v18.17.0-linux-x64.tar.xz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	downloader := NewNodeJSDownloader()
+	downloader.baseURL = server.URL
+
+	var events []ProgressEvent
+	handler := EventHandlerFunc(func(event ProgressEvent) {
+		events = append(events, event)
+	})
+
+	info := &Info{Platform: Linux, Architecture: AMD64}
+	result, err := downloader.DownloadNodeJSWithEvents(context.Background(), "18.17.0", info, t.TempDir(), handler)
+	if err != nil {
+		t.Fatalf("DownloadNodeJSWithEvents() failed: %v", err)
+	}
+	if result.Size != int64(len(content)) {
+		t.Errorf("Expected size %d, got %d", len(content), result.Size)
+	}
+
+	if len(events) == 0 {
+		t.Fatal("Expected progress events")
+	}
+	for _, event := range events {
+		if event.Stage != StageDownloading {
+			t.Errorf("Expected stage %s, got %s", StageDownloading, event.Stage)
+		}
+		if event.BytesTotal != int64(len(content)) {
+			t.Errorf("Expected total %d, got %d", len(content), event.BytesTotal)
+		}
+	}
+	last := events[len(events)-1]
+	if last.BytesDownloaded != int64(len(content)) || last.Percent != 100 {
+		t.Errorf("Expected final event at 100%%, got %+v", last)
+	}
+
+	// handler为nil时不发送事件
+	if _, err := downloader.DownloadNodeJSWithEvents(context.Background(), "18.17.0", info, t.TempDir(), nil); err != nil {
+		t.Fatalf("DownloadNodeJSWithEvents() with nil handler failed: %v", err)
+	}
+
+	// 字节进度回调
+	var downloaded int64
+	progress := func(n, total int64) { downloaded = n }
+	if _, err := downloader.DownloadNodeJS(context.Background(), "18.17.0", info, t.TempDir(), progress); err != nil {
+		t.Fatalf("DownloadNodeJS() failed: %v", err)
+	}
+	if downloaded != int64(len(content)) {
+		t.Errorf("Expected progress up to %d bytes, got %d", len(content), downloaded)
+	}
+}
+
+func TestDownloadProgress(t *testing.T) {
+	event := DownloadProgress(25, 100)
+	if event.Stage != StageDownloading || event.Percent != 25 || event.BytesDownloaded != 25 || event.BytesTotal != 100 {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+
+	// 总大小未知
+	event = DownloadProgress(25, -1)
+	if event.Percent != -1 || event.BytesTotal != -1 {
+		t.Errorf("Expected unknown percent, got %+v", event)
+	}
+}
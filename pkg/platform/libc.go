@@ -0,0 +1,36 @@
+package platform
+
+import (
+	"path/filepath"
+	"runtime"
+)
+
+// Libc Linux使用的C标准库，决定能否使用预编译的原生模块
+type Libc string
+
+const (
+	Glibc       Libc = "glibc"
+	Musl        Libc = "musl" // Alpine等发行版
+	UnknownLibc Libc = ""
+)
+
+// DetectLibc 检测当前系统的C标准库，非Linux系统返回UnknownLibc
+func DetectLibc() Libc {
+	if runtime.GOOS != "linux" {
+		return UnknownLibc
+	}
+	return detectLibc("/")
+}
+
+// detectLibc 根据root下的动态链接器判断C标准库
+func detectLibc(root string) Libc {
+	if matches, _ := filepath.Glob(filepath.Join(root, "lib", "ld-musl-*")); len(matches) > 0 {
+		return Musl
+	}
+	for _, pattern := range []string{"lib*/ld-linux*", "lib/*-linux-gnu*/libc.so.6", "lib*/libc.so.6"} {
+		if matches, _ := filepath.Glob(filepath.Join(root, pattern)); len(matches) > 0 {
+			return Glibc
+		}
+	}
+	return UnknownLibc
+}
@@ -0,0 +1,37 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectLibc(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  Libc
+	}{
+		{"musl", []string{"lib/ld-musl-x86_64.so.1"}, Musl},
+		{"glibc", []string{"lib64/ld-linux-x86-64.so.2"}, Glibc},
+		{"debian multiarch", []string{"lib/x86_64-linux-gnu/libc.so.6"}, Glibc},
+		{"unknown", nil, UnknownLibc},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, file := range tt.files {
+				path := filepath.Join(root, file)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := detectLibc(root); got != tt.want {
+				t.Errorf("detectLibc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
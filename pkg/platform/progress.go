@@ -0,0 +1,57 @@
+package platform
+
+// ProgressStage 进度阶段
+type ProgressStage string
+
+const (
+	StageStarting    ProgressStage = "starting"    // 开始
+	StageDownloading ProgressStage = "downloading" // 下载中
+	StageVerifying   ProgressStage = "verifying"   // 校验中
+	StageExtracting  ProgressStage = "extracting"  // 解压中
+	StageInstalling  ProgressStage = "installing"  // 安装中
+	StageRemoving    ProgressStage = "removing"    // 删除中
+	StageCompleted   ProgressStage = "completed"   // 完成
+)
+
+// ProgressEvent 结构化的进度事件，便于UI渲染进度条
+type ProgressEvent struct {
+	Stage           ProgressStage `json:"stage"`
+	Percent         float64       `json:"percent"`                    // 0-100，总大小未知时为-1
+	BytesDownloaded int64         `json:"bytes_downloaded,omitempty"` // 已下载字节数，仅下载阶段
+	BytesTotal      int64         `json:"bytes_total,omitempty"`      // 总字节数，未知时为0
+	Message         string        `json:"message,omitempty"`          // 面向用户的描述
+}
+
+// EventHandler 进度事件处理器
+type EventHandler interface {
+	HandleProgress(event ProgressEvent)
+}
+
+// EventHandlerFunc 函数形式的EventHandler
+type EventHandlerFunc func(event ProgressEvent)
+
+// HandleProgress 实现EventHandler
+func (f EventHandlerFunc) HandleProgress(event ProgressEvent) {
+	f(event)
+}
+
+// EmitProgress 向handler发送事件，handler为nil时忽略
+func EmitProgress(handler EventHandler, event ProgressEvent) {
+	if handler != nil {
+		handler.HandleProgress(event)
+	}
+}
+
+// DownloadProgress 把字节进度转换为下载阶段的事件
+func DownloadProgress(downloaded, total int64) ProgressEvent {
+	percent := -1.0
+	if total > 0 {
+		percent = float64(downloaded) / float64(total) * 100
+	}
+	return ProgressEvent{
+		Stage:           StageDownloading,
+		Percent:         percent,
+		BytesDownloaded: downloaded,
+		BytesTotal:      total,
+	}
+}
@@ -0,0 +1,171 @@
+package platform
+
+import (
+	"sync"
+	"time"
+)
+
+// OperationEvent 带操作ID的进度事件
+type OperationEvent struct {
+	Operation string    `json:"operation"`
+	Time      time.Time `json:"time"`
+	ProgressEvent
+}
+
+// OperationProgress 单个操作的当前进度
+type OperationProgress struct {
+	Operation string        `json:"operation"`
+	Latest    ProgressEvent `json:"latest"` // 最近一个事件，还没有事件时Stage为空
+	Events    int           `json:"events"`
+	Started   time.Time     `json:"started"` // 注册的时间
+	Updated   time.Time     `json:"updated"`
+	Done      bool          `json:"done"`            // 收到StageCompleted事件或调用了Finish
+	Error     string        `json:"error,omitempty"` // Finish传入的错误
+}
+
+// AggregateProgress 所有操作的汇总进度
+type AggregateProgress struct {
+	Operations int     `json:"operations"`
+	Done       int     `json:"done"`    // 成功完成的操作数
+	Failed     int     `json:"failed"`  // Finish传入错误的操作数
+	Percent    float64 `json:"percent"` // 各操作进度的平均值，完成的按100，进度未知的按0
+
+	BytesDownloaded int64 `json:"bytes_downloaded,omitempty"`
+	BytesTotal      int64 `json:"bytes_total,omitempty"`
+}
+
+// ProgressMux 把多个并发操作的进度事件汇合到一起
+//
+// 每个操作通过Handler获取自己的EventHandler，事件带上操作ID后按顺序交给回调，
+// 回调不会被并发调用，可以直接操作终端或UI。Operation、Operations和Aggregate
+// 随时返回各操作和汇总的进度快照。ProgressMux可以被多个goroutine同时使用。
+type ProgressMux struct {
+	mu         sync.Mutex
+	operations map[string]*OperationProgress
+	order      []string
+
+	emitMu  sync.Mutex
+	handler func(OperationEvent)
+	now     func() time.Time
+}
+
+// NewProgressMux 创建进度多路复用器，handler为nil时只记录进度
+func NewProgressMux(handler func(OperationEvent)) *ProgressMux {
+	return &ProgressMux{
+		operations: make(map[string]*OperationProgress),
+		handler:    handler,
+		now:        time.Now,
+	}
+}
+
+// Handler 注册操作并返回它的EventHandler，同一个ID多次调用返回的handler共享进度
+func (m *ProgressMux) Handler(operation string) EventHandler {
+	m.register(operation)
+	return EventHandlerFunc(func(event ProgressEvent) {
+		m.emit(operation, event)
+	})
+}
+
+// Finish 标记操作结束，err不为nil时记为失败
+//
+// 失败的操作通常不会发送StageCompleted事件，调用方应在操作返回后调用Finish。
+func (m *ProgressMux) Finish(operation string, err error) {
+	m.mu.Lock()
+	progress := m.lockedRegister(operation)
+	progress.Done = true
+	if err != nil {
+		progress.Error = err.Error()
+	}
+	progress.Updated = m.now()
+	m.mu.Unlock()
+}
+
+// Operation 返回单个操作的进度
+func (m *ProgressMux) Operation(operation string) (OperationProgress, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	progress, ok := m.operations[operation]
+	if !ok {
+		return OperationProgress{}, false
+	}
+	return *progress, true
+}
+
+// Operations 按注册顺序返回所有操作的进度
+func (m *ProgressMux) Operations() []OperationProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	operations := make([]OperationProgress, 0, len(m.order))
+	for _, id := range m.order {
+		operations = append(operations, *m.operations[id])
+	}
+	return operations
+}
+
+// Aggregate 返回所有操作的汇总进度
+func (m *ProgressMux) Aggregate() AggregateProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	aggregate := AggregateProgress{Operations: len(m.order)}
+	var percent float64
+	for _, id := range m.order {
+		progress := m.operations[id]
+		switch {
+		case progress.Error != "":
+			aggregate.Failed++
+			percent += 100
+		case progress.Done:
+			aggregate.Done++
+			percent += 100
+		case progress.Latest.Percent > 0:
+			percent += min(progress.Latest.Percent, 100)
+		}
+		aggregate.BytesDownloaded += progress.Latest.BytesDownloaded
+		aggregate.BytesTotal += progress.Latest.BytesTotal
+	}
+	if aggregate.Operations > 0 {
+		aggregate.Percent = percent / float64(aggregate.Operations)
+	}
+	return aggregate
+}
+
+// register 注册操作
+func (m *ProgressMux) register(operation string) {
+	m.mu.Lock()
+	m.lockedRegister(operation)
+	m.mu.Unlock()
+}
+
+// lockedRegister 返回操作的进度，不存在时创建，调用方需持有mu
+func (m *ProgressMux) lockedRegister(operation string) *OperationProgress {
+	progress, ok := m.operations[operation]
+	if !ok {
+		progress = &OperationProgress{Operation: operation, Started: m.now()}
+		m.operations[operation] = progress
+		m.order = append(m.order, operation)
+	}
+	return progress
+}
+
+// emit 更新操作的进度并调用回调
+func (m *ProgressMux) emit(operation string, event ProgressEvent) {
+	// 先更新进度再调用回调，回调中可以读取包含当前事件的快照
+	m.emitMu.Lock()
+	defer m.emitMu.Unlock()
+
+	m.mu.Lock()
+	progress := m.lockedRegister(operation)
+	now := m.now()
+	progress.Latest = event
+	progress.Events++
+	progress.Updated = now
+	if event.Stage == StageCompleted {
+		progress.Done = true
+	}
+	m.mu.Unlock()
+
+	if m.handler != nil {
+		m.handler(OperationEvent{Operation: operation, Time: now, ProgressEvent: event})
+	}
+}
@@ -0,0 +1,81 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestProgressMux(t *testing.T) {
+	var events []OperationEvent
+	mux := NewProgressMux(func(event OperationEvent) {
+		events = append(events, event)
+	})
+
+	lodash := mux.Handler("install lodash")
+	react := mux.Handler("install react")
+	mux.Handler("install vue")
+
+	EmitProgress(lodash, DownloadProgress(50, 100))
+	EmitProgress(react, ProgressEvent{Stage: StageInstalling, Percent: -1})
+	EmitProgress(lodash, ProgressEvent{Stage: StageCompleted, Percent: 100})
+	mux.Finish("install vue", errors.New("E404"))
+
+	if len(events) != 3 || events[0].Operation != "install lodash" || events[1].Operation != "install react" || events[0].BytesDownloaded != 50 {
+		t.Fatalf("Unexpected events: %+v", events)
+	}
+
+	progress, ok := mux.Operation("install lodash")
+	if !ok || !progress.Done || progress.Events != 2 || progress.Latest.Stage != StageCompleted {
+		t.Errorf("Unexpected lodash progress: %+v", progress)
+	}
+	if _, ok := mux.Operation("install angular"); ok {
+		t.Error("Expected unknown operation to be missing")
+	}
+
+	operations := mux.Operations()
+	if len(operations) != 3 || operations[0].Operation != "install lodash" || operations[2].Error != "E404" {
+		t.Errorf("Unexpected operations: %+v", operations)
+	}
+
+	// lodash完成，react进度未知，vue失败
+	aggregate := mux.Aggregate()
+	expected := AggregateProgress{Operations: 3, Done: 1, Failed: 1, Percent: 200.0 / 3}
+	if aggregate != expected {
+		t.Errorf("Aggregate() = %+v, expected %+v", aggregate, expected)
+	}
+}
+
+func TestProgressMuxConcurrent(t *testing.T) {
+	var calls, active int
+	mux := NewProgressMux(func(event OperationEvent) {
+		active++
+		if active > 1 {
+			t.Error("Handler called concurrently")
+		}
+		calls++
+		active--
+	})
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		handler := mux.Handler(fmt.Sprintf("op-%d", i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= 100; j++ {
+				handler.HandleProgress(DownloadProgress(int64(j), 100))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 800 {
+		t.Errorf("Expected 800 events, got %d", calls)
+	}
+	aggregate := mux.Aggregate()
+	if aggregate.Operations != 8 || aggregate.Percent != 100 || aggregate.BytesDownloaded != 800 {
+		t.Errorf("Unexpected aggregate: %+v", aggregate)
+	}
+}
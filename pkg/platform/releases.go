@@ -0,0 +1,83 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxReleaseIndexSize index.json的大小上限
+const maxReleaseIndexSize = 16 << 20
+
+// NodeRelease Node.js发布索引（index.json）中的一个版本
+type NodeRelease struct {
+	Version  string   `json:"version"`           // 不带"v"前缀，例如18.17.0
+	Date     string   `json:"date"`              // 发布日期，YYYY-MM-DD
+	Files    []string `json:"files"`             // 提供的文件类型，例如linux-x64、osx-arm64-tar、win-x64-zip
+	Npm      string   `json:"npm,omitempty"`     // 附带的npm版本
+	V8       string   `json:"v8,omitempty"`      // V8版本
+	LTS      string   `json:"lts,omitempty"`     // LTS代号，例如Hydrogen，不是LTS时为空
+	Security bool     `json:"security"`          // 是否为安全更新
+	Modules  string   `json:"modules,omitempty"` // NODE_MODULE_VERSION
+}
+
+// UnmarshalJSON 解析index.json中的版本，lts为false或代号，version带"v"前缀
+func (r *NodeRelease) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Version  string          `json:"version"`
+		Date     string          `json:"date"`
+		Files    []string        `json:"files"`
+		Npm      string          `json:"npm"`
+		V8       string          `json:"v8"`
+		LTS      json.RawMessage `json:"lts"`
+		Security bool            `json:"security"`
+		Modules  string          `json:"modules"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = NodeRelease{
+		Version:  strings.TrimPrefix(raw.Version, "v"),
+		Date:     raw.Date,
+		Files:    raw.Files,
+		Npm:      raw.Npm,
+		V8:       raw.V8,
+		Security: raw.Security,
+		Modules:  raw.Modules,
+	}
+	// 不是LTS时lts为false
+	var codename string
+	if json.Unmarshal(raw.LTS, &codename) == nil {
+		r.LTS = codename
+	}
+	return nil
+}
+
+// IsLTS 是否为LTS版本
+func (r *NodeRelease) IsLTS() bool {
+	return r.LTS != ""
+}
+
+// HasFile 是否提供指定类型的文件，例如linux-x64
+func (r *NodeRelease) HasFile(file string) bool {
+	for _, f := range r.Files {
+		if f == file {
+			return true
+		}
+	}
+	return false
+}
+
+// GetReleases 获取index.json中的所有版本，按发布索引中的顺序（从新到旧）
+func (nd *NodeJSDownloader) GetReleases(ctx context.Context) ([]NodeRelease, error) {
+	data, err := nd.fetch(ctx, nd.baseURL+"/index.json", maxReleaseIndexSize)
+	if err != nil {
+		return nil, err
+	}
+	var releases []NodeRelease
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse index.json: %w", err)
+	}
+	return releases, nil
+}
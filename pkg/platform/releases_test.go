@@ -0,0 +1,44 @@
+package platform
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetReleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+  {"version":"v22.9.0","date":"2024-09-17","files":["linux-x64","osx-arm64-tar","win-x64-zip"],"npm":"10.8.3","v8":"12.4.254.21","uv":"1.48.0","modules":"127","lts":false,"security":false},
+  {"version":"v20.17.0","date":"2024-08-21","files":["linux-x64"],"npm":"10.8.2","v8":"11.3.244.8","modules":"115","lts":"Iron","security":true}
+]`))
+	}))
+	defer server.Close()
+
+	downloader := NewNodeJSDownloader()
+	downloader.baseURL = server.URL
+	releases, err := downloader.GetReleases(context.Background())
+	if err != nil {
+		t.Fatalf("GetReleases() failed: %v", err)
+	}
+	if len(releases) != 2 {
+		t.Fatalf("Expected 2 releases, got %d", len(releases))
+	}
+	current, lts := releases[0], releases[1]
+	if current.Version != "22.9.0" || current.IsLTS() || current.Npm != "10.8.3" || current.Date != "2024-09-17" || !current.HasFile("osx-arm64-tar") {
+		t.Errorf("Unexpected release: %+v", current)
+	}
+	if lts.Version != "20.17.0" || lts.LTS != "Iron" || !lts.IsLTS() || !lts.Security || lts.HasFile("win-x64-zip") {
+		t.Errorf("Unexpected release: %+v", lts)
+	}
+
+	downloader.baseURL = server.URL + "/missing"
+	if _, err := downloader.GetReleases(context.Background()); err == nil {
+		t.Error("Expected error for missing index.json")
+	}
+}
@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// BulkAdvisory 批量安全公告接口返回的公告
+type BulkAdvisory struct {
+	ID                 int      `json:"id"`
+	URL                string   `json:"url"`
+	Title              string   `json:"title"`
+	Severity           string   `json:"severity"`
+	VulnerableVersions string   `json:"vulnerable_versions"`
+	CWE                []string `json:"cwe,omitempty"`
+	CVSS               struct {
+		Score        float64 `json:"score"`
+		VectorString string  `json:"vectorString,omitempty"`
+	} `json:"cvss"`
+}
+
+// BulkAdvisories 查询一组包版本的安全公告，与npm audit使用的接口相同
+//
+// pkgs为包名到版本列表的映射，返回包名到公告的映射，没有公告的包不出现在结果中。
+// 公告的VulnerableVersions是受影响的版本范围，调用方需要自行判断具体版本是否受影响。
+func (c *Client) BulkAdvisories(ctx context.Context, pkgs map[string][]string) (map[string][]BulkAdvisory, error) {
+	body, err := json.Marshal(pkgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"-/npm/v1/security/advisories/bulk", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, responseError(resp)
+	}
+
+	advisories := make(map[string][]BulkAdvisory)
+	if err := json.NewDecoder(resp.Body).Decode(&advisories); err != nil {
+		return nil, fmt.Errorf("failed to parse advisories: %w", err)
+	}
+	return advisories, nil
+}
@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestBulkAdvisories(t *testing.T) {
+	var request map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/-/npm/v1/security/advisories/bulk" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"lodash": [{"id": 1106913, "url": "https://github.com/advisories/GHSA-jf85-cpcp-j695", "title": "Prototype Pollution", "severity": "critical", "vulnerable_versions": "<4.17.12", "cwe": ["CWE-1321"], "cvss": {"score": 9.1, "vectorString": "CVSS:3.1/AV:N"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetToken("secret")
+	pkgs := map[string][]string{"lodash": {"4.17.11"}, "react": {"18.2.0"}}
+	advisories, err := client.BulkAdvisories(context.Background(), pkgs)
+	if err != nil {
+		t.Fatalf("BulkAdvisories() failed: %v", err)
+	}
+	if !reflect.DeepEqual(request, pkgs) {
+		t.Errorf("Unexpected request body: %v", request)
+	}
+	if len(advisories) != 1 || len(advisories["lodash"]) != 1 {
+		t.Fatalf("Unexpected advisories: %+v", advisories)
+	}
+	advisory := advisories["lodash"][0]
+	if advisory.ID != 1106913 || advisory.Severity != "critical" || advisory.VulnerableVersions != "<4.17.12" || advisory.CVSS.Score != 9.1 {
+		t.Errorf("Unexpected advisory: %+v", advisory)
+	}
+
+	client.SetToken("")
+	if _, err := client.BulkAdvisories(context.Background(), pkgs); err == nil {
+		t.Error("Expected error response to be returned")
+	}
+}
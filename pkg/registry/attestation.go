@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 证明的谓词类型
+const (
+	PredicateSLSAProvenanceV1  = "https://slsa.dev/provenance/v1"
+	PredicateSLSAProvenanceV02 = "https://slsa.dev/provenance/v0.2"
+	PredicateNpmPublish        = "https://github.com/npm/attestation/tree/main/specs/publish/v0.1"
+)
+
+// Attestation 包版本的一个证明，Bundle为sigstore bundle的原始JSON
+type Attestation struct {
+	PredicateType string          `json:"predicateType"`
+	Bundle        json.RawMessage `json:"bundle"`
+}
+
+// DistAttestations 版本清单dist.attestations字段，版本带有来源证明时由registry填写
+type DistAttestations struct {
+	URL        string `json:"url"`
+	Provenance struct {
+		PredicateType string `json:"predicateType"`
+	} `json:"provenance"`
+}
+
+// SigningKey registry签署发布证明使用的公钥
+type SigningKey struct {
+	KeyID   string     `json:"keyid"`
+	KeyType string     `json:"keytype"`
+	Scheme  string     `json:"scheme"`
+	Key     string     `json:"key"`     // base64编码的DER格式SubjectPublicKeyInfo
+	Expires *time.Time `json:"expires"` // 为nil表示未过期
+}
+
+// Attestations 获取包版本的证明，版本没有证明时返回404错误
+func (c *Client) Attestations(ctx context.Context, name, version string) ([]Attestation, error) {
+	resp, err := c.get(ctx, c.baseURL+"-/npm/v1/attestations/"+escapePackageName(name)+"@"+version)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Attestations []Attestation `json:"attestations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to parse attestations: %w", err)
+	}
+	return body.Attestations, nil
+}
+
+// SigningKeys 获取registry的签名公钥，与npm audit signatures使用的接口相同
+func (c *Client) SigningKeys(ctx context.Context) ([]SigningKey, error) {
+	resp, err := c.get(ctx, c.baseURL+"-/npm/v1/keys")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Keys []SigningKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to parse signing keys: %w", err)
+	}
+	return body.Keys, nil
+}
@@ -0,0 +1,44 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAttestations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RawPath + r.URL.Path {
+		case "/-/npm/v1/attestations/@scope%2fpkg@1.0.0/-/npm/v1/attestations/@scope/pkg@1.0.0":
+			w.Write([]byte(`{"attestations":[{"predicateType":"https://slsa.dev/provenance/v1","bundle":{"mediaType":"application/vnd.dev.sigstore.bundle+json;version=0.2"}}]}`))
+		case "/-/npm/v1/keys":
+			w.Write([]byte(`{"keys":[{"expires":null,"keyid":"SHA256:abc","keytype":"ecdsa-sha2-nistp256","scheme":"ecdsa-sha2-nistp256","key":"MFkw"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+	attestations, err := client.Attestations(ctx, "@scope/pkg", "1.0.0")
+	if err != nil {
+		t.Fatalf("Attestations() failed: %v", err)
+	}
+	if len(attestations) != 1 || attestations[0].PredicateType != PredicateSLSAProvenanceV1 || len(attestations[0].Bundle) == 0 {
+		t.Errorf("Unexpected attestations: %+v", attestations)
+	}
+
+	keys, err := client.SigningKeys(ctx)
+	if err != nil {
+		t.Fatalf("SigningKeys() failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0].KeyID != "SHA256:abc" || keys[0].Expires != nil {
+		t.Errorf("Unexpected keys: %+v", keys)
+	}
+
+	if _, err := client.Attestations(ctx, "pkg", "1.0.0"); !IsNotFound(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
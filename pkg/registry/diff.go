@@ -0,0 +1,256 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// maxDiffUnpackedSize 比较时单个tarball解压后的大小上限，防止压缩炸弹
+const maxDiffUnpackedSize = 1 << 30
+
+// FileStatus 文件在两个版本间的变化
+type FileStatus string
+
+const (
+	FileAdded    FileStatus = "added"
+	FileRemoved  FileStatus = "removed"
+	FileModified FileStatus = "modified"
+)
+
+// FileChange 一个文件的变化，路径不含tarball的顶层目录（通常是package/）
+type FileChange struct {
+	Path    string     `json:"path"`
+	Status  FileStatus `json:"status"`
+	OldSize int64      `json:"old_size,omitempty"`
+	NewSize int64      `json:"new_size,omitempty"`
+	OldMode int64      `json:"old_mode,omitempty"` // 只在可执行权限变化时设置
+	NewMode int64      `json:"new_mode,omitempty"`
+}
+
+// DependencyChange 一个依赖的变化，From为空表示新增，To为空表示删除
+type DependencyChange struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // dependencies、devDependencies、optionalDependencies或peerDependencies
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// ScriptChange 一个脚本的变化，From为空表示新增，To为空表示删除
+type ScriptChange struct {
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// VersionDiff 包两个版本之间的差异
+type VersionDiff struct {
+	Name         string             `json:"name"`
+	From         string             `json:"from"`
+	To           string             `json:"to"`
+	Files        []FileChange       `json:"files"`        // 按路径排序
+	Dependencies []DependencyChange `json:"dependencies"` // 按类型和名称排序
+	Scripts      []ScriptChange     `json:"scripts"`      // 按名称排序
+}
+
+// Count 返回指定状态的文件数
+func (d *VersionDiff) Count(status FileStatus) int {
+	count := 0
+	for _, file := range d.Files {
+		if file.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// diffFile tarball中一个文件的摘要
+type diffFile struct {
+	size int64
+	mode int64
+	sum  [sha256.Size]byte
+}
+
+// diffVersion 一个版本的文件和package.json
+type diffVersion struct {
+	version string
+	files   map[string]diffFile
+	pkg     struct {
+		Scripts              map[string]string `json:"scripts"`
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+	}
+}
+
+// DiffVersions 下载包的两个版本的tarball，比较文件、依赖和脚本的变化
+//
+// v1、v2可以是版本号或dist-tag。依赖和脚本取自tarball中的package.json，
+// 文件内容通过SHA-256比较，可执行权限的变化也算作修改。
+func (c *Client) DiffVersions(ctx context.Context, pkg, v1, v2 string) (*VersionDiff, error) {
+	from, err := c.loadDiffVersion(ctx, pkg, v1)
+	if err != nil {
+		return nil, err
+	}
+	to, err := c.loadDiffVersion(ctx, pkg, v2)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &VersionDiff{
+		Name:         pkg,
+		From:         from.version,
+		To:           to.version,
+		Files:        []FileChange{},
+		Dependencies: []DependencyChange{},
+		Scripts:      []ScriptChange{},
+	}
+	for path, old := range from.files {
+		current, ok := to.files[path]
+		switch {
+		case !ok:
+			diff.Files = append(diff.Files, FileChange{Path: path, Status: FileRemoved, OldSize: old.size})
+		case current.sum != old.sum || current.mode != old.mode:
+			change := FileChange{Path: path, Status: FileModified, OldSize: old.size, NewSize: current.size}
+			if current.mode != old.mode {
+				change.OldMode, change.NewMode = old.mode, current.mode
+			}
+			diff.Files = append(diff.Files, change)
+		}
+	}
+	for path, current := range to.files {
+		if _, ok := from.files[path]; !ok {
+			diff.Files = append(diff.Files, FileChange{Path: path, Status: FileAdded, NewSize: current.size})
+		}
+	}
+	sort.Slice(diff.Files, func(i, j int) bool { return diff.Files[i].Path < diff.Files[j].Path })
+
+	depTypes := []struct {
+		name     string
+		from, to map[string]string
+	}{
+		{"dependencies", from.pkg.Dependencies, to.pkg.Dependencies},
+		{"devDependencies", from.pkg.DevDependencies, to.pkg.DevDependencies},
+		{"optionalDependencies", from.pkg.OptionalDependencies, to.pkg.OptionalDependencies},
+		{"peerDependencies", from.pkg.PeerDependencies, to.pkg.PeerDependencies},
+	}
+	for _, depType := range depTypes {
+		for _, name := range changedKeys(depType.from, depType.to) {
+			diff.Dependencies = append(diff.Dependencies, DependencyChange{
+				Name: name,
+				Type: depType.name,
+				From: depType.from[name],
+				To:   depType.to[name],
+			})
+		}
+	}
+	for _, name := range changedKeys(from.pkg.Scripts, to.pkg.Scripts) {
+		diff.Scripts = append(diff.Scripts, ScriptChange{Name: name, From: from.pkg.Scripts[name], To: to.pkg.Scripts[name]})
+	}
+	return diff, nil
+}
+
+// loadDiffVersion 获取版本的清单并下载、读取tarball
+func (c *Client) loadDiffVersion(ctx context.Context, pkg, spec string) (*diffVersion, error) {
+	manifest, err := c.GetManifest(ctx, pkg, spec)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Dist.Tarball == "" {
+		return nil, fmt.Errorf("%s@%s has no tarball", pkg, manifest.Version)
+	}
+	data, err := c.Download(ctx, manifest.Dist.Tarball)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &diffVersion{version: manifest.Version}
+	version.files, err = readDiffFiles(data, func(path string, content []byte) error {
+		if path != "package.json" {
+			return nil
+		}
+		if err := json.Unmarshal(content, &version.pkg); err != nil {
+			return fmt.Errorf("failed to parse package.json of %s@%s: %w", pkg, manifest.Version, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tarball of %s@%s: %w", pkg, manifest.Version, err)
+	}
+	return version, nil
+}
+
+// readDiffFiles 读取tarball中普通文件的摘要，路径去掉顶层目录，每个文件的内容交给fn
+func readDiffFiles(data []byte, fn func(path string, content []byte) error) (map[string]diffFile, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := make(map[string]diffFile)
+	var total int64
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+		_, path, ok := strings.Cut(strings.TrimPrefix(header.Name, "./"), "/")
+		if !ok || path == "" {
+			continue
+		}
+
+		content, err := io.ReadAll(io.LimitReader(reader, maxDiffUnpackedSize-total+1))
+		if err != nil {
+			return nil, err
+		}
+		total += int64(len(content))
+		if total > maxDiffUnpackedSize {
+			return nil, fmt.Errorf("unpacked size exceeds %d bytes", maxDiffUnpackedSize)
+		}
+		if err := fn(path, content); err != nil {
+			return nil, err
+		}
+
+		mode := int64(0644)
+		if header.Mode&0111 != 0 {
+			mode = 0755
+		}
+		// 同名文件以最后一个为准，与解压结果一致
+		files[path] = diffFile{size: int64(len(content)), mode: mode, sum: sha256.Sum256(content)}
+	}
+	return files, nil
+}
+
+// changedKeys 返回两个map中值不同的键，按字典序排列
+func changedKeys(from, to map[string]string) []string {
+	var keys []string
+	for key, value := range from {
+		if current, ok := to[key]; !ok || current != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
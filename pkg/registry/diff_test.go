@@ -0,0 +1,122 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// buildDiffTarball 构造tarball，files为路径（含顶层目录）到内容，mode为0755的路径在executable中
+func buildDiffTarball(t *testing.T, files map[string]string, executable ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	writer := tar.NewWriter(gz)
+	for name, content := range files {
+		mode := int64(0644)
+		for _, exe := range executable {
+			if exe == name {
+				mode = 0755
+			}
+		}
+		if err := writer.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: mode, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		writer.Write([]byte(content))
+	}
+	writer.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestDiffVersions(t *testing.T) {
+	tarballs := map[string][]byte{
+		"1.0.0": buildDiffTarball(t, map[string]string{
+			"package/package.json": `{"name":"demo","version":"1.0.0","scripts":{"test":"jest","build":"tsc"},"dependencies":{"lodash":"^4.17.0","left-pad":"^1.0.0"},"devDependencies":{"jest":"^29.0.0"}}`,
+			"package/index.js":     "module.exports = 1",
+			"package/bin/cli.js":   "#!/usr/bin/env node",
+			"package/old.js":       "removed",
+			"package/README.md":    "# demo",
+		}),
+		"2.0.0": buildDiffTarball(t, map[string]string{
+			"package/package.json": `{"name":"demo","version":"2.0.0","scripts":{"test":"vitest","postinstall":"node setup.js"},"dependencies":{"lodash":"^4.17.21"},"devDependencies":{"jest":"^29.0.0"},"peerDependencies":{"react":">=18"}}`,
+			"package/index.js":     "module.exports = 2",
+			"package/bin/cli.js":   "#!/usr/bin/env node",
+			"package/setup.js":     "added",
+			"package/README.md":    "# demo",
+		}, "package/bin/cli.js"),
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/demo/1.0.0", "/demo/2.0.0", "/demo/latest":
+			version := r.URL.Path[len("/demo/"):]
+			if version == "latest" {
+				version = "2.0.0"
+			}
+			fmt.Fprintf(w, `{"name":"demo","version":%q,"dist":{"tarball":"%s/demo-%s.tgz"}}`, version, server.URL, version)
+		case "/demo-1.0.0.tgz":
+			w.Write(tarballs["1.0.0"])
+		case "/demo-2.0.0.tgz":
+			w.Write(tarballs["2.0.0"])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	diff, err := NewClient(server.URL).DiffVersions(context.Background(), "demo", "1.0.0", "latest")
+	if err != nil {
+		t.Fatalf("DiffVersions() failed: %v", err)
+	}
+	if diff.From != "1.0.0" || diff.To != "2.0.0" {
+		t.Errorf("Expected 1.0.0 -> 2.0.0, got %s -> %s", diff.From, diff.To)
+	}
+
+	packageJSONSize := func(version string) int64 {
+		files, _ := readDiffFiles(tarballs[version], func(string, []byte) error { return nil })
+		return files["package.json"].size
+	}
+	expectedFiles := []FileChange{
+		{Path: "bin/cli.js", Status: FileModified, OldSize: 19, NewSize: 19, OldMode: 0644, NewMode: 0755},
+		{Path: "index.js", Status: FileModified, OldSize: 18, NewSize: 18},
+		{Path: "old.js", Status: FileRemoved, OldSize: 7},
+		{Path: "package.json", Status: FileModified, OldSize: packageJSONSize("1.0.0"), NewSize: packageJSONSize("2.0.0")},
+		{Path: "setup.js", Status: FileAdded, NewSize: 5},
+	}
+	if !reflect.DeepEqual(diff.Files, expectedFiles) {
+		t.Errorf("Unexpected files:\n%+v\nexpected:\n%+v", diff.Files, expectedFiles)
+	}
+	if diff.Count(FileModified) != 3 || diff.Count(FileAdded) != 1 || diff.Count(FileRemoved) != 1 {
+		t.Errorf("Unexpected counts for %+v", diff.Files)
+	}
+
+	expectedDeps := []DependencyChange{
+		{Name: "left-pad", Type: "dependencies", From: "^1.0.0"},
+		{Name: "lodash", Type: "dependencies", From: "^4.17.0", To: "^4.17.21"},
+		{Name: "react", Type: "peerDependencies", To: ">=18"},
+	}
+	if !reflect.DeepEqual(diff.Dependencies, expectedDeps) {
+		t.Errorf("Unexpected dependencies: %+v", diff.Dependencies)
+	}
+
+	expectedScripts := []ScriptChange{
+		{Name: "build", From: "tsc"},
+		{Name: "postinstall", To: "node setup.js"},
+		{Name: "test", From: "jest", To: "vitest"},
+	}
+	if !reflect.DeepEqual(diff.Scripts, expectedScripts) {
+		t.Errorf("Unexpected scripts: %+v", diff.Scripts)
+	}
+
+	if _, err := NewClient(server.URL).DiffVersions(context.Background(), "demo", "1.0.0", "3.0.0"); !IsNotFound(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Dist 版本的发布信息
+type Dist struct {
+	Tarball      string `json:"tarball"`
+	Shasum       string `json:"shasum"`
+	Integrity    string `json:"integrity,omitempty"`
+	FileCount    int    `json:"fileCount,omitempty"`
+	UnpackedSize int64  `json:"unpackedSize,omitempty"`
+
+	Attestations *DistAttestations `json:"attestations,omitempty"` // 版本的来源证明，没有时为nil
+}
+
+// Manifest 单个版本的清单
+type Manifest struct {
+	Name       string      `json:"name"`
+	Version    string      `json:"version"`
+	Deprecated Deprecation `json:"deprecated,omitempty"` // 弃用说明，为空表示未弃用
+	Dist       Dist        `json:"dist"`
+
+	Dependencies         map[string]string       `json:"dependencies,omitempty"`
+	OptionalDependencies map[string]string       `json:"optionalDependencies,omitempty"`
+	PeerDependencies     map[string]string       `json:"peerDependencies,omitempty"`
+	PeerDependenciesMeta map[string]PeerDepsMeta `json:"peerDependenciesMeta,omitempty"`
+}
+
+// PeerDepsMeta peerDependenciesMeta中一个依赖的设置
+type PeerDepsMeta struct {
+	Optional bool `json:"optional,omitempty"` // 可选的peer依赖，npm不会自动安装
+}
+
+// Deprecation 版本的弃用说明
+//
+// registry中个别版本以布尔值记录，false按未弃用处理，true没有说明文字。
+type Deprecation string
+
+// UnmarshalJSON 同时接受字符串和布尔值
+func (d *Deprecation) UnmarshalJSON(data []byte) error {
+	var deprecated bool
+	if err := json.Unmarshal(data, &deprecated); err == nil {
+		*d = ""
+		if deprecated {
+			*d = "deprecated"
+		}
+		return nil
+	}
+	return json.Unmarshal(data, (*string)(d))
+}
+
+// Packument 包的完整文档，包含所有版本
+type Packument struct {
+	Name     string              `json:"name"`
+	DistTags map[string]string   `json:"dist-tags"`
+	Versions map[string]Manifest `json:"versions"`
+	Time     map[string]string   `json:"time,omitempty"` // 各版本的发布时间，另有created和modified
+}
+
+// GetPackument 获取包的完整文档，解码所有版本
+//
+// 只需要版本列表或部分版本时使用GetPackumentWithOptions。
+func (c *Client) GetPackument(ctx context.Context, name string) (*Packument, error) {
+	return c.GetPackumentWithOptions(ctx, name, PackumentOptions{Versions: AllVersions})
+}
+
+// GetManifest 获取包某个版本的清单，spec可以是版本号或dist-tag
+func (c *Client) GetManifest(ctx context.Context, name, spec string) (*Manifest, error) {
+	if spec == "" {
+		spec = "latest"
+	}
+
+	resp, err := c.get(ctx, c.PackageURL(name)+"/"+url.PathEscape(spec))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// Download 下载tarball等文件
+func (c *Client) Download(ctx context.Context, fileURL string) ([]byte, error) {
+	resp, err := c.get(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
+
+// TarballSize 用HEAD请求获取tarball的大小，服务器没有返回Content-Length时为-1
+func (c *Client) TarballSize(ctx context.Context, tarballURL string) (int64, error) {
+	resp, err := c.do(ctx, http.MethodHead, tarballURL)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.ContentLength, nil
+}
+
+// get 发送GET请求，非2xx响应返回*Error
+func (c *Client) get(ctx context.Context, requestURL string) (*http.Response, error) {
+	return c.do(ctx, http.MethodGet, requestURL)
+}
+
+// do 发送不带请求体的请求，非2xx响应返回*Error
+func (c *Client) do(ctx context.Context, method, requestURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, responseError(resp)
+	}
+	return resp, nil
+}
+
+// IsNotFound 判断错误是否为registry返回的404
+func IsNotFound(err error) bool {
+	var registryErr *Error
+	return errors.As(err, &registryErr) && registryErr.StatusCode == http.StatusNotFound
+}
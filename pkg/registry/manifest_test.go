@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetManifest(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RawPath)
+		switch r.URL.Path {
+		case "/@scope/pkg/latest", "/@scope/pkg/1.0.0":
+			w.Write([]byte(`{"name":"@scope/pkg","version":"1.0.0","dist":{"tarball":"https://example.com/pkg.tgz","shasum":"abc","fileCount":3,"unpackedSize":1234},"dependencies":{"a":"^1.0.0"},"peerDependencies":{"react":"*"},"peerDependenciesMeta":{"react":{"optional":true}}}`))
+		case "/@scope/pkg":
+			w.Write([]byte(`{"name":"@scope/pkg","dist-tags":{"latest":"1.0.0"},"versions":{"0.9.0":{"name":"@scope/pkg","version":"0.9.0"},"1.0.0":{"name":"@scope/pkg","version":"1.0.0"}},"time":{"1.0.0":"2024-01-01T00:00:00.000Z"}}`))
+		case "/pkg.tgz":
+			w.Write([]byte("tarball"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	manifest, err := client.GetManifest(context.Background(), "@scope/pkg", "")
+	if err != nil {
+		t.Fatalf("GetManifest() failed: %v", err)
+	}
+	if manifest.Version != "1.0.0" || manifest.Dist.FileCount != 3 || manifest.Dist.UnpackedSize != 1234 || manifest.Dependencies["a"] != "^1.0.0" || !manifest.PeerDependenciesMeta["react"].Optional {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+	if len(paths) != 1 || paths[0] != "/@scope%2fpkg/latest" {
+		t.Errorf("Unexpected request paths: %v", paths)
+	}
+
+	if _, err := client.GetManifest(context.Background(), "missing", "1.0.0"); !IsNotFound(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	packument, err := client.GetPackument(context.Background(), "@scope/pkg")
+	if err != nil {
+		t.Fatalf("GetPackument() failed: %v", err)
+	}
+	if packument.DistTags["latest"] != "1.0.0" || len(packument.Versions) != 2 || packument.Versions["0.9.0"].Version != "0.9.0" || packument.Time["1.0.0"] == "" {
+		t.Errorf("Unexpected packument: %+v", packument)
+	}
+
+	data, err := client.Download(context.Background(), server.URL+"/pkg.tgz")
+	if err != nil || string(data) != "tarball" {
+		t.Errorf("Download() = %q, %v", data, err)
+	}
+	if _, err := client.Download(context.Background(), server.URL+"/missing.tgz"); !IsNotFound(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	if size, err := client.TarballSize(context.Background(), server.URL+"/pkg.tgz"); err != nil || size != int64(len("tarball")) {
+		t.Errorf("TarballSize() = %d, %v", size, err)
+	}
+	if _, err := client.TarballSize(context.Background(), server.URL+"/missing.tgz"); !IsNotFound(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestDeprecationUnmarshal(t *testing.T) {
+	tests := map[string]Deprecation{
+		`{"deprecated":"use bar instead"}`: "use bar instead",
+		`{"deprecated":false}`:             "",
+		`{"deprecated":true}`:              "deprecated",
+		`{}`:                               "",
+	}
+	for input, expected := range tests {
+		var manifest Manifest
+		if err := json.Unmarshal([]byte(input), &manifest); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", input, err)
+		}
+		if manifest.Deprecated != expected {
+			t.Errorf("Unmarshal(%s) deprecated = %q, expected %q", input, manifest.Deprecated, expected)
+		}
+	}
+}
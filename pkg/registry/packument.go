@@ -0,0 +1,488 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/parse"
+	"github.com/scagogogo/go-npm-sdk/pkg/semver"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// PackumentOptions 解码packument的选项
+type PackumentOptions struct {
+	// 选择需要完整解码的版本，nil表示都不解码
+	//
+	// 未解码的版本仍然出现在Packument.Versions中，但只有Name和Version，
+	// 它们在输入中的内容直接跳过，不占用内存。
+	Versions func(version string) bool
+}
+
+// AllVersions 解码所有版本
+func AllVersions(string) bool {
+	return true
+}
+
+// VersionsIn 只解码列出的版本
+func VersionsIn(versions ...string) func(string) bool {
+	set := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		set[version] = true
+	}
+	return func(version string) bool {
+		return set[version]
+	}
+}
+
+// VersionsSatisfying 只解码满足semver范围的版本
+func VersionsSatisfying(rng string) func(string) bool {
+	return func(version string) bool {
+		return semver.Satisfies(version, rng)
+	}
+}
+
+// GetPackumentWithOptions 获取包的文档，按options决定解码哪些版本
+//
+// lodash、react等包的文档有数MB，绝大部分是各版本的清单，批量分析时
+// 通常只需要版本列表、dist-tags和少数版本的清单。
+func (c *Client) GetPackumentWithOptions(ctx context.Context, name string, options PackumentOptions) (*Packument, error) {
+	resp, err := c.get(ctx, c.PackageURL(name))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	packument, err := DecodePackument(resp.Body, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse packument: %w", err)
+	}
+	return packument, nil
+}
+
+// DecodePackument 流式解码packument
+//
+// 只保留name、dist-tags、time和选中版本的清单，其余内容（readme、未选中的版本等）
+// 边读边跳过。跳过的内容只检查括号和字符串是否配对，不做完整的JSON校验。
+// 输入大小和嵌套深度受parse.DefaultLimits限制，错误为带位置信息的*utils.ParseError。
+func DecodePackument(r io.Reader, options PackumentOptions) (*Packument, error) {
+	s := newJSONScanner(r, "packument")
+	packument := &Packument{}
+
+	err := s.object(1, func(key string) error {
+		switch key {
+		case "name":
+			return s.decodeValue(1, &packument.Name)
+		case "dist-tags":
+			return s.decodeValue(1, &packument.DistTags)
+		case "time":
+			return s.decodeValue(1, &packument.Time)
+		case "versions":
+			return s.decodeVersions(packument, options)
+		default:
+			return s.skipValue(1)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.end(); err != nil {
+		return nil, err
+	}
+
+	for version, manifest := range packument.Versions {
+		if manifest.Name == "" && manifest.Version == version && manifest.Dist == (Dist{}) {
+			manifest.Name = packument.Name
+			packument.Versions[version] = manifest
+		}
+	}
+	return packument, nil
+}
+
+// decodeVersions 解码versions对象
+func (s *jsonScanner) decodeVersions(packument *Packument, options PackumentOptions) error {
+	c, err := s.peek()
+	if err != nil {
+		return err
+	}
+	if c == 'n' {
+		packument.Versions = nil
+		return s.skipValue(1)
+	}
+	if packument.Versions == nil {
+		packument.Versions = make(map[string]Manifest)
+	}
+
+	return s.object(2, func(version string) error {
+		if options.Versions == nil || !options.Versions(version) {
+			packument.Versions[version] = Manifest{Version: version}
+			return s.skipValue(2)
+		}
+		manifest := packument.Versions[version]
+		if err := s.decodeValue(2, &manifest); err != nil {
+			return err
+		}
+		packument.Versions[version] = manifest
+		return nil
+	})
+}
+
+// jsonScanner 逐字节读取JSON，用于跳过不需要的值
+type jsonScanner struct {
+	r       *bufio.Reader
+	source  string
+	offset  int64
+	line    int
+	column  int
+	buf     []byte // 正在截取的值，capture为false时不使用
+	capture bool
+}
+
+// newJSONScanner 创建扫描器
+func newJSONScanner(r io.Reader, source string) *jsonScanner {
+	return &jsonScanner{r: bufio.NewReaderSize(r, 64*1024), source: source, line: 1, column: 1}
+}
+
+// errorf 在当前位置创建解析错误
+func (s *jsonScanner) errorf(err error) error {
+	return &utils.ParseError{Source: s.source, Offset: s.offset, Line: s.line, Column: s.column, Err: err}
+}
+
+// readByte 读取一个字节并更新位置
+func (s *jsonScanner) readByte() (byte, error) {
+	if limit := parse.DefaultLimits.MaxSize; limit > 0 && s.offset >= int64(limit) {
+		return 0, s.errorf(fmt.Errorf("%w: exceeds limit of %d bytes", utils.ErrInputTooLarge, limit))
+	}
+	c, err := s.r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, s.errorf(err)
+	}
+	s.offset++
+	if c == '\n' {
+		s.line++
+		s.column = 1
+	} else {
+		s.column++
+	}
+	if s.capture {
+		s.buf = append(s.buf, c)
+	}
+	return c, nil
+}
+
+// peek 跳过空白并返回下一个字节，不读取该字节
+func (s *jsonScanner) peek() (byte, error) {
+	for {
+		next, err := s.r.Peek(1)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, s.errorf(err)
+		}
+		switch next[0] {
+		case ' ', '\t', '\r', '\n':
+			if _, err := s.readByte(); err != nil {
+				return 0, err
+			}
+		default:
+			return next[0], nil
+		}
+	}
+}
+
+// expect 跳过空白并读取指定字节
+func (s *jsonScanner) expect(want byte) error {
+	c, err := s.peek()
+	if err != nil {
+		return err
+	}
+	if c != want {
+		return s.errorf(fmt.Errorf("expected %q, got %q", want, c))
+	}
+	_, err = s.readByte()
+	return err
+}
+
+// end 检查输入在值之后只有空白
+func (s *jsonScanner) end() error {
+	_, err := s.peek()
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.errorf(fmt.Errorf("unexpected data after top-level value"))
+}
+
+// checkDepth 检查嵌套深度
+func (s *jsonScanner) checkDepth(depth int) error {
+	if limit := parse.DefaultLimits.MaxDepth; limit > 0 && depth > limit {
+		return s.errorf(fmt.Errorf("%w: exceeds limit of %d", utils.ErrNestingTooDeep, limit))
+	}
+	return nil
+}
+
+// object 遍历对象，field读取键对应的值，depth为对象的嵌套深度
+func (s *jsonScanner) object(depth int, field func(key string) error) error {
+	if err := s.checkDepth(depth); err != nil {
+		return err
+	}
+	if err := s.expect('{'); err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		c, err := s.peek()
+		if err != nil {
+			return err
+		}
+		if c == '}' {
+			_, err := s.readByte()
+			return err
+		}
+		if !first {
+			if err := s.expect(','); err != nil {
+				return err
+			}
+		}
+
+		key, err := s.readKey()
+		if err != nil {
+			return err
+		}
+		if err := field(key); err != nil {
+			return err
+		}
+	}
+}
+
+// readKey 读取对象的键和冒号
+func (s *jsonScanner) readKey() (string, error) {
+	c, err := s.peek()
+	if err != nil {
+		return "", err
+	}
+	if c != '"' {
+		return "", s.errorf(fmt.Errorf("expected object key, got %q", c))
+	}
+
+	line, column, offset := s.line, s.column, s.offset
+	s.capture, s.buf = true, s.buf[:0]
+	err = s.skipString()
+	s.capture = false
+	if err != nil {
+		return "", err
+	}
+
+	var key string
+	if err := json.Unmarshal(s.buf, &key); err != nil {
+		return "", &utils.ParseError{Source: s.source, Offset: offset, Line: line, Column: column, Err: err}
+	}
+	if err := s.expect(':'); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// decodeValue 截取下一个值并解码到v
+func (s *jsonScanner) decodeValue(depth int, v interface{}) error {
+	if _, err := s.peek(); err != nil {
+		return err
+	}
+
+	line, column, offset := s.line, s.column, s.offset
+	s.capture, s.buf = true, s.buf[:0]
+	err := s.skipValue(depth)
+	s.capture = false
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(s.buf, v); err != nil {
+		return &utils.ParseError{Source: s.source, Offset: offset, Line: line, Column: column, Err: err}
+	}
+	return nil
+}
+
+// skipValue 跳过下一个值，depth为值所在容器的嵌套深度
+func (s *jsonScanner) skipValue(depth int) error {
+	c, err := s.peek()
+	if err != nil {
+		return err
+	}
+
+	switch c {
+	case '"':
+		return s.skipString()
+	case '{', '[':
+		return s.skipContainer(depth + 1)
+	case '}', ']', ',', ':':
+		return s.errorf(fmt.Errorf("unexpected %q", c))
+	}
+
+	// 数字、true、false、null
+	if _, err := s.readByte(); err != nil {
+		return err
+	}
+	for {
+		next, err := s.r.Peek(1)
+		if err != nil || !isLiteralByte(next[0]) {
+			return nil
+		}
+		if _, err := s.readByte(); err != nil {
+			return err
+		}
+	}
+}
+
+// skipString 跳过字符串，当前字节必须是开头的引号
+func (s *jsonScanner) skipString() error {
+	if _, err := s.readByte(); err != nil {
+		return err
+	}
+	for {
+		// 批量跳过普通字符
+		if err := s.skipWhile(&plainStringBytes); err != nil {
+			return err
+		}
+		c, err := s.readByte()
+		if err != nil {
+			return err
+		}
+		switch {
+		case c == '"':
+			return nil
+		case c == '\\':
+			if _, err := s.readByte(); err != nil {
+				return err
+			}
+		default:
+			return s.errorf(fmt.Errorf("invalid character %q in string", c))
+		}
+	}
+}
+
+// skipContainer 跳过对象或数组，只检查括号配对
+func (s *jsonScanner) skipContainer(depth int) error {
+	if err := s.checkDepth(depth); err != nil {
+		return err
+	}
+	open, err := s.readByte()
+	if err != nil {
+		return err
+	}
+
+	var stack [32]byte
+	closers := append(stack[:0], closerOf(open))
+	for len(closers) > 0 {
+		if err := s.skipWhile(&plainContainerBytes); err != nil {
+			return err
+		}
+		c, err := s.peek()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case '"':
+			if err := s.skipString(); err != nil {
+				return err
+			}
+			continue
+		case '{', '[':
+			if err := s.checkDepth(depth + len(closers)); err != nil {
+				return err
+			}
+			closers = append(closers, closerOf(c))
+		case '}', ']':
+			if c != closers[len(closers)-1] {
+				return s.errorf(fmt.Errorf("unexpected %q", c))
+			}
+			closers = closers[:len(closers)-1]
+		}
+		if _, err := s.readByte(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipWhile 跳过缓冲区中连续满足plain的字节，直接在缓冲区上扫描以避免逐字节调用
+func (s *jsonScanner) skipWhile(plain *[256]bool) error {
+	for {
+		if s.r.Buffered() == 0 {
+			if _, err := s.r.Peek(1); err != nil {
+				// 读取错误由调用方的下一次读取报告
+				return nil
+			}
+		}
+		buf, _ := s.r.Peek(s.r.Buffered())
+		n := 0
+		for n < len(buf) && plain[buf[n]] {
+			n++
+		}
+		if limit := parse.DefaultLimits.MaxSize; limit > 0 && s.offset+int64(n) > int64(limit) {
+			n = int(int64(limit) - s.offset)
+		}
+		if n == 0 {
+			return nil
+		}
+
+		chunk := buf[:n]
+		if lines := bytes.Count(chunk, []byte{'\n'}); lines > 0 {
+			s.line += lines
+			s.column = n - bytes.LastIndexByte(chunk, '\n')
+		} else {
+			s.column += n
+		}
+		s.offset += int64(n)
+		if s.capture {
+			s.buf = append(s.buf, chunk...)
+		}
+		if _, err := s.r.Discard(n); err != nil {
+			return s.errorf(err)
+		}
+		if n < len(buf) {
+			return nil
+		}
+	}
+}
+
+// plainStringBytes 字符串中无需特殊处理的字节
+var plainStringBytes = byteTable(func(c byte) bool {
+	return c != '"' && c != '\\' && c >= 0x20
+})
+
+// plainContainerBytes 跳过容器时无需特殊处理的字节
+var plainContainerBytes = byteTable(func(c byte) bool {
+	return c != '"' && c != '{' && c != '}' && c != '[' && c != ']'
+})
+
+// byteTable 生成字节查找表
+func byteTable(match func(byte) bool) [256]bool {
+	var table [256]bool
+	for i := range table {
+		table[i] = match(byte(i))
+	}
+	return table
+}
+
+// closerOf 返回开括号对应的闭括号
+func closerOf(open byte) byte {
+	if open == '{' {
+		return '}'
+	}
+	return ']'
+}
+
+// isLiteralByte 检查是否为数字或字面量中的字符
+func isLiteralByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-' || c == '+' || c == '.'
+}
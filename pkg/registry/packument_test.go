@@ -0,0 +1,256 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/parse"
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// testPackument 生成包含count个版本的packument，结构与registry返回的一致
+func testPackument(count int) []byte {
+	var b strings.Builder
+	b.WriteString(`{"_id": "demo", "_rev": "12-abc", "name": "demo", "description": "a \"quoted\" {description}",`)
+	b.WriteString(`"dist-tags": {"latest": "1.` + fmt.Sprint(count-1) + `.0", "next": "2.0.0-beta.1"},`)
+	b.WriteString(`"versions": {`)
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `
+  "1.%d.0": {
+    "name": "demo", "version": "1.%d.0",
+    "dependencies": {"a": "^1.0.0", "b": "~2.%d.0"},
+    "scripts": {"test": "echo \"}]\" && exit 1"},
+    "contributors": [{"name": "x"}, {"name": "y", "urls": [[], [{}]]}],
+    "deprecated": false, "_hasShrinkwrap": false, "size": 1.5e3,
+    "dist": {"tarball": "https://registry.npmjs.org/demo/-/demo-1.%d.0.tgz", "shasum": "s%d", "integrity": "sha512-%d", "fileCount": %d, "unpackedSize": %d}
+  }`, i, i, i, i, i, i, i+1, (i+1)*1000)
+	}
+	b.WriteString(`},
+"time": {"created": "2020-01-01T00:00:00.000Z", "1.0.0": "2020-01-01T00:00:00.000Z"},
+"readme": "` + strings.Repeat(`# demo\n\n{[\"not json\"]}`, 100) + `",
+"users": {"someone": true}, "maintainers": [{"name": "demo", "email": "demo@example.com"}]
+}`)
+	return []byte(b.String())
+}
+
+func TestDecodePackument(t *testing.T) {
+	data := testPackument(50)
+
+	var expected Packument
+	if err := json.Unmarshal(data, &expected); err != nil {
+		t.Fatalf("Invalid test packument: %v", err)
+	}
+	packument, err := DecodePackument(bytes.NewReader(data), PackumentOptions{Versions: AllVersions})
+	if err != nil {
+		t.Fatalf("DecodePackument() failed: %v", err)
+	}
+	if !reflect.DeepEqual(*packument, expected) {
+		t.Errorf("Expected result to match encoding/json\ngot:  %+v\nwant: %+v", *packument, expected)
+	}
+
+	// 不解码版本内容
+	packument, err = DecodePackument(bytes.NewReader(data), PackumentOptions{})
+	if err != nil {
+		t.Fatalf("DecodePackument() failed: %v", err)
+	}
+	if packument.Name != "demo" || packument.DistTags["latest"] != "1.49.0" || packument.Time["created"] == "" {
+		t.Errorf("Unexpected top-level fields: %+v", packument)
+	}
+	if len(packument.Versions) != 50 {
+		t.Fatalf("Expected all 50 versions to be listed, got %d", len(packument.Versions))
+	}
+	if manifest := packument.Versions["1.10.0"]; !reflect.DeepEqual(manifest, Manifest{Name: "demo", Version: "1.10.0"}) {
+		t.Errorf("Expected version stub without dist, got %+v", manifest)
+	}
+
+	// 只解码部分版本
+	tests := []struct {
+		name     string
+		versions func(string) bool
+		decoded  []string
+	}{
+		{"list", VersionsIn("1.3.0", "1.7.0", "9.9.9"), []string{"1.3.0", "1.7.0"}},
+		{"range", VersionsSatisfying(">=1.47.0"), []string{"1.47.0", "1.48.0", "1.49.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packument, err := DecodePackument(bytes.NewReader(data), PackumentOptions{Versions: tt.versions})
+			if err != nil {
+				t.Fatalf("DecodePackument() failed: %v", err)
+			}
+			var decoded []string
+			for version, manifest := range packument.Versions {
+				if manifest.Dist.Tarball != "" {
+					decoded = append(decoded, version)
+					if !reflect.DeepEqual(manifest, expected.Versions[version]) {
+						t.Errorf("Version %s = %+v, expected %+v", version, manifest, expected.Versions[version])
+					}
+				}
+			}
+			if len(decoded) != len(tt.decoded) {
+				t.Errorf("Expected decoded versions %v, got %v", tt.decoded, decoded)
+			}
+		})
+	}
+}
+
+func TestDecodePackumentEdgeCases(t *testing.T) {
+	packument, err := DecodePackument(strings.NewReader(` {"versions": null, "name": "x"} `+"\n"), PackumentOptions{Versions: AllVersions})
+	if err != nil || packument.Versions != nil || packument.Name != "x" {
+		t.Errorf("DecodePackument() = %+v, %v", packument, err)
+	}
+
+	// 版本内容在name之前
+	packument, err = DecodePackument(strings.NewReader(`{"versions": {"1.0.0": {}}, "name": "late"}`), PackumentOptions{})
+	if err != nil || packument.Versions["1.0.0"].Name != "late" {
+		t.Errorf("Expected stub to get the package name, got %+v, %v", packument, err)
+	}
+
+	// 键中的转义字符
+	packument, err = DecodePackument(strings.NewReader(`{"dist\u002dtags": {"latest": "1.0.0"}, "na\u006de": "escaped"}`), PackumentOptions{})
+	if err != nil || packument.Name != "escaped" || packument.DistTags["latest"] != "1.0.0" {
+		t.Errorf("DecodePackument() = %+v, %v", packument, err)
+	}
+}
+
+func TestDecodePackumentErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		line   int
+		column int
+	}{
+		{"not an object", `[]`, 1, 1},
+		{"unterminated", "{\"name\": \"demo\",\n\"readme\": \"abc", 2, 15},
+		{"mismatched brackets", "{\"readme\": {\"a\": [1, 2}}", 1, 23},
+		{"missing colon", `{"name" "demo"}`, 1, 9},
+		{"missing comma", `{"name": "demo" "x": 1}`, 1, 17},
+		{"trailing data", `{"name": "demo"} {}`, 1, 18},
+		{"wrong type", "{\n  \"name\": 1}", 2, 11},
+		{"invalid version", `{"versions": {"1.0.0": {"dist": []}}}`, 1, 24},
+		{"control character", "{\"readme\": \"a\nb\"}", 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodePackument(strings.NewReader(tt.data), PackumentOptions{Versions: AllVersions})
+			var parseErr *utils.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected *utils.ParseError, got %v", err)
+			}
+			if parseErr.Source != "packument" || parseErr.Line != tt.line || parseErr.Column != tt.column {
+				t.Errorf("Expected error at %d:%d, got %+v", tt.line, tt.column, parseErr)
+			}
+		})
+	}
+}
+
+func TestDecodePackumentLimits(t *testing.T) {
+	limits := parse.DefaultLimits
+	defer func() { parse.DefaultLimits = limits }()
+
+	parse.DefaultLimits = parse.Limits{MaxDepth: 3}
+	if _, err := DecodePackument(strings.NewReader(`{"readme": [[["x"]]]}`), PackumentOptions{}); !errors.Is(err, utils.ErrNestingTooDeep) {
+		t.Errorf("Expected ErrNestingTooDeep, got %v", err)
+	}
+	if _, err := DecodePackument(strings.NewReader(`{"readme": [["x"]]}`), PackumentOptions{}); err != nil {
+		t.Errorf("Expected depth within limit to be accepted, got %v", err)
+	}
+
+	parse.DefaultLimits = parse.Limits{MaxSize: 16}
+	if _, err := DecodePackument(strings.NewReader(`{"name": "demo"}`), PackumentOptions{}); err != nil {
+		t.Errorf("Expected input of exactly MaxSize bytes to be accepted, got %v", err)
+	}
+	if _, err := DecodePackument(strings.NewReader(`{"name": "demo!"}`), PackumentOptions{}); !errors.Is(err, utils.ErrInputTooLarge) {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+}
+
+func TestGetPackumentWithOptions(t *testing.T) {
+	data := testPackument(20)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	packument, err := client.GetPackumentWithOptions(context.Background(), "demo", PackumentOptions{Versions: VersionsIn("1.19.0")})
+	if err != nil {
+		t.Fatalf("GetPackumentWithOptions() failed: %v", err)
+	}
+	if len(packument.Versions) != 20 || packument.Versions["1.19.0"].Dist.FileCount != 20 || packument.Versions["1.18.0"].Dist.Tarball != "" {
+		t.Errorf("Unexpected packument: %+v", packument.Versions["1.19.0"])
+	}
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": `))
+	})
+	var parseErr *utils.ParseError
+	if _, err := client.GetPackument(context.Background(), "demo"); !errors.As(err, &parseErr) {
+		t.Errorf("Expected parse error for truncated response, got %v", err)
+	}
+}
+
+func FuzzDecodePackument(f *testing.F) {
+	f.Add(testPackument(2))
+	f.Add([]byte(`{"versions": {"1.0.0": {"name": "a", "dist": {"tarball": "t"}}}, "time": {}}`))
+	f.Add([]byte(`{"readme": "\\\"", "versions": null}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, options := range []PackumentOptions{{}, {Versions: AllVersions}} {
+			packument, err := DecodePackument(bytes.NewReader(data), options)
+			if err != nil {
+				var parseErr *utils.ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("Expected *utils.ParseError, got %T: %v", err, err)
+				}
+				if parseErr.Offset < 0 || parseErr.Offset > int64(len(data)) {
+					t.Fatalf("Error offset %d out of range", parseErr.Offset)
+				}
+				continue
+			}
+			if packument == nil {
+				t.Fatal("Expected packument without error")
+			}
+		}
+	})
+}
+
+func BenchmarkDecodePackument(b *testing.B) {
+	data := testPackument(500)
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var packument Packument
+			if err := json.NewDecoder(bytes.NewReader(data)).Decode(&packument); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("all versions", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := DecodePackument(bytes.NewReader(data), PackumentOptions{Versions: AllVersions}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("versions skipped", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := DecodePackument(bytes.NewReader(data), PackumentOptions{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
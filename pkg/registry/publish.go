@@ -0,0 +1,203 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ProgressFunc 上传进度回调，sent等于total时请求体已全部发送，正在等待registry处理
+type ProgressFunc func(sent, total int64)
+
+// PublishRequest 发布请求
+type PublishRequest struct {
+	Manifest         []byte       `json:"-"`                 // tarball中的package.json
+	Tarball          []byte       `json:"-"`                 // 包的tarball
+	Tag              string       `json:"tag,omitempty"`     // dist-tag，默认latest
+	Access           string       `json:"access,omitempty"`  // public或restricted，仅对scope包有效
+	OTP              string       `json:"otp,omitempty"`     // 一次性密码，通过npm-otp头发送
+	ProvenanceBundle []byte       `json:"-"`                 // sigstore来源证明bundle
+	DryRun           bool         `json:"dry_run,omitempty"` // 只构造发布文档，不发送请求
+	Progress         ProgressFunc `json:"-"`
+}
+
+// PublishResponse 发布结果
+type PublishResponse struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	ID         string `json:"id"`      // name@version
+	Tarball    string `json:"tarball"` // dist.tarball地址
+	Shasum     string `json:"shasum"`
+	Integrity  string `json:"integrity"`
+	BodySize   int64  `json:"body_size"` // 请求体大小
+	StatusCode int    `json:"status_code"`
+}
+
+// attachment 发布文档中的附件
+type attachment struct {
+	ContentType string `json:"content_type"`
+	Data        string `json:"data"`
+	Length      int    `json:"length"`
+}
+
+// Publish 通过PUT请求直接发布包，文档格式与npm CLI（libnpmpublish）一致
+func (c *Client) Publish(ctx context.Context, req PublishRequest) (*PublishResponse, error) {
+	body, response, err := c.buildPublishBody(req)
+	if err != nil {
+		return nil, err
+	}
+	response.BodySize = int64(len(body))
+	if req.DryRun {
+		return response, nil
+	}
+
+	var reader io.Reader = bytes.NewReader(body)
+	if req.Progress != nil {
+		reader = &progressReader{reader: reader, total: int64(len(body)), callback: req.Progress}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, c.PackageURL(response.Name), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.ContentLength = int64(len(body))
+	c.setHeaders(httpReq)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("npm-command", "publish")
+	if req.OTP != "" {
+		httpReq.Header.Set("npm-otp", req.OTP)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, responseError(resp)
+	}
+	io.Copy(io.Discard, resp.Body)
+
+	response.StatusCode = resp.StatusCode
+	return response, nil
+}
+
+// buildPublishBody 构造发布文档
+func (c *Client) buildPublishBody(req PublishRequest) ([]byte, *PublishResponse, error) {
+	if len(req.Tarball) == 0 {
+		return nil, nil, fmt.Errorf("tarball is empty")
+	}
+
+	var manifest map[string]interface{}
+	if err := json.Unmarshal(req.Manifest, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	name, _ := manifest["name"].(string)
+	version, _ := manifest["version"].(string)
+	if name == "" || version == "" {
+		return nil, nil, fmt.Errorf("manifest must have a name and version")
+	}
+
+	tag := req.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+
+	sha1Sum := sha1.Sum(req.Tarball)
+	sha512Sum := sha512.Sum512(req.Tarball)
+	tarballName := fmt.Sprintf("%s-%s.tgz", name, version)
+	tarballURL, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid registry URL: %w", err)
+	}
+	tarballURL = tarballURL.ResolveReference(&url.URL{Path: name + "/-/" + tarballName})
+	// npm固定以http记录tarball地址，registry读取时会改写
+	tarballURL.Scheme = "http"
+
+	response := &PublishResponse{
+		Name:      name,
+		Version:   version,
+		ID:        name + "@" + version,
+		Tarball:   tarballURL.String(),
+		Shasum:    hex.EncodeToString(sha1Sum[:]),
+		Integrity: "sha512-" + base64.StdEncoding.EncodeToString(sha512Sum[:]),
+	}
+
+	manifest["_id"] = response.ID
+	manifest["dist"] = map[string]interface{}{
+		"shasum":    response.Shasum,
+		"integrity": response.Integrity,
+		"tarball":   response.Tarball,
+	}
+
+	attachments := map[string]attachment{
+		tarballName: {
+			ContentType: "application/octet-stream",
+			Data:        base64.StdEncoding.EncodeToString(req.Tarball),
+			Length:      len(req.Tarball),
+		},
+	}
+	if len(req.ProvenanceBundle) > 0 {
+		var bundle struct {
+			MediaType string `json:"mediaType"`
+		}
+		if err := json.Unmarshal(req.ProvenanceBundle, &bundle); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse provenance bundle: %w", err)
+		}
+		if bundle.MediaType == "" {
+			bundle.MediaType = "application/vnd.dev.sigstore.bundle+json;version=0.2"
+		}
+		attachments[fmt.Sprintf("%s-%s.sigstore", name, version)] = attachment{
+			ContentType: bundle.MediaType,
+			Data:        string(req.ProvenanceBundle),
+			Length:      len(req.ProvenanceBundle),
+		}
+	}
+
+	document := map[string]interface{}{
+		"_id":          name,
+		"name":         name,
+		"dist-tags":    map[string]string{tag: version},
+		"versions":     map[string]interface{}{version: manifest},
+		"access":       nil,
+		"_attachments": attachments,
+	}
+	if description, ok := manifest["description"]; ok {
+		document["description"] = description
+	}
+	if req.Access != "" {
+		document["access"] = req.Access
+	}
+
+	body, err := json.Marshal(document)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode publish document: %w", err)
+	}
+	return body, response, nil
+}
+
+// progressReader 统计已发送字节数的读取器
+type progressReader struct {
+	reader   io.Reader
+	total    int64
+	sent     int64
+	callback ProgressFunc
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.reader.Read(p)
+	if n > 0 {
+		pr.sent += int64(n)
+		pr.callback(pr.sent, pr.total)
+	}
+	return n, err
+}
@@ -0,0 +1,147 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// publishDocument 测试中解析的发布文档
+type publishDocument struct {
+	ID          string                            `json:"_id"`
+	Name        string                            `json:"name"`
+	DistTags    map[string]string                 `json:"dist-tags"`
+	Access      *string                           `json:"access"`
+	Versions    map[string]map[string]interface{} `json:"versions"`
+	Attachments map[string]attachment             `json:"_attachments"`
+}
+
+func TestPublish(t *testing.T) {
+	tarball := []byte("fake tarball contents")
+	var document publishDocument
+	var requestURI string
+	var headers http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		headers = r.Header
+		if r.Method != http.MethodPut {
+			t.Errorf("Expected PUT, got %s", r.Method)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&document); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetToken("secret")
+
+	var progress [][2]int64
+	response, err := client.Publish(context.Background(), PublishRequest{
+		Manifest:         []byte(`{"name":"@scope/pkg","version":"1.2.0","description":"test"}`),
+		Tarball:          tarball,
+		Tag:              "next",
+		Access:           "public",
+		OTP:              "123456",
+		ProvenanceBundle: []byte(`{"mediaType":"application/vnd.dev.sigstore.bundle.v0.3+json"}`),
+		Progress: func(sent, total int64) {
+			progress = append(progress, [2]int64{sent, total})
+		},
+	})
+	if err != nil {
+		t.Fatalf("Publish() failed: %v", err)
+	}
+
+	if requestURI != "/@scope%2fpkg" {
+		t.Errorf("Request URI = %s, expected /@scope%%2fpkg", requestURI)
+	}
+	if headers.Get("Authorization") != "Bearer secret" || headers.Get("npm-otp") != "123456" || headers.Get("npm-command") != "publish" {
+		t.Errorf("Unexpected headers: %v", headers)
+	}
+
+	if response.ID != "@scope/pkg@1.2.0" || response.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	if response.Tarball != "http://"+server.Listener.Addr().String()+"/@scope/pkg/-/@scope/pkg-1.2.0.tgz" {
+		t.Errorf("Unexpected tarball URL: %s", response.Tarball)
+	}
+
+	if document.ID != "@scope/pkg" || document.DistTags["next"] != "1.2.0" || document.Access == nil || *document.Access != "public" {
+		t.Errorf("Unexpected document: %+v", document)
+	}
+	dist, _ := document.Versions["1.2.0"]["dist"].(map[string]interface{})
+	if dist["integrity"] != response.Integrity || dist["shasum"] != response.Shasum {
+		t.Errorf("Unexpected dist: %v", dist)
+	}
+
+	tarballAttachment := document.Attachments["@scope/pkg-1.2.0.tgz"]
+	if data, _ := base64.StdEncoding.DecodeString(tarballAttachment.Data); string(data) != string(tarball) || tarballAttachment.Length != len(tarball) {
+		t.Errorf("Unexpected tarball attachment: %+v", tarballAttachment)
+	}
+	if provenance := document.Attachments["@scope/pkg-1.2.0.sigstore"]; provenance.ContentType != "application/vnd.dev.sigstore.bundle.v0.3+json" {
+		t.Errorf("Unexpected provenance attachment: %+v", provenance)
+	}
+
+	if len(progress) == 0 {
+		t.Fatal("Expected progress callbacks")
+	}
+	last := progress[len(progress)-1]
+	if last[0] != last[1] || last[1] != response.BodySize {
+		t.Errorf("Expected progress to end at %d, got %v", response.BodySize, last)
+	}
+}
+
+func TestPublishErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", "OTP")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"You must provide a one-time pass."}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL + "/")
+	_, err := client.Publish(context.Background(), PublishRequest{
+		Manifest: []byte(`{"name":"pkg","version":"1.0.0"}`),
+		Tarball:  []byte("x"),
+	})
+
+	var registryErr *Error
+	if !errors.As(err, &registryErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	if !registryErr.OTP || registryErr.StatusCode != http.StatusUnauthorized || registryErr.Message != "You must provide a one-time pass." {
+		t.Errorf("Unexpected error: %+v", registryErr)
+	}
+
+	invalid := []PublishRequest{
+		{Manifest: []byte(`{"name":"pkg","version":"1.0.0"}`)},
+		{Manifest: []byte(`{"name":"pkg"}`), Tarball: []byte("x")},
+		{Manifest: []byte(`not json`), Tarball: []byte("x")},
+		{Manifest: []byte(`{"name":"pkg","version":"1.0.0"}`), Tarball: []byte("x"), ProvenanceBundle: []byte("not json")},
+	}
+	for i, req := range invalid {
+		if _, err := client.Publish(context.Background(), req); err == nil {
+			t.Errorf("Request %d: expected error", i)
+		}
+	}
+}
+
+func TestPackageURL(t *testing.T) {
+	client := NewClient("")
+	if client.URL() != DefaultRegistry {
+		t.Errorf("URL() = %s, expected default registry", client.URL())
+	}
+	if url := client.PackageURL("@types/node"); url != "https://registry.npmjs.org/@types%2fnode" {
+		t.Errorf("PackageURL() = %s", url)
+	}
+	if url := client.PackageURL("lodash"); url != "https://registry.npmjs.org/lodash" {
+		t.Errorf("PackageURL() = %s", url)
+	}
+}
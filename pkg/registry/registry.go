@@ -0,0 +1,130 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultRegistry npm官方registry地址
+const DefaultRegistry = "https://registry.npmjs.org/"
+
+// Client npm registry HTTP API客户端
+type Client struct {
+	baseURL    string
+	token      string
+	userAgent  string
+	httpClient *http.Client
+}
+
+// NewClient 创建registry客户端，registryURL为空时使用官方registry
+func NewClient(registryURL string) *Client {
+	if registryURL == "" {
+		registryURL = DefaultRegistry
+	}
+	if !strings.HasSuffix(registryURL, "/") {
+		registryURL += "/"
+	}
+
+	return &Client{
+		baseURL:   registryURL,
+		userAgent: "go-npm-sdk/1.0",
+		httpClient: &http.Client{
+			Timeout: 30 * time.Minute,
+		},
+	}
+}
+
+// URL registry地址，以/结尾
+func (c *Client) URL() string {
+	return c.baseURL
+}
+
+// SetToken 设置认证令牌，以Bearer方式发送
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// SetUserAgent 设置User-Agent
+func (c *Client) SetUserAgent(userAgent string) {
+	c.userAgent = userAgent
+}
+
+// SetHTTPClient 设置HTTP客户端
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// Error registry返回的错误响应
+type Error struct {
+	Method     string `json:"method"`
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Message    string `json:"message"`
+	OTP        bool   `json:"otp"` // 需要一次性密码
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// PackageURL 返回包文档的地址，scope中的/需要转义
+func (c *Client) PackageURL(name string) string {
+	return c.baseURL + escapePackageName(name)
+}
+
+// escapePackageName 转义包名，与npm一致将@scope/name转为@scope%2fname
+func escapePackageName(name string) string {
+	return strings.Replace(url.PathEscape(name), "%2F", "%2f", 1)
+}
+
+// setHeaders 设置通用请求头和认证头
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// responseError 根据错误响应构造Error
+func responseError(resp *http.Response) *Error {
+	err := &Error{
+		Method:     resp.Request.Method,
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var body struct {
+		Error   string `json:"error"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		switch {
+		case body.Error != "":
+			err.Message = body.Error
+		case body.Reason != "":
+			err.Message = body.Reason
+		default:
+			err.Message = body.Message
+		}
+	}
+	if err.Message == "" {
+		err.Message = strings.TrimSpace(string(data))
+	}
+
+	authenticate := strings.ToLower(resp.Header.Get("WWW-Authenticate"))
+	err.OTP = resp.StatusCode == http.StatusUnauthorized &&
+		(strings.Contains(authenticate, "otp") || strings.Contains(strings.ToLower(err.Message), "one-time pass"))
+	return err
+}
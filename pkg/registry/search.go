@@ -0,0 +1,39 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// DefaultSearchSize 与npm search默认返回的结果数一致
+const DefaultSearchSize = 20
+
+// Search 调用registry的搜索接口，返回原始JSON响应
+//
+// 响应格式为{objects: [{package, score, searchScore}], total, time}，
+// 与npm.ParseSearchJSON能解析的格式相同。size小于等于0时使用DefaultSearchSize。
+func (c *Client) Search(ctx context.Context, text string, size int) (json.RawMessage, error) {
+	if size <= 0 {
+		size = DefaultSearchSize
+	}
+	query := url.Values{"text": {text}, "size": {strconv.Itoa(size)}}
+
+	resp, err := c.get(ctx, c.baseURL+"-/v1/search?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("failed to parse search response: invalid JSON")
+	}
+	return data, nil
+}
@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/-/v1/search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query = r.URL.RawQuery
+		if r.URL.Query().Get("text") == "broken" {
+			w.Write([]byte(`{"objects": [`))
+			return
+		}
+		w.Write([]byte(`{"objects": [{"package": {"name": "lodash", "version": "4.17.21"}}], "total": 1}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	data, err := client.Search(context.Background(), "lodash utils", 0)
+	if err != nil {
+		t.Fatalf("Search() failed: %v", err)
+	}
+	if query != "size=20&text=lodash+utils" {
+		t.Errorf("Unexpected query: %s", query)
+	}
+	if len(data) == 0 || data[0] != '{' {
+		t.Errorf("Unexpected response: %s", data)
+	}
+
+	if _, err := client.Search(context.Background(), "broken", 5); err == nil {
+		t.Error("Expected error for invalid JSON response")
+	}
+}
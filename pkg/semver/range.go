@@ -0,0 +1,393 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// operatorSpacePattern 运算符与版本之间的空白，例如">= 1.2.3"
+var operatorSpacePattern = regexp.MustCompile(`(<=|>=|<|>|=|\^|~)\s+`)
+
+// comparator 单个比较条件，version为nil时匹配任意版本
+type comparator struct {
+	op      string
+	version *Version
+}
+
+// Range npm风格的版本范围
+//
+// 支持"||"、空格分隔的比较条件、连字符范围、x范围以及^和~运算符，
+// 语义与node-semver的默认模式一致：预发布版本只匹配同一major.minor.patch上
+// 带预发布标识的比较条件。
+type Range struct {
+	raw  string
+	sets [][]comparator
+}
+
+// ParseRange 解析版本范围
+func ParseRange(value string) (*Range, error) {
+	r := &Range{raw: value}
+	for _, part := range strings.Split(value, "||") {
+		set, err := parseComparatorSet(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", value, err)
+		}
+		r.sets = append(r.sets, set)
+	}
+	return r, nil
+}
+
+// ValidRange 检查版本范围是否合法
+func ValidRange(value string) bool {
+	_, err := ParseRange(value)
+	return err == nil
+}
+
+// String 返回原始范围字符串
+func (r *Range) String() string {
+	return r.raw
+}
+
+// Contains 版本是否满足范围
+func (r *Range) Contains(v *Version) bool {
+	for _, set := range r.sets {
+		if setContains(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Satisfies 版本字符串是否满足范围字符串，任一无法解析时返回false
+func Satisfies(version, rng string) bool {
+	v, err := Parse(version)
+	if err != nil {
+		return false
+	}
+	r, err := ParseRange(rng)
+	if err != nil {
+		return false
+	}
+	return r.Contains(v)
+}
+
+// MaxSatisfying 返回满足范围的最高版本，没有时返回空字符串
+func MaxSatisfying(versions []string, rng string) string {
+	return pickSatisfying(versions, rng, 1)
+}
+
+// MinSatisfying 返回满足范围的最低版本，没有时返回空字符串
+func MinSatisfying(versions []string, rng string) string {
+	return pickSatisfying(versions, rng, -1)
+}
+
+// Sort 按版本从低到高排序，无法解析的版本排在最前
+func Sort(versions []string) {
+	for i := 1; i < len(versions); i++ {
+		for j := i; j > 0 && Compare(versions[j-1], versions[j]) > 0; j-- {
+			versions[j-1], versions[j] = versions[j], versions[j-1]
+		}
+	}
+}
+
+// pickSatisfying 在满足范围的版本中选出最高（direction为1）或最低（-1）的版本
+func pickSatisfying(versions []string, rng string, direction int) string {
+	r, err := ParseRange(rng)
+	if err != nil {
+		return ""
+	}
+
+	var best *Version
+	bestRaw := ""
+	for _, raw := range versions {
+		v, err := Parse(raw)
+		if err != nil || !r.Contains(v) {
+			continue
+		}
+		if best == nil || v.Compare(best) == direction {
+			best = v
+			bestRaw = raw
+		}
+	}
+	return bestRaw
+}
+
+// setContains 版本是否满足一组比较条件
+func setContains(set []comparator, v *Version) bool {
+	for _, c := range set {
+		if !c.matches(v) {
+			return false
+		}
+	}
+
+	if !v.IsPrerelease() {
+		return true
+	}
+	for _, c := range set {
+		if c.version == nil || !c.version.IsPrerelease() {
+			continue
+		}
+		if c.version.Major == v.Major && c.version.Minor == v.Minor && c.version.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// matches 版本是否满足比较条件
+func (c comparator) matches(v *Version) bool {
+	if c.version == nil {
+		return true
+	}
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parseComparatorSet 解析空格分隔的一组比较条件
+func parseComparatorSet(value string) ([]comparator, error) {
+	value = operatorSpacePattern.ReplaceAllString(strings.TrimSpace(value), "$1")
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return []comparator{{}}, nil
+	}
+
+	var set []comparator
+	for i := 0; i < len(fields); i++ {
+		if i+2 < len(fields) && fields[i+1] == "-" {
+			comparators, err := parseHyphen(fields[i], fields[i+2])
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, comparators...)
+			i += 2
+			continue
+		}
+
+		comparators, err := parseComparator(fields[i])
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+	return set, nil
+}
+
+// parseComparator 解析单个比较条件并展开为基本比较
+func parseComparator(token string) ([]comparator, error) {
+	op := ""
+	for _, candidate := range []string{"<=", ">=", "<", ">", "=", "^", "~>", "~"} {
+		if strings.HasPrefix(token, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	p, err := parsePartial(token[len(op):])
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "^":
+		return caretRange(p), nil
+	case "~", "~>":
+		return tildeRange(p), nil
+	case "", "=":
+		return xRange(p), nil
+	}
+
+	if p.major < 0 {
+		// <*和>*不匹配任何版本，其他运算符与*一样匹配任意版本
+		if op == "<" || op == ">" {
+			return []comparator{{op: "<", version: &Version{Prerelease: []string{"0"}}}}, nil
+		}
+		return []comparator{{}}, nil
+	}
+
+	switch op {
+	case ">":
+		if p.minor < 0 {
+			return []comparator{{op: ">=", version: &Version{Major: p.major + 1}}}, nil
+		}
+		if p.patch < 0 {
+			return []comparator{{op: ">=", version: &Version{Major: p.major, Minor: p.minor + 1}}}, nil
+		}
+	case "<=":
+		if p.minor < 0 {
+			return []comparator{{op: "<", version: &Version{Major: p.major + 1, Prerelease: []string{"0"}}}}, nil
+		}
+		if p.patch < 0 {
+			return []comparator{{op: "<", version: &Version{Major: p.major, Minor: p.minor + 1, Prerelease: []string{"0"}}}}, nil
+		}
+	case "<":
+		if p.patch < 0 {
+			return []comparator{{op: "<", version: p.lower(true)}}, nil
+		}
+	}
+	return []comparator{{op: op, version: p.lower(false)}}, nil
+}
+
+// parseHyphen 解析连字符范围"a - b"
+func parseHyphen(from, to string) ([]comparator, error) {
+	lower, err := parsePartial(from)
+	if err != nil {
+		return nil, err
+	}
+	upper, err := parsePartial(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var set []comparator
+	if lower.major >= 0 {
+		set = append(set, comparator{op: ">=", version: lower.lower(false)})
+	}
+	switch {
+	case upper.major < 0:
+	case upper.minor < 0:
+		set = append(set, comparator{op: "<", version: &Version{Major: upper.major + 1, Prerelease: []string{"0"}}})
+	case upper.patch < 0:
+		set = append(set, comparator{op: "<", version: &Version{Major: upper.major, Minor: upper.minor + 1, Prerelease: []string{"0"}}})
+	default:
+		set = append(set, comparator{op: "<=", version: upper.lower(false)})
+	}
+	if len(set) == 0 {
+		set = append(set, comparator{})
+	}
+	return set, nil
+}
+
+// caretRange 展开^范围：不修改最左边的非零版本号
+func caretRange(p partial) []comparator {
+	if p.major < 0 {
+		return []comparator{{}}
+	}
+	lower := comparator{op: ">=", version: p.lower(false)}
+
+	var upper *Version
+	switch {
+	case p.minor < 0:
+		upper = &Version{Major: p.major + 1}
+	case p.major > 0:
+		upper = &Version{Major: p.major + 1}
+	case p.patch < 0:
+		if p.minor > 0 {
+			upper = &Version{Minor: p.minor + 1}
+		} else {
+			upper = &Version{Minor: 1}
+		}
+	case p.minor > 0:
+		upper = &Version{Minor: p.minor + 1}
+	default:
+		upper = &Version{Patch: p.patch + 1}
+	}
+	upper.Prerelease = []string{"0"}
+	return []comparator{lower, {op: "<", version: upper}}
+}
+
+// tildeRange 展开~范围：指定了minor时只允许patch变化
+func tildeRange(p partial) []comparator {
+	if p.major < 0 {
+		return []comparator{{}}
+	}
+	lower := comparator{op: ">=", version: p.lower(false)}
+
+	upper := &Version{Major: p.major + 1, Prerelease: []string{"0"}}
+	if p.minor >= 0 {
+		upper = &Version{Major: p.major, Minor: p.minor + 1, Prerelease: []string{"0"}}
+	}
+	return []comparator{lower, {op: "<", version: upper}}
+}
+
+// xRange 展开不带运算符的版本，部分版本号视为x范围
+func xRange(p partial) []comparator {
+	switch {
+	case p.major < 0:
+		return []comparator{{}}
+	case p.minor < 0:
+		return []comparator{
+			{op: ">=", version: p.lower(false)},
+			{op: "<", version: &Version{Major: p.major + 1, Prerelease: []string{"0"}}},
+		}
+	case p.patch < 0:
+		return []comparator{
+			{op: ">=", version: p.lower(false)},
+			{op: "<", version: &Version{Major: p.major, Minor: p.minor + 1, Prerelease: []string{"0"}}},
+		}
+	}
+	return []comparator{{op: "=", version: p.lower(false)}}
+}
+
+// partial 可能省略部分版本号的版本，-1表示通配
+type partial struct {
+	major, minor, patch int
+	prerelease          []string
+}
+
+// lower 返回部分版本对应的最低版本，prerelease为true时附加"-0"以排除预发布版本
+func (p partial) lower(prerelease bool) *Version {
+	v := &Version{Major: max(p.major, 0), Minor: max(p.minor, 0), Patch: max(p.patch, 0), Prerelease: p.prerelease}
+	if prerelease && len(v.Prerelease) == 0 {
+		v.Prerelease = []string{"0"}
+	}
+	return v
+}
+
+// parsePartial 解析部分版本号，例如"1"、"1.2.x"、"*"
+func parsePartial(value string) (partial, error) {
+	s := strings.TrimLeft(strings.TrimSpace(value), "=v")
+	p := partial{major: -1, minor: -1, patch: -1}
+	if s == "" || s == "*" || s == "x" || s == "X" {
+		return p, nil
+	}
+
+	if idx := strings.Index(s, "+"); idx >= 0 {
+		s = s[:idx]
+	}
+	if idx := strings.Index(s, "-"); idx >= 0 {
+		p.prerelease = strings.Split(s[idx+1:], ".")
+		for _, part := range p.prerelease {
+			if !isIdentifier(part) {
+				return p, fmt.Errorf("invalid prerelease in %q", value)
+			}
+		}
+		s = s[:idx]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return p, fmt.Errorf("invalid version %q", value)
+	}
+	numbers := []*int{&p.major, &p.minor, &p.patch}
+	wildcard := false
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			wildcard = true
+			continue
+		}
+		if wildcard {
+			return p, fmt.Errorf("invalid version %q", value)
+		}
+		n, err := parseNumber(part)
+		if err != nil {
+			return p, fmt.Errorf("invalid version %q: %w", value, err)
+		}
+		*numbers[i] = n
+	}
+	if p.patch < 0 && len(p.prerelease) > 0 {
+		return p, fmt.Errorf("invalid version %q: prerelease requires a full version", value)
+	}
+	return p, nil
+}
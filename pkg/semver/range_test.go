@@ -0,0 +1,82 @@
+package semver
+
+import "testing"
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		rng      string
+		version  string
+		expected bool
+	}{
+		{"", "1.2.3", true},
+		{"*", "1.2.3", true},
+		{"*", "1.2.3-beta", false},
+		{"1.2.3", "1.2.3", true},
+		{"=1.2.3", "1.2.4", false},
+		{"1.x", "1.9.0", true},
+		{"1.x", "2.0.0", false},
+		{"1.2", "1.2.9", true},
+		{"1.2", "1.3.0", false},
+		{"^1.2.3", "1.9.9", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "1.2.2", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0.x", "0.9.0", true},
+		{"^0.0", "0.1.0", false},
+		{"^1.2.3-beta.2", "1.2.3-beta.4", true},
+		{"^1.2.3-beta.2", "1.2.4-beta.1", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1", "1.9.0", true},
+		{"~>1.2", "1.2.5", true},
+		{">=1.2.3", "1.2.3", true},
+		{">= 1.2.3 < 2", "1.9.0", true},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"<1.2", "1.1.9", true},
+		{"<1.2", "1.2.0-beta", false},
+		{"<=1.2", "1.2.9", true},
+		{"<4.17.21", "4.17.20", true},
+		{"<4.17.21", "4.17.21", false},
+		{"1.2.3 - 2.3.4", "2.3.4", true},
+		{"1.2 - 2.3", "2.3.9", true},
+		{"1.2 - 2.3", "2.4.0", false},
+		{"<1.0.0 || >=2.0.0 <2.1.0", "2.0.5", true},
+		{"<1.0.0 || >=2.0.0 <2.1.0", "1.5.0", false},
+		{"<*", "1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := Satisfies(tt.version, tt.rng); got != tt.expected {
+			t.Errorf("Satisfies(%q, %q) = %v, expected %v", tt.version, tt.rng, got, tt.expected)
+		}
+	}
+}
+
+func TestParseRangeInvalid(t *testing.T) {
+	for _, rng := range []string{"1.2.3.4", "^01.2", "1.x.3", ">=a.b.c", "1.2-beta"} {
+		if ValidRange(rng) {
+			t.Errorf("Expected %q to be invalid", rng)
+		}
+	}
+}
+
+func TestMaxMinSatisfying(t *testing.T) {
+	versions := []string{"1.0.0", "1.2.0", "1.10.0", "2.0.0", "2.1.0-beta", "not-a-version"}
+
+	if got := MaxSatisfying(versions, "^1.0.0"); got != "1.10.0" {
+		t.Errorf("MaxSatisfying() = %s, expected 1.10.0", got)
+	}
+	if got := MinSatisfying(versions, ">1.0.0"); got != "1.2.0" {
+		t.Errorf("MinSatisfying() = %s, expected 1.2.0", got)
+	}
+	if got := MaxSatisfying(versions, ">=3"); got != "" {
+		t.Errorf("MaxSatisfying() = %s, expected no match", got)
+	}
+	if got := MaxSatisfying(versions, "invalid range !"); got != "" {
+		t.Errorf("MaxSatisfying() = %s, expected no match for invalid range", got)
+	}
+}
@@ -0,0 +1,234 @@
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version 语义化版本
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+	Build      []string
+}
+
+// Parse 解析版本号
+//
+// 与npm的宽松模式一致，允许前导的"v"或"="以及首尾空白。
+func Parse(value string) (*Version, error) {
+	s := strings.TrimSpace(value)
+	s = strings.TrimLeft(s, "=v")
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("invalid version %q: empty", value)
+	}
+
+	v := &Version{}
+	if idx := strings.Index(s, "+"); idx >= 0 {
+		build := s[idx+1:]
+		s = s[:idx]
+		if build == "" {
+			return nil, fmt.Errorf("invalid version %q: empty build metadata", value)
+		}
+		v.Build = strings.Split(build, ".")
+		for _, part := range v.Build {
+			if !isIdentifier(part) {
+				return nil, fmt.Errorf("invalid version %q: invalid build metadata", value)
+			}
+		}
+	}
+	if idx := strings.Index(s, "-"); idx >= 0 {
+		pre := s[idx+1:]
+		s = s[:idx]
+		if pre == "" {
+			return nil, fmt.Errorf("invalid version %q: empty prerelease", value)
+		}
+		v.Prerelease = strings.Split(pre, ".")
+		for _, part := range v.Prerelease {
+			if !isIdentifier(part) {
+				return nil, fmt.Errorf("invalid version %q: invalid prerelease", value)
+			}
+			if isNumeric(part) && len(part) > 1 && part[0] == '0' {
+				return nil, fmt.Errorf("invalid version %q: prerelease has leading zero", value)
+			}
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid version %q: expected major.minor.patch", value)
+	}
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := parseNumber(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %w", value, err)
+		}
+		*numbers[i] = n
+	}
+
+	return v, nil
+}
+
+// MustParse 解析版本号，失败时panic，用于常量初始化
+func MustParse(value string) *Version {
+	v, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Valid 检查版本号是否合法
+func Valid(value string) bool {
+	_, err := Parse(value)
+	return err == nil
+}
+
+// String 返回规范格式的版本号
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// IsPrerelease 是否为预发布版本
+func (v *Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare 比较两个版本，忽略构建元数据
+//
+// v小于other返回-1，相等返回0，大于返回1。
+func (v *Version) Compare(other *Version) int {
+	if c := compareInt(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// LessThan v是否小于other
+func (v *Version) LessThan(other *Version) bool {
+	return v.Compare(other) < 0
+}
+
+// Equal v是否等于other
+func (v *Version) Equal(other *Version) bool {
+	return v.Compare(other) == 0
+}
+
+// Compare 比较两个版本字符串，无法解析的版本排在合法版本之前并按字符串比较
+func Compare(a, b string) int {
+	va, errA := Parse(a)
+	vb, errB := Parse(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return va.Compare(vb)
+}
+
+// comparePrerelease 按semver规则比较预发布标识
+func comparePrerelease(a, b []string) int {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	// 没有预发布标识的版本更大
+	if len(a) == 0 {
+		return 1
+	}
+	if len(b) == 0 {
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		aNum, bNum := isNumeric(a[i]), isNumeric(b[i])
+		switch {
+		case aNum && bNum:
+			an, _ := strconv.Atoi(a[i])
+			bn, _ := strconv.Atoi(b[i])
+			if c := compareInt(an, bn); c != 0 {
+				return c
+			}
+		case aNum:
+			return -1
+		case bNum:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(a), len(b))
+}
+
+// compareInt 比较整数
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// parseNumber 解析版本号中的数字部分
+func parseNumber(s string) (int, error) {
+	if s == "" || !isNumeric(s) {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("%q has a leading zero", s)
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is out of range", s)
+	}
+	return n, nil
+}
+
+// isNumeric 是否全部为数字
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdentifier 是否为合法的预发布或构建标识
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
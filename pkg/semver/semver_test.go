@@ -0,0 +1,73 @@
+package semver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"v1.2.3", "1.2.3", true},
+		{" =1.2.3 ", "1.2.3", true},
+		{"1.2.3-beta.1+build.5", "1.2.3-beta.1+build.5", true},
+		{"1.2", "", false},
+		{"01.2.3", "", false},
+		{"1.2.3-01", "", false},
+		{"1.2.3-", "", false},
+		{"1.2.3+", "", false},
+		{"1.2.x", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.input)
+		if (err == nil) != tt.valid {
+			t.Errorf("Parse(%q) error = %v, expected valid=%v", tt.input, err, tt.valid)
+			continue
+		}
+		if tt.valid && v.String() != tt.expected {
+			t.Errorf("Parse(%q) = %s, expected %s", tt.input, v, tt.expected)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		if Compare(ordered[i], ordered[i+1]) != -1 {
+			t.Errorf("Expected %s < %s", ordered[i], ordered[i+1])
+		}
+		if Compare(ordered[i+1], ordered[i]) != 1 {
+			t.Errorf("Expected %s > %s", ordered[i+1], ordered[i])
+		}
+	}
+	if Compare("1.0.0+a", "1.0.0+b") != 0 {
+		t.Error("Expected build metadata to be ignored")
+	}
+	if Compare("invalid", "1.0.0") != -1 {
+		t.Error("Expected invalid versions to sort first")
+	}
+
+	versions := []string{"1.10.0", "1.2.0", "1.2.0-rc.1", "0.9.0"}
+	Sort(versions)
+	if !reflect.DeepEqual(versions, []string{"0.9.0", "1.2.0-rc.1", "1.2.0", "1.10.0"}) {
+		t.Errorf("Unexpected sort order: %v", versions)
+	}
+}
@@ -0,0 +1,429 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound 条目不存在或已过期
+var ErrNotFound = errors.New("entry not found")
+
+// entryExt 条目文件扩展名
+const entryExt = ".entry"
+
+// Options 存储选项
+type Options struct {
+	MaxBytes   int64 `json:"max_bytes,omitempty"`   // 所有条目值的总大小上限，0表示不限制
+	MaxEntries int   `json:"max_entries,omitempty"` // 条目数量上限，0表示不限制
+}
+
+// Stats 存储统计
+type Stats struct {
+	Entries   int            `json:"entries"`
+	Bytes     int64          `json:"bytes"`
+	Buckets   map[string]int `json:"buckets"`
+	Evictions int            `json:"evictions"` // 本次打开以来因超出上限被淘汰的条目数
+}
+
+// header 条目文件的第一行
+type header struct {
+	Bucket  string    `json:"bucket"`
+	Key     string    `json:"key"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires,omitempty"`
+	SHA256  string    `json:"sha256,omitempty"` // 值的十六进制SHA-256，旧版本写入的条目没有
+}
+
+// entry 内存中的条目索引
+type entry struct {
+	header
+	path     string
+	size     int64
+	accessed time.Time
+}
+
+// Store 嵌入式键值存储
+//
+// 每个条目保存为目录中的一个文件，第一行是JSON格式的元数据，之后是原始值；
+// 最近访问时间记录在文件的修改时间上。打开时扫描目录重建索引，所以进程重启后
+// 之前的结果仍然可用，批量分析可以跳过已经完成的部分。超出大小或数量上限时
+// 按最近访问时间淘汰最久未使用的条目。
+//
+// 多个进程可以使用同一个目录：Get、Has和Open在索引中找不到条目时检查目录中
+// 是否有其他进程写入的条目文件，Keys和Stats只包含本进程索引中的条目。
+type Store struct {
+	dir       string
+	options   Options
+	mu        sync.Mutex
+	entries   map[string]*entry
+	bytes     int64
+	evictions int
+	now       func() time.Time
+}
+
+// Open 打开或创建存储目录
+func Open(dir string, options Options) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	s := &Store{
+		dir:     dir,
+		options: options,
+		entries: make(map[string]*entry),
+		now:     time.Now,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.evict(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Dir 存储目录
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Put 写入条目，ttl为0表示不过期
+func (s *Store) Put(bucket, key string, value []byte, ttl time.Duration) error {
+	w, err := s.Create(bucket, key, ttl)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(value); err != nil {
+		w.Abort()
+		return err
+	}
+	return w.Commit()
+}
+
+// Get 读取条目，不存在、已过期或已损坏时返回ErrNotFound
+func (s *Store) Get(bucket, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, e := s.lookup(bucket, key)
+	if e == nil {
+		return nil, ErrNotFound
+	}
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.bytes -= e.size
+			delete(s.entries, id)
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read entry: %w", err)
+	}
+	idx := bytes.IndexByte(data, '\n')
+	if idx < 0 {
+		return nil, fmt.Errorf("corrupt entry %s/%s", bucket, key)
+	}
+	// 其他进程可能已经替换了条目文件
+	s.resize(e, int64(len(data)-idx-1))
+
+	// 值与元数据中的SHA-256不一致时文件已损坏，删除后当作不存在
+	var h header
+	if err := json.Unmarshal(data[:idx], &h); err == nil && h.SHA256 != "" {
+		sum := sha256.Sum256(data[idx+1:])
+		if hex.EncodeToString(sum[:]) != h.SHA256 {
+			s.remove(id)
+			return nil, ErrNotFound
+		}
+	}
+
+	now := s.now()
+	e.accessed = now
+	_ = os.Chtimes(e.path, now, now)
+	return data[idx+1:], nil
+}
+
+// Has 条目是否存在且未过期
+func (s *Store) Has(bucket, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, e := s.lookup(bucket, key)
+	return e != nil
+}
+
+// PutJSON 以JSON编码写入条目
+func (s *Store) PutJSON(bucket, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s/%s: %w", bucket, key, err)
+	}
+	return s.Put(bucket, key, data, ttl)
+}
+
+// GetJSON 读取条目并解码到value
+func (s *Store) GetJSON(bucket, key string, value interface{}) error {
+	data, err := s.Get(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to decode %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Delete 删除条目，条目不存在时不返回错误
+func (s *Store) Delete(bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remove(entryID(bucket, key))
+}
+
+// Keys 返回桶中未过期的键，按字典序排列
+func (s *Store) Keys(bucket string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var keys []string
+	for _, e := range s.entries {
+		if e.Bucket == bucket && !e.expired(now) {
+			keys = append(keys, e.Key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Purge 删除所有已过期的条目，返回删除的数量
+func (s *Store) Purge() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	removed := 0
+	for id, e := range s.entries {
+		if e.expired(now) {
+			if err := s.remove(id); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Clear 删除桶中的所有条目，bucket为空时清空整个存储
+func (s *Store) Clear(bucket string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, e := range s.entries {
+		if bucket == "" || e.Bucket == bucket {
+			if err := s.remove(id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stats 返回存储统计
+func (s *Store) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := Stats{
+		Entries:   len(s.entries),
+		Bytes:     s.bytes,
+		Buckets:   make(map[string]int),
+		Evictions: s.evictions,
+	}
+	for _, e := range s.entries {
+		stats.Buckets[e.Bucket]++
+	}
+	return stats
+}
+
+// load 扫描目录重建索引，跳过无法解析的文件
+func (s *Store) load() error {
+	return filepath.Walk(s.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if strings.HasSuffix(path, ".tmp") {
+			// 上次写入中断留下的临时文件
+			os.Remove(path)
+			return nil
+		}
+		if !strings.HasSuffix(path, entryExt) {
+			return nil
+		}
+
+		h, err := readHeader(path)
+		if err != nil {
+			return nil
+		}
+		size := info.Size() - int64(headerLength(h))
+		s.entries[entryID(h.Bucket, h.Key)] = &entry{header: h, path: path, size: size, accessed: info.ModTime()}
+		s.bytes += size
+		return nil
+	})
+}
+
+// lookup 查找未过期的条目，索引中没有时从目录中加载其他进程写入的条目文件
+func (s *Store) lookup(bucket, key string) (string, *entry) {
+	id := entryID(bucket, key)
+	e, ok := s.entries[id]
+	if !ok {
+		if validateBucket(bucket) != nil {
+			return id, nil
+		}
+		path := s.entryPath(bucket, key)
+		info, err := os.Stat(path)
+		if err != nil {
+			return id, nil
+		}
+		h, err := readHeader(path)
+		if err != nil || h.Bucket != bucket || h.Key != key {
+			return id, nil
+		}
+		e = &entry{header: h, path: path, size: info.Size() - int64(headerLength(h)), accessed: info.ModTime()}
+		s.entries[id] = e
+		s.bytes += e.size
+	}
+	if e.expired(s.now()) {
+		s.remove(id)
+		return id, nil
+	}
+	return id, e
+}
+
+// resize 更新条目的大小和总大小
+func (s *Store) resize(e *entry, size int64) {
+	s.bytes += size - e.size
+	e.size = size
+}
+
+// evict 淘汰过期条目以及超出上限的最久未使用条目
+func (s *Store) evict() error {
+	now := s.now()
+	for id, e := range s.entries {
+		if e.expired(now) {
+			if err := s.remove(id); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !s.overLimit() {
+		return nil
+	}
+
+	ids := make([]string, 0, len(s.entries))
+	for id := range s.entries {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return s.entries[ids[i]].accessed.Before(s.entries[ids[j]].accessed)
+	})
+	for _, id := range ids {
+		if !s.overLimit() {
+			break
+		}
+		if err := s.remove(id); err != nil {
+			return err
+		}
+		s.evictions++
+	}
+	return nil
+}
+
+// overLimit 是否超出大小或数量上限
+func (s *Store) overLimit() bool {
+	return (s.options.MaxBytes > 0 && s.bytes > s.options.MaxBytes) ||
+		(s.options.MaxEntries > 0 && len(s.entries) > s.options.MaxEntries)
+}
+
+// remove 删除条目文件和索引
+func (s *Store) remove(id string) error {
+	e, ok := s.entries[id]
+	if !ok {
+		return nil
+	}
+	if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove entry: %w", err)
+	}
+	s.bytes -= e.size
+	delete(s.entries, id)
+	return nil
+}
+
+// entryPath 条目文件路径
+func (s *Store) entryPath(bucket, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, bucket, hex.EncodeToString(sum[:16])+entryExt)
+}
+
+// expired 条目是否已过期
+func (e *entry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
+
+// readHeader 读取条目文件的元数据行
+func readHeader(path string) (header, error) {
+	var h header
+	file, err := os.Open(path)
+	if err != nil {
+		return h, err
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil {
+		return h, err
+	}
+	if err := json.Unmarshal(line, &h); err != nil {
+		return h, err
+	}
+	if h.Bucket == "" {
+		return h, fmt.Errorf("missing bucket")
+	}
+	return h, nil
+}
+
+// headerLength 元数据行的长度（含换行符）
+func headerLength(h header) int {
+	line, _ := json.Marshal(h)
+	return len(line) + 1
+}
+
+// entryID 内存索引的键
+func entryID(bucket, key string) string {
+	return bucket + "\x00" + key
+}
+
+// validateBucket 检查桶名是否可以用作目录名
+func validateBucket(bucket string) error {
+	if bucket == "" || bucket == "." || bucket == ".." || strings.ContainsAny(bucket, `/\:`) {
+		return fmt.Errorf("invalid bucket name %q", bucket)
+	}
+	return nil
+}
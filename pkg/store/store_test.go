@@ -0,0 +1,242 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func openTestStore(t *testing.T, dir string, options Options) (*Store, *fakeClock) {
+	t.Helper()
+	s, err := Open(dir, options)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s.now = clock.Now
+	return s, clock
+}
+
+func TestStorePutGet(t *testing.T) {
+	s, _ := openTestStore(t, t.TempDir(), Options{})
+
+	if err := s.Put("packuments", "lodash", []byte(`{"name":"lodash"}`), 0); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	data, err := s.Get("packuments", "lodash")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if string(data) != `{"name":"lodash"}` {
+		t.Errorf("Get() = %q", data)
+	}
+
+	if _, err := s.Get("packuments", "express"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() missing error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Get("downloads", "lodash"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() other bucket error = %v, want ErrNotFound", err)
+	}
+
+	// 覆盖写入时大小按新值计算
+	if err := s.Put("packuments", "lodash", []byte(`{}`), 0); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if stats := s.Stats(); stats.Entries != 1 || stats.Bytes != 2 {
+		t.Errorf("Stats() = %+v, want 1 entry of 2 bytes", stats)
+	}
+}
+
+func TestStoreJSON(t *testing.T) {
+	s, _ := openTestStore(t, t.TempDir(), Options{})
+
+	type downloads struct {
+		Package   string `json:"package"`
+		Downloads int    `json:"downloads"`
+	}
+	if err := s.PutJSON("downloads", "@types/node", downloads{Package: "@types/node", Downloads: 42}, 0); err != nil {
+		t.Fatalf("PutJSON() failed: %v", err)
+	}
+
+	var got downloads
+	if err := s.GetJSON("downloads", "@types/node", &got); err != nil {
+		t.Fatalf("GetJSON() failed: %v", err)
+	}
+	if got.Downloads != 42 {
+		t.Errorf("GetJSON() = %+v", got)
+	}
+}
+
+func TestStoreTTL(t *testing.T) {
+	s, clock := openTestStore(t, t.TempDir(), Options{})
+
+	if err := s.Put("audits", "project", []byte("report"), time.Hour); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if !s.Has("audits", "project") {
+		t.Error("Has() = false before expiry")
+	}
+
+	clock.now = clock.now.Add(2 * time.Hour)
+	if s.Has("audits", "project") {
+		t.Error("Has() = true after expiry")
+	}
+	if _, err := s.Get("audits", "project"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	if stats := s.Stats(); stats.Entries != 0 {
+		t.Errorf("Stats().Entries = %d, want expired entry removed", stats.Entries)
+	}
+}
+
+func TestStorePurge(t *testing.T) {
+	s, clock := openTestStore(t, t.TempDir(), Options{})
+
+	s.Put("audits", "a", []byte("1"), time.Minute)
+	s.Put("audits", "b", []byte("2"), time.Hour)
+	s.Put("audits", "c", []byte("3"), 0)
+
+	clock.now = clock.now.Add(10 * time.Minute)
+	removed, err := s.Purge()
+	if err != nil {
+		t.Fatalf("Purge() failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Purge() removed %d, want 1", removed)
+	}
+	if keys := s.Keys("audits"); len(keys) != 2 || keys[0] != "b" || keys[1] != "c" {
+		t.Errorf("Keys() = %v, want [b c]", keys)
+	}
+}
+
+func TestStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	s, clock := openTestStore(t, t.TempDir(), Options{MaxBytes: 10})
+
+	for _, key := range []string{"a", "b", "c"} {
+		clock.now = clock.now.Add(time.Second)
+		if err := s.Put("packuments", key, []byte("1234"), 0); err != nil {
+			t.Fatalf("Put(%s) failed: %v", key, err)
+		}
+	}
+
+	// 写入c时超出10字节，最久未使用的a被淘汰
+	if s.Has("packuments", "a") {
+		t.Error("a should have been evicted")
+	}
+
+	// 访问b后写入d，此时c最久未使用
+	clock.now = clock.now.Add(time.Second)
+	if _, err := s.Get("packuments", "b"); err != nil {
+		t.Fatalf("Get(b) failed: %v", err)
+	}
+	clock.now = clock.now.Add(time.Second)
+	if err := s.Put("packuments", "d", []byte("1234"), 0); err != nil {
+		t.Fatalf("Put(d) failed: %v", err)
+	}
+
+	if keys := s.Keys("packuments"); len(keys) != 2 || keys[0] != "b" || keys[1] != "d" {
+		t.Errorf("Keys() = %v, want [b d]", keys)
+	}
+	if stats := s.Stats(); stats.Evictions != 2 || stats.Bytes != 8 {
+		t.Errorf("Stats() = %+v, want 2 evictions and 8 bytes", stats)
+	}
+}
+
+func TestStoreMaxEntries(t *testing.T) {
+	s, clock := openTestStore(t, t.TempDir(), Options{MaxEntries: 2})
+
+	for _, key := range []string{"a", "b", "c"} {
+		clock.now = clock.now.Add(time.Second)
+		s.Put("downloads", key, []byte("x"), 0)
+	}
+	if keys := s.Keys("downloads"); len(keys) != 2 || keys[0] != "b" {
+		t.Errorf("Keys() = %v, want [b c]", keys)
+	}
+}
+
+func TestStoreReopen(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := openTestStore(t, dir, Options{})
+
+	s.Put("packuments", "lodash", []byte("lodash"), 0)
+	s.Put("packuments", "react", []byte("react"), 0)
+	s.Put("audits", "project", []byte("report"), 0)
+
+	// 模拟写入中断留下的临时文件
+	if err := os.WriteFile(filepath.Join(dir, "packuments", "partial.entry.tmp"), []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	reopened, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	stats := reopened.Stats()
+	if stats.Entries != 3 || stats.Bytes != 17 {
+		t.Errorf("Stats() = %+v, want 3 entries of 17 bytes", stats)
+	}
+	if stats.Buckets["packuments"] != 2 || stats.Buckets["audits"] != 1 {
+		t.Errorf("Stats().Buckets = %v", stats.Buckets)
+	}
+	data, err := reopened.Get("packuments", "react")
+	if err != nil || string(data) != "react" {
+		t.Errorf("Get() = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "packuments", "partial.entry.tmp")); !os.IsNotExist(err) {
+		t.Error("temporary file should be removed on open")
+	}
+
+	// 以更小的上限重新打开时立即淘汰
+	limited, err := Open(dir, Options{MaxEntries: 1})
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if stats := limited.Stats(); stats.Entries != 1 {
+		t.Errorf("Stats().Entries = %d, want 1", stats.Entries)
+	}
+}
+
+func TestStoreDeleteAndClear(t *testing.T) {
+	s, _ := openTestStore(t, t.TempDir(), Options{})
+
+	s.Put("packuments", "a", []byte("1"), 0)
+	s.Put("packuments", "b", []byte("2"), 0)
+	s.Put("downloads", "a", []byte("3"), 0)
+
+	if err := s.Delete("packuments", "a"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if err := s.Delete("packuments", "missing"); err != nil {
+		t.Errorf("Delete() missing failed: %v", err)
+	}
+	if err := s.Clear("packuments"); err != nil {
+		t.Fatalf("Clear() failed: %v", err)
+	}
+	if keys := s.Keys("packuments"); len(keys) != 0 {
+		t.Errorf("Keys() = %v, want empty", keys)
+	}
+	if !s.Has("downloads", "a") {
+		t.Error("Clear() removed entries from another bucket")
+	}
+}
+
+func TestStoreInvalidBucket(t *testing.T) {
+	s, _ := openTestStore(t, t.TempDir(), Options{})
+
+	for _, bucket := range []string{"", "..", "a/b", `a\b`} {
+		if err := s.Put(bucket, "key", []byte("x"), 0); err == nil {
+			t.Errorf("Put(%q) should fail", bucket)
+		}
+	}
+}
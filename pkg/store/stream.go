@@ -0,0 +1,198 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Writer 流式写入的条目，不能并发使用
+//
+// 值写入存储目录中的临时文件，同时计算SHA-256；Commit后才对Get和Open可见。
+type Writer struct {
+	store     *Store
+	header    header
+	path      string
+	file      *os.File
+	hash      hash.Hash
+	size      int64
+	sumOffset int64 // 元数据行中sha256占位符的位置
+}
+
+// Create 创建流式写入的条目，ttl为0表示不过期
+//
+// 写入完成后调用Commit，放弃时调用Abort删除临时文件；Commit之后调用Abort不会删除条目，
+// 所以可以在创建后直接defer Abort。
+func (s *Store) Create(bucket, key string, ttl time.Duration) (*Writer, error) {
+	if err := validateBucket(bucket); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	// sha256在写完之前未知，先写入等长的占位符，Commit时原位替换
+	h := header{Bucket: bucket, Key: key, Created: now.UTC(), SHA256: strings.Repeat("0", sha256.Size*2)}
+	if ttl > 0 {
+		h.Expires = now.Add(ttl).UTC()
+	}
+	line, err := json.Marshal(h)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode entry header: %w", err)
+	}
+
+	path := s.entryPath(bucket, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create bucket directory: %w", err)
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create entry: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to write entry: %w", err)
+	}
+
+	return &Writer{
+		store:  s,
+		header: h,
+		path:   path,
+		file:   file,
+		hash:   sha256.New(),
+		// sha256是最后一个字段，键中相同的字符串不会被匹配
+		sumOffset: int64(bytes.LastIndex(line, []byte(h.SHA256))),
+	}, nil
+}
+
+// Write 追加值的内容
+func (w *Writer) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	w.hash.Write(p[:n])
+	w.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("failed to write entry: %w", err)
+	}
+	return n, nil
+}
+
+// Commit 写入SHA-256，把临时文件重命名为条目文件并更新索引
+func (w *Writer) Commit() error {
+	w.header.SHA256 = hex.EncodeToString(w.hash.Sum(nil))
+	if _, err := w.file.WriteAt([]byte(w.header.SHA256), w.sumOffset); err != nil {
+		w.Abort()
+		return fmt.Errorf("failed to write entry: %w", err)
+	}
+	if err := w.file.Close(); err != nil {
+		os.Remove(w.file.Name())
+		return fmt.Errorf("failed to write entry: %w", err)
+	}
+
+	s := w.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Rename(w.file.Name(), w.path); err != nil {
+		os.Remove(w.file.Name())
+		return fmt.Errorf("failed to write entry: %w", err)
+	}
+
+	id := entryID(w.header.Bucket, w.header.Key)
+	if old, ok := s.entries[id]; ok {
+		s.bytes -= old.size
+	}
+	s.entries[id] = &entry{header: w.header, path: w.path, size: w.size, accessed: s.now()}
+	s.bytes += w.size
+
+	return s.evict()
+}
+
+// Abort 放弃写入并删除临时文件
+func (w *Writer) Abort() error {
+	w.file.Close()
+	if err := os.Remove(w.file.Name()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove entry: %w", err)
+	}
+	return nil
+}
+
+// Reader 条目值的读取器，支持Seek和ReadAt，使用后需要Close
+type Reader struct {
+	*io.SectionReader
+	file   *os.File
+	SHA256 string // 值的十六进制SHA-256，旧版本写入的条目为空
+}
+
+// Close 关闭条目文件
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+// Open 打开条目用于流式读取，不存在、已过期或已损坏时返回ErrNotFound
+//
+// 打开时读取整个值校验SHA-256，之后的读取不再校验。
+func (s *Store) Open(bucket, key string) (*Reader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, e := s.lookup(bucket, key)
+	if e == nil {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(e.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.bytes -= e.size
+			delete(s.entries, id)
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read entry: %w", err)
+	}
+	// 其他进程可能已经替换了条目文件，元数据和大小以打开的文件为准
+	var h header
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err == nil {
+		err = json.Unmarshal(line, &h)
+	}
+	info, statErr := file.Stat()
+	if err != nil || statErr != nil || h.Bucket != bucket || h.Key != key {
+		file.Close()
+		return nil, fmt.Errorf("corrupt entry %s/%s", bucket, key)
+	}
+	e.header = h
+	s.resize(e, info.Size()-int64(len(line)))
+
+	// 值与元数据中的SHA-256不一致时文件已损坏，删除后当作不存在
+	section := io.NewSectionReader(file, int64(len(line)), e.size)
+	if h.SHA256 != "" {
+		hash := sha256.New()
+		if _, err := io.Copy(hash, section); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to read entry: %w", err)
+		}
+		if hex.EncodeToString(hash.Sum(nil)) != h.SHA256 {
+			file.Close()
+			s.remove(id)
+			return nil, ErrNotFound
+		}
+		section.Seek(0, io.SeekStart)
+	}
+
+	now := s.now()
+	e.accessed = now
+	_ = os.Chtimes(e.path, now, now)
+	return &Reader{
+		SectionReader: section,
+		file:          file,
+		SHA256:        e.SHA256,
+	}, nil
+}
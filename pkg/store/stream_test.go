@@ -0,0 +1,176 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreCreateOpen(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := openTestStore(t, dir, Options{})
+
+	w, err := s.Create("binaries", "https://example.com/node.tar.gz", 0)
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	io.Copy(w, strings.NewReader("part1-"))
+	io.Copy(w, strings.NewReader("part2"))
+	// Commit之前不可见
+	if s.Has("binaries", "https://example.com/node.tar.gz") {
+		t.Error("entry should not be visible before Commit()")
+	}
+	if err := w.Commit(); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	// Commit之后Abort不删除条目
+	if err := w.Abort(); err != nil {
+		t.Errorf("Abort() after Commit() failed: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte("part1-part2"))
+	check := func(s *Store) {
+		t.Helper()
+		r, err := s.Open("binaries", "https://example.com/node.tar.gz")
+		if err != nil {
+			t.Fatalf("Open() failed: %v", err)
+		}
+		defer r.Close()
+		data, _ := io.ReadAll(r)
+		if string(data) != "part1-part2" || r.Size() != 11 || r.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("Open() = %q (size %d, sha256 %s)", data, r.Size(), r.SHA256)
+		}
+	}
+	check(s)
+	if data, err := s.Get("binaries", "https://example.com/node.tar.gz"); err != nil || string(data) != "part1-part2" {
+		t.Errorf("Get() = %q, %v", data, err)
+	}
+
+	// 重新打开后从元数据行读取SHA-256
+	reopened, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if stats := reopened.Stats(); stats.Bytes != 11 {
+		t.Errorf("Stats().Bytes = %d, want 11", stats.Bytes)
+	}
+	check(reopened)
+
+	if _, err := s.Open("binaries", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() missing error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStoreCreateAbort(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := openTestStore(t, dir, Options{})
+
+	w, err := s.Create("binaries", "partial", 0)
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	w.Write([]byte("incomplete"))
+	if err := w.Abort(); err != nil {
+		t.Fatalf("Abort() failed: %v", err)
+	}
+
+	if s.Has("binaries", "partial") {
+		t.Error("aborted entry should not be stored")
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "binaries", "*"))
+	if len(files) != 0 {
+		t.Errorf("temporary files left after Abort(): %v", files)
+	}
+
+	if _, err := s.Create("../escape", "key", 0); err == nil {
+		t.Error("expected error for invalid bucket")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "..", "escape")); !os.IsNotExist(err) {
+		t.Error("invalid bucket should not create a directory")
+	}
+}
+
+func TestStoreSharedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	a, _ := openTestStore(t, dir, Options{})
+	b, _ := openTestStore(t, dir, Options{})
+
+	// b打开之后a写入的条目
+	if err := a.Put("binaries", "node.tar.gz", []byte("v1"), 0); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if !b.Has("binaries", "node.tar.gz") {
+		t.Error("entry written by another store should be visible")
+	}
+	if data, err := b.Get("binaries", "node.tar.gz"); err != nil || string(data) != "v1" {
+		t.Errorf("Get() = %q, %v", data, err)
+	}
+	if stats := b.Stats(); stats.Entries != 1 || stats.Bytes != 2 {
+		t.Errorf("Stats() = %+v, want 1 entry of 2 bytes", stats)
+	}
+
+	// a替换条目后b按新文件读取并更新大小
+	if err := a.Put("binaries", "node.tar.gz", []byte("version2"), 0); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	r, err := b.Open("binaries", "node.tar.gz")
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	sum := sha256.Sum256([]byte("version2"))
+	if string(data) != "version2" || r.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Open() = %q (sha256 %s)", data, r.SHA256)
+	}
+	if stats := b.Stats(); stats.Bytes != 8 {
+		t.Errorf("Stats().Bytes = %d, want 8", stats.Bytes)
+	}
+
+	if b.Has("../escape", "key") {
+		t.Error("invalid bucket should not be looked up on disk")
+	}
+}
+
+func TestStoreCorruptEntry(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := openTestStore(t, dir, Options{})
+
+	corrupt := func(key string) string {
+		t.Helper()
+		if err := s.Put("binaries", key, []byte("original"), 0); err != nil {
+			t.Fatalf("Put() failed: %v", err)
+		}
+		path := s.entryPath("binaries", key)
+		data, _ := os.ReadFile(path)
+		// 长度不变，只修改值的内容
+		if err := os.WriteFile(path, append(data[:len(data)-8], "tampered"...), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	path := corrupt("get")
+	if _, err := s.Get("binaries", "get"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) || s.Has("binaries", "get") {
+		t.Error("corrupt entry should be removed by Get()")
+	}
+
+	path = corrupt("open")
+	if _, err := s.Open("binaries", "open"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() error = %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) || s.Has("binaries", "open") {
+		t.Error("corrupt entry should be removed by Open()")
+	}
+	if stats := s.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("Stats() = %+v, want empty store", stats)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"strings"
@@ -23,6 +24,7 @@ type ExecuteOptions struct {
 	CaptureOutput bool            `json:"capture_output"`
 	StreamOutput  bool            `json:"stream_output"`
 	OutputCallback func(string)   `json:"-"`
+	KillProcessGroup bool         `json:"kill_process_group"` // 取消或超时时终止命令启动的所有子进程，仅Unix有效
 }
 
 // ExecuteResult 执行结果
@@ -41,6 +43,7 @@ type Executor struct {
 	defaultTimeout time.Duration
 	defaultWorkDir string
 	defaultEnv     map[string]string
+	logger         *slog.Logger
 }
 
 // NewExecutor 创建新的执行器
@@ -67,8 +70,35 @@ func (e *Executor) SetDefaultEnv(env map[string]string) {
 	e.defaultEnv = env
 }
 
+// SetLogger 设置日志记录器，每条命令执行后以debug级别记录，nil表示不记录
+func (e *Executor) SetLogger(logger *slog.Logger) {
+	e.logger = logger
+}
+
+// Logger 返回日志记录器，未设置时为nil
+func (e *Executor) Logger() *slog.Logger {
+	return e.logger
+}
+
 // Execute 执行命令
 func (e *Executor) Execute(ctx context.Context, options ExecuteOptions) (*ExecuteResult, error) {
+	result, err := e.execute(ctx, options)
+	if e.logger != nil {
+		var duration time.Duration
+		if result != nil {
+			duration = result.Duration
+		}
+		workingDir := options.WorkingDir
+		if workingDir == "" {
+			workingDir = e.defaultWorkDir
+		}
+		logCommand(ctx, e.logger, options.Command, options.Args, workingDir, duration, exitCodeOf(result), err)
+	}
+	return result, err
+}
+
+// execute 执行命令的实现
+func (e *Executor) execute(ctx context.Context, options ExecuteOptions) (*ExecuteResult, error) {
 	startTime := time.Now()
 	
 	// 设置默认值
@@ -88,6 +118,9 @@ func (e *Executor) Execute(ctx context.Context, options ExecuteOptions) (*Execut
 
 	// 创建命令
 	cmd := exec.CommandContext(ctx, options.Command, options.Args...)
+	if options.KillProcessGroup {
+		setProcessGroup(cmd)
+	}
 	
 	// 设置工作目录
 	if options.WorkingDir != "" {
@@ -156,11 +189,11 @@ func (e *Executor) Execute(ctx context.Context, options ExecuteOptions) (*Execut
 		}, err
 	}
 
+	// 等待输出处理完成，Wait会关闭管道，必须在读取结束后调用
+	wg.Wait()
+
 	// 等待命令完成
 	err = cmd.Wait()
-	
-	// 等待输出处理完成
-	wg.Wait()
 
 	// 构建结果
 	result := &ExecuteResult{
@@ -336,51 +369,59 @@ func (be *BatchExecutor) ExecuteBatch(ctx context.Context, options BatchOptions)
 	}
 	
 	results := make([]*ExecuteResult, len(options.Commands))
-	semaphore := make(chan struct{}, concurrency)
-	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var failedCount int
 	var shouldStop bool
 
+	tasks := make([]Task, len(options.Commands))
 	for i, cmd := range options.Commands {
-		wg.Add(1)
-		go func(index int, command ExecuteOptions) {
-			defer wg.Done()
-			
-			// 获取信号量
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-			
-			// 检查是否应该停止
-			mu.Lock()
-			if shouldStop {
-				mu.Unlock()
-				results[index] = &ExecuteResult{
-					Success:   false,
-					Cancelled: true,
-					Error:     fmt.Errorf("execution stopped due to previous error"),
-				}
-				return
-			}
-			mu.Unlock()
-			
-			// 执行命令
-			result, _ := be.executor.Execute(ctx, command)
-			results[index] = result
-			
-			// 检查是否失败
-			if !result.Success {
+		index, command := i, cmd
+		tasks[i] = Task{
+			Name: command.Command,
+			Run: func(ctx context.Context) error {
+				// 检查是否应该停止
 				mu.Lock()
-				failedCount++
-				if options.StopOnError {
-					shouldStop = true
+				if shouldStop {
+					mu.Unlock()
+					results[index] = &ExecuteResult{
+						Success:   false,
+						Cancelled: true,
+						Error:     fmt.Errorf("execution stopped due to previous error"),
+					}
+					return nil
 				}
 				mu.Unlock()
-			}
-		}(i, cmd)
+				
+				// 执行命令
+				result, err := be.executor.Execute(ctx, command)
+				results[index] = result
+				
+				// 检查是否失败
+				if !result.Success {
+					mu.Lock()
+					failedCount++
+					if options.StopOnError {
+						shouldStop = true
+					}
+					mu.Unlock()
+				}
+				return err
+			},
+		}
 	}
 	
-	wg.Wait()
+	for i, taskResult := range NewWorkerPool(concurrency).Run(ctx, tasks) {
+		// 未开始或panic的任务没有执行结果
+		if results[i] == nil {
+			_, panicked := taskResult.Err.(*PanicError)
+			results[i] = &ExecuteResult{
+				Success:   false,
+				Cancelled: !panicked,
+				Error:     taskResult.Err,
+			}
+			failedCount++
+		}
+	}
 	
 	return &BatchResult{
 		Results:     results,
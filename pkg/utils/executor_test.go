@@ -1212,3 +1212,27 @@ func TestBatchResultValidation(t *testing.T) {
 		t.Errorf("Expected TotalTime 5s, got %v", batchResult.TotalTime)
 	}
 }
+
+func TestExecuteKillProcessGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are not supported on Windows")
+	}
+
+	// 后台的sleep继承了输出管道，只终止sh时读取输出会一直等到sleep结束
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result, err := NewExecutor().Execute(ctx, ExecuteOptions{
+		Command:          "sh",
+		Args:             []string{"-c", "sleep 30 & wait"},
+		CaptureOutput:    true,
+		Timeout:          -1,
+		KillProcessGroup: true,
+	})
+	if err == nil || !result.Cancelled {
+		t.Errorf("Expected command to be cancelled, got %+v, %v", result, err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected child processes to be killed, took %v", elapsed)
+	}
+}
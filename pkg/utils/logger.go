@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// sensitiveFlags 值需要在日志中隐藏的参数
+var sensitiveFlags = []string{"otp", "token", "password", "auth"}
+
+// LogCommand 以debug级别记录通过exec.Cmd执行的命令，logger为nil时不记录
+//
+// 应在命令结束后调用，start为命令开始时间，err为Run/Output等方法返回的错误。
+func LogCommand(ctx context.Context, logger *slog.Logger, cmd *exec.Cmd, start time.Time, err error) {
+	if logger == nil || cmd == nil {
+		return
+	}
+
+	exitCode := -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	var args []string
+	if len(cmd.Args) > 1 {
+		args = cmd.Args[1:]
+	}
+	logCommand(ctx, logger, cmd.Path, args, cmd.Dir, time.Since(start), exitCode, err)
+}
+
+// logCommand 记录命令、参数、工作目录、耗时和退出码
+func logCommand(ctx context.Context, logger *slog.Logger, command string, args []string, dir string, duration time.Duration, exitCode int, err error) {
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("command", command),
+		slog.Any("args", RedactArgs(args)),
+		slog.Duration("duration", duration),
+		slog.Int("exit_code", exitCode),
+	}
+	if dir != "" {
+		attrs = append(attrs, slog.String("dir", dir))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, slog.LevelDebug, "command executed", attrs...)
+}
+
+// RedactArgs 隐藏参数中的一次性密码、令牌等敏感值，返回新的切片
+//
+// 支持"--otp 123456"和"--otp=123456"两种形式。
+func RedactArgs(args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		if !strings.HasPrefix(arg, "-") || !isSensitiveFlag(arg) {
+			continue
+		}
+		if name, _, found := strings.Cut(arg, "="); found {
+			redacted[i] = name + "=***"
+		} else if i+1 < len(redacted) && !strings.HasPrefix(redacted[i+1], "-") {
+			redacted[i+1] = "***"
+			i++
+		}
+	}
+	return redacted
+}
+
+// IsSensitiveKey 判断配置项或参数名是否包含otp、token、password、auth等敏感关键字，不区分大小写
+func IsSensitiveKey(name string) bool {
+	return isSensitiveFlag(name)
+}
+
+// textRedactions RedactText使用的替换规则
+var textRedactions = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// .npmrc和命令行中的_authToken=xxx、password: xxx等
+	{regexp.MustCompile(`(?i)([\w:/.@-]*(?:otp|token|password|auth)["']?\s*[=:]\s*["']?)[^\s"',;]+`), "${1}***"},
+	// URL中的用户名和密码
+	{regexp.MustCompile(`(://)[^/\s:@]+:[^/\s@]+@`), "${1}***@"},
+	// HTTP认证头
+	{regexp.MustCompile(`(?i)\b(Bearer|Basic)\s+[A-Za-z0-9._~+/=-]+`), "${1} ***"},
+	// npm访问令牌
+	{regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}\b`), "***"},
+}
+
+// RedactText 隐藏文本中的令牌、密码、认证头和URL中的凭据，用于日志和诊断信息
+func RedactText(text string) string {
+	for _, redaction := range textRedactions {
+		text = redaction.pattern.ReplaceAllString(text, redaction.replacement)
+	}
+	return text
+}
+
+// isSensitiveFlag 判断参数名是否包含敏感关键字
+func isSensitiveFlag(arg string) bool {
+	name, _, _ := strings.Cut(strings.ToLower(arg), "=")
+	for _, flag := range sensitiveFlags {
+		if strings.Contains(name, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// exitCodeOf 从执行结果中取得退出码，进程未正常结束（启动失败、超时、取消）时为-1
+func exitCodeOf(result *ExecuteResult) int {
+	if result != nil && (result.Success || result.ExitCode != 0) {
+		return result.ExitCode
+	}
+	return -1
+}
@@ -0,0 +1,35 @@
+package utils
+
+import "github.com/scagogogo/go-npm-sdk/pkg/parse"
+
+// 解析相关的类型和函数已移到pkg/parse，这里保留别名以兼容已有代码，
+// 调整解析限制请修改parse.DefaultLimits。
+
+var (
+	// ErrInputTooLarge 输入超过ParseLimits.MaxSize
+	ErrInputTooLarge = parse.ErrInputTooLarge
+
+	// ErrNestingTooDeep 嵌套深度超过ParseLimits.MaxDepth
+	ErrNestingTooDeep = parse.ErrNestingTooDeep
+)
+
+// ParseLimits 解析不可信输入时的限制
+type ParseLimits = parse.Limits
+
+// ParseError 解析失败的位置信息
+type ParseError = parse.Error
+
+// NewParseError 创建data中offset处的解析错误，行列号根据offset计算
+func NewParseError(source string, data []byte, offset int64, err error) *ParseError {
+	return parse.NewError(source, data, offset, err)
+}
+
+// CheckSize 检查输入大小是否超过parse.DefaultLimits.MaxSize
+func CheckSize(source string, data []byte) error {
+	return parse.CheckSize(source, data)
+}
+
+// DecodeJSON 在parse.DefaultLimits限制下解析JSON，失败时返回*ParseError
+func DecodeJSON(source string, data []byte, v interface{}) error {
+	return parse.DecodeJSON(source, data, v)
+}
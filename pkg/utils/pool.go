@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Task 工作池中的一个任务
+type Task struct {
+	Name     string                          // 任务名，用于结果和panic信息
+	Priority int                             // 优先级，越大越先开始，相同优先级按提交顺序
+	Timeout  time.Duration                   // 单个任务的超时时间，0表示使用工作池的默认值
+	Run      func(ctx context.Context) error // 任务内容，超时或取消时ctx结束
+}
+
+// TaskResult 任务执行结果，与Run传入的任务一一对应
+type TaskResult struct {
+	Name     string        `json:"name"`
+	Started  bool          `json:"started"` // 是否开始执行，ctx取消后未开始的任务为false
+	Err      error         `json:"-"`
+	Duration time.Duration `json:"duration"`
+}
+
+// PanicError 任务panic时的错误，panic不会影响其他任务和调用方
+type PanicError struct {
+	Task  string
+	Value any
+	Stack []byte
+}
+
+// Error 实现error接口
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task %s panicked: %v", e.Task, e.Value)
+}
+
+// WorkerPool 有并发上限的工作池
+//
+// 批量执行器、批量查询包信息等需要并发执行一组任务的地方都使用它，
+// 不再各自管理goroutine。可以重复调用Run，每次调用之间互不影响。
+type WorkerPool struct {
+	concurrency int
+	taskTimeout time.Duration
+}
+
+// NewWorkerPool 创建工作池，concurrency小于等于0时为1
+func NewWorkerPool(concurrency int) *WorkerPool {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &WorkerPool{concurrency: concurrency}
+}
+
+// SetTaskTimeout 设置任务的默认超时时间，0表示不限制
+func (p *WorkerPool) SetTaskTimeout(timeout time.Duration) {
+	p.taskTimeout = timeout
+}
+
+// Run 执行一组任务并等待全部结束
+//
+// 任务按优先级从高到低开始执行，同时最多运行concurrency个。
+// 任务的panic被恢复为*PanicError。ctx取消后未开始的任务不再执行，
+// 其结果的Err为ctx.Err()。任务需要自己响应ctx，Run不会强行中止正在运行的任务。
+func (p *WorkerPool) Run(ctx context.Context, tasks []Task) []TaskResult {
+	results := make([]TaskResult, len(tasks))
+	order := make([]int, len(tasks))
+	for i := range tasks {
+		order[i] = i
+		results[i].Name = tasks[i].Name
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return tasks[order[a]].Priority > tasks[order[b]].Priority
+	})
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < min(p.concurrency, len(tasks)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range queue {
+				results[index] = p.runTask(ctx, tasks[index])
+			}
+		}()
+	}
+
+	for n, index := range order {
+		select {
+		case queue <- index:
+			continue
+		case <-ctx.Done():
+		}
+		for _, rest := range order[n:] {
+			results[rest].Err = ctx.Err()
+		}
+		break
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+// runTask 执行单个任务，恢复panic并应用超时
+func (p *WorkerPool) runTask(ctx context.Context, task Task) (result TaskResult) {
+	result.Name = task.Name
+	// 取消时select可能仍然选中发送任务
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	timeout := task.Timeout
+	if timeout == 0 {
+		timeout = p.taskTimeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result.Started = true
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+		if r := recover(); r != nil {
+			result.Err = &PanicError{Task: task.Name, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	result.Err = task.Run(ctx)
+	return result
+}
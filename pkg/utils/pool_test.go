@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolRun(t *testing.T) {
+	pool := NewWorkerPool(3)
+
+	var running, peak int32
+	var mu sync.Mutex
+	var order []string
+	tasks := make([]Task, 10)
+	for i := range tasks {
+		name := fmt.Sprintf("task-%d", i)
+		tasks[i] = Task{Name: name, Run: func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			mu.Lock()
+			if n > peak {
+				peak = n
+			}
+			order = append(order, name)
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			if name == "task-4" {
+				return errors.New("boom")
+			}
+			return nil
+		}}
+	}
+
+	results := pool.Run(context.Background(), tasks)
+	if len(results) != len(tasks) || len(order) != len(tasks) {
+		t.Fatalf("Expected %d results, got %d (%d ran)", len(tasks), len(results), len(order))
+	}
+	if peak > 3 {
+		t.Errorf("Expected at most 3 concurrent tasks, got %d", peak)
+	}
+	for i, result := range results {
+		if result.Name != tasks[i].Name || !result.Started || result.Duration <= 0 {
+			t.Errorf("Unexpected result %d: %+v", i, result)
+		}
+		if (result.Err != nil) != (i == 4) {
+			t.Errorf("Unexpected error for %s: %v", result.Name, result.Err)
+		}
+	}
+
+	if NewWorkerPool(0).concurrency != 1 {
+		t.Error("Expected non-positive concurrency to default to 1")
+	}
+	if results := pool.Run(context.Background(), nil); len(results) != 0 {
+		t.Errorf("Expected no results for no tasks, got %v", results)
+	}
+}
+
+func TestWorkerPoolPriority(t *testing.T) {
+	var order []string
+	record := func(name string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	tasks := []Task{
+		{Name: "low", Priority: -1, Run: record("low")},
+		{Name: "a", Run: record("a")},
+		{Name: "high", Priority: 10, Run: record("high")},
+		{Name: "b", Run: record("b")},
+	}
+
+	// 单个worker时执行顺序就是调度顺序
+	NewWorkerPool(1).Run(context.Background(), tasks)
+	if expected := []string{"high", "a", "b", "low"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+}
+
+func TestWorkerPoolTimeout(t *testing.T) {
+	pool := NewWorkerPool(2)
+	pool.SetTaskTimeout(10 * time.Millisecond)
+
+	wait := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+	results := pool.Run(context.Background(), []Task{
+		{Name: "default", Run: wait},
+		{Name: "own", Timeout: 20 * time.Millisecond, Run: func(ctx context.Context) error {
+			deadline, ok := ctx.Deadline()
+			if !ok || time.Until(deadline) <= 10*time.Millisecond {
+				return errors.New("expected task timeout to override the pool default")
+			}
+			return nil
+		}},
+	})
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Errorf("Expected default timeout, got %v", results[0].Err)
+	}
+	if results[1].Err != nil {
+		t.Error(results[1].Err)
+	}
+}
+
+func TestWorkerPoolPanic(t *testing.T) {
+	results := NewWorkerPool(2).Run(context.Background(), []Task{
+		{Name: "bad", Run: func(ctx context.Context) error { panic("oops") }},
+		{Name: "good", Run: func(ctx context.Context) error { return nil }},
+	})
+
+	var panicErr *PanicError
+	if !errors.As(results[0].Err, &panicErr) {
+		t.Fatalf("Expected *PanicError, got %v", results[0].Err)
+	}
+	if panicErr.Task != "bad" || panicErr.Value != "oops" || !strings.Contains(string(panicErr.Stack), "pool_test.go") {
+		t.Errorf("Unexpected panic error: %v", panicErr)
+	}
+	if results[1].Err != nil {
+		t.Errorf("Expected other tasks to be unaffected, got %v", results[1].Err)
+	}
+}
+
+func TestWorkerPoolCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ran int32
+	tasks := make([]Task, 20)
+	for i := range tasks {
+		tasks[i] = Task{Name: fmt.Sprint(i), Run: func(ctx context.Context) error {
+			if atomic.AddInt32(&ran, 1) == 2 {
+				cancel()
+			}
+			return nil
+		}}
+	}
+
+	results := NewWorkerPool(1).Run(ctx, tasks)
+	if ran >= int32(len(tasks)) {
+		t.Fatal("Expected tasks to stop after cancellation")
+	}
+	last := results[len(results)-1]
+	if last.Started || last.Err != context.Canceled {
+		t.Errorf("Expected unstarted task to fail with context.Canceled, got %+v", last)
+	}
+}
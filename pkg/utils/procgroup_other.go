@@ -0,0 +1,8 @@
+//go:build !unix
+
+package utils
+
+import "os/exec"
+
+// setProcessGroup 当前平台不支持进程组，取消时只终止命令本身
+func setProcessGroup(cmd *exec.Cmd) {}
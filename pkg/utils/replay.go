@@ -0,0 +1,264 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CommandExecutor 命令执行接口，Executor、Recorder和Replayer都实现了该接口
+type CommandExecutor interface {
+	Execute(ctx context.Context, options ExecuteOptions) (*ExecuteResult, error)
+}
+
+// ErrNoInteraction 回放时没有匹配的录制记录
+var ErrNoInteraction = errors.New("no recorded interaction matches the command")
+
+// Interaction 一次录制的命令调用
+//
+// 不记录工作目录和环境变量，参数中的一次性密码、令牌等敏感值在录制时隐藏。
+type Interaction struct {
+	Command  string   `json:"command"` // 命令名，不含目录和.cmd/.exe扩展名
+	Args     []string `json:"args"`
+	Input    string   `json:"input,omitempty"`
+	Stdout   string   `json:"stdout"`
+	Stderr   string   `json:"stderr"`
+	ExitCode int      `json:"exit_code"`
+	Error    string   `json:"error,omitempty"` // 进程未正常结束时的错误，例如超时
+}
+
+// Fixture 录制的命令调用集合，按调用顺序排列
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadFixture 从JSON文件加载录制记录
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// Save 保存录制记录为JSON文件，目录不存在时自动创建
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// commandName 去掉命令的目录和Windows可执行文件扩展名，使录制记录与安装位置和平台无关
+func commandName(command string) string {
+	name := command[strings.LastIndexAny(command, `/\`)+1:]
+	for _, ext := range []string{".cmd", ".exe", ".bat"} {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// Recorder 包装真实的执行器，记录每次调用的参数和输出
+type Recorder struct {
+	executor CommandExecutor
+	mu       sync.Mutex
+	fixture  Fixture
+}
+
+// NewRecorder 创建录制器，executor为nil时使用NewExecutor
+func NewRecorder(executor CommandExecutor) *Recorder {
+	if executor == nil {
+		executor = NewExecutor()
+	}
+	return &Recorder{executor: executor}
+}
+
+// Execute 执行命令并记录结果
+func (r *Recorder) Execute(ctx context.Context, options ExecuteOptions) (*ExecuteResult, error) {
+	result, err := r.executor.Execute(ctx, options)
+
+	interaction := Interaction{
+		Command: commandName(options.Command),
+		Args:    RedactArgs(options.Args),
+		Input:   options.Input,
+	}
+	if result != nil {
+		interaction.Stdout = result.Stdout
+		interaction.Stderr = result.Stderr
+		interaction.ExitCode = result.ExitCode
+	}
+	if err != nil && interaction.ExitCode == 0 {
+		interaction.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	r.fixture.Interactions = append(r.fixture.Interactions, interaction)
+	r.mu.Unlock()
+
+	return result, err
+}
+
+// SetLogger 设置被包装执行器的日志记录器，执行器不支持日志时忽略
+func (r *Recorder) SetLogger(logger *slog.Logger) {
+	if executor, ok := r.executor.(interface{ SetLogger(*slog.Logger) }); ok {
+		executor.SetLogger(logger)
+	}
+}
+
+// Fixture 返回已录制的调用
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Fixture{Interactions: slices.Clone(r.fixture.Interactions)}
+}
+
+// Save 保存已录制的调用
+func (r *Recorder) Save(path string) error {
+	return r.Fixture().Save(path)
+}
+
+// Matcher 判断录制记录是否与本次调用匹配
+type Matcher func(recorded Interaction, options ExecuteOptions) bool
+
+// DefaultMatcher 命令名、隐藏敏感值后的参数和输入都相同时匹配
+func DefaultMatcher(recorded Interaction, options ExecuteOptions) bool {
+	return recorded.Command == commandName(options.Command) &&
+		slices.Equal(recorded.Args, RedactArgs(options.Args)) &&
+		recorded.Input == options.Input
+}
+
+// Replayer 按录制记录返回结果，不执行真实命令
+//
+// 每条记录只使用一次，按录制顺序取第一条匹配的记录，
+// 因此同一命令多次调用时依次返回录制时的各次结果。
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+	matcher      Matcher
+}
+
+// NewReplayer 创建回放器
+func NewReplayer(fixture *Fixture) *Replayer {
+	interactions := slices.Clone(fixture.Interactions)
+	return &Replayer{
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+		matcher:      DefaultMatcher,
+	}
+}
+
+// LoadReplayer 从JSON文件创建回放器
+func LoadReplayer(path string) (*Replayer, error) {
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(fixture), nil
+}
+
+// SetMatcher 设置匹配函数，例如忽略参数中的临时路径，nil表示使用DefaultMatcher
+func (r *Replayer) SetMatcher(matcher Matcher) {
+	if matcher == nil {
+		matcher = DefaultMatcher
+	}
+	r.mu.Lock()
+	r.matcher = matcher
+	r.mu.Unlock()
+}
+
+// Execute 返回匹配的录制结果，没有匹配记录时返回ErrNoInteraction
+func (r *Replayer) Execute(ctx context.Context, options ExecuteOptions) (*ExecuteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return &ExecuteResult{Cancelled: true, Error: err}, err
+	}
+
+	interaction, ok := r.next(options)
+	if !ok {
+		err := fmt.Errorf("%w: %s %s", ErrNoInteraction, commandName(options.Command), strings.Join(RedactArgs(options.Args), " "))
+		return &ExecuteResult{ExitCode: -1, Error: err}, err
+	}
+
+	result := &ExecuteResult{
+		ExitCode: interaction.ExitCode,
+		Stdout:   interaction.Stdout,
+		Stderr:   interaction.Stderr,
+	}
+	if options.StreamOutput && options.OutputCallback != nil {
+		replayOutput(result.Stdout, "stdout", options.OutputCallback)
+		replayOutput(result.Stderr, "stderr", options.OutputCallback)
+	}
+
+	// 与Executor返回的错误保持一致
+	switch {
+	case interaction.Error == ErrCommandTimeout.Error():
+		result.Cancelled = true
+		result.Error = ErrCommandTimeout
+	case interaction.Error != "":
+		result.Error = errors.New(interaction.Error)
+	case interaction.ExitCode != 0:
+		result.Error = fmt.Errorf("command failed with exit code %d", interaction.ExitCode)
+	default:
+		result.Success = true
+	}
+	return result, result.Error
+}
+
+// Remaining 返回尚未被使用的录制记录，测试结束时可以用来确认所有预期调用都已发生
+func (r *Replayer) Remaining() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var remaining []Interaction
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			remaining = append(remaining, interaction)
+		}
+	}
+	return remaining
+}
+
+// next 取出第一条匹配且未使用的记录
+func (r *Replayer) next(options ExecuteOptions) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if !r.used[i] && r.matcher(interaction, options) {
+			r.used[i] = true
+			return interaction, true
+		}
+	}
+	return Interaction{}, false
+}
+
+// replayOutput 按行回放输出，格式与Executor的流式输出一致
+func replayOutput(output, streamType string, callback func(string)) {
+	if output == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		callback(fmt.Sprintf("[%s] %s", streamType, line))
+	}
+}
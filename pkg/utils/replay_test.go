@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	ctx := context.Background()
+	recorder := NewRecorder(nil)
+
+	commands := []ExecuteOptions{
+		{Command: "/bin/sh", Args: []string{"-c", "echo out; echo err >&2"}, CaptureOutput: true},
+		{Command: "sh", Args: []string{"-c", "exit 2", "--otp", "123456"}, CaptureOutput: true},
+		{Command: "sh", Args: []string{"-c", "cat"}, Input: "hello\n", CaptureOutput: true},
+	}
+	var recorded []*ExecuteResult
+	for _, options := range commands {
+		result, _ := recorder.Execute(ctx, options)
+		recorded = append(recorded, result)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixtures", "sh.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		t.Fatalf("LoadFixture() failed: %v", err)
+	}
+	if len(fixture.Interactions) != 3 {
+		t.Fatalf("Expected 3 interactions, got %d", len(fixture.Interactions))
+	}
+	if fixture.Interactions[0].Command != "sh" {
+		t.Errorf("Expected command name without directory, got %q", fixture.Interactions[0].Command)
+	}
+	if strings.Join(fixture.Interactions[1].Args, " ") != "-c exit 2 --otp ***" {
+		t.Errorf("Expected OTP to be redacted, got %v", fixture.Interactions[1].Args)
+	}
+
+	replayer, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatalf("LoadReplayer() failed: %v", err)
+	}
+
+	// 按不同顺序回放，结果与录制时一致
+	for _, i := range []int{2, 0, 1} {
+		result, err := replayer.Execute(ctx, commands[i])
+		if result.Stdout != recorded[i].Stdout || result.Stderr != recorded[i].Stderr {
+			t.Errorf("Command %d: expected output %q/%q, got %q/%q", i, recorded[i].Stdout, recorded[i].Stderr, result.Stdout, result.Stderr)
+		}
+		if result.ExitCode != recorded[i].ExitCode || result.Success != recorded[i].Success {
+			t.Errorf("Command %d: expected exit code %d, got %d", i, recorded[i].ExitCode, result.ExitCode)
+		}
+		if (err != nil) != (recorded[i].Error != nil) {
+			t.Errorf("Command %d: expected error %v, got %v", i, recorded[i].Error, err)
+		}
+	}
+	if remaining := replayer.Remaining(); len(remaining) != 0 {
+		t.Errorf("Expected all interactions to be used, got %v", remaining)
+	}
+
+	// 每条记录只能使用一次
+	if _, err := replayer.Execute(ctx, commands[0]); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("Expected ErrNoInteraction, got %v", err)
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	ctx := context.Background()
+	fixture := &Fixture{Interactions: []Interaction{
+		{Command: "npm", Args: []string{"--version"}, Stdout: "10.0.0\n"},
+		{Command: "npm", Args: []string{"--version"}, Stdout: "10.1.0\n"},
+		{Command: "npm", Args: []string{"install"}, Stdout: "added 1 package\n", Stderr: "npm warn deprecated\n"},
+		{Command: "npm", Args: []string{"publish"}, Error: ErrCommandTimeout.Error()},
+	}}
+	replayer := NewReplayer(fixture)
+
+	// 同一命令依次返回录制的结果，命令路径和Windows扩展名不影响匹配
+	for _, expected := range []string{"10.0.0\n", "10.1.0\n"} {
+		result, err := replayer.Execute(ctx, ExecuteOptions{Command: `C:\nodejs\npm.cmd`, Args: []string{"--version"}})
+		if err != nil || result.Stdout != expected || !result.Success {
+			t.Errorf("Expected %q, got %+v, %v", expected, result, err)
+		}
+	}
+
+	// 流式输出按行回放
+	var lines []string
+	_, err := replayer.Execute(ctx, ExecuteOptions{
+		Command:        "npm",
+		Args:           []string{"install"},
+		StreamOutput:   true,
+		OutputCallback: func(line string) { lines = append(lines, line) },
+	})
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if strings.Join(lines, "|") != "[stdout] added 1 package|[stderr] npm warn deprecated" {
+		t.Errorf("Unexpected streamed output: %v", lines)
+	}
+
+	// 录制的超时错误
+	result, err := replayer.Execute(ctx, ExecuteOptions{Command: "npm", Args: []string{"publish"}})
+	if !errors.Is(err, ErrCommandTimeout) || !result.Cancelled {
+		t.Errorf("Expected timeout, got %+v, %v", result, err)
+	}
+
+	// 已取消的上下文
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := replayer.Execute(cancelled, ExecuteOptions{Command: "npm"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// 没有匹配的记录
+	result, err = replayer.Execute(ctx, ExecuteOptions{Command: "npm", Args: []string{"ci"}})
+	if !errors.Is(err, ErrNoInteraction) || result == nil || result.ExitCode != -1 {
+		t.Errorf("Expected ErrNoInteraction, got %+v, %v", result, err)
+	}
+}
+
+func TestReplayerSetMatcher(t *testing.T) {
+	replayer := NewReplayer(&Fixture{Interactions: []Interaction{
+		{Command: "npm", Args: []string{"publish", "/tmp/recorded/pkg-1.0.0.tgz"}, Stdout: "+ pkg@1.0.0\n"},
+	}})
+
+	// 忽略参数中的临时路径
+	replayer.SetMatcher(func(recorded Interaction, options ExecuteOptions) bool {
+		return recorded.Command == "npm" && len(options.Args) > 0 && recorded.Args[0] == options.Args[0]
+	})
+
+	result, err := replayer.Execute(context.Background(), ExecuteOptions{Command: "npm", Args: []string{"publish", "/tmp/other/pkg-1.0.0.tgz"}})
+	if err != nil || result.Stdout != "+ pkg@1.0.0\n" {
+		t.Errorf("Expected custom matcher to match, got %+v, %v", result, err)
+	}
+}
+
+func TestRecorderStartFailure(t *testing.T) {
+	recorder := NewRecorder(NewExecutor())
+	if _, err := recorder.Execute(context.Background(), ExecuteOptions{Command: "go-npm-sdk-missing-command", CaptureOutput: true}); err == nil {
+		t.Fatal("Expected error for missing command")
+	}
+
+	fixture := recorder.Fixture()
+	if len(fixture.Interactions) != 1 || fixture.Interactions[0].Error == "" {
+		t.Fatalf("Expected recorded start error, got %+v", fixture.Interactions)
+	}
+
+	// 回放时返回相同的错误
+	_, err := NewReplayer(fixture).Execute(context.Background(), ExecuteOptions{Command: "go-npm-sdk-missing-command"})
+	if err == nil || err.Error() != fixture.Interactions[0].Error {
+		t.Errorf("Expected %q, got %v", fixture.Interactions[0].Error, err)
+	}
+}
@@ -0,0 +1,288 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+// Git 基于git命令行的VCS实现
+type Git struct {
+	gitPath  string
+	dir      string
+	env      map[string]string
+	executor utils.CommandExecutor
+}
+
+// NewGit 创建git仓库操作对象
+func NewGit(dir string) *Git {
+	return &Git{
+		gitPath:  "git",
+		dir:      dir,
+		env:      make(map[string]string),
+		executor: utils.NewExecutor(),
+	}
+}
+
+// SetGitPath 设置git可执行文件路径
+func (g *Git) SetGitPath(path string) {
+	g.gitPath = path
+}
+
+// SetExecutor 设置执行git命令的执行器，例如在测试中使用utils.Replayer回放录制的命令
+func (g *Git) SetExecutor(executor utils.CommandExecutor) {
+	g.executor = executor
+}
+
+// SetAuthor 设置提交者信息，未设置时使用git配置
+func (g *Git) SetAuthor(name, email string) {
+	g.env["GIT_AUTHOR_NAME"] = name
+	g.env["GIT_AUTHOR_EMAIL"] = email
+	g.env["GIT_COMMITTER_NAME"] = name
+	g.env["GIT_COMMITTER_EMAIL"] = email
+}
+
+// Dir 仓库目录
+func (g *Git) Dir() string {
+	return g.dir
+}
+
+// CurrentBranch 获取当前分支
+func (g *Git) CurrentBranch(ctx context.Context) (string, error) {
+	output, err := g.run(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// CreateBranch 创建并切换到新分支
+func (g *Git) CreateBranch(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("branch name cannot be empty")
+	}
+	_, err := g.run(ctx, "checkout", "-b", name)
+	return err
+}
+
+// Checkout 切换分支
+func (g *Git) Checkout(ctx context.Context, name string) error {
+	_, err := g.run(ctx, "checkout", name)
+	return err
+}
+
+// Add 暂存文件
+func (g *Git) Add(ctx context.Context, paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	_, err := g.run(ctx, append([]string{"add", "--"}, paths...)...)
+	return err
+}
+
+// Commit 提交暂存的修改，返回提交ID
+func (g *Git) Commit(ctx context.Context, message string) (string, error) {
+	if _, err := g.run(ctx, "diff", "--cached", "--quiet"); err == nil {
+		return "", ErrNoChanges
+	}
+
+	if _, err := g.runWithInput(ctx, message, "commit", "-F", "-"); err != nil {
+		return "", err
+	}
+
+	output, err := g.run(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Head 获取当前提交ID
+func (g *Git) Head(ctx context.Context) (string, error) {
+	output, err := g.run(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// HasChanges 检查工作区是否有未提交的修改
+func (g *Git) HasChanges(ctx context.Context) (bool, error) {
+	output, err := g.run(ctx, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(output) != "", nil
+}
+
+// Status 获取工作区中有修改的文件，包括未跟踪的文件，按路径排序
+func (g *Git) Status(ctx context.Context) ([]FileStatus, error) {
+	output, err := g.run(ctx, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	return parsePorcelainStatus(output), nil
+}
+
+// Diff 获取两个版本之间修改过的文件路径，from为空时使用HEAD，to为空时与工作区比较
+func (g *Git) Diff(ctx context.Context, from, to string) ([]string, error) {
+	if from == "" {
+		from = "HEAD"
+	}
+	args := []string{"diff", "--name-only", "--no-renames", "-z", from}
+	if to != "" {
+		args = append(args, to)
+	}
+	args = append(args, "--")
+
+	output, err := g.run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, path := range strings.Split(strings.TrimSuffix(output, "\n"), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Tag 在HEAD上创建标签，message不为空时创建附注标签
+func (g *Git) Tag(ctx context.Context, name, message string) error {
+	if name == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+	if message == "" {
+		_, err := g.run(ctx, "tag", name)
+		return err
+	}
+	_, err := g.runWithInput(ctx, message, "tag", "-a", name, "-F", "-")
+	return err
+}
+
+// Push 推送分支或标签，remote为空时使用origin，refs为空时推送当前分支
+func (g *Git) Push(ctx context.Context, remote string, refs ...string) error {
+	if remote == "" {
+		remote = "origin"
+	}
+	_, err := g.run(ctx, append([]string{"push", remote}, refs...)...)
+	return err
+}
+
+// Log 获取from之后到to为止的提交，按从新到旧排序，from为空时从第一个提交开始，to为空时使用HEAD
+func (g *Git) Log(ctx context.Context, from, to string) ([]CommitInfo, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	rev := to
+	if from != "" {
+		rev = from + ".." + to
+	}
+
+	// 字段用\x1f分隔，提交用\x1e分隔，正文中可以包含换行
+	output, err := g.run(ctx, "log", "--format=%H%x1f%an%x1f%aI%x1f%s%x1f%b%x1e", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []CommitInfo
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, CommitInfo{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Date:    date,
+			Subject: fields[3],
+			Body:    strings.TrimSpace(fields[4]),
+		})
+	}
+	return commits, nil
+}
+
+// LatestTag 获取HEAD可以到达的最近的标签，pattern为glob模式，没有匹配的标签时返回ErrNoTag
+func (g *Git) LatestTag(ctx context.Context, pattern string) (string, error) {
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	if pattern != "" {
+		args = append(args, "--match", pattern)
+	}
+	output, err := g.run(ctx, args...)
+	if err != nil {
+		// 没有标签或者仓库还没有提交
+		message := err.Error()
+		if strings.Contains(message, "No names found") || strings.Contains(message, "No tags can describe") ||
+			strings.Contains(message, "cannot describe") || strings.Contains(message, "Not a valid object name") {
+			return "", ErrNoTag
+		}
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// parsePorcelainStatus 解析git status --porcelain=v1 -z的输出
+func parsePorcelainStatus(output string) []FileStatus {
+	var status []FileStatus
+	entries := strings.Split(strings.TrimSuffix(output, "\n"), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		file := FileStatus{
+			Path:     entry[3:],
+			Staging:  StatusCode(entry[0]),
+			Worktree: StatusCode(entry[1]),
+		}
+		// 重命名和复制的下一项是原路径
+		if (file.Staging == Renamed || file.Staging == Copied) && i+1 < len(entries) {
+			i++
+			file.OrigPath = entries[i]
+		}
+		status = append(status, file)
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Path < status[j].Path
+	})
+	return status
+}
+
+// run 执行git命令
+func (g *Git) run(ctx context.Context, args ...string) (string, error) {
+	return g.runWithInput(ctx, "", args...)
+}
+
+// runWithInput 执行git命令并写入标准输入
+func (g *Git) runWithInput(ctx context.Context, input string, args ...string) (string, error) {
+	executeOptions := utils.ExecuteOptions{
+		Command:       g.gitPath,
+		Args:          args,
+		WorkingDir:    g.dir,
+		Env:           g.env,
+		Input:         input,
+		CaptureOutput: true,
+		Timeout:       2 * time.Minute,
+	}
+
+	result, err := g.executor.Execute(ctx, executeOptions)
+	if err != nil {
+		stderr := ""
+		if result != nil {
+			stderr = strings.TrimSpace(result.Stderr)
+		}
+		if stderr != "" {
+			return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, stderr)
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+
+	return result.Stdout, nil
+}
@@ -0,0 +1,298 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/go-npm-sdk/pkg/utils"
+)
+
+func newTestRepo(t *testing.T) *Git {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	repo := NewGit(dir)
+	repo.SetAuthor("Test User", "test@example.com")
+
+	ctx := context.Background()
+	if _, err := repo.run(ctx, "init", "-b", "main"); err != nil {
+		t.Fatalf("git init failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# test\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := repo.Add(ctx, "README.md"); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	if _, err := repo.Commit(ctx, "Initial commit"); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	return repo
+}
+
+func TestGitBranchAndCommit(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	branch, err := repo.CurrentBranch(ctx)
+	if err != nil {
+		t.Fatalf("CurrentBranch() failed: %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("Expected branch 'main', got '%s'", branch)
+	}
+
+	if err := repo.CreateBranch(ctx, "deps/update"); err != nil {
+		t.Fatalf("CreateBranch() failed: %v", err)
+	}
+	if branch, _ := repo.CurrentBranch(ctx); branch != "deps/update" {
+		t.Errorf("Expected branch 'deps/update', got '%s'", branch)
+	}
+
+	if changed, err := repo.HasChanges(ctx); err != nil || changed {
+		t.Errorf("Expected clean tree, got %v, %v", changed, err)
+	}
+	if err := os.WriteFile(filepath.Join(repo.Dir(), "README.md"), []byte("# changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if changed, err := repo.HasChanges(ctx); err != nil || !changed {
+		t.Errorf("Expected dirty tree, got %v, %v", changed, err)
+	}
+
+	if _, err := repo.Commit(ctx, "Nothing staged"); !errors.Is(err, ErrNoChanges) {
+		t.Errorf("Expected ErrNoChanges, got %v", err)
+	}
+
+	if err := repo.Add(ctx, "README.md"); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	commit, err := repo.Commit(ctx, "Update readme\n\nWith a body.")
+	if err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	if len(commit) != 40 {
+		t.Errorf("Expected full commit hash, got '%s'", commit)
+	}
+	if head, err := repo.Head(ctx); err != nil || head != commit {
+		t.Errorf("Head() = %s, %v, expected %s", head, err, commit)
+	}
+
+	if err := repo.Checkout(ctx, "main"); err != nil {
+		t.Fatalf("Checkout() failed: %v", err)
+	}
+	if err := repo.CreateBranch(ctx, ""); err == nil {
+		t.Error("Expected error for empty branch name")
+	}
+}
+
+func TestGitErrors(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := NewGit(t.TempDir())
+	if _, err := repo.CurrentBranch(context.Background()); err == nil {
+		t.Error("Expected error outside of a repository")
+	}
+}
+
+func TestGitStatusAndDiff(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	dir := repo.Dir()
+
+	base, err := repo.Head(ctx)
+	if err != nil {
+		t.Fatalf("Head() failed: %v", err)
+	}
+	if status, err := repo.Status(ctx); err != nil || len(status) != 0 {
+		t.Fatalf("Expected clean status, got %v, %v", status, err)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "packages", "a"), 0755)
+	os.WriteFile(filepath.Join(dir, "packages", "a", "index.js"), []byte("a\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# changed\n"), 0644)
+
+	status, err := repo.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	expected := []FileStatus{
+		{Path: "README.md", Staging: Unmodified, Worktree: Modified},
+		{Path: "packages/a/index.js", Staging: Untracked, Worktree: Untracked},
+	}
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("Status() = %+v, expected %+v", status, expected)
+	}
+	if changed, err := HasChanges(ctx, repo); err != nil || !changed {
+		t.Errorf("HasChanges() = %v, %v", changed, err)
+	}
+
+	// 未跟踪的文件不出现在与工作区的比较中
+	if paths, err := repo.Diff(ctx, "", ""); err != nil || !reflect.DeepEqual(paths, []string{"README.md"}) {
+		t.Errorf("Diff() = %v, %v", paths, err)
+	}
+
+	repo.Add(ctx, "README.md", "packages")
+	if _, err := repo.run(ctx, "mv", "README.md", "docs.md"); err != nil {
+		t.Fatalf("git mv failed: %v", err)
+	}
+	if _, err := repo.Commit(ctx, "Add package a"); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	paths, err := repo.Diff(ctx, base, "HEAD")
+	if err != nil {
+		t.Fatalf("Diff() failed: %v", err)
+	}
+	if !reflect.DeepEqual(paths, []string{"README.md", "docs.md", "packages/a/index.js"}) {
+		t.Errorf("Diff() = %v", paths)
+	}
+}
+
+func TestGitTagAndPush(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	remote := t.TempDir()
+	if _, err := NewGit(remote).run(ctx, "init", "--bare"); err != nil {
+		t.Fatalf("git init --bare failed: %v", err)
+	}
+	if _, err := repo.run(ctx, "remote", "add", "origin", remote); err != nil {
+		t.Fatalf("git remote add failed: %v", err)
+	}
+
+	if err := repo.Tag(ctx, "v1.0.0", ""); err != nil {
+		t.Fatalf("Tag() failed: %v", err)
+	}
+	if err := repo.Tag(ctx, "v1.0.1", "Release 1.0.1\n\nNotes."); err != nil {
+		t.Fatalf("Tag() failed: %v", err)
+	}
+	if kind, _ := repo.run(ctx, "cat-file", "-t", "v1.0.1"); strings.TrimSpace(kind) != "tag" {
+		t.Errorf("Expected annotated tag, got %q", kind)
+	}
+	if err := repo.Tag(ctx, "", ""); err == nil {
+		t.Error("Expected error for empty tag name")
+	}
+
+	if err := repo.Push(ctx, "", "main", "v1.0.0", "v1.0.1"); err != nil {
+		t.Fatalf("Push() failed: %v", err)
+	}
+	refs, err := NewGit(remote).run(ctx, "show-ref")
+	if err != nil {
+		t.Fatalf("git show-ref failed: %v", err)
+	}
+	for _, ref := range []string{"refs/heads/main", "refs/tags/v1.0.0", "refs/tags/v1.0.1"} {
+		if !strings.Contains(refs, ref) {
+			t.Errorf("Expected %s in remote, got %s", ref, refs)
+		}
+	}
+}
+
+func TestGitLogAndLatestTag(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	if _, err := repo.LatestTag(ctx, "v*"); !errors.Is(err, ErrNoTag) {
+		t.Fatalf("Expected ErrNoTag, got %v", err)
+	}
+	if err := repo.Tag(ctx, "v1.0.0", "Release 1.0.0"); err != nil {
+		t.Fatalf("Tag() failed: %v", err)
+	}
+	if err := repo.Tag(ctx, "other", ""); err != nil {
+		t.Fatalf("Tag() failed: %v", err)
+	}
+
+	for i, message := range []string{"feat: add search", "fix(cli): handle empty input\n\nBREAKING CHANGE: exit code is 2"} {
+		path := filepath.Join(repo.Dir(), fmt.Sprintf("file%d.txt", i))
+		if err := os.WriteFile(path, []byte(message), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := repo.Add(ctx, filepath.Base(path)); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+		if _, err := repo.Commit(ctx, message); err != nil {
+			t.Fatalf("Commit() failed: %v", err)
+		}
+	}
+
+	tag, err := repo.LatestTag(ctx, "v*")
+	if err != nil || tag != "v1.0.0" {
+		t.Fatalf("Expected v1.0.0, got %q, %v", tag, err)
+	}
+
+	commits, err := repo.Log(ctx, tag, "")
+	if err != nil {
+		t.Fatalf("Log() failed: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits, got %+v", commits)
+	}
+	if commits[0].Subject != "fix(cli): handle empty input" || commits[0].Body != "BREAKING CHANGE: exit code is 2" {
+		t.Errorf("Unexpected commit: %+v", commits[0])
+	}
+	if commits[1].Subject != "feat: add search" || commits[1].Body != "" || commits[1].Author != "Test User" {
+		t.Errorf("Unexpected commit: %+v", commits[1])
+	}
+	if len(commits[0].Hash) != 40 || commits[0].Date.IsZero() {
+		t.Errorf("Expected hash and date, got %+v", commits[0])
+	}
+
+	all, err := repo.Log(ctx, "", "")
+	if err != nil || len(all) != 3 || all[2].Subject != "Initial commit" {
+		t.Errorf("Expected full history, got %+v, %v", all, err)
+	}
+}
+
+func TestParsePorcelainStatus(t *testing.T) {
+	output := "R  new.txt\x00old.txt\x00 M b.txt\x00UU conflict.txt\x00?? dir/file with space.txt\x00"
+	expected := []FileStatus{
+		{Path: "b.txt", Staging: Unmodified, Worktree: Modified},
+		{Path: "conflict.txt", Staging: UpdatedButUnmerged, Worktree: UpdatedButUnmerged},
+		{Path: "dir/file with space.txt", Staging: Untracked, Worktree: Untracked},
+		{Path: "new.txt", OrigPath: "old.txt", Staging: Renamed, Worktree: Unmodified},
+	}
+	if status := parsePorcelainStatus(output); !reflect.DeepEqual(status, expected) {
+		t.Errorf("parsePorcelainStatus() = %+v, expected %+v", status, expected)
+	}
+}
+
+// fakeExecutor 返回固定输出并记录参数的执行器
+type fakeExecutor struct {
+	stdout string
+	args   [][]string
+}
+
+func (e *fakeExecutor) Execute(ctx context.Context, options utils.ExecuteOptions) (*utils.ExecuteResult, error) {
+	e.args = append(e.args, options.Args)
+	return &utils.ExecuteResult{Stdout: e.stdout}, nil
+}
+
+func TestGitSetExecutor(t *testing.T) {
+	executor := &fakeExecutor{stdout: "b.txt\x00a.txt\x00"}
+	repo := NewGit("/repo")
+	repo.SetExecutor(executor)
+
+	paths, err := repo.Diff(context.Background(), "v1.0.0", "")
+	if err != nil || !reflect.DeepEqual(paths, []string{"a.txt", "b.txt"}) {
+		t.Errorf("Diff() = %v, %v", paths, err)
+	}
+	expected := []string{"diff", "--name-only", "--no-renames", "-z", "v1.0.0", "--"}
+	if !reflect.DeepEqual(executor.args[0], expected) {
+		t.Errorf("Expected args %v, got %v", expected, executor.args[0])
+	}
+
+	repo.Push(context.Background(), "upstream", "v1.0.0")
+	if !reflect.DeepEqual(executor.args[1], []string{"push", "upstream", "v1.0.0"}) {
+		t.Errorf("Unexpected push args: %v", executor.args[1])
+	}
+}
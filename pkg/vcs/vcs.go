@@ -0,0 +1,100 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoChanges 没有可提交的修改
+var ErrNoChanges = errors.New("no changes to commit")
+
+// ErrNoTag 没有匹配的标签
+var ErrNoTag = errors.New("no matching tag")
+
+// VCS 版本控制系统接口
+//
+// Updater等需要创建分支、提交、打标签和推送的功能通过该接口操作仓库。Git是基于git命令行的实现，
+// 也可以替换为其他实现，例如包装go-git、直接调用托管平台API或在测试中记录调用。
+// Status的状态码与go-git的StatusCode取值相同，包装go-git时可以直接转换。
+type VCS interface {
+	// 获取当前分支
+	CurrentBranch(ctx context.Context) (string, error)
+
+	// 创建并切换到新分支
+	CreateBranch(ctx context.Context, name string) error
+
+	// 切换分支
+	Checkout(ctx context.Context, name string) error
+
+	// 暂存文件
+	Add(ctx context.Context, paths ...string) error
+
+	// 提交暂存的修改，返回提交ID，没有暂存的修改时返回ErrNoChanges
+	Commit(ctx context.Context, message string) (string, error)
+
+	// 获取工作区中有修改的文件，包括未跟踪的文件，按路径排序
+	Status(ctx context.Context) ([]FileStatus, error)
+
+	// 获取两个版本之间修改过的文件路径，按路径排序
+	// from为空时使用HEAD，to为空时与工作区比较；重命名按删除旧路径和添加新路径处理
+	Diff(ctx context.Context, from, to string) ([]string, error)
+
+	// 在HEAD上创建标签，message不为空时创建附注标签
+	Tag(ctx context.Context, name, message string) error
+
+	// 推送分支或标签，remote为空时使用origin
+	Push(ctx context.Context, remote string, refs ...string) error
+}
+
+// History 读取提交历史的接口
+//
+// 与VCS分开定义，只需要修改仓库的实现不必提供历史记录。Git同时实现了两个接口。
+type History interface {
+	// 获取from之后到to为止的提交，按从新到旧排序
+	// from为空时从第一个提交开始，to为空时使用HEAD
+	Log(ctx context.Context, from, to string) ([]CommitInfo, error)
+
+	// 获取HEAD可以到达的最近的标签，pattern为glob模式，为空时匹配所有标签，没有时返回ErrNoTag
+	LatestTag(ctx context.Context, pattern string) (string, error)
+}
+
+// CommitInfo 一条提交记录
+type CommitInfo struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body,omitempty"`
+}
+
+// StatusCode 文件在暂存区或工作区中的状态
+type StatusCode byte
+
+const (
+	Unmodified         StatusCode = ' '
+	Untracked          StatusCode = '?'
+	Modified           StatusCode = 'M'
+	Added              StatusCode = 'A'
+	Deleted            StatusCode = 'D'
+	Renamed            StatusCode = 'R'
+	Copied             StatusCode = 'C'
+	UpdatedButUnmerged StatusCode = 'U'
+)
+
+// FileStatus 单个文件的状态
+type FileStatus struct {
+	Path     string     `json:"path"`
+	OrigPath string     `json:"orig_path,omitempty"` // 重命名或复制前的路径
+	Staging  StatusCode `json:"staging"`             // 暂存区相对HEAD的状态
+	Worktree StatusCode `json:"worktree"`            // 工作区相对暂存区的状态
+}
+
+// HasChanges 检查工作区是否有未提交的修改，包括未跟踪的文件
+func HasChanges(ctx context.Context, repo VCS) (bool, error) {
+	status, err := repo.Status(ctx)
+	if err != nil {
+		return false, err
+	}
+	return len(status) > 0, nil
+}